@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 
@@ -14,8 +15,15 @@ import (
 	"video-archiver/internal/api/handlers"
 	"video-archiver/internal/config"
 	"video-archiver/internal/repositories/sqlite"
+	"video-archiver/internal/services/automation"
+	"video-archiver/internal/services/bulk"
 	"video-archiver/internal/services/download"
+	"video-archiver/internal/services/export"
+	"video-archiver/internal/services/hls"
+	"video-archiver/internal/services/storyboard"
 	"video-archiver/internal/services/tools"
+	"video-archiver/internal/services/webhooks"
+	"video-archiver/internal/util/signedurl"
 	"video-archiver/internal/util/version"
 )
 
@@ -61,6 +69,11 @@ __     _____ ____  _____ ___
 	settingsRepo := sqlite.NewSettingsRepository(db)
 	toolsRepo := sqlite.NewToolsRepository(db)
 	collectionRepo := sqlite.NewCollectionRepository(db)
+	webhookRepo := sqlite.NewWebhookRepository(db)
+	automationRepo := sqlite.NewAutomationRepository(db)
+	bulkRepo := sqlite.NewBulkRepository(db)
+	searchRepo := sqlite.NewSearchRepository(db)
+	bookmarkRepo := sqlite.NewBookmarkRepository(db)
 
 	// Tag items downloaded before auto-tagging existed; idempotent, so it can
 	// run on every startup without growing the tag set.
@@ -70,6 +83,15 @@ __     _____ ____  _____ ___
 		}
 	}()
 
+	// Started first and stopped last, so events from the other services'
+	// shutdown are still logged; deliveries cut short resume on next start.
+	fmt.Println("Starting Webhook Service...")
+	webhookService := webhooks.NewService(&webhooks.Config{Repository: webhookRepo})
+	if err := webhookService.Start(); err != nil {
+		log.Fatalf("Failed to start webhook service: %v", err)
+	}
+	defer webhookService.Stop()
+
 	fmt.Println("Starting Download Service...")
 	downloadService := download.NewService(&download.Config{
 		JobRepository:      jobRepo,
@@ -77,14 +99,9 @@ __     _____ ____  _____ ___
 		DownloadPath:       cfg.Server.DownloadPath,
 		Concurrency:        cfg.YtDlp.Concurrency,
 		MaxQuality:         cfg.YtDlp.MaxQuality,
+		Notifier:           webhookService,
 	})
 
-	if err := downloadService.Start(); err != nil {
-		log.Fatalf("Failed to start download service: %v", err)
-	}
-	defer downloadService.Stop()
-
-	fmt.Println("Starting Tools Service...")
 	toolsService := tools.NewService(&tools.Config{
 		ToolsRepository:      toolsRepo,
 		JobRepository:        jobRepo,
@@ -95,17 +112,70 @@ __     _____ ____  _____ ___
 		DownloadPath:  cfg.Server.DownloadPath,
 		ProcessedPath: cfg.Server.ProcessedPath,
 		Concurrency:   2,
+		Notifier:      webhookService,
+	})
+
+	// Registered before the download workers start so no completion is
+	// missed.
+	automationService := automation.NewService(&automation.Config{
+		Rules:           automationRepo,
+		JobRepository:   jobRepo,
+		ToolsRepository: toolsRepo,
+		Tools:           toolsService,
+	})
+	storyboardService := storyboard.NewService(&storyboard.Config{
+		JobRepository: jobRepo,
+		DownloadPath:  cfg.Server.DownloadPath,
 	})
+	downloadService.SetCompletionListener(download.CompletionListeners{automationService, storyboardService})
 
+	if err := downloadService.Start(); err != nil {
+		log.Fatalf("Failed to start download service: %v", err)
+	}
+	defer downloadService.Stop()
+
+	fmt.Println("Starting Tools Service...")
 	if err := toolsService.Start(); err != nil {
 		log.Fatalf("Failed to start tools service: %v", err)
 	}
 	defer toolsService.Stop()
+	// Deferred last so they stop first, before the services they submit to.
+	defer automationService.Stop()
+	defer storyboardService.Stop()
 
+	bulkService := bulk.NewService(&bulk.Config{
+		Repository:           bulkRepo,
+		JobRepository:        jobRepo,
+		CollectionRepository: collectionRepo,
+		Downloads:            downloadService,
+		Tools:                toolsService,
+		Broadcaster:          downloadService.GetHub(),
+	})
+	defer bulkService.Stop()
+
+	signer := signedurl.New(cfg.Server.URLSigningKey)
 	handler := handlers.NewHandler(downloadService, cfg.Server.DownloadPath, settingsRepo,
-		toolsService, toolsRepo, tools.NewFFmpeg())
+		toolsService, toolsRepo, tools.NewFFmpeg(), signer)
 	toolsHandler := handlers.NewToolsHandler(toolsService)
 	collectionsHandler := handlers.NewCollectionsHandler(collectionRepo)
+	webhooksHandler := handlers.NewWebhooksHandler(webhookRepo, webhookService)
+	automationHandler := handlers.NewAutomationHandler(automationRepo, toolsRepo)
+	bulkHandler := handlers.NewBulkHandler(bulkService, bulkRepo)
+	searchHandler := handlers.NewSearchHandler(searchRepo)
+	exportService := export.NewService(&export.Config{
+		DownloadPath: cfg.Server.DownloadPath,
+		CachePath:    filepath.Join(cfg.Server.ProcessedPath, "exports"),
+		MaxCacheSize: cfg.Server.ExportCacheSizeMB << 20,
+	})
+	exportHandler := handlers.NewExportHandler(jobRepo, collectionRepo, exportService, signer, cfg.Server.PublicURL)
+	bookmarksHandler := handlers.NewBookmarksHandler(bookmarkRepo, jobRepo, toolsService)
+	hlsService := hls.NewService(&hls.Config{
+		CachePath:    filepath.Join(cfg.Server.ProcessedPath, "hls"),
+		MaxCacheSize: cfg.Server.HLSCacheSizeMB << 20,
+	})
+	defer hlsService.Stop()
+	hlsHandler := handlers.NewHLSHandler(jobRepo, cfg.Server.DownloadPath, hlsService)
+	storyboardHandler := handlers.NewStoryboardHandler(storyboardService)
 
 	// One router, one port: /ws lives next to the REST routes so deployments
 	// only need a single upstream and the frontend can use same-origin URLs.
@@ -113,6 +183,14 @@ __     _____ ____  _____ ___
 	handler.RegisterRoutes(apiRouter)
 	toolsHandler.RegisterRoutes(apiRouter)
 	collectionsHandler.RegisterRoutes(apiRouter)
+	webhooksHandler.RegisterRoutes(apiRouter)
+	automationHandler.RegisterRoutes(apiRouter)
+	bulkHandler.RegisterRoutes(apiRouter)
+	searchHandler.RegisterRoutes(apiRouter)
+	exportHandler.RegisterRoutes(apiRouter)
+	bookmarksHandler.RegisterRoutes(apiRouter)
+	hlsHandler.RegisterRoutes(apiRouter)
+	storyboardHandler.RegisterRoutes(apiRouter)
 
 	// Explicit timeouts so slow or stalled clients can't pin server resources
 	// indefinitely. Write timeouts are deliberately absent: /video streams
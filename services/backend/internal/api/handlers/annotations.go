@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	log "github.com/sirupsen/logrus"
+	"video-archiver/internal/domain"
+)
+
+// decodeAnnotation applies the request's annotation update to current,
+// writing the error response itself when the update is invalid.
+func decodeAnnotation(w http.ResponseWriter, r *http.Request, current *domain.Annotation) (*domain.Annotation, bool) {
+	var req domain.AnnotationUpdate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return nil, false
+	}
+	annotation, err := req.Apply(current, time.Now())
+	if err != nil {
+		http.Error(w, "Invalid annotation: "+err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	return annotation, true
+}
+
+// HandleSetJobAnnotation favorites, rates or takes notes on a video,
+// playlist or channel. Fields left out of the request keep their value.
+func (h *Handler) HandleSetJobAnnotation(w http.ResponseWriter, r *http.Request) {
+	repo := h.downloadService.GetRepository()
+	job, err := repo.GetByID(chi.URLParam(r, "id"))
+	if err != nil || job == nil {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+
+	current, err := repo.GetAnnotation(job.ID)
+	if err != nil {
+		log.WithError(err).Error("Failed to get annotation")
+		http.Error(w, "Failed to get annotation", http.StatusInternalServerError)
+		return
+	}
+	annotation, ok := decodeAnnotation(w, r, current)
+	if !ok {
+		return
+	}
+	if err := repo.SetAnnotation(job.ID, annotation); err != nil {
+		log.WithError(err).Error("Failed to set annotation")
+		http.Error(w, "Failed to set annotation", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Message: annotation})
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"video-archiver/internal/domain"
+	"video-archiver/internal/repositories/sqlite"
+	"video-archiver/internal/testutil"
+)
+
+func decodeAnnotationResponse(t *testing.T, rec *httptest.ResponseRecorder) domain.Annotation {
+	t.Helper()
+	var resp struct {
+		Message domain.Annotation `json:"message"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode %s: %v", rec.Body.String(), err)
+	}
+	return resp.Message
+}
+
+func TestHandleSetJobAnnotation(t *testing.T) {
+	handler, mockRepo := setupTestHandler(t)
+	r := chi.NewRouter()
+	handler.RegisterRoutes(r)
+	mockRepo.Create(testutil.CreateTestJob("pl", "https://youtube.com/playlist?list=x"))
+	mockRepo.StoreMetadata("pl", testutil.CreateTestPlaylistMetadata())
+
+	put := func(path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, path, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := put("/job/pl/annotation", `{"favorite": true, "notes": "# Watch in order"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	// A partial update keeps the other fields.
+	rec = put("/job/pl/annotation", `{"rating": 4}`)
+	if got := decodeAnnotationResponse(t, rec); !got.Favorite || got.Rating != 4 || got.Notes != "# Watch in order" {
+		t.Errorf("annotation = %+v", got)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/job/pl", nil))
+	if !strings.Contains(rec.Body.String(), `"rating":4`) {
+		t.Errorf("GET /job/pl does not include the annotation: %s", rec.Body.String())
+	}
+
+	for _, tt := range []struct {
+		path, body string
+		want       int
+	}{
+		{"/job/pl/annotation", `{"rating": 9}`, http.StatusBadRequest},
+		{"/job/pl/annotation", `nope`, http.StatusBadRequest},
+		{"/job/missing/annotation", `{"favorite": true}`, http.StatusNotFound},
+	} {
+		if rec := put(tt.path, tt.body); rec.Code != tt.want {
+			t.Errorf("PUT %s %s status = %d, want %d", tt.path, tt.body, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestCollectionsHandlerSetAnnotation(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+	collections := sqlite.NewCollectionRepository(db)
+	collections.Create(&domain.Collection{ID: "col", Name: "Mix", CreatedAt: time.Now(), UpdatedAt: time.Now()})
+
+	r := chi.NewRouter()
+	NewCollectionsHandler(collections).RegisterRoutes(r)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/collections/col/annotation", strings.NewReader(`{"rating": 5}`)))
+	if got := decodeAnnotationResponse(t, rec); rec.Code != http.StatusOK || got.Rating != 5 {
+		t.Fatalf("status = %d, annotation = %+v", rec.Code, got)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/collections/col", nil))
+	if !strings.Contains(rec.Body.String(), `"rating":5`) {
+		t.Errorf("GET /collections/col does not include the annotation: %s", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/collections/missing/annotation", strings.NewReader(`{"rating": 5}`)))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("missing collection status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+	"video-archiver/internal/domain"
+	"video-archiver/internal/services/tools"
+)
+
+// AutomationHandler exposes CRUD for automation rules and the tools jobs they
+// submitted for a download.
+type AutomationHandler struct {
+	rules     domain.AutomationRepository
+	toolsRepo domain.ToolsRepository
+}
+
+func NewAutomationHandler(rules domain.AutomationRepository, toolsRepo domain.ToolsRepository) *AutomationHandler {
+	return &AutomationHandler{rules: rules, toolsRepo: toolsRepo}
+}
+
+func (h *AutomationHandler) RegisterRoutes(r chi.Router) {
+	r.Route("/automation", func(r chi.Router) {
+		r.Get("/rules", h.HandleList)
+		r.Post("/rules", h.HandleCreate)
+		r.Get("/rules/{id}", h.HandleGet)
+		r.Put("/rules/{id}", h.HandleUpdate)
+		r.Delete("/rules/{id}", h.HandleDelete)
+		r.Get("/jobs/{jobID}", h.HandleListJobs)
+	})
+}
+
+// AutomationRuleRequest is the body for creating or updating a rule. Enabled
+// defaults to true on create and keeps the current value on update.
+type AutomationRuleRequest struct {
+	Name     string                    `json:"name"`
+	Enabled  *bool                     `json:"enabled,omitempty"`
+	Match    domain.AutomationMatch    `json:"match"`
+	Workflow domain.WorkflowParameters `json:"workflow"`
+}
+
+// apply copies the request onto rule and validates the result.
+func (req *AutomationRuleRequest) apply(rule *domain.AutomationRule) error {
+	rule.Name = strings.TrimSpace(req.Name)
+	rule.Match = req.Match
+	rule.Workflow = req.Workflow
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+	return tools.ValidateWorkflow(rule.Workflow)
+}
+
+func (h *AutomationHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.rules.List()
+	if err != nil {
+		log.WithError(err).Error("Failed to list automation rules")
+		http.Error(w, "Failed to list automation rules", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Message: rules})
+}
+
+func (h *AutomationHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	var req AutomationRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	rule := &domain.AutomationRule{
+		ID:        uuid.New().String(),
+		Enabled:   true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := req.apply(rule); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.rules.Create(rule); err != nil {
+		log.WithError(err).Error("Failed to create automation rule")
+		http.Error(w, "Failed to create automation rule", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, Response{Message: rule})
+}
+
+// getRule loads the rule from the URL's {id}, writing the error response
+// itself when the rule can't be served.
+func (h *AutomationHandler) getRule(w http.ResponseWriter, r *http.Request) *domain.AutomationRule {
+	rule, err := h.rules.GetByID(chi.URLParam(r, "id"))
+	if err != nil {
+		log.WithError(err).Error("Failed to get automation rule")
+		http.Error(w, "Failed to get automation rule", http.StatusInternalServerError)
+		return nil
+	}
+	if rule == nil {
+		http.Error(w, "Automation rule not found", http.StatusNotFound)
+		return nil
+	}
+	return rule
+}
+
+func (h *AutomationHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	rule := h.getRule(w, r)
+	if rule == nil {
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Message: rule})
+}
+
+func (h *AutomationHandler) HandleUpdate(w http.ResponseWriter, r *http.Request) {
+	rule := h.getRule(w, r)
+	if rule == nil {
+		return
+	}
+
+	var req AutomationRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	if err := req.apply(rule); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.rules.Update(rule); err != nil {
+		log.WithError(err).Error("Failed to update automation rule")
+		http.Error(w, "Failed to update automation rule", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Message: rule})
+}
+
+func (h *AutomationHandler) HandleDelete(w http.ResponseWriter, r *http.Request) {
+	rule := h.getRule(w, r)
+	if rule == nil {
+		return
+	}
+	if err := h.rules.Delete(rule.ID); err != nil {
+		log.WithError(err).Error("Failed to delete automation rule")
+		http.Error(w, "Failed to delete automation rule", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Message: "Automation rule deleted successfully"})
+}
+
+// HandleListJobs returns the tools jobs automation submitted for a download
+// job, newest first.
+func (h *AutomationHandler) HandleListJobs(w http.ResponseWriter, r *http.Request) {
+	jobs, err := h.toolsRepo.ListBySourceJob(chi.URLParam(r, "jobID"))
+	if err != nil {
+		log.WithError(err).Error("Failed to list automation jobs")
+		http.Error(w, "Failed to list automation jobs", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Message: jobs})
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"video-archiver/internal/domain"
+	"video-archiver/internal/repositories/sqlite"
+	"video-archiver/internal/testutil"
+)
+
+func TestAutomationHandler(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+	toolsRepo := newInMemoryToolsRepo()
+	toolsRepo.Create(&domain.ToolsJob{ID: "tools-1", SourceJobID: "video-1", AutomationRuleID: "rule-1"})
+
+	r := chi.NewRouter()
+	NewAutomationHandler(sqlite.NewAutomationRepository(db), toolsRepo).RegisterRoutes(r)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := do(http.MethodPost, "/automation/rules", `{
+		"name": "Podcasts to mp3",
+		"match": {"tags": ["podcast"]},
+		"workflow": {"steps": [{"operation": "extract_audio", "parameters": {"output_format": "mp3"}}]}
+	}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d (%s)", rec.Code, rec.Body.String())
+	}
+	var created struct {
+		Message domain.AutomationRule `json:"message"`
+	}
+	json.NewDecoder(rec.Body).Decode(&created)
+	rule := created.Message
+	if rule.ID == "" || !rule.Enabled || len(rule.Match.Tags) != 1 || len(rule.Workflow.Steps) != 1 {
+		t.Errorf("created rule = %+v", rule)
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{"missing name", http.MethodPost, "/automation/rules",
+			`{"workflow": {"steps": [{"operation": "rotate", "parameters": {"rotation": 90}}]}}`, http.StatusBadRequest},
+		{"empty workflow", http.MethodPost, "/automation/rules", `{"name": "x", "workflow": {"steps": []}}`, http.StatusBadRequest},
+		{"invalid step", http.MethodPost, "/automation/rules",
+			`{"name": "x", "workflow": {"steps": [{"operation": "extract_audio", "parameters": {"output_format": "mp4"}}]}}`, http.StatusBadRequest},
+		{"disable", http.MethodPut, "/automation/rules/" + rule.ID,
+			`{"name": "Podcasts", "enabled": false, "workflow": {"steps": [{"operation": "extract_audio", "parameters": {"output_format": "mp3"}}]}}`, http.StatusOK},
+		{"get", http.MethodGet, "/automation/rules/" + rule.ID, "", http.StatusOK},
+		{"get missing", http.MethodGet, "/automation/rules/nope", "", http.StatusNotFound},
+		{"list", http.MethodGet, "/automation/rules", "", http.StatusOK},
+		{"jobs for download", http.MethodGet, "/automation/jobs/video-1", "", http.StatusOK},
+		{"delete", http.MethodDelete, "/automation/rules/" + rule.ID, "", http.StatusOK},
+		{"delete missing", http.MethodDelete, "/automation/rules/" + rule.ID, "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := do(tt.method, tt.path, tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.name == "disable" && !strings.Contains(rec.Body.String(), `"enabled":false`) {
+				t.Errorf("rule still enabled: %s", rec.Body.String())
+			}
+			if tt.name == "jobs for download" && !strings.Contains(rec.Body.String(), `"tools-1"`) {
+				t.Errorf("jobs = %s", rec.Body.String())
+			}
+		})
+	}
+}
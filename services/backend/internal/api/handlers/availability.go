@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// availabilityCheckTimeout bounds an on-demand check; a single yt-dlp probe
+// normally takes a few seconds.
+const availabilityCheckTimeout = 2 * time.Minute
+
+// HandleGetAvailability returns the latest upstream availability check of a
+// video, or null when it has not been checked yet.
+func (h *Handler) HandleGetAvailability(w http.ResponseWriter, r *http.Request) {
+	job, _, ok := h.videoJobFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	status, err := h.downloadService.GetRepository().GetAvailability(job.ID)
+	if err != nil {
+		log.WithError(err).Error("Failed to get availability")
+		http.Error(w, "Failed to get availability", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Message: status})
+}
+
+// HandleCheckAvailability checks a video's source right away instead of
+// waiting for the periodic checker.
+func (h *Handler) HandleCheckAvailability(w http.ResponseWriter, r *http.Request) {
+	job, _, ok := h.videoJobFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), availabilityCheckTimeout)
+	defer cancel()
+
+	status, err := h.downloadService.CheckAvailability(ctx, job.ID)
+	if err != nil {
+		log.WithError(err).WithField("jobID", job.ID).Warn("Availability check failed")
+		http.Error(w, "Availability check was inconclusive", http.StatusBadGateway)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Message: status})
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"video-archiver/internal/domain"
+	"video-archiver/internal/testutil"
+)
+
+func TestHandleGetAvailability(t *testing.T) {
+	handler, mockRepo := setupTestHandler(t)
+	r := chi.NewRouter()
+	handler.RegisterRoutes(r)
+
+	video := testutil.CreateTestJob("video-id", "https://youtube.com/watch?v=test")
+	mockRepo.Create(video)
+	mockRepo.StoreMetadata(video.ID, testutil.CreateTestVideoMetadata())
+	mockRepo.RecordAvailability(video.ID, domain.AvailabilityPrivate, "Private video", time.Now())
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/video/video-id/availability", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	var resp struct {
+		Message domain.AvailabilityStatus `json:"message"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.Message.Status != domain.AvailabilityPrivate {
+		t.Errorf("status = %q, want private", resp.Message.Status)
+	}
+}
+
+func TestHandleGetDownloadsAvailabilityFilter(t *testing.T) {
+	handler, _ := setupTestHandler(t)
+	r := chi.NewRouter()
+	handler.RegisterRoutes(r)
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+	}{
+		{"valid", "/downloads/videos?availability=removed", http.StatusOK},
+		{"unknown status", "/downloads/videos?availability=gone", http.StatusBadRequest},
+		{"not videos", "/downloads/playlists?availability=removed", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,373 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+	"video-archiver/internal/domain"
+	"video-archiver/internal/services/tools"
+)
+
+// BookmarksHandler exposes CRUD for timestamped bookmarks of videos and
+// exports them as clips or a highlight reel through the tools service.
+type BookmarksHandler struct {
+	bookmarks domain.BookmarkRepository
+	jobs      domain.JobRepository
+	tools     *tools.Service
+}
+
+func NewBookmarksHandler(bookmarks domain.BookmarkRepository, jobs domain.JobRepository, toolsService *tools.Service) *BookmarksHandler {
+	return &BookmarksHandler{bookmarks: bookmarks, jobs: jobs, tools: toolsService}
+}
+
+func (h *BookmarksHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/video/{jobID}/bookmarks", h.HandleListForVideo)
+	r.Post("/video/{jobID}/bookmarks", h.HandleCreate)
+	r.Route("/bookmarks", func(r chi.Router) {
+		r.Get("/", h.HandleList)
+		r.Post("/export", h.HandleExport)
+		r.Get("/{id}", h.HandleGet)
+		r.Put("/{id}", h.HandleUpdate)
+		r.Delete("/{id}", h.HandleDelete)
+	})
+}
+
+// BookmarkRequest is the body for creating or updating a bookmark. Times
+// are in seconds; leave end out to mark a single moment.
+type BookmarkRequest struct {
+	Start float64  `json:"start"`
+	End   *float64 `json:"end,omitempty"`
+	Label string   `json:"label"`
+	Tags  []string `json:"tags"`
+}
+
+// apply copies the request onto bookmark and validates the result against
+// the video's duration, when known.
+func (req *BookmarkRequest) apply(bookmark *domain.Bookmark, duration float64) error {
+	bookmark.Start = req.Start
+	bookmark.End = req.End
+	bookmark.Label = req.Label
+	bookmark.Tags = req.Tags
+	bookmark.Normalize()
+	if err := bookmark.Validate(); err != nil {
+		return err
+	}
+	if duration > 0 && bookmark.Start >= duration {
+		return fmt.Errorf("start must be before the end of the video")
+	}
+	return nil
+}
+
+// videoDuration loads a video job and returns its duration in seconds,
+// writing the error response itself when it isn't a video.
+func (h *BookmarksHandler) videoDuration(w http.ResponseWriter, jobID string) (float64, bool) {
+	item, err := h.jobs.GetJobWithMetadata(jobID)
+	if err != nil || item == nil || item.Job == nil {
+		http.Error(w, "Video not found", http.StatusNotFound)
+		return 0, false
+	}
+	metadata, ok := item.Metadata.(*domain.VideoMetadata)
+	if !ok {
+		http.Error(w, "Bookmarks are only supported on videos", http.StatusBadRequest)
+		return 0, false
+	}
+	return float64(metadata.Duration), true
+}
+
+// HandleListForVideo returns a video's bookmarks in playback order.
+func (h *BookmarksHandler) HandleListForVideo(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "jobID")
+	if _, ok := h.videoDuration(w, jobID); !ok {
+		return
+	}
+	bookmarks, err := h.bookmarks.ListForVideo(jobID)
+	if err != nil {
+		log.WithError(err).Error("Failed to list bookmarks")
+		http.Error(w, "Failed to list bookmarks", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Message: bookmarks})
+}
+
+func (h *BookmarksHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "jobID")
+	duration, ok := h.videoDuration(w, jobID)
+	if !ok {
+		return
+	}
+
+	var req BookmarkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	bookmark := &domain.Bookmark{
+		ID:        uuid.New().String(),
+		JobID:     jobID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := req.apply(bookmark, duration); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.bookmarks.Create(bookmark); err != nil {
+		log.WithError(err).Error("Failed to create bookmark")
+		http.Error(w, "Failed to create bookmark", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, Response{Message: bookmark})
+}
+
+// HandleList returns all bookmarks, newest first, or those carrying tag.
+func (h *BookmarksHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	bookmarks, err := h.bookmarks.List(domain.NormalizeTagName(r.URL.Query().Get("tag")))
+	if err != nil {
+		log.WithError(err).Error("Failed to list bookmarks")
+		http.Error(w, "Failed to list bookmarks", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Message: bookmarks})
+}
+
+// getBookmark loads the bookmark from the URL's {id}, writing the error
+// response itself when the bookmark can't be served.
+func (h *BookmarksHandler) getBookmark(w http.ResponseWriter, r *http.Request) *domain.Bookmark {
+	bookmark, err := h.bookmarks.GetByID(chi.URLParam(r, "id"))
+	if err != nil {
+		log.WithError(err).Error("Failed to get bookmark")
+		http.Error(w, "Failed to get bookmark", http.StatusInternalServerError)
+		return nil
+	}
+	if bookmark == nil {
+		http.Error(w, "Bookmark not found", http.StatusNotFound)
+		return nil
+	}
+	return bookmark
+}
+
+func (h *BookmarksHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	bookmark := h.getBookmark(w, r)
+	if bookmark == nil {
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Message: bookmark})
+}
+
+func (h *BookmarksHandler) HandleUpdate(w http.ResponseWriter, r *http.Request) {
+	bookmark := h.getBookmark(w, r)
+	if bookmark == nil {
+		return
+	}
+	duration, ok := h.videoDuration(w, bookmark.JobID)
+	if !ok {
+		return
+	}
+
+	var req BookmarkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	if err := req.apply(bookmark, duration); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.bookmarks.Update(bookmark); err != nil {
+		log.WithError(err).Error("Failed to update bookmark")
+		http.Error(w, "Failed to update bookmark", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Message: bookmark})
+}
+
+func (h *BookmarksHandler) HandleDelete(w http.ResponseWriter, r *http.Request) {
+	bookmark := h.getBookmark(w, r)
+	if bookmark == nil {
+		return
+	}
+	if err := h.bookmarks.Delete(bookmark.ID); err != nil {
+		log.WithError(err).Error("Failed to delete bookmark")
+		http.Error(w, "Failed to delete bookmark", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Message: "Bookmark deleted successfully"})
+}
+
+// BookmarkExportRequest selects bookmarks to export. Clips mode trims each
+// into its own file; reel mode joins them, in the order given, into one.
+// Bookmarks without an end run for clip_length seconds (default 30).
+type BookmarkExportRequest struct {
+	BookmarkIDs []string                  `json:"bookmark_ids"`
+	Mode        domain.BookmarkExportMode `json:"mode"`
+	ReEncode    bool                      `json:"re_encode"`
+	ClipLength  float64                   `json:"clip_length,omitempty"`
+	// OutputFormat is the container of a reel; mp4 by default.
+	OutputFormat string `json:"output_format,omitempty"`
+}
+
+// bookmarkClip is a resolved part of a video to export.
+type bookmarkClip struct {
+	jobID      string
+	start, end float64
+}
+
+// HandleExport submits tools jobs for the selected bookmarks and returns
+// them: one trim job per bookmark, or one concat job for a reel.
+func (h *BookmarksHandler) HandleExport(w http.ResponseWriter, r *http.Request) {
+	var req BookmarkExportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	if req.Mode == "" {
+		req.Mode = domain.BookmarkExportClips
+	}
+	if !req.Mode.IsValid() {
+		http.Error(w, "Invalid mode. Must be 'clips' or 'reel'", http.StatusBadRequest)
+		return
+	}
+	if len(req.BookmarkIDs) == 0 {
+		http.Error(w, "No bookmarks selected", http.StatusBadRequest)
+		return
+	}
+	if req.ClipLength < 0 {
+		http.Error(w, "clip_length can't be negative", http.StatusBadRequest)
+		return
+	}
+	if req.ClipLength == 0 {
+		req.ClipLength = domain.DefaultClipLength
+	}
+
+	clips, ok := h.resolveClips(w, req)
+	if !ok {
+		return
+	}
+
+	var jobs []*domain.ToolsJob
+	if req.Mode == domain.BookmarkExportReel {
+		jobs = []*domain.ToolsJob{reelJob(clips, req)}
+	} else {
+		for _, clip := range clips {
+			jobs = append(jobs, &domain.ToolsJob{
+				OperationType: domain.OpTypeTrim,
+				InputFiles:    []string{clip.jobID},
+				InputType:     domain.InputTypeVideos,
+				Parameters: map[string]any{
+					"start_time": formatSeconds(clip.start),
+					"end_time":   formatSeconds(clip.end),
+					"re_encode":  req.ReEncode,
+				},
+			})
+		}
+	}
+
+	// Validate every job up front so a bad clip doesn't leave the ones before
+	// it queued.
+	for i, job := range jobs {
+		if err := tools.ValidateOperation(job.OperationType, job.Parameters); err != nil {
+			http.Error(w, fmt.Sprintf("Export job %d of %d: %v", i+1, len(jobs), err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	var queued []string
+	for i, job := range jobs {
+		if err := h.tools.Submit(job); err != nil {
+			log.WithError(err).WithField("queued", queued).Warn("Failed to submit bookmark export")
+			if len(queued) == 0 {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			http.Error(w, fmt.Sprintf("Failed to submit export job %d of %d: %v; already queued: %s",
+				i+1, len(jobs), err, strings.Join(queued, ", ")), http.StatusInternalServerError)
+			return
+		}
+		queued = append(queued, job.ID)
+	}
+	writeJSON(w, http.StatusAccepted, Response{Message: jobs})
+}
+
+// resolveClips loads the selected bookmarks and their videos' durations,
+// writing the error response itself when one can't be exported.
+func (h *BookmarksHandler) resolveClips(w http.ResponseWriter, req BookmarkExportRequest) ([]bookmarkClip, bool) {
+	durations := map[string]float64{}
+	clips := make([]bookmarkClip, 0, len(req.BookmarkIDs))
+	for _, id := range req.BookmarkIDs {
+		bookmark, err := h.bookmarks.GetByID(id)
+		if err != nil {
+			log.WithError(err).Error("Failed to get bookmark")
+			http.Error(w, "Failed to get bookmark", http.StatusInternalServerError)
+			return nil, false
+		}
+		if bookmark == nil {
+			http.Error(w, fmt.Sprintf("Bookmark %s not found", id), http.StatusNotFound)
+			return nil, false
+		}
+
+		duration, seen := durations[bookmark.JobID]
+		if !seen {
+			var ok bool
+			if duration, ok = h.videoDuration(w, bookmark.JobID); !ok {
+				return nil, false
+			}
+			durations[bookmark.JobID] = duration
+		}
+
+		end := bookmark.ClipEnd(req.ClipLength, duration)
+		if end <= bookmark.Start {
+			http.Error(w, fmt.Sprintf("Bookmark %s starts after the end of its video", id), http.StatusBadRequest)
+			return nil, false
+		}
+		clips = append(clips, bookmarkClip{jobID: bookmark.JobID, start: bookmark.Start, end: end})
+	}
+	return clips, true
+}
+
+// reelJob builds the concat job joining clips into a highlight reel.
+func reelJob(clips []bookmarkClip, req BookmarkExportRequest) *domain.ToolsJob {
+	var inputs []string
+	seen := map[string]bool{}
+	segments := make([]domain.ConcatSegment, 0, len(clips))
+	for _, clip := range clips {
+		if !seen[clip.jobID] {
+			seen[clip.jobID] = true
+			inputs = append(inputs, clip.jobID)
+		}
+		segments = append(segments, domain.ConcatSegment{
+			JobID:     clip.jobID,
+			StartTime: formatSeconds(clip.start),
+			EndTime:   formatSeconds(clip.end),
+		})
+	}
+	format := req.OutputFormat
+	if format == "" {
+		format = "mp4"
+	}
+	return &domain.ToolsJob{
+		OperationType: domain.OpTypeConcat,
+		InputFiles:    inputs,
+		InputType:     domain.InputTypeVideos,
+		Parameters: map[string]any{
+			"output_format": format,
+			"re_encode":     req.ReEncode,
+			"segments":      segments,
+		},
+	}
+}
+
+// formatSeconds renders seconds as a tools timecode.
+func formatSeconds(seconds float64) string {
+	return strconv.FormatFloat(seconds, 'f', -1, 64)
+}
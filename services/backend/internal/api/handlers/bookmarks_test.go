@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"video-archiver/internal/domain"
+	"video-archiver/internal/repositories/sqlite"
+	"video-archiver/internal/services/tools"
+	"video-archiver/internal/testutil"
+)
+
+func newBookmarksTestServer(t *testing.T) (*chi.Mux, *inMemoryToolsRepo) {
+	t.Helper()
+	toolsRepo := newInMemoryToolsRepo()
+	return newBookmarksTestServerWith(t, toolsRepo), toolsRepo
+}
+
+func newBookmarksTestServerWith(t *testing.T, toolsRepo domain.ToolsRepository) *chi.Mux {
+	t.Helper()
+	db := testutil.CreateTestDB(t)
+	t.Cleanup(func() { db.Close() })
+
+	jobs := testutil.NewMockJobRepository()
+	for _, id := range []string{"talk", "stream"} {
+		jobs.Create(testutil.CreateTestJob(id, "https://youtube.com/watch?v="+id))
+		jobs.StoreMetadata(id, testutil.CreateTestVideoMetadata()) // 300s long
+	}
+	jobs.Create(testutil.CreateTestJob("pl", "https://youtube.com/playlist?list=x"))
+	jobs.StoreMetadata("pl", testutil.CreateTestPlaylistMetadata())
+
+	svc := tools.NewService(&tools.Config{
+		ToolsRepository: toolsRepo,
+		JobRepository:   jobs,
+		DownloadPath:    t.TempDir(),
+		ProcessedPath:   t.TempDir(),
+		Concurrency:     1,
+	})
+	r := chi.NewRouter()
+	NewBookmarksHandler(sqlite.NewBookmarkRepository(db), jobs, svc).RegisterRoutes(r)
+	return r
+}
+
+func serveBookmarks(r http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return rec
+}
+
+func decodeBookmark(t *testing.T, rec *httptest.ResponseRecorder) domain.Bookmark {
+	t.Helper()
+	var resp struct {
+		Message domain.Bookmark `json:"message"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode %s: %v", rec.Body.String(), err)
+	}
+	return resp.Message
+}
+
+func TestBookmarksHandlerCRUD(t *testing.T) {
+	r, _ := newBookmarksTestServer(t)
+
+	rec := serveBookmarks(r, http.MethodPost, "/video/talk/bookmarks",
+		`{"start": 120, "end": 150, "label": " Demo ", "tags": ["Highlight", "highlight ", ""]}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	created := decodeBookmark(t, rec)
+	if created.ID == "" || created.JobID != "talk" || created.Label != "Demo" || len(created.Tags) != 1 {
+		t.Errorf("created = %+v", created)
+	}
+	serveBookmarks(r, http.MethodPost, "/video/talk/bookmarks", `{"start": 10, "label": "Intro"}`)
+
+	rec = serveBookmarks(r, http.MethodGet, "/video/talk/bookmarks", "")
+	var list struct {
+		Message []domain.Bookmark `json:"message"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil || len(list.Message) != 2 || list.Message[0].Label != "Intro" {
+		t.Errorf("list for video = %s, %v; want Intro first", rec.Body.String(), err)
+	}
+
+	rec = serveBookmarks(r, http.MethodGet, "/bookmarks?tag=highlight", "")
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil || len(list.Message) != 1 || list.Message[0].ID != created.ID {
+		t.Errorf("list by tag = %s, %v", rec.Body.String(), err)
+	}
+
+	rec = serveBookmarks(r, http.MethodPut, "/bookmarks/"+created.ID, `{"start": 130, "label": "Demo, shorter"}`)
+	if got := decodeBookmark(t, rec); rec.Code != http.StatusOK || got.End != nil || got.Start != 130 {
+		t.Errorf("update status = %d, bookmark = %+v", rec.Code, got)
+	}
+
+	if rec := serveBookmarks(r, http.MethodDelete, "/bookmarks/"+created.ID, ""); rec.Code != http.StatusOK {
+		t.Errorf("delete status = %d", rec.Code)
+	}
+	if rec := serveBookmarks(r, http.MethodGet, "/bookmarks/"+created.ID, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("get deleted status = %d, want 404", rec.Code)
+	}
+
+	for _, tt := range []struct {
+		method, path, body string
+		want               int
+	}{
+		{http.MethodPost, "/video/talk/bookmarks", `{"start": 50, "end": 40}`, http.StatusBadRequest},
+		{http.MethodPost, "/video/talk/bookmarks", `{"start": 400}`, http.StatusBadRequest},
+		{http.MethodPost, "/video/talk/bookmarks", `nope`, http.StatusBadRequest},
+		{http.MethodPost, "/video/pl/bookmarks", `{"start": 1}`, http.StatusBadRequest},
+		{http.MethodPost, "/video/missing/bookmarks", `{"start": 1}`, http.StatusNotFound},
+		{http.MethodPut, "/bookmarks/missing", `{"start": 1}`, http.StatusNotFound},
+	} {
+		if rec := serveBookmarks(r, tt.method, tt.path, tt.body); rec.Code != tt.want {
+			t.Errorf("%s %s %s status = %d, want %d", tt.method, tt.path, tt.body, rec.Code, tt.want)
+		}
+	}
+}
+
+func TestBookmarksHandlerExport(t *testing.T) {
+	r, toolsRepo := newBookmarksTestServer(t)
+
+	var ids []string
+	for _, tt := range []struct{ path, body string }{
+		{"/video/talk/bookmarks", `{"start": 120, "end": 150}`},
+		{"/video/stream/bookmarks", `{"start": 10}`},
+		{"/video/talk/bookmarks", `{"start": 290}`},
+	} {
+		ids = append(ids, decodeBookmark(t, serveBookmarks(r, http.MethodPost, tt.path, tt.body)).ID)
+	}
+	idsJSON, _ := json.Marshal(ids)
+
+	decodeJobs := func(rec *httptest.ResponseRecorder) []domain.ToolsJob {
+		t.Helper()
+		if rec.Code != http.StatusAccepted {
+			t.Fatalf("export status = %d, body = %s", rec.Code, rec.Body.String())
+		}
+		var resp struct {
+			Message []domain.ToolsJob `json:"message"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode %s: %v", rec.Body.String(), err)
+		}
+		return resp.Message
+	}
+
+	clips := decodeJobs(serveBookmarks(r, http.MethodPost, "/bookmarks/export",
+		`{"mode": "clips", "clip_length": 20, "bookmark_ids": `+string(idsJSON)+`}`))
+	if len(clips) != 3 {
+		t.Fatalf("clips = %+v, want 3 trim jobs", clips)
+	}
+	// The last bookmark has no end and is cut short at the end of the video.
+	for i, want := range [][2]string{{"120", "150"}, {"10", "30"}, {"290", "300"}} {
+		job := clips[i]
+		if job.OperationType != domain.OpTypeTrim || job.Parameters["start_time"] != want[0] || job.Parameters["end_time"] != want[1] {
+			t.Errorf("clip %d = %+v, want trim %s-%s", i, job, want[0], want[1])
+		}
+		if stored, _ := toolsRepo.GetByID(job.ID); stored == nil {
+			t.Errorf("clip %d was not submitted", i)
+		}
+	}
+
+	reel := decodeJobs(serveBookmarks(r, http.MethodPost, "/bookmarks/export",
+		`{"mode": "reel", "bookmark_ids": `+string(idsJSON)+`}`))
+	if len(reel) != 1 || reel[0].OperationType != domain.OpTypeConcat {
+		t.Fatalf("reel = %+v, want one concat job", reel)
+	}
+	if got := reel[0].InputFiles; len(got) != 2 || got[0] != "talk" || got[1] != "stream" {
+		t.Errorf("reel inputs = %v, want [talk stream]", got)
+	}
+	segments, _ := reel[0].Parameters["segments"].([]any)
+	if len(segments) != 3 {
+		t.Errorf("reel segments = %v, want 3", reel[0].Parameters["segments"])
+	}
+
+	for _, body := range []string{
+		`{"mode": "montage", "bookmark_ids": ["x"]}`,
+		`{"mode": "clips", "bookmark_ids": []}`,
+		`{"mode": "reel", "bookmark_ids": ["x"], "output_format": "flac"}`,
+	} {
+		rec := serveBookmarks(r, http.MethodPost, "/bookmarks/export", strings.Replace(body, `["x"]`, `["`+ids[0]+`"]`, 1))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("export %s status = %d, want 400", body, rec.Code)
+		}
+	}
+	if rec := serveBookmarks(r, http.MethodPost, "/bookmarks/export", `{"bookmark_ids": ["missing"]}`); rec.Code != http.StatusNotFound {
+		t.Errorf("export of missing bookmark status = %d, want 404", rec.Code)
+	}
+}
+
+// flakyToolsRepo fails every tools job created after the first limit ones.
+type flakyToolsRepo struct {
+	*inMemoryToolsRepo
+	limit int
+}
+
+func (r *flakyToolsRepo) Create(job *domain.ToolsJob) error {
+	r.mu.Lock()
+	full := len(r.jobs) >= r.limit
+	r.mu.Unlock()
+	if full {
+		return errors.New("disk full")
+	}
+	return r.inMemoryToolsRepo.Create(job)
+}
+
+func TestBookmarksHandlerExportPartialSubmit(t *testing.T) {
+	toolsRepo := &flakyToolsRepo{inMemoryToolsRepo: newInMemoryToolsRepo(), limit: 1}
+	r := newBookmarksTestServerWith(t, toolsRepo)
+
+	var ids []string
+	for _, start := range []string{"10", "20"} {
+		ids = append(ids, decodeBookmark(t, serveBookmarks(r, http.MethodPost, "/video/talk/bookmarks", `{"start": `+start+`}`)).ID)
+	}
+	idsJSON, _ := json.Marshal(ids)
+
+	rec := serveBookmarks(r, http.MethodPost, "/bookmarks/export", `{"bookmark_ids": `+string(idsJSON)+`}`)
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("export status = %d, want 500", rec.Code)
+	}
+	toolsRepo.mu.Lock()
+	defer toolsRepo.mu.Unlock()
+	if len(toolsRepo.jobs) != 1 {
+		t.Fatalf("queued jobs = %+v, want the first clip", toolsRepo.jobs)
+	}
+	for id := range toolsRepo.jobs {
+		if !strings.Contains(rec.Body.String(), "already queued: "+id) {
+			t.Errorf("export response %q doesn't name queued job %s", rec.Body.String(), id)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	log "github.com/sirupsen/logrus"
+	"video-archiver/internal/domain"
+	"video-archiver/internal/services/bulk"
+)
+
+// BulkHandler starts bulk operations over library items and reports on them.
+type BulkHandler struct {
+	service    *bulk.Service
+	operations domain.BulkRepository
+}
+
+func NewBulkHandler(service *bulk.Service, operations domain.BulkRepository) *BulkHandler {
+	return &BulkHandler{service: service, operations: operations}
+}
+
+func (h *BulkHandler) RegisterRoutes(r chi.Router) {
+	r.Route("/bulk", func(r chi.Router) {
+		r.Get("/", h.HandleList)
+		r.Post("/", h.HandleStart)
+		r.Get("/{id}", h.HandleGet)
+	})
+}
+
+// HandleStart applies one action to every selected item in the background and
+// returns the operation; progress is broadcast over the WebSocket and the
+// per-item results are read from GET /bulk/{id}.
+func (h *BulkHandler) HandleStart(w http.ResponseWriter, r *http.Request) {
+	var req domain.BulkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := h.service.Validate(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	operation, err := h.service.Start(req)
+	if err != nil {
+		log.WithError(err).Error("Failed to start bulk operation")
+		http.Error(w, "Failed to start bulk operation", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, Response{Message: operation})
+}
+
+// HandleList returns the most recent bulk operations without their items.
+func (h *BulkHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	operations, err := h.operations.List(parseIntQuery(r, "limit", 20))
+	if err != nil {
+		log.WithError(err).Error("Failed to list bulk operations")
+		http.Error(w, "Failed to list bulk operations", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Message: operations})
+}
+
+// HandleGet returns one bulk operation with the result for each item.
+func (h *BulkHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid bulk operation ID", http.StatusBadRequest)
+		return
+	}
+
+	operation, err := h.operations.GetByID(id)
+	if err != nil {
+		log.WithError(err).Error("Failed to get bulk operation")
+		http.Error(w, "Failed to get bulk operation", http.StatusInternalServerError)
+		return
+	}
+	if operation == nil {
+		http.Error(w, "Bulk operation not found", http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Message: operation})
+}
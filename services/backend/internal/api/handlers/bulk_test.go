@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"video-archiver/internal/repositories/sqlite"
+	"video-archiver/internal/services/bulk"
+	"video-archiver/internal/testutil"
+)
+
+func TestBulkHandler(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+	repo := sqlite.NewBulkRepository(db)
+	service := bulk.NewService(&bulk.Config{
+		Repository:           repo,
+		JobRepository:        sqlite.NewJobRepository(db),
+		CollectionRepository: sqlite.NewCollectionRepository(db),
+	})
+
+	r := chi.NewRouter()
+	NewBulkHandler(service, repo).RegisterRoutes(r)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{"start", http.MethodPost, "/bulk", `{"action": "add_tags", "job_ids": ["missing"], "parameters": {"tags": ["x"]}}`, http.StatusAccepted},
+		{"invalid body", http.MethodPost, "/bulk", `{`, http.StatusBadRequest},
+		{"no selection", http.MethodPost, "/bulk", `{"action": "delete"}`, http.StatusBadRequest},
+		{"missing collection", http.MethodPost, "/bulk",
+			`{"action": "add_to_collection", "job_ids": ["a"], "parameters": {"collection_id": "nope"}}`, http.StatusBadRequest},
+		{"tools unavailable", http.MethodPost, "/bulk",
+			`{"action": "tools", "job_ids": ["a"], "parameters": {"operation": "convert"}}`, http.StatusBadRequest},
+		{"list", http.MethodGet, "/bulk", "", http.StatusOK},
+		{"invalid id", http.MethodGet, "/bulk/abc", "", http.StatusBadRequest},
+		{"get missing", http.MethodGet, "/bulk/99", "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := do(tt.method, tt.path, tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+		})
+	}
+
+	defer service.Stop()
+
+	// Wait for the started operation so its report is complete.
+	var rec *httptest.ResponseRecorder
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		rec = do(http.MethodGet, "/bulk/1", "")
+		if !strings.Contains(rec.Body.String(), `"status":"running"`) {
+			break
+		}
+	}
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"failed":1`) ||
+		!strings.Contains(rec.Body.String(), `"job_id":"missing"`) {
+		t.Errorf("GET /bulk/1 = %d %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	log "github.com/sirupsen/logrus"
+	"video-archiver/internal/domain"
+)
+
+// HandleGetChannelOptions returns the tabs and filters a channel job downloads
+// with, or null when it downloads the whole channel.
+func (h *Handler) HandleGetChannelOptions(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.channelFromRequest(w, r); !ok {
+		return
+	}
+
+	options, err := h.downloadService.GetRepository().GetChannelOptions(chi.URLParam(r, "id"))
+	if err != nil {
+		log.WithError(err).Error("Failed to get channel options")
+		http.Error(w, "Failed to get channel options", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Message: options})
+}
+
+// HandleSetChannelOptions replaces a channel job's download options for
+// future re-syncs. A null body removes them.
+func (h *Handler) HandleSetChannelOptions(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.channelFromRequest(w, r); !ok {
+		return
+	}
+
+	var options *domain.ChannelDownloadOptions
+	if err := json.NewDecoder(r.Body).Decode(&options); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if options != nil {
+		if err := options.Validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := h.downloadService.GetRepository().SetChannelOptions(chi.URLParam(r, "id"), options); err != nil {
+		log.WithError(err).Error("Failed to set channel options")
+		http.Error(w, "Failed to set channel options", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Message: options})
+}
+
+// HandleResyncJob downloads a playlist or channel again to pick up new items,
+// applying the options stored on the job.
+func (h *Handler) HandleResyncJob(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.playlistSourceFromRequest(w, r); !ok {
+		return
+	}
+
+	jobID := chi.URLParam(r, "id")
+	job, err := h.downloadService.GetRepository().GetByID(jobID)
+	if err != nil {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+	if job.Status == domain.JobStatusPending || job.Status == domain.JobStatusInProgress {
+		http.Error(w, "Job is already running", http.StatusConflict)
+		return
+	}
+
+	if err := h.downloadService.Resync(jobID); err != nil {
+		log.WithError(err).WithField("jobID", jobID).Error("Failed to re-sync job")
+		http.Error(w, "Failed to re-sync job", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, Response{Message: "Re-sync added to download queue"})
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"video-archiver/internal/domain"
+	"video-archiver/internal/testutil"
+)
+
+func TestHandleChannelOptions(t *testing.T) {
+	handler, mockRepo := setupTestHandler(t)
+	r := chi.NewRouter()
+	handler.RegisterRoutes(r)
+
+	channel := testutil.CreateTestJob("channel-id", "https://youtube.com/@example")
+	mockRepo.Create(channel)
+	mockRepo.StoreMetadata(channel.ID, testutil.CreateTestChannelMetadata())
+
+	playlist := testutil.CreateTestJob("playlist-id", "https://youtube.com/playlist?list=test")
+	mockRepo.Create(playlist)
+	mockRepo.StoreMetadata(playlist.ID, testutil.CreateTestPlaylistMetadata())
+
+	tests := []struct {
+		name       string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{"set", "/job/channel-id/channel-options", `{"tabs": ["videos", "shorts"], "max_items": 10}`, http.StatusOK},
+		{"invalid tab", "/job/channel-id/channel-options", `{"tabs": ["about"]}`, http.StatusBadRequest},
+		{"invalid body", "/job/channel-id/channel-options", `{`, http.StatusBadRequest},
+		{"playlist", "/job/playlist-id/channel-options", `{"max_items": 10}`, http.StatusBadRequest},
+		{"unknown job", "/job/missing/channel-options", `{"max_items": 10}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, tt.path, strings.NewReader(tt.body)))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+		})
+	}
+
+	options, _ := mockRepo.GetChannelOptions(channel.ID)
+	if options == nil || options.MaxItems != 10 || len(options.Tabs) != 2 {
+		t.Errorf("stored options = %+v", options)
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/job/channel-id/channel-options", strings.NewReader(`null`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("clear status = %d, want 200", rec.Code)
+	}
+	if options, _ := mockRepo.GetChannelOptions(channel.ID); options != nil {
+		t.Errorf("options left after clearing: %+v", options)
+	}
+}
+
+func TestHandleDownloadStoresChannelOptions(t *testing.T) {
+	handler, mockRepo := setupTestHandler(t)
+
+	rec := httptest.NewRecorder()
+	body := `{"url": "https://youtube.com/@example", "channel_options": {"tabs": ["streams"], "date_after": "20240101"}}`
+	handler.HandleDownload(rec, httptest.NewRequest(http.MethodPost, "/download", bytes.NewBufferString(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (%s)", rec.Code, rec.Body.String())
+	}
+
+	jobs, _ := mockRepo.GetJobs()
+	if len(jobs) != 1 {
+		t.Fatalf("got %d jobs, want 1", len(jobs))
+	}
+	options, _ := mockRepo.GetChannelOptions(jobs[0].ID)
+	if options == nil || options.DateAfter != "20240101" || options.Tabs[0] != domain.ChannelTabStreams {
+		t.Errorf("stored options = %+v", options)
+	}
+
+	rec = httptest.NewRecorder()
+	body = `{"url": "https://youtube.com/@example", "channel_options": {"date_after": "yesterday"}}`
+	handler.HandleDownload(rec, httptest.NewRequest(http.MethodPost, "/download", bytes.NewBufferString(body)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid options status = %d, want 400", rec.Code)
+	}
+}
+
+func TestHandleResyncJob(t *testing.T) {
+	handler, mockRepo := setupTestHandler(t)
+	r := chi.NewRouter()
+	handler.RegisterRoutes(r)
+
+	channel := testutil.CreateTestJob("channel-id", "https://youtube.com/@example")
+	channel.Status = domain.JobStatusComplete
+	mockRepo.Create(channel)
+	mockRepo.StoreMetadata(channel.ID, testutil.CreateTestChannelMetadata())
+
+	video := testutil.CreateTestJob("video-id", "https://youtube.com/watch?v=test")
+	mockRepo.Create(video)
+	mockRepo.StoreMetadata(video.ID, testutil.CreateTestVideoMetadata())
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/job/channel-id/sync", nil))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202 (%s)", rec.Code, rec.Body.String())
+	}
+	if job, _ := mockRepo.GetByID(channel.ID); job.Status != domain.JobStatusPending {
+		t.Errorf("status after re-sync = %v, want pending", job.Status)
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+	}{
+		{"already queued", "/job/channel-id/sync", http.StatusConflict},
+		{"video", "/job/video-id/sync", http.StatusBadRequest},
+		{"unknown job", "/job/missing/sync", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, tt.path, nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	log "github.com/sirupsen/logrus"
+	"video-archiver/internal/domain"
+	"video-archiver/internal/services/download"
+)
+
+// HandleServeChannelAsset serves archived channel art (avatar or banner) of a
+// channel job. Assets are shared by every job of the same source channel.
+func (h *Handler) HandleServeChannelAsset(w http.ResponseWriter, r *http.Request) {
+	kind := chi.URLParam(r, "kind")
+	if kind != domain.ChannelAssetAvatar && kind != domain.ChannelAssetBanner {
+		http.Error(w, "Unknown asset kind", http.StatusBadRequest)
+		return
+	}
+
+	channel, ok := h.channelFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	path, found := download.ChannelAssetPath(h.downloadPath, channel.ID, kind)
+	if !found {
+		http.Error(w, "Asset not available", http.StatusNotFound)
+		return
+	}
+
+	// A re-sync replaces the art in place, so keep client caching short.
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	http.ServeFile(w, r, path)
+}
+
+// HandleGetChannelHistory returns the about-page history (description and
+// follower count snapshots) of a channel job's source channel, oldest first.
+func (h *Handler) HandleGetChannelHistory(w http.ResponseWriter, r *http.Request) {
+	channel, ok := h.channelFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	snapshots, err := h.downloadService.GetRepository().GetChannelSnapshots(channel.ID)
+	if err != nil {
+		log.WithError(err).Error("Failed to get channel history")
+		http.Error(w, "Failed to get channel history", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Message: snapshots})
+}
+
+// channelFromRequest loads the job named by the id URL parameter and requires
+// it to be a channel. Responds with the appropriate error and returns ok=false
+// when it isn't.
+func (h *Handler) channelFromRequest(w http.ResponseWriter, r *http.Request) (*domain.ChannelMetadata, bool) {
+	jobID := chi.URLParam(r, "id")
+	if jobID == "" {
+		http.Error(w, "Missing job ID", http.StatusBadRequest)
+		return nil, false
+	}
+
+	jobWithMetadata, err := h.downloadService.GetJobWithMetadata(jobID)
+	if err != nil || jobWithMetadata == nil || jobWithMetadata.Job == nil {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return nil, false
+	}
+
+	channel, ok := jobWithMetadata.Metadata.(*domain.ChannelMetadata)
+	if !ok || channel.ID == "" {
+		http.Error(w, "Job is not a channel", http.StatusBadRequest)
+		return nil, false
+	}
+	return channel, true
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"video-archiver/internal/services/download"
+	"video-archiver/internal/testutil"
+)
+
+func TestHandleServeChannelAsset(t *testing.T) {
+	dir := t.TempDir()
+	mockRepo := testutil.NewMockJobRepository()
+	service := download.NewService(&download.Config{JobRepository: mockRepo, DownloadPath: dir})
+	handler := NewHandler(service, dir, newMockSettingsRepository(), nil, nil, nil, nil)
+	r := chi.NewRouter()
+	handler.RegisterRoutes(r)
+
+	channel := testutil.CreateTestJob("channel-id", "https://youtube.com/c/testchannel")
+	mockRepo.Create(channel)
+	meta := testutil.CreateTestChannelMetadata()
+	mockRepo.StoreMetadata(channel.ID, meta)
+
+	video := testutil.CreateTestJob("video-id", "https://youtube.com/watch?v=test")
+	mockRepo.Create(video)
+	mockRepo.StoreMetadata(video.ID, testutil.CreateTestVideoMetadata())
+
+	assetDir, err := download.ChannelAssetDir(dir, meta.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(assetDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(assetDir, "avatar.jpg"), []byte("avatar"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+	}{
+		{"archived avatar", "/job/channel-id/assets/avatar", http.StatusOK},
+		{"banner not archived", "/job/channel-id/assets/banner", http.StatusNotFound},
+		{"unknown kind", "/job/channel-id/assets/cover", http.StatusBadRequest},
+		{"not a channel", "/job/video-id/assets/avatar", http.StatusBadRequest},
+		{"unknown job", "/job/missing/assets/avatar", http.StatusNotFound},
+		{"history", "/job/channel-id/channel-history", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+		})
+	}
+}
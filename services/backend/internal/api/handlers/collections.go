@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 	"time"
@@ -13,7 +14,8 @@ import (
 )
 
 // CollectionsHandler exposes CRUD for collections (user-defined video sets)
-// and their video memberships.
+// and their video memberships. Smart collections get their members from a
+// saved library query and can be frozen into a manual copy.
 type CollectionsHandler struct {
 	collections domain.CollectionRepository
 }
@@ -30,16 +32,23 @@ func (h *CollectionsHandler) RegisterRoutes(r chi.Router) {
 		r.Get("/{id}", h.HandleGet)
 		r.Put("/{id}", h.HandleUpdate)
 		r.Delete("/{id}", h.HandleDelete)
+		r.Post("/{id}/freeze", h.HandleFreeze)
+		r.Put("/{id}/annotation", h.HandleSetAnnotation)
 		r.Get("/{id}/videos", h.HandleGetVideos)
 		r.Post("/{id}/videos", h.HandleAddVideos)
 		r.Delete("/{id}/videos/{videoID}", h.HandleRemoveVideo)
+		r.Put("/{id}/order", h.HandleSetOrder)
+		r.Post("/{id}/videos/{videoID}/move", h.HandleMoveVideo)
 	})
 }
 
-// CollectionRequest is the body for creating or updating a collection.
+// CollectionRequest is the body for creating or updating a collection. A
+// query makes the collection smart; a collection can't change between
+// manual and smart once created.
 type CollectionRequest struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
+	Query       string `json:"query,omitempty"`
 }
 
 // maxCollectionNameLength keeps names displayable; there is no meaningful use
@@ -57,6 +66,19 @@ func (req *CollectionRequest) validate() (string, bool) {
 	return name, true
 }
 
+// validateQuery normalizes the smart collection query, returning an error
+// message for the client when it doesn't parse.
+func (req *CollectionRequest) validateQuery() (string, string) {
+	query := strings.TrimSpace(req.Query)
+	if query == "" {
+		return "", ""
+	}
+	if _, err := domain.ParseCollectionQuery(query); err != nil {
+		return "", "Invalid query: " + err.Error()
+	}
+	return query, ""
+}
+
 func (h *CollectionsHandler) HandleList(w http.ResponseWriter, r *http.Request) {
 	collections, err := h.collections.List()
 	if err != nil {
@@ -78,12 +100,18 @@ func (h *CollectionsHandler) HandleCreate(w http.ResponseWriter, r *http.Request
 		http.Error(w, "Collection name is required", http.StatusBadRequest)
 		return
 	}
+	query, problem := req.validateQuery()
+	if problem != "" {
+		http.Error(w, problem, http.StatusBadRequest)
+		return
+	}
 
 	now := time.Now()
 	collection := &domain.Collection{
 		ID:          uuid.New().String(),
 		Name:        name,
 		Description: strings.TrimSpace(req.Description),
+		Query:       query,
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
@@ -92,7 +120,19 @@ func (h *CollectionsHandler) HandleCreate(w http.ResponseWriter, r *http.Request
 		http.Error(w, "Failed to create collection", http.StatusInternalServerError)
 		return
 	}
-	writeJSON(w, http.StatusCreated, Response{Message: collection})
+	h.writeReloaded(w, http.StatusCreated, collection.ID)
+}
+
+// writeReloaded responds with the collection as stored, including its
+// member count and thumbnail.
+func (h *CollectionsHandler) writeReloaded(w http.ResponseWriter, status int, id string) {
+	collection, err := h.collections.GetByID(id)
+	if err != nil || collection == nil {
+		log.WithError(err).Error("Failed to reload collection")
+		http.Error(w, "Failed to reload collection", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, status, Response{Message: collection})
 }
 
 // getCollection loads the collection from the URL's {id}, writing the error
@@ -140,15 +180,93 @@ func (h *CollectionsHandler) HandleUpdate(w http.ResponseWriter, r *http.Request
 		http.Error(w, "Collection name is required", http.StatusBadRequest)
 		return
 	}
+	query, problem := req.validateQuery()
+	if problem != "" {
+		http.Error(w, problem, http.StatusBadRequest)
+		return
+	}
+	if (query != "") != collection.IsSmart() {
+		http.Error(w, "A collection can't change between manual and smart; freeze a smart collection for a manual copy", http.StatusBadRequest)
+		return
+	}
 
 	collection.Name = name
 	collection.Description = strings.TrimSpace(req.Description)
+	collection.Query = query
 	if err := h.collections.Update(collection); err != nil {
 		log.WithError(err).Error("Failed to update collection")
 		http.Error(w, "Failed to update collection", http.StatusInternalServerError)
 		return
 	}
-	writeJSON(w, http.StatusOK, Response{Message: collection})
+	h.writeReloaded(w, http.StatusOK, collection.ID)
+}
+
+// FreezeCollectionRequest is the optional body for freezing a smart
+// collection.
+type FreezeCollectionRequest struct {
+	Name string `json:"name"`
+}
+
+// HandleFreeze copies the current members of a smart collection into a new
+// manual collection, which keeps them when the query's results change.
+func (h *CollectionsHandler) HandleFreeze(w http.ResponseWriter, r *http.Request) {
+	source := h.getCollection(w, r)
+	if source == nil {
+		return
+	}
+	if !source.IsSmart() {
+		http.Error(w, "Only smart collections can be frozen", http.StatusBadRequest)
+		return
+	}
+
+	var req FreezeCollectionRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request payload", http.StatusBadRequest)
+			return
+		}
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		req.Name = source.Name + " (" + time.Now().Format("2006-01-02") + ")"
+	}
+	name, _ := (&CollectionRequest{Name: req.Name}).validate()
+
+	videos, err := h.collections.GetVideos(source.ID)
+	if err != nil {
+		log.WithError(err).Error("Failed to get collection videos")
+		http.Error(w, "Failed to get collection videos", http.StatusInternalServerError)
+		return
+	}
+
+	now := time.Now()
+	frozen := &domain.Collection{
+		ID:          uuid.New().String(),
+		Name:        name,
+		Description: source.Description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := h.collections.Create(frozen); err != nil {
+		log.WithError(err).Error("Failed to create collection")
+		http.Error(w, "Failed to create collection", http.StatusInternalServerError)
+		return
+	}
+	if len(videos) > 0 {
+		ids := make([]string, 0, len(videos))
+		for _, video := range videos {
+			ids = append(ids, video.Job.ID)
+		}
+		if err := h.collections.AddVideos(frozen.ID, ids); err != nil {
+			log.WithError(err).Error("Failed to add videos to frozen collection")
+			// An empty copy is no snapshot; don't leave it in the library.
+			if err := h.collections.Delete(frozen.ID); err != nil {
+				log.WithError(err).WithField("collectionID", frozen.ID).Warn("Failed to remove incomplete frozen collection")
+			}
+			http.Error(w, "Failed to add videos to collection", http.StatusInternalServerError)
+			return
+		}
+	}
+	h.writeReloaded(w, http.StatusCreated, frozen.ID)
 }
 
 func (h *CollectionsHandler) HandleDelete(w http.ResponseWriter, r *http.Request) {
@@ -164,12 +282,36 @@ func (h *CollectionsHandler) HandleDelete(w http.ResponseWriter, r *http.Request
 	writeJSON(w, http.StatusOK, Response{Message: "Collection deleted successfully"})
 }
 
+// HandleSetAnnotation favorites, rates or takes notes on a collection.
+// Fields left out of the request keep their value.
+func (h *CollectionsHandler) HandleSetAnnotation(w http.ResponseWriter, r *http.Request) {
+	collection := h.getCollection(w, r)
+	if collection == nil {
+		return
+	}
+	annotation, ok := decodeAnnotation(w, r, collection.Annotation)
+	if !ok {
+		return
+	}
+	if err := h.collections.SetAnnotation(collection.ID, annotation); err != nil {
+		log.WithError(err).Error("Failed to set collection annotation")
+		http.Error(w, "Failed to set annotation", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Message: annotation})
+}
+
 func (h *CollectionsHandler) HandleGetVideos(w http.ResponseWriter, r *http.Request) {
 	collection := h.getCollection(w, r)
 	if collection == nil {
 		return
 	}
-	videos, err := h.collections.GetVideos(collection.ID)
+	h.writeVideos(w, collection.ID)
+}
+
+// writeVideos responds with the collection's videos in order.
+func (h *CollectionsHandler) writeVideos(w http.ResponseWriter, id string) {
+	videos, err := h.collections.GetVideos(id)
 	if err != nil {
 		log.WithError(err).Error("Failed to get collection videos")
 		http.Error(w, "Failed to get collection videos", http.StatusInternalServerError)
@@ -198,6 +340,10 @@ func (h *CollectionsHandler) HandleAddVideos(w http.ResponseWriter, r *http.Requ
 		http.Error(w, "At least one video ID is required", http.StatusBadRequest)
 		return
 	}
+	if collection.IsSmart() {
+		http.Error(w, "Smart collections can't be edited by hand", http.StatusConflict)
+		return
+	}
 
 	if err := h.collections.AddVideos(collection.ID, req.VideoIDs); err != nil {
 		// Referencing a job that is not a downloaded video is a client error.
@@ -205,14 +351,7 @@ func (h *CollectionsHandler) HandleAddVideos(w http.ResponseWriter, r *http.Requ
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-
-	updated, err := h.collections.GetByID(collection.ID)
-	if err != nil || updated == nil {
-		log.WithError(err).Error("Failed to reload collection")
-		http.Error(w, "Failed to reload collection", http.StatusInternalServerError)
-		return
-	}
-	writeJSON(w, http.StatusOK, Response{Message: updated})
+	h.writeReloaded(w, http.StatusOK, collection.ID)
 }
 
 func (h *CollectionsHandler) HandleRemoveVideo(w http.ResponseWriter, r *http.Request) {
@@ -225,6 +364,10 @@ func (h *CollectionsHandler) HandleRemoveVideo(w http.ResponseWriter, r *http.Re
 		http.Error(w, "Missing video ID", http.StatusBadRequest)
 		return
 	}
+	if collection.IsSmart() {
+		http.Error(w, "Smart collections can't be edited by hand", http.StatusConflict)
+		return
+	}
 	if err := h.collections.RemoveVideo(collection.ID, videoID); err != nil {
 		log.WithError(err).Error("Failed to remove video from collection")
 		http.Error(w, "Failed to remove video from collection", http.StatusInternalServerError)
@@ -249,3 +392,70 @@ func (h *CollectionsHandler) HandleListForVideo(w http.ResponseWriter, r *http.R
 	}
 	writeJSON(w, http.StatusOK, Response{Message: ids})
 }
+
+// writeOrderError responds to a failed reorder, telling client mistakes from
+// server errors.
+func writeOrderError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrSmartCollection):
+		http.Error(w, "Smart collections can't be edited by hand", http.StatusConflict)
+	case errors.Is(err, domain.ErrCollectionOrder):
+		http.Error(w, "The order must list every video in the collection exactly once", http.StatusBadRequest)
+	case errors.Is(err, domain.ErrNotInCollection):
+		http.Error(w, "Video is not in the collection", http.StatusNotFound)
+	default:
+		log.WithError(err).Error("Failed to reorder collection")
+		http.Error(w, "Failed to reorder collection", http.StatusInternalServerError)
+	}
+}
+
+// CollectionOrderRequest is the body for rearranging a whole collection.
+type CollectionOrderRequest struct {
+	VideoIDs []string `json:"video_ids"`
+}
+
+func (h *CollectionsHandler) HandleSetOrder(w http.ResponseWriter, r *http.Request) {
+	collection := h.getCollection(w, r)
+	if collection == nil {
+		return
+	}
+
+	var req CollectionOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	if err := h.collections.SetOrder(collection.ID, req.VideoIDs); err != nil {
+		writeOrderError(w, err)
+		return
+	}
+	h.writeVideos(w, collection.ID)
+}
+
+// MoveCollectionVideoRequest is the body for moving one video; Position is
+// 1-based and clamped to the collection's bounds.
+type MoveCollectionVideoRequest struct {
+	Position int `json:"position"`
+}
+
+func (h *CollectionsHandler) HandleMoveVideo(w http.ResponseWriter, r *http.Request) {
+	collection := h.getCollection(w, r)
+	if collection == nil {
+		return
+	}
+
+	var req MoveCollectionVideoRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	if req.Position < 1 {
+		http.Error(w, "Position must be at least 1", http.StatusBadRequest)
+		return
+	}
+	if err := h.collections.MoveVideo(collection.ID, chi.URLParam(r, "videoID"), req.Position); err != nil {
+		writeOrderError(w, err)
+		return
+	}
+	h.writeVideos(w, collection.ID)
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"video-archiver/internal/domain"
+	"video-archiver/internal/repositories/sqlite"
+	"video-archiver/internal/testutil"
+)
+
+func TestCollectionsHandlerSmartCollections(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+	jobs := sqlite.NewJobRepository(db)
+	jobs.Create(testutil.CreateTestJob("v1", "https://example.com/v1"))
+	jobs.StoreMetadata("v1", testutil.CreateTestVideoMetadata())
+
+	r := chi.NewRouter()
+	NewCollectionsHandler(sqlite.NewCollectionRepository(db)).RegisterRoutes(r)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+	decode := func(rec *httptest.ResponseRecorder) domain.Collection {
+		var resp struct {
+			Message domain.Collection `json:"message"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode %s: %v", rec.Body.String(), err)
+		}
+		return resp.Message
+	}
+
+	rec := do(http.MethodPost, "/collections", `{"name":"Bad","query":"duration>soon"}`)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "Invalid query") {
+		t.Errorf("invalid query: status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	rec = do(http.MethodPost, "/collections", `{"name":"Test channel","query":"channel:\"test channel\" duration<10m"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	smart := decode(rec)
+	if smart.VideoCount != 1 || !smart.IsSmart() {
+		t.Errorf("created = %+v, want a smart collection with 1 video", smart)
+	}
+
+	rec = do(http.MethodPost, "/collections/"+smart.ID+"/videos", `{"video_ids":["v1"]}`)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("add videos: status = %d, want %d", rec.Code, http.StatusConflict)
+	}
+	rec = do(http.MethodPut, "/collections/"+smart.ID, `{"name":"Manual now"}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("update to manual: status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	rec = do(http.MethodPost, "/collections/"+smart.ID+"/freeze", `{"name":"Snapshot"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("freeze: status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	frozen := decode(rec)
+	if frozen.IsSmart() || frozen.Name != "Snapshot" || frozen.VideoCount != 1 {
+		t.Errorf("frozen = %+v, want a manual copy with 1 video", frozen)
+	}
+
+	rec = do(http.MethodPost, "/collections/"+frozen.ID+"/freeze", "")
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("freeze manual: status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+// failingAddCollections fails every AddVideos call.
+type failingAddCollections struct {
+	*sqlite.CollectionRepository
+}
+
+func (failingAddCollections) AddVideos(string, []string) error {
+	return errors.New("disk full")
+}
+
+func TestCollectionsHandlerFreezeFailureLeavesNoCollection(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+	jobs := sqlite.NewJobRepository(db)
+	jobs.Create(testutil.CreateTestJob("v1", "https://example.com/v1"))
+	jobs.StoreMetadata("v1", testutil.CreateTestVideoMetadata())
+	collections := sqlite.NewCollectionRepository(db)
+	now := time.Now()
+	collections.Create(&domain.Collection{ID: "smart", Name: "Smart", Query: `channel:"test channel"`, CreatedAt: now, UpdatedAt: now})
+
+	r := chi.NewRouter()
+	NewCollectionsHandler(failingAddCollections{collections}).RegisterRoutes(r)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/collections/smart/freeze", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("freeze: status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	if all, _ := collections.List(); len(all) != 1 {
+		t.Errorf("collections after failed freeze = %d, want only the smart one", len(all))
+	}
+}
+
+func TestCollectionsHandlerOrdering(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+	jobs := sqlite.NewJobRepository(db)
+	collections := sqlite.NewCollectionRepository(db)
+	for _, id := range []string{"a", "b", "c"} {
+		jobs.Create(testutil.CreateTestJob(id, "https://example.com/"+id))
+		meta := testutil.CreateTestVideoMetadata()
+		meta.ID = id
+		jobs.StoreMetadata(id, meta)
+	}
+	collections.Create(&domain.Collection{ID: "col", Name: "Mix"})
+	collections.AddVideos("col", []string{"a", "b", "c"})
+
+	r := chi.NewRouter()
+	NewCollectionsHandler(collections).RegisterRoutes(r)
+
+	do := func(method, path, body string) (int, string) {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		var resp struct {
+			Message []domain.JobWithMetadata `json:"message"`
+		}
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		var ids []string
+		for _, video := range resp.Message {
+			ids = append(ids, video.Job.ID)
+		}
+		return rec.Code, strings.Join(ids, ",")
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantOrder  string
+	}{
+		{"set order", http.MethodPut, "/collections/col/order", `{"video_ids":["c","a","b"]}`, http.StatusOK, "c,a,b"},
+		{"incomplete order", http.MethodPut, "/collections/col/order", `{"video_ids":["c","a"]}`, http.StatusBadRequest, ""},
+		{"move", http.MethodPost, "/collections/col/videos/b/move", `{"position":1}`, http.StatusOK, "b,c,a"},
+		{"move non-member", http.MethodPost, "/collections/col/videos/x/move", `{"position":1}`, http.StatusNotFound, ""},
+		{"move to zero", http.MethodPost, "/collections/col/videos/b/move", `{"position":0}`, http.StatusBadRequest, ""},
+		{"missing collection", http.MethodPut, "/collections/nope/order", `{"video_ids":[]}`, http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, order := do(tt.method, tt.path, tt.body)
+			if status != tt.wantStatus || order != tt.wantOrder {
+				t.Errorf("%s %s = %d %q, want %d %q", tt.method, tt.path, status, order, tt.wantStatus, tt.wantOrder)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+	"video-archiver/internal/domain"
+)
+
+// HandleGetComments returns a video's archived comments as a page of threads
+// (top-level comments with their replies). Supports ?sort=top|newest|oldest,
+// ?search= over comment text and authors, and page/limit.
+func (h *Handler) HandleGetComments(w http.ResponseWriter, r *http.Request) {
+	job, _, ok := h.videoJobFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	sort := r.URL.Query().Get("sort")
+	switch sort {
+	case "":
+		sort = domain.CommentSortTop
+	case domain.CommentSortTop, domain.CommentSortNewest, domain.CommentSortOldest:
+	default:
+		http.Error(w, "Invalid sort. Must be 'top', 'newest', or 'oldest'", http.StatusBadRequest)
+		return
+	}
+
+	page := parseIntQuery(r, "page", 1)
+	limit := parseIntQuery(r, "limit", 20)
+	if limit > 100 {
+		limit = 20
+	}
+
+	comments, totalCount, err := h.downloadService.GetRepository().GetComments(job.ID, domain.CommentQuery{
+		Page:   page,
+		Limit:  limit,
+		Sort:   sort,
+		Search: r.URL.Query().Get("search"),
+	})
+	if err != nil {
+		log.WithError(err).Error("Failed to get comments")
+		http.Error(w, "Failed to get comments", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Message: struct {
+		Items      []domain.Comment `json:"items"`
+		TotalCount int              `json:"total_count"`
+		Page       int              `json:"page"`
+		Limit      int              `json:"limit"`
+		TotalPages int              `json:"total_pages"`
+	}{comments, totalCount, page, limit, (totalCount + limit - 1) / limit}})
+}
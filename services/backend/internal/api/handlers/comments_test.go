@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"video-archiver/internal/domain"
+	"video-archiver/internal/testutil"
+)
+
+func TestHandleGetComments(t *testing.T) {
+	handler, mockRepo := setupTestHandler(t)
+	r := chi.NewRouter()
+	handler.RegisterRoutes(r)
+
+	video := testutil.CreateTestJob("video-id", "https://youtube.com/watch?v=test")
+	mockRepo.Create(video)
+	mockRepo.StoreMetadata(video.ID, testutil.CreateTestVideoMetadata())
+	mockRepo.StoreComments(video.ID, []domain.Comment{
+		{ID: "c1", Text: "hello"},
+		{ID: "c1.r", ParentID: "c1", Text: "reply"},
+	})
+
+	playlist := testutil.CreateTestJob("playlist-id", "https://youtube.com/playlist?list=test")
+	mockRepo.Create(playlist)
+	mockRepo.StoreMetadata(playlist.ID, testutil.CreateTestPlaylistMetadata())
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+	}{
+		{"video", "/video/video-id/comments?sort=newest&search=hel", http.StatusOK},
+		{"invalid sort", "/video/video-id/comments?sort=best", http.StatusBadRequest},
+		{"not a video", "/video/playlist-id/comments", http.StatusBadRequest},
+		{"unknown job", "/video/missing/comments", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var resp struct {
+				Message struct {
+					Items      []domain.Comment `json:"items"`
+					TotalCount int              `json:"total_count"`
+				} `json:"message"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if resp.Message.TotalCount != 1 || len(resp.Message.Items) != 1 {
+				t.Errorf("got %d items (total %d), want 1", len(resp.Message.Items), resp.Message.TotalCount)
+			}
+		})
+	}
+}
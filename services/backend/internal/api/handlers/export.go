@@ -0,0 +1,411 @@
+package handlers
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	log "github.com/sirupsen/logrus"
+	"video-archiver/internal/domain"
+	"video-archiver/internal/services/export"
+	"video-archiver/internal/util/signedurl"
+)
+
+const (
+	// defaultSignedLinkTTL keeps signed links in an exported playlist
+	// playable for a week unless expires_in says otherwise.
+	defaultSignedLinkTTL = 7 * 24 * time.Hour
+	maxSignedLinkTTL     = 365 * 24 * time.Hour
+)
+
+// ExportHandler exports collections, playlists and channels as M3U8, XSPF or
+// a JSON manifest whose links stream from /video/{jobID}, so external
+// players can play the archive directly, or as a zip of the files
+// themselves.
+type ExportHandler struct {
+	jobs        domain.JobRepository
+	collections domain.CollectionRepository
+	archives    *export.Service
+	signer      *signedurl.Signer
+	// publicURL overrides the base URL of the links; see baseURL.
+	publicURL string
+}
+
+func NewExportHandler(jobs domain.JobRepository, collections domain.CollectionRepository,
+	archives *export.Service, signer *signedurl.Signer, publicURL string) *ExportHandler {
+	return &ExportHandler{
+		jobs:        jobs,
+		collections: collections,
+		archives:    archives,
+		signer:      signer,
+		publicURL:   strings.TrimRight(publicURL, "/"),
+	}
+}
+
+func (h *ExportHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/export/collection/{id}", h.HandleExportCollection)
+	r.Get("/export/job/{id}", h.HandleExportJob)
+	r.Get("/collections/{id}/export.zip", h.HandleExportCollectionZip)
+	r.Get("/job/{id}/export.zip", h.HandleExportJobZip)
+}
+
+// exportOptions are the query parameters shared by the export endpoints:
+// format (m3u8, xspf or json; default m3u8), and signed with an optional
+// expires_in duration for signed links.
+type exportOptions struct {
+	format domain.ExportFormat
+	signed bool
+	ttl    time.Duration
+}
+
+func (h *ExportHandler) parseOptions(w http.ResponseWriter, r *http.Request) (exportOptions, bool) {
+	query := r.URL.Query()
+	opts := exportOptions{format: domain.ExportFormat(strings.ToLower(query.Get("format"))), ttl: defaultSignedLinkTTL}
+	if opts.format == "" {
+		opts.format = domain.ExportFormatM3U8
+	}
+	if !opts.format.IsValid() {
+		http.Error(w, "Invalid format. Must be 'm3u8', 'xspf' or 'json'", http.StatusBadRequest)
+		return opts, false
+	}
+
+	opts.signed = query.Get("signed") == "true" || query.Get("signed") == "1"
+	if !opts.signed {
+		return opts, true
+	}
+	if h.signer == nil {
+		http.Error(w, "Signed links are not enabled; set URL_SIGNING_KEY", http.StatusBadRequest)
+		return opts, false
+	}
+	if value := query.Get("expires_in"); value != "" {
+		ttl, err := time.ParseDuration(value)
+		if err != nil || ttl <= 0 || ttl > maxSignedLinkTTL {
+			http.Error(w, "Invalid expires_in, use a duration such as 24h, up to 8760h", http.StatusBadRequest)
+			return opts, false
+		}
+		opts.ttl = ttl
+	}
+	return opts, true
+}
+
+// collectionSource loads the collection from the URL's {id}, writing the
+// error response itself when it can't be exported.
+func (h *ExportHandler) collectionSource(w http.ResponseWriter, r *http.Request) (export.Source, bool) {
+	collection, err := h.collections.GetByID(chi.URLParam(r, "id"))
+	if err != nil {
+		log.WithError(err).Error("Failed to get collection")
+		http.Error(w, "Failed to get collection", http.StatusInternalServerError)
+		return export.Source{}, false
+	}
+	if collection == nil {
+		http.Error(w, "Collection not found", http.StatusNotFound)
+		return export.Source{}, false
+	}
+	videos, err := h.collections.GetVideos(collection.ID)
+	if err != nil {
+		log.WithError(err).Error("Failed to get collection videos")
+		http.Error(w, "Failed to get collection videos", http.StatusInternalServerError)
+		return export.Source{}, false
+	}
+	return export.Source{Kind: "collection", ID: collection.ID, Title: collection.Name, Videos: videos}, true
+}
+
+// jobSource loads the playlist or channel from the URL's {id} with its
+// videos in source order, writing the error response itself when it can't
+// be exported.
+func (h *ExportHandler) jobSource(w http.ResponseWriter, r *http.Request) (export.Source, bool) {
+	job, err := h.jobs.GetJobWithMetadata(chi.URLParam(r, "id"))
+	if err != nil || job == nil || job.Job == nil {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return export.Source{}, false
+	}
+
+	source := export.Source{ID: job.Job.ID}
+	switch metadata := job.Metadata.(type) {
+	case *domain.PlaylistMetadata:
+		source.Kind, source.Title = "playlist", metadata.Title
+	case *domain.ChannelMetadata:
+		source.Kind, source.Title = "channel", metadata.Channel
+	default:
+		http.Error(w, "Only playlists and channels can be exported; use a collection for single videos", http.StatusBadRequest)
+		return export.Source{}, false
+	}
+
+	source.Videos, err = h.jobs.GetVideosForParent(job.Job.ID)
+	if err != nil {
+		log.WithError(err).Error("Failed to get videos for parent")
+		http.Error(w, "Failed to get videos for parent", http.StatusInternalServerError)
+		return export.Source{}, false
+	}
+	return source, true
+}
+
+func (h *ExportHandler) HandleExportCollection(w http.ResponseWriter, r *http.Request) {
+	opts, ok := h.parseOptions(w, r)
+	if !ok {
+		return
+	}
+	if source, ok := h.collectionSource(w, r); ok {
+		h.writeExport(w, r, opts, source)
+	}
+}
+
+// HandleExportJob exports the downloaded videos of a playlist or channel in
+// source order.
+func (h *ExportHandler) HandleExportJob(w http.ResponseWriter, r *http.Request) {
+	opts, ok := h.parseOptions(w, r)
+	if !ok {
+		return
+	}
+	if source, ok := h.jobSource(w, r); ok {
+		h.writeExport(w, r, opts, source)
+	}
+}
+
+func (h *ExportHandler) writeExport(w http.ResponseWriter, r *http.Request, opts exportOptions, source export.Source) {
+	base := h.baseURL(r)
+	playlist := domain.PlaylistExport{
+		Kind:       source.Kind,
+		ID:         source.ID,
+		Title:      source.Title,
+		ExportedAt: time.Now(),
+		Items:      []domain.PlaylistExportItem{},
+	}
+	for _, video := range source.Videos {
+		metadata, ok := video.Metadata.(*domain.VideoMetadata)
+		// Only downloaded videos are playable.
+		if !ok || video.Job.Status != domain.JobStatusComplete {
+			continue
+		}
+		link := base + videoURLPath(video.Job.ID)
+		thumbnail := base + thumbnailURLPath(video.Job.ID)
+		if opts.signed {
+			link += "?" + h.signer.Sign(videoURLPath(video.Job.ID), opts.ttl).Encode()
+			thumbnail += "?" + h.signer.Sign(thumbnailURLPath(video.Job.ID), opts.ttl).Encode()
+		}
+		item := domain.PlaylistExportItem{
+			JobID:     video.Job.ID,
+			Title:     metadata.Title,
+			Channel:   firstNonEmpty(metadata.Channel, metadata.Uploader),
+			Duration:  metadata.Duration,
+			URL:       link,
+			Thumbnail: thumbnail,
+			SourceURL: video.Job.URL,
+		}
+		item.Annotate(video.Annotation)
+		playlist.Items = append(playlist.Items, item)
+	}
+
+	var body []byte
+	var contentType string
+	switch opts.format {
+	case domain.ExportFormatM3U8:
+		body, contentType = renderM3U8(playlist), "application/vnd.apple.mpegurl"
+	case domain.ExportFormatXSPF:
+		var err error
+		body, err = renderXSPF(playlist)
+		if err != nil {
+			log.WithError(err).Error("Failed to render XSPF playlist")
+			http.Error(w, "Failed to export playlist", http.StatusInternalServerError)
+			return
+		}
+		contentType = "application/xspf+xml"
+	case domain.ExportFormatJSON:
+		body, _ = json.MarshalIndent(playlist, "", "  ")
+		contentType = "application/json"
+	}
+
+	filename := exportFilename(source.Title) + "." + string(opts.format)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+func (h *ExportHandler) HandleExportCollectionZip(w http.ResponseWriter, r *http.Request) {
+	naming, ok := parseNaming(w, r)
+	if !ok {
+		return
+	}
+	if source, ok := h.collectionSource(w, r); ok {
+		h.writeZip(w, r, source, naming)
+	}
+}
+
+// HandleExportJobZip is HandleExportCollectionZip for playlists and
+// channels.
+func (h *ExportHandler) HandleExportJobZip(w http.ResponseWriter, r *http.Request) {
+	naming, ok := parseNaming(w, r)
+	if !ok {
+		return
+	}
+	if source, ok := h.jobSource(w, r); ok {
+		h.writeZip(w, r, source, naming)
+	}
+}
+
+// parseNaming reads the optional naming query parameter, the file name
+// template of the archive entries (see export.Naming).
+func parseNaming(w http.ResponseWriter, r *http.Request) (export.Naming, bool) {
+	naming, err := export.ParseNaming(r.URL.Query().Get("naming"))
+	if err != nil {
+		http.Error(w, "Invalid naming: "+err.Error(), http.StatusBadRequest)
+		return naming, false
+	}
+	return naming, true
+}
+
+// writeZip streams the source's files as a zip. Once the archive is cached,
+// requests for the same content are served from the cache, with range
+// requests so interrupted downloads can resume; the ETag identifies the
+// content for If-Range.
+func (h *ExportHandler) writeZip(w http.ResponseWriter, r *http.Request, source export.Source, naming export.Naming) {
+	archive, err := h.archives.Plan(source, naming)
+	if err != nil {
+		log.WithError(err).Error("Failed to plan zip export")
+		http.Error(w, "Failed to export archive", http.StatusInternalServerError)
+		return
+	}
+
+	filename := exportFilename(source.Title) + ".zip"
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	w.Header().Set("ETag", `"`+archive.Key+`"`)
+
+	if f, info, ok := h.archives.Cached(archive); ok {
+		defer f.Close()
+		http.ServeContent(w, r, filename, info.ModTime(), f)
+		return
+	}
+
+	// Until the archive is cached, any range is answered with the whole
+	// archive, which HTTP allows.
+	w.WriteHeader(http.StatusOK)
+	if err := h.archives.Write(w, archive); err != nil {
+		log.WithError(err).WithField("id", source.ID).Warn("Zip export ended early")
+	}
+}
+
+// baseURL is where players reach this backend: PUBLIC_URL when configured,
+// otherwise the request's origin as seen through any proxy, including the
+// path prefix it strips (X-Forwarded-Prefix).
+func (h *ExportHandler) baseURL(r *http.Request) string {
+	if h.publicURL != "" {
+		return h.publicURL
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = strings.TrimSpace(strings.Split(proto, ",")[0])
+	}
+	host := r.Host
+	if forwarded := r.Header.Get("X-Forwarded-Host"); forwarded != "" {
+		host = strings.TrimSpace(strings.Split(forwarded, ",")[0])
+	}
+	return scheme + "://" + host + strings.TrimRight(r.Header.Get("X-Forwarded-Prefix"), "/")
+}
+
+// videoURLPath is the route streaming a video; signed links sign this path.
+func videoURLPath(jobID string) string {
+	return "/video/" + url.PathEscape(jobID)
+}
+
+// thumbnailURLPath is the route serving a job's thumbnail; signed links sign
+// this path.
+func thumbnailURLPath(jobID string) string {
+	return "/job/" + url.PathEscape(jobID) + "/thumbnail"
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// oneLine keeps titles from breaking the line-based M3U format.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func renderM3U8(export domain.PlaylistExport) []byte {
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n")
+	if export.Title != "" {
+		fmt.Fprintf(&b, "#PLAYLIST:%s\n", oneLine(export.Title))
+	}
+	for _, item := range export.Items {
+		duration := item.Duration
+		if duration == 0 {
+			duration = -1
+		}
+		name := oneLine(item.Title)
+		if item.Channel != "" {
+			name = oneLine(item.Channel) + " - " + name
+		}
+		fmt.Fprintf(&b, "#EXTINF:%d,%s\n%s\n", duration, name, item.URL)
+	}
+	return []byte(b.String())
+}
+
+type xspfPlaylist struct {
+	XMLName   xml.Name `xml:"playlist"`
+	Version   string   `xml:"version,attr"`
+	Namespace string   `xml:"xmlns,attr"`
+	Title     string   `xml:"title,omitempty"`
+	TrackList struct {
+		Tracks []xspfTrack `xml:"track"`
+	} `xml:"trackList"`
+}
+
+type xspfTrack struct {
+	Location string `xml:"location"`
+	Title    string `xml:"title,omitempty"`
+	Creator  string `xml:"creator,omitempty"`
+	// Duration is in milliseconds.
+	Duration int    `xml:"duration,omitempty"`
+	Image    string `xml:"image,omitempty"`
+	// Annotation is the video's notes.
+	Annotation string `xml:"annotation,omitempty"`
+}
+
+func renderXSPF(export domain.PlaylistExport) ([]byte, error) {
+	playlist := xspfPlaylist{Version: "1", Namespace: "http://xspf.org/ns/0/", Title: export.Title}
+	for _, item := range export.Items {
+		playlist.TrackList.Tracks = append(playlist.TrackList.Tracks, xspfTrack{
+			Location:   item.URL,
+			Title:      item.Title,
+			Creator:    item.Channel,
+			Duration:   item.Duration * 1000,
+			Image:      item.Thumbnail,
+			Annotation: item.Notes,
+		})
+	}
+	body, err := xml.MarshalIndent(playlist, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(body, '\n')...), nil
+}
+
+var unsafeFilenameChars = regexp.MustCompile(`[^\p{L}\p{N} ._-]+`)
+
+// exportFilename turns a title into a portable file name.
+func exportFilename(title string) string {
+	name := strings.Trim(oneLine(unsafeFilenameChars.ReplaceAllString(title, " ")), " .")
+	if name == "" {
+		return "playlist"
+	}
+	return name
+}
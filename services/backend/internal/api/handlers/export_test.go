@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"video-archiver/internal/domain"
+	"video-archiver/internal/repositories/sqlite"
+	"video-archiver/internal/services/export"
+	"video-archiver/internal/testutil"
+	"video-archiver/internal/util/signedurl"
+)
+
+// newExportTestRouter serves exports over a library holding a playlist
+// (pl) of two downloaded videos (v1, v2) and a pending one (v3), and a
+// collection (col) holding v2. The downloaded videos have media files with
+// an .info.json, thumbnail and English subtitles under dir.
+func newExportTestRouter(t *testing.T, signer *signedurl.Signer, publicURL string) (http.Handler, string) {
+	t.Helper()
+	dir := t.TempDir()
+	db := testutil.CreateTestDB(t)
+	t.Cleanup(func() { db.Close() })
+	jobs := sqlite.NewJobRepository(db)
+	collections := sqlite.NewCollectionRepository(db)
+
+	jobs.Create(testutil.CreateTestJob("pl", "https://example.com/pl"))
+	jobs.StoreMetadata("pl", testutil.CreateTestPlaylistMetadata())
+	for _, id := range []string{"v1", "v2", "v3"} {
+		job := testutil.CreateTestJob(id, "https://example.com/"+id)
+		if id != "v3" {
+			job.Status = domain.JobStatusComplete
+		}
+		jobs.Create(job)
+		meta := testutil.CreateTestVideoMetadata()
+		meta.ID, meta.Title = id, "Video "+id
+		jobs.StoreMetadata(id, meta)
+		jobs.AddVideoToParent(id, "pl", "playlist")
+		if id != "v3" {
+			stem := filepath.Join(dir, "Test Channel", meta.Title)
+			os.MkdirAll(filepath.Dir(stem), 0755)
+			for _, suffix := range []string{".mp4", ".info.json", ".jpg", ".en.vtt"} {
+				os.WriteFile(stem+suffix, []byte(id+suffix), 0644)
+			}
+			jobs.SetFilePath(id, stem+".mp4")
+		}
+	}
+	collections.Create(&domain.Collection{ID: "col", Name: "My mix: best/of", CreatedAt: time.Now(), UpdatedAt: time.Now()})
+	collections.AddVideos("col", []string{"v2"})
+	jobs.SetAnnotation("v2", &domain.Annotation{Rating: 4, Notes: "Best part at 3:00", UpdatedAt: time.Now()})
+
+	archives := export.NewService(&export.Config{DownloadPath: dir, CachePath: filepath.Join(dir, ".exports")})
+	r := chi.NewRouter()
+	NewExportHandler(jobs, collections, archives, signer, publicURL).RegisterRoutes(r)
+	return r, dir
+}
+
+func getExport(r http.Handler, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Host = "archive.local:8080"
+	req.Header.Set("X-Forwarded-Prefix", "/api")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestExportFormats(t *testing.T) {
+	r, _ := newExportTestRouter(t, nil, "")
+
+	rec := getExport(r, "/export/job/pl")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("m3u8 status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	want := "#EXTM3U\n#PLAYLIST:Test Playlist\n" +
+		"#EXTINF:300,Test Channel - Video v1\nhttp://archive.local:8080/api/video/v1\n" +
+		"#EXTINF:300,Test Channel - Video v2\nhttp://archive.local:8080/api/video/v2\n"
+	if rec.Body.String() != want {
+		t.Errorf("m3u8 body =\n%s\nwant\n%s", rec.Body.String(), want)
+	}
+	if got := rec.Header().Get("Content-Disposition"); got != `attachment; filename="Test Playlist.m3u8"` {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+
+	rec = getExport(r, "/export/collection/col?format=xspf")
+	body := rec.Body.String()
+	if rec.Code != http.StatusOK || !strings.Contains(body, `<playlist version="1" xmlns="http://xspf.org/ns/0/">`) ||
+		!strings.Contains(body, "<location>http://archive.local:8080/api/video/v2</location>") ||
+		!strings.Contains(body, "<duration>300000</duration>") ||
+		!strings.Contains(body, "<annotation>Best part at 3:00</annotation>") {
+		t.Errorf("xspf status = %d, body = %s", rec.Code, body)
+	}
+	if got := rec.Header().Get("Content-Disposition"); got != `attachment; filename="My mix best of.xspf"` {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+
+	rec = getExport(r, "/export/collection/col?format=json")
+	var manifest domain.PlaylistExport
+	if err := json.Unmarshal(rec.Body.Bytes(), &manifest); err != nil {
+		t.Fatalf("decode manifest: %v (%s)", err, rec.Body.String())
+	}
+	if manifest.Kind != "collection" || len(manifest.Items) != 1 ||
+		manifest.Items[0].Thumbnail != "http://archive.local:8080/api/job/v2/thumbnail" ||
+		manifest.Items[0].SourceURL != "https://example.com/v2" ||
+		manifest.Items[0].Rating != 4 || manifest.Items[0].Notes != "Best part at 3:00" {
+		t.Errorf("manifest = %+v", manifest)
+	}
+
+	for path, status := range map[string]int{
+		"/export/job/pl?format=pls":       http.StatusBadRequest,
+		"/export/job/v1":                  http.StatusBadRequest,
+		"/export/job/missing":             http.StatusNotFound,
+		"/export/collection/missing":      http.StatusNotFound,
+		"/export/collection/col?signed=1": http.StatusBadRequest,
+	} {
+		if rec := getExport(r, path); rec.Code != status {
+			t.Errorf("GET %s status = %d, want %d", path, rec.Code, status)
+		}
+	}
+}
+
+func TestExportSignedLinks(t *testing.T) {
+	signer := signedurl.New("secret")
+	r, _ := newExportTestRouter(t, signer, "https://videos.example.com/api/")
+
+	rec := getExport(r, "/export/collection/col?format=json&signed=true&expires_in=1h")
+	var manifest domain.PlaylistExport
+	if err := json.Unmarshal(rec.Body.Bytes(), &manifest); err != nil || len(manifest.Items) != 1 {
+		t.Fatalf("decode manifest: %v (%s)", err, rec.Body.String())
+	}
+	link, err := url.Parse(manifest.Items[0].URL)
+	if err != nil {
+		t.Fatalf("parse %q: %v", manifest.Items[0].URL, err)
+	}
+	if link.Host != "videos.example.com" || link.Path != "/api/video/v2" {
+		t.Errorf("link = %s, want it under PUBLIC_URL", link)
+	}
+	if err := signer.Verify("/video/v2", link.Query()); err != nil {
+		t.Errorf("Verify(link) error = %v", err)
+	}
+	thumbnail, err := url.Parse(manifest.Items[0].Thumbnail)
+	if err != nil {
+		t.Fatalf("parse %q: %v", manifest.Items[0].Thumbnail, err)
+	}
+	if thumbnail.Path != "/api/job/v2/thumbnail" {
+		t.Errorf("thumbnail = %s, want it under PUBLIC_URL", thumbnail)
+	}
+	if err := signer.Verify("/job/v2/thumbnail", thumbnail.Query()); err != nil {
+		t.Errorf("Verify(thumbnail) error = %v", err)
+	}
+
+	if rec := getExport(r, "/export/collection/col?signed=true&expires_in=forever"); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid expires_in status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestExportZip(t *testing.T) {
+	r, dir := newExportTestRouter(t, nil, "")
+
+	rec := getExport(r, "/job/pl/export.zip?naming={index}+{channel}/{title}")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/zip" {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	etag := rec.Header().Get("ETag")
+	body := rec.Body.Bytes()
+	zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Fatalf("read zip: %v", err)
+	}
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	want := []string{
+		"1 Test Channel/Video v1.mp4", "1 Test Channel/Video v1.info.json", "1 Test Channel/Video v1.jpg", "1 Test Channel/Video v1.en.vtt",
+		"2 Test Channel/Video v2.mp4", "2 Test Channel/Video v2.info.json", "2 Test Channel/Video v2.jpg", "2 Test Channel/Video v2.en.vtt",
+		"manifest.json", "index.html",
+	}
+	if strings.Join(names, "|") != strings.Join(want, "|") {
+		t.Errorf("entries = %v, want %v", names, want)
+	}
+
+	// The archive is now cached, so a resumed download gets the rest of the
+	// same bytes.
+	req := httptest.NewRequest(http.MethodGet, "/job/pl/export.zip?naming={index}+{channel}/{title}", nil)
+	req.Header.Set("Range", "bytes=100-")
+	req.Header.Set("If-Range", etag)
+	resumed := httptest.NewRecorder()
+	r.ServeHTTP(resumed, req)
+	if resumed.Code != http.StatusPartialContent || !bytes.Equal(resumed.Body.Bytes(), body[100:]) {
+		t.Errorf("resumed status = %d with %d bytes, want 206 with %d", resumed.Code, resumed.Body.Len(), len(body)-100)
+	}
+
+	// Changed files make a new archive.
+	os.WriteFile(filepath.Join(dir, "Test Channel", "Video v1.mp4"), []byte("re-downloaded"), 0644)
+	if rec := getExport(r, "/job/pl/export.zip?naming={index}+{channel}/{title}"); rec.Header().Get("ETag") == etag {
+		t.Error("ETag unchanged after a member file changed")
+	}
+
+	rec = getExport(r, "/collections/col/export.zip")
+	body = rec.Body.Bytes()
+	zr, err = zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil || len(zr.File) != 6 || zr.File[0].Name != "1 - Video v2.mp4" {
+		t.Errorf("collection zip = %v, %v", zr, err)
+	}
+
+	for path, status := range map[string]int{
+		"/collections/col/export.zip?naming={nope}": http.StatusBadRequest,
+		"/collections/missing/export.zip":           http.StatusNotFound,
+		"/job/v1/export.zip":                        http.StatusBadRequest,
+	} {
+		if rec := getExport(r, path); rec.Code != status {
+			t.Errorf("GET %s status = %d, want %d", path, rec.Code, status)
+		}
+	}
+}
+
+func TestServeVideoRejectsBadSignatures(t *testing.T) {
+	handler, _ := setupTestHandler(t)
+	r := chi.NewRouter()
+	handler.RegisterRoutes(r)
+
+	// Without a signing key, signed links are refused outright.
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/video/v1?expires=1&sig=abc", nil))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+
+	handler.signer = signedurl.New("secret")
+	query := handler.signer.Sign("/video/other", time.Hour).Encode()
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/video/v1?"+query, nil))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("signature for another video: status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+
+	// Thumbnails of exported playlists are signed the same way.
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/job/v1/thumbnail?"+query, nil))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("thumbnail with a video signature: status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+	query = handler.signer.Sign("/job/v1/thumbnail", time.Hour).Encode()
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/job/v1/thumbnail?"+query, nil))
+	if rec.Code == http.StatusForbidden {
+		t.Errorf("signed thumbnail: status = %d", rec.Code)
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"video-archiver/internal/domain"
 	"video-archiver/internal/services/download"
 	"video-archiver/internal/services/tools"
+	"video-archiver/internal/util/signedurl"
 	"video-archiver/internal/util/statistics"
 )
 
@@ -22,6 +23,12 @@ type DownloadRequest struct {
 	URL       string `json:"url"`
 	Quality   *int   `json:"quality,omitempty"`
 	MediaType string `json:"media_type,omitempty"` // "video" (default) or "audio"
+	// ArchiveComments overrides the archive_comments setting for this download.
+	ArchiveComments *bool `json:"archive_comments,omitempty"`
+	// Live records a livestream or premiere; see domain.Job.Live.
+	Live bool `json:"live,omitempty"`
+	// ChannelOptions selects tabs and filters when the URL is a channel.
+	ChannelOptions *domain.ChannelDownloadOptions `json:"channel_options,omitempty"`
 }
 
 type Response struct {
@@ -56,10 +63,12 @@ type Handler struct {
 	toolsService       *tools.Service
 	toolsRepository    domain.ToolsRepository
 	ffmpeg             *tools.FFmpeg
+	// signer checks signed video links; nil when signing is disabled.
+	signer *signedurl.Signer
 }
 
 func NewHandler(downloadService *download.Service, downloadPath string, settingsRepository domain.SettingsRepository,
-	toolsService *tools.Service, toolsRepository domain.ToolsRepository, ffmpeg *tools.FFmpeg) *Handler {
+	toolsService *tools.Service, toolsRepository domain.ToolsRepository, ffmpeg *tools.FFmpeg, signer *signedurl.Signer) *Handler {
 	return &Handler{
 		downloadService:    downloadService,
 		downloadPath:       downloadPath,
@@ -67,6 +76,7 @@ func NewHandler(downloadService *download.Service, downloadPath string, settings
 		toolsService:       toolsService,
 		toolsRepository:    toolsRepository,
 		ffmpeg:             ffmpeg,
+		signer:             signer,
 	}
 }
 
@@ -80,15 +90,52 @@ func (h *Handler) RegisterRoutes(r *chi.Mux) {
 	r.Delete("/job/{id}", h.HandleDeleteJob)
 	r.Get("/job/{id}/parents", h.HandleGetJobParents)
 	r.Get("/job/{id}/videos", h.HandleGetJobVideos)
+	r.Get("/job/{id}/thumbnail", h.HandleServeJobThumbnail)
+	r.Get("/job/{id}/assets/{kind}", h.HandleServeChannelAsset)
+	r.Get("/job/{id}/channel-history", h.HandleGetChannelHistory)
+	r.Get("/job/{id}/snapshots", h.HandleGetPlaylistSnapshots)
+	r.Get("/job/{id}/snapshots/{snapshotID}", h.HandleGetPlaylistSnapshot)
+	r.Get("/job/{id}/snapshots/{a}/diff/{b}", h.HandleDiffPlaylistSnapshots)
+	r.Post("/job/{id}/refresh", h.HandleRefreshJob)
+	r.Post("/job/{id}/sync", h.HandleResyncJob)
+	r.Get("/job/{id}/channel-options", h.HandleGetChannelOptions)
+	r.Put("/job/{id}/channel-options", h.HandleSetChannelOptions)
+	r.Get("/job/{id}/refresh-schedule", h.HandleGetRefreshSchedule)
+	r.Put("/job/{id}/refresh-schedule", h.HandleSetRefreshSchedule)
 	r.Get("/job/{id}/tags", h.HandleGetJobTags)
 	r.Post("/job/{id}/tags", h.HandleAddJobTags)
 	r.Delete("/job/{id}/tags/{tagID}", h.HandleRemoveJobTag)
+	r.Put("/job/{id}/annotation", h.HandleSetJobAnnotation)
 	r.Get("/tags", h.HandleListTags)
+	r.Post("/tags/merge", h.HandleMergeTags)
+	r.Patch("/tags/{tagID}", h.HandleUpdateTag)
+	r.Delete("/tags/{tagID}", h.HandleDeleteTag)
+	r.Get("/tags/rules", h.HandleListTagRules)
+	r.Post("/tags/rules", h.HandleCreateTagRule)
+	r.Post("/tags/rules/apply", h.HandleApplyTagRules)
+	r.Get("/tags/rules/{ruleID}", h.HandleGetTagRule)
+	r.Put("/tags/rules/{ruleID}", h.HandleUpdateTagRule)
+	r.Delete("/tags/rules/{ruleID}", h.HandleDeleteTagRule)
+	r.Get("/tags/vocabulary", h.HandleGetTagVocabulary)
+	r.Post("/tags/synonyms", h.HandleSetTagSynonym)
+	r.Delete("/tags/synonyms/{alias}", h.HandleDeleteTagSynonym)
+	r.Post("/tags/blocklist", h.HandleBlockTag)
+	r.Delete("/tags/blocklist/{name}", h.HandleUnblockTag)
+	r.Post("/upgrades", h.HandleStartUpgrade)
+	r.Get("/upgrades", h.HandleListUpgrades)
+	r.Get("/upgrades/{id}", h.HandleGetUpgrade)
 	r.Get("/statistics", h.HandleGetStatistics)
 	r.Get("/downloads/{type}", h.HandleGetDownloads)
 	r.Get("/video/{jobID}", h.HandleServeVideo)
 	r.Get("/video/{jobID}/playback-info", h.HandlePlaybackInfo)
 	r.Post("/video/{jobID}/transcode", h.HandleRequestTranscode)
+	r.Get("/video/{jobID}/comments", h.HandleGetComments)
+	r.Get("/video/{jobID}/history", h.HandleGetVideoHistory)
+	r.Get("/video/{jobID}/availability", h.HandleGetAvailability)
+	r.Post("/video/{jobID}/availability/check", h.HandleCheckAvailability)
+	r.Put("/video/{jobID}/progress", h.HandleSetWatchProgress)
+	r.Delete("/video/{jobID}/progress", h.HandleResetWatchProgress)
+	r.Get("/continue-watching", h.HandleContinueWatching)
 	r.Get("/settings", h.HandleGetSettings)
 	r.Put("/settings", h.HandleUpdateSettings)
 	r.Get("/ws", h.HandleWebSocket)
@@ -152,6 +199,13 @@ func (h *Handler) HandleDownload(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.ChannelOptions != nil {
+		if err := req.ChannelOptions.Validate(); err != nil {
+			http.Error(w, "Invalid channel_options: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
 	mediaType := domain.MediaTypeVideo
 	switch req.MediaType {
 	case "", string(domain.MediaTypeVideo):
@@ -169,12 +223,15 @@ func (h *Handler) HandleDownload(w http.ResponseWriter, r *http.Request) {
 	}
 
 	job := domain.Job{
-		ID:            uuid.New().String(),
-		URL:           req.URL,
-		MediaType:     mediaType,
-		CustomQuality: req.Quality,
-		CreatedAt:     time.Now(),
-		UpdatedAt:     time.Now(),
+		ID:              uuid.New().String(),
+		URL:             req.URL,
+		MediaType:       mediaType,
+		CustomQuality:   req.Quality,
+		ArchiveComments: req.ArchiveComments,
+		Live:            req.Live,
+		ChannelOptions:  req.ChannelOptions,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
 	}
 
 	if err := h.downloadService.Submit(job); err != nil {
@@ -426,13 +483,37 @@ func (h *Handler) HandleGetDownloads(w http.ResponseWriter, r *http.Request) {
 		order = "desc"
 	}
 
+	availability := domain.Availability(r.URL.Query().Get("availability"))
+	if availability != "" {
+		if contentType != "videos" {
+			http.Error(w, "The availability filter only applies to videos", http.StatusBadRequest)
+			return
+		}
+		if !availability.IsValid() {
+			http.Error(w, "Invalid availability. Must be 'available', 'private', 'removed', 'geo_blocked', or 'account_terminated'", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// q is the library query language, e.g. `duration>20m res>=1080 -tag:music`.
+	filters, err := domain.ParseLibraryQuery(r.URL.Query().Get("q"))
+	if err == nil {
+		err = domain.CheckQueryFilters(filters, contentType)
+	}
+	if err != nil {
+		http.Error(w, "Invalid query: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	items, totalCount, err := h.downloadService.GetRepository().GetMetadataByType(contentType, domain.MetadataQuery{
-		Page:   page,
-		Limit:  limit,
-		SortBy: sortBy,
-		Order:  order,
-		Search: r.URL.Query().Get("search"),
-		Tag:    r.URL.Query().Get("tag"),
+		Page:         page,
+		Limit:        limit,
+		SortBy:       sortBy,
+		Order:        order,
+		Search:       r.URL.Query().Get("search"),
+		Tag:          r.URL.Query().Get("tag"),
+		Availability: availability,
+		Filters:      filters,
 	})
 	if err != nil {
 		log.WithError(err).Errorf("Failed to get %s", contentType)
@@ -453,6 +534,24 @@ func (h *Handler) HandleGetDownloads(w http.ResponseWriter, r *http.Request) {
 	}})
 }
 
+// checkSignature rejects a signed link whose signature isn't valid for path,
+// so a proxy in front can let such requests through without its own
+// authentication. Unsigned requests pass.
+func (h *Handler) checkSignature(w http.ResponseWriter, r *http.Request, path string) bool {
+	if !r.URL.Query().Has(signedurl.SignatureParam) {
+		return true
+	}
+	if h.signer == nil {
+		http.Error(w, "Signed links are not enabled", http.StatusForbidden)
+		return false
+	}
+	if err := h.signer.Verify(path, r.URL.Query()); err != nil {
+		http.Error(w, "Invalid or expired link", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
 func (h *Handler) HandleServeVideo(w http.ResponseWriter, r *http.Request) {
 	jobID := chi.URLParam(r, "jobID")
 	if jobID == "" {
@@ -460,6 +559,10 @@ func (h *Handler) HandleServeVideo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.checkSignature(w, r, videoURLPath(jobID)) {
+		return
+	}
+
 	// Get job metadata to find the video file
 	jobWithMetadata, err := h.downloadService.GetJobWithMetadata(jobID)
 	if err != nil {
@@ -531,6 +634,9 @@ type UpdateSettingsRequest struct {
 	ToolsDefaultQuality   *string `json:"tools_default_quality,omitempty"`
 	ToolsPreserveOriginal *bool   `json:"tools_preserve_original,omitempty"`
 	ToolsOutputPath       *string `json:"tools_output_path,omitempty"`
+	ArchiveComments       *bool   `json:"archive_comments,omitempty"`
+	// AvailabilityCheckHours: 0 disables the availability checker.
+	AvailabilityCheckHours *int `json:"availability_check_hours,omitempty"`
 }
 
 func (h *Handler) HandleUpdateSettings(w http.ResponseWriter, r *http.Request) {
@@ -555,6 +661,11 @@ func (h *Handler) HandleUpdateSettings(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.AvailabilityCheckHours != nil && (*req.AvailabilityCheckHours < 0 || *req.AvailabilityCheckHours > 8760) {
+		http.Error(w, "Invalid availability check interval. Must be between 0 and 8760 hours", http.StatusBadRequest)
+		return
+	}
+
 	settings, err := h.settingsRepository.Get()
 	if err != nil {
 		log.WithError(err).Error("Failed to get current settings")
@@ -577,6 +688,12 @@ func (h *Handler) HandleUpdateSettings(w http.ResponseWriter, r *http.Request) {
 	if req.ToolsOutputPath != nil {
 		settings.ToolsOutputPath = *req.ToolsOutputPath
 	}
+	if req.ArchiveComments != nil {
+		settings.ArchiveComments = *req.ArchiveComments
+	}
+	if req.AvailabilityCheckHours != nil {
+		settings.AvailabilityCheckHours = *req.AvailabilityCheckHours
+	}
 
 	if err := h.settingsRepository.Update(settings); err != nil {
 		log.WithError(err).Error("Failed to update settings")
@@ -593,7 +710,7 @@ func (h *Handler) HandleUpdateSettings(w http.ResponseWriter, r *http.Request) {
 func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
 
 		if r.Method == "OPTIONS" {
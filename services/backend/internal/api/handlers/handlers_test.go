@@ -52,7 +52,7 @@ func setupTestHandler(t *testing.T) (*Handler, *testutil.MockJobRepository) {
 	}
 	service := download.NewService(config)
 
-	handler := NewHandler(service, "/tmp/test", mockSettings, nil, nil, nil)
+	handler := NewHandler(service, "/tmp/test", mockSettings, nil, nil, nil, nil)
 	return handler, mockRepo
 }
 
@@ -310,6 +310,27 @@ func TestHandleGetDownloads(t *testing.T) {
 			expectedStatus: http.StatusBadRequest,
 			checkCount:     false,
 		},
+		{
+			name:           "query",
+			contentType:    "videos",
+			queryParams:    "?q=duration%3E20m+-tag%3Amusic&sort_by=duration",
+			expectedStatus: http.StatusOK,
+			checkCount:     false,
+		},
+		{
+			name:           "query with unknown field",
+			contentType:    "videos",
+			queryParams:    "?q=jobs.status%3Aerror",
+			expectedStatus: http.StatusBadRequest,
+			checkCount:     false,
+		},
+		{
+			name:           "video filter on playlists",
+			contentType:    "playlists",
+			queryParams:    "?q=res%3E%3D1080",
+			expectedStatus: http.StatusBadRequest,
+			checkCount:     false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -370,7 +391,7 @@ func TestHandleRecent(t *testing.T) {
 				MaxQuality:    1080,
 			}
 			service := download.NewService(config)
-			handler = NewHandler(service, "/tmp/test", mockSettings, nil, nil, nil)
+			handler = NewHandler(service, "/tmp/test", mockSettings, nil, nil, nil, nil)
 
 			// Setup test data
 			for i := 0; i < tt.setupJobs; i++ {
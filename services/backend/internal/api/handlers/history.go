@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	log "github.com/sirupsen/logrus"
+	"video-archiver/internal/services/download"
+)
+
+// maxRefreshIntervalHours caps refresh schedules at one year.
+const maxRefreshIntervalHours = 24 * 365
+
+type RefreshScheduleRequest struct {
+	// IntervalHours between refreshes; 0 removes the schedule.
+	IntervalHours int `json:"interval_hours"`
+}
+
+// HandleGetVideoHistory returns a video's engagement history (views, likes,
+// comments, title and description per refresh), oldest first.
+func (h *Handler) HandleGetVideoHistory(w http.ResponseWriter, r *http.Request) {
+	job, _, ok := h.videoJobFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	snapshots, err := h.downloadService.GetRepository().GetMetadataSnapshots(job.ID)
+	if err != nil {
+		log.WithError(err).Error("Failed to get video history")
+		http.Error(w, "Failed to get video history", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Message: snapshots})
+}
+
+// HandleRefreshJob starts an immediate metadata refresh of a video, playlist
+// or channel job in the background, or answers 409 while one is running.
+func (h *Handler) HandleRefreshJob(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "id")
+	if err := h.downloadService.RefreshJobAsync(jobID); err != nil {
+		if errors.Is(err, download.ErrRefreshRunning) {
+			http.Error(w, "A metadata refresh of this job is already running", http.StatusConflict)
+			return
+		}
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, Response{Message: "Metadata refresh started"})
+}
+
+// HandleGetRefreshSchedule returns a job's refresh schedule, or null when the
+// job is not refreshed automatically.
+func (h *Handler) HandleGetRefreshSchedule(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "id")
+	repo := h.downloadService.GetRepository()
+	if _, err := repo.GetByID(jobID); err != nil {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+
+	schedule, err := repo.GetRefreshSchedule(jobID)
+	if err != nil {
+		log.WithError(err).Error("Failed to get refresh schedule")
+		http.Error(w, "Failed to get refresh schedule", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Message: schedule})
+}
+
+// HandleSetRefreshSchedule sets or removes a job's refresh schedule.
+func (h *Handler) HandleSetRefreshSchedule(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "id")
+	repo := h.downloadService.GetRepository()
+	if _, err := repo.GetByID(jobID); err != nil {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+
+	var req RefreshScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.IntervalHours < 0 || req.IntervalHours > maxRefreshIntervalHours {
+		http.Error(w, "interval_hours must be between 0 and 8760", http.StatusBadRequest)
+		return
+	}
+
+	if err := repo.SetRefreshSchedule(jobID, req.IntervalHours); err != nil {
+		log.WithError(err).Error("Failed to set refresh schedule")
+		http.Error(w, "Failed to set refresh schedule", http.StatusInternalServerError)
+		return
+	}
+
+	schedule, err := repo.GetRefreshSchedule(jobID)
+	if err != nil {
+		log.WithError(err).Error("Failed to get refresh schedule")
+		http.Error(w, "Failed to get refresh schedule", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Message: schedule})
+}
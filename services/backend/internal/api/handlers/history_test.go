@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"video-archiver/internal/domain"
+	"video-archiver/internal/testutil"
+)
+
+func TestHandleGetVideoHistory(t *testing.T) {
+	handler, mockRepo := setupTestHandler(t)
+	r := chi.NewRouter()
+	handler.RegisterRoutes(r)
+
+	video := testutil.CreateTestJob("video-id", "https://youtube.com/watch?v=test")
+	mockRepo.Create(video)
+	meta := testutil.CreateTestVideoMetadata()
+	mockRepo.StoreMetadata(video.ID, meta)
+	mockRepo.RecordMetadataSnapshot(video.ID, meta)
+	mockRepo.RecordMetadataSnapshot(video.ID, meta)
+
+	playlist := testutil.CreateTestJob("playlist-id", "https://youtube.com/playlist?list=test")
+	mockRepo.Create(playlist)
+	mockRepo.StoreMetadata(playlist.ID, testutil.CreateTestPlaylistMetadata())
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/video/video-id/history", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	var resp struct {
+		Message []domain.MetadataSnapshot `json:"message"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(resp.Message) != 2 {
+		t.Errorf("got %d snapshots, want 2", len(resp.Message))
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/video/playlist-id/history", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("playlist history status = %d, want 400", rec.Code)
+	}
+}
+
+func TestHandleRefreshSchedule(t *testing.T) {
+	handler, mockRepo := setupTestHandler(t)
+	r := chi.NewRouter()
+	handler.RegisterRoutes(r)
+
+	mockRepo.Create(testutil.CreateTestJob("job-id", "https://youtube.com/playlist?list=test"))
+
+	tests := []struct {
+		name       string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{"set", "/job/job-id/refresh-schedule", `{"interval_hours": 24}`, http.StatusOK},
+		{"negative", "/job/job-id/refresh-schedule", `{"interval_hours": -1}`, http.StatusBadRequest},
+		{"too long", "/job/job-id/refresh-schedule", `{"interval_hours": 10000}`, http.StatusBadRequest},
+		{"invalid body", "/job/job-id/refresh-schedule", `{`, http.StatusBadRequest},
+		{"unknown job", "/job/missing/refresh-schedule", `{"interval_hours": 24}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, tt.path, strings.NewReader(tt.body)))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+		})
+	}
+
+	if s, _ := mockRepo.GetRefreshSchedule("job-id"); s == nil || s.IntervalHours != 24 {
+		t.Errorf("schedule = %+v, want 24h", s)
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/job/job-id/refresh-schedule", strings.NewReader(`{"interval_hours": 0}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("remove status = %d, want 200", rec.Code)
+	}
+	if s, _ := mockRepo.GetRefreshSchedule("job-id"); s != nil {
+		t.Errorf("schedule still present after removal: %+v", s)
+	}
+}
+
+func TestHandleRefreshJobUnknown(t *testing.T) {
+	handler, _ := setupTestHandler(t)
+	r := chi.NewRouter()
+	handler.RegisterRoutes(r)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/job/missing/refresh", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	log "github.com/sirupsen/logrus"
+	"video-archiver/internal/domain"
+	"video-archiver/internal/services/hls"
+	"video-archiver/internal/services/tools"
+)
+
+// HLSHandler streams videos as adaptive HLS, transcoding segments on demand
+// so files the browser can't decode play without a full transcode first.
+type HLSHandler struct {
+	jobs         domain.JobRepository
+	downloadPath string
+	streams      *hls.Service
+}
+
+func NewHLSHandler(jobs domain.JobRepository, downloadPath string, streams *hls.Service) *HLSHandler {
+	return &HLSHandler{jobs: jobs, downloadPath: downloadPath, streams: streams}
+}
+
+func (h *HLSHandler) RegisterRoutes(r chi.Router) {
+	r.Route("/video/{jobID}/hls", func(r chi.Router) {
+		r.Get("/master.m3u8", h.HandleMasterPlaylist)
+		r.Get("/{rendition}/index.m3u8", h.HandleMediaPlaylist)
+		r.Get("/{rendition}/{index:[0-9]+}.ts", h.HandleSegment)
+	})
+}
+
+// hlsMasterPath is the route of a video's HLS master playlist.
+func hlsMasterPath(jobID string) string {
+	return "/video/" + jobID + "/hls/master.m3u8"
+}
+
+// source locates the video file of the URL's {jobID}, writing the error
+// response itself when it can't be streamed.
+func (h *HLSHandler) source(w http.ResponseWriter, r *http.Request) (hls.Source, bool) {
+	jobID := chi.URLParam(r, "jobID")
+	item, err := h.jobs.GetJobWithMetadata(jobID)
+	if err != nil || item == nil || item.Job == nil {
+		http.Error(w, "Video not found", http.StatusNotFound)
+		return hls.Source{}, false
+	}
+	metadata, ok := item.Metadata.(*domain.VideoMetadata)
+	if !ok {
+		http.Error(w, "Unsupported content type for video playback", http.StatusBadRequest)
+		return hls.Source{}, false
+	}
+	path, err := tools.ResolveVideoFileWithHint(h.downloadPath, item.Job.FilePath, metadata)
+	if err != nil {
+		http.Error(w, "Video file not found", http.StatusNotFound)
+		return hls.Source{}, false
+	}
+	return hls.Source{JobID: jobID, Path: path}, true
+}
+
+// writeStreamError maps an error of the HLS service to a response.
+func writeStreamError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, hls.ErrNotFound):
+		http.Error(w, "Not found", http.StatusNotFound)
+	case errors.Is(err, hls.ErrNoVideo):
+		http.Error(w, "File has no video stream", http.StatusBadRequest)
+	case errors.Is(err, context.Canceled) && r.Context().Err() != nil:
+		// The player went away; nobody is left to answer.
+	default:
+		log.WithError(err).Error("Failed to stream HLS")
+		http.Error(w, "Failed to stream video", http.StatusInternalServerError)
+	}
+}
+
+func writePlaylist(w http.ResponseWriter, playlist []byte) {
+	w.Header().Set("Content-Type", "application/vnd.apple.mpegurl")
+	w.Header().Set("Cache-Control", "no-cache")
+	_, _ = w.Write(playlist)
+}
+
+// HandleMasterPlaylist lists the renditions of the video.
+func (h *HLSHandler) HandleMasterPlaylist(w http.ResponseWriter, r *http.Request) {
+	source, ok := h.source(w, r)
+	if !ok {
+		return
+	}
+	playlist, err := h.streams.MasterPlaylist(source)
+	if err != nil {
+		writeStreamError(w, r, err)
+		return
+	}
+	writePlaylist(w, playlist)
+}
+
+// HandleMediaPlaylist lists the segments of a rendition.
+func (h *HLSHandler) HandleMediaPlaylist(w http.ResponseWriter, r *http.Request) {
+	source, ok := h.source(w, r)
+	if !ok {
+		return
+	}
+	playlist, err := h.streams.MediaPlaylist(source, chi.URLParam(r, "rendition"))
+	if err != nil {
+		writeStreamError(w, r, err)
+		return
+	}
+	writePlaylist(w, playlist)
+}
+
+// HandleSegment serves a segment, transcoding it first unless cached. The
+// transcode stops when the player stops waiting for it.
+func (h *HLSHandler) HandleSegment(w http.ResponseWriter, r *http.Request) {
+	source, ok := h.source(w, r)
+	if !ok {
+		return
+	}
+	index, err := strconv.Atoi(chi.URLParam(r, "index"))
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	segment, err := h.streams.Segment(r.Context(), source, chi.URLParam(r, "rendition"), index)
+	if err != nil {
+		writeStreamError(w, r, err)
+		return
+	}
+	defer segment.Close()
+	stat, err := segment.Stat()
+	if err != nil {
+		writeStreamError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "video/mp2t")
+	w.Header().Set("Cache-Control", "no-cache")
+	http.ServeContent(w, r, "", stat.ModTime(), segment)
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"video-archiver/internal/services/hls"
+	"video-archiver/internal/services/tools"
+	"video-archiver/internal/testutil"
+)
+
+// fakeHLSFFmpeg probes every file as a 720p VP9 video and writes segments
+// holding their ffmpeg arguments.
+type fakeHLSFFmpeg struct{}
+
+func (fakeHLSFFmpeg) Probe(string) (*tools.MediaInfo, error) {
+	return &tools.MediaInfo{Duration: 10, Width: 1280, Height: 720, HasVideo: true, VideoCodec: "vp9"}, nil
+}
+
+func (fakeHLSFFmpeg) Run(_ context.Context, opArgs []string, output string, _ float64, _ tools.ProgressFunc) error {
+	return os.WriteFile(output, []byte(strings.Join(opArgs, " ")), 0644)
+}
+
+func TestHLSHandler(t *testing.T) {
+	downloadPath := t.TempDir()
+	videoPath := filepath.Join(downloadPath, "video.webm")
+	if err := os.WriteFile(videoPath, []byte("webm"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	jobs := testutil.NewMockJobRepository()
+	job := testutil.CreateTestJob("v1", "https://youtube.com/watch?v=v1")
+	job.FilePath = videoPath
+	jobs.Create(job)
+	jobs.StoreMetadata("v1", testutil.CreateTestVideoMetadata())
+	jobs.Create(testutil.CreateTestJob("pl", "https://youtube.com/playlist?list=x"))
+	jobs.StoreMetadata("pl", testutil.CreateTestPlaylistMetadata())
+
+	streams := hls.NewService(&hls.Config{CachePath: t.TempDir(), FFmpeg: fakeHLSFFmpeg{}})
+	defer streams.Stop()
+	r := chi.NewRouter()
+	NewHLSHandler(jobs, downloadPath, streams).RegisterRoutes(r)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	rec := get(hlsMasterPath("v1"))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "720p/index.m3u8") {
+		t.Fatalf("master status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/vnd.apple.mpegurl" {
+		t.Errorf("master Content-Type = %q", ct)
+	}
+
+	rec = get("/video/v1/hls/480p/index.m3u8")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "#EXTINF:4.000,\n1.ts\n#EXT-X-ENDLIST") {
+		t.Errorf("media playlist status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+
+	rec = get("/video/v1/hls/480p/1.ts")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "video/mp2t" ||
+		!strings.Contains(rec.Body.String(), "-ss 6.000") {
+		t.Errorf("segment status = %d, headers = %v, body = %s", rec.Code, rec.Header(), rec.Body.String())
+	}
+
+	for path, want := range map[string]int{
+		"/video/v1/hls/480p/2.ts":        http.StatusNotFound,
+		"/video/v1/hls/1080p/index.m3u8": http.StatusNotFound,
+		"/video/missing/hls/master.m3u8": http.StatusNotFound,
+		"/video/pl/hls/master.m3u8":      http.StatusBadRequest,
+	} {
+		if rec := get(path); rec.Code != want {
+			t.Errorf("GET %s status = %d, want %d", path, rec.Code, want)
+		}
+	}
+}
@@ -8,25 +8,12 @@ import (
 	"github.com/go-chi/chi"
 	log "github.com/sirupsen/logrus"
 	"video-archiver/internal/domain"
+	"video-archiver/internal/services/tools"
 )
 
-// browserSafeCodecs reports whether a probed media file can be decoded by the
-// HTML5 <video> element across browsers. Downloads are merged into mp4
-// containers, but merging only remuxes: files can carry VP9/AV1 video or Opus
-// audio that Safari (and others) cannot decode. h264 + aac/mp3 is the
-// universally supported combination.
-func browserSafeCodecs(videoCodec, audioCodec string, hasAudio bool) bool {
-	if videoCodec != "h264" {
-		return false
-	}
-	if !hasAudio {
-		return true
-	}
-	return audioCodec == "aac" || audioCodec == "mp3"
-}
-
 // HandlePlaybackInfo reports a video's container/codecs, whether the browser
-// can play it directly, and the state of any transcode job for it.
+// can play it directly or after a remux or transcode, and the state of any
+// job producing a playable copy.
 func (h *Handler) HandlePlaybackInfo(w http.ResponseWriter, r *http.Request) {
 	job, metadata, ok := h.videoJobFromRequest(w, r)
 	if !ok {
@@ -46,29 +33,38 @@ func (h *Handler) HandlePlaybackInfo(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	container := strings.TrimPrefix(strings.ToLower(filepath.Ext(videoPath)), ".")
+	faststart := false
+	if container == "mp4" || container == "m4v" || container == "mov" {
+		if faststart, err = tools.IsFaststart(videoPath); err != nil {
+			log.WithError(err).WithField("path", videoPath).Warn("Failed to check for faststart")
+		}
+	}
+
 	info := domain.PlaybackInfo{
-		Container:   strings.TrimPrefix(strings.ToLower(filepath.Ext(videoPath)), "."),
-		VideoCodec:  probe.VideoCodec,
-		AudioCodec:  probe.AudioCodec,
-		BrowserSafe: browserSafeCodecs(probe.VideoCodec, probe.AudioCodec, probe.HasAudio),
+		Container:      container,
+		VideoCodec:     probe.VideoCodec,
+		AudioCodec:     probe.AudioCodec,
+		BrowserSafe:    domain.BrowserSafeCodecs(probe.VideoCodec, probe.AudioCodec, probe.HasAudio),
+		Faststart:      faststart,
+		Playback:       domain.PlaybackModeFor(container, probe.VideoCodec, probe.AudioCodec, probe.HasAudio, faststart),
+		HLSPath:        hlsMasterPath(job.ID),
+		StoryboardPath: storyboardPath(job.ID),
 	}
 
 	if transcode, err := h.toolsRepository.FindLatestConvertForInput(job.ID); err != nil {
 		log.WithError(err).Warn("Failed to look up transcode job")
 	} else if transcode != nil {
-		info.Transcode = &domain.PlaybackTranscode{
-			JobID:    transcode.ID,
-			Status:   transcode.Status,
-			Progress: transcode.Progress,
-		}
+		info.Transcode = playbackTranscode(transcode)
 	}
 
 	writeJSON(w, http.StatusOK, Response{Message: info})
 }
 
-// HandleRequestTranscode submits a convert job that produces a browser-safe
-// (h264/aac mp4) version of a video. If a transcode is already pending or
-// running, that job is returned instead of starting a duplicate.
+// HandleRequestTranscode submits a job that produces a browser-safe version
+// of a video: a remux into a faststart mp4 when the codecs already play, a
+// convert to h264/aac otherwise. If such a job is already pending or running,
+// that job is returned instead of starting a duplicate.
 func (h *Handler) HandleRequestTranscode(w http.ResponseWriter, r *http.Request) {
 	job, metadata, ok := h.videoJobFromRequest(w, r)
 	if !ok {
@@ -83,11 +79,7 @@ func (h *Handler) HandleRequestTranscode(w http.ResponseWriter, r *http.Request)
 	}
 	if existing != nil &&
 		(existing.Status == domain.ToolsJobStatusPending || existing.Status == domain.ToolsJobStatusProcessing) {
-		writeJSON(w, http.StatusOK, Response{Message: domain.PlaybackTranscode{
-			JobID:    existing.ID,
-			Status:   existing.Status,
-			Progress: existing.Progress,
-		}})
+		writeJSON(w, http.StatusOK, Response{Message: playbackTranscode(existing)})
 		return
 	}
 
@@ -102,18 +94,45 @@ func (h *Handler) HandleRequestTranscode(w http.ResponseWriter, r *http.Request)
 			"output_name":   metadata.Title + " (web)",
 		},
 	}
+	if h.needsOnlyRemux(job, metadata) {
+		toolsJob.OperationType = domain.OpTypeRemux
+		toolsJob.Parameters = map[string]any{
+			"output_format": "mp4",
+			"output_name":   metadata.Title + " (web)",
+		}
+	}
 	if err := h.toolsService.Submit(toolsJob); err != nil {
 		log.WithError(err).Error("Failed to submit transcode job")
 		http.Error(w, "Failed to start transcode", http.StatusServiceUnavailable)
 		return
 	}
 
-	log.WithField("jobID", job.ID).WithField("toolsJobID", toolsJob.ID).Info("Transcode requested")
-	writeJSON(w, http.StatusAccepted, Response{Message: domain.PlaybackTranscode{
-		JobID:    toolsJob.ID,
-		Status:   toolsJob.Status,
-		Progress: toolsJob.Progress,
-	}})
+	log.WithField("jobID", job.ID).WithField("toolsJobID", toolsJob.ID).
+		WithField("operation", toolsJob.OperationType).Info("Transcode requested")
+	writeJSON(w, http.StatusAccepted, Response{Message: playbackTranscode(toolsJob)})
+}
+
+// needsOnlyRemux reports whether the video's codecs play in browsers, so a
+// stream copy is enough. A file that can't be probed gets the full transcode.
+func (h *Handler) needsOnlyRemux(job *domain.Job, metadata *domain.VideoMetadata) bool {
+	videoPath, err := h.locateVideoFile(job, metadata)
+	if err != nil {
+		return false
+	}
+	probe, err := h.ffmpeg.Probe(videoPath)
+	if err != nil {
+		return false
+	}
+	return domain.BrowserSafeCodecs(probe.VideoCodec, probe.AudioCodec, probe.HasAudio)
+}
+
+func playbackTranscode(job *domain.ToolsJob) *domain.PlaybackTranscode {
+	return &domain.PlaybackTranscode{
+		JobID:     job.ID,
+		Operation: job.OperationType,
+		Status:    job.Status,
+		Progress:  job.Progress,
+	}
 }
 
 // videoJobFromRequest loads the job named by the jobID URL parameter and
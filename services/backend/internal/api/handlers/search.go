@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi"
+	log "github.com/sirupsen/logrus"
+	"video-archiver/internal/domain"
+)
+
+// SearchHandler serves full-text search across videos, playlists, channels
+// and collections.
+type SearchHandler struct {
+	search domain.SearchRepository
+}
+
+func NewSearchHandler(search domain.SearchRepository) *SearchHandler {
+	return &SearchHandler{search: search}
+}
+
+func (h *SearchHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/search", h.HandleSearch)
+}
+
+// HandleSearch returns ranked, highlighted matches for q. Words and "quoted
+// phrases" must all match, a trailing * matches a prefix, and type narrows
+// the results to one kind.
+func (h *SearchHandler) HandleSearch(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		http.Error(w, "Missing search query", http.StatusBadRequest)
+		return
+	}
+	kind := domain.SearchKind(r.URL.Query().Get("type"))
+	if kind != "" && !kind.IsValid() {
+		http.Error(w, "Invalid type. Must be 'video', 'playlist', 'channel' or 'collection'", http.StatusBadRequest)
+		return
+	}
+	limit := parseIntQuery(r, "limit", 20)
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	results, err := h.search.Search(query, kind, limit)
+	if err != nil {
+		log.WithError(err).Error("Failed to search")
+		http.Error(w, "Failed to search", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Message: results})
+}
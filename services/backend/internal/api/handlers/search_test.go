@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"video-archiver/internal/repositories/sqlite"
+	"video-archiver/internal/testutil"
+)
+
+func TestSearchHandler(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+	jobs := sqlite.NewJobRepository(db)
+	jobs.Create(testutil.CreateTestJob("v1", "https://example.com/v1"))
+	jobs.StoreMetadata("v1", testutil.CreateTestVideoMetadata())
+
+	r := chi.NewRouter()
+	NewSearchHandler(sqlite.NewSearchRepository(db)).RegisterRoutes(r)
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"match", "/search?q=test+vid*", http.StatusOK, `\u003cmark\u003eTest\u003c/mark\u003e`},
+		{"kind", "/search?q=test&type=playlist", http.StatusOK, `"message":[]`},
+		{"missing query", "/search?q=+", http.StatusBadRequest, ""},
+		{"invalid type", "/search?q=test&type=tag", http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("body = %s, want %s", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	log "github.com/sirupsen/logrus"
+	"video-archiver/internal/domain"
+)
+
+// HandleGetPlaylistSnapshots lists the item-list snapshots of a playlist or
+// channel job's source, oldest first, each with the counts of its diff
+// against the previous one.
+func (h *Handler) HandleGetPlaylistSnapshots(w http.ResponseWriter, r *http.Request) {
+	sourceID, ok := h.playlistSourceFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	snapshots, err := h.downloadService.GetRepository().GetPlaylistSnapshots(sourceID)
+	if err != nil {
+		log.WithError(err).Error("Failed to get playlist snapshots")
+		http.Error(w, "Failed to get playlist snapshots", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Message: snapshots})
+}
+
+// HandleGetPlaylistSnapshot returns one snapshot with its items.
+func (h *Handler) HandleGetPlaylistSnapshot(w http.ResponseWriter, r *http.Request) {
+	sourceID, ok := h.playlistSourceFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	snapshot, ok := h.snapshotFromParam(w, r, "snapshotID", sourceID)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Message: snapshot})
+}
+
+// HandleDiffPlaylistSnapshots returns the items added, removed, renamed and
+// reordered between snapshots a and b of the same source.
+func (h *Handler) HandleDiffPlaylistSnapshots(w http.ResponseWriter, r *http.Request) {
+	sourceID, ok := h.playlistSourceFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	from, ok := h.snapshotFromParam(w, r, "a", sourceID)
+	if !ok {
+		return
+	}
+	to, ok := h.snapshotFromParam(w, r, "b", sourceID)
+	if !ok {
+		return
+	}
+
+	diff := domain.DiffPlaylistEntries(from.Items, to.Items)
+	diff.From = from.ID
+	diff.To = to.ID
+	writeJSON(w, http.StatusOK, Response{Message: diff})
+}
+
+// playlistSourceFromRequest loads the job named by the id URL parameter and
+// returns the ID of the playlist or channel it was synced from.
+func (h *Handler) playlistSourceFromRequest(w http.ResponseWriter, r *http.Request) (string, bool) {
+	jobWithMetadata, err := h.downloadService.GetJobWithMetadata(chi.URLParam(r, "id"))
+	if err != nil || jobWithMetadata == nil || jobWithMetadata.Job == nil {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return "", false
+	}
+
+	var sourceID string
+	switch m := jobWithMetadata.Metadata.(type) {
+	case *domain.PlaylistMetadata:
+		sourceID = m.ID
+	case *domain.ChannelMetadata:
+		sourceID = m.ID
+	}
+	if sourceID == "" {
+		http.Error(w, "Job is not a playlist or channel", http.StatusBadRequest)
+		return "", false
+	}
+	return sourceID, true
+}
+
+// snapshotFromParam loads the snapshot named by a URL parameter, requiring it
+// to belong to sourceID so one job's URL cannot read another source's history.
+func (h *Handler) snapshotFromParam(w http.ResponseWriter, r *http.Request, param, sourceID string) (*domain.PlaylistSnapshot, bool) {
+	id, err := strconv.ParseInt(chi.URLParam(r, param), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid snapshot ID", http.StatusBadRequest)
+		return nil, false
+	}
+
+	snapshot, err := h.downloadService.GetRepository().GetPlaylistSnapshot(id)
+	if err != nil || snapshot.SourceID != sourceID {
+		http.Error(w, "Snapshot not found", http.StatusNotFound)
+		return nil, false
+	}
+	return snapshot, true
+}
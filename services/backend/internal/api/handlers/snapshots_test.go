@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"video-archiver/internal/domain"
+	"video-archiver/internal/testutil"
+)
+
+func TestHandlePlaylistSnapshots(t *testing.T) {
+	handler, mockRepo := setupTestHandler(t)
+	r := chi.NewRouter()
+	handler.RegisterRoutes(r)
+
+	playlist := testutil.CreateTestJob("playlist-id", "https://youtube.com/playlist?list=test")
+	mockRepo.Create(playlist)
+	meta := testutil.CreateTestPlaylistMetadata()
+	mockRepo.StoreMetadata(playlist.ID, meta)
+	mockRepo.RecordPlaylistSnapshot(playlist.ID, meta.ID, []domain.PlaylistEntry{
+		{VideoID: "a", Title: "A", Position: 1},
+		{VideoID: "b", Title: "B", Position: 2},
+	})
+	mockRepo.RecordPlaylistSnapshot(playlist.ID, meta.ID, []domain.PlaylistEntry{
+		{VideoID: "b", Title: "B", Position: 1},
+		{VideoID: "c", Title: "C", Position: 2},
+	})
+	mockRepo.RecordPlaylistSnapshot("other-job", "other-source", nil)
+
+	video := testutil.CreateTestJob("video-id", "https://youtube.com/watch?v=test")
+	mockRepo.Create(video)
+	mockRepo.StoreMetadata(video.ID, testutil.CreateTestVideoMetadata())
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/job/playlist-id/snapshots", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("list status = %d, want 200", rec.Code)
+	}
+	var list struct {
+		Message []domain.PlaylistSnapshot `json:"message"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&list); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(list.Message) != 2 {
+		t.Errorf("got %d snapshots, want 2", len(list.Message))
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/job/playlist-id/snapshots/1/diff/2", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("diff status = %d, want 200", rec.Code)
+	}
+	var diff struct {
+		Message domain.PlaylistDiff `json:"message"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&diff); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if diff.Message.From != 1 || diff.Message.To != 2 ||
+		len(diff.Message.Added) != 1 || diff.Message.Added[0].VideoID != "c" ||
+		len(diff.Message.Removed) != 1 || diff.Message.Removed[0].VideoID != "a" {
+		t.Errorf("diff = %+v", diff.Message)
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+	}{
+		{"single snapshot", "/job/playlist-id/snapshots/2", http.StatusOK},
+		{"video job", "/job/video-id/snapshots", http.StatusBadRequest},
+		{"unknown job", "/job/missing/snapshots", http.StatusNotFound},
+		{"invalid id", "/job/playlist-id/snapshots/x/diff/2", http.StatusBadRequest},
+		{"missing snapshot", "/job/playlist-id/snapshots/1/diff/99", http.StatusNotFound},
+		{"other source", "/job/playlist-id/snapshots/3", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	log "github.com/sirupsen/logrus"
+	"video-archiver/internal/services/storyboard"
+)
+
+// StoryboardHandler serves the seek-preview storyboards of videos: a WebVTT
+// index whose cues point into JPEG sprite sheets.
+type StoryboardHandler struct {
+	storyboards *storyboard.Service
+}
+
+func NewStoryboardHandler(storyboards *storyboard.Service) *StoryboardHandler {
+	return &StoryboardHandler{storyboards: storyboards}
+}
+
+func (h *StoryboardHandler) RegisterRoutes(r chi.Router) {
+	r.Get("/video/{jobID}/storyboard.vtt", h.HandleIndex)
+	r.Get("/video/{jobID}/storyboard/{index:[0-9]+}.jpg", h.HandleSheet)
+}
+
+// storyboardPath is the route of a video's storyboard index.
+func storyboardPath(jobID string) string {
+	return "/video/" + jobID + "/storyboard.vtt"
+}
+
+// writeStoryboardError maps an error of the storyboard service to a response.
+func writeStoryboardError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, storyboard.ErrNotFound):
+		http.Error(w, "Not found", http.StatusNotFound)
+	case errors.Is(err, storyboard.ErrNoVideo):
+		http.Error(w, "Storyboards are only available for videos", http.StatusBadRequest)
+	case errors.Is(err, context.Canceled) && r.Context().Err() != nil:
+		// The player went away; the generation finishes for the next one.
+	default:
+		log.WithError(err).Error("Failed to generate storyboard")
+		http.Error(w, "Failed to generate storyboard", http.StatusInternalServerError)
+	}
+}
+
+// HandleIndex serves the WebVTT index, generating the storyboard first for
+// videos that don't have one yet.
+func (h *StoryboardHandler) HandleIndex(w http.ResponseWriter, r *http.Request) {
+	index, err := h.storyboards.Index(r.Context(), chi.URLParam(r, "jobID"))
+	if err != nil {
+		writeStoryboardError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/vtt; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	http.ServeFile(w, r, index)
+}
+
+// HandleSheet serves a sprite sheet of an index served before.
+func (h *StoryboardHandler) HandleSheet(w http.ResponseWriter, r *http.Request) {
+	index, err := strconv.Atoi(chi.URLParam(r, "index"))
+	if err != nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	sheet, err := h.storyboards.Sheet(chi.URLParam(r, "jobID"), index)
+	if err != nil {
+		writeStoryboardError(w, r, err)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-cache")
+	http.ServeFile(w, r, sheet)
+}
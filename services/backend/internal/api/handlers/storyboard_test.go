@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"video-archiver/internal/services/storyboard"
+	"video-archiver/internal/services/tools"
+	"video-archiver/internal/testutil"
+)
+
+// fakeStoryboardFFmpeg probes every file as a 30 second 720p video and writes
+// a single sprite sheet.
+type fakeStoryboardFFmpeg struct{}
+
+func (fakeStoryboardFFmpeg) Probe(string) (*tools.MediaInfo, error) {
+	return &tools.MediaInfo{Duration: 30, Width: 1280, Height: 720, HasVideo: true, VideoCodec: "vp9"}, nil
+}
+
+func (fakeStoryboardFFmpeg) Run(_ context.Context, _ []string, output string, _ float64, _ tools.ProgressFunc) error {
+	return os.WriteFile(strings.Replace(output, "%d", "0", 1), []byte("jpeg"), 0644)
+}
+
+func TestStoryboardHandler(t *testing.T) {
+	downloadPath := t.TempDir()
+	videoPath := filepath.Join(downloadPath, "video.webm")
+	if err := os.WriteFile(videoPath, []byte("webm"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	jobs := testutil.NewMockJobRepository()
+	job := testutil.CreateTestJob("v1", "https://youtube.com/watch?v=v1")
+	job.FilePath = videoPath
+	jobs.Create(job)
+	jobs.StoreMetadata("v1", testutil.CreateTestVideoMetadata())
+	jobs.Create(testutil.CreateTestJob("pl", "https://youtube.com/playlist?list=x"))
+	jobs.StoreMetadata("pl", testutil.CreateTestPlaylistMetadata())
+
+	storyboards := storyboard.NewService(&storyboard.Config{
+		JobRepository: jobs,
+		DownloadPath:  downloadPath,
+		FFmpeg:        fakeStoryboardFFmpeg{},
+	})
+	defer storyboards.Stop()
+	r := chi.NewRouter()
+	NewStoryboardHandler(storyboards).RegisterRoutes(r)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	// Sheets only exist once an index has been generated.
+	if rec := get("/video/v1/storyboard/0.jpg"); rec.Code != http.StatusNotFound {
+		t.Errorf("sheet before index status = %d, want 404", rec.Code)
+	}
+
+	rec := get(storyboardPath("v1"))
+	if rec.Code != http.StatusOK ||
+		!strings.Contains(rec.Body.String(), "00:00:20.000 --> 00:00:30.000\nstoryboard/0.jpg#xywh=320,0,160,90") {
+		t.Fatalf("index status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/vtt; charset=utf-8" {
+		t.Errorf("index Content-Type = %q", ct)
+	}
+
+	rec = get("/video/v1/storyboard/0.jpg")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "image/jpeg" {
+		t.Errorf("sheet status = %d, headers = %v", rec.Code, rec.Header())
+	}
+
+	for path, want := range map[string]int{
+		"/video/v1/storyboard/1.jpg":    http.StatusNotFound,
+		"/video/missing/storyboard.vtt": http.StatusNotFound,
+		"/video/pl/storyboard.vtt":      http.StatusBadRequest,
+		"/video/v1/storyboard/../x.jpg": http.StatusNotFound,
+		"/video/v1/storyboard/abc.jpg":  http.StatusNotFound,
+	} {
+		if rec := get(path); rec.Code != want {
+			t.Errorf("GET %s status = %d, want %d", path, rec.Code, want)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi"
+	log "github.com/sirupsen/logrus"
+	"video-archiver/internal/domain"
+)
+
+// TagRuleRequest is the body for creating or updating a tag rule. Enabled
+// defaults to true on create and keeps the current value on update.
+type TagRuleRequest struct {
+	Name         string              `json:"name"`
+	Enabled      *bool               `json:"enabled,omitempty"`
+	Match        domain.TagRuleMatch `json:"match"`
+	Tags         []string            `json:"tags"`
+	CollectionID string              `json:"collection_id,omitempty"`
+}
+
+// apply copies the request onto rule and validates the result.
+func (req *TagRuleRequest) apply(rule *domain.TagRule) error {
+	rule.Name = strings.TrimSpace(req.Name)
+	rule.Match = req.Match
+	rule.Tags = req.Tags
+	rule.CollectionID = req.CollectionID
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	return rule.Validate()
+}
+
+func (h *Handler) HandleListTagRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.downloadService.GetRepository().ListTagRules()
+	if err != nil {
+		log.WithError(err).Error("Failed to list tag rules")
+		http.Error(w, "Failed to list tag rules", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Message: rules})
+}
+
+func (h *Handler) HandleCreateTagRule(w http.ResponseWriter, r *http.Request) {
+	var req TagRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	rule := &domain.TagRule{Enabled: true}
+	if err := req.apply(rule); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.downloadService.GetRepository().CreateTagRule(rule); err != nil {
+		log.WithError(err).Error("Failed to create tag rule")
+		http.Error(w, "Failed to create tag rule", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, Response{Message: rule})
+}
+
+// getTagRule loads the rule from the URL's {ruleID}, writing the error
+// response itself when the rule can't be served.
+func (h *Handler) getTagRule(w http.ResponseWriter, r *http.Request) *domain.TagRule {
+	id, err := strconv.ParseInt(chi.URLParam(r, "ruleID"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid rule ID", http.StatusBadRequest)
+		return nil
+	}
+	rule, err := h.downloadService.GetRepository().GetTagRule(id)
+	if err != nil {
+		log.WithError(err).Error("Failed to get tag rule")
+		http.Error(w, "Failed to get tag rule", http.StatusInternalServerError)
+		return nil
+	}
+	if rule == nil {
+		http.Error(w, "Tag rule not found", http.StatusNotFound)
+		return nil
+	}
+	return rule
+}
+
+func (h *Handler) HandleGetTagRule(w http.ResponseWriter, r *http.Request) {
+	rule := h.getTagRule(w, r)
+	if rule == nil {
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Message: rule})
+}
+
+func (h *Handler) HandleUpdateTagRule(w http.ResponseWriter, r *http.Request) {
+	rule := h.getTagRule(w, r)
+	if rule == nil {
+		return
+	}
+	var req TagRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	if err := req.apply(rule); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.downloadService.GetRepository().UpdateTagRule(rule); err != nil {
+		log.WithError(err).Error("Failed to update tag rule")
+		http.Error(w, "Failed to update tag rule", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Message: rule})
+}
+
+func (h *Handler) HandleDeleteTagRule(w http.ResponseWriter, r *http.Request) {
+	rule := h.getTagRule(w, r)
+	if rule == nil {
+		return
+	}
+	if err := h.downloadService.GetRepository().DeleteTagRule(rule.ID); err != nil {
+		log.WithError(err).Error("Failed to delete tag rule")
+		http.Error(w, "Failed to delete tag rule", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Message: "Tag rule deleted successfully"})
+}
+
+// HandleApplyTagRules re-applies auto-tagging, the rules and the vocabulary
+// to the whole library and reports what changed.
+func (h *Handler) HandleApplyTagRules(w http.ResponseWriter, r *http.Request) {
+	run, err := h.downloadService.GetRepository().ReapplyTagRules()
+	if err != nil {
+		log.WithError(err).Error("Failed to re-apply tag rules")
+		http.Error(w, "Failed to re-apply tag rules", http.StatusInternalServerError)
+		return
+	}
+	log.WithFields(log.Fields{"items": run.Items, "added": run.TagsAdded, "removed": run.TagsRemoved}).
+		Info("Tag rules re-applied")
+	writeJSON(w, http.StatusOK, Response{Message: run})
+}
+
+// HandleGetTagVocabulary returns the tag synonyms and the blocklist.
+func (h *Handler) HandleGetTagVocabulary(w http.ResponseWriter, r *http.Request) {
+	vocabulary, err := h.downloadService.GetRepository().GetTagVocabulary()
+	if err != nil {
+		log.WithError(err).Error("Failed to get tag vocabulary")
+		http.Error(w, "Failed to get tag vocabulary", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Message: vocabulary})
+}
+
+func (h *Handler) HandleSetTagSynonym(w http.ResponseWriter, r *http.Request) {
+	var req domain.TagSynonym
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	req.Alias = domain.NormalizeTagName(req.Alias)
+	req.Canonical = domain.NormalizeTagName(req.Canonical)
+	if req.Alias == "" || req.Canonical == "" {
+		http.Error(w, "alias and canonical are required", http.StatusBadRequest)
+		return
+	}
+	if strings.EqualFold(req.Alias, req.Canonical) {
+		http.Error(w, "alias and canonical must differ", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.downloadService.GetRepository().SetTagSynonym(req.Alias, req.Canonical); err != nil {
+		log.WithError(err).Error("Failed to set tag synonym")
+		http.Error(w, "Failed to set tag synonym", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Message: req})
+}
+
+func (h *Handler) HandleDeleteTagSynonym(w http.ResponseWriter, r *http.Request) {
+	if err := h.downloadService.GetRepository().DeleteTagSynonym(chi.URLParam(r, "alias")); err != nil {
+		log.WithError(err).Error("Failed to delete tag synonym")
+		http.Error(w, "Failed to delete tag synonym", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Message: "Tag synonym deleted successfully"})
+}
+
+// BlockTagRequest is the body for adding a name to the tag blocklist.
+type BlockTagRequest struct {
+	Name string `json:"name"`
+}
+
+func (h *Handler) HandleBlockTag(w http.ResponseWriter, r *http.Request) {
+	var req BlockTagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	req.Name = domain.NormalizeTagName(req.Name)
+	if req.Name == "" {
+		http.Error(w, "name is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.downloadService.GetRepository().BlockTag(req.Name); err != nil {
+		log.WithError(err).Error("Failed to block tag")
+		http.Error(w, "Failed to block tag", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Message: req})
+}
+
+func (h *Handler) HandleUnblockTag(w http.ResponseWriter, r *http.Request) {
+	if err := h.downloadService.GetRepository().UnblockTag(chi.URLParam(r, "name")); err != nil {
+		log.WithError(err).Error("Failed to unblock tag")
+		http.Error(w, "Failed to unblock tag", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Message: "Tag unblocked successfully"})
+}
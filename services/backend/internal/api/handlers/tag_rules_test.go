@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+)
+
+func TestTagRuleHandlers(t *testing.T) {
+	handler, mockRepo := setupTestHandler(t)
+	r := chi.NewRouter()
+	handler.RegisterRoutes(r)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{"create", http.MethodPost, "/tags/rules",
+			`{"name": "courses", "match": {"title_pattern": "lecture \\d+"}, "tags": ["course"]}`, http.StatusCreated},
+		{"missing name", http.MethodPost, "/tags/rules", `{"tags": ["x"]}`, http.StatusBadRequest},
+		{"bad pattern", http.MethodPost, "/tags/rules", `{"name": "x", "tags": ["x"], "match": {"title_pattern": "("}}`, http.StatusBadRequest},
+		{"list", http.MethodGet, "/tags/rules", "", http.StatusOK},
+		{"get", http.MethodGet, "/tags/rules/1", "", http.StatusOK},
+		{"get missing", http.MethodGet, "/tags/rules/99", "", http.StatusNotFound},
+		{"invalid id", http.MethodGet, "/tags/rules/abc", "", http.StatusBadRequest},
+		{"update", http.MethodPut, "/tags/rules/1", `{"name": "courses", "enabled": false, "tags": ["course"]}`, http.StatusOK},
+		{"apply", http.MethodPost, "/tags/rules/apply", "", http.StatusOK},
+		{"synonym", http.MethodPost, "/tags/synonyms", `{"alias": "js", "canonical": "JavaScript"}`, http.StatusOK},
+		{"self synonym", http.MethodPost, "/tags/synonyms", `{"alias": "js", "canonical": "JS"}`, http.StatusBadRequest},
+		{"block", http.MethodPost, "/tags/blocklist", `{"name": "subscribe"}`, http.StatusOK},
+		{"block empty", http.MethodPost, "/tags/blocklist", `{"name": " "}`, http.StatusBadRequest},
+		{"vocabulary", http.MethodGet, "/tags/vocabulary", "", http.StatusOK},
+		{"delete synonym", http.MethodDelete, "/tags/synonyms/js", "", http.StatusOK},
+		{"unblock", http.MethodDelete, "/tags/blocklist/subscribe", "", http.StatusOK},
+		{"delete", http.MethodDelete, "/tags/rules/1", "", http.StatusOK},
+		{"delete missing", http.MethodDelete, "/tags/rules/1", "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := do(tt.method, tt.path, tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			switch tt.name {
+			case "update":
+				if !strings.Contains(rec.Body.String(), `"enabled":false`) {
+					t.Errorf("rule still enabled: %s", rec.Body.String())
+				}
+			case "vocabulary":
+				body := rec.Body.String()
+				if !strings.Contains(body, `"JavaScript"`) || !strings.Contains(body, `"subscribe"`) {
+					t.Errorf("vocabulary = %s", body)
+				}
+			}
+		})
+	}
+
+	vocabulary, _ := mockRepo.GetTagVocabulary()
+	if len(vocabulary.Synonyms) != 0 || len(vocabulary.Blocked) != 0 {
+		t.Errorf("vocabulary not cleared: %+v", vocabulary)
+	}
+}
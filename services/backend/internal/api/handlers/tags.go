@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	log "github.com/sirupsen/logrus"
+	"video-archiver/internal/domain"
+)
+
+// UpdateTagRequest is the body for PATCH /tags/{id}. Omitted fields are left
+// unchanged; an empty color resets it and a parent_id of 0 makes the tag
+// top-level.
+type UpdateTagRequest struct {
+	Name     *string `json:"name,omitempty"`
+	Color    *string `json:"color,omitempty"`
+	ParentID *int64  `json:"parent_id,omitempty"`
+}
+
+// MergeTagsRequest is the body for POST /tags/merge.
+type MergeTagsRequest struct {
+	TargetID  int64   `json:"target_id"`
+	SourceIDs []int64 `json:"source_ids"`
+}
+
+// getTag loads the tag from the URL's {tagID}, writing the error response
+// itself when the tag can't be served.
+func (h *Handler) getTag(w http.ResponseWriter, r *http.Request) *domain.Tag {
+	id, err := strconv.ParseInt(chi.URLParam(r, "tagID"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid tag ID", http.StatusBadRequest)
+		return nil
+	}
+	tag, err := h.downloadService.GetRepository().GetTag(id)
+	if err != nil {
+		log.WithError(err).Error("Failed to get tag")
+		http.Error(w, "Failed to get tag", http.StatusInternalServerError)
+		return nil
+	}
+	if tag == nil {
+		http.Error(w, "Tag not found", http.StatusNotFound)
+		return nil
+	}
+	return tag
+}
+
+// HandleUpdateTag renames, recolors or re-parents a tag. Renaming onto an
+// existing tag's name is refused; merge the tags instead.
+func (h *Handler) HandleUpdateTag(w http.ResponseWriter, r *http.Request) {
+	tag := h.getTag(w, r)
+	if tag == nil {
+		return
+	}
+	var req UpdateTagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	repo := h.downloadService.GetRepository()
+
+	if req.Name != nil {
+		name := domain.NormalizeTagName(*req.Name)
+		if name == "" {
+			http.Error(w, "name must not be empty", http.StatusBadRequest)
+			return
+		}
+		existing, err := repo.GetTagByName(name)
+		if err != nil {
+			log.WithError(err).Error("Failed to look up tag")
+			http.Error(w, "Failed to update tag", http.StatusInternalServerError)
+			return
+		}
+		if existing != nil && existing.ID != tag.ID {
+			http.Error(w, fmt.Sprintf("Tag %q already exists; merge the tags instead", existing.Name), http.StatusConflict)
+			return
+		}
+		tag.Name = name
+	}
+	if req.Color != nil {
+		if !domain.ValidTagColor(*req.Color) {
+			http.Error(w, "color must be a #rrggbb hex color", http.StatusBadRequest)
+			return
+		}
+		tag.Color = *req.Color
+	}
+	if req.ParentID != nil {
+		if msg := h.checkTagParent(tag.ID, *req.ParentID); msg != "" {
+			http.Error(w, msg, http.StatusBadRequest)
+			return
+		}
+		tag.ParentID = req.ParentID
+		if *req.ParentID == 0 {
+			tag.ParentID = nil
+		}
+	}
+
+	if err := repo.UpdateTag(tag); err != nil {
+		log.WithError(err).Error("Failed to update tag")
+		http.Error(w, "Failed to update tag", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Message: tag})
+}
+
+// checkTagParent returns why parentID can't be the parent of the tag id, or
+// "" when it can. 0 (no parent) is always allowed.
+func (h *Handler) checkTagParent(id, parentID int64) string {
+	if parentID == 0 {
+		return ""
+	}
+	if parentID == id {
+		return "a tag can't be its own parent"
+	}
+	catalog, err := h.downloadService.GetRepository().ListTags()
+	if err != nil {
+		log.WithError(err).Error("Failed to list tags")
+		return "Failed to check the parent tag"
+	}
+	found := false
+	for _, t := range catalog {
+		found = found || t.ID == parentID
+	}
+	if !found {
+		return "parent tag not found"
+	}
+	if domain.TagParentCycle(catalog, id, parentID) {
+		return "the parent tag is nested under this tag"
+	}
+	return ""
+}
+
+// HandleMergeTags folds the source tags into the target tag and returns the
+// target afterwards.
+func (h *Handler) HandleMergeTags(w http.ResponseWriter, r *http.Request) {
+	var req MergeTagsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	if req.TargetID == 0 || len(req.SourceIDs) == 0 {
+		http.Error(w, "target_id and source_ids are required", http.StatusBadRequest)
+		return
+	}
+	// Each source is merged once; a repeated one would be gone by its second
+	// turn.
+	seen := make(map[int64]bool, len(req.SourceIDs))
+	sourceIDs := make([]int64, 0, len(req.SourceIDs))
+	for _, id := range req.SourceIDs {
+		if id == req.TargetID {
+			http.Error(w, "target_id must not be among source_ids", http.StatusBadRequest)
+			return
+		}
+		if !seen[id] {
+			seen[id] = true
+			sourceIDs = append(sourceIDs, id)
+		}
+	}
+	req.SourceIDs = sourceIDs
+
+	repo := h.downloadService.GetRepository()
+	for _, id := range append([]int64{req.TargetID}, req.SourceIDs...) {
+		tag, err := repo.GetTag(id)
+		if err != nil {
+			log.WithError(err).Error("Failed to get tag")
+			http.Error(w, "Failed to merge tags", http.StatusInternalServerError)
+			return
+		}
+		if tag == nil {
+			http.Error(w, fmt.Sprintf("Tag %d not found", id), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := repo.MergeTags(req.TargetID, req.SourceIDs); err != nil {
+		log.WithError(err).Error("Failed to merge tags")
+		http.Error(w, "Failed to merge tags", http.StatusInternalServerError)
+		return
+	}
+	target, err := repo.GetTag(req.TargetID)
+	if err != nil {
+		log.WithError(err).Error("Failed to get merged tag")
+		http.Error(w, "Failed to get merged tag", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Message: target})
+}
+
+// HandleDeleteTag removes a tag from every item and deletes it.
+func (h *Handler) HandleDeleteTag(w http.ResponseWriter, r *http.Request) {
+	tag := h.getTag(w, r)
+	if tag == nil {
+		return
+	}
+	if err := h.downloadService.GetRepository().DeleteTag(tag.ID); err != nil {
+		log.WithError(err).Error("Failed to delete tag")
+		http.Error(w, "Failed to delete tag", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Message: "Tag deleted successfully"})
+}
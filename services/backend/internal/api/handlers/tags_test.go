@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"video-archiver/internal/domain"
+	"video-archiver/internal/repositories/sqlite"
+	"video-archiver/internal/services/download"
+	"video-archiver/internal/testutil"
+)
+
+func TestTagManagementHandlers(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+	repo := sqlite.NewJobRepository(db)
+	service := download.NewService(&download.Config{JobRepository: repo, DownloadPath: "/tmp/test", Concurrency: 1})
+	handler := NewHandler(service, "/tmp/test", newMockSettingsRepository(), nil, nil, nil, nil)
+	r := chi.NewRouter()
+	handler.RegisterRoutes(r)
+
+	repo.Create(testutil.CreateTestJob("job-1", "https://youtube.com/watch?v=test"))
+	repo.AddTagsToJob("job-1", []string{"music", "jazz", "Jazz Music", "bebop", "swing"}, domain.TagSourceUser)
+	id := func(name string) string {
+		tag, _ := repo.GetTagByName(name)
+		return strconv.FormatInt(tag.ID, 10)
+	}
+	music, jazz, jazzMusic, bebop, swing := id("music"), id("jazz"), id("Jazz Music"), id("bebop"), id("swing")
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"rename", http.MethodPatch, "/tags/" + music, `{"name": "Music"}`, http.StatusOK, `"name":"Music"`},
+		{"rename onto existing", http.MethodPatch, "/tags/" + music, `{"name": "JAZZ"}`, http.StatusConflict, "merge"},
+		{"empty name", http.MethodPatch, "/tags/" + music, `{"name": " "}`, http.StatusBadRequest, ""},
+		{"recolor", http.MethodPatch, "/tags/" + music, `{"color": "#ff0000"}`, http.StatusOK, `"color":"#ff0000"`},
+		{"bad color", http.MethodPatch, "/tags/" + music, `{"color": "red"}`, http.StatusBadRequest, ""},
+		{"set parent", http.MethodPatch, "/tags/" + jazz, `{"parent_id": ` + music + `}`, http.StatusOK, `"parent_id":` + music},
+		{"parent cycle", http.MethodPatch, "/tags/" + music, `{"parent_id": ` + jazz + `}`, http.StatusBadRequest, ""},
+		{"own parent", http.MethodPatch, "/tags/" + music, `{"parent_id": ` + music + `}`, http.StatusBadRequest, ""},
+		{"missing parent", http.MethodPatch, "/tags/" + music, `{"parent_id": 999}`, http.StatusBadRequest, ""},
+		{"missing tag", http.MethodPatch, "/tags/999", `{"name": "x"}`, http.StatusNotFound, ""},
+		{"merge", http.MethodPost, "/tags/merge", `{"target_id": ` + jazz + `, "source_ids": [` + jazzMusic + `]}`, http.StatusOK, `"name":"jazz"`},
+		{"merge missing source", http.MethodPost, "/tags/merge", `{"target_id": ` + jazz + `, "source_ids": [999]}`, http.StatusBadRequest, ""},
+		{"merge without sources", http.MethodPost, "/tags/merge", `{"target_id": ` + jazz + `}`, http.StatusBadRequest, ""},
+		{"merge into a source", http.MethodPost, "/tags/merge", `{"target_id": ` + jazz + `, "source_ids": [` + swing + `, ` + jazz + `]}`, http.StatusBadRequest, ""},
+		{"merge repeated source", http.MethodPost, "/tags/merge", `{"target_id": ` + jazz + `, "source_ids": [` + swing + `, ` + swing + `]}`, http.StatusOK, `"name":"jazz"`},
+		{"delete", http.MethodDelete, "/tags/" + bebop, "", http.StatusOK, ""},
+		{"delete missing", http.MethodDelete, "/tags/" + bebop, "", http.StatusNotFound, ""},
+		{"clear parent", http.MethodPatch, "/tags/" + jazz, `{"parent_id": 0}`, http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantBody != "" && !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("body = %s, want it to contain %s", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+
+	tags, _ := repo.GetTagsForJob("job-1")
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+		if tag.ParentID != nil {
+			t.Errorf("tag %q still has a parent", tag.Name)
+		}
+	}
+	if got := strings.Join(names, ","); got != "jazz,Music" {
+		t.Errorf("tags = %s, want jazz,Music", got)
+	}
+}
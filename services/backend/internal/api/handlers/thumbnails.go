@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	log "github.com/sirupsen/logrus"
+	"video-archiver/internal/domain"
+	"video-archiver/internal/services/download"
+)
+
+// thumbnailWidths lists the resized variants the API generates. Limiting
+// widths keeps the cache bounded to a few files per job.
+var thumbnailWidths = []int{160, 320, 480, 640, 1280}
+
+func isValidThumbnailWidth(width int) bool {
+	for _, w := range thumbnailWidths {
+		if width == w {
+			return true
+		}
+	}
+	return false
+}
+
+// HandleServeJobThumbnail serves the locally archived thumbnail of a job.
+// ?w= selects a resized variant, generated on first request and cached.
+// Videos without a downloaded thumbnail (older downloads, or sources that had
+// none) fall back to a poster frame extracted from the media file. Playlists
+// use their archived cover; channels, and playlists archived before covers
+// were, use the thumbnail of their first video that has one.
+func (h *Handler) HandleServeJobThumbnail(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "id")
+	if jobID == "" {
+		http.Error(w, "Missing job ID", http.StatusBadRequest)
+		return
+	}
+	if !h.checkSignature(w, r, thumbnailURLPath(jobID)) {
+		return
+	}
+
+	width := 0
+	if v := r.URL.Query().Get("w"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || !isValidThumbnailWidth(n) {
+			http.Error(w, fmt.Sprintf("Invalid width, must be one of %v", thumbnailWidths), http.StatusBadRequest)
+			return
+		}
+		width = n
+	}
+
+	jobWithMetadata, err := h.downloadService.GetJobWithMetadata(jobID)
+	if err != nil || jobWithMetadata == nil || jobWithMetadata.Job == nil {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+
+	source, err := h.thumbnailSource(r.Context(), jobWithMetadata)
+	if err != nil {
+		log.WithError(err).WithField("jobID", jobID).Debug("No thumbnail for job")
+		http.Error(w, "Thumbnail not available", http.StatusNotFound)
+		return
+	}
+
+	path := source
+	if width > 0 {
+		path, err = h.resizedThumbnail(r.Context(), jobID, source, width)
+		if err != nil {
+			log.WithError(err).WithField("jobID", jobID).Error("Failed to resize thumbnail")
+			http.Error(w, "Failed to generate thumbnail", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	// Thumbnails can be replaced (re-download, poster regenerated), so allow
+	// caching but keep it short enough for changes to show up.
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	http.ServeFile(w, r, path)
+}
+
+// thumbnailSource returns the full-size thumbnail image for a job.
+func (h *Handler) thumbnailSource(ctx context.Context, jwm *domain.JobWithMetadata) (string, error) {
+	metadata, ok := jwm.Metadata.(*domain.VideoMetadata)
+	if !ok {
+		if path, ok := download.ArchivedThumbnail(h.downloadPath, jwm.Job.ID); ok {
+			return path, nil
+		}
+		videos, err := h.downloadService.GetRepository().GetVideosForParent(jwm.Job.ID)
+		if err != nil {
+			return "", fmt.Errorf("get videos: %w", err)
+		}
+		for _, video := range videos {
+			if video == nil || video.Job == nil {
+				continue
+			}
+			if _, isVideo := video.Metadata.(*domain.VideoMetadata); !isVideo {
+				continue
+			}
+			if path, err := h.thumbnailSource(ctx, video); err == nil {
+				return path, nil
+			}
+		}
+		return "", fmt.Errorf("no video in %s has a thumbnail", jwm.Job.ID)
+	}
+
+	mediaPath, err := h.locateVideoFile(jwm.Job, metadata)
+	if err != nil {
+		return "", err
+	}
+	if path, ok := download.LocalThumbnail(mediaPath); ok {
+		return path, nil
+	}
+	return h.posterThumbnail(ctx, jwm.Job, mediaPath, metadata)
+}
+
+// posterThumbnail extracts (once) a poster frame from a video's media file
+// into the thumbnail cache.
+func (h *Handler) posterThumbnail(ctx context.Context, job *domain.Job, mediaPath string, metadata *domain.VideoMetadata) (string, error) {
+	if job.IsAudio() {
+		return "", fmt.Errorf("audio download has no poster")
+	}
+	poster, err := download.ThumbnailCachePath(h.downloadPath, job.ID, 0)
+	if err != nil {
+		return "", err
+	}
+	if info, err := os.Stat(poster); err == nil && !info.IsDir() {
+		return poster, nil
+	}
+	if h.ffmpeg == nil {
+		return "", fmt.Errorf("ffmpeg not available")
+	}
+
+	// Same seek heuristic as tools output posters: early, but never past
+	// short videos.
+	seek := float64(metadata.Duration) * 0.1
+	if seek > 10 {
+		seek = 10
+	}
+	if metadata.Duration < 1 {
+		seek = 0
+	}
+	if err := generateThumbnail(poster, func(tmp string) error {
+		return h.ffmpeg.ExtractPoster(ctx, mediaPath, tmp, seek)
+	}); err != nil {
+		return "", err
+	}
+	return poster, nil
+}
+
+// resizedThumbnail returns the cached width variant of source, regenerating it
+// when the source is newer than the cached copy.
+func (h *Handler) resizedThumbnail(ctx context.Context, jobID, source string, width int) (string, error) {
+	path, err := download.ThumbnailCachePath(h.downloadPath, jobID, width)
+	if err != nil {
+		return "", err
+	}
+	srcInfo, err := os.Stat(source)
+	if err != nil {
+		return "", err
+	}
+	if info, err := os.Stat(path); err == nil && !info.ModTime().Before(srcInfo.ModTime()) {
+		return path, nil
+	}
+	if h.ffmpeg == nil {
+		return "", fmt.Errorf("ffmpeg not available")
+	}
+	if err := generateThumbnail(path, func(tmp string) error {
+		return h.ffmpeg.ResizeImage(ctx, source, tmp, width)
+	}); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// generateThumbnail runs generate into a temp file of its own and renames it
+// to path, so concurrent requests never see a partial image or write into each
+// other's; a racing double-generation is idempotent.
+func generateThumbnail(path string, generate func(tmp string) error) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create thumbnails directory: %w", err)
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), "*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp thumbnail: %w", err)
+	}
+	tmp := f.Name()
+	f.Close()
+	if err := generate(tmp); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("finalize thumbnail: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"video-archiver/internal/domain"
+	"video-archiver/internal/services/download"
+	"video-archiver/internal/testutil"
+)
+
+// newThumbnailTestServer returns a router backed by a temp download directory
+// holding one video (with a thumbnail sidecar) that belongs to a playlist.
+func newThumbnailTestServer(t *testing.T) (*chi.Mux, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	mockRepo := testutil.NewMockJobRepository()
+	service := download.NewService(&download.Config{
+		JobRepository: mockRepo,
+		DownloadPath:  dir,
+		Concurrency:   1,
+		MaxQuality:    1080,
+	})
+	handler := NewHandler(service, dir, newMockSettingsRepository(), nil, nil, nil, nil)
+
+	media := filepath.Join(dir, "Test Channel", "Test Video.mp4")
+	if err := os.MkdirAll(filepath.Dir(media), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(media, []byte("video"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "Test Channel", "Test Video.jpg"), []byte("thumb"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	video := testutil.CreateTestJob("video-id", "https://youtube.com/watch?v=test")
+	video.Status = domain.JobStatusComplete
+	video.FilePath = media
+	mockRepo.Create(video)
+	mockRepo.StoreMetadata(video.ID, testutil.CreateTestVideoMetadata())
+
+	playlist := testutil.CreateTestJob("playlist-id", "https://youtube.com/playlist?list=test")
+	mockRepo.Create(playlist)
+	mockRepo.StoreMetadata(playlist.ID, testutil.CreateTestPlaylistMetadata())
+	mockRepo.AddVideoToParent(video.ID, playlist.ID, "playlist")
+
+	r := chi.NewRouter()
+	handler.RegisterRoutes(r)
+	return r, dir
+}
+
+func TestHandleServeJobThumbnail(t *testing.T) {
+	r, dir := newThumbnailTestServer(t)
+
+	// A cached variant newer than the source is served without ffmpeg.
+	variant, err := download.ThumbnailCachePath(dir, "video-id", 320)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(variant), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(variant, []byte("small"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"video sidecar", "/job/video-id/thumbnail", http.StatusOK, "thumb"},
+		{"playlist uses first video", "/job/playlist-id/thumbnail", http.StatusOK, "thumb"},
+		{"cached variant", "/job/video-id/thumbnail?w=320", http.StatusOK, "small"},
+		{"unsupported width", "/job/video-id/thumbnail?w=123", http.StatusBadRequest, ""},
+		{"non-numeric width", "/job/video-id/thumbnail?w=big", http.StatusBadRequest, ""},
+		{"unknown job", "/job/missing/thumbnail", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.wantBody != "" && rec.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestHandleServeJobThumbnailWithoutSourceOrFFmpeg(t *testing.T) {
+	r, dir := newThumbnailTestServer(t)
+	if err := os.Remove(filepath.Join(dir, "Test Channel", "Test Video.jpg")); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/job/video-id/thumbnail", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestHandleServeJobThumbnailPlaylistCover(t *testing.T) {
+	r, dir := newThumbnailTestServer(t)
+	poster, err := download.ThumbnailCachePath(dir, "playlist-id", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(poster), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cover := strings.TrimSuffix(poster, ".jpg") + ".webp"
+	if err := os.WriteFile(cover, []byte("cover"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The archived cover wins over the first video's thumbnail.
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/job/playlist-id/thumbnail", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "cover" {
+		t.Errorf("status = %d, body = %q; want the archived cover", rec.Code, rec.Body.String())
+	}
+}
+
+func TestGenerateThumbnailCleansUpTempFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".thumbs", "job-w320.jpg")
+	var tmp string
+	err := generateThumbnail(path, func(name string) error {
+		tmp = name
+		return errors.New("ffmpeg failed")
+	})
+	if err == nil {
+		t.Fatal("expected the generate error")
+	}
+	if filepath.Dir(tmp) != filepath.Dir(path) || tmp == path+".tmp" {
+		t.Errorf("temp file %q should be a unique file next to %q", tmp, path)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 0 {
+		t.Errorf("thumbnail directory not cleaned up: %v", entries)
+	}
+}
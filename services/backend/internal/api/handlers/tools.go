@@ -57,6 +57,7 @@ var supportedOperations = []struct {
 	{domain.OpTypeExtractAudio, "Extract Audio", "Extract the audio track from a video", []string{"mp3", "aac", "flac", "wav", "ogg"}, 1},
 	{domain.OpTypeAdjustQuality, "Adjust Quality", "Change resolution, bitrate or CRF", []string{"mp4"}, 1},
 	{domain.OpTypeRotate, "Rotate Video", "Rotate or flip a video", []string{"mp4", "mkv", "webm"}, 1},
+	{domain.OpTypeRemux, "Remux", "Rewrap into another container without re-encoding", []string{"mp4", "mov", "mkv"}, 1},
 	{domain.OpTypeWorkflow, "Workflow", "Chain multiple operations together", []string{"mp4", "mkv", "webm"}, 1},
 }
 
@@ -52,7 +52,7 @@ func (r *inMemoryToolsRepo) FindLatestConvertForInput(jobID string) (*domain.Too
 	defer r.mu.Unlock()
 	var latest *domain.ToolsJob
 	for _, job := range r.jobs {
-		if job.OperationType != domain.OpTypeConvert || len(job.InputFiles) != 1 || job.InputFiles[0] != jobID {
+		if !job.ProducesPlaybackCopy() || len(job.InputFiles) != 1 || job.InputFiles[0] != jobID {
 			continue
 		}
 		if latest == nil || job.CreatedAt.After(latest.CreatedAt) {
@@ -62,6 +62,18 @@ func (r *inMemoryToolsRepo) FindLatestConvertForInput(jobID string) (*domain.Too
 	}
 	return latest, nil
 }
+func (r *inMemoryToolsRepo) ListBySourceJob(sourceJobID string) ([]*domain.ToolsJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []*domain.ToolsJob
+	for _, job := range r.jobs {
+		if job.SourceJobID == sourceJobID {
+			cp := *job
+			out = append(out, &cp)
+		}
+	}
+	return out, nil
+}
 func (r *inMemoryToolsRepo) Delete(string) error { return nil }
 func (r *inMemoryToolsRepo) List(page, limit int, status, operationType string) ([]*domain.ToolsJob, int, error) {
 	r.mu.Lock()
@@ -111,8 +123,8 @@ func TestHandleListOperations(t *testing.T) {
 	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
 		t.Fatalf("decode: %v", err)
 	}
-	if len(resp.Message) != 7 {
-		t.Errorf("expected 7 operations, got %d", len(resp.Message))
+	if len(resp.Message) != 8 {
+		t.Errorf("expected 8 operations, got %d", len(resp.Message))
 	}
 }
 
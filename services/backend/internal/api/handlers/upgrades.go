@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	log "github.com/sirupsen/logrus"
+)
+
+// StartUpgradeRequest selects what a quality upgrade run covers. No job IDs
+// means the whole library; playlist and channel jobs stand for their videos.
+type StartUpgradeRequest struct {
+	JobIDs []string `json:"job_ids,omitempty"`
+	// DryRun only reports which videos would improve, without downloading.
+	DryRun bool `json:"dry_run"`
+}
+
+// HandleStartUpgrade starts a quality upgrade run in the background and
+// returns it; progress is broadcast over the WebSocket and the report is
+// read from GET /upgrades/{id}.
+func (h *Handler) HandleStartUpgrade(w http.ResponseWriter, r *http.Request) {
+	var req StartUpgradeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if h.downloadService.IsUpgradeRunning() {
+		http.Error(w, "An upgrade run is already in progress", http.StatusConflict)
+		return
+	}
+
+	run, err := h.downloadService.StartUpgrade(req.JobIDs, req.DryRun)
+	if err != nil {
+		log.WithError(err).Error("Failed to start upgrade run")
+		http.Error(w, "Failed to start upgrade run", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, Response{Message: run})
+}
+
+// HandleListUpgrades returns the most recent upgrade runs without their
+// items.
+func (h *Handler) HandleListUpgrades(w http.ResponseWriter, r *http.Request) {
+	runs, err := h.downloadService.GetRepository().ListUpgradeRuns(parseIntQuery(r, "limit", 20))
+	if err != nil {
+		log.WithError(err).Error("Failed to list upgrade runs")
+		http.Error(w, "Failed to list upgrade runs", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Message: runs})
+}
+
+// HandleGetUpgrade returns the report of one upgrade run: its totals and what
+// happened to each video.
+func (h *Handler) HandleGetUpgrade(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid upgrade run ID", http.StatusBadRequest)
+		return
+	}
+
+	run, err := h.downloadService.GetRepository().GetUpgradeRun(id)
+	if err != nil {
+		http.Error(w, "Upgrade run not found", http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Message: run})
+}
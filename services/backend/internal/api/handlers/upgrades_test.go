@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"video-archiver/internal/domain"
+)
+
+func TestHandleUpgrades(t *testing.T) {
+	handler, mockRepo := setupTestHandler(t)
+	r := chi.NewRouter()
+	handler.RegisterRoutes(r)
+
+	run := &domain.UpgradeRun{Status: domain.UpgradeRunComplete, TargetHeight: 1080, Upgraded: 1, BytesBefore: 100, BytesAfter: 300}
+	mockRepo.CreateUpgradeRun(run)
+	mockRepo.AddUpgradeItem(&domain.UpgradeItem{RunID: run.ID, JobID: "video-1", Outcome: domain.UpgradeOutcomeUpgraded, OldHeight: 720, NewHeight: 1080})
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{"list", http.MethodGet, "/upgrades", "", http.StatusOK},
+		{"report", http.MethodGet, "/upgrades/1", "", http.StatusOK},
+		{"unknown run", http.MethodGet, "/upgrades/99", "", http.StatusNotFound},
+		{"invalid run ID", http.MethodGet, "/upgrades/abc", "", http.StatusBadRequest},
+		{"invalid body", http.MethodPost, "/upgrades", `{`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/upgrades/1", nil))
+	var resp struct {
+		Message domain.UpgradeRun `json:"message"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode report: %v", err)
+	}
+	if resp.Message.BytesAfter-resp.Message.BytesBefore != 200 || len(resp.Message.Items) != 1 || resp.Message.Items[0].NewHeight != 1080 {
+		t.Errorf("report = %+v", resp.Message)
+	}
+}
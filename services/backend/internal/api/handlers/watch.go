@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"video-archiver/internal/domain"
+)
+
+// defaultContinueWatchingLimit is how many videos continue-watching returns
+// without a limit.
+const defaultContinueWatchingLimit = 20
+
+// HandleSetWatchProgress records where playback of a video stopped. Players
+// call it periodically and on pause; the video becomes watched near its end
+// or when completed is sent.
+func (h *Handler) HandleSetWatchProgress(w http.ResponseWriter, r *http.Request) {
+	job, metadata, ok := h.videoJobFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req domain.WatchProgressUpdate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	progress, err := req.Apply(job.ID, float64(metadata.Duration), time.Now())
+	if err != nil {
+		http.Error(w, "Invalid progress: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.downloadService.GetRepository().SetWatchProgress(progress); err != nil {
+		log.WithError(err).Error("Failed to set watch progress")
+		http.Error(w, "Failed to set watch progress", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Message: progress})
+}
+
+// HandleResetWatchProgress marks a video unwatched again.
+func (h *Handler) HandleResetWatchProgress(w http.ResponseWriter, r *http.Request) {
+	job, _, ok := h.videoJobFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.downloadService.GetRepository().DeleteWatchProgress(job.ID); err != nil {
+		log.WithError(err).Error("Failed to reset watch progress")
+		http.Error(w, "Failed to reset watch progress", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Message: "Watch progress reset"})
+}
+
+// HandleContinueWatching lists videos that were started but not finished,
+// most recently watched first.
+func (h *Handler) HandleContinueWatching(w http.ResponseWriter, r *http.Request) {
+	limit := parseIntQuery(r, "limit", defaultContinueWatchingLimit)
+	if limit < 1 || limit > 100 {
+		limit = defaultContinueWatchingLimit
+	}
+
+	items, err := h.downloadService.GetRepository().GetContinueWatching(limit)
+	if err != nil {
+		log.WithError(err).Error("Failed to get continue watching")
+		http.Error(w, "Failed to get continue watching", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Response{Message: items})
+}
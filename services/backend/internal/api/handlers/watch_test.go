@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"video-archiver/internal/domain"
+	"video-archiver/internal/testutil"
+)
+
+func TestHandleWatchProgress(t *testing.T) {
+	handler, mockRepo := setupTestHandler(t)
+	r := chi.NewRouter()
+	handler.RegisterRoutes(r)
+
+	for _, id := range []string{"v1", "v2"} {
+		mockRepo.Create(testutil.CreateTestJob(id, "https://youtube.com/watch?v="+id))
+		mockRepo.StoreMetadata(id, testutil.CreateTestVideoMetadata())
+	}
+	mockRepo.Create(testutil.CreateTestJob("pl", "https://youtube.com/playlist?list=x"))
+	mockRepo.StoreMetadata("pl", testutil.CreateTestPlaylistMetadata())
+
+	put := func(path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, path, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := put("/video/v1/progress", `{"position": 95.5}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Message domain.WatchProgress `json:"message"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	// The duration defaults to the video's.
+	if resp.Message.Position != 95.5 || resp.Message.Duration != 300 || resp.Message.Completed {
+		t.Errorf("progress = %+v", resp.Message)
+	}
+	put("/video/v2/progress", `{"position": 0, "completed": true}`)
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/continue-watching", nil))
+	var listing struct {
+		Message []struct {
+			Job   domain.Job           `json:"job"`
+			Watch domain.WatchProgress `json:"watch"`
+		} `json:"message"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&listing); err != nil {
+		t.Fatalf("Failed to decode continue watching: %v", err)
+	}
+	if len(listing.Message) != 1 || listing.Message[0].Job.ID != "v1" || listing.Message[0].Watch.Position != 95.5 {
+		t.Errorf("continue watching = %+v, want only v1", listing.Message)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/video/v2/progress", nil))
+	if p, _ := mockRepo.GetWatchProgress("v2"); rec.Code != http.StatusOK || p != nil {
+		t.Errorf("reset status = %d, progress = %+v", rec.Code, p)
+	}
+
+	for _, tt := range []struct {
+		path, body string
+		want       int
+	}{
+		{"/video/v1/progress", `{"position": -5}`, http.StatusBadRequest},
+		{"/video/v1/progress", `not json`, http.StatusBadRequest},
+		{"/video/pl/progress", `{"position": 5}`, http.StatusBadRequest},
+		{"/video/missing/progress", `{"position": 5}`, http.StatusNotFound},
+	} {
+		if rec := put(tt.path, tt.body); rec.Code != tt.want {
+			t.Errorf("PUT %s %s status = %d, want %d", tt.path, tt.body, rec.Code, tt.want)
+		}
+	}
+}
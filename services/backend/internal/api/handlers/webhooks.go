@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+	"video-archiver/internal/domain"
+	"video-archiver/internal/services/webhooks"
+)
+
+// WebhooksHandler exposes CRUD for webhook endpoints, their delivery log and
+// a test delivery.
+type WebhooksHandler struct {
+	webhooks domain.WebhookRepository
+	service  *webhooks.Service
+}
+
+func NewWebhooksHandler(repository domain.WebhookRepository, service *webhooks.Service) *WebhooksHandler {
+	return &WebhooksHandler{webhooks: repository, service: service}
+}
+
+func (h *WebhooksHandler) RegisterRoutes(r chi.Router) {
+	r.Route("/webhooks", func(r chi.Router) {
+		r.Get("/", h.HandleList)
+		r.Post("/", h.HandleCreate)
+		r.Get("/events", h.HandleListEvents)
+		r.Get("/{id}", h.HandleGet)
+		r.Put("/{id}", h.HandleUpdate)
+		r.Delete("/{id}", h.HandleDelete)
+		r.Get("/{id}/deliveries", h.HandleListDeliveries)
+		r.Post("/{id}/test", h.HandleTest)
+	})
+}
+
+// WebhookRequest is the body for creating or updating a webhook. An empty
+// secret generates one on create and keeps the current one on update.
+type WebhookRequest struct {
+	Name    string                `json:"name"`
+	URL     string                `json:"url"`
+	Secret  string                `json:"secret,omitempty"`
+	Events  []domain.WebhookEvent `json:"events"`
+	Enabled *bool                 `json:"enabled,omitempty"`
+}
+
+// createdWebhook is the create response, the only one that includes the
+// secret so a generated one can be configured on the receiver.
+type createdWebhook struct {
+	*domain.Webhook
+	Secret string `json:"secret"`
+}
+
+// apply copies the request onto webhook and validates the result.
+func (req *WebhookRequest) apply(webhook *domain.Webhook) error {
+	webhook.Name = strings.TrimSpace(req.Name)
+	webhook.URL = strings.TrimSpace(req.URL)
+	webhook.Events = req.Events
+	if req.Secret != "" {
+		webhook.Secret = req.Secret
+	}
+	if req.Enabled != nil {
+		webhook.Enabled = *req.Enabled
+	}
+	return webhook.Validate()
+}
+
+// newWebhookSecret returns 32 random bytes, hex encoded.
+func newWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+func (h *WebhooksHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	list, err := h.webhooks.List()
+	if err != nil {
+		log.WithError(err).Error("Failed to list webhooks")
+		http.Error(w, "Failed to list webhooks", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Message: list})
+}
+
+// HandleListEvents returns the events a webhook can subscribe to.
+func (h *WebhooksHandler) HandleListEvents(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, Response{Message: domain.WebhookEvents})
+}
+
+func (h *WebhooksHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	var req WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	webhook := &domain.Webhook{
+		ID:        uuid.New().String(),
+		Enabled:   true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := req.apply(webhook); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if webhook.Secret == "" {
+		secret, err := newWebhookSecret()
+		if err != nil {
+			log.WithError(err).Error("Failed to generate webhook secret")
+			http.Error(w, "Failed to create webhook", http.StatusInternalServerError)
+			return
+		}
+		webhook.Secret = secret
+	}
+
+	if err := h.webhooks.Create(webhook); err != nil {
+		log.WithError(err).Error("Failed to create webhook")
+		http.Error(w, "Failed to create webhook", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, Response{Message: createdWebhook{Webhook: webhook, Secret: webhook.Secret}})
+}
+
+// getWebhook loads the webhook from the URL's {id}, writing the error
+// response itself when the webhook can't be served.
+func (h *WebhooksHandler) getWebhook(w http.ResponseWriter, r *http.Request) *domain.Webhook {
+	webhook, err := h.webhooks.GetByID(chi.URLParam(r, "id"))
+	if err != nil {
+		log.WithError(err).Error("Failed to get webhook")
+		http.Error(w, "Failed to get webhook", http.StatusInternalServerError)
+		return nil
+	}
+	if webhook == nil {
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+		return nil
+	}
+	return webhook
+}
+
+func (h *WebhooksHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	webhook := h.getWebhook(w, r)
+	if webhook == nil {
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Message: webhook})
+}
+
+func (h *WebhooksHandler) HandleUpdate(w http.ResponseWriter, r *http.Request) {
+	webhook := h.getWebhook(w, r)
+	if webhook == nil {
+		return
+	}
+
+	var req WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+	if err := req.apply(webhook); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.webhooks.Update(webhook); err != nil {
+		log.WithError(err).Error("Failed to update webhook")
+		http.Error(w, "Failed to update webhook", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Message: webhook})
+}
+
+func (h *WebhooksHandler) HandleDelete(w http.ResponseWriter, r *http.Request) {
+	webhook := h.getWebhook(w, r)
+	if webhook == nil {
+		return
+	}
+	if err := h.webhooks.Delete(webhook.ID); err != nil {
+		log.WithError(err).Error("Failed to delete webhook")
+		http.Error(w, "Failed to delete webhook", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Message: "Webhook deleted successfully"})
+}
+
+// HandleListDeliveries returns the webhook's delivery log, newest first.
+func (h *WebhooksHandler) HandleListDeliveries(w http.ResponseWriter, r *http.Request) {
+	webhook := h.getWebhook(w, r)
+	if webhook == nil {
+		return
+	}
+	limit := parseIntQuery(r, "limit", 50)
+	if limit > 500 {
+		limit = 500
+	}
+	deliveries, err := h.webhooks.ListDeliveries(webhook.ID, limit)
+	if err != nil {
+		log.WithError(err).Error("Failed to list webhook deliveries")
+		http.Error(w, "Failed to list webhook deliveries", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, Response{Message: deliveries})
+}
+
+// HandleTest sends a ping to the webhook and returns the queued delivery.
+func (h *WebhooksHandler) HandleTest(w http.ResponseWriter, r *http.Request) {
+	webhook := h.getWebhook(w, r)
+	if webhook == nil {
+		return
+	}
+	delivery, err := h.service.SendTest(webhook)
+	if err != nil {
+		log.WithError(err).Error("Failed to send webhook test")
+		http.Error(w, "Failed to send webhook test", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, Response{Message: delivery})
+}
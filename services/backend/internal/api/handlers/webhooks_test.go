@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"video-archiver/internal/domain"
+	"video-archiver/internal/repositories/sqlite"
+	"video-archiver/internal/services/webhooks"
+	"video-archiver/internal/testutil"
+)
+
+func TestWebhooksHandler(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+	repo := sqlite.NewWebhookRepository(db)
+	service := webhooks.NewService(&webhooks.Config{Repository: repo})
+	defer service.Stop()
+
+	r := chi.NewRouter()
+	NewWebhooksHandler(repo, service).RegisterRoutes(r)
+
+	pinged := make(chan string, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		pinged <- req.Header.Get(webhooks.EventHeader)
+	}))
+	defer receiver.Close()
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := do(http.MethodPost, "/webhooks", `{"name": "Chat", "url": "`+receiver.URL+`", "events": ["download.completed"]}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d (%s)", rec.Code, rec.Body.String())
+	}
+	var created struct {
+		Message struct {
+			domain.Webhook
+			Secret string `json:"secret"`
+		} `json:"message"`
+	}
+	json.NewDecoder(rec.Body).Decode(&created)
+	webhook := created.Message.Webhook
+	if webhook.ID == "" || len(created.Message.Secret) != 64 || !webhook.Enabled {
+		t.Errorf("created webhook = %+v", created.Message)
+	}
+	if stored, _ := repo.GetByID(webhook.ID); stored.Secret != created.Message.Secret {
+		t.Errorf("stored secret = %q, want the one returned on create", stored.Secret)
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{"invalid url", http.MethodPost, "/webhooks", `{"url": "ftp://example.com"}`, http.StatusBadRequest},
+		{"unknown event", http.MethodPost, "/webhooks", `{"url": "https://example.com", "events": ["nope"]}`, http.StatusBadRequest},
+		{"invalid body", http.MethodPost, "/webhooks", `{`, http.StatusBadRequest},
+		{"list", http.MethodGet, "/webhooks", "", http.StatusOK},
+		{"events", http.MethodGet, "/webhooks/events", "", http.StatusOK},
+		{"get", http.MethodGet, "/webhooks/" + webhook.ID, "", http.StatusOK},
+		{"unknown", http.MethodGet, "/webhooks/missing", "", http.StatusNotFound},
+		{"update", http.MethodPut, "/webhooks/" + webhook.ID, `{"name": "Chat", "url": "` + receiver.URL + `", "enabled": false}`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := do(tt.method, tt.path, tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if strings.Contains(rec.Body.String(), "secret") {
+				t.Errorf("response leaks the secret: %s", rec.Body.String())
+			}
+		})
+	}
+
+	updated, _ := repo.GetByID(webhook.ID)
+	if updated.Enabled || updated.Secret != created.Message.Secret || len(updated.Events) != 0 {
+		t.Errorf("updated webhook = %+v, want disabled with the secret kept", updated)
+	}
+
+	rec = do(http.MethodPut, "/webhooks/"+webhook.ID, `{"name": "Chat", "url": "`+receiver.URL+`", "secret": "rotated", "enabled": false}`)
+	if rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), "rotated") {
+		t.Errorf("rotate secret = %d (%s)", rec.Code, rec.Body.String())
+	}
+	if rotated, _ := repo.GetByID(webhook.ID); rotated.Secret != "rotated" {
+		t.Errorf("secret after update = %q, want rotated", rotated.Secret)
+	}
+
+	// A test ping goes out even to a disabled webhook.
+	if rec := do(http.MethodPost, "/webhooks/"+webhook.ID+"/test", ""); rec.Code != http.StatusAccepted {
+		t.Fatalf("test status = %d", rec.Code)
+	}
+	select {
+	case event := <-pinged:
+		if event != string(domain.WebhookEventPing) {
+			t.Errorf("event = %q, want ping", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("test ping not received")
+	}
+
+	rec = do(http.MethodGet, "/webhooks/"+webhook.ID+"/deliveries", "")
+	var deliveries struct {
+		Message []domain.WebhookDelivery `json:"message"`
+	}
+	json.NewDecoder(rec.Body).Decode(&deliveries)
+	if rec.Code != http.StatusOK || len(deliveries.Message) != 1 || deliveries.Message[0].Event != domain.WebhookEventPing {
+		t.Errorf("deliveries = %d %+v", rec.Code, deliveries.Message)
+	}
+
+	if rec := do(http.MethodDelete, "/webhooks/"+webhook.ID, ""); rec.Code != http.StatusOK {
+		t.Errorf("delete status = %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/webhooks/"+webhook.ID, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("get after delete status = %d", rec.Code)
+	}
+}
@@ -10,6 +10,16 @@ type Config struct {
 		DownloadPath  string `env:"DOWNLOAD_PATH" envDefault:"./data/downloads"`
 		ProcessedPath string `env:"PROCESSED_PATH" envDefault:"./data/processed"`
 		DatabasePath  string `env:"DATABASE_PATH" envDefault:"./data/db/video-archiver.db"`
+		// PublicURL is the backend's address as players reach it, used for the
+		// links in exported playlists; by default it is derived from the
+		// request.
+		PublicURL string `env:"PUBLIC_URL"`
+		// URLSigningKey enables signed video links in exported playlists.
+		URLSigningKey string `env:"URL_SIGNING_KEY"`
+		// HLSCacheSizeMB caps the transcoded HLS segments kept on disk.
+		HLSCacheSizeMB int64 `env:"HLS_CACHE_SIZE_MB" envDefault:"2048"`
+		// ExportCacheSizeMB caps the finished zip exports kept for resuming.
+		ExportCacheSizeMB int64 `env:"EXPORT_CACHE_SIZE_MB" envDefault:"10240"`
 	}
 	YtDlp struct {
 		Concurrency int `env:"YTDLP_CONCURRENCY" envDefault:"2"`
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MaxAnnotationNotes caps the length of an item's notes, in bytes.
+const MaxAnnotationNotes = 64 << 10
+
+// AnnotationKind is the kind of library item an annotation belongs to.
+type AnnotationKind string
+
+const (
+	// AnnotationKindJob covers videos, playlists and channels.
+	AnnotationKindJob        AnnotationKind = "job"
+	AnnotationKindCollection AnnotationKind = "collection"
+)
+
+// Annotation holds a user's own marks on a job or collection.
+type Annotation struct {
+	Favorite bool `json:"favorite"`
+	// Rating is 1 to 5 stars; 0 means unrated.
+	Rating int `json:"rating"`
+	// Notes are free-form Markdown.
+	Notes     string    `json:"notes"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// IsEmpty reports whether the annotation carries nothing worth storing.
+func (a *Annotation) IsEmpty() bool {
+	return !a.Favorite && a.Rating == 0 && strings.TrimSpace(a.Notes) == ""
+}
+
+// AnnotationUpdate changes the fields it sets and keeps the others.
+type AnnotationUpdate struct {
+	Favorite *bool   `json:"favorite,omitempty"`
+	Rating   *int    `json:"rating,omitempty"`
+	Notes    *string `json:"notes,omitempty"`
+}
+
+// Apply returns current, which may be nil, with the update applied.
+func (u AnnotationUpdate) Apply(current *Annotation, at time.Time) (*Annotation, error) {
+	a := Annotation{}
+	if current != nil {
+		a = *current
+	}
+	if u.Favorite != nil {
+		a.Favorite = *u.Favorite
+	}
+	if u.Rating != nil {
+		if *u.Rating < 0 || *u.Rating > 5 {
+			return nil, fmt.Errorf("rating must be between 1 and 5, or 0 to clear it")
+		}
+		a.Rating = *u.Rating
+	}
+	if u.Notes != nil {
+		if len(*u.Notes) > MaxAnnotationNotes {
+			return nil, fmt.Errorf("notes can't be longer than %d bytes", MaxAnnotationNotes)
+		}
+		a.Notes = *u.Notes
+	}
+	a.UpdatedAt = at
+	return &a, nil
+}
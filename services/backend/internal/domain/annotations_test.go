@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAnnotationUpdateApply(t *testing.T) {
+	yes, four, notes := true, 4, "Great *intro*"
+	current := &Annotation{Rating: 2, Notes: "old"}
+
+	got, err := AnnotationUpdate{Favorite: &yes, Rating: &four}.Apply(current, time.Now())
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	// Notes weren't sent, so they are kept.
+	if !got.Favorite || got.Rating != 4 || got.Notes != "old" {
+		t.Errorf("Apply() = %+v", got)
+	}
+	if current.Rating != 2 {
+		t.Error("Apply() modified the current annotation")
+	}
+
+	got, err = AnnotationUpdate{Notes: &notes}.Apply(nil, time.Now())
+	if err != nil || got.Notes != notes || got.IsEmpty() {
+		t.Errorf("Apply(nil) = %+v, %v", got, err)
+	}
+
+	zero, empty := 0, " "
+	got, _ = AnnotationUpdate{Rating: &zero, Notes: &empty}.Apply(current, time.Now())
+	if !got.IsEmpty() {
+		t.Errorf("cleared annotation %+v is not empty", got)
+	}
+
+	six, long := 6, strings.Repeat("x", MaxAnnotationNotes+1)
+	for _, u := range []AnnotationUpdate{{Rating: &six}, {Notes: &long}} {
+		if _, err := u.Apply(nil, time.Now()); err == nil {
+			t.Errorf("Apply(%+v) error = nil", u)
+		}
+	}
+}
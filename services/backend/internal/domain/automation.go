@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AutomationRule runs a saved tools workflow on every downloaded video that
+// matches its conditions, as soon as the download completes. The tools jobs
+// it submits carry the rule's ID and the video's download job ID.
+type AutomationRule struct {
+	ID        string             `json:"id"`
+	Name      string             `json:"name"`
+	Enabled   bool               `json:"enabled"`
+	Match     AutomationMatch    `json:"match"`
+	Workflow  WorkflowParameters `json:"workflow"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+}
+
+// AutomationMatch holds the conditions a video must meet for a rule to fire.
+// Unset conditions are ignored, so the zero value matches every video.
+type AutomationMatch struct {
+	MediaType MediaType `json:"media_type,omitempty"`
+	// Tags matches videos carrying any of these tags (case-insensitive).
+	Tags []string `json:"tags,omitempty"`
+	// Channel matches the channel name, channel ID or uploader
+	// (case-insensitive).
+	Channel string `json:"channel,omitempty"`
+	// BrowserSafe matches on whether the file's codecs play in browsers
+	// without a transcode (see BrowserSafeCodecs); false targets the files
+	// that need one. Audio-only files never match this condition.
+	BrowserSafe *bool `json:"browser_safe,omitempty"`
+	// MinDuration and MaxDuration bound the video length in seconds; 0 is
+	// unbounded.
+	MinDuration int `json:"min_duration,omitempty"`
+	MaxDuration int `json:"max_duration,omitempty"`
+}
+
+// AutomationSubject describes a downloaded video for rule matching.
+type AutomationSubject struct {
+	MediaType MediaType
+	Tags      []string
+	// Channels holds the channel name, channel ID and uploader.
+	Channels []string
+	Duration int // seconds
+	// BrowserSafe is nil when the file couldn't be probed or has no video
+	// stream.
+	BrowserSafe *bool
+}
+
+// Matches reports whether subject meets every condition of the match.
+func (m *AutomationMatch) Matches(subject AutomationSubject) bool {
+	if m.MediaType != "" && m.MediaType != subject.effectiveMediaType() {
+		return false
+	}
+	if len(m.Tags) > 0 && !anyFold(m.Tags, subject.Tags) {
+		return false
+	}
+	if m.Channel != "" && !anyFold([]string{m.Channel}, subject.Channels) {
+		return false
+	}
+	if m.BrowserSafe != nil && (subject.BrowserSafe == nil || *subject.BrowserSafe != *m.BrowserSafe) {
+		return false
+	}
+	if m.MinDuration > 0 && subject.Duration < m.MinDuration {
+		return false
+	}
+	if m.MaxDuration > 0 && subject.Duration > m.MaxDuration {
+		return false
+	}
+	return true
+}
+
+// effectiveMediaType treats the legacy empty media type as video, like
+// Job.IsAudio.
+func (s AutomationSubject) effectiveMediaType() MediaType {
+	if s.MediaType == "" {
+		return MediaTypeVideo
+	}
+	return s.MediaType
+}
+
+// anyFold reports whether any of want equals any of have, ignoring case.
+func anyFold(want, have []string) bool {
+	for _, w := range want {
+		for _, h := range have {
+			if h != "" && strings.EqualFold(strings.TrimSpace(w), h) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Validate checks the rule's name and conditions and returns a message
+// suitable for API clients. The workflow itself is validated by the tools
+// service.
+func (r *AutomationRule) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	switch r.Match.MediaType {
+	case "", MediaTypeVideo, MediaTypeAudio:
+	default:
+		return fmt.Errorf("invalid media_type: %q", r.Match.MediaType)
+	}
+	if r.Match.MinDuration < 0 || r.Match.MaxDuration < 0 {
+		return fmt.Errorf("durations must not be negative")
+	}
+	if r.Match.MaxDuration > 0 && r.Match.MinDuration > r.Match.MaxDuration {
+		return fmt.Errorf("min_duration must not exceed max_duration")
+	}
+	return nil
+}
+
+//tygo:ignore
+type AutomationRepository interface {
+	Create(rule *AutomationRule) error
+	Update(rule *AutomationRule) error
+	Delete(id string) error
+	// GetByID returns nil when the rule doesn't exist.
+	GetByID(id string) (*AutomationRule, error)
+	List() ([]*AutomationRule, error)
+}
@@ -0,0 +1,64 @@
+package domain
+
+import "testing"
+
+func TestAutomationMatchMatches(t *testing.T) {
+	safe, notSafe := true, false
+	subject := AutomationSubject{
+		Tags:        []string{"Podcast", "news"},
+		Channels:    []string{"Some Channel", "UC123", ""},
+		Duration:    600,
+		BrowserSafe: &notSafe,
+	}
+
+	tests := []struct {
+		name    string
+		match   AutomationMatch
+		subject AutomationSubject
+		want    bool
+	}{
+		{"empty match", AutomationMatch{}, subject, true},
+		{"legacy media type is video", AutomationMatch{MediaType: MediaTypeVideo}, subject, true},
+		{"media type mismatch", AutomationMatch{MediaType: MediaTypeAudio}, subject, false},
+		{"any tag, case-insensitive", AutomationMatch{Tags: []string{"music", "podcast"}}, subject, true},
+		{"no tag matches", AutomationMatch{Tags: []string{"music"}}, subject, false},
+		{"channel by name", AutomationMatch{Channel: "some channel"}, subject, true},
+		{"channel by ID", AutomationMatch{Channel: "UC123"}, subject, true},
+		{"other channel", AutomationMatch{Channel: "Other"}, subject, false},
+		{"needs transcode", AutomationMatch{BrowserSafe: &notSafe}, subject, true},
+		{"browser safe only", AutomationMatch{BrowserSafe: &safe}, subject, false},
+		{"codec unknown", AutomationMatch{BrowserSafe: &notSafe}, AutomationSubject{}, false},
+		{"within duration", AutomationMatch{MinDuration: 300, MaxDuration: 900}, subject, true},
+		{"too short", AutomationMatch{MinDuration: 900}, subject, false},
+		{"too long", AutomationMatch{MaxDuration: 300}, subject, false},
+		{"all conditions", AutomationMatch{Tags: []string{"news"}, Channel: "UC123", MinDuration: 60}, subject, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.match.Matches(tt.subject); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAutomationRuleValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    AutomationRule
+		wantErr bool
+	}{
+		{"valid", AutomationRule{Name: "mp3", Match: AutomationMatch{MediaType: MediaTypeVideo}}, false},
+		{"missing name", AutomationRule{Name: "  "}, true},
+		{"bad media type", AutomationRule{Name: "x", Match: AutomationMatch{MediaType: "image"}}, true},
+		{"negative duration", AutomationRule{Name: "x", Match: AutomationMatch{MinDuration: -1}}, true},
+		{"inverted durations", AutomationRule{Name: "x", Match: AutomationMatch{MinDuration: 20, MaxDuration: 10}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.rule.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package domain
+
+import "time"
+
+// Availability is the upstream state of an archived video's source, as
+// determined by the availability checker.
+type Availability string
+
+const (
+	AvailabilityAvailable         Availability = "available"
+	AvailabilityPrivate           Availability = "private"
+	AvailabilityRemoved           Availability = "removed"
+	AvailabilityGeoBlocked        Availability = "geo_blocked"
+	AvailabilityAccountTerminated Availability = "account_terminated"
+)
+
+// IsValid reports whether a is one of the known availability states.
+func (a Availability) IsValid() bool {
+	switch a {
+	case AvailabilityAvailable, AvailabilityPrivate, AvailabilityRemoved,
+		AvailabilityGeoBlocked, AvailabilityAccountTerminated:
+		return true
+	}
+	return false
+}
+
+// AvailabilityStatus is the latest check result for a video. FirstSeenAt is
+// when the current status was first observed; it only moves when the status
+// changes, so "removed since" survives repeated checks.
+type AvailabilityStatus struct {
+	JobID       string       `json:"job_id"`
+	Status      Availability `json:"status"`
+	Reason      string       `json:"reason,omitempty"` // yt-dlp's error message, if any
+	FirstSeenAt time.Time    `json:"first_seen_at"`
+	CheckedAt   time.Time    `json:"checked_at"`
+}
+
+// AvailabilityUpdate is broadcast when an archived video's upstream status
+// changes.
+type AvailabilityUpdate struct {
+	Type     string       `json:"type"` // always "availability"
+	JobID    string       `json:"jobID"`
+	Title    string       `json:"title"`
+	Status   Availability `json:"status"`
+	Previous Availability `json:"previous,omitempty"`
+	Reason   string       `json:"reason,omitempty"`
+}
@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultClipLength is how long, in seconds, an exported clip of a bookmark
+// without an end runs.
+const DefaultClipLength = 30
+
+// MaxBookmarkLabel caps the length of a bookmark's label, in runes.
+const MaxBookmarkLabel = 200
+
+// Bookmark marks a moment of a video or, with an end, a clip of it.
+type Bookmark struct {
+	ID    string `json:"id"`
+	JobID string `json:"job_id"`
+	// Start and End are in seconds; End is nil for a single moment.
+	Start     float64   `json:"start"`
+	End       *float64  `json:"end,omitempty"`
+	Label     string    `json:"label"`
+	Tags      []string  `json:"tags"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Normalize trims the label and normalizes and de-duplicates the tags,
+// case-insensitively.
+func (b *Bookmark) Normalize() {
+	b.Label = strings.TrimSpace(b.Label)
+	tags := make([]string, 0, len(b.Tags))
+	seen := make(map[string]bool, len(b.Tags))
+	for _, tag := range b.Tags {
+		tag = NormalizeTagName(tag)
+		if tag == "" || seen[strings.ToLower(tag)] {
+			continue
+		}
+		seen[strings.ToLower(tag)] = true
+		tags = append(tags, tag)
+	}
+	b.Tags = tags
+}
+
+// Validate checks the times and label and returns a message suitable for API
+// clients.
+func (b *Bookmark) Validate() error {
+	if b.Start < 0 {
+		return fmt.Errorf("start can't be negative")
+	}
+	if b.End != nil && *b.End <= b.Start {
+		return fmt.Errorf("end must be after start")
+	}
+	if len([]rune(b.Label)) > MaxBookmarkLabel {
+		return fmt.Errorf("label can't be longer than %d characters", MaxBookmarkLabel)
+	}
+	return nil
+}
+
+// ClipEnd is where a clip of the bookmark ends: its end, or length seconds
+// after its start, cut short at duration when the video's duration is known.
+func (b *Bookmark) ClipEnd(length, duration float64) float64 {
+	end := b.Start + length
+	if b.End != nil {
+		end = *b.End
+	}
+	if duration > 0 && end > duration {
+		end = duration
+	}
+	return end
+}
+
+// BookmarkExportMode chooses how bookmarks are exported.
+type BookmarkExportMode string
+
+const (
+	// BookmarkExportClips trims every bookmark into its own file.
+	BookmarkExportClips BookmarkExportMode = "clips"
+	// BookmarkExportReel joins the bookmarks into one highlight reel.
+	BookmarkExportReel BookmarkExportMode = "reel"
+)
+
+func (m BookmarkExportMode) IsValid() bool {
+	return m == BookmarkExportClips || m == BookmarkExportReel
+}
+
+//tygo:ignore
+type BookmarkRepository interface {
+	Create(bookmark *Bookmark) error
+	Update(bookmark *Bookmark) error
+	Delete(id string) error
+	GetByID(id string) (*Bookmark, error)
+	// ListForVideo returns a video's bookmarks in playback order.
+	ListForVideo(jobID string) ([]*Bookmark, error)
+	// List returns all bookmarks, or those carrying tag, newest first.
+	List(tag string) ([]*Bookmark, error)
+}
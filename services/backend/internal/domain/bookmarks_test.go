@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBookmarkValidate(t *testing.T) {
+	end, before := 20.0, 5.0
+	tests := []struct {
+		name     string
+		bookmark Bookmark
+		wantErr  bool
+	}{
+		{"moment", Bookmark{Start: 10}, false},
+		{"clip", Bookmark{Start: 10, End: &end}, false},
+		{"negative start", Bookmark{Start: -1}, true},
+		{"end before start", Bookmark{Start: 10, End: &before}, true},
+		{"long label", Bookmark{Label: strings.Repeat("x", MaxBookmarkLabel+1)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.bookmark.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBookmarkNormalize(t *testing.T) {
+	b := Bookmark{Label: "  Goal ", Tags: []string{" Sports ", "sports", "", "big   play"}}
+	b.Normalize()
+	if b.Label != "Goal" {
+		t.Errorf("Label = %q, want %q", b.Label, "Goal")
+	}
+	if want := []string{"Sports", "big play"}; strings.Join(b.Tags, ",") != strings.Join(want, ",") {
+		t.Errorf("Tags = %q, want %q", b.Tags, want)
+	}
+}
+
+func TestBookmarkClipEnd(t *testing.T) {
+	end := 50.0
+	tests := []struct {
+		name     string
+		bookmark Bookmark
+		duration float64
+		want     float64
+	}{
+		{"moment", Bookmark{Start: 10}, 300, 40},
+		{"clip", Bookmark{Start: 10, End: &end}, 300, 50},
+		{"cut at the end of the video", Bookmark{Start: 290}, 300, 300},
+		{"unknown duration", Bookmark{Start: 290}, 0, 320},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.bookmark.ClipEnd(30, tt.duration); got != tt.want {
+				t.Errorf("ClipEnd() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// BulkAction is what a bulk operation does to each selected library item.
+type BulkAction string
+
+const (
+	BulkActionAddTags         BulkAction = "add_tags"
+	BulkActionRemoveTags      BulkAction = "remove_tags"
+	BulkActionAddToCollection BulkAction = "add_to_collection"
+	BulkActionDelete          BulkAction = "delete"
+	BulkActionRedownload      BulkAction = "redownload"
+	BulkActionRefreshMetadata BulkAction = "refresh_metadata"
+	// BulkActionTools submits one tools job per item.
+	BulkActionTools BulkAction = "tools"
+)
+
+type BulkStatus string
+
+const (
+	BulkStatusRunning  BulkStatus = "running"
+	BulkStatusComplete BulkStatus = "complete"
+	// BulkStatusStopped: the service shut down before the operation finished.
+	BulkStatusStopped BulkStatus = "stopped"
+)
+
+// BulkOutcome is what a bulk operation did with one item.
+type BulkOutcome string
+
+const (
+	BulkOutcomeSucceeded BulkOutcome = "succeeded"
+	// BulkOutcomeSkipped: the action does not apply to the item, e.g. adding
+	// a playlist to a collection.
+	BulkOutcomeSkipped BulkOutcome = "skipped"
+	BulkOutcomeFailed  BulkOutcome = "failed"
+)
+
+// BulkParameters configures a bulk action; which fields apply depends on the
+// action.
+type BulkParameters struct {
+	// Tags for add_tags and remove_tags.
+	Tags []string `json:"tags,omitempty"`
+	// CollectionID for add_to_collection.
+	CollectionID string `json:"collection_id,omitempty"`
+	// DeleteFiles makes delete remove media files too, not only the library
+	// records.
+	DeleteFiles bool `json:"delete_files,omitempty"`
+	// Operation and ToolsParameters describe the tools job submitted per
+	// item for tools.
+	Operation       ToolsOperationType `json:"operation,omitempty"`
+	ToolsParameters map[string]any     `json:"tools_parameters,omitempty"`
+}
+
+// BulkQuery selects every item a library listing would show, across all
+// pages.
+type BulkQuery struct {
+	// Type is "videos", "playlists" or "channels".
+	Type         string       `json:"type"`
+	Search       string       `json:"search,omitempty"`
+	Tag          string       `json:"tag,omitempty"`
+	Availability Availability `json:"availability,omitempty"`
+	// Q is a library query, see ParseLibraryQuery.
+	Q string `json:"q,omitempty"`
+}
+
+// BulkRequest selects library items, either by job ID or with a query, and
+// names the action to apply to each of them.
+type BulkRequest struct {
+	Action     BulkAction     `json:"action"`
+	JobIDs     []string       `json:"job_ids,omitempty"`
+	Query      *BulkQuery     `json:"query,omitempty"`
+	Parameters BulkParameters `json:"parameters"`
+}
+
+// Validate checks the request and returns a message suitable for API clients.
+// Tools parameters are validated by the tools service.
+func (r *BulkRequest) Validate() error {
+	if (len(r.JobIDs) == 0) == (r.Query == nil) {
+		return fmt.Errorf("select items with either job_ids or query")
+	}
+	if r.Query != nil {
+		switch r.Query.Type {
+		case "videos", "playlists", "channels":
+		default:
+			return fmt.Errorf("query type must be videos, playlists or channels")
+		}
+		filters, err := ParseLibraryQuery(r.Query.Q)
+		if err == nil {
+			err = CheckQueryFilters(filters, r.Query.Type)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid query: %w", err)
+		}
+	}
+
+	switch r.Action {
+	case BulkActionAddTags, BulkActionRemoveTags:
+		if len(r.Parameters.Tags) == 0 {
+			return fmt.Errorf("%s requires tags", r.Action)
+		}
+	case BulkActionAddToCollection:
+		if r.Parameters.CollectionID == "" {
+			return fmt.Errorf("add_to_collection requires collection_id")
+		}
+	case BulkActionTools:
+		if r.Parameters.Operation == "" {
+			return fmt.Errorf("tools requires an operation")
+		}
+		if r.Parameters.Operation == OpTypeConcat {
+			return fmt.Errorf("concat joins several videos and can't run per item")
+		}
+	case BulkActionDelete, BulkActionRedownload, BulkActionRefreshMetadata:
+	default:
+		return fmt.Errorf("unknown action: %q", r.Action)
+	}
+	return nil
+}
+
+// BulkOperation is one action applied to a selection of library items in the
+// background. The counters form the report; Items is only populated when a
+// single operation is requested.
+type BulkOperation struct {
+	ID         int64          `json:"id"`
+	Action     BulkAction     `json:"action"`
+	Parameters BulkParameters `json:"parameters"`
+	Status     BulkStatus     `json:"status"`
+	Total      int            `json:"total"`
+	Processed  int            `json:"processed"`
+	Succeeded  int            `json:"succeeded"`
+	Skipped    int            `json:"skipped"`
+	Failed     int            `json:"failed"`
+	StartedAt  time.Time      `json:"started_at"`
+	FinishedAt *time.Time     `json:"finished_at,omitempty"`
+	Items      []BulkItem     `json:"items,omitempty"`
+}
+
+// BulkItem records what a bulk operation did with one library item. Detail
+// holds a result worth following up on, such as the submitted tools job's ID.
+type BulkItem struct {
+	ID          int64       `json:"id"`
+	OperationID int64       `json:"operation_id"`
+	JobID       string      `json:"job_id"`
+	Title       string      `json:"title"`
+	Outcome     BulkOutcome `json:"outcome"`
+	Detail      string      `json:"detail,omitempty"`
+	Error       string      `json:"error,omitempty"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+// BulkUpdate is broadcast over the WebSocket as a bulk operation progresses.
+type BulkUpdate struct {
+	Type        string     `json:"type"` // always "bulk"
+	OperationID int64      `json:"operationID"`
+	Action      BulkAction `json:"action"`
+	Status      BulkStatus `json:"status"`
+	Total       int        `json:"total"`
+	Processed   int        `json:"processed"`
+	Succeeded   int        `json:"succeeded"`
+	Skipped     int        `json:"skipped"`
+	Failed      int        `json:"failed"`
+}
+
+//tygo:ignore
+type BulkRepository interface {
+	// Create and Update persist an operation's totals; AddItem records its
+	// result for one item.
+	Create(operation *BulkOperation) error
+	Update(operation *BulkOperation) error
+	AddItem(item *BulkItem) error
+	// GetByID returns an operation with its items, or nil when it doesn't
+	// exist. List returns the most recent operations without items.
+	GetByID(id int64) (*BulkOperation, error)
+	List(limit int) ([]BulkOperation, error)
+}
@@ -0,0 +1,31 @@
+package domain
+
+import "testing"
+
+func TestBulkRequestValidate(t *testing.T) {
+	ids := []string{"job-1"}
+	tests := []struct {
+		name    string
+		req     BulkRequest
+		wantErr bool
+	}{
+		{"tags by id", BulkRequest{Action: BulkActionAddTags, JobIDs: ids, Parameters: BulkParameters{Tags: []string{"x"}}}, false},
+		{"delete by query", BulkRequest{Action: BulkActionDelete, Query: &BulkQuery{Type: "playlists"}}, false},
+		{"tools", BulkRequest{Action: BulkActionTools, JobIDs: ids, Parameters: BulkParameters{Operation: OpTypeConvert}}, false},
+		{"no selection", BulkRequest{Action: BulkActionDelete}, true},
+		{"both selections", BulkRequest{Action: BulkActionDelete, JobIDs: ids, Query: &BulkQuery{Type: "videos"}}, true},
+		{"bad query type", BulkRequest{Action: BulkActionDelete, Query: &BulkQuery{Type: "comments"}}, true},
+		{"tags missing", BulkRequest{Action: BulkActionRemoveTags, JobIDs: ids}, true},
+		{"collection missing", BulkRequest{Action: BulkActionAddToCollection, JobIDs: ids}, true},
+		{"tools without operation", BulkRequest{Action: BulkActionTools, JobIDs: ids}, true},
+		{"concat per item", BulkRequest{Action: BulkActionTools, JobIDs: ids, Parameters: BulkParameters{Operation: OpTypeConcat}}, true},
+		{"unknown action", BulkRequest{Action: "archive", JobIDs: ids}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.req.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// ChannelTab is a section of a channel page that can be downloaded.
+type ChannelTab string
+
+const (
+	ChannelTabVideos    ChannelTab = "videos"
+	ChannelTabShorts    ChannelTab = "shorts"
+	ChannelTabStreams   ChannelTab = "streams"
+	ChannelTabPlaylists ChannelTab = "playlists"
+)
+
+// IsValid reports whether t is one of the downloadable tabs.
+func (t ChannelTab) IsValid() bool {
+	switch t {
+	case ChannelTabVideos, ChannelTabShorts, ChannelTabStreams, ChannelTabPlaylists:
+		return true
+	}
+	return false
+}
+
+// ChannelDownloadOptions narrows what a channel download fetches. They are
+// stored on the channel's job so every re-sync applies the same rules. Zero
+// values mean "no restriction"; no tabs means the channel URL as submitted.
+type ChannelDownloadOptions struct {
+	Tabs []ChannelTab `json:"tabs,omitempty"`
+	// DateAfter and DateBefore bound the upload date (YYYYMMDD, inclusive).
+	DateAfter  string `json:"date_after,omitempty"`
+	DateBefore string `json:"date_before,omitempty"`
+	// MaxItems stops the download after this many videos.
+	MaxItems int `json:"max_items,omitempty"`
+	// MinDuration and MaxDuration bound the video length in seconds.
+	MinDuration int `json:"min_duration,omitempty"`
+	MaxDuration int `json:"max_duration,omitempty"`
+	// TitleInclude and TitleExclude are regular expressions the title must
+	// (not) match.
+	TitleInclude string `json:"title_include,omitempty"`
+	TitleExclude string `json:"title_exclude,omitempty"`
+}
+
+// Validate checks the options and returns a message suitable for API clients.
+func (o *ChannelDownloadOptions) Validate() error {
+	seen := make(map[ChannelTab]bool, len(o.Tabs))
+	for _, tab := range o.Tabs {
+		if !tab.IsValid() {
+			return fmt.Errorf("invalid tab %q; must be videos, shorts, streams or playlists", tab)
+		}
+		if seen[tab] {
+			return fmt.Errorf("tab %q listed twice", tab)
+		}
+		seen[tab] = true
+	}
+
+	var after, before time.Time
+	var err error
+	if o.DateAfter != "" {
+		if after, err = time.Parse("20060102", o.DateAfter); err != nil {
+			return fmt.Errorf("date_after must be YYYYMMDD")
+		}
+	}
+	if o.DateBefore != "" {
+		if before, err = time.Parse("20060102", o.DateBefore); err != nil {
+			return fmt.Errorf("date_before must be YYYYMMDD")
+		}
+	}
+	if !after.IsZero() && !before.IsZero() && after.After(before) {
+		return fmt.Errorf("date_after must not be later than date_before")
+	}
+
+	if o.MaxItems < 0 {
+		return fmt.Errorf("max_items must not be negative")
+	}
+	if o.MinDuration < 0 || o.MaxDuration < 0 {
+		return fmt.Errorf("durations must not be negative")
+	}
+	if o.MaxDuration > 0 && o.MinDuration > o.MaxDuration {
+		return fmt.Errorf("min_duration must not exceed max_duration")
+	}
+
+	for name, pattern := range map[string]string{"title_include": o.TitleInclude, "title_exclude": o.TitleExclude} {
+		if pattern == "" {
+			continue
+		}
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("%s is not a valid regular expression: %v", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package domain
+
+import "testing"
+
+func TestChannelDownloadOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		options ChannelDownloadOptions
+		wantErr bool
+	}{
+		{"empty", ChannelDownloadOptions{}, false},
+		{"full", ChannelDownloadOptions{
+			Tabs:         []ChannelTab{ChannelTabVideos, ChannelTabStreams},
+			DateAfter:    "20240101",
+			DateBefore:   "20241231",
+			MaxItems:     50,
+			MinDuration:  60,
+			MaxDuration:  3600,
+			TitleInclude: "(?i)review",
+			TitleExclude: "#shorts",
+		}, false},
+		{"unknown tab", ChannelDownloadOptions{Tabs: []ChannelTab{"community"}}, true},
+		{"duplicate tab", ChannelDownloadOptions{Tabs: []ChannelTab{ChannelTabShorts, ChannelTabShorts}}, true},
+		{"bad date", ChannelDownloadOptions{DateAfter: "2024-01-01"}, true},
+		{"inverted dates", ChannelDownloadOptions{DateAfter: "20240201", DateBefore: "20240101"}, true},
+		{"negative max items", ChannelDownloadOptions{MaxItems: -1}, true},
+		{"inverted durations", ChannelDownloadOptions{MinDuration: 600, MaxDuration: 60}, true},
+		{"min duration only", ChannelDownloadOptions{MinDuration: 600}, false},
+		{"bad regex", ChannelDownloadOptions{TitleInclude: "("}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.options.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package domain
+
+import "time"
+
+// ChannelAssetKind names a piece of channel art archived alongside a channel
+// download and served from /job/{id}/assets/{kind}.
+const (
+	ChannelAssetAvatar = "avatar"
+	ChannelAssetBanner = "banner"
+)
+
+// ChannelSnapshot records a channel's about-page state at one point in time.
+// Snapshots are keyed by the source channel ID rather than the job, so the
+// history spans every job that archived or re-synced the same channel. A new
+// snapshot is only recorded when the description or follower count changed.
+type ChannelSnapshot struct {
+	ID               int64     `json:"id"`
+	ChannelID        string    `json:"channel_id"`
+	JobID            string    `json:"job_id"`
+	Description      string    `json:"description"`
+	ChannelFollowers int       `json:"channel_follower_count"`
+	CapturedAt       time.Time `json:"captured_at"`
+}
+
+// ChannelArtURLs picks the avatar and banner URLs from a channel's thumbnail
+// list. yt-dlp labels the full-size originals "avatar_uncropped" and
+// "banner_uncropped"; extractors without those labels fall back to the largest
+// square image (avatar) and the largest wide image (banner). Kinds without a
+// match are omitted.
+func ChannelArtURLs(thumbnails []Thumbnail) map[string]string {
+	urls := make(map[string]string)
+	var avatar, banner *Thumbnail
+	for i := range thumbnails {
+		t := &thumbnails[i]
+		if t.URL == "" {
+			continue
+		}
+		switch {
+		case t.ID == "avatar_uncropped":
+			urls[ChannelAssetAvatar] = t.URL
+		case t.ID == "banner_uncropped":
+			urls[ChannelAssetBanner] = t.URL
+		case t.Width > 0 && t.Width == t.Height:
+			if avatar == nil || t.Width > avatar.Width {
+				avatar = t
+			}
+		case t.Height > 0 && t.Width >= 2*t.Height:
+			if banner == nil || t.Width > banner.Width {
+				banner = t
+			}
+		}
+	}
+	if _, ok := urls[ChannelAssetAvatar]; !ok && avatar != nil {
+		urls[ChannelAssetAvatar] = avatar.URL
+	}
+	if _, ok := urls[ChannelAssetBanner]; !ok && banner != nil {
+		urls[ChannelAssetBanner] = banner.URL
+	}
+	return urls
+}
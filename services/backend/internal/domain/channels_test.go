@@ -0,0 +1,57 @@
+package domain
+
+import "testing"
+
+func TestChannelArtURLs(t *testing.T) {
+	tests := []struct {
+		name       string
+		thumbnails []Thumbnail
+		want       map[string]string
+	}{
+		{
+			name: "labelled originals win",
+			thumbnails: []Thumbnail{
+				{URL: "https://x/avatar-900", Width: 900, Height: 900},
+				{URL: "https://x/banner-2560", Width: 2560, Height: 424},
+				{URL: "https://x/avatar-orig", ID: "avatar_uncropped"},
+				{URL: "https://x/banner-orig", ID: "banner_uncropped"},
+			},
+			want: map[string]string{
+				ChannelAssetAvatar: "https://x/avatar-orig",
+				ChannelAssetBanner: "https://x/banner-orig",
+			},
+		},
+		{
+			name: "largest square and wide images",
+			thumbnails: []Thumbnail{
+				{URL: "https://x/avatar-88", Width: 88, Height: 88},
+				{URL: "https://x/avatar-900", Width: 900, Height: 900},
+				{URL: "https://x/banner-1060", Width: 1060, Height: 175},
+				{URL: "https://x/banner-2560", Width: 2560, Height: 424},
+				{URL: "https://x/other", Width: 640, Height: 480},
+			},
+			want: map[string]string{
+				ChannelAssetAvatar: "https://x/avatar-900",
+				ChannelAssetBanner: "https://x/banner-2560",
+			},
+		},
+		{
+			name:       "no usable art",
+			thumbnails: []Thumbnail{{URL: "https://x/other", Width: 640, Height: 480}, {ID: "avatar_uncropped"}},
+			want:       map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ChannelArtURLs(tt.thumbnails)
+			if len(got) != len(tt.want) {
+				t.Fatalf("ChannelArtURLs() = %v, want %v", got, tt.want)
+			}
+			for kind, url := range tt.want {
+				if got[kind] != url {
+					t.Errorf("ChannelArtURLs()[%q] = %q, want %q", kind, got[kind], url)
+				}
+			}
+		})
+	}
+}
@@ -1,24 +1,66 @@
 package domain
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 // Collection is a user-defined set of downloaded videos — a custom playlist.
 // Unlike playlists and channels, which mirror content on the source platform,
 // collections are curated locally and can mix videos from any source. The
 // tools section treats a collection like a playlist: one collection ID
 // expands into its member videos.
+//
+// A smart collection has a Query instead of hand-picked members: its videos
+// are whatever the library query matches when the collection is read.
 type Collection struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description,omitempty"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Query is the library query (see ParseLibraryQuery) of a smart
+	// collection; empty for manual collections.
+	Query     string    `json:"query,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 	// VideoCount and Thumbnail are derived from the members when listing;
 	// Thumbnail is the first member's thumbnail.
 	VideoCount int    `json:"video_count"`
 	Thumbnail  string `json:"thumbnail,omitempty"`
+	// Completion is how many of the videos have been watched.
+	Completion *WatchCompletion `json:"completion,omitempty"`
+	// Annotation holds the user's favorite flag, rating and notes.
+	Annotation *Annotation `json:"annotation,omitempty"`
 }
 
+func (c *Collection) IsSmart() bool {
+	return c.Query != ""
+}
+
+// ParseCollectionQuery parses a smart collection's query, which must hold at
+// least one filter and only filters that apply to videos.
+func ParseCollectionQuery(query string) ([]QueryFilter, error) {
+	filters, err := ParseLibraryQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(filters) == 0 {
+		return nil, fmt.Errorf("query has no filters")
+	}
+	return filters, CheckQueryFilters(filters, "videos")
+}
+
+var (
+	// ErrSmartCollection is returned when adding, removing or reordering
+	// videos by hand in a smart collection.
+	ErrSmartCollection = errors.New("smart collections can't be edited by hand")
+	// ErrCollectionOrder is returned when a new order doesn't list exactly the
+	// collection's videos.
+	ErrCollectionOrder = errors.New("the order must list every video in the collection exactly once")
+	// ErrNotInCollection is returned when moving a video that isn't a member.
+	ErrNotInCollection = errors.New("video is not in the collection")
+)
+
 //tygo:ignore
 type CollectionRepository interface {
 	Create(collection *Collection) error
@@ -27,11 +69,24 @@ type CollectionRepository interface {
 	GetByID(id string) (*Collection, error)
 	List() ([]*Collection, error)
 	// AddVideos appends the given video jobs to the collection, skipping IDs
-	// that are already members or do not reference a downloaded video.
+	// that are already members or do not reference a downloaded video. It,
+	// RemoveVideo, SetOrder and MoveVideo return ErrSmartCollection for smart
+	// collections.
 	AddVideos(collectionID string, videoJobIDs []string) error
 	RemoveVideo(collectionID string, videoJobID string) error
-	// GetVideos returns the member videos in collection order.
+	// SetOrder rearranges the collection to the given order, which must hold
+	// every member exactly once.
+	SetOrder(collectionID string, videoJobIDs []string) error
+	// MoveVideo moves a member to a 1-based position, clamped to the
+	// collection's bounds.
+	MoveVideo(collectionID string, videoJobID string, position int) error
+	// GetVideos returns the member videos in collection order; a smart
+	// collection's query is evaluated now, oldest upload first.
 	GetVideos(collectionID string) ([]*JobWithMetadata, error)
 	// ListForVideo returns the IDs of the collections containing the video.
 	ListForVideo(videoJobID string) ([]string, error)
+	// SetAnnotation and GetAnnotation work like their JobRepository
+	// counterparts.
+	SetAnnotation(collectionID string, annotation *Annotation) error
+	GetAnnotation(collectionID string) (*Annotation, error)
 }
@@ -0,0 +1,36 @@
+package domain
+
+// Comment is an archived comment on a downloaded video, parsed from the
+// "comments" list yt-dlp writes into .info.json with --write-comments.
+// Top-level comments have an empty ParentID; when returned from the comments
+// API they carry their replies (oldest first).
+type Comment struct {
+	ID               string    `json:"id"`
+	ParentID         string    `json:"parent_id,omitempty"`
+	Author           string    `json:"author"`
+	AuthorID         string    `json:"author_id,omitempty"`
+	AuthorIsUploader bool      `json:"author_is_uploader,omitempty"`
+	Text             string    `json:"text"`
+	LikeCount        int       `json:"like_count"`
+	Timestamp        int64     `json:"timestamp"` // Unix seconds; 0 when the source gave none
+	IsPinned         bool      `json:"is_pinned,omitempty"`
+	IsHearted        bool      `json:"is_hearted,omitempty"` // liked by the uploader
+	ReplyCount       int       `json:"reply_count,omitempty"`
+	Replies          []Comment `json:"replies,omitempty"`
+}
+
+// Comment sort orders accepted by CommentQuery.
+const (
+	CommentSortTop    = "top"
+	CommentSortNewest = "newest"
+	CommentSortOldest = "oldest"
+)
+
+// CommentQuery holds the listing options for GetComments. Paging applies to
+// top-level threads; a thread matches Search when its root or any reply does.
+type CommentQuery struct {
+	Page   int
+	Limit  int
+	Sort   string
+	Search string
+}
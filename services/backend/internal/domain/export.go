@@ -0,0 +1,59 @@
+package domain
+
+import "time"
+
+// ExportFormat is a playlist file format collections, playlists and
+// channels can be exported as, for players such as VLC and mpv.
+type ExportFormat string
+
+const (
+	ExportFormatM3U8 ExportFormat = "m3u8"
+	ExportFormatXSPF ExportFormat = "xspf"
+	// ExportFormatJSON is a manifest of PlaylistExport.
+	ExportFormatJSON ExportFormat = "json"
+)
+
+func (f ExportFormat) IsValid() bool {
+	switch f {
+	case ExportFormatM3U8, ExportFormatXSPF, ExportFormatJSON:
+		return true
+	}
+	return false
+}
+
+// PlaylistExport lists the downloaded videos of a collection, playlist or
+// channel in order, with links that play them from the archive.
+type PlaylistExport struct {
+	// Kind is "collection", "playlist" or "channel".
+	Kind       string               `json:"kind"`
+	ID         string               `json:"id"`
+	Title      string               `json:"title"`
+	ExportedAt time.Time            `json:"exported_at"`
+	Items      []PlaylistExportItem `json:"items"`
+}
+
+type PlaylistExportItem struct {
+	JobID   string `json:"job_id"`
+	Title   string `json:"title"`
+	Channel string `json:"channel,omitempty"`
+	// Duration is in seconds; zero when unknown.
+	Duration int `json:"duration,omitempty"`
+	// URL streams the video from /video/{jobID}, signed when requested. In
+	// a zip archive's manifest, URL and Thumbnail are paths inside the
+	// archive.
+	URL       string `json:"url"`
+	Thumbnail string `json:"thumbnail,omitempty"`
+	// SourceURL is where the video was downloaded from.
+	SourceURL string `json:"source_url"`
+	// Favorite, Rating and Notes carry the video's annotation.
+	Favorite bool   `json:"favorite,omitempty"`
+	Rating   int    `json:"rating,omitempty"`
+	Notes    string `json:"notes,omitempty"`
+}
+
+// Annotate copies a video's annotation, if any, into the item.
+func (i *PlaylistExportItem) Annotate(annotation *Annotation) {
+	if annotation != nil {
+		i.Favorite, i.Rating, i.Notes = annotation.Favorite, annotation.Rating, annotation.Notes
+	}
+}
@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+// MetadataSnapshot is one point in a video's engagement history: the
+// counters and editable text as seen by a download or a metadata refresh.
+// TitleChanged/DescriptionChanged are derived when history is read and mark
+// snapshots whose text differs from the one before.
+type MetadataSnapshot struct {
+	ID                 int64     `json:"id"`
+	JobID              string    `json:"job_id"`
+	Title              string    `json:"title"`
+	Description        string    `json:"description"`
+	ViewCount          int       `json:"view_count"`
+	LikeCount          int       `json:"like_count"`
+	CommentCount       int       `json:"comment_count"`
+	TitleChanged       bool      `json:"title_changed,omitempty"`
+	DescriptionChanged bool      `json:"description_changed,omitempty"`
+	CapturedAt         time.Time `json:"captured_at"`
+}
+
+// RefreshSchedule re-fetches the metadata of a job every IntervalHours. For
+// playlist and channel jobs the refresh covers their member videos; Cursor is
+// the last member an interrupted run got through, where the next one resumes.
+type RefreshSchedule struct {
+	JobID           string     `json:"job_id"`
+	IntervalHours   int        `json:"interval_hours"`
+	LastRefreshedAt *time.Time `json:"last_refreshed_at,omitempty"`
+	NextRefreshAt   time.Time  `json:"next_refresh_at"`
+	Cursor          string     `json:"cursor,omitempty"`
+}
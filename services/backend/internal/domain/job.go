@@ -28,13 +28,23 @@ type Job struct {
 	Progress      float64   `json:"progress"`
 	MediaType     MediaType `json:"media_type,omitempty"`
 	CustomQuality *int      `json:"custom_quality,omitempty"`
-	Warnings      []string  `json:"warnings,omitempty"`
+	// ArchiveComments overrides the archive_comments setting for this job;
+	// nil uses the setting. Stored with the job, so re-syncs keep it.
+	ArchiveComments *bool `json:"archive_comments,omitempty"`
+	// Live records the source as a livestream: wait for scheduled streams,
+	// capture from the start and keep the recording when stopped. Also
+	// enabled automatically when the source reports a live or upcoming stream.
+	Live bool `json:"live,omitempty"`
+	// ChannelOptions narrows a channel download. Only set on submission;
+	// the stored copy is read back with GetChannelOptions.
+	ChannelOptions *ChannelDownloadOptions `json:"channel_options,omitempty"`
+	Warnings       []string                `json:"warnings,omitempty"`
 	// FilePath is the absolute on-disk path of the downloaded media file,
 	// captured from yt-dlp when the download finishes. Empty for playlist and
 	// channel parent jobs and for downloads made before this field existed.
-	FilePath string `json:"file_path,omitempty"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	FilePath  string    `json:"file_path,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 // IsAudio reports whether the job downloads audio only. The zero value of
@@ -67,7 +77,94 @@ type JobRepository interface {
 	GetTagsForJob(jobID string) ([]Tag, error)
 	AddTagsToJob(jobID string, names []string, source string) ([]Tag, error)
 	RemoveTagFromJob(jobID string, tagID int64) error
+	// GetTag and GetTagByName return nil when the tag doesn't exist.
+	GetTag(id int64) (*Tag, error)
+	GetTagByName(name string) (*Tag, error)
+	// UpdateTag saves a tag's name, color and parent.
+	UpdateTag(tag *Tag) error
+	// MergeTags moves every assignment of sourceIDs onto targetID, keeping
+	// each assignment's source, and deletes the merged tags.
+	MergeTags(targetID int64, sourceIDs []int64) error
+	// DeleteTag detaches a tag from every item and deletes it; its children
+	// become top-level tags.
+	DeleteTag(id int64) error
 	BackfillAutoTags() error
+	// Tag rules, synonyms and the blocklist shape derived tags. GetTagRule
+	// returns nil when the rule doesn't exist.
+	ListTagRules() ([]TagRule, error)
+	GetTagRule(id int64) (*TagRule, error)
+	CreateTagRule(rule *TagRule) error
+	UpdateTagRule(rule *TagRule) error
+	DeleteTagRule(id int64) error
+	GetTagVocabulary() (*TagVocabulary, error)
+	SetTagSynonym(alias, canonical string) error
+	DeleteTagSynonym(alias string) error
+	BlockTag(name string) error
+	UnblockTag(name string) error
+	// ReapplyTagRules re-derives the auto and rule tags of every item and
+	// adds matching videos to rule collections.
+	ReapplyTagRules() (*TagRuleRun, error)
+	// GetChannelSnapshots returns the recorded about-page history of a source
+	// channel (by channel ID, not job ID), oldest first.
+	GetChannelSnapshots(channelID string) ([]ChannelSnapshot, error)
+	// HasChannelJobs reports whether any job of a source channel remains:
+	// the channel itself, or one of its videos or playlists.
+	HasChannelJobs(channelID string) (bool, error)
+	// StoreComments replaces the archived comments of a video job.
+	StoreComments(jobID string, comments []Comment) error
+	GetComments(jobID string, opts CommentQuery) ([]Comment, int, error)
+	// RecordMetadataSnapshot appends a point to a video's engagement history.
+	RecordMetadataSnapshot(jobID string, metadata *VideoMetadata) error
+	GetMetadataSnapshots(jobID string) ([]MetadataSnapshot, error)
+	// SetRefreshSchedule schedules periodic metadata refreshes of a job; an
+	// interval of 0 removes the schedule.
+	SetRefreshSchedule(jobID string, intervalHours int) error
+	GetRefreshSchedule(jobID string) (*RefreshSchedule, error)
+	ListRefreshSchedules() ([]RefreshSchedule, error)
+	MarkRefreshed(jobID string, at time.Time) error
+	// SaveRefreshCursor records how far an interrupted refresh got and when
+	// to resume it, leaving the last completed refresh as it was.
+	SaveRefreshCursor(jobID, cursor string, retryAt time.Time) error
+	// RecordAvailability stores a check result and returns the status it
+	// replaced (nil on the first check).
+	RecordAvailability(jobID string, status Availability, reason string, at time.Time) (*AvailabilityStatus, error)
+	GetAvailability(jobID string) (*AvailabilityStatus, error)
+	// ListAvailabilityCandidates returns up to limit downloaded videos not
+	// checked since checkedBefore, never-checked and oldest-checked first.
+	ListAvailabilityCandidates(checkedBefore time.Time, limit int) ([]*Job, error)
+	// RecordPlaylistSnapshot stores the item list seen by a playlist or
+	// channel sync, diffed against the source's previous snapshot.
+	RecordPlaylistSnapshot(jobID, sourceID string, items []PlaylistEntry) (*PlaylistSnapshot, error)
+	// GetPlaylistSnapshots lists a source's snapshots (without items), oldest
+	// first; GetPlaylistSnapshot returns one snapshot with its items.
+	GetPlaylistSnapshots(sourceID string) ([]PlaylistSnapshot, error)
+	GetPlaylistSnapshot(id int64) (*PlaylistSnapshot, error)
+	// SetChannelOptions stores the download options of a channel job; nil
+	// removes them. GetChannelOptions returns nil when none are stored.
+	SetChannelOptions(jobID string, options *ChannelDownloadOptions) error
+	GetChannelOptions(jobID string) (*ChannelDownloadOptions, error)
+	// CreateUpgradeRun and UpdateUpgradeRun persist a quality upgrade run;
+	// AddUpgradeItem records its result for one video.
+	CreateUpgradeRun(run *UpgradeRun) error
+	UpdateUpgradeRun(run *UpgradeRun) error
+	AddUpgradeItem(item *UpgradeItem) error
+	// GetUpgradeRun returns a run with its items; ListUpgradeRuns returns the
+	// most recent runs without items, newest first.
+	GetUpgradeRun(id int64) (*UpgradeRun, error)
+	ListUpgradeRuns(limit int) ([]UpgradeRun, error)
+	// SetWatchProgress stores where playback of a video stopped;
+	// GetWatchProgress returns nil for videos never played and
+	// DeleteWatchProgress marks a video unwatched again.
+	SetWatchProgress(progress *WatchProgress) error
+	GetWatchProgress(jobID string) (*WatchProgress, error)
+	DeleteWatchProgress(jobID string) error
+	// GetContinueWatching returns up to limit videos that were started but
+	// not finished, most recently watched first.
+	GetContinueWatching(limit int) ([]*JobWithMetadata, error)
+	// SetAnnotation stores a job's favorite flag, rating and notes, removing
+	// an empty annotation; GetAnnotation returns nil when there is none.
+	SetAnnotation(jobID string, annotation *Annotation) error
+	GetAnnotation(jobID string) (*Annotation, error)
 }
 
 // MetadataQuery holds the listing options for GetMetadataByType.
@@ -78,6 +175,12 @@ type MetadataQuery struct {
 	Order  string
 	Search string // case-insensitive match against title/channel
 	Tag    string // only items carrying this tag
+	// Availability narrows videos to those whose last availability check
+	// reported this status.
+	Availability Availability
+	// Filters are parsed from the library query language; see
+	// ParseLibraryQuery.
+	Filters []QueryFilter
 }
 
 type JobType string
@@ -93,6 +196,14 @@ type JobWithMetadata struct {
 	Job      *Job     `json:"job"`
 	Metadata Metadata `json:"metadata,omitempty"`
 	Tags     []Tag    `json:"tags,omitempty"`
+	// Availability is the latest upstream check of a video, when it has one.
+	Availability *AvailabilityStatus `json:"availability,omitempty"`
+	// Watch is where playback of a video stopped, when it has been played.
+	Watch *WatchProgress `json:"watch,omitempty"`
+	// Completion is how much of a playlist or channel has been watched.
+	Completion *WatchCompletion `json:"completion,omitempty"`
+	// Annotation holds the user's favorite flag, rating and notes.
+	Annotation *Annotation `json:"annotation,omitempty"`
 }
 
 type ProgressUpdate struct {
@@ -109,6 +220,11 @@ type ProgressUpdate struct {
 	MaxRetries           int       `json:"maxRetries,omitempty"`
 	RetryError           string    `json:"retryError,omitempty"`
 	Warnings             []string  `json:"warnings,omitempty"`
+	// Live recordings have no meaningful percentage; they report how long
+	// and how much has been recorded instead.
+	IsLive         bool  `json:"isLive,omitempty"`
+	ElapsedSeconds int   `json:"elapsedSeconds,omitempty"`
+	RecordedBytes  int64 `json:"recordedBytes,omitempty"`
 }
 
 const (
@@ -117,6 +233,10 @@ const (
 	DownloadPhaseAudio    = "audio"
 	DownloadPhaseMerging  = "merging"
 	DownloadPhaseComplete = "complete"
+	// DownloadPhaseWaiting: a scheduled stream has not started yet.
+	DownloadPhaseWaiting = "waiting"
+	// DownloadPhaseRecording: a live stream is being captured.
+	DownloadPhaseRecording = "recording"
 )
 
 type VideoMetadata struct {
@@ -154,12 +274,19 @@ type VideoMetadata struct {
 	AudioCodec        string   `json:"acodec"`
 	AudioChannels     int      `json:"audio_channels"`
 	WasLive           bool     `json:"was_live"`
+	LiveStatus        string   `json:"live_status,omitempty"`
 	WebpageURLDomain  string   `json:"webpage_url_domain"`
 	Extractor         string   `json:"extractor"`
 	FullTitle         string   `json:"fulltitle"`
 	Type              string   `json:"_type"`
 }
 
+// IsLiveOrUpcoming reports whether the source is a stream that is currently
+// live or scheduled (a premiere or an upcoming livestream).
+func (v *VideoMetadata) IsLiveOrUpcoming() bool {
+	return v.LiveStatus == "is_live" || v.LiveStatus == "is_upcoming"
+}
+
 type Thumbnail struct {
 	URL    string `json:"url"`
 	Height int    `json:"height"`
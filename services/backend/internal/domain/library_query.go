@@ -0,0 +1,422 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// QueryField names what a library query filter matches against. Only these
+// fields are accepted; the repository maps each to a fixed SQL expression.
+type QueryField string
+
+const (
+	// QueryFieldText matches bare words and quoted phrases against the
+	// full-text search index: title, description, channel and tags.
+	QueryFieldText       QueryField = "text"
+	QueryFieldTitle      QueryField = "title"
+	QueryFieldChannel    QueryField = "channel"
+	QueryFieldTag        QueryField = "tag"
+	QueryFieldStatus     QueryField = "status"
+	QueryFieldDuration   QueryField = "duration"
+	QueryFieldUploaded   QueryField = "uploaded"
+	QueryFieldResolution QueryField = "res"
+	QueryFieldCodec      QueryField = "codec"
+	QueryFieldSize       QueryField = "size"
+	QueryFieldViews      QueryField = "views"
+	QueryFieldWatch      QueryField = "watch"
+	QueryFieldFavorite   QueryField = "favorite"
+	QueryFieldRating     QueryField = "rating"
+	QueryFieldNotes      QueryField = "notes"
+)
+
+// QueryOp compares a field with a filter's value. ":" means "matches": a
+// substring for text fields, equality for numbers and the whole period for
+// dates.
+type QueryOp string
+
+const (
+	QueryOpMatch        QueryOp = ":"
+	QueryOpGreater      QueryOp = ">"
+	QueryOpGreaterEqual QueryOp = ">="
+	QueryOpLess         QueryOp = "<"
+	QueryOpLessEqual    QueryOp = "<="
+)
+
+// QueryFilter is one condition of a library query.
+type QueryFilter struct {
+	Field  QueryField
+	Op     QueryOp
+	Negate bool
+	// Text is the value of text, title, channel, tag, status, codec, watch
+	// and notes filters.
+	Text string
+	// Number is the value of numeric filters: seconds for duration, pixels
+	// of height for res, bytes for size, stars for rating and 1 or 0 for
+	// favorite.
+	Number int64
+	// From and To bound an uploaded filter's period as inclusive YYYYMMDD
+	// dates.
+	From string
+	To   string
+}
+
+// AppliesTo reports whether the filter can narrow a listing of contentType
+// ("videos", "playlists" or "channels"). Media filters only apply to videos.
+func (f QueryFilter) AppliesTo(contentType string) bool {
+	switch f.Field {
+	case QueryFieldText, QueryFieldTitle, QueryFieldChannel, QueryFieldTag, QueryFieldStatus,
+		QueryFieldFavorite, QueryFieldRating, QueryFieldNotes:
+		return true
+	}
+	return contentType == "videos"
+}
+
+// CheckQueryFilters returns an error naming the first filter that doesn't
+// apply to contentType.
+func CheckQueryFilters(filters []QueryFilter, contentType string) error {
+	for _, f := range filters {
+		if !f.AppliesTo(contentType) {
+			return fmt.Errorf("the %s filter only applies to videos", f.Field)
+		}
+	}
+	return nil
+}
+
+var (
+	queryKeyPattern   = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+	queryDatePattern  = regexp.MustCompile(`^(\d{4})(?:-?(\d{2}))?(?:-?(\d{2}))?$`)
+	queryAgoPattern   = regexp.MustCompile(`^(\d+)([dwmy])$`)
+	querySizePattern  = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*([kmgt]?i?b?)$`)
+	queryResolutions  = map[string]int64{"8k": 4320, "4k": 2160, "2k": 1440, "uhd": 2160, "fhd": 1080, "hd": 720, "sd": 480}
+	querySizeUnits    = map[string]int64{"": 1, "b": 1, "k": 1 << 10, "m": 1 << 20, "g": 1 << 30, "t": 1 << 40}
+	queryCodecAliases = map[string][]string{
+		"h264": {"avc1", "h264"},
+		"avc":  {"avc1", "h264"},
+		"h265": {"hvc1", "hev1", "h265"},
+		"hevc": {"hvc1", "hev1", "h265"},
+		"vp9":  {"vp09", "vp9"},
+		"av1":  {"av01"},
+		"aac":  {"mp4a"},
+	}
+)
+
+// CodecPrefixes returns the codec string prefixes yt-dlp reports for a codec
+// name, e.g. "vp09" and "vp9" for vp9. Unknown names match themselves.
+func CodecPrefixes(name string) []string {
+	name = strings.ToLower(name)
+	if prefixes, ok := queryCodecAliases[name]; ok {
+		return prefixes
+	}
+	return []string{name}
+}
+
+// ParseLibraryQuery parses the compact library query syntax, for example
+//
+//	channel:"Some Channel" duration>20m uploaded:2023 res>=1080 tag:a -tag:b codec:vp9 size>1GB status:error watch:unwatched
+//	favorite:yes rating>=4 notes:"to rewatch"
+//
+// uploaded also takes periods relative to the current date, such as
+// uploaded:week or uploaded:30d, which are resolved when the query is parsed.
+//
+// Terms are separated by spaces and all must match; a leading "-" negates a
+// term. Words and quoted phrases without a field are full-text matches;
+// quote text containing a colon. Unknown fields and malformed
+// values are rejected with a message suitable for API clients.
+func ParseLibraryQuery(query string) ([]QueryFilter, error) {
+	tokens, err := tokenizeLibraryQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var filters []QueryFilter
+	for _, token := range tokens {
+		negate := false
+		if len(token) > 1 && token[0] == '-' {
+			negate, token = true, token[1:]
+		}
+
+		key, op, value, ok := splitQueryTerm(token)
+		if !ok {
+			text := unquote(token)
+			if text == "" {
+				continue
+			}
+			filters = append(filters, QueryFilter{Field: QueryFieldText, Op: QueryOpMatch, Negate: negate, Text: text})
+			continue
+		}
+
+		filter, err := parseQueryFilter(QueryField(key), op, unquote(value))
+		if err != nil {
+			return nil, err
+		}
+		filter.Negate = negate
+		filters = append(filters, filter)
+	}
+	return filters, nil
+}
+
+// tokenizeLibraryQuery splits on whitespace outside double quotes.
+func tokenizeLibraryQuery(query string) ([]string, error) {
+	var tokens []string
+	var current strings.Builder
+	inQuotes := false
+	for _, r := range query {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			current.WriteRune(r)
+		case unicode.IsSpace(r) && !inQuotes:
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("unterminated quote in query")
+	}
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+	return tokens, nil
+}
+
+// splitQueryTerm splits "key<op>value". Anything shaped like an identifier
+// is a key, so unknown and unsafe fields are rejected rather than searched
+// for; terms such as "3:10" or quoted phrases are free text.
+func splitQueryTerm(token string) (key string, op QueryOp, value string, ok bool) {
+	i := strings.IndexAny(token, ":<>")
+	if i <= 0 || !queryKeyPattern.MatchString(token[:i]) {
+		return "", "", "", false
+	}
+	key, rest := token[:i], token[i:]
+	switch {
+	case strings.HasPrefix(rest, ">="):
+		op = QueryOpGreaterEqual
+	case strings.HasPrefix(rest, "<="):
+		op = QueryOpLessEqual
+	case rest[0] == '>':
+		op = QueryOpGreater
+	case rest[0] == '<':
+		op = QueryOpLess
+	default:
+		op = QueryOpMatch
+	}
+	return key, op, rest[len(op):], true
+}
+
+func unquote(s string) string {
+	return strings.TrimSpace(strings.ReplaceAll(s, `"`, ""))
+}
+
+func parseQueryFilter(field QueryField, op QueryOp, value string) (QueryFilter, error) {
+	filter := QueryFilter{Field: field, Op: op}
+	if value == "" {
+		return filter, fmt.Errorf("%s needs a value", field)
+	}
+
+	switch field {
+	case QueryFieldTitle, QueryFieldChannel, QueryFieldTag, QueryFieldCodec, QueryFieldNotes:
+		if op != QueryOpMatch {
+			return filter, fmt.Errorf("%s only supports %s:value", field, field)
+		}
+		filter.Text = value
+	case QueryFieldStatus:
+		if op != QueryOpMatch {
+			return filter, fmt.Errorf("status only supports status:value")
+		}
+		switch JobStatus(strings.ToLower(value)) {
+		case JobStatusPending, JobStatusInProgress, JobStatusComplete, JobStatusError, JobStatusCancelled:
+		default:
+			return filter, fmt.Errorf("invalid status %q", value)
+		}
+		filter.Text = strings.ToLower(value)
+	case QueryFieldWatch:
+		if op != QueryOpMatch {
+			return filter, fmt.Errorf("watch only supports watch:value")
+		}
+		state := WatchState(strings.ToLower(value))
+		if !state.IsValid() {
+			return filter, fmt.Errorf("invalid watch state %q, use watched, unwatched or in-progress", value)
+		}
+		filter.Text = string(state)
+	case QueryFieldFavorite:
+		if op != QueryOpMatch {
+			return filter, fmt.Errorf("favorite only supports favorite:yes or favorite:no")
+		}
+		switch strings.ToLower(value) {
+		case "yes", "true", "1":
+			filter.Number = 1
+		case "no", "false", "0":
+			filter.Number = 0
+		default:
+			return filter, fmt.Errorf("invalid favorite %q, use yes or no", value)
+		}
+	case QueryFieldRating:
+		stars, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || stars < 0 || stars > 5 {
+			return filter, fmt.Errorf("invalid rating %q, use 0 to 5", value)
+		}
+		filter.Number = stars
+	case QueryFieldDuration:
+		seconds, err := parseQueryDuration(value)
+		if err != nil {
+			return filter, err
+		}
+		filter.Number = seconds
+	case QueryFieldResolution:
+		height, err := parseQueryResolution(value)
+		if err != nil {
+			return filter, err
+		}
+		filter.Number = height
+	case QueryFieldSize:
+		size, err := parseQuerySize(value)
+		if err != nil {
+			return filter, err
+		}
+		filter.Number = size
+	case QueryFieldViews:
+		views, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || views < 0 {
+			return filter, fmt.Errorf("invalid view count %q", value)
+		}
+		filter.Number = views
+	case QueryFieldUploaded:
+		from, to, err := parseQueryDate(value)
+		if err != nil {
+			return filter, err
+		}
+		filter.From, filter.To = from, to
+	default:
+		return filter, fmt.Errorf("unknown filter %q", field)
+	}
+	return filter, nil
+}
+
+// parseQueryDuration accepts Go durations ("20m", "1h30m") and plain
+// seconds.
+func parseQueryDuration(value string) (int64, error) {
+	if _, err := strconv.Atoi(value); err == nil {
+		value += "s"
+	}
+	d, err := time.ParseDuration(strings.ToLower(value))
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration %q, use e.g. 90s, 20m or 1h30m", value)
+	}
+	return int64(d / time.Second), nil
+}
+
+// parseQueryResolution accepts a height ("1080", "1080p") or a name ("4k").
+func parseQueryResolution(value string) (int64, error) {
+	value = strings.ToLower(value)
+	if height, ok := queryResolutions[value]; ok {
+		return height, nil
+	}
+	height, err := strconv.ParseInt(strings.TrimSuffix(value, "p"), 10, 64)
+	if err != nil || height <= 0 {
+		return 0, fmt.Errorf("invalid resolution %q, use e.g. 720, 1080p or 4k", value)
+	}
+	return height, nil
+}
+
+// parseQuerySize accepts a byte count with an optional binary unit: "500MB",
+// "1.5GB", "2G".
+func parseQuerySize(value string) (int64, error) {
+	m := querySizePattern.FindStringSubmatch(strings.ToLower(value))
+	if m == nil {
+		return 0, fmt.Errorf("invalid size %q, use e.g. 500MB or 1GB", value)
+	}
+	n, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+	unit := strings.TrimSuffix(strings.TrimSuffix(m[2], "b"), "i")
+	return int64(n * float64(querySizeUnits[unit])), nil
+}
+
+// queryNow is the clock relative upload dates are resolved against.
+var queryNow = time.Now
+
+// parseQueryDate turns a year, month or day ("2023", "2023-05",
+// "2023-05-01") into the inclusive YYYYMMDD bounds of that period. It also
+// accepts periods ending today: "today", "yesterday", the current "week"
+// (from Monday), "month" or "year", and the last few days, weeks, months or
+// years ("7d", "2w", "6m", "1y").
+func parseQueryDate(value string) (from, to string, err error) {
+	if start, end, ok := parseRelativeQueryDate(strings.ToLower(value)); ok {
+		return start.Format("20060102"), end.Format("20060102"), nil
+	}
+	m := queryDatePattern.FindStringSubmatch(value)
+	if m == nil {
+		return "", "", fmt.Errorf("invalid date %q, use YYYY, YYYY-MM, YYYY-MM-DD, today, week or e.g. 30d", value)
+	}
+	layout, text := "2006", m[1]
+	if m[2] != "" {
+		layout, text = layout+"01", text+m[2]
+	}
+	if m[3] != "" {
+		if m[2] == "" {
+			return "", "", fmt.Errorf("invalid date %q, use YYYY, YYYY-MM or YYYY-MM-DD", value)
+		}
+		layout, text = layout+"02", text+m[3]
+	}
+	start, err := time.Parse(layout, text)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid date %q", value)
+	}
+
+	end := start.AddDate(1, 0, -1)
+	switch {
+	case m[3] != "":
+		end = start
+	case m[2] != "":
+		end = start.AddDate(0, 1, -1)
+	}
+	return start.Format("20060102"), end.Format("20060102"), nil
+}
+
+func parseRelativeQueryDate(value string) (start, end time.Time, ok bool) {
+	now := queryNow()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	switch value {
+	case "today":
+		return today, today, true
+	case "yesterday":
+		yesterday := today.AddDate(0, 0, -1)
+		return yesterday, yesterday, true
+	case "week":
+		// Weeks start on Monday.
+		return today.AddDate(0, 0, -(int(today.Weekday())+6)%7), today, true
+	case "month":
+		return today.AddDate(0, 0, 1-today.Day()), today, true
+	case "year":
+		return today.AddDate(0, 0, 1-today.YearDay()), today, true
+	}
+
+	m := queryAgoPattern.FindStringSubmatch(value)
+	if m == nil {
+		return time.Time{}, time.Time{}, false
+	}
+	n, err := strconv.Atoi(m[1])
+	if err != nil || n == 0 {
+		return time.Time{}, time.Time{}, false
+	}
+	switch m[2] {
+	case "d":
+		start = today.AddDate(0, 0, 1-n)
+	case "w":
+		start = today.AddDate(0, 0, 1-7*n)
+	case "m":
+		start = today.AddDate(0, -n, 1)
+	case "y":
+		start = today.AddDate(-n, 0, 1)
+	}
+	return start, today, true
+}
@@ -0,0 +1,143 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseLibraryQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []QueryFilter
+	}{
+		{"empty", "  ", nil},
+		{"bare words", `cats "big dogs"`, []QueryFilter{
+			{Field: QueryFieldText, Op: QueryOpMatch, Text: "cats"},
+			{Field: QueryFieldText, Op: QueryOpMatch, Text: "big dogs"},
+		}},
+		{"quoted channel", `channel:"Linus Tech Tips"`, []QueryFilter{
+			{Field: QueryFieldChannel, Op: QueryOpMatch, Text: "Linus Tech Tips"},
+		}},
+		{"negated tag", "tag:a -tag:b", []QueryFilter{
+			{Field: QueryFieldTag, Op: QueryOpMatch, Text: "a"},
+			{Field: QueryFieldTag, Op: QueryOpMatch, Text: "b", Negate: true},
+		}},
+		{"duration", "duration>20m duration<=90", []QueryFilter{
+			{Field: QueryFieldDuration, Op: QueryOpGreater, Number: 1200},
+			{Field: QueryFieldDuration, Op: QueryOpLessEqual, Number: 90},
+		}},
+		{"resolution", "res>=1080p res<4k", []QueryFilter{
+			{Field: QueryFieldResolution, Op: QueryOpGreaterEqual, Number: 1080},
+			{Field: QueryFieldResolution, Op: QueryOpLess, Number: 2160},
+		}},
+		{"size", "size>1GB size<500mb", []QueryFilter{
+			{Field: QueryFieldSize, Op: QueryOpGreater, Number: 1 << 30},
+			{Field: QueryFieldSize, Op: QueryOpLess, Number: 500 << 20},
+		}},
+		{"uploaded year", "uploaded:2023", []QueryFilter{
+			{Field: QueryFieldUploaded, Op: QueryOpMatch, From: "20230101", To: "20231231"},
+		}},
+		{"uploaded month", "uploaded>=2024-02", []QueryFilter{
+			{Field: QueryFieldUploaded, Op: QueryOpGreaterEqual, From: "20240201", To: "20240229"},
+		}},
+		{"status and codec", "status:ERROR codec:vp9", []QueryFilter{
+			{Field: QueryFieldStatus, Op: QueryOpMatch, Text: "error"},
+			{Field: QueryFieldCodec, Op: QueryOpMatch, Text: "vp9"},
+		}},
+		{"watch state", "watch:In-Progress -watch:watched", []QueryFilter{
+			{Field: QueryFieldWatch, Op: QueryOpMatch, Text: "in-progress"},
+			{Field: QueryFieldWatch, Op: QueryOpMatch, Text: "watched", Negate: true},
+		}},
+		{"annotations", `favorite:yes rating>=4 notes:"to rewatch"`, []QueryFilter{
+			{Field: QueryFieldFavorite, Op: QueryOpMatch, Number: 1},
+			{Field: QueryFieldRating, Op: QueryOpGreaterEqual, Number: 4},
+			{Field: QueryFieldNotes, Op: QueryOpMatch, Text: "to rewatch"},
+		}},
+		{"time is text", "3:10", []QueryFilter{
+			{Field: QueryFieldText, Op: QueryOpMatch, Text: "3:10"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLibraryQuery(tt.query)
+			if err != nil {
+				t.Fatalf("ParseLibraryQuery(%q) error = %v", tt.query, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseLibraryQuery(%q) = %+v, want %+v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLibraryQueryRejects(t *testing.T) {
+	for _, query := range []string{
+		"jobs.status:x",
+		"metadata_json:x",
+		"sql:1",
+		`channel:"unterminated`,
+		"duration>soon",
+		"res>=tall",
+		"size>1PB",
+		"uploaded:2023-13",
+		"uploaded:2023--01",
+		"uploaded:0d",
+		"uploaded:fortnight",
+		"status:done",
+		"tag>a",
+		"title:",
+		"views>-1",
+		"watch:maybe",
+		"favorite:maybe",
+		"rating>6",
+		"watch>watched",
+	} {
+		if _, err := ParseLibraryQuery(query); err == nil {
+			t.Errorf("ParseLibraryQuery(%q) error = nil, want an error", query)
+		}
+	}
+}
+
+func TestParseLibraryQueryRelativeDates(t *testing.T) {
+	defer func(now func() time.Time) { queryNow = now }(queryNow)
+	// A Thursday.
+	queryNow = func() time.Time { return time.Date(2024, 3, 14, 18, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		value    string
+		from, to string
+	}{
+		{"today", "20240314", "20240314"},
+		{"Yesterday", "20240313", "20240313"},
+		{"week", "20240311", "20240314"},
+		{"month", "20240301", "20240314"},
+		{"year", "20240101", "20240314"},
+		{"7d", "20240308", "20240314"},
+		{"2w", "20240301", "20240314"},
+		{"1m", "20240215", "20240314"},
+		{"1y", "20230315", "20240314"},
+	}
+	for _, tt := range tests {
+		filters, err := ParseLibraryQuery("uploaded:" + tt.value)
+		if err != nil {
+			t.Errorf("ParseLibraryQuery(uploaded:%s) error = %v", tt.value, err)
+			continue
+		}
+		if f := filters[0]; f.From != tt.from || f.To != tt.to {
+			t.Errorf("uploaded:%s = %s..%s, want %s..%s", tt.value, f.From, f.To, tt.from, tt.to)
+		}
+	}
+}
+
+func TestCheckQueryFilters(t *testing.T) {
+	filters, _ := ParseLibraryQuery("tag:a status:complete")
+	if err := CheckQueryFilters(filters, "channels"); err != nil {
+		t.Errorf("CheckQueryFilters(channels) error = %v", err)
+	}
+	filters, _ = ParseLibraryQuery("title:x duration>1m")
+	if err := CheckQueryFilters(filters, "playlists"); err == nil {
+		t.Error("CheckQueryFilters(playlists) error = nil, want duration rejected")
+	}
+}
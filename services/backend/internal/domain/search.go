@@ -0,0 +1,39 @@
+package domain
+
+// SearchKind is the type of item a search result points at.
+type SearchKind string
+
+const (
+	SearchKindVideo      SearchKind = "video"
+	SearchKindPlaylist   SearchKind = "playlist"
+	SearchKindChannel    SearchKind = "channel"
+	SearchKindCollection SearchKind = "collection"
+)
+
+func (k SearchKind) IsValid() bool {
+	switch k {
+	case SearchKindVideo, SearchKindPlaylist, SearchKindChannel, SearchKindCollection:
+		return true
+	}
+	return false
+}
+
+// SearchResult is one ranked full-text search hit. ID is a job ID, or a
+// collection ID for collections. Title and Snippet are HTML-escaped, with the
+// matched terms wrapped in <mark> elements.
+type SearchResult struct {
+	Kind    SearchKind `json:"kind"`
+	ID      string     `json:"id"`
+	Title   string     `json:"title"`
+	Snippet string     `json:"snippet"`
+	// Score is the relevance of the hit; higher is better.
+	Score float64 `json:"score"`
+}
+
+//tygo:ignore
+type SearchRepository interface {
+	// Search returns the best matches for query, limited to one kind unless
+	// kind is empty. Words and "quoted phrases" must all match; a trailing *
+	// matches a word as a prefix.
+	Search(query string, kind SearchKind, limit int) ([]SearchResult, error)
+}
@@ -3,16 +3,20 @@ package domain
 import "time"
 
 type Settings struct {
-	ID                    int       `json:"id"`
-	Theme                 string    `json:"theme"`
-	DownloadQuality       int       `json:"download_quality"`
-	ConcurrentDownloads   int       `json:"concurrent_downloads"`
-	ToolsDefaultFormat    string    `json:"tools_default_format"`
-	ToolsDefaultQuality   string    `json:"tools_default_quality"`
-	ToolsPreserveOriginal bool      `json:"tools_preserve_original"`
-	ToolsOutputPath       string    `json:"tools_output_path"`
-	CreatedAt             time.Time `json:"created_at"`
-	UpdatedAt             time.Time `json:"updated_at"`
+	ID                    int    `json:"id"`
+	Theme                 string `json:"theme"`
+	DownloadQuality       int    `json:"download_quality"`
+	ConcurrentDownloads   int    `json:"concurrent_downloads"`
+	ToolsDefaultFormat    string `json:"tools_default_format"`
+	ToolsDefaultQuality   string `json:"tools_default_quality"`
+	ToolsPreserveOriginal bool   `json:"tools_preserve_original"`
+	ToolsOutputPath       string `json:"tools_output_path"`
+	ArchiveComments       bool   `json:"archive_comments"`
+	// AvailabilityCheckHours is how often each archived video's source is
+	// re-checked upstream; 0 disables the availability checker.
+	AvailabilityCheckHours int       `json:"availability_check_hours"`
+	CreatedAt              time.Time `json:"created_at"`
+	UpdatedAt              time.Time `json:"updated_at"`
 }
 
 //tygo:ignore
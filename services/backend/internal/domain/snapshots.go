@@ -0,0 +1,145 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// PlaylistEntry is one item of a playlist or channel listing as it appeared
+// upstream at sync time. Position is the 1-based playlist_index.
+type PlaylistEntry struct {
+	VideoID  string `json:"video_id"`
+	Title    string `json:"title"`
+	Position int    `json:"position"`
+}
+
+// PlaylistSnapshot is the ordered item list of a playlist or channel recorded
+// by one sync. Snapshots are keyed by the source's ID, not the job, so every
+// re-sync of the same playlist extends one history. The counts summarize the
+// diff against the previous snapshot (all zero for the first one). Items is
+// only populated when a single snapshot is requested.
+type PlaylistSnapshot struct {
+	ID             int64           `json:"id"`
+	SourceID       string          `json:"source_id"`
+	JobID          string          `json:"job_id"`
+	ItemCount      int             `json:"item_count"`
+	AddedCount     int             `json:"added_count"`
+	RemovedCount   int             `json:"removed_count"`
+	RenamedCount   int             `json:"renamed_count"`
+	ReorderedCount int             `json:"reordered_count"`
+	CapturedAt     time.Time       `json:"captured_at"`
+	Items          []PlaylistEntry `json:"items,omitempty"`
+}
+
+// PlaylistRename is an item whose title changed between two snapshots.
+type PlaylistRename struct {
+	VideoID  string `json:"video_id"`
+	OldTitle string `json:"old_title"`
+	NewTitle string `json:"new_title"`
+}
+
+// PlaylistMove is an item that changed its order relative to the other
+// items between two snapshots.
+type PlaylistMove struct {
+	VideoID     string `json:"video_id"`
+	Title       string `json:"title"`
+	OldPosition int    `json:"old_position"`
+	NewPosition int    `json:"new_position"`
+}
+
+// PlaylistDiff describes how a playlist changed from one snapshot to another.
+type PlaylistDiff struct {
+	From      int64            `json:"from"`
+	To        int64            `json:"to"`
+	Added     []PlaylistEntry  `json:"added"`
+	Removed   []PlaylistEntry  `json:"removed"`
+	Renamed   []PlaylistRename `json:"renamed"`
+	Reordered []PlaylistMove   `json:"reordered"`
+}
+
+// DiffPlaylistEntries compares two item lists. An item counts as reordered
+// only when its order relative to the other surviving items changed: the
+// longest run of items that kept their relative order stays put, so a single
+// insertion near the top does not mark every later item as moved.
+func DiffPlaylistEntries(from, to []PlaylistEntry) PlaylistDiff {
+	diff := PlaylistDiff{
+		Added:     []PlaylistEntry{},
+		Removed:   []PlaylistEntry{},
+		Renamed:   []PlaylistRename{},
+		Reordered: []PlaylistMove{},
+	}
+
+	before := make(map[string]PlaylistEntry, len(from))
+	for _, e := range from {
+		before[e.VideoID] = e
+	}
+	after := make(map[string]bool, len(to))
+
+	// Items present in both lists, in their new order.
+	var kept []PlaylistEntry
+	for _, e := range to {
+		after[e.VideoID] = true
+		old, ok := before[e.VideoID]
+		if !ok {
+			diff.Added = append(diff.Added, e)
+			continue
+		}
+		kept = append(kept, e)
+		if old.Title != e.Title {
+			diff.Renamed = append(diff.Renamed, PlaylistRename{VideoID: e.VideoID, OldTitle: old.Title, NewTitle: e.Title})
+		}
+	}
+	for _, e := range from {
+		if !after[e.VideoID] {
+			diff.Removed = append(diff.Removed, e)
+		}
+	}
+
+	oldPositions := make([]int, len(kept))
+	for i, e := range kept {
+		oldPositions[i] = before[e.VideoID].Position
+	}
+	stable := longestIncreasingRun(oldPositions)
+	for i, e := range kept {
+		if !stable[i] {
+			diff.Reordered = append(diff.Reordered, PlaylistMove{
+				VideoID:     e.VideoID,
+				Title:       e.Title,
+				OldPosition: before[e.VideoID].Position,
+				NewPosition: e.Position,
+			})
+		}
+	}
+	return diff
+}
+
+// longestIncreasingRun marks the elements of one longest strictly increasing
+// subsequence of values (patience sorting, O(n log n)).
+func longestIncreasingRun(values []int) []bool {
+	// tails[k] is the index of the smallest tail of an increasing subsequence
+	// of length k+1; prev links each element to its predecessor.
+	tails := []int{}
+	prev := make([]int, len(values))
+	for i, v := range values {
+		k := sort.Search(len(tails), func(j int) bool { return values[tails[j]] >= v })
+		if k > 0 {
+			prev[i] = tails[k-1]
+		} else {
+			prev[i] = -1
+		}
+		if k == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[k] = i
+		}
+	}
+
+	marked := make([]bool, len(values))
+	if len(tails) == 0 {
+		return marked
+	}
+	for i := tails[len(tails)-1]; i >= 0; i = prev[i] {
+		marked[i] = true
+	}
+	return marked
+}
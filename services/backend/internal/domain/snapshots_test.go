@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func entries(ids ...string) []PlaylistEntry {
+	result := make([]PlaylistEntry, len(ids))
+	for i, id := range ids {
+		result[i] = PlaylistEntry{VideoID: id, Title: "title " + id, Position: i + 1}
+	}
+	return result
+}
+
+func moveIDs(moves []PlaylistMove) []string {
+	ids := []string{}
+	for _, m := range moves {
+		ids = append(ids, m.VideoID)
+	}
+	return ids
+}
+
+func entryIDs(list []PlaylistEntry) []string {
+	ids := []string{}
+	for _, e := range list {
+		ids = append(ids, e.VideoID)
+	}
+	return ids
+}
+
+func TestDiffPlaylistEntries(t *testing.T) {
+	tests := []struct {
+		name          string
+		from, to      []PlaylistEntry
+		wantAdded     []string
+		wantRemoved   []string
+		wantReordered []string
+	}{
+		{
+			name:          "unchanged",
+			from:          entries("a", "b", "c"),
+			to:            entries("a", "b", "c"),
+			wantAdded:     []string{},
+			wantRemoved:   []string{},
+			wantReordered: []string{},
+		},
+		{
+			name:          "insertion at the top does not reorder the rest",
+			from:          entries("a", "b", "c"),
+			to:            entries("x", "a", "b", "c"),
+			wantAdded:     []string{"x"},
+			wantRemoved:   []string{},
+			wantReordered: []string{},
+		},
+		{
+			name:          "removal",
+			from:          entries("a", "b", "c"),
+			to:            entries("a", "c"),
+			wantAdded:     []string{},
+			wantRemoved:   []string{"b"},
+			wantReordered: []string{},
+		},
+		{
+			name:          "one item moved to the end",
+			from:          entries("a", "b", "c", "d"),
+			to:            entries("b", "c", "d", "a"),
+			wantAdded:     []string{},
+			wantRemoved:   []string{},
+			wantReordered: []string{"a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := DiffPlaylistEntries(tt.from, tt.to)
+			if got := entryIDs(diff.Added); !reflect.DeepEqual(got, tt.wantAdded) {
+				t.Errorf("added = %v, want %v", got, tt.wantAdded)
+			}
+			if got := entryIDs(diff.Removed); !reflect.DeepEqual(got, tt.wantRemoved) {
+				t.Errorf("removed = %v, want %v", got, tt.wantRemoved)
+			}
+			if got := moveIDs(diff.Reordered); !reflect.DeepEqual(got, tt.wantReordered) {
+				t.Errorf("reordered = %v, want %v", got, tt.wantReordered)
+			}
+		})
+	}
+}
+
+func TestDiffPlaylistEntriesRenamed(t *testing.T) {
+	from := entries("a", "b")
+	to := entries("a", "b")
+	to[1].Title = "new title"
+
+	diff := DiffPlaylistEntries(from, to)
+	want := []PlaylistRename{{VideoID: "b", OldTitle: "title b", NewTitle: "new title"}}
+	if !reflect.DeepEqual(diff.Renamed, want) {
+		t.Errorf("renamed = %+v, want %+v", diff.Renamed, want)
+	}
+}
@@ -0,0 +1,263 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// TagRule tags items whose metadata matches its conditions and adds matching
+// videos to a collection. Rules are evaluated whenever metadata is stored
+// and when they are re-applied to the whole library.
+type TagRule struct {
+	ID      int64        `json:"id"`
+	Name    string       `json:"name"`
+	Enabled bool         `json:"enabled"`
+	Match   TagRuleMatch `json:"match"`
+	Tags    []string     `json:"tags"`
+	// CollectionID receives matching videos; playlists and channels are
+	// never added. A rule whose collection was deleted only tags.
+	CollectionID string    `json:"collection_id,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Content types a tag rule can be limited to.
+const (
+	TagRuleContentVideo    = "video"
+	TagRuleContentPlaylist = "playlist"
+	TagRuleContentChannel  = "channel"
+)
+
+// TagRuleMatch holds a rule's conditions, all of which must hold. Unset
+// conditions are ignored.
+type TagRuleMatch struct {
+	// ContentType limits the rule to videos, playlists or channels.
+	ContentType string `json:"content_type,omitempty"`
+	// TitlePattern is a regular expression matched case-insensitively
+	// against the title (the channel name for channels).
+	TitlePattern string `json:"title_pattern,omitempty"`
+	// Channel matches the channel name, channel ID or uploader ID
+	// (case-insensitive).
+	Channel string `json:"channel,omitempty"`
+	// MinDuration and MaxDuration bound a video's length in seconds; 0 is
+	// unbounded. Only videos have a duration, so either bound excludes
+	// playlists and channels.
+	MinDuration int `json:"min_duration,omitempty"`
+	MaxDuration int `json:"max_duration,omitempty"`
+}
+
+// Validate checks the rule and returns a message suitable for API clients.
+func (r *TagRule) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(r.Tags) == 0 && r.CollectionID == "" {
+		return fmt.Errorf("a rule needs tags or a collection")
+	}
+	for _, tag := range r.Tags {
+		if NormalizeTagName(tag) == "" {
+			return fmt.Errorf("tag names must not be empty")
+		}
+	}
+	switch r.Match.ContentType {
+	case "", TagRuleContentVideo, TagRuleContentPlaylist, TagRuleContentChannel:
+	default:
+		return fmt.Errorf("invalid content_type: %q", r.Match.ContentType)
+	}
+	if _, err := compileTitlePattern(r.Match.TitlePattern); err != nil {
+		return fmt.Errorf("invalid title_pattern: %w", err)
+	}
+	if r.Match.MinDuration < 0 || r.Match.MaxDuration < 0 {
+		return fmt.Errorf("durations must not be negative")
+	}
+	if r.Match.MaxDuration > 0 && r.Match.MinDuration > r.Match.MaxDuration {
+		return fmt.Errorf("min_duration must not exceed max_duration")
+	}
+	return nil
+}
+
+func compileTitlePattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	return regexp.Compile("(?i)" + pattern)
+}
+
+// TagSynonym rewrites the Alias tag to Canonical wherever tags are derived.
+type TagSynonym struct {
+	Alias     string `json:"alias"`
+	Canonical string `json:"canonical"`
+}
+
+// TagVocabulary normalizes derived tags: synonyms are rewritten to their
+// canonical name and blocked names are dropped. Tags users attach by hand are
+// never rewritten.
+type TagVocabulary struct {
+	Synonyms []TagSynonym `json:"synonyms"`
+	Blocked  []string     `json:"blocked"`
+}
+
+// TagRuleRun summarizes re-applying auto-tagging and the rules to the whole
+// library. Removed counts auto and rule tags that no longer apply.
+type TagRuleRun struct {
+	Items            int `json:"items"`
+	TagsAdded        int `json:"tags_added"`
+	TagsRemoved      int `json:"tags_removed"`
+	CollectionsAdded int `json:"collections_added"`
+}
+
+// TagEngine evaluates auto-tagging, tag rules and the vocabulary against
+// metadata. Build one per batch: patterns are compiled once.
+type TagEngine struct {
+	rules    []compiledTagRule
+	synonyms map[string]string // lower-case alias -> canonical
+	blocked  map[string]bool   // lower-case names
+}
+
+type compiledTagRule struct {
+	TagRule
+	title *regexp.Regexp
+}
+
+// NewTagEngine prepares the enabled rules and the vocabulary; either may be
+// nil. Rules with an invalid pattern are skipped.
+func NewTagEngine(rules []TagRule, vocabulary *TagVocabulary) *TagEngine {
+	e := &TagEngine{synonyms: map[string]string{}, blocked: map[string]bool{}}
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		title, err := compileTitlePattern(rule.Match.TitlePattern)
+		if err != nil {
+			continue
+		}
+		e.rules = append(e.rules, compiledTagRule{TagRule: rule, title: title})
+	}
+	if vocabulary != nil {
+		for _, s := range vocabulary.Synonyms {
+			e.synonyms[strings.ToLower(NormalizeTagName(s.Alias))] = NormalizeTagName(s.Canonical)
+		}
+		for _, name := range vocabulary.Blocked {
+			e.blocked[strings.ToLower(NormalizeTagName(name))] = true
+		}
+	}
+	return e
+}
+
+// Canonical normalizes a derived tag name through the vocabulary. It returns
+// "" for empty or blocked names.
+func (e *TagEngine) Canonical(name string) string {
+	name = NormalizeTagName(name)
+	if canonical, ok := e.synonyms[strings.ToLower(name)]; ok {
+		name = canonical
+	}
+	if name == "" || e.blocked[strings.ToLower(name)] {
+		return ""
+	}
+	return name
+}
+
+// AutoTags derives an item's automatic tags, applying the vocabulary before
+// the maxAutoTags cap so dropped keywords make room for the next ones.
+func (e *TagEngine) AutoTags(metadata Metadata) []string {
+	return e.normalize(autoTagCandidates(metadata), maxAutoTags)
+}
+
+// Evaluate returns the tags and collections the matching rules assign to an
+// item.
+func (e *TagEngine) Evaluate(metadata Metadata) (tags []string, collectionIDs []string) {
+	subject, ok := tagRuleSubjectFor(metadata)
+	if !ok {
+		return nil, nil
+	}
+	var names []string
+	seen := map[string]bool{}
+	for _, rule := range e.rules {
+		if !rule.matches(subject) {
+			continue
+		}
+		names = append(names, rule.Tags...)
+		if rule.CollectionID != "" && subject.contentType == TagRuleContentVideo && !seen[rule.CollectionID] {
+			seen[rule.CollectionID] = true
+			collectionIDs = append(collectionIDs, rule.CollectionID)
+		}
+	}
+	return e.normalize(names, 0), collectionIDs
+}
+
+// normalize maps names through the vocabulary and de-duplicates them
+// case-insensitively, keeping at most limit names (0 for no limit).
+func (e *TagEngine) normalize(names []string, limit int) []string {
+	tags := []string{}
+	seen := map[string]bool{}
+	for _, n := range names {
+		name := e.Canonical(n)
+		if name == "" || seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		tags = append(tags, name)
+		if limit > 0 && len(tags) >= limit {
+			break
+		}
+	}
+	return tags
+}
+
+// tagRuleSubject is the part of an item's metadata rules match on.
+type tagRuleSubject struct {
+	contentType string
+	title       string
+	channels    []string
+	duration    int
+}
+
+func tagRuleSubjectFor(metadata Metadata) (tagRuleSubject, bool) {
+	switch m := metadata.(type) {
+	case *VideoMetadata:
+		return tagRuleSubject{
+			contentType: TagRuleContentVideo,
+			title:       m.Title,
+			channels:    []string{m.Channel, m.ChannelID, m.UploaderID},
+			duration:    m.Duration,
+		}, true
+	case *PlaylistMetadata:
+		return tagRuleSubject{
+			contentType: TagRuleContentPlaylist,
+			title:       m.Title,
+			channels:    []string{m.Channel, m.ChannelID, m.UploaderID},
+		}, true
+	case *ChannelMetadata:
+		return tagRuleSubject{
+			contentType: TagRuleContentChannel,
+			title:       m.Channel,
+			channels:    []string{m.Channel, m.ID},
+		}, true
+	}
+	return tagRuleSubject{}, false
+}
+
+func (r *compiledTagRule) matches(s tagRuleSubject) bool {
+	m := r.Match
+	if m.ContentType != "" && m.ContentType != s.contentType {
+		return false
+	}
+	if r.title != nil && !r.title.MatchString(s.title) {
+		return false
+	}
+	if m.Channel != "" && !anyFold([]string{m.Channel}, s.channels) {
+		return false
+	}
+	if (m.MinDuration > 0 || m.MaxDuration > 0) && s.contentType != TagRuleContentVideo {
+		return false
+	}
+	if m.MinDuration > 0 && s.duration < m.MinDuration {
+		return false
+	}
+	if m.MaxDuration > 0 && s.duration > m.MaxDuration {
+		return false
+	}
+	return true
+}
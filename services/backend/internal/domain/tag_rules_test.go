@@ -0,0 +1,87 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTagEngineEvaluate(t *testing.T) {
+	video := &VideoMetadata{Title: "Lecture 12: Graphs", Channel: "Uni Channel", ChannelID: "UC1", Duration: 4000}
+	playlist := &PlaylistMetadata{Title: "Lecture 1-10", Channel: "Uni Channel"}
+
+	engine := NewTagEngine([]TagRule{
+		{Name: "course", Enabled: true, Match: TagRuleMatch{TitlePattern: `lecture \d+`}, Tags: []string{"course"}, CollectionID: "cs101"},
+		{Name: "long", Enabled: true, Match: TagRuleMatch{Channel: "uc1", MinDuration: 3600}, Tags: []string{"long-form"}},
+		{Name: "playlists", Enabled: true, Match: TagRuleMatch{ContentType: TagRuleContentPlaylist}, Tags: []string{"series"}},
+		{Name: "disabled", Enabled: false, Tags: []string{"never"}},
+		{Name: "bad pattern", Enabled: true, Match: TagRuleMatch{TitlePattern: "("}, Tags: []string{"never"}},
+	}, &TagVocabulary{Synonyms: []TagSynonym{{Alias: "long-form", Canonical: "Longform"}}})
+
+	tags, collections := engine.Evaluate(video)
+	if want := []string{"course", "Longform"}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("video tags = %v, want %v", tags, want)
+	}
+	if want := []string{"cs101"}; !reflect.DeepEqual(collections, want) {
+		t.Errorf("video collections = %v, want %v", collections, want)
+	}
+
+	// Duration bounds exclude playlists, and playlists never join collections.
+	tags, collections = engine.Evaluate(playlist)
+	if want := []string{"course", "series"}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("playlist tags = %v, want %v", tags, want)
+	}
+	if len(collections) != 0 {
+		t.Errorf("playlist collections = %v, want none", collections)
+	}
+}
+
+func TestTagEngineVocabulary(t *testing.T) {
+	engine := NewTagEngine(nil, &TagVocabulary{
+		Synonyms: []TagSynonym{{Alias: "JS", Canonical: "JavaScript"}},
+		Blocked:  []string{"subscribe", "Video"},
+	})
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"js", "JavaScript"},
+		{"  Subscribe ", ""},
+		{"VIDEO", ""},
+		{"Go", "Go"},
+	}
+	for _, tt := range tests {
+		if got := engine.Canonical(tt.name); got != tt.want {
+			t.Errorf("Canonical(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	tags := engine.AutoTags(&VideoMetadata{Tags: []string{"subscribe", "js", "javascript", "video", "Go"}})
+	if want := []string{"JavaScript", "Go"}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("AutoTags() = %v, want %v", tags, want)
+	}
+}
+
+func TestTagRuleValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    TagRule
+		wantErr bool
+	}{
+		{"valid", TagRule{Name: "r", Tags: []string{"x"}, Match: TagRuleMatch{TitlePattern: `lecture \d+`}}, false},
+		{"collection only", TagRule{Name: "r", CollectionID: "c1"}, false},
+		{"missing name", TagRule{Tags: []string{"x"}}, true},
+		{"no action", TagRule{Name: "r"}, true},
+		{"empty tag", TagRule{Name: "r", Tags: []string{" "}}, true},
+		{"bad content type", TagRule{Name: "r", Tags: []string{"x"}, Match: TagRuleMatch{ContentType: "audio"}}, true},
+		{"bad pattern", TagRule{Name: "r", Tags: []string{"x"}, Match: TagRuleMatch{TitlePattern: "("}}, true},
+		{"inverted durations", TagRule{Name: "r", Tags: []string{"x"}, Match: TagRuleMatch{MinDuration: 60, MaxDuration: 30}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.rule.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,11 +1,16 @@
 package domain
 
-import "strings"
+import (
+	"regexp"
+	"strings"
+)
 
 // TagSource records how a tag got attached to a job.
 const (
 	TagSourceUser = "user"
 	TagSourceAuto = "auto"
+	// TagSourceRule marks tags attached by a user-defined TagRule.
+	TagSourceRule = "rule"
 )
 
 // Tag is a label attached to a downloaded video, playlist or channel. Count is
@@ -16,6 +21,35 @@ type Tag struct {
 	Name   string `json:"name"`
 	Source string `json:"source,omitempty"`
 	Count  int    `json:"count,omitempty"`
+	// Color is a "#rrggbb" hex color, empty for the default.
+	Color string `json:"color,omitempty"`
+	// ParentID nests the tag under another one; filtering by a parent also
+	// matches items carrying its descendants.
+	ParentID *int64 `json:"parent_id,omitempty"`
+}
+
+var tagColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// ValidTagColor reports whether color is empty or a "#rrggbb" hex color.
+func ValidTagColor(color string) bool {
+	return color == "" || tagColorPattern.MatchString(color)
+}
+
+// TagParentCycle reports whether nesting the tag id under parentID would make
+// it its own ancestor, given the tag catalog.
+func TagParentCycle(catalog []Tag, id, parentID int64) bool {
+	parents := make(map[int64]*int64, len(catalog))
+	for _, tag := range catalog {
+		parents[tag.ID] = tag.ParentID
+	}
+	seen := map[int64]bool{}
+	for current := &parentID; current != nil && !seen[*current]; current = parents[*current] {
+		if *current == id {
+			return true
+		}
+		seen[*current] = true
+	}
+	return false
 }
 
 // maxAutoTags caps how many tags auto-tagging attaches per item so noisy
@@ -25,8 +59,15 @@ const maxAutoTags = 8
 // AutoTagsFor derives tags from extracted metadata: the content's categories
 // and channel name, topped up with the first few uploader-supplied keywords.
 // The result is deterministic so re-applying it on metadata refresh is
-// idempotent.
+// idempotent. TagEngine.AutoTags does the same with synonyms and the
+// blocklist applied.
 func AutoTagsFor(metadata Metadata) []string {
+	return NewTagEngine(nil, nil).AutoTags(metadata)
+}
+
+// autoTagCandidates lists the metadata fields auto-tagging draws from, most
+// significant first.
+func autoTagCandidates(metadata Metadata) []string {
 	var candidates []string
 	switch m := metadata.(type) {
 	case *VideoMetadata:
@@ -38,21 +79,7 @@ func AutoTagsFor(metadata Metadata) []string {
 	case *ChannelMetadata:
 		candidates = append(candidates, m.Channel)
 	}
-
-	tags := make([]string, 0, maxAutoTags)
-	seen := map[string]bool{}
-	for _, c := range candidates {
-		name := NormalizeTagName(c)
-		if name == "" || seen[strings.ToLower(name)] {
-			continue
-		}
-		seen[strings.ToLower(name)] = true
-		tags = append(tags, name)
-		if len(tags) >= maxAutoTags {
-			break
-		}
-	}
-	return tags
+	return candidates
 }
 
 // NormalizeTagName trims and length-caps a tag name. Tags are matched
@@ -0,0 +1,43 @@
+package domain
+
+import "testing"
+
+func TestValidTagColor(t *testing.T) {
+	for color, want := range map[string]bool{
+		"":         true,
+		"#1a2B3c":  true,
+		"#fff":     false,
+		"1a2b3c":   false,
+		"#1a2b3g":  false,
+		"#1a2b3c4": false,
+	} {
+		if got := ValidTagColor(color); got != want {
+			t.Errorf("ValidTagColor(%q) = %v, want %v", color, got, want)
+		}
+	}
+}
+
+func TestTagParentCycle(t *testing.T) {
+	id := func(v int64) *int64 { return &v }
+	// 1 <- 2 <- 3, and 4 on its own.
+	catalog := []Tag{{ID: 1}, {ID: 2, ParentID: id(1)}, {ID: 3, ParentID: id(2)}, {ID: 4}}
+
+	tests := []struct {
+		name         string
+		id, parentID int64
+		want         bool
+	}{
+		{"unrelated", 4, 3, false},
+		{"move deeper", 3, 1, false},
+		{"self", 1, 1, true},
+		{"under own child", 1, 2, true},
+		{"under own grandchild", 1, 3, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TagParentCycle(catalog, tt.id, tt.parentID); got != tt.want {
+				t.Errorf("TagParentCycle(%d, %d) = %v, want %v", tt.id, tt.parentID, got, tt.want)
+			}
+		})
+	}
+}
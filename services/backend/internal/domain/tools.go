@@ -21,6 +21,7 @@ const (
 	OpTypeExtractAudio  ToolsOperationType = "extract_audio"
 	OpTypeAdjustQuality ToolsOperationType = "adjust_quality"
 	OpTypeRotate        ToolsOperationType = "rotate"
+	OpTypeRemux         ToolsOperationType = "remux"
 	OpTypeWorkflow      ToolsOperationType = "workflow"
 )
 
@@ -60,6 +61,10 @@ type ToolsJob struct {
 	Height     int     `json:"height,omitempty"`
 	VideoCodec string  `json:"video_codec,omitempty"`
 	AudioCodec string  `json:"audio_codec,omitempty"`
+	// Set on jobs submitted by an automation rule: the rule, and the
+	// download job of the video it ran on.
+	AutomationRuleID string `json:"automation_rule_id,omitempty"`
+	SourceJobID      string `json:"source_job_id,omitempty"`
 }
 
 // Media kind of a produced output file, probed after the job completes.
@@ -77,10 +82,34 @@ type TrimParameters struct {
 	ReEncode  bool   `json:"re_encode"`  // Re-encode for frame-accurate cuts
 }
 
+// ProducesPlaybackCopy reports whether the job writes a browser-playable
+// copy of its input: a convert, or a remux that keeps the original.
+func (j *ToolsJob) ProducesPlaybackCopy() bool {
+	switch j.OperationType {
+	case OpTypeConvert:
+		return true
+	case OpTypeRemux:
+		replace, _ := j.Parameters["replace_original"].(bool)
+		return !replace
+	}
+	return false
+}
+
 type ConcatParameters struct {
 	OutputFormat string   `json:"output_format"` // mp4, mkv, webm
 	ReEncode     bool     `json:"re_encode"`     // Re-encode if codecs differ
 	FileOrder    []string `json:"file_order"`    // Explicit ordering by job ID
+	// Segments, when set, joins these parts of the input videos, in order,
+	// instead of the whole videos; a video may appear several times.
+	// FileOrder is ignored.
+	Segments []ConcatSegment `json:"segments,omitempty"`
+}
+
+// ConcatSegment is a part of an input video, for highlight reels.
+type ConcatSegment struct {
+	JobID     string `json:"job_id"`
+	StartTime string `json:"start_time"` // HH:MM:SS(.ms) or seconds
+	EndTime   string `json:"end_time"`   // HH:MM:SS(.ms) or seconds
 }
 
 type ConvertParameters struct {
@@ -90,6 +119,15 @@ type ConvertParameters struct {
 	Bitrate      string `json:"bitrate"`       // e.g. "2M", "5M"
 }
 
+// RemuxParameters copies the streams into a new container without
+// re-encoding, with the index at the front for progressive playback.
+type RemuxParameters struct {
+	OutputFormat string `json:"output_format"` // mp4 (default), mov, mkv
+	// ReplaceOriginal swaps the downloaded file for the remuxed one instead
+	// of writing to the processed directory. Requires a single video.
+	ReplaceOriginal bool `json:"replace_original"`
+}
+
 type ExtractAudioParameters struct {
 	OutputFormat string `json:"output_format"` // mp3, aac, flac, wav, ogg
 	Bitrate      string `json:"bitrate"`       // e.g. "128k", "320k"
@@ -141,7 +179,12 @@ type ToolsRepository interface {
 	GetByID(id string) (*ToolsJob, error)
 	GetAll() ([]*ToolsJob, error)
 	GetByStatus(status ToolsJobStatus) ([]*ToolsJob, error)
+	// FindLatestConvertForInput returns the newest job of a single video
+	// that ProducesPlaybackCopy, or nil.
 	FindLatestConvertForInput(jobID string) (*ToolsJob, error)
+	// ListBySourceJob returns the jobs automation submitted for a download
+	// job, newest first.
+	ListBySourceJob(sourceJobID string) ([]*ToolsJob, error)
 	Delete(id string) error
 	List(page int, limit int, status string, operationType string) ([]*ToolsJob, int, error)
 }
@@ -149,17 +192,68 @@ type ToolsRepository interface {
 // PlaybackInfo describes whether a downloaded video's codecs can be decoded by
 // browsers, and any transcode job that produces a compatible version.
 type PlaybackInfo struct {
-	Container   string             `json:"container"`
-	VideoCodec  string             `json:"video_codec"`
-	AudioCodec  string             `json:"audio_codec"`
-	BrowserSafe bool               `json:"browser_safe"`
-	Transcode   *PlaybackTranscode `json:"transcode,omitempty"`
+	Container   string `json:"container"`
+	VideoCodec  string `json:"video_codec"`
+	AudioCodec  string `json:"audio_codec"`
+	BrowserSafe bool   `json:"browser_safe"`
+	// Faststart reports an mp4/mov index ahead of the media data.
+	Faststart bool               `json:"faststart"`
+	Playback  PlaybackMode       `json:"playback"`
+	Transcode *PlaybackTranscode `json:"transcode,omitempty"`
+	// HLSPath is the master playlist of an adaptive stream transcoded on
+	// the fly, playable right away whatever the codecs.
+	HLSPath string `json:"hls_path"`
+	// StoryboardPath is the WebVTT index of seek bar previews.
+	StoryboardPath string `json:"storyboard_path"`
+}
+
+// BrowserSafeCodecs reports whether a probed media file can be decoded by the
+// HTML5 <video> element across browsers. Downloads are merged into mp4
+// containers, but merging only remuxes: files can carry VP9/AV1 video or Opus
+// audio that Safari (and others) cannot decode. h264 + aac/mp3 is the
+// universally supported combination.
+func BrowserSafeCodecs(videoCodec, audioCodec string, hasAudio bool) bool {
+	if videoCodec != "h264" {
+		return false
+	}
+	if !hasAudio {
+		return true
+	}
+	return audioCodec == "aac" || audioCodec == "mp3"
+}
+
+// PlaybackMode is what a video needs before browsers can play it.
+type PlaybackMode string
+
+const (
+	PlaybackModeDirect PlaybackMode = "direct"
+	// PlaybackModeRemux: the codecs play, but the container doesn't, or an mp4
+	// keeps its index at the end; copying the streams takes seconds.
+	PlaybackModeRemux PlaybackMode = "remux"
+	// PlaybackModeTranscode: the codecs need re-encoding.
+	PlaybackModeTranscode PlaybackMode = "transcode"
+)
+
+// browserContainers are the containers browsers play h264/aac from.
+var browserContainers = map[string]bool{"mp4": true, "m4v": true, "mov": true}
+
+// PlaybackModeFor decides what a file in container (its extension) with the
+// given codecs needs. faststart only matters for browser containers.
+func PlaybackModeFor(container, videoCodec, audioCodec string, hasAudio, faststart bool) PlaybackMode {
+	if !BrowserSafeCodecs(videoCodec, audioCodec, hasAudio) {
+		return PlaybackModeTranscode
+	}
+	if !browserContainers[container] || !faststart {
+		return PlaybackModeRemux
+	}
+	return PlaybackModeDirect
 }
 
-// PlaybackTranscode is the state of the convert job backing a browser-safe
-// version of a video.
+// PlaybackTranscode is the state of the convert or remux job backing a
+// browser-safe version of a video.
 type PlaybackTranscode struct {
-	JobID    string         `json:"job_id"`
-	Status   ToolsJobStatus `json:"status"`
-	Progress float64        `json:"progress"`
+	JobID     string             `json:"job_id"`
+	Operation ToolsOperationType `json:"operation"`
+	Status    ToolsJobStatus     `json:"status"`
+	Progress  float64            `json:"progress"`
 }
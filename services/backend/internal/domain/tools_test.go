@@ -0,0 +1,70 @@
+package domain
+
+import "testing"
+
+func TestBrowserSafeCodecs(t *testing.T) {
+	tests := []struct {
+		name       string
+		videoCodec string
+		audioCodec string
+		hasAudio   bool
+		want       bool
+	}{
+		{"h264+aac", "h264", "aac", true, true},
+		{"h264+mp3", "h264", "mp3", true, true},
+		{"h264 no audio track", "h264", "", false, true},
+		{"h264+opus", "h264", "opus", true, false},
+		{"vp9+aac", "vp9", "aac", true, false},
+		{"av1+opus", "av1", "opus", true, false},
+		{"empty probe", "", "", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BrowserSafeCodecs(tt.videoCodec, tt.audioCodec, tt.hasAudio); got != tt.want {
+				t.Errorf("BrowserSafeCodecs(%q, %q, %v) = %v, want %v",
+					tt.videoCodec, tt.audioCodec, tt.hasAudio, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlaybackModeFor(t *testing.T) {
+	tests := []struct {
+		name      string
+		container string
+		video     string
+		audio     string
+		faststart bool
+		want      PlaybackMode
+	}{
+		{"faststart mp4", "mp4", "h264", "aac", true, PlaybackModeDirect},
+		{"mp4 with index at the end", "mp4", "h264", "aac", false, PlaybackModeRemux},
+		{"h264 in mkv", "mkv", "h264", "aac", false, PlaybackModeRemux},
+		{"vp9 in webm", "webm", "vp9", "opus", false, PlaybackModeTranscode},
+		{"h264+opus in mp4", "mp4", "h264", "opus", true, PlaybackModeTranscode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PlaybackModeFor(tt.container, tt.video, tt.audio, true, tt.faststart); got != tt.want {
+				t.Errorf("PlaybackModeFor() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProducesPlaybackCopy(t *testing.T) {
+	tests := []struct {
+		job  ToolsJob
+		want bool
+	}{
+		{ToolsJob{OperationType: OpTypeConvert}, true},
+		{ToolsJob{OperationType: OpTypeRemux, Parameters: map[string]any{}}, true},
+		{ToolsJob{OperationType: OpTypeRemux, Parameters: map[string]any{"replace_original": true}}, false},
+		{ToolsJob{OperationType: OpTypeTrim}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.job.ProducesPlaybackCopy(); got != tt.want {
+			t.Errorf("ProducesPlaybackCopy(%s %v) = %v, want %v", tt.job.OperationType, tt.job.Parameters, got, tt.want)
+		}
+	}
+}
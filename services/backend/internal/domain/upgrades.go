@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+type UpgradeRunStatus string
+
+const (
+	UpgradeRunRunning  UpgradeRunStatus = "running"
+	UpgradeRunComplete UpgradeRunStatus = "complete"
+	// UpgradeRunStopped: the service shut down before the run finished.
+	UpgradeRunStopped UpgradeRunStatus = "stopped"
+)
+
+// UpgradeOutcome is what an upgrade run did with one video.
+type UpgradeOutcome string
+
+const (
+	UpgradeOutcomeUpgraded UpgradeOutcome = "upgraded"
+	// UpgradeOutcomeAvailable: a dry run found a better format.
+	UpgradeOutcomeAvailable     UpgradeOutcome = "available"
+	UpgradeOutcomeNoImprovement UpgradeOutcome = "no_improvement"
+	UpgradeOutcomeFailed        UpgradeOutcome = "failed"
+)
+
+// UpgradeRun is one pass of re-downloading archived videos whose stored
+// resolution or codec falls short of the current quality settings. The
+// counters and byte totals form the report; Items is only populated when a
+// single run is requested.
+type UpgradeRun struct {
+	ID           int64            `json:"id"`
+	Status       UpgradeRunStatus `json:"status"`
+	DryRun       bool             `json:"dry_run"`
+	TargetHeight int              `json:"target_height"`
+	Candidates   int              `json:"candidates"`
+	Checked      int              `json:"checked"`
+	Upgraded     int              `json:"upgraded"`
+	Failed       int              `json:"failed"`
+	// BytesBefore and BytesAfter are the sizes of the upgraded files before
+	// and after, so BytesAfter-BytesBefore is the disk the run cost. In a dry
+	// run Upgraded counts the videos that would be upgraded and BytesAfter
+	// is yt-dlp's size estimate.
+	BytesBefore int64         `json:"bytes_before"`
+	BytesAfter  int64         `json:"bytes_after"`
+	StartedAt   time.Time     `json:"started_at"`
+	FinishedAt  *time.Time    `json:"finished_at,omitempty"`
+	Items       []UpgradeItem `json:"items,omitempty"`
+}
+
+// UpgradeItem records what an upgrade run found and did for one video.
+type UpgradeItem struct {
+	ID        int64          `json:"id"`
+	RunID     int64          `json:"run_id"`
+	JobID     string         `json:"job_id"`
+	Title     string         `json:"title"`
+	Outcome   UpgradeOutcome `json:"outcome"`
+	OldHeight int            `json:"old_height"`
+	NewHeight int            `json:"new_height"`
+	OldCodec  string         `json:"old_codec"`
+	NewCodec  string         `json:"new_codec"`
+	OldSize   int64          `json:"old_size"`
+	NewSize   int64          `json:"new_size"`
+	Error     string         `json:"error,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// UpgradeUpdate is broadcast over the WebSocket as an upgrade run progresses.
+type UpgradeUpdate struct {
+	Type       string           `json:"type"` // always "upgrade"
+	RunID      int64            `json:"runID"`
+	Status     UpgradeRunStatus `json:"status"`
+	Candidates int              `json:"candidates"`
+	Checked    int              `json:"checked"`
+	Upgraded   int              `json:"upgraded"`
+	Failed     int              `json:"failed"`
+}
+
+// IsPreferredVideoCodec reports whether vcodec is the codec downloads prefer:
+// H.264, which every browser can decode from an mp4.
+func IsPreferredVideoCodec(vcodec string) bool {
+	vcodec = strings.ToLower(vcodec)
+	return strings.HasPrefix(vcodec, "avc1") || strings.HasPrefix(vcodec, "h264")
+}
+
+// IsQualityImprovement reports whether a format of newHeight/newCodec would
+// be better than the archived oldHeight/oldCodec: a higher resolution, or the
+// preferred codec at no loss of resolution.
+func IsQualityImprovement(oldHeight int, oldCodec string, newHeight int, newCodec string) bool {
+	if newHeight > oldHeight {
+		return true
+	}
+	return newHeight == oldHeight && !IsPreferredVideoCodec(oldCodec) && IsPreferredVideoCodec(newCodec)
+}
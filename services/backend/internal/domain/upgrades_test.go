@@ -0,0 +1,29 @@
+package domain
+
+import "testing"
+
+func TestIsQualityImprovement(t *testing.T) {
+	tests := []struct {
+		name      string
+		oldHeight int
+		oldCodec  string
+		newHeight int
+		newCodec  string
+		want      bool
+	}{
+		{"higher resolution", 720, "avc1.64001F", 1080, "avc1.640028", true},
+		{"higher resolution, other codec", 720, "avc1.64001F", 1080, "vp09.00.40.08", true},
+		{"same format", 1080, "avc1.640028", 1080, "avc1.640028", false},
+		{"preferred codec at same resolution", 1080, "vp09.00.40.08", 1080, "avc1.640028", true},
+		{"preferred codec at lower resolution", 1080, "vp09.00.40.08", 720, "avc1.64001F", false},
+		{"lower resolution", 1080, "avc1.640028", 720, "avc1.64001F", false},
+		{"unknown old codec", 1080, "", 1080, "h264", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsQualityImprovement(tt.oldHeight, tt.oldCodec, tt.newHeight, tt.newCodec); got != tt.want {
+				t.Errorf("IsQualityImprovement() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// WatchedThreshold is the share of a video's duration after which playback
+// counts as watched, leaving end credits and outros out.
+const WatchedThreshold = 0.9
+
+// WatchProgress is where playback of a video last stopped.
+type WatchProgress struct {
+	JobID string `json:"job_id"`
+	// Position and Duration are in seconds.
+	Position      float64   `json:"position"`
+	Duration      float64   `json:"duration"`
+	Completed     bool      `json:"completed"`
+	LastWatchedAt time.Time `json:"last_watched_at"`
+}
+
+// Percent is how far into the video playback got, from 0 to 100.
+func (p *WatchProgress) Percent() float64 {
+	if p.Completed {
+		return 100
+	}
+	if p.Duration <= 0 {
+		return 0
+	}
+	return min(p.Position/p.Duration*100, 100)
+}
+
+// WatchProgressUpdate is a progress report from a player. Duration defaults
+// to the video's duration; Completed marks the video watched (true) or
+// unwatched (false) regardless of position, which otherwise decides it.
+type WatchProgressUpdate struct {
+	Position  float64  `json:"position"`
+	Duration  *float64 `json:"duration,omitempty"`
+	Completed *bool    `json:"completed,omitempty"`
+}
+
+// Apply turns the update into the progress of the video jobID, whose known
+// duration is duration seconds.
+func (u WatchProgressUpdate) Apply(jobID string, duration float64, at time.Time) (*WatchProgress, error) {
+	if u.Position < 0 {
+		return nil, fmt.Errorf("position can't be negative")
+	}
+	if u.Duration != nil {
+		if *u.Duration < 0 {
+			return nil, fmt.Errorf("duration can't be negative")
+		}
+		duration = *u.Duration
+	}
+	progress := &WatchProgress{
+		JobID:         jobID,
+		Position:      u.Position,
+		Duration:      duration,
+		LastWatchedAt: at,
+	}
+	if duration > 0 && progress.Position > duration {
+		progress.Position = duration
+	}
+	if u.Completed != nil {
+		progress.Completed = *u.Completed
+	} else {
+		progress.Completed = duration > 0 && progress.Position >= duration*WatchedThreshold
+	}
+	return progress, nil
+}
+
+// WatchState narrows a listing by watch progress.
+type WatchState string
+
+const (
+	WatchStateWatched    WatchState = "watched"
+	WatchStateUnwatched  WatchState = "unwatched"
+	WatchStateInProgress WatchState = "in-progress"
+)
+
+func (s WatchState) IsValid() bool {
+	switch s {
+	case WatchStateWatched, WatchStateUnwatched, WatchStateInProgress:
+		return true
+	}
+	return false
+}
+
+// WatchCompletion summarizes how much of a playlist, channel or collection
+// has been watched.
+type WatchCompletion struct {
+	Watched int `json:"watched"`
+	Total   int `json:"total"`
+	// Percent is Watched out of Total, rounded down.
+	Percent int `json:"percent"`
+}
+
+func NewWatchCompletion(watched, total int) *WatchCompletion {
+	c := &WatchCompletion{Watched: watched, Total: total}
+	if total > 0 {
+		c.Percent = watched * 100 / total
+	}
+	return c
+}
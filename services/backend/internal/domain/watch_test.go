@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWatchProgressUpdateApply(t *testing.T) {
+	yes, no := true, false
+	longer := 600.0
+	at := time.Now()
+
+	tests := []struct {
+		name          string
+		update        WatchProgressUpdate
+		wantPosition  float64
+		wantDuration  float64
+		wantCompleted bool
+	}{
+		{"started", WatchProgressUpdate{Position: 60}, 60, 300, false},
+		{"near the end", WatchProgressUpdate{Position: 280}, 280, 300, true},
+		{"past the end", WatchProgressUpdate{Position: 400}, 300, 300, true},
+		{"player duration", WatchProgressUpdate{Position: 280, Duration: &longer}, 280, 600, false},
+		{"marked watched", WatchProgressUpdate{Completed: &yes}, 0, 300, true},
+		{"marked unwatched", WatchProgressUpdate{Position: 290, Completed: &no}, 290, 300, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.update.Apply("v1", 300, at)
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			if got.Position != tt.wantPosition || got.Duration != tt.wantDuration || got.Completed != tt.wantCompleted {
+				t.Errorf("Apply() = %+v, want position %v, duration %v, completed %v",
+					got, tt.wantPosition, tt.wantDuration, tt.wantCompleted)
+			}
+		})
+	}
+
+	if _, err := (WatchProgressUpdate{Position: -1}).Apply("v1", 300, at); err == nil {
+		t.Error("Apply(negative position) error = nil")
+	}
+}
+
+func TestNewWatchCompletion(t *testing.T) {
+	if got := NewWatchCompletion(2, 3); got.Percent != 66 {
+		t.Errorf("Percent = %d, want 66", got.Percent)
+	}
+	if got := NewWatchCompletion(0, 0); got.Percent != 0 {
+		t.Errorf("empty Percent = %d, want 0", got.Percent)
+	}
+}
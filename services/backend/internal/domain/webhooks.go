@@ -0,0 +1,171 @@
+package domain
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// WebhookEvent names a lifecycle event webhooks can subscribe to.
+type WebhookEvent string
+
+const (
+	WebhookEventDownloadCompleted WebhookEvent = "download.completed"
+	WebhookEventDownloadFailed    WebhookEvent = "download.failed"
+	WebhookEventDownloadCancelled WebhookEvent = "download.cancelled"
+	WebhookEventToolsCompleted    WebhookEvent = "tools.completed"
+	WebhookEventToolsFailed       WebhookEvent = "tools.failed"
+	// WebhookEventSubscriptionItem: a re-synced playlist or channel gained a
+	// video since its previous sync.
+	WebhookEventSubscriptionItem WebhookEvent = "subscription.new_item"
+	// WebhookEventVideoRemoved: an archived video stopped being available at
+	// its source (removed, private, terminated or geo-blocked).
+	WebhookEventVideoRemoved WebhookEvent = "video.removed"
+	// WebhookEventPing is only sent by the test endpoint.
+	WebhookEventPing WebhookEvent = "ping"
+)
+
+// WebhookEvents lists the events a webhook can subscribe to.
+var WebhookEvents = []WebhookEvent{
+	WebhookEventDownloadCompleted,
+	WebhookEventDownloadFailed,
+	WebhookEventDownloadCancelled,
+	WebhookEventToolsCompleted,
+	WebhookEventToolsFailed,
+	WebhookEventSubscriptionItem,
+	WebhookEventVideoRemoved,
+}
+
+// IsValid reports whether e is an event webhooks can subscribe to.
+func (e WebhookEvent) IsValid() bool {
+	for _, event := range WebhookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Webhook is a registered HTTP endpoint that receives signed JSON payloads
+// for the events it subscribes to. No events means all of them.
+type Webhook struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	// Secret keys the HMAC-SHA256 signature sent in X-Webhook-Signature. It
+	// is write-only: only the create response carries it.
+	Secret    string         `json:"-"`
+	Events    []WebhookEvent `json:"events"`
+	Enabled   bool           `json:"enabled"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// Subscribes reports whether the webhook wants event delivered.
+func (w *Webhook) Subscribes(event WebhookEvent) bool {
+	if !w.Enabled {
+		return false
+	}
+	if len(w.Events) == 0 || event == WebhookEventPing {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the URL and event filter and returns a message suitable
+// for API clients.
+func (w *Webhook) Validate() error {
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an absolute http or https URL")
+	}
+	seen := make(map[WebhookEvent]bool, len(w.Events))
+	for _, event := range w.Events {
+		if !event.IsValid() {
+			return fmt.Errorf("unknown event %q", event)
+		}
+		if seen[event] {
+			return fmt.Errorf("event %q listed twice", event)
+		}
+		seen[event] = true
+	}
+	return nil
+}
+
+// WebhookPayload is the JSON body POSTed to a webhook. Data mirrors what the
+// WebSocket carries for the event: a ProgressUpdate for downloads, a
+// ToolsProgressUpdate for tools jobs, a SubscriptionItem or an
+// AvailabilityUpdate.
+type WebhookPayload struct {
+	Event      WebhookEvent `json:"event"`
+	DeliveryID int64        `json:"delivery_id"`
+	Timestamp  time.Time    `json:"timestamp"`
+	Data       any          `json:"data"`
+}
+
+// SubscriptionItem is the data of a subscription.new_item event.
+type SubscriptionItem struct {
+	JobID    string `json:"jobID"` // the playlist or channel job
+	SourceID string `json:"sourceID"`
+	VideoID  string `json:"videoID"`
+	Title    string `json:"title"`
+	Position int    `json:"position"`
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery is the log entry of one event sent to one webhook,
+// updated after every attempt.
+type WebhookDelivery struct {
+	ID        int64                 `json:"id"`
+	WebhookID string                `json:"webhook_id"`
+	Event     WebhookEvent          `json:"event"`
+	Payload   string                `json:"payload"`
+	Status    WebhookDeliveryStatus `json:"status"`
+	Attempts  int                   `json:"attempts"`
+	// ResponseStatus is the HTTP status of the last attempt; 0 when the
+	// request never got a response.
+	ResponseStatus int        `json:"response_status,omitempty"`
+	Error          string     `json:"error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+}
+
+// Notifier receives lifecycle events for webhook delivery. Notify must not
+// block the caller on network I/O.
+//
+//tygo:ignore
+type Notifier interface {
+	Notify(event WebhookEvent, data any)
+}
+
+//tygo:ignore
+type WebhookRepository interface {
+	Create(webhook *Webhook) error
+	Update(webhook *Webhook) error
+	// Delete removes a webhook and its delivery log.
+	Delete(id string) error
+	GetByID(id string) (*Webhook, error)
+	List() ([]*Webhook, error)
+	// CreateDelivery logs a delivery and trims the webhook's log to its
+	// most recent finished deliveries.
+	CreateDelivery(delivery *WebhookDelivery) error
+	UpdateDelivery(delivery *WebhookDelivery) error
+	// ListDeliveries returns a webhook's most recent deliveries, newest first.
+	ListDeliveries(webhookID string, limit int) ([]*WebhookDelivery, error)
+	// ListPendingDeliveries returns deliveries that were still being retried
+	// when the service last stopped.
+	ListPendingDeliveries() ([]*WebhookDelivery, error)
+}
@@ -0,0 +1,142 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"video-archiver/internal/domain"
+)
+
+// The annotations table holds favorites, ratings and notes of jobs and
+// collections, keyed by item ID and kind. Items without any are absent.
+
+// getAnnotation returns an item's annotation, or nil when it has none.
+func getAnnotation(db *sql.DB, itemID string, kind domain.AnnotationKind) (*domain.Annotation, error) {
+	var a domain.Annotation
+	err := db.QueryRow(`
+        SELECT favorite, rating, notes, updated_at
+        FROM annotations
+        WHERE item_id = ? AND kind = ?`, itemID, kind).Scan(&a.Favorite, &a.Rating, &a.Notes, &a.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get annotation: %w", err)
+	}
+	return &a, nil
+}
+
+// setAnnotation stores an item's annotation; an empty one is removed.
+func setAnnotation(q execer, itemID string, kind domain.AnnotationKind, a *domain.Annotation) error {
+	if a.IsEmpty() {
+		if _, err := q.Exec(`DELETE FROM annotations WHERE item_id = ? AND kind = ?`, itemID, kind); err != nil {
+			return fmt.Errorf("delete annotation: %w", err)
+		}
+		return nil
+	}
+	if _, err := q.Exec(`
+        INSERT INTO annotations (item_id, kind, favorite, rating, notes, updated_at)
+        VALUES (?, ?, ?, ?, ?, ?)
+        ON CONFLICT(item_id, kind) DO UPDATE SET
+            favorite = excluded.favorite,
+            rating = excluded.rating,
+            notes = excluded.notes,
+            updated_at = excluded.updated_at`,
+		itemID, kind, a.Favorite, a.Rating, a.Notes, a.UpdatedAt.UTC()); err != nil {
+		return fmt.Errorf("set annotation: %w", err)
+	}
+	return nil
+}
+
+// annotationField is a column of the annotation of the job in jobColumn;
+// unannotated jobs read as zero or an empty string.
+func annotationField(jobColumn, column string) string {
+	fallback := "0"
+	if column == "notes" {
+		fallback = "''"
+	}
+	return `COALESCE((SELECT a.` + column + ` FROM annotations a
+            WHERE a.item_id = ` + jobColumn + ` AND a.kind = 'job'), ` + fallback + `)`
+}
+
+// SetAnnotation stores a job's favorite flag, rating and notes and updates
+// its search document.
+func (r *JobRepository) SetAnnotation(jobID string, a *domain.Annotation) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin set annotation: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := setAnnotation(tx, jobID, domain.AnnotationKindJob, a); err != nil {
+		return err
+	}
+	if err := reindexJobsSearch(tx, []string{jobID}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetAnnotation returns a job's annotation, or nil when it has none.
+func (r *JobRepository) GetAnnotation(jobID string) (*domain.Annotation, error) {
+	return getAnnotation(r.db, jobID, domain.AnnotationKindJob)
+}
+
+// SetAnnotation stores a collection's favorite flag, rating and notes and
+// updates its search document.
+func (r *CollectionRepository) SetAnnotation(collectionID string, a *domain.Annotation) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin set annotation: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := setAnnotation(tx, collectionID, domain.AnnotationKindCollection, a); err != nil {
+		return err
+	}
+	if err := reindexCollectionSearch(tx, collectionID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetAnnotation returns a collection's annotation, or nil when it has none.
+func (r *CollectionRepository) GetAnnotation(collectionID string) (*domain.Annotation, error) {
+	return getAnnotation(r.db, collectionID, domain.AnnotationKindCollection)
+}
+
+// attachAnnotations loads the annotations of a page of jobs in one query and
+// assigns them.
+func (r *JobRepository) attachAnnotations(items []*domain.JobWithMetadata) error {
+	ids, placeholders := jobIDArgs(items)
+	if len(ids) == 0 {
+		return nil
+	}
+
+	rows, err := r.db.Query(`
+        SELECT item_id, favorite, rating, notes, updated_at
+        FROM annotations
+        WHERE kind = 'job' AND item_id IN (`+placeholders+`)`, ids...)
+	if err != nil {
+		return fmt.Errorf("load annotations: %w", err)
+	}
+	defer rows.Close()
+
+	byJob := map[string]*domain.Annotation{}
+	for rows.Next() {
+		var jobID string
+		var a domain.Annotation
+		if err := rows.Scan(&jobID, &a.Favorite, &a.Rating, &a.Notes, &a.UpdatedAt); err != nil {
+			return fmt.Errorf("scan annotation: %w", err)
+		}
+		byJob[jobID] = &a
+	}
+
+	for _, item := range items {
+		if item.Job != nil {
+			item.Annotation = byJob[item.Job.ID]
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,113 @@
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"video-archiver/internal/domain"
+	"video-archiver/internal/testutil"
+)
+
+func TestJobRepository_Annotations(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+	repo := NewJobRepository(db)
+	search := NewSearchRepository(db)
+	for _, id := range []string{"loved", "liked", "plain"} {
+		createCompletedVideo(t, repo, id)
+	}
+
+	now := time.Now()
+	if err := repo.SetAnnotation("loved", &domain.Annotation{Favorite: true, Rating: 5, Notes: "The *zeppelin* scene", UpdatedAt: now}); err != nil {
+		t.Fatalf("SetAnnotation() error = %v", err)
+	}
+	repo.SetAnnotation("liked", &domain.Annotation{Rating: 3, UpdatedAt: now})
+
+	got, err := repo.GetAnnotation("loved")
+	if err != nil || got == nil || !got.Favorite || got.Rating != 5 {
+		t.Fatalf("GetAnnotation() = %+v, %v", got, err)
+	}
+	if got, err := repo.GetAnnotation("plain"); err != nil || got != nil {
+		t.Errorf("GetAnnotation(unannotated) = %+v, %v; want nil", got, err)
+	}
+
+	// Notes are searchable.
+	results, err := search.Search("zeppelin", "", 10)
+	if err != nil || len(results) != 1 || results[0].ID != "loved" {
+		t.Errorf("Search(notes) = %+v, %v", results, err)
+	}
+
+	items, _, err := repo.GetMetadataByType("videos", domain.MetadataQuery{SortBy: "rating", Order: "desc"})
+	if err != nil || len(items) != 3 {
+		t.Fatalf("GetMetadataByType() = %d items, %v", len(items), err)
+	}
+	if items[0].Job.ID != "loved" || items[1].Job.ID != "liked" || items[2].Annotation != nil ||
+		items[0].Annotation == nil || items[0].Annotation.Notes != "The *zeppelin* scene" {
+		t.Errorf("sorted by rating: %s, %s, %s", items[0].Job.ID, items[1].Job.ID, items[2].Job.ID)
+	}
+
+	for query, want := range map[string]int{
+		"favorite:yes":      1,
+		"favorite:no":       2,
+		"rating>=3":         2,
+		"rating:0":          1,
+		"notes:zeppelin":    1,
+		"-notes:zeppelin":   2,
+		"favorite:no tag:x": 0,
+	} {
+		filters, err := domain.ParseLibraryQuery(query)
+		if err != nil {
+			t.Fatalf("ParseLibraryQuery(%q) error = %v", query, err)
+		}
+		if _, total, err := repo.GetMetadataByType("videos", domain.MetadataQuery{Filters: filters}); err != nil || total != want {
+			t.Errorf("%s: total = %d, %v; want %d", query, total, err, want)
+		}
+	}
+
+	// Clearing every field removes the annotation and its notes from search.
+	if err := repo.SetAnnotation("loved", &domain.Annotation{UpdatedAt: now}); err != nil {
+		t.Fatalf("SetAnnotation(empty) error = %v", err)
+	}
+	if got, _ := repo.GetAnnotation("loved"); got != nil {
+		t.Errorf("annotation after clearing = %+v, want nil", got)
+	}
+	if results, _ := search.Search("zeppelin", "", 10); len(results) != 0 {
+		t.Errorf("Search() after clearing notes = %+v", results)
+	}
+
+	repo.SetAnnotation("liked", &domain.Annotation{Notes: "bye", UpdatedAt: now})
+	if err := repo.DeleteJob("liked"); err != nil {
+		t.Fatalf("DeleteJob() error = %v", err)
+	}
+	if got, _ := repo.GetAnnotation("liked"); got != nil {
+		t.Errorf("annotation of deleted job = %+v", got)
+	}
+}
+
+func TestCollectionRepository_Annotations(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+	collections := NewCollectionRepository(db)
+	search := NewSearchRepository(db)
+
+	now := time.Now()
+	collections.Create(&domain.Collection{ID: "col", Name: "Mix", CreatedAt: now, UpdatedAt: now})
+	if err := collections.SetAnnotation("col", &domain.Annotation{Favorite: true, Notes: "road trip", UpdatedAt: now}); err != nil {
+		t.Fatalf("SetAnnotation() error = %v", err)
+	}
+
+	c, err := collections.GetByID("col")
+	if err != nil || c.Annotation == nil || !c.Annotation.Favorite || c.Annotation.Notes != "road trip" {
+		t.Fatalf("GetByID() annotation = %+v, %v", c.Annotation, err)
+	}
+	if results, err := search.Search("road", domain.SearchKindCollection, 10); err != nil || len(results) != 1 {
+		t.Errorf("Search(collection notes) = %+v, %v", results, err)
+	}
+
+	if err := collections.Delete("col"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if got, _ := collections.GetAnnotation("col"); got != nil {
+		t.Errorf("annotation of deleted collection = %+v", got)
+	}
+}
@@ -0,0 +1,123 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"video-archiver/internal/domain"
+)
+
+type AutomationRepository struct {
+	db *sql.DB
+}
+
+func NewAutomationRepository(db *sql.DB) *AutomationRepository {
+	return &AutomationRepository{db: db}
+}
+
+func (r *AutomationRepository) Create(rule *domain.AutomationRule) error {
+	matchJSON, workflowJSON, err := marshalAutomationRule(rule)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(`
+        INSERT INTO automation_rules (id, name, enabled, match_json, workflow_json, created_at, updated_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		rule.ID, rule.Name, rule.Enabled, matchJSON, workflowJSON, rule.CreatedAt, rule.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("create automation rule: %w", err)
+	}
+	return nil
+}
+
+func (r *AutomationRepository) Update(rule *domain.AutomationRule) error {
+	matchJSON, workflowJSON, err := marshalAutomationRule(rule)
+	if err != nil {
+		return err
+	}
+	rule.UpdatedAt = time.Now()
+	res, err := r.db.Exec(`
+        UPDATE automation_rules
+        SET name = ?, enabled = ?, match_json = ?, workflow_json = ?, updated_at = ?
+        WHERE id = ?`,
+		rule.Name, rule.Enabled, matchJSON, workflowJSON, rule.UpdatedAt, rule.ID)
+	if err != nil {
+		return fmt.Errorf("update automation rule: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("automation rule not found")
+	}
+	return nil
+}
+
+// Delete removes a rule. Tools jobs it already submitted keep its ID.
+func (r *AutomationRepository) Delete(id string) error {
+	if _, err := r.db.Exec(`DELETE FROM automation_rules WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("delete automation rule: %w", err)
+	}
+	return nil
+}
+
+const automationRuleSelect = `
+    SELECT id, name, enabled, match_json, workflow_json, created_at, updated_at
+    FROM automation_rules`
+
+func scanAutomationRule(row interface{ Scan(...any) error }) (*domain.AutomationRule, error) {
+	rule := &domain.AutomationRule{}
+	var matchJSON, workflowJSON string
+	err := row.Scan(&rule.ID, &rule.Name, &rule.Enabled, &matchJSON, &workflowJSON, &rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(matchJSON), &rule.Match); err != nil {
+		return nil, fmt.Errorf("unmarshal automation match: %w", err)
+	}
+	if err := json.Unmarshal([]byte(workflowJSON), &rule.Workflow); err != nil {
+		return nil, fmt.Errorf("unmarshal automation workflow: %w", err)
+	}
+	return rule, nil
+}
+
+func (r *AutomationRepository) GetByID(id string) (*domain.AutomationRule, error) {
+	rule, err := scanAutomationRule(r.db.QueryRow(automationRuleSelect+` WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get automation rule by id: %w", err)
+	}
+	return rule, nil
+}
+
+func (r *AutomationRepository) List() ([]*domain.AutomationRule, error) {
+	rows, err := r.db.Query(automationRuleSelect + ` ORDER BY created_at ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("list automation rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []*domain.AutomationRule{}
+	for rows.Next() {
+		rule, err := scanAutomationRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan automation rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+func marshalAutomationRule(rule *domain.AutomationRule) (string, string, error) {
+	matchJSON, err := json.Marshal(rule.Match)
+	if err != nil {
+		return "", "", fmt.Errorf("marshal automation match: %w", err)
+	}
+	workflowJSON, err := json.Marshal(rule.Workflow)
+	if err != nil {
+		return "", "", fmt.Errorf("marshal automation workflow: %w", err)
+	}
+	return string(matchJSON), string(workflowJSON), nil
+}
@@ -0,0 +1,67 @@
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"video-archiver/internal/domain"
+	"video-archiver/internal/testutil"
+)
+
+func TestAutomationRepository(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+	repo := NewAutomationRepository(db)
+
+	notSafe := false
+	now := time.Now()
+	rule := &domain.AutomationRule{
+		ID:      "rule-1",
+		Name:    "Transcode for browsers",
+		Enabled: true,
+		Match:   domain.AutomationMatch{BrowserSafe: &notSafe, Tags: []string{"music"}},
+		Workflow: domain.WorkflowParameters{
+			Steps: []domain.WorkflowStep{{
+				Operation:  domain.OpTypeConvert,
+				Parameters: map[string]any{"output_format": "mp4"},
+			}},
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := repo.Create(rule); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	got, err := repo.GetByID(rule.ID)
+	if err != nil || got == nil {
+		t.Fatalf("GetByID() = %v, %v", got, err)
+	}
+	if got.Name != rule.Name || !got.Enabled || got.Match.BrowserSafe == nil || *got.Match.BrowserSafe ||
+		len(got.Match.Tags) != 1 || len(got.Workflow.Steps) != 1 || got.Workflow.Steps[0].Operation != domain.OpTypeConvert {
+		t.Errorf("GetByID() = %+v", got)
+	}
+	if missing, err := repo.GetByID("nope"); err != nil || missing != nil {
+		t.Errorf("GetByID(missing) = %v, %v; want nil", missing, err)
+	}
+
+	got.Enabled = false
+	got.Match = domain.AutomationMatch{MediaType: domain.MediaTypeAudio}
+	if err := repo.Update(got); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	list, err := repo.List()
+	if err != nil || len(list) != 1 || list[0].Enabled || list[0].Match.MediaType != domain.MediaTypeAudio || list[0].Match.BrowserSafe != nil {
+		t.Errorf("List() = %+v, %v", list, err)
+	}
+	if err := repo.Update(&domain.AutomationRule{ID: "nope"}); err == nil {
+		t.Error("Update(missing) succeeded")
+	}
+
+	if err := repo.Delete(rule.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if list, _ := repo.List(); len(list) != 0 {
+		t.Errorf("List() after delete = %+v", list)
+	}
+}
@@ -0,0 +1,135 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"video-archiver/internal/domain"
+)
+
+type BookmarkRepository struct {
+	db *sql.DB
+}
+
+func NewBookmarkRepository(db *sql.DB) *BookmarkRepository {
+	return &BookmarkRepository{db: db}
+}
+
+func (r *BookmarkRepository) Create(bookmark *domain.Bookmark) error {
+	tagsJSON, err := json.Marshal(bookmarkTags(bookmark))
+	if err != nil {
+		return fmt.Errorf("marshal bookmark tags: %w", err)
+	}
+	_, err = r.db.Exec(`
+        INSERT INTO bookmarks (id, job_id, start_seconds, end_seconds, label, tags, created_at, updated_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		bookmark.ID, bookmark.JobID, bookmark.Start, bookmark.End, bookmark.Label, string(tagsJSON),
+		bookmark.CreatedAt, bookmark.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("create bookmark: %w", err)
+	}
+	return nil
+}
+
+func (r *BookmarkRepository) Update(bookmark *domain.Bookmark) error {
+	tagsJSON, err := json.Marshal(bookmarkTags(bookmark))
+	if err != nil {
+		return fmt.Errorf("marshal bookmark tags: %w", err)
+	}
+	bookmark.UpdatedAt = time.Now()
+	res, err := r.db.Exec(`
+        UPDATE bookmarks
+        SET start_seconds = ?, end_seconds = ?, label = ?, tags = ?, updated_at = ?
+        WHERE id = ?`,
+		bookmark.Start, bookmark.End, bookmark.Label, string(tagsJSON), bookmark.UpdatedAt, bookmark.ID)
+	if err != nil {
+		return fmt.Errorf("update bookmark: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("bookmark not found")
+	}
+	return nil
+}
+
+func (r *BookmarkRepository) Delete(id string) error {
+	if _, err := r.db.Exec(`DELETE FROM bookmarks WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("delete bookmark: %w", err)
+	}
+	return nil
+}
+
+const bookmarkSelect = `
+    SELECT id, job_id, start_seconds, end_seconds, label, tags, created_at, updated_at
+    FROM bookmarks`
+
+func scanBookmark(row interface{ Scan(...any) error }) (*domain.Bookmark, error) {
+	b := &domain.Bookmark{}
+	var end sql.NullFloat64
+	var tagsJSON string
+	err := row.Scan(&b.ID, &b.JobID, &b.Start, &end, &b.Label, &tagsJSON, &b.CreatedAt, &b.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if end.Valid {
+		b.End = &end.Float64
+	}
+	if err := json.Unmarshal([]byte(tagsJSON), &b.Tags); err != nil {
+		return nil, fmt.Errorf("unmarshal bookmark tags: %w", err)
+	}
+	return b, nil
+}
+
+func (r *BookmarkRepository) GetByID(id string) (*domain.Bookmark, error) {
+	b, err := scanBookmark(r.db.QueryRow(bookmarkSelect+` WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get bookmark by id: %w", err)
+	}
+	return b, nil
+}
+
+func (r *BookmarkRepository) ListForVideo(jobID string) ([]*domain.Bookmark, error) {
+	return r.queryBookmarks(bookmarkSelect+` WHERE job_id = ? ORDER BY start_seconds ASC, created_at ASC`, jobID)
+}
+
+// List matches tag case-insensitively, like job tags.
+func (r *BookmarkRepository) List(tag string) ([]*domain.Bookmark, error) {
+	if tag == "" {
+		return r.queryBookmarks(bookmarkSelect + ` ORDER BY created_at DESC`)
+	}
+	return r.queryBookmarks(bookmarkSelect+`
+        WHERE EXISTS (SELECT 1 FROM json_each(bookmarks.tags) t WHERE t.value = ? COLLATE NOCASE)
+        ORDER BY created_at DESC`, tag)
+}
+
+func (r *BookmarkRepository) queryBookmarks(query string, args ...any) ([]*domain.Bookmark, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list bookmarks: %w", err)
+	}
+	defer rows.Close()
+
+	bookmarks := []*domain.Bookmark{}
+	for rows.Next() {
+		b, err := scanBookmark(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan bookmark: %w", err)
+		}
+		bookmarks = append(bookmarks, b)
+	}
+	return bookmarks, rows.Err()
+}
+
+// bookmarkTags returns the tags to store; nil is stored as [] so the column
+// always holds a JSON array.
+func bookmarkTags(bookmark *domain.Bookmark) []string {
+	if bookmark.Tags == nil {
+		return []string{}
+	}
+	return bookmark.Tags
+}
@@ -0,0 +1,81 @@
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"video-archiver/internal/domain"
+	"video-archiver/internal/testutil"
+)
+
+func TestBookmarkRepository(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+	jobs := NewJobRepository(db)
+	repo := NewBookmarkRepository(db)
+	createCompletedVideo(t, jobs, "talk")
+	createCompletedVideo(t, jobs, "stream")
+
+	now := time.Now()
+	end := 95.5
+	for _, b := range []*domain.Bookmark{
+		{ID: "b-late", JobID: "talk", Start: 600, Label: "Q&A", Tags: []string{"Questions"}, CreatedAt: now, UpdatedAt: now},
+		{ID: "b-early", JobID: "talk", Start: 80, End: &end, Label: "Demo", Tags: []string{"demo", "highlight"},
+			CreatedAt: now.Add(time.Second), UpdatedAt: now},
+		{ID: "b-stream", JobID: "stream", Start: 10, CreatedAt: now.Add(2 * time.Second), UpdatedAt: now},
+	} {
+		if err := repo.Create(b); err != nil {
+			t.Fatalf("Create(%s) error = %v", b.ID, err)
+		}
+	}
+
+	got, err := repo.GetByID("b-early")
+	if err != nil || got == nil {
+		t.Fatalf("GetByID() = %v, %v", got, err)
+	}
+	if got.End == nil || *got.End != end || got.Label != "Demo" || len(got.Tags) != 2 {
+		t.Errorf("GetByID() = %+v", got)
+	}
+	if got, err := repo.GetByID("b-stream"); err != nil || got.End != nil || got.Tags == nil {
+		t.Errorf("GetByID(no end, no tags) = %+v, %v; want nil end and empty tags", got, err)
+	}
+	if missing, err := repo.GetByID("nope"); err != nil || missing != nil {
+		t.Errorf("GetByID(missing) = %v, %v; want nil", missing, err)
+	}
+
+	forTalk, err := repo.ListForVideo("talk")
+	if err != nil || len(forTalk) != 2 || forTalk[0].ID != "b-early" || forTalk[1].ID != "b-late" {
+		t.Errorf("ListForVideo() = %+v, %v; want b-early, b-late", forTalk, err)
+	}
+
+	all, err := repo.List("")
+	if err != nil || len(all) != 3 || all[0].ID != "b-stream" {
+		t.Errorf("List() = %+v, %v; want 3, newest first", all, err)
+	}
+	tagged, err := repo.List("HIGHLIGHT")
+	if err != nil || len(tagged) != 1 || tagged[0].ID != "b-early" {
+		t.Errorf("List(tag) = %+v, %v; want b-early", tagged, err)
+	}
+
+	got.End = nil
+	got.Tags = []string{"highlight"}
+	if err := repo.Update(got); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if got, _ := repo.GetByID("b-early"); got.End != nil || len(got.Tags) != 1 {
+		t.Errorf("after Update() = %+v", got)
+	}
+	if err := repo.Update(&domain.Bookmark{ID: "nope"}); err == nil {
+		t.Error("Update(missing) succeeded")
+	}
+
+	if err := repo.Delete("b-late"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := jobs.DeleteJob("stream"); err != nil {
+		t.Fatalf("DeleteJob() error = %v", err)
+	}
+	if all, _ := repo.List(""); len(all) != 1 || all[0].ID != "b-early" {
+		t.Errorf("after deletes List() = %+v; want only b-early", all)
+	}
+}
@@ -0,0 +1,150 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"video-archiver/internal/domain"
+)
+
+type BulkRepository struct {
+	db *sql.DB
+}
+
+func NewBulkRepository(db *sql.DB) *BulkRepository {
+	return &BulkRepository{db: db}
+}
+
+const bulkOperationColumns = `id, action, parameters_json, status, total, processed, succeeded, skipped, failed,
+        started_at, finished_at`
+
+func (r *BulkRepository) Create(operation *domain.BulkOperation) error {
+	if operation.StartedAt.IsZero() {
+		operation.StartedAt = time.Now()
+	}
+	params, err := json.Marshal(operation.Parameters)
+	if err != nil {
+		return fmt.Errorf("marshal bulk parameters: %w", err)
+	}
+	result, err := r.db.Exec(`
+        INSERT INTO bulk_operations (action, parameters_json, status, total, processed, succeeded, skipped, failed,
+                                     started_at, finished_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		operation.Action, string(params), operation.Status, operation.Total, operation.Processed,
+		operation.Succeeded, operation.Skipped, operation.Failed, operation.StartedAt, operation.FinishedAt)
+	if err != nil {
+		return fmt.Errorf("insert bulk operation: %w", err)
+	}
+	if operation.ID, err = result.LastInsertId(); err != nil {
+		return fmt.Errorf("bulk operation id: %w", err)
+	}
+	return nil
+}
+
+// Update stores an operation's status, counters and finish time.
+func (r *BulkRepository) Update(operation *domain.BulkOperation) error {
+	_, err := r.db.Exec(`
+        UPDATE bulk_operations
+        SET status = ?, total = ?, processed = ?, succeeded = ?, skipped = ?, failed = ?, finished_at = ?
+        WHERE id = ?`,
+		operation.Status, operation.Total, operation.Processed, operation.Succeeded, operation.Skipped,
+		operation.Failed, operation.FinishedAt, operation.ID)
+	if err != nil {
+		return fmt.Errorf("update bulk operation: %w", err)
+	}
+	return nil
+}
+
+// AddItem records the result of a bulk operation for one item.
+func (r *BulkRepository) AddItem(item *domain.BulkItem) error {
+	if item.CreatedAt.IsZero() {
+		item.CreatedAt = time.Now()
+	}
+	result, err := r.db.Exec(`
+        INSERT INTO bulk_items (operation_id, job_id, title, outcome, detail, error, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		item.OperationID, item.JobID, item.Title, item.Outcome, item.Detail, item.Error, item.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("insert bulk item: %w", err)
+	}
+	if item.ID, err = result.LastInsertId(); err != nil {
+		return fmt.Errorf("bulk item id: %w", err)
+	}
+	return nil
+}
+
+// GetByID returns an operation with its items in the order they were
+// processed.
+func (r *BulkRepository) GetByID(id int64) (*domain.BulkOperation, error) {
+	operation, err := scanBulkOperation(r.db.QueryRow(`SELECT `+bulkOperationColumns+` FROM bulk_operations WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(`
+        SELECT id, operation_id, job_id, title, outcome, detail, error, created_at
+        FROM bulk_items
+        WHERE operation_id = ?
+        ORDER BY id`, id)
+	if err != nil {
+		return nil, fmt.Errorf("query bulk items: %w", err)
+	}
+	defer rows.Close()
+
+	operation.Items = []domain.BulkItem{}
+	for rows.Next() {
+		var item domain.BulkItem
+		if err := rows.Scan(&item.ID, &item.OperationID, &item.JobID, &item.Title, &item.Outcome,
+			&item.Detail, &item.Error, &item.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan bulk item: %w", err)
+		}
+		operation.Items = append(operation.Items, item)
+	}
+	return operation, rows.Err()
+}
+
+// List returns up to limit operations without their items, newest first.
+func (r *BulkRepository) List(limit int) ([]domain.BulkOperation, error) {
+	rows, err := r.db.Query(`SELECT `+bulkOperationColumns+` FROM bulk_operations ORDER BY id DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query bulk operations: %w", err)
+	}
+	defer rows.Close()
+
+	operations := []domain.BulkOperation{}
+	for rows.Next() {
+		operation, err := scanBulkOperation(rows)
+		if err != nil {
+			return nil, err
+		}
+		operations = append(operations, *operation)
+	}
+	return operations, rows.Err()
+}
+
+func scanBulkOperation(row rowScanner) (*domain.BulkOperation, error) {
+	var operation domain.BulkOperation
+	var params string
+	var finishedAt sql.NullTime
+	if err := row.Scan(&operation.ID, &operation.Action, &params, &operation.Status, &operation.Total,
+		&operation.Processed, &operation.Succeeded, &operation.Skipped, &operation.Failed,
+		&operation.StartedAt, &finishedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("scan bulk operation: %w", err)
+	}
+	if err := json.Unmarshal([]byte(params), &operation.Parameters); err != nil {
+		return nil, fmt.Errorf("unmarshal bulk parameters: %w", err)
+	}
+	if finishedAt.Valid {
+		operation.FinishedAt = &finishedAt.Time
+	}
+	return &operation, nil
+}
@@ -0,0 +1,69 @@
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"video-archiver/internal/domain"
+	"video-archiver/internal/testutil"
+)
+
+func TestBulkRepository(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+	repo := NewBulkRepository(db)
+
+	operation := &domain.BulkOperation{
+		Action:     domain.BulkActionAddTags,
+		Parameters: domain.BulkParameters{Tags: []string{"music"}},
+		Status:     domain.BulkStatusRunning,
+		Total:      2,
+	}
+	if err := repo.Create(operation); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if operation.ID == 0 || operation.StartedAt.IsZero() {
+		t.Errorf("created operation = %+v", operation)
+	}
+
+	for _, item := range []domain.BulkItem{
+		{OperationID: operation.ID, JobID: "a", Title: "A", Outcome: domain.BulkOutcomeSucceeded},
+		{OperationID: operation.ID, JobID: "b", Outcome: domain.BulkOutcomeFailed, Error: "job not found"},
+	} {
+		if err := repo.AddItem(&item); err != nil {
+			t.Fatalf("AddItem() error = %v", err)
+		}
+	}
+
+	finished := time.Now()
+	operation.Status = domain.BulkStatusComplete
+	operation.Processed, operation.Succeeded, operation.Failed = 2, 1, 1
+	operation.FinishedAt = &finished
+	if err := repo.Update(operation); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	got, err := repo.GetByID(operation.ID)
+	if err != nil || got == nil {
+		t.Fatalf("GetByID() = %v, %v", got, err)
+	}
+	if got.Status != domain.BulkStatusComplete || got.Processed != 2 || got.Failed != 1 || got.FinishedAt == nil ||
+		len(got.Parameters.Tags) != 1 || got.Parameters.Tags[0] != "music" {
+		t.Errorf("GetByID() = %+v", got)
+	}
+	if len(got.Items) != 2 || got.Items[0].JobID != "a" || got.Items[1].Error != "job not found" {
+		t.Errorf("items = %+v", got.Items)
+	}
+	if missing, err := repo.GetByID(999); err != nil || missing != nil {
+		t.Errorf("GetByID(missing) = %v, %v; want nil", missing, err)
+	}
+
+	second := &domain.BulkOperation{Action: domain.BulkActionDelete, Status: domain.BulkStatusRunning}
+	if err := repo.Create(second); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	list, err := repo.List(10)
+	if err != nil || len(list) != 2 || list[0].ID != second.ID || list[1].Items != nil {
+		t.Errorf("List() = %+v, %v; want newest first without items", list, err)
+	}
+}
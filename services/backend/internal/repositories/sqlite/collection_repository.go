@@ -21,30 +21,30 @@ func NewCollectionRepository(db *sql.DB) *CollectionRepository {
 
 func (r *CollectionRepository) Create(collection *domain.Collection) error {
 	_, err := r.db.Exec(`
-        INSERT INTO collections (id, name, description, created_at, updated_at)
-        VALUES (?, ?, ?, ?, ?)`,
-		collection.ID, collection.Name, collection.Description,
+        INSERT INTO collections (id, name, description, query, created_at, updated_at)
+        VALUES (?, ?, ?, ?, ?, ?)`,
+		collection.ID, collection.Name, collection.Description, collection.Query,
 		collection.CreatedAt, collection.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("create collection: %w", err)
 	}
-	return nil
+	return reindexCollectionSearch(r.db, collection.ID)
 }
 
 func (r *CollectionRepository) Update(collection *domain.Collection) error {
 	collection.UpdatedAt = time.Now()
 	res, err := r.db.Exec(`
         UPDATE collections
-        SET name = ?, description = ?, updated_at = ?
+        SET name = ?, description = ?, query = ?, updated_at = ?
         WHERE id = ?`,
-		collection.Name, collection.Description, collection.UpdatedAt, collection.ID)
+		collection.Name, collection.Description, collection.Query, collection.UpdatedAt, collection.ID)
 	if err != nil {
 		return fmt.Errorf("update collection: %w", err)
 	}
 	if n, err := res.RowsAffected(); err == nil && n == 0 {
 		return fmt.Errorf("collection not found")
 	}
-	return nil
+	return reindexCollectionSearch(r.db, collection.ID)
 }
 
 // Delete removes a collection and its memberships. Member videos themselves
@@ -59,17 +59,28 @@ func (r *CollectionRepository) Delete(id string) error {
 	if _, err := tx.Exec(`DELETE FROM collection_videos WHERE collection_id = ?`, id); err != nil {
 		return fmt.Errorf("delete collection memberships: %w", err)
 	}
+	if _, err := tx.Exec(`DELETE FROM annotations WHERE item_id = ? AND kind = 'collection'`, id); err != nil {
+		return fmt.Errorf("delete collection annotation: %w", err)
+	}
 	if _, err := tx.Exec(`DELETE FROM collections WHERE id = ?`, id); err != nil {
 		return fmt.Errorf("delete collection: %w", err)
 	}
+	if err := reindexCollectionSearch(tx, id); err != nil {
+		return err
+	}
 	return tx.Commit()
 }
 
-// collectionSelect returns collections enriched with their member count and
-// the thumbnail of their first member, so listings need no extra queries.
+// collectionSelect returns collections enriched with their member count, the
+// number of those watched, the thumbnail of their first member and their
+// annotation, so listings need no extra queries.
+// Smart collections have no stored members; see smartSummary.
 const collectionSelect = `
-    SELECT c.id, c.name, c.description, c.created_at, c.updated_at,
+    SELECT c.id, c.name, c.description, c.query, c.created_at, c.updated_at,
            (SELECT COUNT(*) FROM collection_videos cv WHERE cv.collection_id = c.id) AS video_count,
+           (SELECT COUNT(*) FROM collection_videos cv
+            JOIN watch_progress wp ON wp.job_id = cv.video_job_id
+            WHERE cv.collection_id = c.id AND wp.completed) AS watched_count,
            COALESCE((
                SELECT json_extract(v.metadata_json, '$.thumbnail')
                FROM collection_videos cv
@@ -77,16 +88,32 @@ const collectionSelect = `
                WHERE cv.collection_id = c.id
                ORDER BY cv.position ASC, cv.rowid ASC
                LIMIT 1
-           ), '') AS thumbnail
-    FROM collections c`
+           ), '') AS thumbnail,
+           a.favorite, a.rating, a.notes, a.updated_at
+    FROM collections c
+    LEFT JOIN annotations a ON a.item_id = c.id AND a.kind = 'collection'`
 
 func scanCollection(row interface{ Scan(...any) error }) (*domain.Collection, error) {
 	c := &domain.Collection{}
-	err := row.Scan(&c.ID, &c.Name, &c.Description, &c.CreatedAt, &c.UpdatedAt,
-		&c.VideoCount, &c.Thumbnail)
+	var watched int
+	var favorite sql.NullBool
+	var rating sql.NullInt64
+	var notes sql.NullString
+	var annotatedAt sql.NullTime
+	err := row.Scan(&c.ID, &c.Name, &c.Description, &c.Query, &c.CreatedAt, &c.UpdatedAt,
+		&c.VideoCount, &watched, &c.Thumbnail, &favorite, &rating, &notes, &annotatedAt)
 	if err != nil {
 		return nil, err
 	}
+	c.Completion = domain.NewWatchCompletion(watched, c.VideoCount)
+	if annotatedAt.Valid {
+		c.Annotation = &domain.Annotation{
+			Favorite:  favorite.Bool,
+			Rating:    int(rating.Int64),
+			Notes:     notes.String,
+			UpdatedAt: annotatedAt.Time,
+		}
+	}
 	return c, nil
 }
 
@@ -98,6 +125,9 @@ func (r *CollectionRepository) GetByID(id string) (*domain.Collection, error) {
 	if err != nil {
 		return nil, fmt.Errorf("get collection by id: %w", err)
 	}
+	if err := r.smartSummary(c); err != nil {
+		return nil, err
+	}
 	return c, nil
 }
 
@@ -116,7 +146,60 @@ func (r *CollectionRepository) List() ([]*domain.Collection, error) {
 		}
 		collections = append(collections, c)
 	}
-	return collections, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	for _, c := range collections {
+		if err := r.smartSummary(c); err != nil {
+			return nil, err
+		}
+	}
+	return collections, nil
+}
+
+// smartQuery is the listing query evaluating a smart collection: its videos
+// in upload order, oldest first, like a series.
+func smartQuery(c *domain.Collection, page, limit int) (domain.MetadataQuery, error) {
+	filters, err := domain.ParseCollectionQuery(c.Query)
+	if err != nil {
+		return domain.MetadataQuery{}, fmt.Errorf("collection %s query: %w", c.ID, err)
+	}
+	return domain.MetadataQuery{Page: page, Limit: limit, SortBy: "upload_date", Order: "asc", Filters: filters}, nil
+}
+
+// smartSummary fills in the member count, completion and thumbnail of a
+// smart collection by evaluating its query. A query that no longer parses
+// matches nothing.
+func (r *CollectionRepository) smartSummary(c *domain.Collection) error {
+	if !c.IsSmart() {
+		return nil
+	}
+	opts, err := smartQuery(c, 1, 1)
+	if err != nil {
+		log.WithError(err).Warn("Invalid smart collection query")
+		return nil
+	}
+	jobs := &JobRepository{db: r.db}
+	items, total, err := jobs.GetMetadataByType("videos", opts)
+	if err != nil {
+		return fmt.Errorf("evaluate collection %s: %w", c.ID, err)
+	}
+	c.VideoCount = total
+	if len(items) > 0 {
+		if video, ok := items[0].Metadata.(*domain.VideoMetadata); ok {
+			c.Thumbnail = video.Thumbnail
+		}
+	}
+
+	opts.Filters = append(opts.Filters, domain.QueryFilter{Field: domain.QueryFieldWatch, Op: domain.QueryOpMatch, Text: string(domain.WatchStateWatched)})
+	_, watched, err := jobs.GetMetadataByType("videos", opts)
+	if err != nil {
+		return fmt.Errorf("evaluate collection %s completion: %w", c.ID, err)
+	}
+	c.Completion = domain.NewWatchCompletion(watched, total)
+	return nil
 }
 
 // AddVideos appends videos to the end of a collection. IDs that are already
@@ -131,6 +214,9 @@ func (r *CollectionRepository) AddVideos(collectionID string, videoJobIDs []stri
 	if collection == nil {
 		return fmt.Errorf("collection not found")
 	}
+	if collection.IsSmart() {
+		return domain.ErrSmartCollection
+	}
 
 	tx, err := r.db.Begin()
 	if err != nil {
@@ -175,7 +261,16 @@ func (r *CollectionRepository) AddVideos(collectionID string, videoJobIDs []stri
 }
 
 func (r *CollectionRepository) RemoveVideo(collectionID string, videoJobID string) error {
-	_, err := r.db.Exec(`
+	var query string
+	err := r.db.QueryRow(`SELECT query FROM collections WHERE id = ?`, collectionID).Scan(&query)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("get collection query: %w", err)
+	}
+	if query != "" {
+		return domain.ErrSmartCollection
+	}
+
+	_, err = r.db.Exec(`
         DELETE FROM collection_videos
         WHERE collection_id = ? AND video_job_id = ?`, collectionID, videoJobID)
 	if err != nil {
@@ -188,7 +283,131 @@ func (r *CollectionRepository) RemoveVideo(collectionID string, videoJobID strin
 	return nil
 }
 
+// memberIDs returns a manual collection's members in order, or
+// ErrSmartCollection for a smart one.
+func memberIDs(tx *sql.Tx, collectionID string) ([]string, error) {
+	var query string
+	err := tx.QueryRow(`SELECT query FROM collections WHERE id = ?`, collectionID).Scan(&query)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("collection not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get collection query: %w", err)
+	}
+	if query != "" {
+		return nil, domain.ErrSmartCollection
+	}
+
+	rows, err := tx.Query(`
+        SELECT video_job_id FROM collection_videos
+        WHERE collection_id = ?
+        ORDER BY position ASC, rowid ASC`, collectionID)
+	if err != nil {
+		return nil, fmt.Errorf("list collection members: %w", err)
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan collection member: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// writeOrder renumbers the members 1..n in the given order.
+func writeOrder(tx *sql.Tx, collectionID string, videoJobIDs []string) error {
+	for i, id := range videoJobIDs {
+		if _, err := tx.Exec(`
+            UPDATE collection_videos SET position = ?
+            WHERE collection_id = ? AND video_job_id = ?`, i+1, collectionID, id); err != nil {
+			return fmt.Errorf("set position of %s: %w", id, err)
+		}
+	}
+	if _, err := tx.Exec(`UPDATE collections SET updated_at = ? WHERE id = ?`,
+		time.Now(), collectionID); err != nil {
+		return fmt.Errorf("touch collection: %w", err)
+	}
+	return nil
+}
+
+func (r *CollectionRepository) SetOrder(collectionID string, videoJobIDs []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin set order: %w", err)
+	}
+	defer tx.Rollback()
+
+	current, err := memberIDs(tx, collectionID)
+	if err != nil {
+		return err
+	}
+	if len(videoJobIDs) != len(current) {
+		return domain.ErrCollectionOrder
+	}
+	members := make(map[string]bool, len(current))
+	for _, id := range current {
+		members[id] = true
+	}
+	for _, id := range videoJobIDs {
+		if !members[id] {
+			return domain.ErrCollectionOrder
+		}
+		// Each member may appear once.
+		delete(members, id)
+	}
+
+	if err := writeOrder(tx, collectionID, videoJobIDs); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *CollectionRepository) MoveVideo(collectionID string, videoJobID string, position int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin move video: %w", err)
+	}
+	defer tx.Rollback()
+
+	ids, err := memberIDs(tx, collectionID)
+	if err != nil {
+		return err
+	}
+	from := -1
+	for i, id := range ids {
+		if id == videoJobID {
+			from = i
+			break
+		}
+	}
+	if from < 0 {
+		return domain.ErrNotInCollection
+	}
+
+	to := min(max(position, 1), len(ids)) - 1
+	ids = append(ids[:from], ids[from+1:]...)
+	ids = append(ids[:to], append([]string{videoJobID}, ids[to:]...)...)
+
+	if err := writeOrder(tx, collectionID, ids); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
 func (r *CollectionRepository) GetVideos(collectionID string) ([]*domain.JobWithMetadata, error) {
+	var query string
+	err := r.db.QueryRow(`SELECT query FROM collections WHERE id = ?`, collectionID).Scan(&query)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("get collection query: %w", err)
+	}
+	if query != "" {
+		return r.smartVideos(&domain.Collection{ID: collectionID, Query: query})
+	}
+
 	rows, err := r.db.Query(`
         SELECT j.job_id, j.url, j.status, j.progress, j.warnings, j.file_path,
                j.created_at, j.updated_at, v.metadata_json
@@ -233,7 +452,19 @@ func (r *CollectionRepository) GetVideos(collectionID string) ([]*domain.JobWith
 			Metadata: &videoMetadata,
 		})
 	}
-	return result, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	jobs := &JobRepository{db: r.db}
+	if err := jobs.attachWatch(result); err != nil {
+		log.WithError(err).Warn("Failed to attach watch progress to collection videos")
+	}
+	if err := jobs.attachAnnotations(result); err != nil {
+		log.WithError(err).Warn("Failed to attach annotations to collection videos")
+	}
+	return result, nil
 }
 
 func (r *CollectionRepository) ListForVideo(videoJobID string) ([]string, error) {
@@ -255,3 +486,25 @@ func (r *CollectionRepository) ListForVideo(videoJobID string) ([]string, error)
 	}
 	return ids, rows.Err()
 }
+
+// smartVideos evaluates a smart collection's query, page by page within the
+// listing's limit.
+func (r *CollectionRepository) smartVideos(c *domain.Collection) ([]*domain.JobWithMetadata, error) {
+	const pageSize = 100
+	jobs := &JobRepository{db: r.db}
+	result := []*domain.JobWithMetadata{}
+	for page := 1; ; page++ {
+		opts, err := smartQuery(c, page, pageSize)
+		if err != nil {
+			return nil, err
+		}
+		items, total, err := jobs.GetMetadataByType("videos", opts)
+		if err != nil {
+			return nil, fmt.Errorf("evaluate collection %s: %w", c.ID, err)
+		}
+		result = append(result, items...)
+		if len(items) < pageSize || len(result) >= total {
+			return result, nil
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package sqlite
 
 import (
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -236,3 +238,132 @@ func TestJobRepository_DeleteJobRemovesCollectionMemberships(t *testing.T) {
 		t.Errorf("deleted job still member of collection")
 	}
 }
+
+func TestCollectionRepository_SmartCollection(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+
+	jobs := NewJobRepository(db)
+	repo := NewCollectionRepository(db)
+	for _, v := range []struct{ id, channel, uploaded string }{
+		{"v1", "Alpha", "20240301"},
+		{"v2", "Beta", "20240102"},
+		{"v3", "Alpha", "20240101"},
+	} {
+		if err := jobs.Create(testutil.CreateTestJob(v.id, "https://youtube.com/watch?v="+v.id)); err != nil {
+			t.Fatalf("Create(%s) error = %v", v.id, err)
+		}
+		meta := testutil.CreateTestVideoMetadata()
+		meta.ID, meta.Channel, meta.Uploader, meta.UploadDate = v.id, v.channel, v.channel, v.uploaded
+		meta.Thumbnail = "https://example.com/" + v.id + ".jpg"
+		if err := jobs.StoreMetadata(v.id, meta); err != nil {
+			t.Fatalf("StoreMetadata(%s) error = %v", v.id, err)
+		}
+	}
+
+	smart := newTestCollection("smart", "Alpha uploads")
+	smart.Query = "channel:alpha"
+	if err := repo.Create(smart); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	videos, err := repo.GetVideos("smart")
+	if err != nil {
+		t.Fatalf("GetVideos() error = %v", err)
+	}
+	if len(videos) != 2 || videos[0].Job.ID != "v3" || videos[1].Job.ID != "v1" {
+		t.Fatalf("GetVideos() = %d videos, want v3 then v1", len(videos))
+	}
+
+	got, err := repo.GetByID("smart")
+	if err != nil || got == nil {
+		t.Fatalf("GetByID() = %v, %v", got, err)
+	}
+	if got.Query != "channel:alpha" || got.VideoCount != 2 || got.Thumbnail != "https://example.com/v3.jpg" {
+		t.Errorf("GetByID() = %+v, want 2 videos with v3's thumbnail", got)
+	}
+
+	// Membership follows the library.
+	got.Query = "channel:alpha uploaded:2024-03"
+	if err := repo.Update(got); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	list, err := repo.List()
+	if err != nil || len(list) != 1 || list[0].VideoCount != 1 {
+		t.Fatalf("List() = %+v, %v, want the collection with 1 video", list, err)
+	}
+
+	if err := repo.AddVideos("smart", []string{"v2"}); !errors.Is(err, domain.ErrSmartCollection) {
+		t.Errorf("AddVideos() error = %v, want ErrSmartCollection", err)
+	}
+	if err := repo.RemoveVideo("smart", "v1"); !errors.Is(err, domain.ErrSmartCollection) {
+		t.Errorf("RemoveVideo() error = %v, want ErrSmartCollection", err)
+	}
+}
+
+func TestCollectionRepository_SetOrderAndMoveVideo(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+
+	jobs := NewJobRepository(db)
+	repo := NewCollectionRepository(db)
+	for _, id := range []string{"a", "b", "c"} {
+		createTestVideo(t, jobs, id)
+	}
+	if err := repo.Create(newTestCollection("col-1", "Mix")); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := repo.AddVideos("col-1", []string{"a", "b", "c"}); err != nil {
+		t.Fatalf("AddVideos() error = %v", err)
+	}
+
+	order := func() string {
+		videos, err := repo.GetVideos("col-1")
+		if err != nil {
+			t.Fatalf("GetVideos() error = %v", err)
+		}
+		var ids []string
+		for _, v := range videos {
+			ids = append(ids, v.Job.ID)
+		}
+		return strings.Join(ids, ",")
+	}
+
+	if err := repo.SetOrder("col-1", []string{"c", "a", "b"}); err != nil {
+		t.Fatalf("SetOrder() error = %v", err)
+	}
+	if got := order(); got != "c,a,b" {
+		t.Errorf("order after SetOrder = %s, want c,a,b", got)
+	}
+	for _, bad := range [][]string{{"c", "a"}, {"c", "a", "a"}, {"c", "a", "x"}} {
+		if err := repo.SetOrder("col-1", bad); !errors.Is(err, domain.ErrCollectionOrder) {
+			t.Errorf("SetOrder(%v) error = %v, want ErrCollectionOrder", bad, err)
+		}
+	}
+
+	if err := repo.MoveVideo("col-1", "b", 1); err != nil {
+		t.Fatalf("MoveVideo() error = %v", err)
+	}
+	if got := order(); got != "b,c,a" {
+		t.Errorf("order after move to top = %s, want b,c,a", got)
+	}
+	// Positions past the end clamp to the last slot.
+	if err := repo.MoveVideo("col-1", "b", 10); err != nil {
+		t.Fatalf("MoveVideo() error = %v", err)
+	}
+	if got := order(); got != "c,a,b" {
+		t.Errorf("order after move to end = %s, want c,a,b", got)
+	}
+	if err := repo.MoveVideo("col-1", "x", 1); !errors.Is(err, domain.ErrNotInCollection) {
+		t.Errorf("MoveVideo(non-member) error = %v, want ErrNotInCollection", err)
+	}
+
+	// New videos are appended after the reordered ones.
+	createTestVideo(t, jobs, "d")
+	if err := repo.AddVideos("col-1", []string{"d"}); err != nil {
+		t.Fatalf("AddVideos() error = %v", err)
+	}
+	if got := order(); got != "c,a,b,d" {
+		t.Errorf("order after append = %s, want c,a,b,d", got)
+	}
+}
@@ -97,6 +97,392 @@ var migrations = []func(*sql.DB) error{
 		}
 		return addColumnIfMissing(db, "jobs", "media_type", "TEXT NOT NULL DEFAULT 'video'")
 	},
+	// 6: channel about-page history
+	func(db *sql.DB) error {
+		_, err := db.Exec(`
+        CREATE TABLE IF NOT EXISTS channel_snapshots (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            channel_id TEXT NOT NULL,
+            job_id TEXT NOT NULL,
+            description TEXT NOT NULL DEFAULT '',
+            follower_count INTEGER NOT NULL DEFAULT 0,
+            captured_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+            FOREIGN KEY (job_id) REFERENCES jobs (job_id)
+        );
+        CREATE INDEX IF NOT EXISTS idx_channel_snapshots_channel ON channel_snapshots(channel_id, captured_at);
+    `)
+		return err
+	},
+	// 7: archived comments and the opt-in setting
+	func(db *sql.DB) error {
+		if _, err := db.Exec(`
+        CREATE TABLE IF NOT EXISTS comments (
+            job_id TEXT NOT NULL,
+            comment_id TEXT NOT NULL,
+            parent_id TEXT NOT NULL DEFAULT '',
+            author TEXT NOT NULL DEFAULT '',
+            author_id TEXT NOT NULL DEFAULT '',
+            author_is_uploader BOOLEAN NOT NULL DEFAULT 0,
+            text TEXT NOT NULL DEFAULT '',
+            like_count INTEGER NOT NULL DEFAULT 0,
+            timestamp INTEGER NOT NULL DEFAULT 0,
+            is_pinned BOOLEAN NOT NULL DEFAULT 0,
+            is_hearted BOOLEAN NOT NULL DEFAULT 0,
+            PRIMARY KEY (job_id, comment_id),
+            FOREIGN KEY (job_id) REFERENCES jobs (job_id)
+        );
+        CREATE INDEX IF NOT EXISTS idx_comments_job_parent ON comments(job_id, parent_id);
+    `); err != nil {
+			return err
+		}
+		// Very old databases may predate the settings table entirely.
+		if ok, err := tableExists(db, "settings"); err != nil || !ok {
+			return err
+		}
+		return addColumnIfMissing(db, "settings", "archive_comments", "BOOLEAN DEFAULT 0")
+	},
+	// 8: engagement history and metadata refresh schedules
+	func(db *sql.DB) error {
+		_, err := db.Exec(`
+        CREATE TABLE IF NOT EXISTS metadata_snapshots (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            job_id TEXT NOT NULL,
+            title TEXT NOT NULL DEFAULT '',
+            description TEXT NOT NULL DEFAULT '',
+            view_count INTEGER NOT NULL DEFAULT 0,
+            like_count INTEGER NOT NULL DEFAULT 0,
+            comment_count INTEGER NOT NULL DEFAULT 0,
+            captured_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+            FOREIGN KEY (job_id) REFERENCES jobs (job_id)
+        );
+        CREATE INDEX IF NOT EXISTS idx_metadata_snapshots_job ON metadata_snapshots(job_id, captured_at);
+        CREATE TABLE IF NOT EXISTS refresh_schedules (
+            job_id TEXT PRIMARY KEY,
+            interval_hours INTEGER NOT NULL,
+            last_refreshed_at TIMESTAMP,
+            next_refresh_at TIMESTAMP NOT NULL,
+            FOREIGN KEY (job_id) REFERENCES jobs (job_id)
+        );
+    `)
+		return err
+	},
+	// 9: upstream availability checks and their interval setting
+	func(db *sql.DB) error {
+		if _, err := db.Exec(`
+        CREATE TABLE IF NOT EXISTS video_availability (
+            job_id TEXT PRIMARY KEY,
+            status TEXT NOT NULL,
+            reason TEXT NOT NULL DEFAULT '',
+            first_seen_at TIMESTAMP NOT NULL,
+            checked_at TIMESTAMP NOT NULL,
+            FOREIGN KEY (job_id) REFERENCES jobs (job_id)
+        );
+        CREATE INDEX IF NOT EXISTS idx_video_availability_status ON video_availability(status);
+    `); err != nil {
+			return err
+		}
+		if ok, err := tableExists(db, "settings"); err != nil || !ok {
+			return err
+		}
+		return addColumnIfMissing(db, "settings", "availability_check_hours", "INTEGER DEFAULT 168")
+	},
+	// 10: playlist/channel item list snapshots
+	func(db *sql.DB) error {
+		_, err := db.Exec(`
+        CREATE TABLE IF NOT EXISTS playlist_snapshots (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            source_id TEXT NOT NULL,
+            job_id TEXT NOT NULL,
+            item_count INTEGER NOT NULL DEFAULT 0,
+            added_count INTEGER NOT NULL DEFAULT 0,
+            removed_count INTEGER NOT NULL DEFAULT 0,
+            renamed_count INTEGER NOT NULL DEFAULT 0,
+            reordered_count INTEGER NOT NULL DEFAULT 0,
+            captured_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+            FOREIGN KEY (job_id) REFERENCES jobs (job_id)
+        );
+        CREATE INDEX IF NOT EXISTS idx_playlist_snapshots_source ON playlist_snapshots(source_id);
+        CREATE INDEX IF NOT EXISTS idx_playlist_snapshots_job ON playlist_snapshots(job_id);
+        CREATE TABLE IF NOT EXISTS playlist_snapshot_items (
+            snapshot_id INTEGER NOT NULL,
+            position INTEGER NOT NULL,
+            video_id TEXT NOT NULL,
+            title TEXT NOT NULL DEFAULT '',
+            PRIMARY KEY (snapshot_id, position),
+            FOREIGN KEY (snapshot_id) REFERENCES playlist_snapshots (id)
+        );
+    `)
+		return err
+	},
+	// 11: per-channel download options (tabs and filters)
+	func(db *sql.DB) error {
+		_, err := db.Exec(`
+        CREATE TABLE IF NOT EXISTS channel_download_options (
+            job_id TEXT PRIMARY KEY,
+            options_json TEXT NOT NULL,
+            updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+            FOREIGN KEY (job_id) REFERENCES jobs (job_id)
+        );
+    `)
+		return err
+	},
+	// 12: quality upgrade runs and their per-video results
+	func(db *sql.DB) error {
+		_, err := db.Exec(`
+        CREATE TABLE IF NOT EXISTS upgrade_runs (
+                id INTEGER PRIMARY KEY AUTOINCREMENT,
+                status TEXT NOT NULL,
+                dry_run BOOLEAN NOT NULL DEFAULT 0,
+                target_height INTEGER NOT NULL,
+                candidates INTEGER NOT NULL DEFAULT 0,
+                checked INTEGER NOT NULL DEFAULT 0,
+                upgraded INTEGER NOT NULL DEFAULT 0,
+                failed INTEGER NOT NULL DEFAULT 0,
+                bytes_before INTEGER NOT NULL DEFAULT 0,
+                bytes_after INTEGER NOT NULL DEFAULT 0,
+                started_at TIMESTAMP NOT NULL,
+                finished_at TIMESTAMP
+        );
+        CREATE TABLE IF NOT EXISTS upgrade_items (
+                id INTEGER PRIMARY KEY AUTOINCREMENT,
+                run_id INTEGER NOT NULL,
+                job_id TEXT NOT NULL,
+                title TEXT NOT NULL DEFAULT '',
+                outcome TEXT NOT NULL,
+                old_height INTEGER NOT NULL DEFAULT 0,
+                new_height INTEGER NOT NULL DEFAULT 0,
+                old_codec TEXT NOT NULL DEFAULT '',
+                new_codec TEXT NOT NULL DEFAULT '',
+                old_size INTEGER NOT NULL DEFAULT 0,
+                new_size INTEGER NOT NULL DEFAULT 0,
+                error TEXT NOT NULL DEFAULT '',
+                created_at TIMESTAMP NOT NULL,
+                FOREIGN KEY (run_id) REFERENCES upgrade_runs (id)
+        );
+        CREATE INDEX IF NOT EXISTS idx_upgrade_items_run ON upgrade_items(run_id);
+        CREATE INDEX IF NOT EXISTS idx_upgrade_items_job ON upgrade_items(job_id);
+    `)
+		return err
+	},
+	// 13: webhook endpoints and their delivery log
+	func(db *sql.DB) error {
+		_, err := db.Exec(`
+        CREATE TABLE IF NOT EXISTS webhooks (
+                id TEXT PRIMARY KEY,
+                name TEXT NOT NULL DEFAULT '',
+                url TEXT NOT NULL,
+                secret TEXT NOT NULL,
+                events_json TEXT NOT NULL DEFAULT '[]',
+                enabled BOOLEAN NOT NULL DEFAULT 1,
+                created_at TIMESTAMP NOT NULL,
+                updated_at TIMESTAMP NOT NULL
+        );
+        CREATE TABLE IF NOT EXISTS webhook_deliveries (
+                id INTEGER PRIMARY KEY AUTOINCREMENT,
+                webhook_id TEXT NOT NULL,
+                event TEXT NOT NULL,
+                payload TEXT NOT NULL,
+                status TEXT NOT NULL,
+                attempts INTEGER NOT NULL DEFAULT 0,
+                response_status INTEGER NOT NULL DEFAULT 0,
+                error TEXT NOT NULL DEFAULT '',
+                created_at TIMESTAMP NOT NULL,
+                updated_at TIMESTAMP NOT NULL,
+                delivered_at TIMESTAMP,
+                FOREIGN KEY (webhook_id) REFERENCES webhooks (id)
+        );
+        CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook ON webhook_deliveries(webhook_id, id);
+        CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_status ON webhook_deliveries(status);
+    `)
+		return err
+	},
+	// 14: post-download automation rules, and the link from the tools jobs
+	// they submit back to the rule and source download
+	func(db *sql.DB) error {
+		_, err := db.Exec(`
+        CREATE TABLE IF NOT EXISTS automation_rules (
+                id TEXT PRIMARY KEY,
+                name TEXT NOT NULL,
+                enabled BOOLEAN NOT NULL DEFAULT 1,
+                match_json TEXT NOT NULL DEFAULT '{}',
+                workflow_json TEXT NOT NULL,
+                created_at TIMESTAMP NOT NULL,
+                updated_at TIMESTAMP NOT NULL
+        );
+    `)
+		if err != nil {
+			return err
+		}
+		// Databases from before the tools feature have no tools_jobs table.
+		if exists, err := tableExists(db, "tools_jobs"); err != nil || !exists {
+			return err
+		}
+		if err := addColumnIfMissing(db, "tools_jobs", "automation_rule_id", "TEXT NOT NULL DEFAULT ''"); err != nil {
+			return err
+		}
+		if err := addColumnIfMissing(db, "tools_jobs", "source_job_id", "TEXT NOT NULL DEFAULT ''"); err != nil {
+			return err
+		}
+		_, err = db.Exec(`CREATE INDEX IF NOT EXISTS idx_tools_jobs_source_job ON tools_jobs(source_job_id)`)
+		return err
+	},
+	// 15: tagging rules, tag synonyms and the tag blocklist
+	func(db *sql.DB) error {
+		_, err := db.Exec(`
+        CREATE TABLE IF NOT EXISTS tag_rules (
+                id INTEGER PRIMARY KEY AUTOINCREMENT,
+                name TEXT NOT NULL,
+                enabled BOOLEAN NOT NULL DEFAULT 1,
+                match_json TEXT NOT NULL DEFAULT '{}',
+                tags_json TEXT NOT NULL DEFAULT '[]',
+                collection_id TEXT NOT NULL DEFAULT '',
+                created_at TIMESTAMP NOT NULL,
+                updated_at TIMESTAMP NOT NULL
+        );
+        CREATE TABLE IF NOT EXISTS tag_synonyms (
+                alias TEXT PRIMARY KEY COLLATE NOCASE,
+                canonical TEXT NOT NULL
+        );
+        CREATE TABLE IF NOT EXISTS tag_blocklist (
+                name TEXT PRIMARY KEY COLLATE NOCASE
+        );
+    `)
+		return err
+	},
+	// 16: tag colors and parent tags
+	func(db *sql.DB) error {
+		if err := addColumnIfMissing(db, "tags", "color", "TEXT NOT NULL DEFAULT ''"); err != nil {
+			return err
+		}
+		if err := addColumnIfMissing(db, "tags", "parent_id", "INTEGER REFERENCES tags (id)"); err != nil {
+			return err
+		}
+		_, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_tags_parent_id ON tags(parent_id)`)
+		return err
+	},
+	// 17: bulk operations and their per-item results
+	func(db *sql.DB) error {
+		_, err := db.Exec(`
+        CREATE TABLE IF NOT EXISTS bulk_operations (
+                id INTEGER PRIMARY KEY AUTOINCREMENT,
+                action TEXT NOT NULL,
+                parameters_json TEXT NOT NULL DEFAULT '{}',
+                status TEXT NOT NULL,
+                total INTEGER NOT NULL DEFAULT 0,
+                processed INTEGER NOT NULL DEFAULT 0,
+                succeeded INTEGER NOT NULL DEFAULT 0,
+                skipped INTEGER NOT NULL DEFAULT 0,
+                failed INTEGER NOT NULL DEFAULT 0,
+                started_at TIMESTAMP NOT NULL,
+                finished_at TIMESTAMP
+        );
+        CREATE TABLE IF NOT EXISTS bulk_items (
+                id INTEGER PRIMARY KEY AUTOINCREMENT,
+                operation_id INTEGER NOT NULL,
+                job_id TEXT NOT NULL,
+                title TEXT NOT NULL DEFAULT '',
+                outcome TEXT NOT NULL,
+                detail TEXT NOT NULL DEFAULT '',
+                error TEXT NOT NULL DEFAULT '',
+                created_at TIMESTAMP NOT NULL,
+                FOREIGN KEY (operation_id) REFERENCES bulk_operations (id)
+        );
+        CREATE INDEX IF NOT EXISTS idx_bulk_items_operation ON bulk_items(operation_id);
+    `)
+		return err
+	},
+	// 18: full-text search index, backfilled from the library
+	func(db *sql.DB) error {
+		if _, err := db.Exec(`
+        CREATE VIRTUAL TABLE IF NOT EXISTS search_index USING fts5(
+                item_id UNINDEXED,
+                kind UNINDEXED,
+                title,
+                description,
+                channel,
+                tags,
+                notes,
+                tokenize = 'unicode61 remove_diacritics 2'
+        );
+    `); err != nil {
+			return err
+		}
+		// Databases from before metadata storage have nothing to index.
+		for _, table := range []string{"videos", "playlists", "channels", "collections"} {
+			if exists, err := tableExists(db, table); err != nil || !exists {
+				return err
+			}
+		}
+		return rebuildSearchIndex(db)
+	},
+	// 19: saved library queries of smart collections
+	func(db *sql.DB) error {
+		return addColumnIfMissing(db, "collections", "query", "TEXT NOT NULL DEFAULT ''")
+	},
+	// 20: per-video watch progress
+	func(db *sql.DB) error {
+		_, err := db.Exec(`
+        CREATE TABLE IF NOT EXISTS watch_progress (
+            job_id TEXT PRIMARY KEY,
+            position REAL NOT NULL DEFAULT 0,
+            duration REAL NOT NULL DEFAULT 0,
+            completed BOOLEAN NOT NULL DEFAULT 0,
+            last_watched_at TIMESTAMP NOT NULL,
+            FOREIGN KEY (job_id) REFERENCES jobs (job_id)
+        );
+        CREATE INDEX IF NOT EXISTS idx_watch_progress_last_watched ON watch_progress(last_watched_at DESC);
+    `)
+		return err
+	},
+	// 21: favorites, ratings and notes of jobs and collections
+	func(db *sql.DB) error {
+		_, err := db.Exec(`
+        CREATE TABLE IF NOT EXISTS annotations (
+            item_id TEXT NOT NULL,
+            kind TEXT NOT NULL,
+            favorite BOOLEAN NOT NULL DEFAULT 0,
+            rating INTEGER NOT NULL DEFAULT 0,
+            notes TEXT NOT NULL DEFAULT '',
+            updated_at TIMESTAMP NOT NULL,
+            PRIMARY KEY (item_id, kind)
+        );
+    `)
+		return err
+	},
+	// 22: timestamped bookmarks of videos
+	func(db *sql.DB) error {
+		_, err := db.Exec(`
+        CREATE TABLE IF NOT EXISTS bookmarks (
+            id TEXT PRIMARY KEY,
+            job_id TEXT NOT NULL,
+            start_seconds REAL NOT NULL,
+            end_seconds REAL,
+            label TEXT NOT NULL DEFAULT '',
+            tags TEXT NOT NULL DEFAULT '[]',
+            created_at TIMESTAMP NOT NULL,
+            updated_at TIMESTAMP NOT NULL,
+            FOREIGN KEY (job_id) REFERENCES jobs (job_id)
+        );
+        CREATE INDEX IF NOT EXISTS idx_bookmarks_job ON bookmarks(job_id, start_seconds);
+    `)
+		return err
+	},
+	// 23: where an interrupted refresh of a playlist or channel resumes
+	func(db *sql.DB) error {
+		return addColumnIfMissing(db, "refresh_schedules", "refresh_cursor", "TEXT NOT NULL DEFAULT ''")
+	},
+	// 24: per-job archive_comments override, kept for re-syncs
+	func(db *sql.DB) error {
+		return addColumnIfMissing(db, "jobs", "archive_comments", "BOOLEAN")
+	},
+	// 25: per-job quality cap, kept for re-syncs
+	func(db *sql.DB) error {
+		return addColumnIfMissing(db, "jobs", "custom_quality", "INTEGER")
+	},
+	// 26: live capture flag, kept across restarts and re-syncs
+	func(db *sql.DB) error {
+		return addColumnIfMissing(db, "jobs", "live", "BOOLEAN NOT NULL DEFAULT 0")
+	},
 }
 
 func NewDB(dbPath string) (*sql.DB, error) {
@@ -30,9 +30,11 @@ func (r *JobRepository) Create(job *domain.Job) error {
 	}
 
 	_, err = r.db.Exec(`
-        INSERT INTO jobs (job_id, url, status, progress, media_type, warnings, file_path, created_at, updated_at)
-        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		job.ID, job.URL, job.Status, job.Progress, mediaType, string(warningsJSON), job.FilePath, job.CreatedAt, job.UpdatedAt)
+        INSERT INTO jobs (job_id, url, status, progress, media_type, warnings, file_path, archive_comments, custom_quality,
+                          live, created_at, updated_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		job.ID, job.URL, job.Status, job.Progress, mediaType, string(warningsJSON), job.FilePath, job.ArchiveComments,
+		job.CustomQuality, job.Live, job.CreatedAt, job.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("create job: %w", err)
 	}
@@ -47,11 +49,13 @@ func (r *JobRepository) Update(job *domain.Job) error {
 		return fmt.Errorf("marshal warnings: %w", err)
 	}
 
+	// Live is only ever set: a job found to be a live capture stays one, even
+	// when updated through a copy made before it was found out.
 	_, err = r.db.Exec(`
         UPDATE jobs
-        SET status = ?, progress = ?, warnings = ?, updated_at = ?
+        SET status = ?, progress = ?, warnings = ?, live = live OR ?, updated_at = ?
         WHERE job_id = ?`,
-		job.Status, job.Progress, string(warningsJSON), job.UpdatedAt, job.ID)
+		job.Status, job.Progress, string(warningsJSON), job.Live, job.UpdatedAt, job.ID)
 	if err != nil {
 		return fmt.Errorf("update job: %w", err)
 	}
@@ -75,12 +79,16 @@ func (r *JobRepository) GetByID(id string) (*domain.Job, error) {
 	job := &domain.Job{}
 	var warningsJSON, filePath sql.NullString
 	var mediaType string
+	var archiveComments sql.NullBool
+	var customQuality sql.NullInt64
 
 	err := r.db.QueryRow(`
-        SELECT job_id, url, status, progress, media_type, warnings, file_path, created_at, updated_at
+        SELECT job_id, url, status, progress, media_type, warnings, file_path, archive_comments, custom_quality,
+               live, created_at, updated_at
         FROM jobs
         WHERE job_id = ?`, id).
-		Scan(&job.ID, &job.URL, &job.Status, &job.Progress, &mediaType, &warningsJSON, &filePath, &job.CreatedAt, &job.UpdatedAt)
+		Scan(&job.ID, &job.URL, &job.Status, &job.Progress, &mediaType, &warningsJSON, &filePath, &archiveComments,
+			&customQuality, &job.Live, &job.CreatedAt, &job.UpdatedAt)
 	if err != nil {
 		return nil, fmt.Errorf("get job by id: %w", err)
 	}
@@ -94,13 +102,20 @@ func (r *JobRepository) GetByID(id string) (*domain.Job, error) {
 	}
 	job.MediaType = domain.MediaType(mediaType)
 	job.FilePath = filePath.String
+	if archiveComments.Valid {
+		job.ArchiveComments = &archiveComments.Bool
+	}
+	if customQuality.Valid {
+		quality := int(customQuality.Int64)
+		job.CustomQuality = &quality
+	}
 
 	return job, nil
 }
 
 func (r *JobRepository) GetRecent(limit int) ([]*domain.Job, error) {
 	rows, err := r.db.Query(`
-        SELECT job_id, url, status, progress, media_type, warnings, file_path, created_at, updated_at
+        SELECT job_id, url, status, progress, media_type, warnings, file_path, live, created_at, updated_at
         FROM jobs
         ORDER BY updated_at DESC
         LIMIT ?`, limit)
@@ -116,7 +131,7 @@ func (r *JobRepository) GetRecent(limit int) ([]*domain.Job, error) {
 		var mediaType string
 
 		err := rows.Scan(&job.ID, &job.URL, &job.Status, &job.Progress,
-			&mediaType, &warningsJSON, &filePath, &job.CreatedAt, &job.UpdatedAt)
+			&mediaType, &warningsJSON, &filePath, &job.Live, &job.CreatedAt, &job.UpdatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("scan job row: %w", err)
 		}
@@ -137,35 +152,45 @@ func (r *JobRepository) GetRecent(limit int) ([]*domain.Job, error) {
 }
 
 func (r *JobRepository) StoreMetadata(jobID string, metadata domain.Metadata) error {
+	var created bool
 	var err error
 	switch m := metadata.(type) {
 	case *domain.VideoMetadata:
-		err = r.storeVideoMetadata(jobID, m)
+		created, err = r.storeVideoMetadata(jobID, m)
 	case *domain.PlaylistMetadata:
-		err = r.storePlaylistMetadata(jobID, m)
+		created, err = r.storePlaylistMetadata(jobID, m)
 	case *domain.ChannelMetadata:
-		err = r.storeChannelMetadata(jobID, m)
+		created, err = r.storeChannelMetadata(jobID, m)
 	default:
 		return fmt.Errorf("unsupported metadata type: %T", metadata)
 	}
 	if err != nil {
 		return err
 	}
-	r.applyAutoTags(jobID, metadata)
+	r.applyAutoTags(jobID, metadata, created)
+	if err := reindexJobsSearch(r.db, []string{jobID}); err != nil {
+		log.WithError(err).WithField("jobID", jobID).Warn("Failed to index job for search")
+	}
+	switch m := metadata.(type) {
+	case *domain.VideoMetadata:
+		r.recordBaselineSnapshot(jobID, m)
+	case *domain.ChannelMetadata:
+		r.recordChannelSnapshot(jobID, m)
+	}
 	return nil
 }
 
-func (r *JobRepository) storeVideoMetadata(jobID string, metadata *domain.VideoMetadata) error {
+func (r *JobRepository) storeVideoMetadata(jobID string, metadata *domain.VideoMetadata) (bool, error) {
 	metadataJSON, err := json.Marshal(metadata)
 	if err != nil {
-		return fmt.Errorf("marshal metadata: %w", err)
+		return false, fmt.Errorf("marshal metadata: %w", err)
 	}
 
 	// Check if the record already exists
 	var count int
 	err = r.db.QueryRow("SELECT COUNT(*) FROM videos WHERE job_id = ?", jobID).Scan(&count)
 	if err != nil {
-		return fmt.Errorf("check existing video: %w", err)
+		return false, fmt.Errorf("check existing video: %w", err)
 	}
 
 	if count > 0 {
@@ -176,7 +201,7 @@ func (r *JobRepository) storeVideoMetadata(jobID string, metadata *domain.VideoM
             WHERE job_id = ?`,
 			metadata.Title, string(metadataJSON), jobID)
 		if err != nil {
-			return fmt.Errorf("update video metadata: %w", err)
+			return false, fmt.Errorf("update video metadata: %w", err)
 		}
 	} else {
 		// Insert new record
@@ -185,31 +210,31 @@ func (r *JobRepository) storeVideoMetadata(jobID string, metadata *domain.VideoM
             VALUES (?, ?, ?)`,
 			jobID, metadata.Title, string(metadataJSON))
 		if err != nil {
-			return fmt.Errorf("insert video metadata: %w", err)
+			return false, fmt.Errorf("insert video metadata: %w", err)
 		}
 	}
 
-	return nil
+	return count == 0, nil
 }
 
-func (r *JobRepository) storePlaylistMetadata(jobID string, metadata *domain.PlaylistMetadata) error {
+func (r *JobRepository) storePlaylistMetadata(jobID string, metadata *domain.PlaylistMetadata) (bool, error) {
 	metadataJSON, err := json.Marshal(metadata)
 	if err != nil {
-		return fmt.Errorf("marshal metadata: %w", err)
+		return false, fmt.Errorf("marshal metadata: %w", err)
 	}
 
 	// Check if the record already exists
 	var count int
 	err = r.db.QueryRow("SELECT COUNT(*) FROM playlists WHERE job_id = ?", jobID).Scan(&count)
 	if err != nil {
-		return fmt.Errorf("check existing playlist: %w", err)
+		return false, fmt.Errorf("check existing playlist: %w", err)
 	}
 
 	if count > 0 {
 		// Update existing record only if items are not empty
 		if len(metadata.Items) == 0 {
 			log.Info("No items in playlist metadata, skipping update")
-			return nil
+			return false, nil
 		}
 
 		_, err = r.db.Exec(`
@@ -218,7 +243,7 @@ func (r *JobRepository) storePlaylistMetadata(jobID string, metadata *domain.Pla
             WHERE job_id = ?`,
 			metadata.Title, string(metadataJSON), jobID)
 		if err != nil {
-			return fmt.Errorf("update playlist metadata: %w", err)
+			return false, fmt.Errorf("update playlist metadata: %w", err)
 		}
 	} else {
 		// Insert new record
@@ -227,24 +252,24 @@ func (r *JobRepository) storePlaylistMetadata(jobID string, metadata *domain.Pla
             VALUES (?, ?, ?)`,
 			jobID, metadata.Title, string(metadataJSON))
 		if err != nil {
-			return fmt.Errorf("insert playlist metadata: %w", err)
+			return false, fmt.Errorf("insert playlist metadata: %w", err)
 		}
 	}
 
-	return nil
+	return count == 0, nil
 }
 
-func (r *JobRepository) storeChannelMetadata(jobID string, metadata *domain.ChannelMetadata) error {
+func (r *JobRepository) storeChannelMetadata(jobID string, metadata *domain.ChannelMetadata) (bool, error) {
 	metadataJSON, err := json.Marshal(metadata)
 	if err != nil {
-		return fmt.Errorf("marshal metadata: %w", err)
+		return false, fmt.Errorf("marshal metadata: %w", err)
 	}
 
 	// Check if the record already exists
 	var count int
 	err = r.db.QueryRow("SELECT COUNT(*) FROM channels WHERE job_id = ?", jobID).Scan(&count)
 	if err != nil {
-		return fmt.Errorf("check existing channel: %w", err)
+		return false, fmt.Errorf("check existing channel: %w", err)
 	}
 
 	if count > 0 {
@@ -255,7 +280,7 @@ func (r *JobRepository) storeChannelMetadata(jobID string, metadata *domain.Chan
             WHERE job_id = ?`,
 			metadata.Channel, string(metadataJSON), jobID)
 		if err != nil {
-			return fmt.Errorf("update channel metadata: %w", err)
+			return false, fmt.Errorf("update channel metadata: %w", err)
 		}
 	} else {
 		// Insert new record
@@ -264,11 +289,11 @@ func (r *JobRepository) storeChannelMetadata(jobID string, metadata *domain.Chan
             VALUES (?, ?, ?)`,
 			jobID, metadata.Channel, string(metadataJSON))
 		if err != nil {
-			return fmt.Errorf("insert channel metadata: %w", err)
+			return false, fmt.Errorf("insert channel metadata: %w", err)
 		}
 	}
 
-	return nil
+	return count == 0, nil
 }
 
 func (r *JobRepository) GetJobWithMetadata(jobID string) (*domain.JobWithMetadata, error) {
@@ -289,11 +314,33 @@ func (r *JobRepository) GetJobWithMetadata(jobID string) (*domain.JobWithMetadat
 		tags = nil
 	}
 
-	return &domain.JobWithMetadata{
-		Job:      job,
-		Metadata: metadata,
-		Tags:     tags,
-	}, nil
+	availability, err := r.GetAvailability(jobID)
+	if err != nil {
+		log.WithError(err).Warnf("Could not retrieve availability for job %s", jobID)
+	}
+
+	annotation, err := r.GetAnnotation(jobID)
+	if err != nil {
+		log.WithError(err).Warnf("Could not retrieve annotation for job %s", jobID)
+	}
+
+	result := &domain.JobWithMetadata{
+		Job:          job,
+		Metadata:     metadata,
+		Tags:         tags,
+		Availability: availability,
+		Annotation:   annotation,
+	}
+	switch metadata.(type) {
+	case *domain.VideoMetadata:
+		err = r.attachWatch([]*domain.JobWithMetadata{result})
+	case *domain.PlaylistMetadata, *domain.ChannelMetadata:
+		err = r.attachCompletion([]*domain.JobWithMetadata{result})
+	}
+	if err != nil {
+		log.WithError(err).Warnf("Could not retrieve watch progress for job %s", jobID)
+	}
+	return result, nil
 }
 
 func (r *JobRepository) GetRecentWithMetadata(limit int) ([]*domain.JobWithMetadata, error) {
@@ -352,7 +399,7 @@ func (r *JobRepository) GetAllJobsWithMetadata() ([]*domain.JobWithMetadata, err
 
 func (r *JobRepository) GetJobs() ([]*domain.Job, error) {
 	rows, err := r.db.Query(`
-		SELECT job_id, url, status, progress, media_type, warnings, file_path, created_at, updated_at
+		SELECT job_id, url, status, progress, media_type, warnings, file_path, live, created_at, updated_at
 		FROM jobs`)
 	if err != nil {
 		return nil, fmt.Errorf("get jobs: %w", err)
@@ -366,7 +413,7 @@ func (r *JobRepository) GetJobs() ([]*domain.Job, error) {
 		var mediaType string
 
 		err := rows.Scan(&job.ID, &job.URL, &job.Status, &job.Progress,
-			&mediaType, &warningsJSON, &filePath, &job.CreatedAt, &job.UpdatedAt)
+			&mediaType, &warningsJSON, &filePath, &job.Live, &job.CreatedAt, &job.UpdatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("scan job row: %w", err)
 		}
@@ -464,13 +511,14 @@ func (r *JobRepository) GetMetadataByType(contentType string, opts domain.Metada
 	order := opts.Order
 
 	log.WithFields(log.Fields{
-		"contentType": contentType,
-		"page":        page,
-		"limit":       limit,
-		"sortBy":      sortBy,
-		"order":       order,
-		"search":      opts.Search,
-		"tag":         opts.Tag,
+		"contentType":  contentType,
+		"page":         page,
+		"limit":        limit,
+		"sortBy":       sortBy,
+		"order":        order,
+		"search":       opts.Search,
+		"tag":          opts.Tag,
+		"availability": opts.Availability,
 	}).Debug("Getting metadata by type")
 
 	if page < 1 {
@@ -506,19 +554,30 @@ func (r *JobRepository) GetMetadataByType(contentType string, opts domain.Metada
 	// Create a whitelist mapping of allowed sort fields to their actual SQL counterparts
 	var sortFieldMapping = map[string]map[string]string{
 		"videos": {
-			"created_at": "jobs.created_at",
-			"updated_at": "jobs.updated_at",
-			"title":      "videos.title",
+			"created_at":  "jobs.created_at",
+			"updated_at":  "jobs.updated_at",
+			"title":       "videos.title",
+			"duration":    "json_extract(videos.metadata_json, '$.duration')",
+			"upload_date": "NULLIF(json_extract(videos.metadata_json, '$.upload_date'), '')",
+			"views":       "json_extract(videos.metadata_json, '$.view_count')",
+			"file_size":   "json_extract(videos.metadata_json, '$.filesize_approx')",
+			"resolution":  "json_extract(videos.metadata_json, '$.height')",
+			"rating":      annotationField("jobs.job_id", "rating"),
+			"favorite":    annotationField("jobs.job_id", "favorite"),
 		},
 		"playlists": {
 			"created_at": "jobs.created_at",
 			"updated_at": "jobs.updated_at",
 			"title":      "playlists.title",
+			"rating":     annotationField("jobs.job_id", "rating"),
+			"favorite":   annotationField("jobs.job_id", "favorite"),
 		},
 		"channels": {
 			"created_at": "jobs.created_at",
 			"updated_at": "jobs.updated_at",
 			"title":      "channels.name",
+			"rating":     annotationField("jobs.job_id", "rating"),
+			"favorite":   annotationField("jobs.job_id", "favorite"),
 		},
 	}
 
@@ -534,9 +593,10 @@ func (r *JobRepository) GetMetadataByType(contentType string, opts domain.Metada
 		sortField = "jobs.created_at"
 	}
 
-	// Optional filters. Search matches the title and the channel name stored
-	// inside the metadata JSON; tag narrows to jobs carrying the named tag.
-	// Both are parameterized — only validated identifiers are interpolated.
+	// Optional filters. Search is a full-text match against the title,
+	// description, channel and tags in the search index; tag narrows to jobs
+	// carrying the named tag; filters come from the library query language.
+	// All are parameterized — only validated identifiers are interpolated.
 	titleColumn := tableName + ".title"
 	if contentType == "channels" {
 		titleColumn = "channels.name"
@@ -545,18 +605,35 @@ func (r *JobRepository) GetMetadataByType(contentType string, opts domain.Metada
 	var conditions []string
 	var filterArgs []any
 	if search := strings.TrimSpace(opts.Search); search != "" {
-		pattern := "%" + escapeLike(search) + "%"
-		conditions = append(conditions, `(`+titleColumn+` LIKE ? ESCAPE '\'
-            OR json_extract(`+tableName+`.metadata_json, '$.channel') LIKE ? ESCAPE '\'
-            OR json_extract(`+tableName+`.metadata_json, '$.uploader') LIKE ? ESCAPE '\')`)
-		filterArgs = append(filterArgs, pattern, pattern, pattern)
+		condition, args := searchTextCondition(search)
+		conditions = append(conditions, condition)
+		filterArgs = append(filterArgs, args...)
 	}
 	if tag := strings.TrimSpace(opts.Tag); tag != "" {
-		conditions = append(conditions, `EXISTS (
-            SELECT 1 FROM job_tags jt JOIN tags t ON t.id = jt.tag_id
-            WHERE jt.job_id = jobs.job_id AND t.name = ? COLLATE NOCASE)`)
+		// The named tag matches together with all of its descendants.
+		conditions = append(conditions, tagTreeCondition)
 		filterArgs = append(filterArgs, tag)
 	}
+	if opts.Availability != "" {
+		if contentType != "videos" {
+			return nil, 0, fmt.Errorf("availability filter only applies to videos")
+		}
+		conditions = append(conditions, `EXISTS (
+            SELECT 1 FROM video_availability va
+            WHERE va.job_id = jobs.job_id AND va.status = ?)`)
+		filterArgs = append(filterArgs, opts.Availability)
+	}
+	for _, filter := range opts.Filters {
+		if !filter.AppliesTo(contentType) {
+			return nil, 0, fmt.Errorf("the %s filter only applies to videos", filter.Field)
+		}
+		condition, args, err := queryFilterCondition(tableName, titleColumn, filter)
+		if err != nil {
+			return nil, 0, err
+		}
+		conditions = append(conditions, condition)
+		filterArgs = append(filterArgs, args...)
+	}
 
 	whereClause := ""
 	if len(conditions) > 0 {
@@ -572,14 +649,15 @@ func (r *JobRepository) GetMetadataByType(contentType string, opts domain.Metada
 		return nil, 0, fmt.Errorf("count %s: %w", contentType, err)
 	}
 
-	// Build the query using only validated table names and sort fields
+	// Build the query using only validated table names and sort fields. The
+	// job ID breaks ties so pages of items with equal sort keys don't overlap.
 	query := `
         SELECT jobs.job_id, jobs.url, jobs.status, jobs.progress, jobs.media_type, jobs.warnings, jobs.file_path, jobs.created_at, jobs.updated_at, ` +
 		tableName + `.metadata_json
         FROM ` + tableName + `
         JOIN jobs ON ` + tableName + `.job_id = jobs.job_id` +
 		whereClause + `
-        ORDER BY ` + sortField + ` ` + orderDirection + `
+        ORDER BY ` + sortField + ` ` + orderDirection + `, jobs.job_id
         LIMIT ? OFFSET ?`
 
 	log.Debugf("Executing download query with limit=%d offset=%d", limit, offset)
@@ -655,6 +733,19 @@ func (r *JobRepository) GetMetadataByType(contentType string, opts domain.Metada
 	if err := r.attachTags(result); err != nil {
 		log.WithError(err).Warn("Failed to attach tags to listing")
 	}
+	if err := r.attachAnnotations(result); err != nil {
+		log.WithError(err).Warn("Failed to attach annotations to listing")
+	}
+	if contentType == "videos" {
+		if err := r.attachAvailability(result); err != nil {
+			log.WithError(err).Warn("Failed to attach availability to listing")
+		}
+		if err := r.attachWatch(result); err != nil {
+			log.WithError(err).Warn("Failed to attach watch progress to listing")
+		}
+	} else if err := r.attachCompletion(result); err != nil {
+		log.WithError(err).Warn("Failed to attach completion to listing")
+	}
 
 	log.WithFields(log.Fields{
 		"contentType": contentType,
@@ -694,7 +785,7 @@ func (r *JobRepository) attachTags(items []*domain.JobWithMetadata) error {
 	}
 
 	rows, err := r.db.Query(`
-        SELECT jt.job_id, t.id, t.name, jt.source
+        SELECT jt.job_id, t.id, t.name, t.color, t.parent_id, jt.source
         FROM job_tags jt
         JOIN tags t ON t.id = jt.tag_id
         WHERE jt.job_id IN (`+strings.Join(placeholders, ",")+`)
@@ -708,9 +799,11 @@ func (r *JobRepository) attachTags(items []*domain.JobWithMetadata) error {
 	for rows.Next() {
 		var jobID string
 		var tag domain.Tag
-		if err := rows.Scan(&jobID, &tag.ID, &tag.Name, &tag.Source); err != nil {
+		var parentID sql.NullInt64
+		if err := rows.Scan(&jobID, &tag.ID, &tag.Name, &tag.Color, &parentID, &tag.Source); err != nil {
 			return fmt.Errorf("scan tag: %w", err)
 		}
+		tag.ParentID = nullableTagID(parentID)
 		tagsByJob[jobID] = append(tagsByJob[jobID], tag)
 	}
 
@@ -833,15 +926,27 @@ func (r *JobRepository) GetParentsForVideo(videoJobID string) ([]*domain.JobWith
 	return result, nil
 }
 
+// GetVideosForParent returns the videos of a playlist or channel job in the
+// source's order as of the job's latest snapshot. Videos missing from the
+// snapshot (or parents synced before snapshots existed) follow in the order
+// they were linked.
 func (r *JobRepository) GetVideosForParent(parentJobID string) ([]*domain.JobWithMetadata, error) {
 	rows, err := r.db.Query(`
-        SELECT j.job_id, j.url, j.status, j.progress, j.media_type, j.warnings, j.file_path, j.created_at, j.updated_at,
+        WITH source_order AS (
+            SELECT video_id, MIN(position) AS position
+            FROM playlist_snapshot_items
+            WHERE snapshot_id = (SELECT MAX(id) FROM playlist_snapshots WHERE job_id = ?)
+            GROUP BY video_id
+        )
+        SELECT j.job_id, j.url, j.status, j.progress, j.media_type, j.warnings, j.file_path, j.live, j.created_at, j.updated_at,
                v.metadata_json
         FROM jobs j
         JOIN video_memberships vm ON j.job_id = vm.video_job_id
         JOIN videos v ON j.job_id = v.job_id
-        WHERE vm.parent_job_id = ?`,
-		parentJobID)
+        LEFT JOIN source_order so ON so.video_id = j.job_id
+        WHERE vm.parent_job_id = ?
+        ORDER BY so.position IS NULL, so.position, vm.id`,
+		parentJobID, parentJobID)
 
 	if err != nil {
 		return nil, err
@@ -858,7 +963,7 @@ func (r *JobRepository) GetVideosForParent(parentJobID string) ([]*domain.JobWit
 
 		err := rows.Scan(
 			&job.ID, &job.URL, &job.Status, &job.Progress, &mediaType, &warningsJSON, &filePath,
-			&job.CreatedAt, &job.UpdatedAt, &metadataJSON,
+			&job.Live, &job.CreatedAt, &job.UpdatedAt, &metadataJSON,
 		)
 
 		if err != nil {
@@ -886,6 +991,16 @@ func (r *JobRepository) GetVideosForParent(parentJobID string) ([]*domain.JobWit
 			Metadata: &videoMetadata,
 		})
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
 
+	if err := r.attachWatch(result); err != nil {
+		log.WithError(err).Warn("Failed to attach watch progress to parent videos")
+	}
+	if err := r.attachAnnotations(result); err != nil {
+		log.WithError(err).Warn("Failed to attach annotations to parent videos")
+	}
 	return result, nil
 }
@@ -0,0 +1,125 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"video-archiver/internal/domain"
+)
+
+// RecordAvailability stores the result of an availability check. The
+// first-seen date carries over while the status stays the same. Times are
+// written in UTC so checked_at orders correctly as text.
+func (r *JobRepository) RecordAvailability(jobID string, status domain.Availability, reason string, at time.Time) (*domain.AvailabilityStatus, error) {
+	previous, err := r.GetAvailability(jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	at = at.UTC()
+	firstSeen := at
+	if previous != nil && previous.Status == status {
+		firstSeen = previous.FirstSeenAt
+	}
+
+	if _, err := r.db.Exec(`
+        INSERT INTO video_availability (job_id, status, reason, first_seen_at, checked_at)
+        VALUES (?, ?, ?, ?, ?)
+        ON CONFLICT(job_id) DO UPDATE SET
+            status = excluded.status,
+            reason = excluded.reason,
+            first_seen_at = excluded.first_seen_at,
+            checked_at = excluded.checked_at`,
+		jobID, status, reason, firstSeen, at); err != nil {
+		return nil, fmt.Errorf("record availability: %w", err)
+	}
+	return previous, nil
+}
+
+// GetAvailability returns a video's latest check result, or nil when it has
+// never been checked.
+func (r *JobRepository) GetAvailability(jobID string) (*domain.AvailabilityStatus, error) {
+	var s domain.AvailabilityStatus
+	err := r.db.QueryRow(`
+        SELECT job_id, status, reason, first_seen_at, checked_at
+        FROM video_availability
+        WHERE job_id = ?`, jobID).Scan(&s.JobID, &s.Status, &s.Reason, &s.FirstSeenAt, &s.CheckedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get availability: %w", err)
+	}
+	return &s, nil
+}
+
+// ListAvailabilityCandidates returns downloaded videos due for a check.
+// Failed and cancelled jobs are skipped: there is nothing archived to lose.
+func (r *JobRepository) ListAvailabilityCandidates(checkedBefore time.Time, limit int) ([]*domain.Job, error) {
+	rows, err := r.db.Query(`
+        SELECT jobs.job_id, jobs.url, jobs.status
+        FROM videos
+        JOIN jobs ON jobs.job_id = videos.job_id
+        LEFT JOIN video_availability va ON va.job_id = jobs.job_id
+        WHERE jobs.status = ? AND (va.checked_at IS NULL OR va.checked_at < ?)
+        ORDER BY va.checked_at IS NOT NULL, va.checked_at ASC
+        LIMIT ?`, domain.JobStatusComplete, checkedBefore.UTC(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("list availability candidates: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := []*domain.Job{}
+	for rows.Next() {
+		job := &domain.Job{}
+		if err := rows.Scan(&job.ID, &job.URL, &job.Status); err != nil {
+			return nil, fmt.Errorf("scan availability candidate: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+// attachAvailability loads the latest availability of a page of videos in one
+// query and assigns it.
+func (r *JobRepository) attachAvailability(items []*domain.JobWithMetadata) error {
+	ids := make([]any, 0, len(items))
+	placeholders := make([]string, 0, len(items))
+	for _, item := range items {
+		if item.Job != nil {
+			ids = append(ids, item.Job.ID)
+			placeholders = append(placeholders, "?")
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	rows, err := r.db.Query(`
+        SELECT job_id, status, reason, first_seen_at, checked_at
+        FROM video_availability
+        WHERE job_id IN (`+strings.Join(placeholders, ",")+`)`, ids...)
+	if err != nil {
+		return fmt.Errorf("load availability: %w", err)
+	}
+	defer rows.Close()
+
+	byJob := map[string]*domain.AvailabilityStatus{}
+	for rows.Next() {
+		var s domain.AvailabilityStatus
+		if err := rows.Scan(&s.JobID, &s.Status, &s.Reason, &s.FirstSeenAt, &s.CheckedAt); err != nil {
+			return fmt.Errorf("scan availability: %w", err)
+		}
+		byJob[s.JobID] = &s
+	}
+
+	for _, item := range items {
+		if item.Job != nil {
+			item.Availability = byJob[item.Job.ID]
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,123 @@
+package sqlite
+
+import (
+	"testing"
+	"time"
+	"video-archiver/internal/domain"
+	"video-archiver/internal/testutil"
+)
+
+func createCompletedVideo(t *testing.T, repo *JobRepository, id string) {
+	t.Helper()
+	job := testutil.CreateTestJob(id, "https://youtube.com/watch?v="+id)
+	job.Status = domain.JobStatusComplete
+	if err := repo.Create(job); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := repo.StoreMetadata(id, testutil.CreateTestVideoMetadata()); err != nil {
+		t.Fatalf("StoreMetadata() error = %v", err)
+	}
+}
+
+func TestJobRepository_RecordAvailability(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+	repo := NewJobRepository(db)
+	createCompletedVideo(t, repo, "video-1")
+
+	first := time.Now().Add(-48 * time.Hour)
+	previous, err := repo.RecordAvailability("video-1", domain.AvailabilityRemoved, "removed by uploader", first)
+	if err != nil || previous != nil {
+		t.Fatalf("first RecordAvailability() = %v, %v; want nil previous", previous, err)
+	}
+
+	// A repeated status keeps its first-seen date.
+	previous, err = repo.RecordAvailability("video-1", domain.AvailabilityRemoved, "removed by uploader", time.Now())
+	if err != nil || previous == nil || previous.Status != domain.AvailabilityRemoved {
+		t.Fatalf("second RecordAvailability() = %v, %v", previous, err)
+	}
+	status, err := repo.GetAvailability("video-1")
+	if err != nil {
+		t.Fatalf("GetAvailability() error = %v", err)
+	}
+	if !status.FirstSeenAt.Equal(first) || !status.CheckedAt.After(first) {
+		t.Errorf("status = %+v, want first seen at %v", status, first)
+	}
+
+	// A new status starts a new first-seen date.
+	if _, err := repo.RecordAvailability("video-1", domain.AvailabilityAvailable, "", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	status, _ = repo.GetAvailability("video-1")
+	if status.Status != domain.AvailabilityAvailable || status.FirstSeenAt.Equal(first) {
+		t.Errorf("status after change = %+v", status)
+	}
+
+	if err := repo.DeleteJob("video-1"); err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := repo.GetAvailability("video-1"); status != nil {
+		t.Errorf("availability left after DeleteJob: %+v", status)
+	}
+}
+
+func TestJobRepository_ListAvailabilityCandidates(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+	repo := NewJobRepository(db)
+
+	createCompletedVideo(t, repo, "never-checked")
+	createCompletedVideo(t, repo, "checked-long-ago")
+	createCompletedVideo(t, repo, "checked-recently")
+	failed := testutil.CreateTestJob("failed", "https://youtube.com/watch?v=failed")
+	failed.Status = domain.JobStatusError
+	repo.Create(failed)
+	repo.StoreMetadata("failed", testutil.CreateTestVideoMetadata())
+
+	now := time.Now()
+	repo.RecordAvailability("checked-long-ago", domain.AvailabilityAvailable, "", now.Add(-30*24*time.Hour))
+	repo.RecordAvailability("checked-recently", domain.AvailabilityAvailable, "", now.Add(-time.Hour))
+
+	jobs, err := repo.ListAvailabilityCandidates(now.Add(-7*24*time.Hour), 10)
+	if err != nil {
+		t.Fatalf("ListAvailabilityCandidates() error = %v", err)
+	}
+	if len(jobs) != 2 || jobs[0].ID != "never-checked" || jobs[1].ID != "checked-long-ago" {
+		ids := []string{}
+		for _, j := range jobs {
+			ids = append(ids, j.ID)
+		}
+		t.Errorf("candidates = %v, want [never-checked checked-long-ago]", ids)
+	}
+
+	jobs, _ = repo.ListAvailabilityCandidates(now.Add(-7*24*time.Hour), 1)
+	if len(jobs) != 1 {
+		t.Errorf("limit ignored: got %d candidates", len(jobs))
+	}
+}
+
+func TestJobRepository_GetMetadataByTypeAvailabilityFilter(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+	repo := NewJobRepository(db)
+
+	createCompletedVideo(t, repo, "gone")
+	createCompletedVideo(t, repo, "still-there")
+	repo.RecordAvailability("gone", domain.AvailabilityRemoved, "", time.Now())
+	repo.RecordAvailability("still-there", domain.AvailabilityAvailable, "", time.Now())
+
+	items, total, err := repo.GetMetadataByType("videos", domain.MetadataQuery{Availability: domain.AvailabilityRemoved})
+	if err != nil {
+		t.Fatalf("GetMetadataByType() error = %v", err)
+	}
+	if total != 1 || len(items) != 1 || items[0].Job.ID != "gone" {
+		t.Fatalf("filtered items = %d (total %d), want only 'gone'", len(items), total)
+	}
+	if items[0].Availability == nil || items[0].Availability.Status != domain.AvailabilityRemoved {
+		t.Errorf("availability not attached: %+v", items[0].Availability)
+	}
+
+	if _, _, err := repo.GetMetadataByType("playlists", domain.MetadataQuery{Availability: domain.AvailabilityRemoved}); err == nil {
+		t.Error("expected an error filtering playlists by availability")
+	}
+}
@@ -0,0 +1,57 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"video-archiver/internal/domain"
+)
+
+// SetChannelOptions stores a channel job's download options, replacing any
+// previous ones; nil removes them.
+func (r *JobRepository) SetChannelOptions(jobID string, options *domain.ChannelDownloadOptions) error {
+	if options == nil {
+		if _, err := r.db.Exec(`DELETE FROM channel_download_options WHERE job_id = ?`, jobID); err != nil {
+			return fmt.Errorf("delete channel options: %w", err)
+		}
+		return nil
+	}
+
+	optionsJSON, err := json.Marshal(options)
+	if err != nil {
+		return fmt.Errorf("marshal channel options: %w", err)
+	}
+	_, err = r.db.Exec(`
+        INSERT INTO channel_download_options (job_id, options_json, updated_at)
+        VALUES (?, ?, ?)
+        ON CONFLICT(job_id) DO UPDATE SET
+            options_json = excluded.options_json,
+            updated_at = excluded.updated_at`,
+		jobID, string(optionsJSON), time.Now())
+	if err != nil {
+		return fmt.Errorf("set channel options: %w", err)
+	}
+	return nil
+}
+
+// GetChannelOptions returns a channel job's download options, or nil when it
+// has none.
+func (r *JobRepository) GetChannelOptions(jobID string) (*domain.ChannelDownloadOptions, error) {
+	var optionsJSON string
+	err := r.db.QueryRow(`SELECT options_json FROM channel_download_options WHERE job_id = ?`, jobID).Scan(&optionsJSON)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get channel options: %w", err)
+	}
+
+	var options domain.ChannelDownloadOptions
+	if err := json.Unmarshal([]byte(optionsJSON), &options); err != nil {
+		return nil, fmt.Errorf("unmarshal channel options: %w", err)
+	}
+	return &options, nil
+}
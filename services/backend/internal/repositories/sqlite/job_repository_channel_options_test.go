@@ -0,0 +1,54 @@
+package sqlite
+
+import (
+	"testing"
+	"video-archiver/internal/domain"
+	"video-archiver/internal/testutil"
+)
+
+func TestJobRepository_ChannelOptions(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+	repo := NewJobRepository(db)
+
+	job := testutil.CreateTestJob("channel-1", "https://youtube.com/@example")
+	if err := repo.Create(job); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if options, err := repo.GetChannelOptions(job.ID); err != nil || options != nil {
+		t.Fatalf("GetChannelOptions() = %v, %v; want nil", options, err)
+	}
+
+	for _, maxItems := range []int{10, 20} {
+		err := repo.SetChannelOptions(job.ID, &domain.ChannelDownloadOptions{
+			Tabs:     []domain.ChannelTab{domain.ChannelTabShorts},
+			MaxItems: maxItems,
+		})
+		if err != nil {
+			t.Fatalf("SetChannelOptions() error = %v", err)
+		}
+	}
+	options, err := repo.GetChannelOptions(job.ID)
+	if err != nil || options == nil {
+		t.Fatalf("GetChannelOptions() = %v, %v", options, err)
+	}
+	if options.MaxItems != 20 || len(options.Tabs) != 1 || options.Tabs[0] != domain.ChannelTabShorts {
+		t.Errorf("GetChannelOptions() = %+v", options)
+	}
+
+	if err := repo.SetChannelOptions(job.ID, nil); err != nil {
+		t.Fatalf("SetChannelOptions(nil) error = %v", err)
+	}
+	if options, _ := repo.GetChannelOptions(job.ID); options != nil {
+		t.Errorf("options left after removal: %+v", options)
+	}
+
+	repo.SetChannelOptions(job.ID, &domain.ChannelDownloadOptions{MaxItems: 5})
+	if err := repo.DeleteJob(job.ID); err != nil {
+		t.Fatalf("DeleteJob() error = %v", err)
+	}
+	if options, _ := repo.GetChannelOptions(job.ID); options != nil {
+		t.Errorf("options left after DeleteJob: %+v", options)
+	}
+}
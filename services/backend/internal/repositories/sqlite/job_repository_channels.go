@@ -0,0 +1,86 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"video-archiver/internal/domain"
+)
+
+// recordChannelSnapshot appends the channel's current description and
+// follower count to its history when either differs from the latest snapshot.
+// Metadata is stored several times per download (basic extraction, async
+// enhancement), so unchanged states must not pile up. Best-effort: a failure
+// is logged, never a metadata store failure.
+func (r *JobRepository) recordChannelSnapshot(jobID string, metadata *domain.ChannelMetadata) {
+	if metadata.ID == "" || (metadata.Description == "" && metadata.ChannelFollowers == 0) {
+		return
+	}
+
+	var description string
+	var followers int
+	err := r.db.QueryRow(`
+        SELECT description, follower_count
+        FROM channel_snapshots
+        WHERE channel_id = ?
+        ORDER BY captured_at DESC, id DESC
+        LIMIT 1`, metadata.ID).Scan(&description, &followers)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		log.WithError(err).WithField("jobID", jobID).Warn("Failed to load latest channel snapshot")
+		return
+	}
+	if err == nil && description == metadata.Description && followers == metadata.ChannelFollowers {
+		return
+	}
+
+	if _, err := r.db.Exec(`
+        INSERT INTO channel_snapshots (channel_id, job_id, description, follower_count, captured_at)
+        VALUES (?, ?, ?, ?, ?)`,
+		metadata.ID, jobID, metadata.Description, metadata.ChannelFollowers, time.Now()); err != nil {
+		log.WithError(err).WithField("jobID", jobID).Warn("Failed to record channel snapshot")
+	}
+}
+
+// GetChannelSnapshots returns a channel's about-page history, oldest first.
+func (r *JobRepository) GetChannelSnapshots(channelID string) ([]domain.ChannelSnapshot, error) {
+	rows, err := r.db.Query(`
+        SELECT id, channel_id, job_id, description, follower_count, captured_at
+        FROM channel_snapshots
+        WHERE channel_id = ?
+        ORDER BY captured_at ASC, id ASC`, channelID)
+	if err != nil {
+		return nil, fmt.Errorf("get channel snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	snapshots := []domain.ChannelSnapshot{}
+	for rows.Next() {
+		var s domain.ChannelSnapshot
+		if err := rows.Scan(&s.ID, &s.ChannelID, &s.JobID, &s.Description, &s.ChannelFollowers, &s.CapturedAt); err != nil {
+			return nil, fmt.Errorf("scan channel snapshot: %w", err)
+		}
+		snapshots = append(snapshots, s)
+	}
+	return snapshots, rows.Err()
+}
+
+// HasChannelJobs reports whether any job of a source channel remains: the
+// channel itself, or one of its videos or playlists.
+func (r *JobRepository) HasChannelJobs(channelID string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`
+        SELECT EXISTS (
+            SELECT 1 FROM channels WHERE json_extract(metadata_json, '$.id') = ?1
+            UNION ALL
+            SELECT 1 FROM videos WHERE json_extract(metadata_json, '$.channel_id') = ?1
+            UNION ALL
+            SELECT 1 FROM playlists WHERE json_extract(metadata_json, '$.channel_id') = ?1
+        )`, channelID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("check channel jobs: %w", err)
+	}
+	return exists, nil
+}
@@ -0,0 +1,92 @@
+package sqlite
+
+import (
+	"testing"
+	"video-archiver/internal/testutil"
+)
+
+func TestJobRepository_ChannelSnapshots(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+
+	repo := NewJobRepository(db)
+	for _, id := range []string{"channel-1", "channel-2"} {
+		if err := repo.Create(testutil.CreateTestJob(id, "https://youtube.com/c/testchannel")); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	meta := testutil.CreateTestChannelMetadata()
+	if err := repo.StoreMetadata("channel-1", meta); err != nil {
+		t.Fatalf("StoreMetadata() error = %v", err)
+	}
+	// Storing the same state again (e.g. after async enhancement) must not
+	// add a snapshot.
+	if err := repo.StoreMetadata("channel-1", meta); err != nil {
+		t.Fatalf("StoreMetadata() error = %v", err)
+	}
+
+	// A re-sync through another job that sees a changed channel appends to
+	// the same history.
+	meta.ChannelFollowers = 12000
+	if err := repo.StoreMetadata("channel-2", meta); err != nil {
+		t.Fatalf("StoreMetadata() error = %v", err)
+	}
+
+	snapshots, err := repo.GetChannelSnapshots(meta.ID)
+	if err != nil {
+		t.Fatalf("GetChannelSnapshots() error = %v", err)
+	}
+	if len(snapshots) != 2 {
+		t.Fatalf("got %d snapshots, want 2: %+v", len(snapshots), snapshots)
+	}
+	if snapshots[0].ChannelFollowers != 10000 || snapshots[1].ChannelFollowers != 12000 {
+		t.Errorf("snapshots out of order: %+v", snapshots)
+	}
+	if snapshots[1].JobID != "channel-2" || snapshots[1].Description != meta.Description {
+		t.Errorf("unexpected latest snapshot: %+v", snapshots[1])
+	}
+
+	// Deleting a job keeps the history while another job of the channel
+	// shows it; the snapshots it recorded move to that job.
+	if err := repo.DeleteJob("channel-1"); err != nil {
+		t.Fatalf("DeleteJob() error = %v", err)
+	}
+	snapshots, err = repo.GetChannelSnapshots(meta.ID)
+	if err != nil {
+		t.Fatalf("GetChannelSnapshots() error = %v", err)
+	}
+	if len(snapshots) != 2 || snapshots[0].JobID != "channel-2" || snapshots[1].JobID != "channel-2" {
+		t.Errorf("after delete got %+v, want both snapshots kept for channel-2", snapshots)
+	}
+
+	// Deleting the last job of the channel removes the history.
+	if err := repo.DeleteJob("channel-2"); err != nil {
+		t.Fatalf("DeleteJob() error = %v", err)
+	}
+	if snapshots, _ = repo.GetChannelSnapshots(meta.ID); len(snapshots) != 0 {
+		t.Errorf("after deleting every job got %+v, want none", snapshots)
+	}
+}
+
+func TestJobRepository_ChannelSnapshotsKeptForChannelVideos(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+
+	repo := NewJobRepository(db)
+	repo.Create(testutil.CreateTestJob("channel-1", "https://youtube.com/c/testchannel"))
+	repo.Create(testutil.CreateTestJob("video-1", "https://youtube.com/watch?v=test"))
+	channel := testutil.CreateTestChannelMetadata()
+	video := testutil.CreateTestVideoMetadata()
+	video.ChannelID = channel.ID
+	repo.StoreMetadata("channel-1", channel)
+	repo.StoreMetadata("video-1", video)
+
+	if err := repo.DeleteJob("channel-1"); err != nil {
+		t.Fatalf("DeleteJob() error = %v", err)
+	}
+	snapshots, _ := repo.GetChannelSnapshots(channel.ID)
+	if len(snapshots) != 1 || snapshots[0].JobID != "video-1" {
+		t.Errorf("after deleting the channel job got %+v, want its snapshot kept for video-1", snapshots)
+	}
+}
@@ -0,0 +1,155 @@
+package sqlite
+
+import (
+	"fmt"
+	"strings"
+
+	"video-archiver/internal/domain"
+)
+
+// commentSortOrders maps the accepted sort names to ORDER BY clauses. rowid is
+// the final tie-breaker: comments are inserted in the order yt-dlp listed them.
+var commentSortOrders = map[string]string{
+	domain.CommentSortTop:    "c.is_pinned DESC, c.like_count DESC, c.rowid ASC",
+	domain.CommentSortNewest: "c.timestamp DESC, c.rowid ASC",
+	domain.CommentSortOldest: "c.timestamp ASC, c.rowid ASC",
+}
+
+const commentColumns = `c.comment_id, c.parent_id, c.author, c.author_id, c.author_is_uploader,
+               c.text, c.like_count, c.timestamp, c.is_pinned, c.is_hearted`
+
+// StoreComments replaces the archived comments of a job, so re-archiving a
+// video refreshes its comment section instead of accumulating stale copies.
+func (r *JobRepository) StoreComments(jobID string, comments []domain.Comment) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin store comments: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM comments WHERE job_id = ?`, jobID); err != nil {
+		return fmt.Errorf("clear comments: %w", err)
+	}
+
+	stmt, err := tx.Prepare(`
+        INSERT OR REPLACE INTO comments (job_id, comment_id, parent_id, author, author_id, author_is_uploader,
+                                         text, like_count, timestamp, is_pinned, is_hearted)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("prepare comment insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, c := range comments {
+		if c.ID == "" {
+			continue
+		}
+		if _, err := stmt.Exec(jobID, c.ID, c.ParentID, c.Author, c.AuthorID, c.AuthorIsUploader,
+			c.Text, c.LikeCount, c.Timestamp, c.IsPinned, c.IsHearted); err != nil {
+			return fmt.Errorf("insert comment %s: %w", c.ID, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetComments returns a page of top-level comment threads with their replies
+// attached (oldest first), plus the total number of matching threads.
+func (r *JobRepository) GetComments(jobID string, opts domain.CommentQuery) ([]domain.Comment, int, error) {
+	page, limit := opts.Page, opts.Limit
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+	orderBy, ok := commentSortOrders[opts.Sort]
+	if !ok {
+		orderBy = commentSortOrders[domain.CommentSortTop]
+	}
+
+	where := `c.job_id = ? AND c.parent_id = ''`
+	args := []any{jobID}
+	if search := strings.TrimSpace(opts.Search); search != "" {
+		pattern := "%" + escapeLike(search) + "%"
+		where += ` AND (c.text LIKE ? ESCAPE '\' OR c.author LIKE ? ESCAPE '\'
+            OR EXISTS (
+                SELECT 1 FROM comments rc
+                WHERE rc.job_id = c.job_id AND rc.parent_id = c.comment_id
+                  AND (rc.text LIKE ? ESCAPE '\' OR rc.author LIKE ? ESCAPE '\')))`
+		args = append(args, pattern, pattern, pattern, pattern)
+	}
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM comments c WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("count comments: %w", err)
+	}
+
+	rows, err := r.db.Query(`
+        SELECT `+commentColumns+`,
+               (SELECT COUNT(*) FROM comments rc WHERE rc.job_id = c.job_id AND rc.parent_id = c.comment_id)
+        FROM comments c
+        WHERE `+where+`
+        ORDER BY `+orderBy+`
+        LIMIT ? OFFSET ?`, append(args, limit, (page-1)*limit)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("get comments: %w", err)
+	}
+	defer rows.Close()
+
+	threads := []domain.Comment{}
+	for rows.Next() {
+		var c domain.Comment
+		if err := rows.Scan(&c.ID, &c.ParentID, &c.Author, &c.AuthorID, &c.AuthorIsUploader,
+			&c.Text, &c.LikeCount, &c.Timestamp, &c.IsPinned, &c.IsHearted, &c.ReplyCount); err != nil {
+			return nil, 0, fmt.Errorf("scan comment: %w", err)
+		}
+		threads = append(threads, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	if err := r.attachReplies(jobID, threads); err != nil {
+		return nil, 0, err
+	}
+	return threads, total, nil
+}
+
+// attachReplies loads the replies of a page of threads in one query.
+func (r *JobRepository) attachReplies(jobID string, threads []domain.Comment) error {
+	if len(threads) == 0 {
+		return nil
+	}
+
+	args := []any{jobID}
+	placeholders := make([]string, 0, len(threads))
+	index := make(map[string]int, len(threads))
+	for i, t := range threads {
+		args = append(args, t.ID)
+		placeholders = append(placeholders, "?")
+		index[t.ID] = i
+	}
+
+	rows, err := r.db.Query(`
+        SELECT `+commentColumns+`
+        FROM comments c
+        WHERE c.job_id = ? AND c.parent_id IN (`+strings.Join(placeholders, ",")+`)
+        ORDER BY c.timestamp ASC, c.rowid ASC`, args...)
+	if err != nil {
+		return fmt.Errorf("load replies: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c domain.Comment
+		if err := rows.Scan(&c.ID, &c.ParentID, &c.Author, &c.AuthorID, &c.AuthorIsUploader,
+			&c.Text, &c.LikeCount, &c.Timestamp, &c.IsPinned, &c.IsHearted); err != nil {
+			return fmt.Errorf("scan reply: %w", err)
+		}
+		if i, ok := index[c.ParentID]; ok {
+			threads[i].Replies = append(threads[i].Replies, c)
+		}
+	}
+	return rows.Err()
+}
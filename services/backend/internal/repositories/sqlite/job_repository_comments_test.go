@@ -0,0 +1,127 @@
+package sqlite
+
+import (
+	"testing"
+	"video-archiver/internal/domain"
+	"video-archiver/internal/testutil"
+)
+
+func seedComments(t *testing.T, repo *JobRepository) {
+	t.Helper()
+	if err := repo.Create(testutil.CreateTestJob("video-1", "https://youtube.com/watch?v=test")); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	comments := []domain.Comment{
+		{ID: "a", Author: "@alice", Text: "Great video", LikeCount: 5, Timestamp: 100},
+		{ID: "b", Author: "@bob", Text: "Pinned notice", LikeCount: 1, Timestamp: 300, IsPinned: true},
+		{ID: "c", Author: "@carol", Text: "Most liked", LikeCount: 50, Timestamp: 200},
+		{ID: "a.1", ParentID: "a", Author: "@dave", Text: "late reply about cats", Timestamp: 400},
+		{ID: "a.2", ParentID: "a", Author: "@erin", Text: "early reply", Timestamp: 150},
+	}
+	if err := repo.StoreComments("video-1", comments); err != nil {
+		t.Fatalf("StoreComments() error = %v", err)
+	}
+}
+
+func commentIDs(comments []domain.Comment) []string {
+	ids := make([]string, 0, len(comments))
+	for _, c := range comments {
+		ids = append(ids, c.ID)
+	}
+	return ids
+}
+
+func TestJobRepository_GetCommentsThreadingAndSort(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+	repo := NewJobRepository(db)
+	seedComments(t, repo)
+
+	tests := []struct {
+		sort string
+		want []string
+	}{
+		{domain.CommentSortTop, []string{"b", "c", "a"}},
+		{domain.CommentSortNewest, []string{"b", "c", "a"}},
+		{domain.CommentSortOldest, []string{"a", "c", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			threads, total, err := repo.GetComments("video-1", domain.CommentQuery{Page: 1, Limit: 10, Sort: tt.sort})
+			if err != nil {
+				t.Fatalf("GetComments() error = %v", err)
+			}
+			if total != 3 {
+				t.Errorf("total = %d, want 3", total)
+			}
+			got := commentIDs(threads)
+			if len(got) != len(tt.want) {
+				t.Fatalf("threads = %v, want %v", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Fatalf("threads = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	threads, _, err := repo.GetComments("video-1", domain.CommentQuery{Sort: domain.CommentSortOldest})
+	if err != nil {
+		t.Fatalf("GetComments() error = %v", err)
+	}
+	a := threads[0]
+	if a.ReplyCount != 2 || len(a.Replies) != 2 || a.Replies[0].ID != "a.2" || a.Replies[1].ID != "a.1" {
+		t.Errorf("replies of a = %v (count %d), want [a.2 a.1]", commentIDs(a.Replies), a.ReplyCount)
+	}
+}
+
+func TestJobRepository_GetCommentsSearchAndPaging(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+	repo := NewJobRepository(db)
+	seedComments(t, repo)
+
+	// A match inside a reply returns the whole thread.
+	threads, total, err := repo.GetComments("video-1", domain.CommentQuery{Search: "cats"})
+	if err != nil {
+		t.Fatalf("GetComments() error = %v", err)
+	}
+	if total != 1 || len(threads) != 1 || threads[0].ID != "a" || len(threads[0].Replies) != 2 {
+		t.Errorf("search result = %v (total %d), want thread a with replies", commentIDs(threads), total)
+	}
+
+	threads, total, err = repo.GetComments("video-1", domain.CommentQuery{Page: 2, Limit: 2, Sort: domain.CommentSortOldest})
+	if err != nil {
+		t.Fatalf("GetComments() error = %v", err)
+	}
+	if total != 3 || len(threads) != 1 || threads[0].ID != "b" {
+		t.Errorf("page 2 = %v (total %d), want [b]", commentIDs(threads), total)
+	}
+}
+
+func TestJobRepository_CommentsReplacedAndDeleted(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+	repo := NewJobRepository(db)
+	seedComments(t, repo)
+
+	if err := repo.StoreComments("video-1", []domain.Comment{{ID: "z", Text: "only one"}}); err != nil {
+		t.Fatalf("StoreComments() error = %v", err)
+	}
+	threads, total, err := repo.GetComments("video-1", domain.CommentQuery{})
+	if err != nil || total != 1 || threads[0].ID != "z" {
+		t.Errorf("after re-store got %v (total %d, err %v), want [z]", commentIDs(threads), total, err)
+	}
+
+	if err := repo.DeleteJob("video-1"); err != nil {
+		t.Fatalf("DeleteJob() error = %v", err)
+	}
+	var count int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM comments`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("%d comments left after DeleteJob, want 0", count)
+	}
+}
@@ -0,0 +1,186 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"video-archiver/internal/domain"
+)
+
+// recordBaselineSnapshot starts a video's engagement history with the
+// metadata seen at download time. Later stores of the same job (resolution
+// updates, re-downloads) leave the history alone; refreshes append to it
+// explicitly. Best-effort like recordChannelSnapshot.
+func (r *JobRepository) recordBaselineSnapshot(jobID string, metadata *domain.VideoMetadata) {
+	var exists int
+	err := r.db.QueryRow(`SELECT 1 FROM metadata_snapshots WHERE job_id = ? LIMIT 1`, jobID).Scan(&exists)
+	if err == nil {
+		return
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		log.WithError(err).WithField("jobID", jobID).Warn("Failed to check metadata history")
+		return
+	}
+	if err := r.RecordMetadataSnapshot(jobID, metadata); err != nil {
+		log.WithError(err).WithField("jobID", jobID).Warn("Failed to record baseline metadata snapshot")
+	}
+}
+
+// RecordMetadataSnapshot appends the video's current counters, title and
+// description to its history.
+func (r *JobRepository) RecordMetadataSnapshot(jobID string, metadata *domain.VideoMetadata) error {
+	_, err := r.db.Exec(`
+        INSERT INTO metadata_snapshots (job_id, title, description, view_count, like_count, comment_count, captured_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		jobID, metadata.Title, metadata.Description, metadata.ViewCount, metadata.LikeCount,
+		metadata.CommentCount, time.Now())
+	if err != nil {
+		return fmt.Errorf("record metadata snapshot: %w", err)
+	}
+	return nil
+}
+
+// GetMetadataSnapshots returns a video's history oldest first, flagging the
+// snapshots at which the title or description was edited upstream.
+func (r *JobRepository) GetMetadataSnapshots(jobID string) ([]domain.MetadataSnapshot, error) {
+	rows, err := r.db.Query(`
+        SELECT id, job_id, title, description, view_count, like_count, comment_count, captured_at
+        FROM metadata_snapshots
+        WHERE job_id = ?
+        ORDER BY captured_at ASC, id ASC`, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("get metadata snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	snapshots := []domain.MetadataSnapshot{}
+	for rows.Next() {
+		var s domain.MetadataSnapshot
+		if err := rows.Scan(&s.ID, &s.JobID, &s.Title, &s.Description, &s.ViewCount, &s.LikeCount,
+			&s.CommentCount, &s.CapturedAt); err != nil {
+			return nil, fmt.Errorf("scan metadata snapshot: %w", err)
+		}
+		if n := len(snapshots); n > 0 {
+			s.TitleChanged = s.Title != snapshots[n-1].Title
+			s.DescriptionChanged = s.Description != snapshots[n-1].Description
+		}
+		snapshots = append(snapshots, s)
+	}
+	return snapshots, rows.Err()
+}
+
+// SetRefreshSchedule creates or changes a job's refresh interval. The first
+// refresh of a new schedule is due immediately; changing the interval of an
+// existing one reschedules it relative to its last refresh.
+func (r *JobRepository) SetRefreshSchedule(jobID string, intervalHours int) error {
+	if intervalHours <= 0 {
+		if _, err := r.db.Exec(`DELETE FROM refresh_schedules WHERE job_id = ?`, jobID); err != nil {
+			return fmt.Errorf("delete refresh schedule: %w", err)
+		}
+		return nil
+	}
+
+	existing, err := r.GetRefreshSchedule(jobID)
+	if err != nil {
+		return err
+	}
+	next := time.Now()
+	var last *time.Time
+	if existing != nil && existing.LastRefreshedAt != nil {
+		last = existing.LastRefreshedAt
+		next = last.Add(time.Duration(intervalHours) * time.Hour)
+	}
+
+	if _, err := r.db.Exec(`
+        INSERT INTO refresh_schedules (job_id, interval_hours, last_refreshed_at, next_refresh_at)
+        VALUES (?, ?, ?, ?)
+        ON CONFLICT(job_id) DO UPDATE SET
+            interval_hours = excluded.interval_hours,
+            next_refresh_at = excluded.next_refresh_at`,
+		jobID, intervalHours, last, next); err != nil {
+		return fmt.Errorf("set refresh schedule: %w", err)
+	}
+	return nil
+}
+
+// GetRefreshSchedule returns a job's schedule, or nil when it has none.
+func (r *JobRepository) GetRefreshSchedule(jobID string) (*domain.RefreshSchedule, error) {
+	s, err := scanRefreshSchedule(r.db.QueryRow(`
+        SELECT job_id, interval_hours, last_refreshed_at, next_refresh_at, refresh_cursor
+        FROM refresh_schedules
+        WHERE job_id = ?`, jobID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get refresh schedule: %w", err)
+	}
+	return &s, nil
+}
+
+// ListRefreshSchedules returns every schedule; callers pick the due ones.
+func (r *JobRepository) ListRefreshSchedules() ([]domain.RefreshSchedule, error) {
+	rows, err := r.db.Query(`
+        SELECT job_id, interval_hours, last_refreshed_at, next_refresh_at, refresh_cursor
+        FROM refresh_schedules
+        ORDER BY job_id`)
+	if err != nil {
+		return nil, fmt.Errorf("list refresh schedules: %w", err)
+	}
+	defer rows.Close()
+
+	schedules := []domain.RefreshSchedule{}
+	for rows.Next() {
+		s, err := scanRefreshSchedule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan refresh schedule: %w", err)
+		}
+		schedules = append(schedules, s)
+	}
+	return schedules, rows.Err()
+}
+
+// MarkRefreshed records a completed refresh and schedules the next one, which
+// starts over from the first member.
+func (r *JobRepository) MarkRefreshed(jobID string, at time.Time) error {
+	schedule, err := r.GetRefreshSchedule(jobID)
+	if err != nil || schedule == nil {
+		return err
+	}
+	next := at.Add(time.Duration(schedule.IntervalHours) * time.Hour)
+	if _, err := r.db.Exec(`
+        UPDATE refresh_schedules SET last_refreshed_at = ?, next_refresh_at = ?, refresh_cursor = ''
+        WHERE job_id = ?`, at, next, jobID); err != nil {
+		return fmt.Errorf("mark refreshed: %w", err)
+	}
+	return nil
+}
+
+// SaveRefreshCursor records where an interrupted refresh resumes and when.
+func (r *JobRepository) SaveRefreshCursor(jobID, cursor string, retryAt time.Time) error {
+	if _, err := r.db.Exec(`
+        UPDATE refresh_schedules SET refresh_cursor = ?, next_refresh_at = ?
+        WHERE job_id = ?`, cursor, retryAt, jobID); err != nil {
+		return fmt.Errorf("save refresh cursor: %w", err)
+	}
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanRefreshSchedule(row rowScanner) (domain.RefreshSchedule, error) {
+	var s domain.RefreshSchedule
+	var last sql.NullTime
+	if err := row.Scan(&s.JobID, &s.IntervalHours, &last, &s.NextRefreshAt, &s.Cursor); err != nil {
+		return s, err
+	}
+	if last.Valid {
+		s.LastRefreshedAt = &last.Time
+	}
+	return s, nil
+}
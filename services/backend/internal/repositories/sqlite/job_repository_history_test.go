@@ -0,0 +1,124 @@
+package sqlite
+
+import (
+	"testing"
+	"time"
+	"video-archiver/internal/testutil"
+)
+
+func TestJobRepository_MetadataSnapshots(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+	repo := NewJobRepository(db)
+
+	job := testutil.CreateTestJob("video-1", "https://youtube.com/watch?v=test")
+	if err := repo.Create(job); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	meta := testutil.CreateTestVideoMetadata()
+	// The first store is the download-time baseline; later stores don't add to
+	// the history.
+	for i := 0; i < 2; i++ {
+		if err := repo.StoreMetadata(job.ID, meta); err != nil {
+			t.Fatalf("StoreMetadata() error = %v", err)
+		}
+	}
+
+	refreshed := *meta
+	refreshed.ViewCount += 100
+	if err := repo.RecordMetadataSnapshot(job.ID, &refreshed); err != nil {
+		t.Fatalf("RecordMetadataSnapshot() error = %v", err)
+	}
+	refreshed.Title = "Edited title"
+	if err := repo.RecordMetadataSnapshot(job.ID, &refreshed); err != nil {
+		t.Fatalf("RecordMetadataSnapshot() error = %v", err)
+	}
+
+	snapshots, err := repo.GetMetadataSnapshots(job.ID)
+	if err != nil {
+		t.Fatalf("GetMetadataSnapshots() error = %v", err)
+	}
+	if len(snapshots) != 3 {
+		t.Fatalf("got %d snapshots, want 3", len(snapshots))
+	}
+	if snapshots[0].ViewCount != meta.ViewCount || snapshots[1].ViewCount != meta.ViewCount+100 {
+		t.Errorf("view counts = %d, %d", snapshots[0].ViewCount, snapshots[1].ViewCount)
+	}
+	if snapshots[1].TitleChanged || !snapshots[2].TitleChanged || snapshots[2].DescriptionChanged {
+		t.Errorf("change flags = %+v", snapshots)
+	}
+
+	if err := repo.DeleteJob(job.ID); err != nil {
+		t.Fatalf("DeleteJob() error = %v", err)
+	}
+	if snapshots, _ := repo.GetMetadataSnapshots(job.ID); len(snapshots) != 0 {
+		t.Errorf("%d snapshots left after DeleteJob", len(snapshots))
+	}
+}
+
+func TestJobRepository_RefreshSchedules(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+	repo := NewJobRepository(db)
+
+	job := testutil.CreateTestJob("playlist-1", "https://youtube.com/playlist?list=test")
+	if err := repo.Create(job); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if s, err := repo.GetRefreshSchedule(job.ID); err != nil || s != nil {
+		t.Fatalf("GetRefreshSchedule() = %v, %v; want nil", s, err)
+	}
+
+	if err := repo.SetRefreshSchedule(job.ID, 24); err != nil {
+		t.Fatalf("SetRefreshSchedule() error = %v", err)
+	}
+	s, err := repo.GetRefreshSchedule(job.ID)
+	if err != nil || s == nil {
+		t.Fatalf("GetRefreshSchedule() = %v, %v", s, err)
+	}
+	if s.IntervalHours != 24 || s.LastRefreshedAt != nil || s.NextRefreshAt.After(time.Now()) {
+		t.Errorf("new schedule = %+v, want due now", s)
+	}
+
+	at := time.Now().Add(-time.Hour)
+	if err := repo.MarkRefreshed(job.ID, at); err != nil {
+		t.Fatalf("MarkRefreshed() error = %v", err)
+	}
+	if err := repo.SetRefreshSchedule(job.ID, 6); err != nil {
+		t.Fatalf("SetRefreshSchedule() error = %v", err)
+	}
+	schedules, err := repo.ListRefreshSchedules()
+	if err != nil || len(schedules) != 1 {
+		t.Fatalf("ListRefreshSchedules() = %v, %v", schedules, err)
+	}
+	s = &schedules[0]
+	if s.LastRefreshedAt == nil || !s.LastRefreshedAt.Equal(at) || !s.NextRefreshAt.Equal(at.Add(6*time.Hour)) {
+		t.Errorf("rescheduled = %+v, want next = last + 6h", s)
+	}
+
+	// An interrupted run keeps the last refresh and resumes from its cursor;
+	// the next complete run starts over.
+	retryAt := time.Now().Add(time.Minute).Truncate(time.Second)
+	if err := repo.SaveRefreshCursor(job.ID, "video-7", retryAt); err != nil {
+		t.Fatalf("SaveRefreshCursor() error = %v", err)
+	}
+	s, _ = repo.GetRefreshSchedule(job.ID)
+	if s.Cursor != "video-7" || !s.NextRefreshAt.Equal(retryAt) || !s.LastRefreshedAt.Equal(at) {
+		t.Errorf("interrupted schedule = %+v, want cursor video-7 due at %v", s, retryAt)
+	}
+	if err := repo.MarkRefreshed(job.ID, time.Now()); err != nil {
+		t.Fatalf("MarkRefreshed() error = %v", err)
+	}
+	if s, _ = repo.GetRefreshSchedule(job.ID); s.Cursor != "" {
+		t.Errorf("cursor %q kept after a complete refresh", s.Cursor)
+	}
+
+	if err := repo.SetRefreshSchedule(job.ID, 0); err != nil {
+		t.Fatalf("SetRefreshSchedule(0) error = %v", err)
+	}
+	if s, _ := repo.GetRefreshSchedule(job.ID); s != nil {
+		t.Errorf("schedule still present after removal: %+v", s)
+	}
+}
@@ -0,0 +1,132 @@
+package sqlite
+
+import (
+	"fmt"
+	"strings"
+
+	"video-archiver/internal/domain"
+)
+
+// tagTreeCondition matches jobs carrying the named tag or any of its
+// descendants.
+const tagTreeCondition = `EXISTS (
+            SELECT 1 FROM job_tags jt
+            WHERE jt.job_id = jobs.job_id AND jt.tag_id IN (
+                WITH RECURSIVE tag_tree(id) AS (
+                    SELECT id FROM tags WHERE name = ? COLLATE NOCASE
+                    UNION
+                    SELECT tags.id FROM tags JOIN tag_tree ON tags.parent_id = tag_tree.id
+                )
+                SELECT id FROM tag_tree))`
+
+// searchTextCondition matches free text against the search index; every
+// word matches as a prefix. Text without any words matches nothing.
+func searchTextCondition(text string) (string, []any) {
+	match := ftsQuery(text, true)
+	if match == "" {
+		return "0", nil
+	}
+	return searchCondition, []any{match}
+}
+
+// queryFilterCondition translates a parsed library query filter into a SQL
+// condition and its arguments. Only the validated table name and fixed
+// expressions are interpolated; every value is a parameter.
+func queryFilterCondition(tableName, titleColumn string, f domain.QueryFilter) (string, []any, error) {
+	metadataField := func(path string) string {
+		return `json_extract(` + tableName + `.metadata_json, '$.` + path + `')`
+	}
+
+	var condition string
+	var args []any
+	switch f.Field {
+	case domain.QueryFieldText:
+		condition, args = searchTextCondition(f.Text)
+	case domain.QueryFieldTitle:
+		condition = titleColumn + ` LIKE ? ESCAPE '\'`
+		args = []any{"%" + escapeLike(f.Text) + "%"}
+	case domain.QueryFieldChannel:
+		pattern := "%" + escapeLike(f.Text) + "%"
+		condition = `(` + metadataField("channel") + ` LIKE ? ESCAPE '\' OR ` + metadataField("uploader") + ` LIKE ? ESCAPE '\'`
+		args = []any{pattern, pattern}
+		if tableName == "channels" {
+			condition += ` OR channels.name LIKE ? ESCAPE '\'`
+			args = append(args, pattern)
+		}
+		condition += `)`
+	case domain.QueryFieldTag:
+		condition = tagTreeCondition
+		args = []any{f.Text}
+	case domain.QueryFieldStatus:
+		condition = `jobs.status = ?`
+		args = []any{f.Text}
+	case domain.QueryFieldNotes:
+		condition = annotationField("jobs.job_id", "notes") + ` LIKE ? ESCAPE '\'`
+		args = []any{"%" + escapeLike(f.Text) + "%"}
+	case domain.QueryFieldFavorite:
+		condition = annotationField("jobs.job_id", "favorite") + ` = ?`
+		args = []any{f.Number}
+	case domain.QueryFieldWatch:
+		condition = watchStateCondition(domain.WatchState(f.Text))
+	case domain.QueryFieldCodec:
+		var alternatives []string
+		for _, prefix := range domain.CodecPrefixes(f.Text) {
+			pattern := escapeLike(prefix) + "%"
+			alternatives = append(alternatives, metadataField("vcodec")+` LIKE ? ESCAPE '\'`, metadataField("acodec")+` LIKE ? ESCAPE '\'`)
+			args = append(args, pattern, pattern)
+		}
+		condition = `(` + strings.Join(alternatives, " OR ") + `)`
+	case domain.QueryFieldDuration, domain.QueryFieldResolution, domain.QueryFieldSize, domain.QueryFieldViews, domain.QueryFieldRating:
+		column := map[domain.QueryField]string{
+			domain.QueryFieldDuration:   metadataField("duration"),
+			domain.QueryFieldResolution: metadataField("height"),
+			domain.QueryFieldSize:       metadataField("filesize_approx"),
+			domain.QueryFieldViews:      metadataField("view_count"),
+			domain.QueryFieldRating:     annotationField("jobs.job_id", "rating"),
+		}[f.Field]
+		op, err := comparisonOperator(f.Op)
+		if err != nil {
+			return "", nil, err
+		}
+		condition = column + ` ` + op + ` ?`
+		args = []any{f.Number}
+	case domain.QueryFieldUploaded:
+		// upload_date is stored as YYYYMMDD, so the period bounds compare
+		// as text.
+		column := `NULLIF(` + metadataField("upload_date") + `, '')`
+		switch f.Op {
+		case domain.QueryOpMatch:
+			condition = column + ` BETWEEN ? AND ?`
+			args = []any{f.From, f.To}
+		case domain.QueryOpGreater:
+			condition, args = column+` > ?`, []any{f.To}
+		case domain.QueryOpGreaterEqual:
+			condition, args = column+` >= ?`, []any{f.From}
+		case domain.QueryOpLess:
+			condition, args = column+` < ?`, []any{f.From}
+		case domain.QueryOpLessEqual:
+			condition, args = column+` <= ?`, []any{f.To}
+		default:
+			return "", nil, fmt.Errorf("invalid operator %q", f.Op)
+		}
+	default:
+		return "", nil, fmt.Errorf("unknown filter %q", f.Field)
+	}
+
+	if f.Negate {
+		// Missing values make the condition NULL; a negated filter keeps
+		// those items.
+		condition = `NOT COALESCE(` + condition + `, 0)`
+	}
+	return condition, args, nil
+}
+
+func comparisonOperator(op domain.QueryOp) (string, error) {
+	switch op {
+	case domain.QueryOpMatch:
+		return "=", nil
+	case domain.QueryOpGreater, domain.QueryOpGreaterEqual, domain.QueryOpLess, domain.QueryOpLessEqual:
+		return string(op), nil
+	}
+	return "", fmt.Errorf("invalid operator %q", op)
+}
@@ -0,0 +1,110 @@
+package sqlite
+
+import (
+	"testing"
+
+	"video-archiver/internal/domain"
+	"video-archiver/internal/testutil"
+)
+
+func TestJobRepository_GetMetadataByTypeQueryFilters(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+	repo := NewJobRepository(db)
+
+	videos := []struct {
+		id       string
+		status   domain.JobStatus
+		channel  string
+		duration int
+		uploaded string
+		height   int
+		vcodec   string
+		size     int64
+		views    int
+	}{
+		{"short", domain.JobStatusComplete, "Cats Daily", 120, "20230105", 720, "avc1.64001F", 50 << 20, 10},
+		{"long", domain.JobStatusComplete, "Lectures", 3600, "20231120", 1080, "vp09.00.40.08", 2 << 30, 5000},
+		{"broken", domain.JobStatusError, "Lectures", 1800, "20240301", 2160, "av01.0.12M.10", 0, 300},
+	}
+	for _, v := range videos {
+		job := testutil.CreateTestJob(v.id, "https://example.com/"+v.id)
+		job.Status = v.status
+		if err := repo.Create(job); err != nil {
+			t.Fatalf("Create(%s) error = %v", v.id, err)
+		}
+		meta := testutil.CreateTestVideoMetadata()
+		meta.ID, meta.Title, meta.Channel = v.id, "Video "+v.id, v.channel
+		meta.Duration, meta.UploadDate, meta.Height = v.duration, v.uploaded, v.height
+		meta.VideoCodec, meta.FileSize, meta.ViewCount = v.vcodec, v.size, v.views
+		if err := repo.StoreMetadata(v.id, meta); err != nil {
+			t.Fatalf("StoreMetadata(%s) error = %v", v.id, err)
+		}
+	}
+	if _, err := repo.AddTagsToJob("long", []string{"Course"}, domain.TagSourceUser); err != nil {
+		t.Fatalf("AddTagsToJob() error = %v", err)
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{`channel:"lectures"`, []string{"broken", "long"}},
+		{"duration>20m", []string{"broken", "long"}},
+		{"duration>20m -status:error", []string{"long"}},
+		{"uploaded:2023", []string{"short", "long"}},
+		{"uploaded>2023", []string{"broken"}},
+		{"uploaded<2023-11", []string{"short"}},
+		{"res>=1080", []string{"broken", "long"}},
+		{"res:4k", []string{"broken"}},
+		{"codec:vp9", []string{"long"}},
+		{"codec:h264", []string{"short"}},
+		{"size>1GB", []string{"long"}},
+		{"views<=300", []string{"short", "broken"}},
+		{"tag:course", []string{"long"}},
+		{"-tag:course", []string{"short", "broken"}},
+		{"video short", []string{"short"}},
+		{"-lectures", []string{"short"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			filters, err := domain.ParseLibraryQuery(tt.query)
+			if err != nil {
+				t.Fatalf("ParseLibraryQuery() error = %v", err)
+			}
+			items, total, err := repo.GetMetadataByType("videos", domain.MetadataQuery{
+				SortBy:  "duration",
+				Order:   "asc",
+				Filters: filters,
+			})
+			if err != nil {
+				t.Fatalf("GetMetadataByType() error = %v", err)
+			}
+			var got []string
+			for _, item := range items {
+				got = append(got, item.Job.ID)
+			}
+			if total != len(tt.want) || len(got) != len(tt.want) {
+				t.Fatalf("got %v (total %d), want %v", got, total, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("got %v, want %v in duration order", got, tt.want)
+				}
+			}
+		})
+	}
+
+	sorts := map[string]string{"duration": "long", "upload_date": "broken", "views": "long", "file_size": "long", "resolution": "broken"}
+	for sortBy, first := range sorts {
+		items, _, err := repo.GetMetadataByType("videos", domain.MetadataQuery{SortBy: sortBy, Order: "desc"})
+		if err != nil || len(items) != 3 || items[0].Job.ID != first {
+			t.Errorf("sort by %s desc: first = %v (err=%v), want %s", sortBy, items, err, first)
+		}
+	}
+
+	filters, _ := domain.ParseLibraryQuery("duration>1m")
+	if _, _, err := repo.GetMetadataByType("channels", domain.MetadataQuery{Filters: filters}); err == nil {
+		t.Error("duration filter on channels: error = nil, want an error")
+	}
+}
@@ -0,0 +1,153 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"video-archiver/internal/domain"
+)
+
+// querier is satisfied by both *sql.DB and *sql.Tx.
+type querier interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// RecordPlaylistSnapshot stores the ordered item list of one sync together
+// with the counts of its diff against the previous snapshot of the source.
+func (r *JobRepository) RecordPlaylistSnapshot(jobID, sourceID string, items []domain.PlaylistEntry) (*domain.PlaylistSnapshot, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("begin playlist snapshot: %w", err)
+	}
+	defer tx.Rollback()
+
+	snapshot := &domain.PlaylistSnapshot{
+		SourceID:   sourceID,
+		JobID:      jobID,
+		ItemCount:  len(items),
+		CapturedAt: time.Now(),
+	}
+
+	var previousID int64
+	err = tx.QueryRow(`
+        SELECT id FROM playlist_snapshots
+        WHERE source_id = ?
+        ORDER BY id DESC
+        LIMIT 1`, sourceID).Scan(&previousID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return nil, fmt.Errorf("find previous playlist snapshot: %w", err)
+	default:
+		previous, err := loadSnapshotItems(tx, previousID)
+		if err != nil {
+			return nil, err
+		}
+		diff := domain.DiffPlaylistEntries(previous, items)
+		snapshot.AddedCount = len(diff.Added)
+		snapshot.RemovedCount = len(diff.Removed)
+		snapshot.RenamedCount = len(diff.Renamed)
+		snapshot.ReorderedCount = len(diff.Reordered)
+	}
+
+	result, err := tx.Exec(`
+        INSERT INTO playlist_snapshots (source_id, job_id, item_count, added_count, removed_count,
+                                        renamed_count, reordered_count, captured_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		snapshot.SourceID, snapshot.JobID, snapshot.ItemCount, snapshot.AddedCount, snapshot.RemovedCount,
+		snapshot.RenamedCount, snapshot.ReorderedCount, snapshot.CapturedAt)
+	if err != nil {
+		return nil, fmt.Errorf("insert playlist snapshot: %w", err)
+	}
+	if snapshot.ID, err = result.LastInsertId(); err != nil {
+		return nil, fmt.Errorf("playlist snapshot id: %w", err)
+	}
+
+	stmt, err := tx.Prepare(`
+        INSERT INTO playlist_snapshot_items (snapshot_id, position, video_id, title)
+        VALUES (?, ?, ?, ?)`)
+	if err != nil {
+		return nil, fmt.Errorf("prepare snapshot item insert: %w", err)
+	}
+	defer stmt.Close()
+	for _, item := range items {
+		if _, err := stmt.Exec(snapshot.ID, item.Position, item.VideoID, item.Title); err != nil {
+			return nil, fmt.Errorf("insert snapshot item %s: %w", item.VideoID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit playlist snapshot: %w", err)
+	}
+	return snapshot, nil
+}
+
+// GetPlaylistSnapshots returns a source's snapshots without their items,
+// oldest first.
+func (r *JobRepository) GetPlaylistSnapshots(sourceID string) ([]domain.PlaylistSnapshot, error) {
+	rows, err := r.db.Query(`
+        SELECT id, source_id, job_id, item_count, added_count, removed_count,
+               renamed_count, reordered_count, captured_at
+        FROM playlist_snapshots
+        WHERE source_id = ?
+        ORDER BY id ASC`, sourceID)
+	if err != nil {
+		return nil, fmt.Errorf("get playlist snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	snapshots := []domain.PlaylistSnapshot{}
+	for rows.Next() {
+		var s domain.PlaylistSnapshot
+		if err := rows.Scan(&s.ID, &s.SourceID, &s.JobID, &s.ItemCount, &s.AddedCount, &s.RemovedCount,
+			&s.RenamedCount, &s.ReorderedCount, &s.CapturedAt); err != nil {
+			return nil, fmt.Errorf("scan playlist snapshot: %w", err)
+		}
+		snapshots = append(snapshots, s)
+	}
+	return snapshots, rows.Err()
+}
+
+// GetPlaylistSnapshot returns one snapshot with its items in playlist order,
+// or sql.ErrNoRows (wrapped) when it does not exist.
+func (r *JobRepository) GetPlaylistSnapshot(id int64) (*domain.PlaylistSnapshot, error) {
+	var s domain.PlaylistSnapshot
+	err := r.db.QueryRow(`
+        SELECT id, source_id, job_id, item_count, added_count, removed_count,
+               renamed_count, reordered_count, captured_at
+        FROM playlist_snapshots
+        WHERE id = ?`, id).Scan(&s.ID, &s.SourceID, &s.JobID, &s.ItemCount, &s.AddedCount,
+		&s.RemovedCount, &s.RenamedCount, &s.ReorderedCount, &s.CapturedAt)
+	if err != nil {
+		return nil, fmt.Errorf("get playlist snapshot %d: %w", id, err)
+	}
+
+	if s.Items, err = loadSnapshotItems(r.db, id); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func loadSnapshotItems(q querier, snapshotID int64) ([]domain.PlaylistEntry, error) {
+	rows, err := q.Query(`
+        SELECT position, video_id, title
+        FROM playlist_snapshot_items
+        WHERE snapshot_id = ?
+        ORDER BY position ASC`, snapshotID)
+	if err != nil {
+		return nil, fmt.Errorf("load snapshot items: %w", err)
+	}
+	defer rows.Close()
+
+	items := []domain.PlaylistEntry{}
+	for rows.Next() {
+		var e domain.PlaylistEntry
+		if err := rows.Scan(&e.Position, &e.VideoID, &e.Title); err != nil {
+			return nil, fmt.Errorf("scan snapshot item: %w", err)
+		}
+		items = append(items, e)
+	}
+	return items, rows.Err()
+}
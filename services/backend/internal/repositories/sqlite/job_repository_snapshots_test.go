@@ -0,0 +1,111 @@
+package sqlite
+
+import (
+	"testing"
+	"video-archiver/internal/domain"
+	"video-archiver/internal/testutil"
+)
+
+func TestJobRepository_PlaylistSnapshots(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+	repo := NewJobRepository(db)
+
+	for _, id := range []string{"sync-1", "sync-2"} {
+		if err := repo.Create(testutil.CreateTestJob(id, "https://youtube.com/playlist?list=PL1")); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+	}
+
+	first, err := repo.RecordPlaylistSnapshot("sync-1", "PL1", []domain.PlaylistEntry{
+		{VideoID: "a", Title: "A", Position: 1},
+		{VideoID: "b", Title: "B", Position: 2},
+		{VideoID: "c", Title: "C", Position: 3},
+	})
+	if err != nil {
+		t.Fatalf("RecordPlaylistSnapshot() error = %v", err)
+	}
+	if first.ItemCount != 3 || first.AddedCount != 0 {
+		t.Errorf("first snapshot = %+v, want 3 items and no diff counts", first)
+	}
+
+	// "b" removed, "c" renamed and moved to the top, "d" added.
+	second, err := repo.RecordPlaylistSnapshot("sync-2", "PL1", []domain.PlaylistEntry{
+		{VideoID: "c", Title: "C (edited)", Position: 1},
+		{VideoID: "a", Title: "A", Position: 2},
+		{VideoID: "d", Title: "D", Position: 3},
+	})
+	if err != nil {
+		t.Fatalf("RecordPlaylistSnapshot() error = %v", err)
+	}
+	if second.AddedCount != 1 || second.RemovedCount != 1 || second.RenamedCount != 1 || second.ReorderedCount != 1 {
+		t.Errorf("second snapshot counts = %+v", second)
+	}
+
+	snapshots, err := repo.GetPlaylistSnapshots("PL1")
+	if err != nil {
+		t.Fatalf("GetPlaylistSnapshots() error = %v", err)
+	}
+	if len(snapshots) != 2 || snapshots[0].ID != first.ID || snapshots[1].JobID != "sync-2" {
+		t.Fatalf("GetPlaylistSnapshots() = %+v", snapshots)
+	}
+	if snapshots[0].Items != nil {
+		t.Errorf("listed snapshots should not include items")
+	}
+
+	got, err := repo.GetPlaylistSnapshot(second.ID)
+	if err != nil {
+		t.Fatalf("GetPlaylistSnapshot() error = %v", err)
+	}
+	if len(got.Items) != 3 || got.Items[0].VideoID != "c" || got.Items[0].Title != "C (edited)" {
+		t.Errorf("GetPlaylistSnapshot() items = %+v", got.Items)
+	}
+	if _, err := repo.GetPlaylistSnapshot(999); err == nil {
+		t.Errorf("GetPlaylistSnapshot() of missing id should fail")
+	}
+
+	if err := repo.DeleteJob("sync-2"); err != nil {
+		t.Fatalf("DeleteJob() error = %v", err)
+	}
+	if snapshots, _ := repo.GetPlaylistSnapshots("PL1"); len(snapshots) != 1 {
+		t.Errorf("%d snapshots left after DeleteJob, want 1", len(snapshots))
+	}
+	var items int
+	db.QueryRow("SELECT COUNT(*) FROM playlist_snapshot_items").Scan(&items)
+	if items != 3 {
+		t.Errorf("%d snapshot items left after DeleteJob, want 3", items)
+	}
+}
+
+func TestJobRepository_GetVideosForParentUsesSnapshotOrder(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+	repo := NewJobRepository(db)
+
+	repo.Create(testutil.CreateTestJob("parent-id", "https://youtube.com/playlist?list=PL1"))
+	for _, id := range []string{"v1", "v2", "v3"} {
+		repo.Create(testutil.CreateTestJob(id, "https://youtube.com/watch?v="+id))
+		repo.StoreMetadata(id, testutil.CreateTestVideoMetadata())
+		repo.AddVideoToParent(id, "parent-id", "playlist")
+	}
+
+	// v1 is not in the snapshot and goes last.
+	if _, err := repo.RecordPlaylistSnapshot("parent-id", "PL1", []domain.PlaylistEntry{
+		{VideoID: "v3", Position: 1},
+		{VideoID: "v2", Position: 2},
+	}); err != nil {
+		t.Fatalf("RecordPlaylistSnapshot() error = %v", err)
+	}
+
+	videos, err := repo.GetVideosForParent("parent-id")
+	if err != nil {
+		t.Fatalf("GetVideosForParent() error = %v", err)
+	}
+	var order []string
+	for _, v := range videos {
+		order = append(order, v.Job.ID)
+	}
+	if len(order) != 3 || order[0] != "v3" || order[1] != "v2" || order[2] != "v1" {
+		t.Errorf("GetVideosForParent() order = %v, want [v3 v2 v1]", order)
+	}
+}
@@ -0,0 +1,324 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"video-archiver/internal/domain"
+)
+
+const tagRuleSelect = `
+    SELECT id, name, enabled, match_json, tags_json, collection_id, created_at, updated_at
+    FROM tag_rules`
+
+func scanTagRule(row rowScanner) (*domain.TagRule, error) {
+	rule := &domain.TagRule{}
+	var matchJSON, tagsJSON string
+	err := row.Scan(&rule.ID, &rule.Name, &rule.Enabled, &matchJSON, &tagsJSON, &rule.CollectionID,
+		&rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(matchJSON), &rule.Match); err != nil {
+		return nil, fmt.Errorf("unmarshal tag rule match: %w", err)
+	}
+	if err := json.Unmarshal([]byte(tagsJSON), &rule.Tags); err != nil {
+		return nil, fmt.Errorf("unmarshal tag rule tags: %w", err)
+	}
+	return rule, nil
+}
+
+func (r *JobRepository) ListTagRules() ([]domain.TagRule, error) {
+	rows, err := r.db.Query(tagRuleSelect + ` ORDER BY id ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("list tag rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []domain.TagRule{}
+	for rows.Next() {
+		rule, err := scanTagRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan tag rule: %w", err)
+		}
+		rules = append(rules, *rule)
+	}
+	return rules, rows.Err()
+}
+
+func (r *JobRepository) GetTagRule(id int64) (*domain.TagRule, error) {
+	rule, err := scanTagRule(r.db.QueryRow(tagRuleSelect+` WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get tag rule: %w", err)
+	}
+	return rule, nil
+}
+
+// CreateTagRule inserts a rule and sets its ID and timestamps.
+func (r *JobRepository) CreateTagRule(rule *domain.TagRule) error {
+	matchJSON, tagsJSON, err := marshalTagRule(rule)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	rule.CreatedAt, rule.UpdatedAt = now, now
+	res, err := r.db.Exec(`
+        INSERT INTO tag_rules (name, enabled, match_json, tags_json, collection_id, created_at, updated_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		rule.Name, rule.Enabled, matchJSON, tagsJSON, rule.CollectionID, rule.CreatedAt, rule.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("create tag rule: %w", err)
+	}
+	if rule.ID, err = res.LastInsertId(); err != nil {
+		return fmt.Errorf("tag rule id: %w", err)
+	}
+	return nil
+}
+
+func (r *JobRepository) UpdateTagRule(rule *domain.TagRule) error {
+	matchJSON, tagsJSON, err := marshalTagRule(rule)
+	if err != nil {
+		return err
+	}
+	rule.UpdatedAt = time.Now()
+	res, err := r.db.Exec(`
+        UPDATE tag_rules
+        SET name = ?, enabled = ?, match_json = ?, tags_json = ?, collection_id = ?, updated_at = ?
+        WHERE id = ?`,
+		rule.Name, rule.Enabled, matchJSON, tagsJSON, rule.CollectionID, rule.UpdatedAt, rule.ID)
+	if err != nil {
+		return fmt.Errorf("update tag rule: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("tag rule not found")
+	}
+	return nil
+}
+
+// DeleteTagRule removes a rule. Tags it attached stay until the rules are
+// re-applied.
+func (r *JobRepository) DeleteTagRule(id int64) error {
+	if _, err := r.db.Exec(`DELETE FROM tag_rules WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("delete tag rule: %w", err)
+	}
+	return nil
+}
+
+func marshalTagRule(rule *domain.TagRule) (string, string, error) {
+	matchJSON, err := json.Marshal(rule.Match)
+	if err != nil {
+		return "", "", fmt.Errorf("marshal tag rule match: %w", err)
+	}
+	tags := rule.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	tagsJSON, err := json.Marshal(tags)
+	if err != nil {
+		return "", "", fmt.Errorf("marshal tag rule tags: %w", err)
+	}
+	return string(matchJSON), string(tagsJSON), nil
+}
+
+func (r *JobRepository) GetTagVocabulary() (*domain.TagVocabulary, error) {
+	vocabulary := &domain.TagVocabulary{Synonyms: []domain.TagSynonym{}, Blocked: []string{}}
+
+	rows, err := r.db.Query(`SELECT alias, canonical FROM tag_synonyms ORDER BY alias COLLATE NOCASE`)
+	if err != nil {
+		return nil, fmt.Errorf("list tag synonyms: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var s domain.TagSynonym
+		if err := rows.Scan(&s.Alias, &s.Canonical); err != nil {
+			return nil, fmt.Errorf("scan tag synonym: %w", err)
+		}
+		vocabulary.Synonyms = append(vocabulary.Synonyms, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	blocked, err := r.db.Query(`SELECT name FROM tag_blocklist ORDER BY name COLLATE NOCASE`)
+	if err != nil {
+		return nil, fmt.Errorf("list blocked tags: %w", err)
+	}
+	defer blocked.Close()
+	for blocked.Next() {
+		var name string
+		if err := blocked.Scan(&name); err != nil {
+			return nil, fmt.Errorf("scan blocked tag: %w", err)
+		}
+		vocabulary.Blocked = append(vocabulary.Blocked, name)
+	}
+	return vocabulary, blocked.Err()
+}
+
+// SetTagSynonym maps alias to canonical, replacing any existing mapping of
+// alias. Aliases match case-insensitively.
+func (r *JobRepository) SetTagSynonym(alias, canonical string) error {
+	_, err := r.db.Exec(`
+        INSERT INTO tag_synonyms (alias, canonical) VALUES (?, ?)
+        ON CONFLICT(alias) DO UPDATE SET canonical = excluded.canonical`,
+		domain.NormalizeTagName(alias), domain.NormalizeTagName(canonical))
+	if err != nil {
+		return fmt.Errorf("set tag synonym: %w", err)
+	}
+	return nil
+}
+
+func (r *JobRepository) DeleteTagSynonym(alias string) error {
+	if _, err := r.db.Exec(`DELETE FROM tag_synonyms WHERE alias = ?`, domain.NormalizeTagName(alias)); err != nil {
+		return fmt.Errorf("delete tag synonym: %w", err)
+	}
+	return nil
+}
+
+func (r *JobRepository) BlockTag(name string) error {
+	if _, err := r.db.Exec(`INSERT OR IGNORE INTO tag_blocklist (name) VALUES (?)`, domain.NormalizeTagName(name)); err != nil {
+		return fmt.Errorf("block tag: %w", err)
+	}
+	return nil
+}
+
+func (r *JobRepository) UnblockTag(name string) error {
+	if _, err := r.db.Exec(`DELETE FROM tag_blocklist WHERE name = ?`, domain.NormalizeTagName(name)); err != nil {
+		return fmt.Errorf("unblock tag: %w", err)
+	}
+	return nil
+}
+
+// tagEngine loads the current rules and vocabulary.
+func (r *JobRepository) tagEngine() (*domain.TagEngine, error) {
+	rules, err := r.ListTagRules()
+	if err != nil {
+		return nil, err
+	}
+	vocabulary, err := r.GetTagVocabulary()
+	if err != nil {
+		return nil, err
+	}
+	return domain.NewTagEngine(rules, vocabulary), nil
+}
+
+// ReapplyTagRules brings every item's derived tags in line with the current
+// auto-tagging, rules and vocabulary: missing ones are attached and auto or
+// rule tags that no longer apply are detached. User tags are left alone, and
+// videos are never removed from collections. Like BackfillAutoTags it is
+// idempotent.
+func (r *JobRepository) ReapplyTagRules() (*domain.TagRuleRun, error) {
+	engine, err := r.tagEngine()
+	if err != nil {
+		return nil, fmt.Errorf("load tag rules: %w", err)
+	}
+	jobs, err := r.GetAllJobsWithMetadata()
+	if err != nil {
+		return nil, fmt.Errorf("load jobs for tag rules: %w", err)
+	}
+
+	run := &domain.TagRuleRun{}
+	for _, jwm := range jobs {
+		if jwm == nil || jwm.Job == nil || jwm.Metadata == nil {
+			continue
+		}
+		run.Items++
+		if err := r.syncDerivedTags(engine, jwm.Job.ID, jwm.Metadata, run); err != nil {
+			log.WithError(err).WithField("jobID", jwm.Job.ID).Warn("Failed to re-apply tag rules")
+		}
+	}
+
+	if _, err := r.db.Exec(pruneOrphanTags); err != nil {
+		return nil, fmt.Errorf("prune orphan tags: %w", err)
+	}
+	if err := rebuildSearchIndex(r.db); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// syncDerivedTags makes one item's auto and rule tags match what engine
+// derives, counting the changes into run.
+func (r *JobRepository) syncDerivedTags(engine *domain.TagEngine, jobID string, metadata domain.Metadata, run *domain.TagRuleRun) error {
+	current, err := r.GetTagsForJob(jobID)
+	if err != nil {
+		return err
+	}
+	autoTags := engine.AutoTags(metadata)
+	ruleTags, collectionIDs := engine.Evaluate(metadata)
+	want := map[string][]string{domain.TagSourceAuto: autoTags, domain.TagSourceRule: ruleTags}
+
+	for _, tag := range current {
+		names, derived := want[tag.Source]
+		if !derived || containsFold(names, tag.Name) {
+			continue
+		}
+		if _, err := r.db.Exec(`DELETE FROM job_tags WHERE job_id = ? AND tag_id = ?`, jobID, tag.ID); err != nil {
+			return fmt.Errorf("detach tag %q: %w", tag.Name, err)
+		}
+		run.TagsRemoved++
+	}
+
+	for _, source := range []string{domain.TagSourceAuto, domain.TagSourceRule} {
+		added, err := r.attachJobTags(jobID, want[source], source)
+		if err != nil {
+			return err
+		}
+		run.TagsAdded += added
+	}
+	for _, collectionID := range collectionIDs {
+		added, err := r.addToRuleCollection(collectionID, jobID)
+		if err != nil {
+			return err
+		}
+		if added {
+			run.CollectionsAdded++
+		}
+	}
+	return nil
+}
+
+// addToRuleCollection appends a video to a rule's collection unless it is
+// already a member. A collection that no longer exists, or that is a smart
+// collection, is skipped.
+func (r *JobRepository) addToRuleCollection(collectionID, videoJobID string) (bool, error) {
+	var exists int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM collections WHERE id = ? AND query = ''`, collectionID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("check collection %s: %w", collectionID, err)
+	}
+	if exists == 0 {
+		return false, nil
+	}
+
+	res, err := r.db.Exec(`
+        INSERT OR IGNORE INTO collection_videos (collection_id, video_job_id, position)
+        SELECT ?, ?, COALESCE(MAX(position), 0) + 1 FROM collection_videos WHERE collection_id = ?`,
+		collectionID, videoJobID, collectionID)
+	if err != nil {
+		return false, fmt.Errorf("add video to collection %s: %w", collectionID, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil || n == 0 {
+		return false, err
+	}
+	if _, err := r.db.Exec(`UPDATE collections SET updated_at = ? WHERE id = ?`, time.Now(), collectionID); err != nil {
+		return true, fmt.Errorf("touch collection: %w", err)
+	}
+	return true, nil
+}
+
+func containsFold(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
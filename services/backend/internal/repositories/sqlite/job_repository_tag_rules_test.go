@@ -0,0 +1,228 @@
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"video-archiver/internal/domain"
+	"video-archiver/internal/testutil"
+)
+
+func TestJobRepository_TagRuleCRUD(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+	repo := NewJobRepository(db)
+
+	rule := &domain.TagRule{Name: "courses", Enabled: true, Match: domain.TagRuleMatch{TitlePattern: `lecture \d+`}, Tags: []string{"course"}}
+	if err := repo.CreateTagRule(rule); err != nil {
+		t.Fatalf("CreateTagRule() error = %v", err)
+	}
+	if rule.ID == 0 {
+		t.Fatal("CreateTagRule() did not set the ID")
+	}
+
+	rule.Enabled = false
+	rule.Match.Channel = "uni"
+	if err := repo.UpdateTagRule(rule); err != nil {
+		t.Fatalf("UpdateTagRule() error = %v", err)
+	}
+	got, err := repo.GetTagRule(rule.ID)
+	if err != nil || got == nil {
+		t.Fatalf("GetTagRule() = %v, %v", got, err)
+	}
+	if got.Enabled || got.Match.Channel != "uni" || got.Match.TitlePattern != `lecture \d+` || len(got.Tags) != 1 {
+		t.Errorf("GetTagRule() = %+v", got)
+	}
+
+	if err := repo.DeleteTagRule(rule.ID); err != nil {
+		t.Fatalf("DeleteTagRule() error = %v", err)
+	}
+	if got, _ := repo.GetTagRule(rule.ID); got != nil {
+		t.Errorf("rule still present after delete: %+v", got)
+	}
+	if err := repo.UpdateTagRule(rule); err == nil {
+		t.Error("UpdateTagRule() on a deleted rule should fail")
+	}
+}
+
+func TestJobRepository_TagVocabulary(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+	repo := NewJobRepository(db)
+
+	repo.SetTagSynonym("js", "JS")
+	repo.SetTagSynonym("JS", "JavaScript") // replaces, aliases are case-insensitive
+	repo.BlockTag("subscribe")
+	repo.BlockTag("Subscribe")
+
+	vocabulary, err := repo.GetTagVocabulary()
+	if err != nil {
+		t.Fatalf("GetTagVocabulary() error = %v", err)
+	}
+	if len(vocabulary.Synonyms) != 1 || vocabulary.Synonyms[0].Canonical != "JavaScript" {
+		t.Errorf("synonyms = %+v", vocabulary.Synonyms)
+	}
+	if len(vocabulary.Blocked) != 1 {
+		t.Errorf("blocked = %v", vocabulary.Blocked)
+	}
+
+	repo.DeleteTagSynonym("JS")
+	repo.UnblockTag("SUBSCRIBE")
+	vocabulary, _ = repo.GetTagVocabulary()
+	if len(vocabulary.Synonyms) != 0 || len(vocabulary.Blocked) != 0 {
+		t.Errorf("vocabulary not cleared: %+v", vocabulary)
+	}
+}
+
+func TestJobRepository_TagRulesOnStoreMetadata(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+	repo := NewJobRepository(db)
+	collections := NewCollectionRepository(db)
+
+	now := time.Now()
+	collections.Create(&domain.Collection{ID: "cs101", Name: "CS101", CreatedAt: now, UpdatedAt: now})
+	repo.CreateTagRule(&domain.TagRule{
+		Name: "tests", Enabled: true, Match: domain.TagRuleMatch{TitlePattern: `^test`, MaxDuration: 600},
+		Tags: []string{"testing"}, CollectionID: "cs101",
+	})
+	repo.BlockTag("tag1")
+
+	repo.Create(testutil.CreateTestJob("job-1", "https://youtube.com/watch?v=test"))
+	if err := repo.StoreMetadata("job-1", testutil.CreateTestVideoMetadata()); err != nil {
+		t.Fatalf("StoreMetadata() error = %v", err)
+	}
+
+	tags, _ := repo.GetTagsForJob("job-1")
+	if containsName(tags, "tag1") {
+		t.Errorf("blocked tag attached: %v", tagNames(tags))
+	}
+	found := false
+	for _, tag := range tags {
+		if tag.Name == "testing" {
+			found = true
+			if tag.Source != domain.TagSourceRule {
+				t.Errorf("rule tag source = %q, want rule", tag.Source)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("rule tag missing: %v", tagNames(tags))
+	}
+
+	ids, err := collections.ListForVideo("job-1")
+	if err != nil || len(ids) != 1 || ids[0] != "cs101" {
+		t.Errorf("ListForVideo() = %v, %v; want [cs101]", ids, err)
+	}
+}
+
+func TestJobRepository_BackfillKeepsRemovedCollectionMembersOut(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+	repo := NewJobRepository(db)
+	collections := NewCollectionRepository(db)
+
+	now := time.Now()
+	collections.Create(&domain.Collection{ID: "cs101", Name: "CS101", CreatedAt: now, UpdatedAt: now})
+	repo.CreateTagRule(&domain.TagRule{
+		Name: "tests", Enabled: true, Match: domain.TagRuleMatch{TitlePattern: `^test`},
+		Tags: []string{"testing"}, CollectionID: "cs101",
+	})
+	repo.Create(testutil.CreateTestJob("job-1", "https://youtube.com/watch?v=test"))
+	repo.StoreMetadata("job-1", testutil.CreateTestVideoMetadata())
+
+	if err := collections.RemoveVideo("cs101", "job-1"); err != nil {
+		t.Fatalf("RemoveVideo() error = %v", err)
+	}
+	// As at every startup.
+	if err := repo.BackfillAutoTags(); err != nil {
+		t.Fatalf("BackfillAutoTags() error = %v", err)
+	}
+
+	if ids, _ := collections.ListForVideo("job-1"); len(ids) != 0 {
+		t.Errorf("ListForVideo() = %v, want the removed video to stay out", ids)
+	}
+	if tags, _ := repo.GetTagsForJob("job-1"); !containsName(tags, "testing") {
+		t.Errorf("backfill dropped the rule tag: %v", tagNames(tags))
+	}
+}
+
+func TestJobRepository_StoreMetadataAgainKeepsRemovedCollectionMembersOut(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+	repo := NewJobRepository(db)
+	collections := NewCollectionRepository(db)
+
+	now := time.Now()
+	collections.Create(&domain.Collection{ID: "cs101", Name: "CS101", CreatedAt: now, UpdatedAt: now})
+	repo.CreateTagRule(&domain.TagRule{
+		Name: "tests", Enabled: true, Match: domain.TagRuleMatch{TitlePattern: `^test`},
+		Tags: []string{"testing"}, CollectionID: "cs101",
+	})
+	repo.Create(testutil.CreateTestJob("job-1", "https://youtube.com/watch?v=test"))
+	repo.StoreMetadata("job-1", testutil.CreateTestVideoMetadata())
+
+	if err := collections.RemoveVideo("cs101", "job-1"); err != nil {
+		t.Fatalf("RemoveVideo() error = %v", err)
+	}
+	// As on a metadata refresh or a format upgrade.
+	if err := repo.StoreMetadata("job-1", testutil.CreateTestVideoMetadata()); err != nil {
+		t.Fatalf("StoreMetadata() error = %v", err)
+	}
+
+	if ids, _ := collections.ListForVideo("job-1"); len(ids) != 0 {
+		t.Errorf("ListForVideo() = %v, want the removed video to stay out", ids)
+	}
+	if tags, _ := repo.GetTagsForJob("job-1"); !containsName(tags, "testing") {
+		t.Errorf("second store dropped the rule tag: %v", tagNames(tags))
+	}
+}
+
+func TestJobRepository_ReapplyTagRules(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+	repo := NewJobRepository(db)
+
+	repo.Create(testutil.CreateTestJob("job-1", "https://youtube.com/watch?v=test"))
+	repo.StoreMetadata("job-1", testutil.CreateTestVideoMetadata())
+	repo.AddTagsToJob("job-1", []string{"tag2"}, domain.TagSourceUser)
+	repo.AddTagsToJob("job-1", []string{"keep-me"}, domain.TagSourceUser)
+
+	// Rules and vocabulary added after the fact only take effect on re-apply.
+	repo.CreateTagRule(&domain.TagRule{Name: "channel", Enabled: true, Match: domain.TagRuleMatch{Channel: "test-channel-id"}, Tags: []string{"followed"}})
+	repo.BlockTag("tag1")
+	repo.SetTagSynonym("Entertainment", "Fun")
+
+	run, err := repo.ReapplyTagRules()
+	if err != nil {
+		t.Fatalf("ReapplyTagRules() error = %v", err)
+	}
+	if run.Items != 1 || run.TagsAdded == 0 || run.TagsRemoved == 0 {
+		t.Errorf("run = %+v", run)
+	}
+
+	tags, _ := repo.GetTagsForJob("job-1")
+	for _, want := range []string{"followed", "Fun", "keep-me", "Test Channel"} {
+		if !containsName(tags, want) {
+			t.Errorf("tags missing %q: %v", want, tagNames(tags))
+		}
+	}
+	for _, gone := range []string{"tag1", "Entertainment"} {
+		if containsName(tags, gone) {
+			t.Errorf("tag %q should have been removed: %v", gone, tagNames(tags))
+		}
+	}
+
+	// Re-applying again changes nothing.
+	run, _ = repo.ReapplyTagRules()
+	if run.TagsAdded != 0 || run.TagsRemoved != 0 {
+		t.Errorf("second run = %+v, want no changes", run)
+	}
+
+	all, _ := repo.ListTags()
+	for _, tag := range all {
+		if tag.Name == "tag1" {
+			t.Error("orphaned tag tag1 was not pruned")
+		}
+	}
+}
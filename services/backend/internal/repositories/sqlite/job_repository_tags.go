@@ -2,6 +2,7 @@ package sqlite
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -13,7 +14,7 @@ import (
 // used first.
 func (r *JobRepository) ListTags() ([]domain.Tag, error) {
 	rows, err := r.db.Query(`
-        SELECT t.id, t.name, COUNT(jt.job_id) as usage_count
+        SELECT t.id, t.name, t.color, t.parent_id, COUNT(jt.job_id) as usage_count
         FROM tags t
         LEFT JOIN job_tags jt ON jt.tag_id = t.id
         GROUP BY t.id, t.name
@@ -26,17 +27,256 @@ func (r *JobRepository) ListTags() ([]domain.Tag, error) {
 	tags := []domain.Tag{}
 	for rows.Next() {
 		var tag domain.Tag
-		if err := rows.Scan(&tag.ID, &tag.Name, &tag.Count); err != nil {
+		var parentID sql.NullInt64
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.Color, &parentID, &tag.Count); err != nil {
 			return nil, fmt.Errorf("scan tag: %w", err)
 		}
+		tag.ParentID = nullableTagID(parentID)
 		tags = append(tags, tag)
 	}
 	return tags, rows.Err()
 }
 
+func nullableTagID(id sql.NullInt64) *int64 {
+	if !id.Valid {
+		return nil
+	}
+	return &id.Int64
+}
+
+func (r *JobRepository) GetTag(id int64) (*domain.Tag, error) {
+	return r.getTag(`t.id = ?`, id)
+}
+
+// GetTagByName looks a tag up case-insensitively.
+func (r *JobRepository) GetTagByName(name string) (*domain.Tag, error) {
+	return r.getTag(`t.name = ? COLLATE NOCASE`, domain.NormalizeTagName(name))
+}
+
+func (r *JobRepository) getTag(condition string, arg any) (*domain.Tag, error) {
+	var tag domain.Tag
+	var parentID sql.NullInt64
+	err := r.db.QueryRow(`
+        SELECT t.id, t.name, t.color, t.parent_id,
+               (SELECT COUNT(*) FROM job_tags jt WHERE jt.tag_id = t.id)
+        FROM tags t
+        WHERE `+condition, arg).Scan(&tag.ID, &tag.Name, &tag.Color, &parentID, &tag.Count)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get tag: %w", err)
+	}
+	tag.ParentID = nullableTagID(parentID)
+	return &tag, nil
+}
+
+// UpdateTag saves a tag's name, color and parent. A rename records the old
+// name as a synonym of the new one so auto-tagging and the tag rules keep
+// deriving the new name instead of recreating the old one.
+func (r *JobRepository) UpdateTag(tag *domain.Tag) error {
+	tag.Name = domain.NormalizeTagName(tag.Name)
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin tag update: %w", err)
+	}
+	defer tx.Rollback()
+
+	var oldName string
+	if err := tx.QueryRow(`SELECT name FROM tags WHERE id = ?`, tag.ID).Scan(&oldName); err != nil {
+		return fmt.Errorf("get tag %d: %w", tag.ID, err)
+	}
+	if _, err := tx.Exec(`UPDATE tags SET name = ?, color = ?, parent_id = ? WHERE id = ?`,
+		tag.Name, tag.Color, tag.ParentID, tag.ID); err != nil {
+		return fmt.Errorf("update tag: %w", err)
+	}
+	if !strings.EqualFold(oldName, tag.Name) {
+		if err := redirectTagName(tx, oldName, tag.Name); err != nil {
+			return err
+		}
+	}
+	if oldName != tag.Name {
+		if err := reindexSearch(tx, `job_id IN (SELECT job_id FROM job_tags WHERE tag_id = ?)`, tag.ID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// MergeTags folds the source tags into the target. Every item carrying a
+// source tag ends up carrying the target with the same source; where an item
+// already carried both, the stronger source wins (user over rule over auto).
+// Children of merged tags move under the target, a target nested below a
+// merged tag moves up to that tag's parent, and the merged names become
+// synonyms of the target.
+func (r *JobRepository) MergeTags(targetID int64, sourceIDs []int64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin tag merge: %w", err)
+	}
+	defer tx.Rollback()
+
+	var targetName string
+	if err := tx.QueryRow(`SELECT name FROM tags WHERE id = ?`, targetID).Scan(&targetName); err != nil {
+		return fmt.Errorf("get merge target %d: %w", targetID, err)
+	}
+
+	merged := make(map[int64]bool, len(sourceIDs))
+	for _, sourceID := range sourceIDs {
+		if sourceID == targetID || merged[sourceID] {
+			continue
+		}
+		merged[sourceID] = true
+		var sourceName string
+		if err := tx.QueryRow(`SELECT name FROM tags WHERE id = ?`, sourceID).Scan(&sourceName); err != nil {
+			return fmt.Errorf("get merged tag %d: %w", sourceID, err)
+		}
+
+		if _, err := tx.Exec(`
+            INSERT INTO job_tags (job_id, tag_id, source, created_at)
+            SELECT job_id, ?, source, created_at FROM job_tags WHERE tag_id = ?
+            ON CONFLICT(job_id, tag_id) DO UPDATE SET source = CASE
+                WHEN 'user' IN (job_tags.source, excluded.source) THEN 'user'
+                WHEN 'rule' IN (job_tags.source, excluded.source) THEN 'rule'
+                ELSE job_tags.source
+            END`, targetID, sourceID); err != nil {
+			return fmt.Errorf("move tag assignments: %w", err)
+		}
+		// The target can't stay nested under a tag that is going away, and
+		// once that tag's children move under the target, staying anywhere
+		// below it would make the target its own ancestor: lift it out first.
+		catalog, err := tagHierarchy(tx)
+		if err != nil {
+			return err
+		}
+		if domain.TagParentCycle(catalog, sourceID, targetID) {
+			if _, err := tx.Exec(`UPDATE tags SET parent_id = (SELECT parent_id FROM tags WHERE id = ?) WHERE id = ?`,
+				sourceID, targetID); err != nil {
+				return fmt.Errorf("re-home merge target: %w", err)
+			}
+		}
+
+		statements := []struct {
+			query string
+			args  []any
+		}{
+			{`DELETE FROM job_tags WHERE tag_id = ?`, []any{sourceID}},
+			{`UPDATE tags SET parent_id = ? WHERE parent_id = ? AND id != ?`, []any{targetID, sourceID, targetID}},
+			{`DELETE FROM tags WHERE id = ?`, []any{sourceID}},
+		}
+		for _, stmt := range statements {
+			if _, err := tx.Exec(stmt.query, stmt.args...); err != nil {
+				return fmt.Errorf("merge tag %q: %w", sourceName, err)
+			}
+		}
+		if err := redirectTagName(tx, sourceName, targetName); err != nil {
+			return err
+		}
+	}
+	if err := reindexSearch(tx, `job_id IN (SELECT job_id FROM job_tags WHERE tag_id = ?)`, targetID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// tagHierarchy returns the id and parent of every tag, as seen by tx.
+func tagHierarchy(tx *sql.Tx) ([]domain.Tag, error) {
+	rows, err := tx.Query(`SELECT id, parent_id FROM tags`)
+	if err != nil {
+		return nil, fmt.Errorf("list tag hierarchy: %w", err)
+	}
+	defer rows.Close()
+
+	var tags []domain.Tag
+	for rows.Next() {
+		var tag domain.Tag
+		var parentID sql.NullInt64
+		if err := rows.Scan(&tag.ID, &parentID); err != nil {
+			return nil, fmt.Errorf("scan tag: %w", err)
+		}
+		tag.ParentID = nullableTagID(parentID)
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+// redirectTagName makes oldName a synonym of newName and repoints synonyms of
+// oldName at newName.
+func redirectTagName(tx *sql.Tx, oldName, newName string) error {
+	if _, err := tx.Exec(`UPDATE tag_synonyms SET canonical = ? WHERE canonical = ? COLLATE NOCASE`,
+		newName, oldName); err != nil {
+		return fmt.Errorf("repoint tag synonyms: %w", err)
+	}
+	// A synonym from the new name would now point back at itself.
+	if _, err := tx.Exec(`DELETE FROM tag_synonyms WHERE alias = ?`, newName); err != nil {
+		return fmt.Errorf("drop tag synonym: %w", err)
+	}
+	if _, err := tx.Exec(`
+        INSERT INTO tag_synonyms (alias, canonical) VALUES (?, ?)
+        ON CONFLICT(alias) DO UPDATE SET canonical = excluded.canonical`, oldName, newName); err != nil {
+		return fmt.Errorf("record tag synonym: %w", err)
+	}
+	return nil
+}
+
+// DeleteTag detaches a tag from every item and deletes it. Its children
+// become top-level tags. Auto-tagging may recreate it unless it is blocked.
+func (r *JobRepository) DeleteTag(id int64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin tag delete: %w", err)
+	}
+	defer tx.Rollback()
+
+	jobIDs, err := jobIDsForTag(tx, id)
+	if err != nil {
+		return err
+	}
+	for _, stmt := range []string{
+		`DELETE FROM job_tags WHERE tag_id = ?`,
+		`UPDATE tags SET parent_id = NULL WHERE parent_id = ?`,
+		`DELETE FROM tags WHERE id = ?`,
+	} {
+		if _, err := tx.Exec(stmt, id); err != nil {
+			return fmt.Errorf("delete tag %d: %w", id, err)
+		}
+	}
+	if err := reindexJobsSearch(tx, jobIDs); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func jobIDsForTag(tx *sql.Tx, tagID int64) ([]string, error) {
+	rows, err := tx.Query(`SELECT job_id FROM job_tags WHERE tag_id = ?`, tagID)
+	if err != nil {
+		return nil, fmt.Errorf("jobs for tag %d: %w", tagID, err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan tagged job: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// pruneOrphanTags deletes tags no item carries, so the catalog does not fill
+// with leftovers. Curated tags — colored ones and parents — are kept.
+const pruneOrphanTags = `
+        DELETE FROM tags
+        WHERE NOT EXISTS (SELECT 1 FROM job_tags WHERE job_tags.tag_id = tags.id)
+        AND color = ''
+        AND NOT EXISTS (SELECT 1 FROM tags child WHERE child.parent_id = tags.id)`
+
 func (r *JobRepository) GetTagsForJob(jobID string) ([]domain.Tag, error) {
 	rows, err := r.db.Query(`
-        SELECT t.id, t.name, jt.source
+        SELECT t.id, t.name, t.color, t.parent_id, jt.source
         FROM job_tags jt
         JOIN tags t ON t.id = jt.tag_id
         WHERE jt.job_id = ?
@@ -49,9 +289,11 @@ func (r *JobRepository) GetTagsForJob(jobID string) ([]domain.Tag, error) {
 	tags := []domain.Tag{}
 	for rows.Next() {
 		var tag domain.Tag
-		if err := rows.Scan(&tag.ID, &tag.Name, &tag.Source); err != nil {
+		var parentID sql.NullInt64
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.Color, &parentID, &tag.Source); err != nil {
 			return nil, fmt.Errorf("scan job tag: %w", err)
 		}
+		tag.ParentID = nullableTagID(parentID)
 		tags = append(tags, tag)
 	}
 	return tags, rows.Err()
@@ -62,10 +304,22 @@ func (r *JobRepository) GetTagsForJob(jobID string) ([]domain.Tag, error) {
 // untouched (a user tag is not downgraded to auto). Returns the job's full tag
 // list afterwards.
 func (r *JobRepository) AddTagsToJob(jobID string, names []string, source string) ([]domain.Tag, error) {
-	if source != domain.TagSourceAuto {
+	if source != domain.TagSourceAuto && source != domain.TagSourceRule {
 		source = domain.TagSourceUser
 	}
+	if _, err := r.attachJobTags(jobID, names, source); err != nil {
+		return nil, err
+	}
+	if err := reindexJobsSearch(r.db, []string{jobID}); err != nil {
+		return nil, err
+	}
+	return r.GetTagsForJob(jobID)
+}
 
+// attachJobTags attaches the named tags and returns how many were not attached
+// already.
+func (r *JobRepository) attachJobTags(jobID string, names []string, source string) (int, error) {
+	added := 0
 	for _, name := range names {
 		name = domain.NormalizeTagName(name)
 		if name == "" {
@@ -73,15 +327,19 @@ func (r *JobRepository) AddTagsToJob(jobID string, names []string, source string
 		}
 		tagID, err := r.ensureTag(name)
 		if err != nil {
-			return nil, err
+			return added, err
 		}
-		if _, err := r.db.Exec(`
+		res, err := r.db.Exec(`
             INSERT OR IGNORE INTO job_tags (job_id, tag_id, source)
-            VALUES (?, ?, ?)`, jobID, tagID, source); err != nil {
-			return nil, fmt.Errorf("attach tag %q: %w", name, err)
+            VALUES (?, ?, ?)`, jobID, tagID, source)
+		if err != nil {
+			return added, fmt.Errorf("attach tag %q: %w", name, err)
+		}
+		if n, err := res.RowsAffected(); err == nil && n > 0 {
+			added++
 		}
 	}
-	return r.GetTagsForJob(jobID)
+	return added, nil
 }
 
 // ensureTag returns the ID of the named tag, creating it if needed.
@@ -103,35 +361,62 @@ func (r *JobRepository) ensureTag(name string) (int64, error) {
 }
 
 // RemoveTagFromJob detaches a tag from a job and removes the tag entirely once
-// nothing references it, so the catalog does not fill with orphans.
+// nothing references it, unless it is curated (see pruneOrphanTags).
 func (r *JobRepository) RemoveTagFromJob(jobID string, tagID int64) error {
 	if _, err := r.db.Exec(`DELETE FROM job_tags WHERE job_id = ? AND tag_id = ?`, jobID, tagID); err != nil {
 		return fmt.Errorf("remove tag from job: %w", err)
 	}
-	if _, err := r.db.Exec(`
-        DELETE FROM tags WHERE id = ?
-        AND NOT EXISTS (SELECT 1 FROM job_tags WHERE tag_id = ?)`, tagID, tagID); err != nil {
+	if _, err := r.db.Exec(pruneOrphanTags+` AND id = ?`, tagID); err != nil {
 		return fmt.Errorf("prune orphan tag: %w", err)
 	}
-	return nil
+	return reindexJobsSearch(r.db, []string{jobID})
 }
 
-// applyAutoTags derives and attaches automatic tags for freshly stored
-// metadata. Failures are logged rather than propagated: tagging must never
-// fail a download.
-func (r *JobRepository) applyAutoTags(jobID string, metadata domain.Metadata) {
-	names := domain.AutoTagsFor(metadata)
-	if len(names) == 0 {
-		return
+// applyAutoTags derives and attaches automatic and rule tags for freshly
+// stored metadata. Only metadata stored for the first time adds videos to the
+// collections of matching rules; later stores, such as a metadata refresh or
+// a format upgrade, leave a video the user took out of one out. Failures are
+// logged rather than propagated: tagging must never fail a download.
+func (r *JobRepository) applyAutoTags(jobID string, metadata domain.Metadata, collections bool) {
+	engine, err := r.tagEngine()
+	if err != nil {
+		log.WithError(err).WithField("jobID", jobID).Warn("Failed to load tag rules")
+		engine = domain.NewTagEngine(nil, nil)
 	}
-	if _, err := r.AddTagsToJob(jobID, names, domain.TagSourceAuto); err != nil {
+	r.applyTagEngine(engine, jobID, metadata, collections)
+}
+
+// applyTagEngine attaches the auto and rule tags engine derives and, with
+// collections set, adds the video to the collections of matching rules.
+func (r *JobRepository) applyTagEngine(engine *domain.TagEngine, jobID string, metadata domain.Metadata, collections bool) {
+	if _, err := r.attachJobTags(jobID, engine.AutoTags(metadata), domain.TagSourceAuto); err != nil {
 		log.WithError(err).WithField("jobID", jobID).Warn("Failed to apply auto tags")
 	}
+	ruleTags, collectionIDs := engine.Evaluate(metadata)
+	if _, err := r.attachJobTags(jobID, ruleTags, domain.TagSourceRule); err != nil {
+		log.WithError(err).WithField("jobID", jobID).Warn("Failed to apply rule tags")
+	}
+	if !collections {
+		return
+	}
+	for _, collectionID := range collectionIDs {
+		if _, err := r.addToRuleCollection(collectionID, jobID); err != nil {
+			log.WithError(err).WithField("jobID", jobID).Warn("Failed to apply rule collection")
+		}
+	}
 }
 
-// BackfillAutoTags applies auto-tagging to every item that was downloaded
-// before tagging existed. It is idempotent and safe to run at every startup.
+// BackfillAutoTags applies auto-tagging and the tag rules to every item that
+// was downloaded before they existed. It only adds, is idempotent and is safe
+// to run at every startup; ReapplyTagRules also removes stale tags. It leaves
+// rule collections alone, so a video the user took out of one stays out;
+// those are filled when a video's metadata is first stored and by
+// ReapplyTagRules.
 func (r *JobRepository) BackfillAutoTags() error {
+	engine, err := r.tagEngine()
+	if err != nil {
+		return fmt.Errorf("load tag rules for backfill: %w", err)
+	}
 	jobs, err := r.GetAllJobsWithMetadata()
 	if err != nil {
 		return fmt.Errorf("load jobs for tag backfill: %w", err)
@@ -140,14 +425,30 @@ func (r *JobRepository) BackfillAutoTags() error {
 		if jwm == nil || jwm.Job == nil || jwm.Metadata == nil {
 			continue
 		}
-		r.applyAutoTags(jwm.Job.ID, jwm.Metadata)
+		r.applyTagEngine(engine, jwm.Job.ID, jwm.Metadata, false)
 	}
-	return nil
+	// Also heals a search index that drifted from the library.
+	return rebuildSearchIndex(r.db)
 }
 
+// otherChannelJob selects a job other than ?1 of the channel of the
+// channel_snapshots row at hand: the channel itself, or one of its videos or
+// playlists.
+const otherChannelJob = `
+        SELECT job_id FROM channels
+        WHERE job_id != ?1 AND json_extract(metadata_json, '$.id') = channel_snapshots.channel_id
+        UNION ALL
+        SELECT job_id FROM videos
+        WHERE job_id != ?1 AND json_extract(metadata_json, '$.channel_id') = channel_snapshots.channel_id
+        UNION ALL
+        SELECT job_id FROM playlists
+        WHERE job_id != ?1 AND json_extract(metadata_json, '$.channel_id') = channel_snapshots.channel_id
+        LIMIT 1`
+
 // DeleteJob removes a job and everything referencing it: metadata records,
-// playlist/channel memberships and tag assignments. Orphaned tags are pruned
-// afterwards. Files on disk are the caller's responsibility.
+// playlist/channel memberships, tag assignments and its search document.
+// Orphaned tags are pruned afterwards. Files on disk are the caller's
+// responsibility.
 func (r *JobRepository) DeleteJob(jobID string) error {
 	statements := []string{
 		`DELETE FROM videos WHERE job_id = ?`,
@@ -156,6 +457,23 @@ func (r *JobRepository) DeleteJob(jobID string) error {
 		`DELETE FROM video_memberships WHERE video_job_id = ? OR parent_job_id = ?`,
 		`DELETE FROM collection_videos WHERE video_job_id = ?`,
 		`DELETE FROM job_tags WHERE job_id = ?`,
+		// A channel's history outlives the job that recorded it while other
+		// jobs of that channel still show it.
+		`UPDATE channel_snapshots SET job_id = (` + otherChannelJob + `)
+         WHERE job_id = ?1 AND EXISTS (` + otherChannelJob + `)`,
+		`DELETE FROM channel_snapshots WHERE job_id = ?`,
+		`DELETE FROM comments WHERE job_id = ?`,
+		`DELETE FROM metadata_snapshots WHERE job_id = ?`,
+		`DELETE FROM refresh_schedules WHERE job_id = ?`,
+		`DELETE FROM video_availability WHERE job_id = ?`,
+		`DELETE FROM playlist_snapshot_items WHERE snapshot_id IN (SELECT id FROM playlist_snapshots WHERE job_id = ?)`,
+		`DELETE FROM playlist_snapshots WHERE job_id = ?`,
+		`DELETE FROM channel_download_options WHERE job_id = ?`,
+		`DELETE FROM upgrade_items WHERE job_id = ?`,
+		`DELETE FROM watch_progress WHERE job_id = ?`,
+		`DELETE FROM annotations WHERE item_id = ? AND kind = 'job'`,
+		`DELETE FROM bookmarks WHERE job_id = ?`,
+		`DELETE FROM search_index WHERE item_id = ? AND kind != 'collection'`,
 		`DELETE FROM jobs WHERE job_id = ?`,
 	}
 
@@ -174,10 +492,7 @@ func (r *JobRepository) DeleteJob(jobID string) error {
 			return fmt.Errorf("delete job %s: %w", jobID, err)
 		}
 	}
-	if _, err := tx.Exec(`
-        DELETE FROM tags WHERE NOT EXISTS (
-            SELECT 1 FROM job_tags WHERE job_tags.tag_id = tags.id
-        )`); err != nil {
+	if _, err := tx.Exec(pruneOrphanTags); err != nil {
 		return fmt.Errorf("prune orphan tags: %w", err)
 	}
 
@@ -227,3 +227,207 @@ func TestJobRepository_DeleteJob(t *testing.T) {
 		t.Errorf("parent job was deleted too: %v", err)
 	}
 }
+
+func TestJobRepository_UpdateTag(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+
+	repo := NewJobRepository(db)
+	repo.Create(testutil.CreateTestJob("job-1", "https://youtube.com/watch?v=test"))
+	tags, _ := repo.AddTagsToJob("job-1", []string{"js", "programming"}, domain.TagSourceAuto)
+
+	js, _ := repo.GetTagByName("JS")
+	parent, _ := repo.GetTagByName("programming")
+	if js == nil || parent == nil {
+		t.Fatalf("GetTagByName() did not find tags: %v", tagNames(tags))
+	}
+
+	js.Name = "JavaScript"
+	js.Color = "#f7df1e"
+	js.ParentID = &parent.ID
+	if err := repo.UpdateTag(js); err != nil {
+		t.Fatalf("UpdateTag() error = %v", err)
+	}
+
+	got, err := repo.GetTag(js.ID)
+	if err != nil || got == nil {
+		t.Fatalf("GetTag() = %v, %v", got, err)
+	}
+	if got.Name != "JavaScript" || got.Color != "#f7df1e" || got.ParentID == nil || *got.ParentID != parent.ID || got.Count != 1 {
+		t.Errorf("GetTag() = %+v", got)
+	}
+
+	// The old name now resolves to the new one when tags are derived.
+	vocabulary, _ := repo.GetTagVocabulary()
+	if len(vocabulary.Synonyms) != 1 || vocabulary.Synonyms[0].Alias != "js" || vocabulary.Synonyms[0].Canonical != "JavaScript" {
+		t.Errorf("synonyms after rename = %+v", vocabulary.Synonyms)
+	}
+
+	// Removing the last item keeps curated tags: the colored one and the parent.
+	repo.RemoveTagFromJob("job-1", js.ID)
+	repo.RemoveTagFromJob("job-1", parent.ID)
+	if tag, _ := repo.GetTag(js.ID); tag == nil {
+		t.Error("colored tag was pruned")
+	}
+	if tag, _ := repo.GetTag(parent.ID); tag == nil {
+		t.Error("parent tag was pruned")
+	}
+}
+
+func TestJobRepository_MergeTags(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+
+	repo := NewJobRepository(db)
+	for _, id := range []string{"job-1", "job-2", "job-3"} {
+		repo.Create(testutil.CreateTestJob(id, "https://youtube.com/watch?v="+id))
+	}
+	repo.AddTagsToJob("job-1", []string{"golang"}, domain.TagSourceAuto)
+	repo.AddTagsToJob("job-2", []string{"go-lang"}, domain.TagSourceRule)
+	repo.AddTagsToJob("job-3", []string{"Go"}, domain.TagSourceAuto)
+	repo.AddTagsToJob("job-3", []string{"golang"}, domain.TagSourceUser)
+	repo.AddTagsToJob("job-1", []string{"generics"}, domain.TagSourceUser)
+
+	target, _ := repo.GetTagByName("Go")
+	golang, _ := repo.GetTagByName("golang")
+	goLang, _ := repo.GetTagByName("go-lang")
+	child, _ := repo.GetTagByName("generics")
+	child.ParentID = &golang.ID
+	repo.UpdateTag(child)
+
+	if err := repo.MergeTags(target.ID, []int64{golang.ID, goLang.ID}); err != nil {
+		t.Fatalf("MergeTags() error = %v", err)
+	}
+
+	wantSources := map[string]string{
+		"job-1": domain.TagSourceAuto,
+		"job-2": domain.TagSourceRule,
+		"job-3": domain.TagSourceUser, // the stronger source wins
+	}
+	for jobID, want := range wantSources {
+		tags, _ := repo.GetTagsForJob(jobID)
+		found := false
+		for _, tag := range tags {
+			if tag.ID == golang.ID || tag.ID == goLang.ID {
+				t.Errorf("%s still carries merged tag %q", jobID, tag.Name)
+			}
+			if tag.ID == target.ID {
+				found = true
+				if tag.Source != want {
+					t.Errorf("%s source = %q, want %q", jobID, tag.Source, want)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("%s lost the merged tag: %v", jobID, tagNames(tags))
+		}
+	}
+
+	if tag, _ := repo.GetTag(golang.ID); tag != nil {
+		t.Error("merged tag still exists")
+	}
+	if got, _ := repo.GetTag(child.ID); got.ParentID == nil || *got.ParentID != target.ID {
+		t.Errorf("child parent = %v, want %d", got.ParentID, target.ID)
+	}
+	vocabulary, _ := repo.GetTagVocabulary()
+	if len(vocabulary.Synonyms) != 2 {
+		t.Errorf("synonyms after merge = %+v", vocabulary.Synonyms)
+	}
+}
+
+func TestJobRepository_MergeTagsIntoDescendant(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+
+	repo := NewJobRepository(db)
+	repo.Create(testutil.CreateTestJob("job-1", "https://youtube.com/watch?v=test"))
+	repo.AddTagsToJob("job-1", []string{"music", "jazz", "bebop", "genres"}, domain.TagSourceUser)
+	genres, _ := repo.GetTagByName("genres")
+	music, _ := repo.GetTagByName("music")
+	jazz, _ := repo.GetTagByName("jazz")
+	bebop, _ := repo.GetTagByName("bebop")
+	for _, nest := range []struct{ child, parent *domain.Tag }{{music, genres}, {jazz, music}, {bebop, jazz}} {
+		nest.child.ParentID = &nest.parent.ID
+		repo.UpdateTag(nest.child)
+	}
+
+	// bebop sits two levels below music: merging music into it must not
+	// leave jazz and bebop parenting each other.
+	if err := repo.MergeTags(bebop.ID, []int64{music.ID}); err != nil {
+		t.Fatalf("MergeTags() error = %v", err)
+	}
+	catalog, _ := repo.ListTags()
+	parents := map[int64]*int64{}
+	for _, tag := range catalog {
+		parents[tag.ID] = tag.ParentID
+	}
+	if p := parents[bebop.ID]; p == nil || *p != genres.ID {
+		t.Errorf("target parent = %v, want %d (the merged tag's parent)", p, genres.ID)
+	}
+	if p := parents[jazz.ID]; p == nil || *p != bebop.ID {
+		t.Errorf("jazz parent = %v, want %d", p, bebop.ID)
+	}
+	for _, tag := range catalog {
+		if tag.ParentID != nil && domain.TagParentCycle(catalog, tag.ID, *tag.ParentID) {
+			t.Errorf("tag %q is its own ancestor", tag.Name)
+		}
+	}
+}
+
+func TestJobRepository_DeleteTag(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+
+	repo := NewJobRepository(db)
+	repo.Create(testutil.CreateTestJob("job-1", "https://youtube.com/watch?v=test"))
+	repo.AddTagsToJob("job-1", []string{"music", "jazz"}, domain.TagSourceUser)
+	music, _ := repo.GetTagByName("music")
+	jazz, _ := repo.GetTagByName("jazz")
+	jazz.ParentID = &music.ID
+	repo.UpdateTag(jazz)
+
+	if err := repo.DeleteTag(music.ID); err != nil {
+		t.Fatalf("DeleteTag() error = %v", err)
+	}
+	tags, _ := repo.GetTagsForJob("job-1")
+	if containsName(tags, "music") || !containsName(tags, "jazz") {
+		t.Errorf("tags after delete = %v", tagNames(tags))
+	}
+	if got, _ := repo.GetTag(jazz.ID); got == nil || got.ParentID != nil {
+		t.Errorf("child after parent delete = %+v", got)
+	}
+}
+
+func TestJobRepository_TagFilterMatchesChildren(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+
+	repo := NewJobRepository(db)
+	for _, id := range []string{"v1", "v2", "v3"} {
+		repo.Create(testutil.CreateTestJob(id, "https://youtube.com/watch?v="+id))
+		repo.StoreMetadata(id, testutil.CreateTestVideoMetadata())
+	}
+	repo.AddTagsToJob("v1", []string{"music"}, domain.TagSourceUser)
+	repo.AddTagsToJob("v2", []string{"jazz"}, domain.TagSourceUser)
+	repo.AddTagsToJob("v3", []string{"bebop"}, domain.TagSourceUser)
+
+	music, _ := repo.GetTagByName("music")
+	jazz, _ := repo.GetTagByName("jazz")
+	bebop, _ := repo.GetTagByName("bebop")
+	jazz.ParentID = &music.ID
+	bebop.ParentID = &jazz.ID
+	repo.UpdateTag(jazz)
+	repo.UpdateTag(bebop)
+
+	for tag, want := range map[string]int{"music": 3, "jazz": 2, "bebop": 1} {
+		_, total, err := repo.GetMetadataByType("videos", domain.MetadataQuery{
+			Page: 1, Limit: 20, SortBy: "created_at", Order: "desc", Tag: tag,
+		})
+		if err != nil {
+			t.Fatalf("GetMetadataByType(tag=%q) error = %v", tag, err)
+		}
+		if total != want {
+			t.Errorf("tag %q total = %d, want %d", tag, total, want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package sqlite
 
 import (
+	"strings"
 	"testing"
 	"time"
 	"video-archiver/internal/domain"
@@ -109,6 +110,81 @@ func TestJobRepository_GetByID(t *testing.T) {
 	}
 }
 
+func TestJobRepository_GetByIDKeepsJobOptions(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+	repo := NewJobRepository(db)
+
+	archiveComments, quality := false, 720
+	job := testutil.CreateTestJob("with-options", "https://youtube.com/watch?v=a")
+	job.ArchiveComments = &archiveComments
+	job.CustomQuality = &quality
+	repo.Create(job)
+	repo.Create(testutil.CreateTestJob("defaults", "https://youtube.com/watch?v=b"))
+
+	got, err := repo.GetByID("with-options")
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if got.ArchiveComments == nil || *got.ArchiveComments {
+		t.Errorf("ArchiveComments = %v, want false", got.ArchiveComments)
+	}
+	if got.CustomQuality == nil || *got.CustomQuality != 720 {
+		t.Errorf("CustomQuality = %v, want 720", got.CustomQuality)
+	}
+
+	got, err = repo.GetByID("defaults")
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if got.ArchiveComments != nil || got.CustomQuality != nil {
+		t.Errorf("unset options read back as %v, %v", got.ArchiveComments, got.CustomQuality)
+	}
+}
+
+func TestJobRepository_LiveFlagPersists(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+	repo := NewJobRepository(db)
+
+	live := testutil.CreateTestJob("live", "https://youtube.com/watch?v=live")
+	live.Live = true
+	repo.Create(live)
+	found := testutil.CreateTestJob("found-live", "https://youtube.com/watch?v=found")
+	repo.Create(found)
+	stale := *found
+
+	// The download finds out the source is live and updates its copy.
+	found.Live = true
+	if err := repo.Update(found); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	// A copy made before that must not clear it again.
+	stale.Status = domain.JobStatusComplete
+	if err := repo.Update(&stale); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	for _, id := range []string{"live", "found-live"} {
+		got, err := repo.GetByID(id)
+		if err != nil {
+			t.Fatalf("GetByID(%s) error = %v", id, err)
+		}
+		if !got.Live {
+			t.Errorf("GetByID(%s).Live = false, want true", id)
+		}
+	}
+	jobs, err := repo.GetJobs()
+	if err != nil {
+		t.Fatalf("GetJobs() error = %v", err)
+	}
+	for _, job := range jobs {
+		if !job.Live {
+			t.Errorf("GetJobs() lost the live flag of %s", job.ID)
+		}
+	}
+}
+
 func TestJobRepository_GetRecent(t *testing.T) {
 	db := testutil.CreateTestDB(t)
 	defer db.Close()
@@ -393,6 +469,35 @@ func TestJobRepository_GetMetadataByType(t *testing.T) {
 	}
 }
 
+func TestJobRepository_GetMetadataByTypePagesEqualSortKeys(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+
+	repo := NewJobRepository(db)
+	// Every video has the same title; insert them out of ID order.
+	for _, id := range []string{"video-3", "video-1", "video-5", "video-2", "video-4"} {
+		repo.Create(testutil.CreateTestJob(id, "https://youtube.com/watch?v="+id))
+		repo.StoreMetadata(id, testutil.CreateTestVideoMetadata())
+	}
+
+	var got []string
+	for page := 1; page <= 3; page++ {
+		items, _, err := repo.GetMetadataByType("videos", domain.MetadataQuery{
+			Page: page, Limit: 2, SortBy: "title", Order: "asc",
+		})
+		if err != nil {
+			t.Fatalf("GetMetadataByType(page %d) error = %v", page, err)
+		}
+		for _, item := range items {
+			got = append(got, item.Job.ID)
+		}
+	}
+	want := []string{"video-1", "video-2", "video-3", "video-4", "video-5"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("paged IDs = %v, want %v", got, want)
+	}
+}
+
 func TestJobRepository_AddVideoToParent(t *testing.T) {
 	db := testutil.CreateTestDB(t)
 	defer db.Close()
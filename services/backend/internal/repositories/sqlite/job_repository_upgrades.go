@@ -0,0 +1,134 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"video-archiver/internal/domain"
+)
+
+const upgradeRunColumns = `id, status, dry_run, target_height, candidates, checked, upgraded, failed,
+               bytes_before, bytes_after, started_at, finished_at`
+
+// CreateUpgradeRun inserts a quality upgrade run and sets its ID.
+func (r *JobRepository) CreateUpgradeRun(run *domain.UpgradeRun) error {
+	if run.StartedAt.IsZero() {
+		run.StartedAt = time.Now()
+	}
+	result, err := r.db.Exec(`
+        INSERT INTO upgrade_runs (status, dry_run, target_height, candidates, checked, upgraded, failed,
+                                  bytes_before, bytes_after, started_at, finished_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		run.Status, run.DryRun, run.TargetHeight, run.Candidates, run.Checked, run.Upgraded, run.Failed,
+		run.BytesBefore, run.BytesAfter, run.StartedAt, run.FinishedAt)
+	if err != nil {
+		return fmt.Errorf("insert upgrade run: %w", err)
+	}
+	if run.ID, err = result.LastInsertId(); err != nil {
+		return fmt.Errorf("upgrade run id: %w", err)
+	}
+	return nil
+}
+
+// UpdateUpgradeRun stores a run's status, counters and finish time.
+func (r *JobRepository) UpdateUpgradeRun(run *domain.UpgradeRun) error {
+	_, err := r.db.Exec(`
+        UPDATE upgrade_runs
+        SET status = ?, candidates = ?, checked = ?, upgraded = ?, failed = ?,
+            bytes_before = ?, bytes_after = ?, finished_at = ?
+        WHERE id = ?`,
+		run.Status, run.Candidates, run.Checked, run.Upgraded, run.Failed,
+		run.BytesBefore, run.BytesAfter, run.FinishedAt, run.ID)
+	if err != nil {
+		return fmt.Errorf("update upgrade run: %w", err)
+	}
+	return nil
+}
+
+// AddUpgradeItem records the result of an upgrade run for one video.
+func (r *JobRepository) AddUpgradeItem(item *domain.UpgradeItem) error {
+	if item.CreatedAt.IsZero() {
+		item.CreatedAt = time.Now()
+	}
+	result, err := r.db.Exec(`
+        INSERT INTO upgrade_items (run_id, job_id, title, outcome, old_height, new_height,
+                                   old_codec, new_codec, old_size, new_size, error, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		item.RunID, item.JobID, item.Title, item.Outcome, item.OldHeight, item.NewHeight,
+		item.OldCodec, item.NewCodec, item.OldSize, item.NewSize, item.Error, item.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("insert upgrade item: %w", err)
+	}
+	if item.ID, err = result.LastInsertId(); err != nil {
+		return fmt.Errorf("upgrade item id: %w", err)
+	}
+	return nil
+}
+
+// GetUpgradeRun returns a run with its items in the order they were checked.
+func (r *JobRepository) GetUpgradeRun(id int64) (*domain.UpgradeRun, error) {
+	run, err := scanUpgradeRun(r.db.QueryRow(`SELECT `+upgradeRunColumns+` FROM upgrade_runs WHERE id = ?`, id))
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(`
+        SELECT id, run_id, job_id, title, outcome, old_height, new_height,
+               old_codec, new_codec, old_size, new_size, error, created_at
+        FROM upgrade_items
+        WHERE run_id = ?
+        ORDER BY id`, id)
+	if err != nil {
+		return nil, fmt.Errorf("query upgrade items: %w", err)
+	}
+	defer rows.Close()
+
+	run.Items = []domain.UpgradeItem{}
+	for rows.Next() {
+		var item domain.UpgradeItem
+		if err := rows.Scan(&item.ID, &item.RunID, &item.JobID, &item.Title, &item.Outcome,
+			&item.OldHeight, &item.NewHeight, &item.OldCodec, &item.NewCodec,
+			&item.OldSize, &item.NewSize, &item.Error, &item.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan upgrade item: %w", err)
+		}
+		run.Items = append(run.Items, item)
+	}
+	return run, rows.Err()
+}
+
+// ListUpgradeRuns returns up to limit runs without their items, newest first.
+func (r *JobRepository) ListUpgradeRuns(limit int) ([]domain.UpgradeRun, error) {
+	rows, err := r.db.Query(`SELECT `+upgradeRunColumns+` FROM upgrade_runs ORDER BY id DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query upgrade runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []domain.UpgradeRun{}
+	for rows.Next() {
+		run, err := scanUpgradeRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, *run)
+	}
+	return runs, rows.Err()
+}
+
+func scanUpgradeRun(row rowScanner) (*domain.UpgradeRun, error) {
+	var run domain.UpgradeRun
+	var finishedAt sql.NullTime
+	if err := row.Scan(&run.ID, &run.Status, &run.DryRun, &run.TargetHeight, &run.Candidates,
+		&run.Checked, &run.Upgraded, &run.Failed, &run.BytesBefore, &run.BytesAfter,
+		&run.StartedAt, &finishedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("scan upgrade run: %w", err)
+	}
+	if finishedAt.Valid {
+		run.FinishedAt = &finishedAt.Time
+	}
+	return &run, nil
+}
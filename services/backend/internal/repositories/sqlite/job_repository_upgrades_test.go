@@ -0,0 +1,77 @@
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"video-archiver/internal/domain"
+	"video-archiver/internal/testutil"
+)
+
+func TestJobRepository_UpgradeRuns(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+	repo := NewJobRepository(db)
+
+	job := testutil.CreateTestJob("video-1", "https://youtube.com/watch?v=video-1")
+	if err := repo.Create(job); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	run := &domain.UpgradeRun{Status: domain.UpgradeRunRunning, TargetHeight: 1080, Candidates: 1}
+	if err := repo.CreateUpgradeRun(run); err != nil || run.ID == 0 {
+		t.Fatalf("CreateUpgradeRun() = %d, %v", run.ID, err)
+	}
+
+	item := &domain.UpgradeItem{
+		RunID:     run.ID,
+		JobID:     job.ID,
+		Title:     "Video",
+		Outcome:   domain.UpgradeOutcomeUpgraded,
+		OldHeight: 720,
+		NewHeight: 1080,
+		OldSize:   100,
+		NewSize:   250,
+	}
+	if err := repo.AddUpgradeItem(item); err != nil {
+		t.Fatalf("AddUpgradeItem() error = %v", err)
+	}
+
+	finished := time.Now()
+	run.Status = domain.UpgradeRunComplete
+	run.Checked, run.Upgraded = 1, 1
+	run.BytesBefore, run.BytesAfter = 100, 250
+	run.FinishedAt = &finished
+	if err := repo.UpdateUpgradeRun(run); err != nil {
+		t.Fatalf("UpdateUpgradeRun() error = %v", err)
+	}
+
+	got, err := repo.GetUpgradeRun(run.ID)
+	if err != nil {
+		t.Fatalf("GetUpgradeRun() error = %v", err)
+	}
+	if got.Status != domain.UpgradeRunComplete || got.Upgraded != 1 || got.BytesAfter != 250 || got.FinishedAt == nil {
+		t.Errorf("GetUpgradeRun() = %+v", got)
+	}
+	if len(got.Items) != 1 || got.Items[0].NewHeight != 1080 || got.Items[0].Outcome != domain.UpgradeOutcomeUpgraded {
+		t.Errorf("items = %+v", got.Items)
+	}
+
+	second := &domain.UpgradeRun{Status: domain.UpgradeRunRunning, DryRun: true, TargetHeight: 720}
+	repo.CreateUpgradeRun(second)
+	runs, err := repo.ListUpgradeRuns(10)
+	if err != nil || len(runs) != 2 || runs[0].ID != second.ID || !runs[0].DryRun || runs[0].Items != nil {
+		t.Errorf("ListUpgradeRuns() = %+v, %v", runs, err)
+	}
+
+	if _, err := repo.GetUpgradeRun(999); err == nil {
+		t.Error("GetUpgradeRun(missing) succeeded")
+	}
+
+	if err := repo.DeleteJob(job.ID); err != nil {
+		t.Fatalf("DeleteJob() error = %v", err)
+	}
+	if got, _ := repo.GetUpgradeRun(run.ID); got == nil || len(got.Items) != 0 {
+		t.Errorf("upgrade items of deleted job kept: %+v", got)
+	}
+}
@@ -0,0 +1,219 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"video-archiver/internal/domain"
+)
+
+// SetWatchProgress stores where playback of a video stopped, replacing any
+// earlier progress. Times are written in UTC so last_watched_at orders
+// correctly as text.
+func (r *JobRepository) SetWatchProgress(progress *domain.WatchProgress) error {
+	if _, err := r.db.Exec(`
+        INSERT INTO watch_progress (job_id, position, duration, completed, last_watched_at)
+        VALUES (?, ?, ?, ?, ?)
+        ON CONFLICT(job_id) DO UPDATE SET
+            position = excluded.position,
+            duration = excluded.duration,
+            completed = excluded.completed,
+            last_watched_at = excluded.last_watched_at`,
+		progress.JobID, progress.Position, progress.Duration, progress.Completed, progress.LastWatchedAt.UTC()); err != nil {
+		return fmt.Errorf("set watch progress: %w", err)
+	}
+	return nil
+}
+
+// GetWatchProgress returns a video's watch progress, or nil when it has
+// never been played.
+func (r *JobRepository) GetWatchProgress(jobID string) (*domain.WatchProgress, error) {
+	var p domain.WatchProgress
+	err := r.db.QueryRow(`
+        SELECT job_id, position, duration, completed, last_watched_at
+        FROM watch_progress
+        WHERE job_id = ?`, jobID).Scan(&p.JobID, &p.Position, &p.Duration, &p.Completed, &p.LastWatchedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get watch progress: %w", err)
+	}
+	return &p, nil
+}
+
+// DeleteWatchProgress forgets a video's progress, making it unwatched.
+func (r *JobRepository) DeleteWatchProgress(jobID string) error {
+	if _, err := r.db.Exec(`DELETE FROM watch_progress WHERE job_id = ?`, jobID); err != nil {
+		return fmt.Errorf("delete watch progress: %w", err)
+	}
+	return nil
+}
+
+// GetContinueWatching returns videos that were started but not finished,
+// most recently watched first.
+func (r *JobRepository) GetContinueWatching(limit int) ([]*domain.JobWithMetadata, error) {
+	rows, err := r.db.Query(`
+        SELECT j.job_id, j.url, j.status, j.progress, j.media_type, j.warnings, j.file_path, j.created_at, j.updated_at,
+               v.metadata_json, wp.position, wp.duration, wp.completed, wp.last_watched_at
+        FROM watch_progress wp
+        JOIN jobs j ON j.job_id = wp.job_id
+        JOIN videos v ON v.job_id = wp.job_id
+        WHERE NOT wp.completed AND wp.position > 0
+        ORDER BY wp.last_watched_at DESC
+        LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query continue watching: %w", err)
+	}
+	defer rows.Close()
+
+	result := []*domain.JobWithMetadata{}
+	for rows.Next() {
+		job := &domain.Job{}
+		watch := &domain.WatchProgress{}
+		var metadataJSON string
+		var warningsJSON, filePath sql.NullString
+		var mediaType string
+
+		if err := rows.Scan(
+			&job.ID, &job.URL, &job.Status, &job.Progress, &mediaType, &warningsJSON, &filePath,
+			&job.CreatedAt, &job.UpdatedAt, &metadataJSON,
+			&watch.Position, &watch.Duration, &watch.Completed, &watch.LastWatchedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan continue watching: %w", err)
+		}
+		watch.JobID = job.ID
+
+		if warningsJSON.Valid && warningsJSON.String != "" {
+			if err := json.Unmarshal([]byte(warningsJSON.String), &job.Warnings); err != nil {
+				log.WithError(err).Warn("Failed to unmarshal warnings")
+				job.Warnings = []string{}
+			}
+		}
+		job.MediaType = domain.MediaType(mediaType)
+		job.FilePath = filePath.String
+
+		var videoMetadata domain.VideoMetadata
+		if err := json.Unmarshal([]byte(metadataJSON), &videoMetadata); err != nil {
+			log.WithError(err).Warnf("Could not unmarshal video metadata for job %s", job.ID)
+			continue
+		}
+
+		result = append(result, &domain.JobWithMetadata{
+			Job:      job,
+			Metadata: &videoMetadata,
+			Watch:    watch,
+		})
+	}
+	return result, rows.Err()
+}
+
+// watchStateCondition matches videos (as jobs.job_id) in a watch state.
+// Videos never played, or reset, are unwatched.
+func watchStateCondition(state domain.WatchState) string {
+	switch state {
+	case domain.WatchStateWatched:
+		return `EXISTS (SELECT 1 FROM watch_progress wp WHERE wp.job_id = jobs.job_id AND wp.completed)`
+	case domain.WatchStateInProgress:
+		return `EXISTS (SELECT 1 FROM watch_progress wp WHERE wp.job_id = jobs.job_id AND NOT wp.completed AND wp.position > 0)`
+	default:
+		return `NOT EXISTS (SELECT 1 FROM watch_progress wp WHERE wp.job_id = jobs.job_id AND (wp.completed OR wp.position > 0))`
+	}
+}
+
+// attachWatch loads the watch progress for a page of videos in one query and
+// assigns it.
+func (r *JobRepository) attachWatch(items []*domain.JobWithMetadata) error {
+	ids, placeholders := jobIDArgs(items)
+	if len(ids) == 0 {
+		return nil
+	}
+
+	rows, err := r.db.Query(`
+        SELECT job_id, position, duration, completed, last_watched_at
+        FROM watch_progress
+        WHERE job_id IN (`+placeholders+`)`, ids...)
+	if err != nil {
+		return fmt.Errorf("load watch progress: %w", err)
+	}
+	defer rows.Close()
+
+	byJob := map[string]*domain.WatchProgress{}
+	for rows.Next() {
+		var p domain.WatchProgress
+		if err := rows.Scan(&p.JobID, &p.Position, &p.Duration, &p.Completed, &p.LastWatchedAt); err != nil {
+			return fmt.Errorf("scan watch progress: %w", err)
+		}
+		byJob[p.JobID] = &p
+	}
+
+	for _, item := range items {
+		if item.Job != nil {
+			item.Watch = byJob[item.Job.ID]
+		}
+	}
+	return rows.Err()
+}
+
+// attachCompletion counts the watched videos of a page of playlists or
+// channels in one query and assigns their completion.
+func (r *JobRepository) attachCompletion(items []*domain.JobWithMetadata) error {
+	ids, placeholders := jobIDArgs(items)
+	if len(ids) == 0 {
+		return nil
+	}
+
+	rows, err := r.db.Query(`
+        SELECT vm.parent_job_id,
+               COUNT(DISTINCT vm.video_job_id),
+               COUNT(DISTINCT CASE WHEN wp.completed THEN vm.video_job_id END)
+        FROM video_memberships vm
+        JOIN videos v ON v.job_id = vm.video_job_id
+        LEFT JOIN watch_progress wp ON wp.job_id = vm.video_job_id
+        WHERE vm.parent_job_id IN (`+placeholders+`)
+        GROUP BY vm.parent_job_id`, ids...)
+	if err != nil {
+		return fmt.Errorf("load completion: %w", err)
+	}
+	defer rows.Close()
+
+	byJob := map[string]*domain.WatchCompletion{}
+	for rows.Next() {
+		var jobID string
+		var total, watched int
+		if err := rows.Scan(&jobID, &total, &watched); err != nil {
+			return fmt.Errorf("scan completion: %w", err)
+		}
+		byJob[jobID] = domain.NewWatchCompletion(watched, total)
+	}
+
+	for _, item := range items {
+		if item.Job == nil {
+			continue
+		}
+		if completion, ok := byJob[item.Job.ID]; ok {
+			item.Completion = completion
+		} else {
+			item.Completion = domain.NewWatchCompletion(0, 0)
+		}
+	}
+	return rows.Err()
+}
+
+// jobIDArgs returns the job IDs of items as query arguments with matching
+// placeholders.
+func jobIDArgs(items []*domain.JobWithMetadata) ([]any, string) {
+	ids := make([]any, 0, len(items))
+	placeholders := make([]string, 0, len(items))
+	for _, item := range items {
+		if item.Job != nil {
+			ids = append(ids, item.Job.ID)
+			placeholders = append(placeholders, "?")
+		}
+	}
+	return ids, strings.Join(placeholders, ",")
+}
@@ -0,0 +1,129 @@
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"video-archiver/internal/domain"
+	"video-archiver/internal/testutil"
+)
+
+func TestJobRepository_WatchProgress(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+	repo := NewJobRepository(db)
+	for _, id := range []string{"started", "finished", "fresh", "earlier"} {
+		createCompletedVideo(t, repo, id)
+	}
+
+	now := time.Now()
+	for _, p := range []*domain.WatchProgress{
+		{JobID: "started", Position: 120, Duration: 300, LastWatchedAt: now},
+		{JobID: "finished", Position: 290, Duration: 300, Completed: true, LastWatchedAt: now},
+		{JobID: "earlier", Position: 30, Duration: 300, LastWatchedAt: now.Add(-time.Hour)},
+	} {
+		if err := repo.SetWatchProgress(p); err != nil {
+			t.Fatalf("SetWatchProgress(%s) error = %v", p.JobID, err)
+		}
+	}
+
+	got, err := repo.GetWatchProgress("started")
+	if err != nil || got == nil || got.Position != 120 || got.Completed {
+		t.Fatalf("GetWatchProgress() = %+v, %v", got, err)
+	}
+	if got, err := repo.GetWatchProgress("fresh"); err != nil || got != nil {
+		t.Errorf("GetWatchProgress(never played) = %+v, %v; want nil", got, err)
+	}
+
+	continuing, err := repo.GetContinueWatching(10)
+	if err != nil {
+		t.Fatalf("GetContinueWatching() error = %v", err)
+	}
+	if len(continuing) != 2 || continuing[0].Job.ID != "started" || continuing[1].Job.ID != "earlier" ||
+		continuing[0].Watch == nil || continuing[0].Watch.Position != 120 {
+		t.Errorf("GetContinueWatching() = %v", continuing)
+	}
+
+	for query, want := range map[string]int{
+		"watch:watched":     1,
+		"watch:in-progress": 2,
+		"watch:unwatched":   1,
+		"-watch:unwatched":  3,
+	} {
+		filters, err := domain.ParseLibraryQuery(query)
+		if err != nil {
+			t.Fatalf("ParseLibraryQuery(%q) error = %v", query, err)
+		}
+		items, total, err := repo.GetMetadataByType("videos", domain.MetadataQuery{Filters: filters})
+		if err != nil || total != want {
+			t.Errorf("%s: total = %d, %v; want %d", query, total, err, want)
+		}
+		for _, item := range items {
+			if (item.Job.ID == "fresh") != (item.Watch == nil) {
+				t.Errorf("%s: %s watch = %+v", query, item.Job.ID, item.Watch)
+			}
+		}
+	}
+
+	if err := repo.DeleteWatchProgress("finished"); err != nil {
+		t.Fatalf("DeleteWatchProgress() error = %v", err)
+	}
+	if got, _ := repo.GetWatchProgress("finished"); got != nil {
+		t.Errorf("progress after reset = %+v, want nil", got)
+	}
+}
+
+func TestJobRepository_WatchCompletion(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+	repo := NewJobRepository(db)
+	collections := NewCollectionRepository(db)
+
+	repo.Create(testutil.CreateTestJob("playlist", "https://youtube.com/playlist?list=x"))
+	repo.StoreMetadata("playlist", testutil.CreateTestPlaylistMetadata())
+	repo.Create(testutil.CreateTestJob("empty", "https://youtube.com/playlist?list=y"))
+	repo.StoreMetadata("empty", testutil.CreateTestPlaylistMetadata())
+	for _, id := range []string{"v1", "v2", "v3"} {
+		createCompletedVideo(t, repo, id)
+		repo.AddVideoToParent(id, "playlist", "playlist")
+	}
+	repo.SetWatchProgress(&domain.WatchProgress{JobID: "v1", Position: 300, Duration: 300, Completed: true, LastWatchedAt: time.Now()})
+	repo.SetWatchProgress(&domain.WatchProgress{JobID: "v2", Position: 10, Duration: 300, LastWatchedAt: time.Now()})
+
+	items, _, err := repo.GetMetadataByType("playlists", domain.MetadataQuery{SortBy: "title"})
+	if err != nil {
+		t.Fatalf("GetMetadataByType() error = %v", err)
+	}
+	for _, item := range items {
+		want := domain.WatchCompletion{Watched: 1, Total: 3, Percent: 33}
+		if item.Job.ID == "empty" {
+			want = domain.WatchCompletion{}
+		}
+		if item.Completion == nil || *item.Completion != want {
+			t.Errorf("%s completion = %+v, want %+v", item.Job.ID, item.Completion, want)
+		}
+	}
+
+	playlist, err := repo.GetJobWithMetadata("playlist")
+	if err != nil || playlist.Completion == nil || playlist.Completion.Watched != 1 {
+		t.Errorf("GetJobWithMetadata() completion = %+v, %v", playlist.Completion, err)
+	}
+	videos, err := repo.GetVideosForParent("playlist")
+	if err != nil || len(videos) != 3 || videos[0].Watch == nil || !videos[0].Watch.Completed {
+		t.Errorf("GetVideosForParent() = %v, %v; want v1 watched", videos, err)
+	}
+
+	now := time.Now()
+	collections.Create(&domain.Collection{ID: "manual", Name: "Manual", CreatedAt: now, UpdatedAt: now})
+	collections.AddVideos("manual", []string{"v1", "v3"})
+	collections.Create(&domain.Collection{ID: "smart", Name: "Smart", Query: "status:complete", CreatedAt: now, UpdatedAt: now})
+	for id, want := range map[string]domain.WatchCompletion{
+		"manual": {Watched: 1, Total: 2, Percent: 50},
+		"smart":  {Watched: 1, Total: 3, Percent: 33},
+	} {
+		c, err := collections.GetByID(id)
+		if err != nil || c.Completion == nil || *c.Completion != want {
+			t.Errorf("collection %s completion = %+v, %v; want %+v", id, c.Completion, err, want)
+		}
+	}
+}
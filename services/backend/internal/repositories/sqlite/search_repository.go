@@ -0,0 +1,227 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+
+	"video-archiver/internal/domain"
+)
+
+// The search_index FTS5 table holds one document per library item and
+// collection: item_id, kind, title, description, channel, tags and notes.
+// The repositories keep it in sync as they write; rebuildSearchIndex
+// recreates it from scratch.
+
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// searchDocumentsQuery selects the search documents of the jobs matching
+// filter, a condition on job_id. The filter appears once per item table, so
+// its arguments must be repeated three times.
+func searchDocumentsQuery(filter string) string {
+	tags := func(table string) string {
+		return `(SELECT COALESCE(group_concat(t.name, ' '), '') FROM job_tags jt
+                 JOIN tags t ON t.id = jt.tag_id WHERE jt.job_id = ` + table + `.job_id)`
+	}
+	field := func(table, path string) string {
+		return `COALESCE(json_extract(` + table + `.metadata_json, '$.` + path + `'), '')`
+	}
+	notes := func(table string) string {
+		return annotationField(table+".job_id", "notes")
+	}
+	return `
+        INSERT INTO search_index (item_id, kind, title, description, channel, tags, notes)
+        SELECT job_id, 'video', title, ` + field("videos", "description") + `,
+               TRIM(` + field("videos", "channel") + ` || ' ' || ` + field("videos", "uploader") + `),
+               ` + tags("videos") + `, ` + notes("videos") + `
+        FROM videos WHERE ` + filter + `
+        UNION ALL
+        SELECT job_id, 'playlist', title, ` + field("playlists", "description") + `,
+               ` + field("playlists", "channel") + `, ` + tags("playlists") + `, ` + notes("playlists") + `
+        FROM playlists WHERE ` + filter + `
+        UNION ALL
+        SELECT job_id, 'channel', name, ` + field("channels", "description") + `,
+               name, ` + tags("channels") + `, ` + notes("channels") + `
+        FROM channels WHERE ` + filter
+}
+
+// reindexSearch rewrites the search documents of the jobs matching filter, a
+// condition on job_id.
+func reindexSearch(q execer, filter string, args ...any) error {
+	if _, err := q.Exec(`
+        DELETE FROM search_index
+        WHERE kind != 'collection' AND item_id IN (SELECT job_id FROM jobs WHERE `+filter+`)`, args...); err != nil {
+		return fmt.Errorf("clear search documents: %w", err)
+	}
+	repeated := append(append(append([]any{}, args...), args...), args...)
+	if _, err := q.Exec(searchDocumentsQuery(filter), repeated...); err != nil {
+		return fmt.Errorf("index search documents: %w", err)
+	}
+	return nil
+}
+
+// reindexJobsSearch rewrites the search documents of the given jobs.
+func reindexJobsSearch(q execer, jobIDs []string) error {
+	if len(jobIDs) == 0 {
+		return nil
+	}
+	ids, err := json.Marshal(jobIDs)
+	if err != nil {
+		return fmt.Errorf("marshal job ids: %w", err)
+	}
+	return reindexSearch(q, `job_id IN (SELECT value FROM json_each(?))`, string(ids))
+}
+
+// reindexCollectionSearch rewrites the search document of one collection,
+// removing it when the collection no longer exists.
+func reindexCollectionSearch(q execer, collectionID string) error {
+	if _, err := q.Exec(`DELETE FROM search_index WHERE kind = 'collection' AND item_id = ?`, collectionID); err != nil {
+		return fmt.Errorf("clear collection search document: %w", err)
+	}
+	if _, err := q.Exec(`
+        INSERT INTO search_index (item_id, kind, title, description, channel, tags, notes)
+        SELECT id, 'collection', name, description, '', '',
+               COALESCE((SELECT a.notes FROM annotations a WHERE a.item_id = collections.id AND a.kind = 'collection'), '')
+        FROM collections WHERE id = ?`, collectionID); err != nil {
+		return fmt.Errorf("index collection: %w", err)
+	}
+	return nil
+}
+
+// rebuildSearchIndex recreates every search document.
+func rebuildSearchIndex(q execer) error {
+	if _, err := q.Exec(`DELETE FROM search_index`); err != nil {
+		return fmt.Errorf("clear search index: %w", err)
+	}
+	if err := reindexSearch(q, `1`); err != nil {
+		return err
+	}
+	if _, err := q.Exec(`
+        INSERT INTO search_index (item_id, kind, title, description, channel, tags, notes)
+        SELECT id, 'collection', name, description, '', '',
+               COALESCE((SELECT a.notes FROM annotations a WHERE a.item_id = collections.id AND a.kind = 'collection'), '')
+        FROM collections`); err != nil {
+		return fmt.Errorf("index collections: %w", err)
+	}
+	return nil
+}
+
+// ftsQuery turns user input into an FTS5 query in which every word and
+// "quoted phrase" must match. A word ending in * matches as a prefix; with
+// prefixAll every bare word does, for search-as-you-type. All terms are
+// quoted, so FTS5 operators and column filters in the input are plain text.
+// It returns "" when the input holds no terms.
+func ftsQuery(input string, prefixAll bool) string {
+	var terms []string
+	add := func(term string, phrase bool) {
+		prefix := strings.HasSuffix(term, "*")
+		term = strings.TrimRight(term, "*")
+		if strings.IndexFunc(term, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsNumber(r) }) < 0 {
+			return
+		}
+		quoted := `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+		if prefix || (prefixAll && !phrase) {
+			quoted += "*"
+		}
+		terms = append(terms, quoted)
+	}
+
+	var current strings.Builder
+	inQuotes := false
+	for _, r := range input {
+		switch {
+		case r == '"':
+			if current.Len() > 0 {
+				add(current.String(), inQuotes)
+				current.Reset()
+			}
+			inQuotes = !inQuotes
+		case unicode.IsSpace(r) && !inQuotes:
+			if current.Len() > 0 {
+				add(current.String(), false)
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		add(current.String(), inQuotes)
+	}
+	return strings.Join(terms, " AND ")
+}
+
+// searchCondition narrows a library listing to jobs whose search document
+// matches an FTS5 query.
+const searchCondition = `jobs.job_id IN (SELECT item_id FROM search_index WHERE search_index MATCH ?)`
+
+// Highlight delimiters, replaced by <mark> elements once the text around them
+// is escaped.
+const (
+	highlightStart = "\x01"
+	highlightEnd   = "\x02"
+)
+
+func markHighlights(s string) string {
+	s = html.EscapeString(s)
+	return strings.NewReplacer(highlightStart, "<mark>", highlightEnd, "</mark>").Replace(s)
+}
+
+type SearchRepository struct {
+	db *sql.DB
+}
+
+func NewSearchRepository(db *sql.DB) *SearchRepository {
+	return &SearchRepository{db: db}
+}
+
+// Search ranks matches with BM25, weighting titles highest, then tags,
+// channels, notes and descriptions.
+func (r *SearchRepository) Search(query string, kind domain.SearchKind, limit int) ([]domain.SearchResult, error) {
+	results := []domain.SearchResult{}
+	match := ftsQuery(query, false)
+	if match == "" {
+		return results, nil
+	}
+
+	args := []any{match}
+	kindFilter := ""
+	if kind != "" {
+		kindFilter = ` AND kind = ?`
+		args = append(args, kind)
+	}
+	args = append(args, limit)
+
+	rows, err := r.db.Query(`
+        SELECT item_id, kind,
+               highlight(search_index, 2, char(1), char(2)),
+               snippet(search_index, -1, char(1), char(2), '…', 16),
+               bm25(search_index, 0, 0, 10.0, 1.0, 4.0, 5.0, 2.0) AS score
+        FROM search_index
+        WHERE search_index MATCH ?`+kindFilter+`
+        ORDER BY score
+        LIMIT ?`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("search: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var result domain.SearchResult
+		var score float64
+		if err := rows.Scan(&result.ID, &result.Kind, &result.Title, &result.Snippet, &score); err != nil {
+			return nil, fmt.Errorf("scan search result: %w", err)
+		}
+		result.Title = markHighlights(result.Title)
+		result.Snippet = markHighlights(result.Snippet)
+		// bm25 scores are negative, better matches more so.
+		result.Score = -score
+		results = append(results, result)
+	}
+	return results, rows.Err()
+}
@@ -0,0 +1,162 @@
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"video-archiver/internal/domain"
+	"video-archiver/internal/testutil"
+)
+
+func TestFTSQuery(t *testing.T) {
+	tests := []struct {
+		input     string
+		prefixAll bool
+		want      string
+	}{
+		{"", false, ""},
+		{"cats dogs", false, `"cats" AND "dogs"`},
+		{"cats dogs", true, `"cats"* AND "dogs"*`},
+		{`"big cats" dog*`, false, `"big cats" AND "dog"*`},
+		{`"big cats"`, true, `"big cats"`},
+		{`title:x OR NEAR(a b)`, false, `"title:x" AND "OR" AND "NEAR(a" AND "b)"`},
+		{`say "hi`, false, `"say" AND "hi"`},
+		{`- * ""`, false, ""},
+	}
+	for _, tt := range tests {
+		if got := ftsQuery(tt.input, tt.prefixAll); got != tt.want {
+			t.Errorf("ftsQuery(%q, %v) = %s, want %s", tt.input, tt.prefixAll, got, tt.want)
+		}
+	}
+}
+
+func TestSearchRepository(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+	jobs := NewJobRepository(db)
+	collections := NewCollectionRepository(db)
+	search := NewSearchRepository(db)
+
+	store := func(id string, meta domain.Metadata) {
+		t.Helper()
+		if err := jobs.Create(testutil.CreateTestJob(id, "https://example.com/"+id)); err != nil {
+			t.Fatalf("Create(%s) error = %v", id, err)
+		}
+		if err := jobs.StoreMetadata(id, meta); err != nil {
+			t.Fatalf("StoreMetadata(%s) error = %v", id, err)
+		}
+	}
+	video := testutil.CreateTestVideoMetadata()
+	video.Title = "Sourdough <basics>"
+	video.Description = "Baking bread with a wild yeast starter"
+	video.Channel = "Kitchen Lab"
+	store("v1", video)
+	other := testutil.CreateTestVideoMetadata()
+	other.Title = "Bread machine review"
+	other.Description = "Gadgets"
+	store("v2", other)
+	playlist := testutil.CreateTestPlaylistMetadata()
+	playlist.Title = "Weekend baking"
+	store("p1", playlist)
+
+	ids := func(query string, kind domain.SearchKind) []string {
+		t.Helper()
+		results, err := search.Search(query, kind, 10)
+		if err != nil {
+			t.Fatalf("Search(%q) error = %v", query, err)
+		}
+		var out []string
+		for _, r := range results {
+			out = append(out, r.ID)
+		}
+		return out
+	}
+
+	if got := ids("bread", ""); len(got) != 2 || got[0] != "v2" {
+		t.Errorf("Search(bread) = %v, want the title match v2 ranked first", got)
+	}
+	if got := ids(`"wild yeast"`, ""); len(got) != 1 || got[0] != "v1" {
+		t.Errorf("phrase search = %v, want v1", got)
+	}
+	if got := ids(`"yeast wild"`, ""); len(got) != 0 {
+		t.Errorf("reversed phrase search = %v, want nothing", got)
+	}
+	if got := ids("bak*", ""); len(got) != 2 {
+		t.Errorf("prefix search = %v, want v1 and p1", got)
+	}
+	if got := ids("bak*", domain.SearchKindPlaylist); len(got) != 1 || got[0] != "p1" {
+		t.Errorf("prefix search for playlists = %v, want p1", got)
+	}
+	if got := ids("kitchen", ""); len(got) != 1 {
+		t.Errorf("channel search = %v, want v1", got)
+	}
+
+	results, _ := search.Search("sourdough", "", 10)
+	if len(results) != 1 || results[0].Title != "<mark>Sourdough</mark> &lt;basics&gt;" || results[0].Score <= 0 {
+		t.Errorf("highlighted result = %+v", results)
+	}
+
+	// Tag changes are indexed, including renames.
+	if _, err := jobs.AddTagsToJob("v2", []string{"Appliances"}, domain.TagSourceUser); err != nil {
+		t.Fatalf("AddTagsToJob() error = %v", err)
+	}
+	if got := ids("appliances", ""); len(got) != 1 || got[0] != "v2" {
+		t.Errorf("tag search = %v, want v2", got)
+	}
+	tag, _ := jobs.GetTagByName("Appliances")
+	tag.Name = "Gear"
+	if err := jobs.UpdateTag(tag); err != nil {
+		t.Fatalf("UpdateTag() error = %v", err)
+	}
+	if got := ids("appliances", ""); len(got) != 0 {
+		t.Errorf("old tag name still found: %v", got)
+	}
+	if got := ids("gear", ""); len(got) != 1 {
+		t.Errorf("renamed tag search = %v, want v2", got)
+	}
+	if err := jobs.DeleteTag(tag.ID); err != nil {
+		t.Fatalf("DeleteTag() error = %v", err)
+	}
+	if got := ids("gear", ""); len(got) != 0 {
+		t.Errorf("deleted tag still found: %v", got)
+	}
+
+	if err := jobs.DeleteJob("v1"); err != nil {
+		t.Fatalf("DeleteJob() error = %v", err)
+	}
+	if got := ids("sourdough", ""); len(got) != 0 {
+		t.Errorf("deleted job still found: %v", got)
+	}
+
+	now := time.Now()
+	collection := &domain.Collection{ID: "c1", Name: "Baking course", Description: "Pastry lessons", CreatedAt: now, UpdatedAt: now}
+	if err := collections.Create(collection); err != nil {
+		t.Fatalf("Create collection error = %v", err)
+	}
+	if got := ids("pastry", domain.SearchKindCollection); len(got) != 1 || got[0] != "c1" {
+		t.Errorf("collection search = %v, want c1", got)
+	}
+	if err := collections.Delete("c1"); err != nil {
+		t.Fatalf("Delete collection error = %v", err)
+	}
+	if got := ids("pastry", ""); len(got) != 0 {
+		t.Errorf("deleted collection still found: %v", got)
+	}
+
+	// The rebuild used by the migration and the backfill restores the index.
+	if _, err := db.Exec(`DELETE FROM search_index`); err != nil {
+		t.Fatal(err)
+	}
+	if err := rebuildSearchIndex(db); err != nil {
+		t.Fatalf("rebuildSearchIndex() error = %v", err)
+	}
+	if got := ids("bread", ""); len(got) != 1 || got[0] != "v2" {
+		t.Errorf("after rebuild Search(bread) = %v, want v2", got)
+	}
+
+	// Library listings use the index for their search.
+	items, total, err := jobs.GetMetadataByType("videos", domain.MetadataQuery{Search: "gadg"})
+	if err != nil || total != 1 || items[0].Job.ID != "v2" {
+		t.Errorf("listing search = %v (total %d, err %v), want v2 by description prefix", items, total, err)
+	}
+}
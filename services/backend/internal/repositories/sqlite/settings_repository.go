@@ -19,12 +19,14 @@ func (r *SettingsRepository) Get() (*domain.Settings, error) {
 	settings := &domain.Settings{}
 	err := r.db.QueryRow(`
         SELECT id, theme, download_quality, concurrent_downloads, tools_default_format,
-               tools_default_quality, tools_preserve_original, tools_output_path, created_at, updated_at
+               tools_default_quality, tools_preserve_original, tools_output_path, archive_comments,
+               availability_check_hours, created_at, updated_at
         FROM settings
         WHERE id = 1`).
 		Scan(&settings.ID, &settings.Theme, &settings.DownloadQuality, &settings.ConcurrentDownloads,
 			&settings.ToolsDefaultFormat, &settings.ToolsDefaultQuality, &settings.ToolsPreserveOriginal,
-			&settings.ToolsOutputPath, &settings.CreatedAt, &settings.UpdatedAt)
+			&settings.ToolsOutputPath, &settings.ArchiveComments, &settings.AvailabilityCheckHours,
+			&settings.CreatedAt, &settings.UpdatedAt)
 	if err != nil {
 		return nil, fmt.Errorf("get settings: %w", err)
 	}
@@ -37,11 +39,13 @@ func (r *SettingsRepository) Update(settings *domain.Settings) error {
         UPDATE settings
         SET theme = ?, download_quality = ?, concurrent_downloads = ?,
             tools_default_format = ?, tools_default_quality = ?,
-            tools_preserve_original = ?, tools_output_path = ?, updated_at = ?
+            tools_preserve_original = ?, tools_output_path = ?, archive_comments = ?,
+            availability_check_hours = ?, updated_at = ?
         WHERE id = 1`,
 		settings.Theme, settings.DownloadQuality, settings.ConcurrentDownloads,
 		settings.ToolsDefaultFormat, settings.ToolsDefaultQuality,
-		settings.ToolsPreserveOriginal, settings.ToolsOutputPath, settings.UpdatedAt)
+		settings.ToolsPreserveOriginal, settings.ToolsOutputPath, settings.ArchiveComments,
+		settings.AvailabilityCheckHours, settings.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("update settings: %w", err)
 	}
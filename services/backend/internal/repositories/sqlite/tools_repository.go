@@ -31,12 +31,14 @@ func (r *ToolsRepository) Create(job *domain.ToolsJob) error {
         INSERT INTO tools_jobs (id, operation_type, status, progress, input_files, input_type,
                                  output_file, parameters, error_message, created_at, updated_at,
                                  completed_at, estimated_size, actual_size,
-                                 media_kind, duration, width, height, video_codec, audio_codec)
-        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+                                 media_kind, duration, width, height, video_codec, audio_codec,
+                                 automation_rule_id, source_job_id)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		job.ID, job.OperationType, job.Status, job.Progress, string(inputFilesJSON), job.InputType,
 		job.OutputFile, string(paramsJSON), job.ErrorMessage, job.CreatedAt, job.UpdatedAt,
 		job.CompletedAt, job.EstimatedSize, job.ActualSize,
-		job.MediaKind, job.Duration, job.Width, job.Height, job.VideoCodec, job.AudioCodec)
+		job.MediaKind, job.Duration, job.Width, job.Height, job.VideoCodec, job.AudioCodec,
+		job.AutomationRuleID, job.SourceJobID)
 	if err != nil {
 		return fmt.Errorf("create tools job: %w", err)
 	}
@@ -81,13 +83,15 @@ func (r *ToolsRepository) GetByID(id string) (*domain.ToolsJob, error) {
         SELECT id, operation_type, status, progress, input_files, input_type,
                output_file, parameters, error_message, created_at, updated_at,
                completed_at, estimated_size, actual_size,
-               media_kind, duration, width, height, video_codec, audio_codec
+               media_kind, duration, width, height, video_codec, audio_codec,
+               automation_rule_id, source_job_id
         FROM tools_jobs
         WHERE id = ?`, id).
 		Scan(&job.ID, &job.OperationType, &job.Status, &job.Progress, &inputFilesJSON, &job.InputType,
 			&job.OutputFile, &paramsJSON, &job.ErrorMessage, &job.CreatedAt, &job.UpdatedAt,
 			&job.CompletedAt, &job.EstimatedSize, &job.ActualSize,
-			&job.MediaKind, &job.Duration, &job.Width, &job.Height, &job.VideoCodec, &job.AudioCodec)
+			&job.MediaKind, &job.Duration, &job.Width, &job.Height, &job.VideoCodec, &job.AudioCodec,
+			&job.AutomationRuleID, &job.SourceJobID)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -112,7 +116,8 @@ func (r *ToolsRepository) GetAll() ([]*domain.ToolsJob, error) {
         SELECT id, operation_type, status, progress, input_files, input_type,
                output_file, parameters, error_message, created_at, updated_at,
                completed_at, estimated_size, actual_size,
-               media_kind, duration, width, height, video_codec, audio_codec
+               media_kind, duration, width, height, video_codec, audio_codec,
+               automation_rule_id, source_job_id
         FROM tools_jobs
         ORDER BY created_at DESC`)
 	if err != nil {
@@ -128,7 +133,8 @@ func (r *ToolsRepository) GetByStatus(status domain.ToolsJobStatus) ([]*domain.T
         SELECT id, operation_type, status, progress, input_files, input_type,
                output_file, parameters, error_message, created_at, updated_at,
                completed_at, estimated_size, actual_size,
-               media_kind, duration, width, height, video_codec, audio_codec
+               media_kind, duration, width, height, video_codec, audio_codec,
+               automation_rule_id, source_job_id
         FROM tools_jobs
         WHERE status = ?
         ORDER BY created_at DESC`, status)
@@ -140,9 +146,9 @@ func (r *ToolsRepository) GetByStatus(status domain.ToolsJobStatus) ([]*domain.T
 	return r.scanJobs(rows)
 }
 
-// FindLatestConvertForInput returns the most recent convert job whose only
-// input is the given download job — the transcode-for-playback lookup. Returns
-// nil (no error) when none exists.
+// FindLatestConvertForInput returns the most recent convert job, or remux
+// job keeping the original, whose only input is the given download job — the
+// transcode-for-playback lookup. Returns nil (no error) when none exists.
 func (r *ToolsRepository) FindLatestConvertForInput(jobID string) (*domain.ToolsJob, error) {
 	inputFilesJSON, err := json.Marshal([]string{jobID})
 	if err != nil {
@@ -152,11 +158,15 @@ func (r *ToolsRepository) FindLatestConvertForInput(jobID string) (*domain.Tools
 	rows, err := r.db.Query(`
         SELECT id, operation_type, status, progress, input_files, input_type,
                output_file, parameters, error_message, created_at, updated_at,
-               completed_at, estimated_size, actual_size
+               completed_at, estimated_size, actual_size,
+               media_kind, duration, width, height, video_codec, audio_codec,
+               automation_rule_id, source_job_id
         FROM tools_jobs
-        WHERE operation_type = ? AND input_files = ?
+        WHERE input_files = ?
+          AND (operation_type = ? OR
+               (operation_type = ? AND NOT COALESCE(json_extract(parameters, '$.replace_original'), 0)))
         ORDER BY created_at DESC
-        LIMIT 1`, domain.OpTypeConvert, string(inputFilesJSON))
+        LIMIT 1`, string(inputFilesJSON), domain.OpTypeConvert, domain.OpTypeRemux)
 	if err != nil {
 		return nil, fmt.Errorf("query convert job for input: %w", err)
 	}
@@ -172,6 +182,31 @@ func (r *ToolsRepository) FindLatestConvertForInput(jobID string) (*domain.Tools
 	return jobs[0], nil
 }
 
+func (r *ToolsRepository) ListBySourceJob(sourceJobID string) ([]*domain.ToolsJob, error) {
+	rows, err := r.db.Query(`
+        SELECT id, operation_type, status, progress, input_files, input_type,
+               output_file, parameters, error_message, created_at, updated_at,
+               completed_at, estimated_size, actual_size,
+               media_kind, duration, width, height, video_codec, audio_codec,
+               automation_rule_id, source_job_id
+        FROM tools_jobs
+        WHERE source_job_id = ?
+        ORDER BY created_at DESC`, sourceJobID)
+	if err != nil {
+		return nil, fmt.Errorf("query tools jobs by source job: %w", err)
+	}
+	defer rows.Close()
+
+	jobs, err := r.scanJobs(rows)
+	if err != nil {
+		return nil, err
+	}
+	if jobs == nil {
+		jobs = []*domain.ToolsJob{}
+	}
+	return jobs, nil
+}
+
 func (r *ToolsRepository) Delete(id string) error {
 	_, err := r.db.Exec(`DELETE FROM tools_jobs WHERE id = ?`, id)
 	if err != nil {
@@ -216,7 +251,8 @@ func (r *ToolsRepository) List(page int, limit int, status string, operationType
         SELECT id, operation_type, status, progress, input_files, input_type,
                output_file, parameters, error_message, created_at, updated_at,
                completed_at, estimated_size, actual_size,
-               media_kind, duration, width, height, video_codec, audio_codec
+               media_kind, duration, width, height, video_codec, audio_codec,
+               automation_rule_id, source_job_id
         FROM tools_jobs
         %s
         ORDER BY created_at DESC
@@ -247,7 +283,8 @@ func (r *ToolsRepository) scanJobs(rows *sql.Rows) ([]*domain.ToolsJob, error) {
 		err := rows.Scan(&job.ID, &job.OperationType, &job.Status, &job.Progress, &inputFilesJSON, &job.InputType,
 			&job.OutputFile, &paramsJSON, &job.ErrorMessage, &job.CreatedAt, &job.UpdatedAt,
 			&job.CompletedAt, &job.EstimatedSize, &job.ActualSize,
-			&job.MediaKind, &job.Duration, &job.Width, &job.Height, &job.VideoCodec, &job.AudioCodec)
+			&job.MediaKind, &job.Duration, &job.Width, &job.Height, &job.VideoCodec, &job.AudioCodec,
+			&job.AutomationRuleID, &job.SourceJobID)
 		if err != nil {
 			return nil, fmt.Errorf("scan tools job: %w", err)
 		}
@@ -0,0 +1,226 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"video-archiver/internal/domain"
+)
+
+// maxWebhookDeliveries is how many finished deliveries the log keeps per
+// webhook; older ones are dropped as new ones are logged.
+const maxWebhookDeliveries = 500
+
+type WebhookRepository struct {
+	db *sql.DB
+}
+
+func NewWebhookRepository(db *sql.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+func (r *WebhookRepository) Create(webhook *domain.Webhook) error {
+	eventsJSON, err := json.Marshal(webhookEvents(webhook))
+	if err != nil {
+		return fmt.Errorf("marshal webhook events: %w", err)
+	}
+	_, err = r.db.Exec(`
+        INSERT INTO webhooks (id, name, url, secret, events_json, enabled, created_at, updated_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		webhook.ID, webhook.Name, webhook.URL, webhook.Secret, string(eventsJSON), webhook.Enabled,
+		webhook.CreatedAt, webhook.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("create webhook: %w", err)
+	}
+	return nil
+}
+
+func (r *WebhookRepository) Update(webhook *domain.Webhook) error {
+	eventsJSON, err := json.Marshal(webhookEvents(webhook))
+	if err != nil {
+		return fmt.Errorf("marshal webhook events: %w", err)
+	}
+	webhook.UpdatedAt = time.Now()
+	res, err := r.db.Exec(`
+        UPDATE webhooks
+        SET name = ?, url = ?, secret = ?, events_json = ?, enabled = ?, updated_at = ?
+        WHERE id = ?`,
+		webhook.Name, webhook.URL, webhook.Secret, string(eventsJSON), webhook.Enabled,
+		webhook.UpdatedAt, webhook.ID)
+	if err != nil {
+		return fmt.Errorf("update webhook: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("webhook not found")
+	}
+	return nil
+}
+
+// Delete removes a webhook together with its delivery log.
+func (r *WebhookRepository) Delete(id string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin delete webhook: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM webhook_deliveries WHERE webhook_id = ?`, id); err != nil {
+		return fmt.Errorf("delete webhook deliveries: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM webhooks WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("delete webhook: %w", err)
+	}
+	return tx.Commit()
+}
+
+const webhookSelect = `
+    SELECT id, name, url, secret, events_json, enabled, created_at, updated_at
+    FROM webhooks`
+
+func scanWebhook(row interface{ Scan(...any) error }) (*domain.Webhook, error) {
+	w := &domain.Webhook{}
+	var eventsJSON string
+	err := row.Scan(&w.ID, &w.Name, &w.URL, &w.Secret, &eventsJSON, &w.Enabled, &w.CreatedAt, &w.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(eventsJSON), &w.Events); err != nil {
+		return nil, fmt.Errorf("unmarshal webhook events: %w", err)
+	}
+	return w, nil
+}
+
+func (r *WebhookRepository) GetByID(id string) (*domain.Webhook, error) {
+	w, err := scanWebhook(r.db.QueryRow(webhookSelect+` WHERE id = ?`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get webhook by id: %w", err)
+	}
+	return w, nil
+}
+
+func (r *WebhookRepository) List() ([]*domain.Webhook, error) {
+	rows, err := r.db.Query(webhookSelect + ` ORDER BY created_at ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("list webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	webhooks := []*domain.Webhook{}
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan webhook: %w", err)
+		}
+		webhooks = append(webhooks, w)
+	}
+	return webhooks, rows.Err()
+}
+
+// webhookEvents returns the event filter to store; nil is stored as [] so
+// the column always holds a JSON array.
+func webhookEvents(webhook *domain.Webhook) []domain.WebhookEvent {
+	if webhook.Events == nil {
+		return []domain.WebhookEvent{}
+	}
+	return webhook.Events
+}
+
+// CreateDelivery inserts a delivery log entry and sets its ID, then drops
+// the webhook's finished deliveries beyond the newest maxWebhookDeliveries.
+// Pending ones are kept, since a retry is still going to update them.
+func (r *WebhookRepository) CreateDelivery(delivery *domain.WebhookDelivery) error {
+	now := time.Now()
+	if delivery.CreatedAt.IsZero() {
+		delivery.CreatedAt = now
+	}
+	delivery.UpdatedAt = now
+	res, err := r.db.Exec(`
+        INSERT INTO webhook_deliveries (webhook_id, event, payload, status, attempts, response_status,
+                                        error, created_at, updated_at, delivered_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		delivery.WebhookID, delivery.Event, delivery.Payload, delivery.Status, delivery.Attempts,
+		delivery.ResponseStatus, delivery.Error, delivery.CreatedAt, delivery.UpdatedAt, delivery.DeliveredAt)
+	if err != nil {
+		return fmt.Errorf("create webhook delivery: %w", err)
+	}
+	if delivery.ID, err = res.LastInsertId(); err != nil {
+		return fmt.Errorf("webhook delivery id: %w", err)
+	}
+	_, err = r.db.Exec(`
+        DELETE FROM webhook_deliveries
+        WHERE webhook_id = ? AND status != ? AND id <= (
+            SELECT id FROM webhook_deliveries WHERE webhook_id = ?
+            ORDER BY id DESC LIMIT 1 OFFSET ?)`,
+		delivery.WebhookID, domain.WebhookDeliveryPending, delivery.WebhookID, maxWebhookDeliveries)
+	if err != nil {
+		return fmt.Errorf("trim webhook deliveries: %w", err)
+	}
+	return nil
+}
+
+// UpdateDelivery stores the outcome of the latest attempt. The payload is
+// written too, since it embeds the delivery ID assigned on creation.
+func (r *WebhookRepository) UpdateDelivery(delivery *domain.WebhookDelivery) error {
+	delivery.UpdatedAt = time.Now()
+	_, err := r.db.Exec(`
+        UPDATE webhook_deliveries
+        SET payload = ?, status = ?, attempts = ?, response_status = ?, error = ?, updated_at = ?, delivered_at = ?
+        WHERE id = ?`,
+		delivery.Payload, delivery.Status, delivery.Attempts, delivery.ResponseStatus, delivery.Error,
+		delivery.UpdatedAt, delivery.DeliveredAt, delivery.ID)
+	if err != nil {
+		return fmt.Errorf("update webhook delivery: %w", err)
+	}
+	return nil
+}
+
+const deliverySelect = `
+    SELECT id, webhook_id, event, payload, status, attempts, response_status, error,
+           created_at, updated_at, delivered_at
+    FROM webhook_deliveries`
+
+func scanDelivery(row interface{ Scan(...any) error }) (*domain.WebhookDelivery, error) {
+	d := &domain.WebhookDelivery{}
+	var deliveredAt sql.NullTime
+	err := row.Scan(&d.ID, &d.WebhookID, &d.Event, &d.Payload, &d.Status, &d.Attempts,
+		&d.ResponseStatus, &d.Error, &d.CreatedAt, &d.UpdatedAt, &deliveredAt)
+	if err != nil {
+		return nil, err
+	}
+	if deliveredAt.Valid {
+		d.DeliveredAt = &deliveredAt.Time
+	}
+	return d, nil
+}
+
+func (r *WebhookRepository) ListDeliveries(webhookID string, limit int) ([]*domain.WebhookDelivery, error) {
+	return r.queryDeliveries(deliverySelect+` WHERE webhook_id = ? ORDER BY id DESC LIMIT ?`, webhookID, limit)
+}
+
+func (r *WebhookRepository) ListPendingDeliveries() ([]*domain.WebhookDelivery, error) {
+	return r.queryDeliveries(deliverySelect+` WHERE status = ? ORDER BY id ASC`, domain.WebhookDeliveryPending)
+}
+
+func (r *WebhookRepository) queryDeliveries(query string, args ...any) ([]*domain.WebhookDelivery, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []*domain.WebhookDelivery{}
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
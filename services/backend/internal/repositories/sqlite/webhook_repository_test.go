@@ -0,0 +1,136 @@
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"video-archiver/internal/domain"
+	"video-archiver/internal/testutil"
+)
+
+func TestWebhookRepository(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+	repo := NewWebhookRepository(db)
+
+	now := time.Now()
+	webhook := &domain.Webhook{
+		ID:        "hook-1",
+		Name:      "Chat",
+		URL:       "https://example.com/hook",
+		Secret:    "s3cret",
+		Events:    []domain.WebhookEvent{domain.WebhookEventDownloadCompleted},
+		Enabled:   true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := repo.Create(webhook); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	got, err := repo.GetByID(webhook.ID)
+	if err != nil || got == nil {
+		t.Fatalf("GetByID() = %v, %v", got, err)
+	}
+	if got.URL != webhook.URL || !got.Enabled || len(got.Events) != 1 || got.Events[0] != domain.WebhookEventDownloadCompleted {
+		t.Errorf("GetByID() = %+v", got)
+	}
+	if missing, err := repo.GetByID("nope"); err != nil || missing != nil {
+		t.Errorf("GetByID(missing) = %v, %v; want nil", missing, err)
+	}
+
+	got.Events = nil
+	got.Enabled = false
+	if err := repo.Update(got); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	list, err := repo.List()
+	if err != nil || len(list) != 1 || list[0].Enabled || len(list[0].Events) != 0 {
+		t.Errorf("List() = %+v, %v", list, err)
+	}
+	if err := repo.Update(&domain.Webhook{ID: "nope"}); err == nil {
+		t.Error("Update(missing) succeeded")
+	}
+
+	delivery := &domain.WebhookDelivery{
+		WebhookID: webhook.ID,
+		Event:     domain.WebhookEventDownloadCompleted,
+		Payload:   `{}`,
+		Status:    domain.WebhookDeliveryPending,
+	}
+	if err := repo.CreateDelivery(delivery); err != nil || delivery.ID == 0 {
+		t.Fatalf("CreateDelivery() = %d, %v", delivery.ID, err)
+	}
+	second := &domain.WebhookDelivery{WebhookID: webhook.ID, Event: domain.WebhookEventPing, Payload: `{}`, Status: domain.WebhookDeliveryPending}
+	repo.CreateDelivery(second)
+
+	delivered := time.Now()
+	second.Status = domain.WebhookDeliveryDelivered
+	second.Attempts = 2
+	second.ResponseStatus = 204
+	second.DeliveredAt = &delivered
+	if err := repo.UpdateDelivery(second); err != nil {
+		t.Fatalf("UpdateDelivery() error = %v", err)
+	}
+
+	deliveries, err := repo.ListDeliveries(webhook.ID, 10)
+	if err != nil || len(deliveries) != 2 {
+		t.Fatalf("ListDeliveries() = %v, %v", deliveries, err)
+	}
+	if deliveries[0].ID != second.ID || deliveries[0].Attempts != 2 || deliveries[0].ResponseStatus != 204 || deliveries[0].DeliveredAt == nil {
+		t.Errorf("newest delivery = %+v", deliveries[0])
+	}
+
+	pending, err := repo.ListPendingDeliveries()
+	if err != nil || len(pending) != 1 || pending[0].ID != delivery.ID {
+		t.Errorf("ListPendingDeliveries() = %v, %v", pending, err)
+	}
+
+	if err := repo.Delete(webhook.ID); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if deliveries, _ := repo.ListDeliveries(webhook.ID, 10); len(deliveries) != 0 {
+		t.Errorf("deliveries kept after delete: %v", deliveries)
+	}
+}
+
+func TestWebhookRepository_CreateDeliveryTrimsLog(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+	repo := NewWebhookRepository(db)
+
+	now := time.Now()
+	for _, id := range []string{"hook-1", "hook-2"} {
+		repo.Create(&domain.Webhook{ID: id, URL: "https://example.com/" + id, CreatedAt: now, UpdatedAt: now})
+	}
+	// The oldest delivery is still being retried and must survive the trim.
+	retrying := &domain.WebhookDelivery{WebhookID: "hook-1", Event: domain.WebhookEventPing, Payload: `{}`, Status: domain.WebhookDeliveryPending}
+	repo.CreateDelivery(retrying)
+	other := &domain.WebhookDelivery{WebhookID: "hook-2", Event: domain.WebhookEventPing, Payload: `{}`, Status: domain.WebhookDeliveryDelivered}
+	repo.CreateDelivery(other)
+
+	var newest *domain.WebhookDelivery
+	for i := 0; i < maxWebhookDeliveries+5; i++ {
+		newest = &domain.WebhookDelivery{WebhookID: "hook-1", Event: domain.WebhookEventPing, Payload: `{}`, Status: domain.WebhookDeliveryDelivered}
+		if err := repo.CreateDelivery(newest); err != nil {
+			t.Fatalf("CreateDelivery() error = %v", err)
+		}
+	}
+
+	deliveries, err := repo.ListDeliveries("hook-1", 2*maxWebhookDeliveries)
+	if err != nil {
+		t.Fatalf("ListDeliveries() error = %v", err)
+	}
+	if len(deliveries) != maxWebhookDeliveries+1 {
+		t.Errorf("kept %d deliveries, want %d plus the pending one", len(deliveries), maxWebhookDeliveries)
+	}
+	if deliveries[0].ID != newest.ID {
+		t.Errorf("newest kept delivery = %d, want %d", deliveries[0].ID, newest.ID)
+	}
+	if pending, _ := repo.ListPendingDeliveries(); len(pending) != 1 || pending[0].ID != retrying.ID {
+		t.Errorf("pending deliveries after trim = %v, want the retrying one", pending)
+	}
+	if kept, _ := repo.ListDeliveries("hook-2", 10); len(kept) != 1 {
+		t.Errorf("another webhook's log = %v, want untouched", kept)
+	}
+}
@@ -0,0 +1,239 @@
+package automation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"video-archiver/internal/domain"
+	"video-archiver/internal/services/tools"
+)
+
+// defaultQueueRetryDelay spaces out submissions while the tools queue is
+// full.
+const defaultQueueRetryDelay = 5 * time.Second
+
+// Submitter enqueues tools jobs; the tools service satisfies it.
+type Submitter interface {
+	Submit(job *domain.ToolsJob) error
+}
+
+// Prober inspects a media file's streams; tools.FFmpeg satisfies it.
+type Prober interface {
+	Probe(path string) (*tools.MediaInfo, error)
+}
+
+type Config struct {
+	Rules           domain.AutomationRepository
+	JobRepository   domain.JobRepository
+	ToolsRepository domain.ToolsRepository
+	Tools           Submitter
+	// Prober defaults to tools.NewFFmpeg(). Files are only probed when an
+	// enabled rule matches on browser-safe codecs.
+	Prober Prober
+}
+
+// Service runs automation rules on completed downloads. A rule runs at most
+// once per video: videos that already have a tools job from the rule are
+// skipped, so re-syncing a playlist only processes its new videos.
+type Service struct {
+	rules     domain.AutomationRepository
+	jobs      domain.JobRepository
+	toolsRepo domain.ToolsRepository
+	tools     Submitter
+	prober    Prober
+	// queueRetryDelay is defaultQueueRetryDelay; tests shorten it.
+	queueRetryDelay time.Duration
+
+	wg     sync.WaitGroup
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func NewService(config *Config) *Service {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	prober := config.Prober
+	if prober == nil {
+		prober = tools.NewFFmpeg()
+	}
+
+	return &Service{
+		rules:     config.Rules,
+		jobs:      config.JobRepository,
+		toolsRepo: config.ToolsRepository,
+		tools:     config.Tools,
+		prober:    prober,
+		ctx:       ctx,
+		cancel:    cancel,
+
+		queueRetryDelay: defaultQueueRetryDelay,
+	}
+}
+
+func (s *Service) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// DownloadCompleted evaluates the rules against a completed download in the
+// background, keeping tag lookups and probes off the download worker.
+func (s *Service) DownloadCompleted(job domain.Job) {
+	if s.ctx.Err() != nil {
+		return
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		if _, err := s.apply(job.ID); err != nil {
+			log.WithError(err).WithField("jobID", job.ID).Warn("Failed to run automation rules")
+		}
+	}()
+}
+
+// apply runs the enabled rules on a completed download — the video itself,
+// or every video of a playlist or channel — and returns the tools jobs it
+// submitted.
+func (s *Service) apply(jobID string) ([]*domain.ToolsJob, error) {
+	rules, err := s.enabledRules()
+	if err != nil || len(rules) == 0 {
+		return nil, err
+	}
+
+	download, err := s.jobs.GetJobWithMetadata(jobID)
+	if err != nil {
+		return nil, fmt.Errorf("get job: %w", err)
+	}
+	if download == nil || download.Job == nil {
+		return nil, fmt.Errorf("job %s not found", jobID)
+	}
+
+	videos := []*domain.JobWithMetadata{download}
+	if _, ok := download.Metadata.(*domain.VideoMetadata); !ok {
+		if videos, err = s.jobs.GetVideosForParent(jobID); err != nil {
+			return nil, fmt.Errorf("get videos: %w", err)
+		}
+	}
+
+	needsProbe := false
+	for _, rule := range rules {
+		if rule.Match.BrowserSafe != nil {
+			needsProbe = true
+		}
+	}
+
+	var submitted []*domain.ToolsJob
+	for _, video := range videos {
+		if s.ctx.Err() != nil {
+			break
+		}
+		metadata, ok := video.Metadata.(*domain.VideoMetadata)
+		if !ok || video.Job == nil {
+			continue
+		}
+		submitted = append(submitted, s.applyToVideo(rules, download.Job, video.Job, metadata, needsProbe)...)
+	}
+	return submitted, nil
+}
+
+func (s *Service) enabledRules() ([]*domain.AutomationRule, error) {
+	all, err := s.rules.List()
+	if err != nil {
+		return nil, fmt.Errorf("list automation rules: %w", err)
+	}
+	var rules []*domain.AutomationRule
+	for _, rule := range all {
+		if rule.Enabled {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+func (s *Service) applyToVideo(rules []*domain.AutomationRule, download, video *domain.Job, metadata *domain.VideoMetadata, needsProbe bool) []*domain.ToolsJob {
+	existing, err := s.toolsRepo.ListBySourceJob(video.ID)
+	if err != nil {
+		log.WithError(err).WithField("jobID", video.ID).Warn("Failed to list automation jobs")
+		return nil
+	}
+	ran := make(map[string]bool, len(existing))
+	for _, job := range existing {
+		ran[job.AutomationRuleID] = true
+	}
+
+	subject := s.subject(download, video, metadata, needsProbe)
+
+	var submitted []*domain.ToolsJob
+	for _, rule := range rules {
+		if ran[rule.ID] || !rule.Match.Matches(subject) {
+			continue
+		}
+		job, err := tools.NewWorkflowJob(rule.Workflow, video.ID)
+		if err != nil {
+			log.WithError(err).WithField("ruleID", rule.ID).Warn("Failed to build automation job")
+			continue
+		}
+		job.AutomationRuleID = rule.ID
+		job.SourceJobID = video.ID
+		if err := s.submit(job); err != nil {
+			log.WithError(err).WithFields(log.Fields{"ruleID": rule.ID, "jobID": video.ID}).
+				Warn("Failed to submit automation job")
+			continue
+		}
+		log.WithFields(log.Fields{"ruleID": rule.ID, "jobID": video.ID, "toolsJobID": job.ID}).
+			Info("Automation rule matched")
+		submitted = append(submitted, job)
+	}
+	return submitted
+}
+
+// submit enqueues job, waiting while the tools queue is full so the videos
+// of a large playlist are paced to the queue instead of dropped.
+func (s *Service) submit(job *domain.ToolsJob) error {
+	for {
+		err := s.tools.Submit(job)
+		if err == nil || !errors.Is(err, tools.ErrQueueFull) {
+			return err
+		}
+		select {
+		case <-s.ctx.Done():
+			return err
+		case <-time.After(s.queueRetryDelay):
+		}
+	}
+}
+
+// subject collects what rules match on. Videos of a playlist or channel
+// inherit the download's media type when they don't record their own.
+func (s *Service) subject(download, video *domain.Job, metadata *domain.VideoMetadata, probe bool) domain.AutomationSubject {
+	subject := domain.AutomationSubject{
+		MediaType: video.MediaType,
+		Channels:  []string{metadata.Channel, metadata.ChannelID, metadata.Uploader},
+		Duration:  metadata.Duration,
+	}
+	if subject.MediaType == "" {
+		subject.MediaType = download.MediaType
+	}
+
+	if tags, err := s.jobs.GetTagsForJob(video.ID); err != nil {
+		log.WithError(err).WithField("jobID", video.ID).Warn("Failed to get tags for automation")
+	} else {
+		for _, tag := range tags {
+			subject.Tags = append(subject.Tags, tag.Name)
+		}
+	}
+
+	if probe && video.FilePath != "" {
+		info, err := s.prober.Probe(video.FilePath)
+		if err != nil {
+			log.WithError(err).WithField("jobID", video.ID).Warn("Failed to probe video for automation")
+		} else if info.HasVideo {
+			safe := domain.BrowserSafeCodecs(info.VideoCodec, info.AudioCodec, info.HasAudio)
+			subject.BrowserSafe = &safe
+		}
+	}
+	return subject
+}
@@ -0,0 +1,188 @@
+package automation
+
+import (
+	"testing"
+	"time"
+
+	"video-archiver/internal/domain"
+	"video-archiver/internal/repositories/sqlite"
+	"video-archiver/internal/services/tools"
+	"video-archiver/internal/testutil"
+)
+
+// recordingTools is a Submitter and the ListBySourceJob half of a
+// ToolsRepository, keeping submitted jobs in memory. The first full
+// submissions are rejected as if the queue were full.
+type recordingTools struct {
+	domain.ToolsRepository
+	jobs []*domain.ToolsJob
+	full int
+}
+
+func (r *recordingTools) Submit(job *domain.ToolsJob) error {
+	if r.full > 0 {
+		r.full--
+		return tools.ErrQueueFull
+	}
+	job.ID = "tools-" + job.SourceJobID + "-" + job.AutomationRuleID
+	job.Status = domain.ToolsJobStatusPending
+	r.jobs = append(r.jobs, job)
+	return nil
+}
+
+func (r *recordingTools) ListBySourceJob(sourceJobID string) ([]*domain.ToolsJob, error) {
+	var out []*domain.ToolsJob
+	for _, job := range r.jobs {
+		if job.SourceJobID == sourceJobID {
+			out = append(out, job)
+		}
+	}
+	return out, nil
+}
+
+// fakeProber reports the same streams for every file.
+type fakeProber struct {
+	info  tools.MediaInfo
+	calls int
+}
+
+func (p *fakeProber) Probe(string) (*tools.MediaInfo, error) {
+	p.calls++
+	info := p.info
+	return &info, nil
+}
+
+func newTestService(t *testing.T) (*Service, domain.AutomationRepository, *testutil.MockJobRepository, *recordingTools, *fakeProber) {
+	t.Helper()
+	db := testutil.CreateTestDB(t)
+	t.Cleanup(func() { db.Close() })
+
+	rules := sqlite.NewAutomationRepository(db)
+	jobs := testutil.NewMockJobRepository()
+	toolsFake := &recordingTools{}
+	prober := &fakeProber{info: tools.MediaInfo{HasVideo: true, HasAudio: true, VideoCodec: "vp9", AudioCodec: "opus"}}
+	svc := NewService(&Config{
+		Rules:           rules,
+		JobRepository:   jobs,
+		ToolsRepository: toolsFake,
+		Tools:           toolsFake,
+		Prober:          prober,
+	})
+	t.Cleanup(svc.Stop)
+	return svc, rules, jobs, toolsFake, prober
+}
+
+func addRule(t *testing.T, rules domain.AutomationRepository, id string, enabled bool, match domain.AutomationMatch, op domain.ToolsOperationType) {
+	t.Helper()
+	now := time.Now()
+	err := rules.Create(&domain.AutomationRule{
+		ID:      id,
+		Name:    id,
+		Enabled: enabled,
+		Match:   match,
+		Workflow: domain.WorkflowParameters{
+			Steps: []domain.WorkflowStep{{Operation: op, Parameters: map[string]any{"output_format": "mp3"}}},
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func addVideo(t *testing.T, jobs *testutil.MockJobRepository, id string, tags ...string) {
+	t.Helper()
+	job := testutil.CreateTestJob(id, "https://example.com/"+id)
+	job.Status = domain.JobStatusComplete
+	job.FilePath = "/downloads/" + id + ".mp4"
+	jobs.Create(job)
+	jobs.StoreMetadata(id, testutil.CreateTestVideoMetadata())
+	jobs.AddTagsToJob(id, tags, domain.TagSourceUser)
+}
+
+func TestApplySubmitsMatchingRulesOnce(t *testing.T) {
+	svc, rules, jobs, toolsFake, prober := newTestService(t)
+	addRule(t, rules, "podcast-mp3", true, domain.AutomationMatch{Tags: []string{"podcast"}}, domain.OpTypeExtractAudio)
+	addRule(t, rules, "disabled", false, domain.AutomationMatch{}, domain.OpTypeExtractAudio)
+	addRule(t, rules, "long-only", true, domain.AutomationMatch{MinDuration: 3600}, domain.OpTypeExtractAudio)
+	addVideo(t, jobs, "video-1", "Podcast")
+
+	submitted, err := svc.apply("video-1")
+	if err != nil {
+		t.Fatalf("apply() error = %v", err)
+	}
+	if len(submitted) != 1 {
+		t.Fatalf("apply() submitted %d jobs, want 1", len(submitted))
+	}
+	job := submitted[0]
+	if job.AutomationRuleID != "podcast-mp3" || job.SourceJobID != "video-1" ||
+		job.OperationType != domain.OpTypeWorkflow || len(job.InputFiles) != 1 || job.InputFiles[0] != "video-1" {
+		t.Errorf("submitted job = %+v", job)
+	}
+	if prober.calls != 0 {
+		t.Errorf("probed %d times without a codec rule", prober.calls)
+	}
+
+	if again, _ := svc.apply("video-1"); len(again) != 0 {
+		t.Errorf("second apply() submitted %d jobs, want 0", len(again))
+	}
+	if len(toolsFake.jobs) != 1 {
+		t.Errorf("tools jobs = %d, want 1", len(toolsFake.jobs))
+	}
+}
+
+func TestApplyWaitsWhileQueueIsFull(t *testing.T) {
+	svc, rules, jobs, toolsFake, _ := newTestService(t)
+	svc.queueRetryDelay = time.Millisecond
+	toolsFake.full = 3
+	addRule(t, rules, "all-mp3", true, domain.AutomationMatch{}, domain.OpTypeExtractAudio)
+
+	playlist := testutil.CreateTestJob("playlist-1", "https://example.com/playlist")
+	jobs.Create(playlist)
+	jobs.StoreMetadata(playlist.ID, testutil.CreateTestPlaylistMetadata())
+	for _, id := range []string{"video-1", "video-2"} {
+		addVideo(t, jobs, id)
+		jobs.AddVideoToParent(id, playlist.ID, "playlist")
+	}
+
+	submitted, err := svc.apply("playlist-1")
+	if err != nil {
+		t.Fatalf("apply() error = %v", err)
+	}
+	if len(submitted) != 2 || len(toolsFake.jobs) != 2 {
+		t.Errorf("submitted %d jobs (%d recorded), want 2", len(submitted), len(toolsFake.jobs))
+	}
+}
+
+func TestApplyMatchesCodecsAcrossPlaylist(t *testing.T) {
+	svc, rules, jobs, _, prober := newTestService(t)
+	notSafe := false
+	addRule(t, rules, "transcode", true, domain.AutomationMatch{BrowserSafe: &notSafe}, domain.OpTypeConvert)
+
+	playlist := testutil.CreateTestJob("playlist-1", "https://example.com/playlist")
+	playlist.MediaType = domain.MediaTypeVideo
+	jobs.Create(playlist)
+	jobs.StoreMetadata(playlist.ID, testutil.CreateTestPlaylistMetadata())
+	for _, id := range []string{"video-1", "video-2"} {
+		addVideo(t, jobs, id)
+		jobs.AddVideoToParent(id, playlist.ID, "playlist")
+	}
+
+	submitted, err := svc.apply(playlist.ID)
+	if err != nil {
+		t.Fatalf("apply() error = %v", err)
+	}
+	if len(submitted) != 2 || submitted[0].SourceJobID != "video-1" || submitted[1].SourceJobID != "video-2" {
+		t.Fatalf("apply() = %+v, want one job per video", submitted)
+	}
+	if prober.calls != 2 {
+		t.Errorf("probed %d times, want 2", prober.calls)
+	}
+
+	prober.info = tools.MediaInfo{HasVideo: true, HasAudio: true, VideoCodec: "h264", AudioCodec: "aac"}
+	addVideo(t, jobs, "video-3")
+	if got, _ := svc.apply("video-3"); len(got) != 0 {
+		t.Errorf("browser-safe video matched: %+v", got)
+	}
+}
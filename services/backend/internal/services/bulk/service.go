@@ -0,0 +1,415 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"video-archiver/internal/domain"
+	"video-archiver/internal/services/download"
+	"video-archiver/internal/services/tools"
+)
+
+const (
+	// queryPageSize is how many items a query selection loads per page, the
+	// most GetMetadataByType returns at once.
+	queryPageSize = 100
+	// defaultQueueRetryDelay spaces out re-download and tools submissions
+	// while the download or tools queue is full.
+	defaultQueueRetryDelay = 5 * time.Second
+	// refreshTimeout bounds the metadata refresh of one item.
+	refreshTimeout = 10 * time.Minute
+)
+
+// Downloads is the part of the download service that bulk actions drive.
+type Downloads interface {
+	DeleteJob(id string) error
+	DeleteJobKeepFiles(id string) error
+	Resync(id string) error
+	RefreshJob(ctx context.Context, jobID string) (int, error)
+}
+
+// Submitter enqueues tools jobs; the tools service satisfies it.
+type Submitter interface {
+	Submit(job *domain.ToolsJob) error
+}
+
+// Broadcaster pushes progress to WebSocket clients; the download service's
+// hub satisfies it.
+type Broadcaster interface {
+	Broadcast(update interface{})
+}
+
+type Config struct {
+	Repository           domain.BulkRepository
+	JobRepository        domain.JobRepository
+	CollectionRepository domain.CollectionRepository
+	Downloads            Downloads
+	// Tools is optional; without it the tools action is rejected.
+	Tools       Submitter
+	Broadcaster Broadcaster
+}
+
+// Service applies one action to a selection of library items in the
+// background, recording a result per item and broadcasting progress.
+// Operations run independently of each other, items one at a time.
+type Service struct {
+	repo        domain.BulkRepository
+	jobs        domain.JobRepository
+	collections domain.CollectionRepository
+	downloads   Downloads
+	tools       Submitter
+	broadcaster Broadcaster
+	// queueRetryDelay is defaultQueueRetryDelay; tests shorten it.
+	queueRetryDelay time.Duration
+
+	wg     sync.WaitGroup
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func NewService(config *Config) *Service {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Service{
+		repo:        config.Repository,
+		jobs:        config.JobRepository,
+		collections: config.CollectionRepository,
+		downloads:   config.Downloads,
+		tools:       config.Tools,
+		broadcaster: config.Broadcaster,
+		ctx:         ctx,
+		cancel:      cancel,
+
+		queueRetryDelay: defaultQueueRetryDelay,
+	}
+}
+
+// Stop cancels running operations, which record themselves as stopped.
+func (s *Service) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// Validate checks a request, including that its collection exists and its
+// tools parameters are valid, and returns a message suitable for API
+// clients.
+func (s *Service) Validate(req *domain.BulkRequest) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+	switch req.Action {
+	case domain.BulkActionAddToCollection:
+		collection, err := s.collections.GetByID(req.Parameters.CollectionID)
+		if err != nil || collection == nil {
+			return fmt.Errorf("collection not found")
+		}
+		if collection.IsSmart() {
+			return domain.ErrSmartCollection
+		}
+	case domain.BulkActionTools:
+		if s.tools == nil {
+			return fmt.Errorf("tools are not available")
+		}
+		if err := tools.ValidateOperation(req.Parameters.Operation, req.Parameters.ToolsParameters); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Start validates the request, resolves its selection and applies the action
+// in the background. It returns the new operation; progress is broadcast
+// and the per-item results are read from the repository.
+func (s *Service) Start(req domain.BulkRequest) (*domain.BulkOperation, error) {
+	if err := s.Validate(&req); err != nil {
+		return nil, err
+	}
+	jobIDs, err := s.resolve(req)
+	if err != nil {
+		return nil, err
+	}
+
+	operation := &domain.BulkOperation{
+		Action:     req.Action,
+		Parameters: req.Parameters,
+		Status:     domain.BulkStatusRunning,
+		Total:      len(jobIDs),
+		StartedAt:  time.Now(),
+	}
+	if err := s.repo.Create(operation); err != nil {
+		return nil, fmt.Errorf("create bulk operation: %w", err)
+	}
+
+	started := *operation
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.run(operation, jobIDs)
+	}()
+	return &started, nil
+}
+
+// resolve returns the selected job IDs without duplicates. A query selects
+// every item the library listing would show, across all pages.
+func (s *Service) resolve(req domain.BulkRequest) ([]string, error) {
+	seen := map[string]bool{}
+	var ids []string
+	add := func(id string) {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	if req.Query == nil {
+		for _, id := range req.JobIDs {
+			add(strings.TrimSpace(id))
+		}
+		return ids, nil
+	}
+
+	filters, err := domain.ParseLibraryQuery(req.Query.Q)
+	if err != nil {
+		return nil, err
+	}
+	for page := 1; ; page++ {
+		items, total, err := s.jobs.GetMetadataByType(req.Query.Type, domain.MetadataQuery{
+			Page:         page,
+			Limit:        queryPageSize,
+			SortBy:       "created_at",
+			Order:        "desc",
+			Search:       req.Query.Search,
+			Tag:          req.Query.Tag,
+			Availability: req.Query.Availability,
+			Filters:      filters,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("query library: %w", err)
+		}
+		for _, item := range items {
+			if item != nil && item.Job != nil {
+				add(item.Job.ID)
+			}
+		}
+		if len(items) == 0 || page*queryPageSize >= total {
+			return ids, nil
+		}
+	}
+}
+
+// run applies the action to each item, recording an item per job and keeping
+// the operation's totals current.
+func (s *Service) run(operation *domain.BulkOperation, jobIDs []string) {
+	logger := log.WithFields(log.Fields{"operationID": operation.ID, "action": operation.Action})
+	logger.Infof("Starting bulk operation over %d item(s)", len(jobIDs))
+
+	for _, jobID := range jobIDs {
+		if s.ctx.Err() != nil {
+			break
+		}
+
+		item := s.apply(operation, jobID)
+		item.OperationID = operation.ID
+		if err := s.repo.AddItem(&item); err != nil {
+			logger.WithError(err).WithField("jobID", jobID).Warn("Failed to record bulk item")
+		}
+
+		operation.Processed++
+		switch item.Outcome {
+		case domain.BulkOutcomeSucceeded:
+			operation.Succeeded++
+		case domain.BulkOutcomeSkipped:
+			operation.Skipped++
+		case domain.BulkOutcomeFailed:
+			operation.Failed++
+		}
+		if err := s.repo.Update(operation); err != nil {
+			logger.WithError(err).Warn("Failed to update bulk operation")
+		}
+		s.broadcast(operation)
+	}
+
+	operation.Status = domain.BulkStatusComplete
+	if s.ctx.Err() != nil {
+		operation.Status = domain.BulkStatusStopped
+	}
+	finished := time.Now()
+	operation.FinishedAt = &finished
+	if err := s.repo.Update(operation); err != nil {
+		logger.WithError(err).Warn("Failed to finish bulk operation")
+	}
+	s.broadcast(operation)
+
+	logger.Infof("Bulk operation %s: %d succeeded, %d skipped, %d failed",
+		operation.Status, operation.Succeeded, operation.Skipped, operation.Failed)
+}
+
+func (s *Service) broadcast(operation *domain.BulkOperation) {
+	if s.broadcaster == nil {
+		return
+	}
+	s.broadcaster.Broadcast(domain.BulkUpdate{
+		Type:        "bulk",
+		OperationID: operation.ID,
+		Action:      operation.Action,
+		Status:      operation.Status,
+		Total:       operation.Total,
+		Processed:   operation.Processed,
+		Succeeded:   operation.Succeeded,
+		Skipped:     operation.Skipped,
+		Failed:      operation.Failed,
+	})
+}
+
+// apply runs the operation's action on one item.
+func (s *Service) apply(operation *domain.BulkOperation, jobID string) domain.BulkItem {
+	item := domain.BulkItem{JobID: jobID, Outcome: domain.BulkOutcomeSucceeded}
+	fail := func(err error) domain.BulkItem {
+		log.WithError(err).WithFields(log.Fields{"jobID": jobID, "action": operation.Action}).Warn("Bulk action failed")
+		item.Outcome = domain.BulkOutcomeFailed
+		item.Error = err.Error()
+		return item
+	}
+	skip := func(reason string) domain.BulkItem {
+		item.Outcome = domain.BulkOutcomeSkipped
+		item.Detail = reason
+		return item
+	}
+
+	jwm, err := s.jobs.GetJobWithMetadata(jobID)
+	if err != nil || jwm == nil || jwm.Job == nil {
+		return fail(fmt.Errorf("job not found"))
+	}
+	item.Title = titleOf(jwm)
+	params := operation.Parameters
+
+	switch operation.Action {
+	case domain.BulkActionAddTags:
+		if _, err := s.jobs.AddTagsToJob(jobID, params.Tags, domain.TagSourceUser); err != nil {
+			return fail(err)
+		}
+
+	case domain.BulkActionRemoveTags:
+		carried, err := s.jobs.GetTagsForJob(jobID)
+		if err != nil {
+			return fail(err)
+		}
+		removed := 0
+		for _, tag := range carried {
+			if !containsFold(params.Tags, tag.Name) {
+				continue
+			}
+			if err := s.jobs.RemoveTagFromJob(jobID, tag.ID); err != nil {
+				return fail(err)
+			}
+			removed++
+		}
+		if removed == 0 {
+			return skip("carries none of the tags")
+		}
+
+	case domain.BulkActionAddToCollection:
+		if _, ok := jwm.Metadata.(*domain.VideoMetadata); !ok {
+			return skip("only videos can be added to collections")
+		}
+		if err := s.collections.AddVideos(params.CollectionID, []string{jobID}); err != nil {
+			return fail(err)
+		}
+
+	case domain.BulkActionDelete:
+		deleteJob := s.downloads.DeleteJobKeepFiles
+		if params.DeleteFiles {
+			deleteJob = s.downloads.DeleteJob
+		}
+		if err := deleteJob(jobID); err != nil {
+			return fail(err)
+		}
+
+	case domain.BulkActionRedownload:
+		if status := jwm.Job.Status; status == domain.JobStatusPending || status == domain.JobStatusInProgress {
+			return skip("already queued")
+		}
+		if err := s.whileQueueFull(download.ErrQueueFull, func() error { return s.downloads.Resync(jobID) }); err != nil {
+			return fail(err)
+		}
+
+	case domain.BulkActionRefreshMetadata:
+		ctx, cancel := context.WithTimeout(s.ctx, refreshTimeout)
+		refreshed, err := s.downloads.RefreshJob(ctx, jobID)
+		cancel()
+		if err != nil {
+			return fail(err)
+		}
+		item.Detail = fmt.Sprintf("%d video(s) refreshed", refreshed)
+
+	case domain.BulkActionTools:
+		job := &domain.ToolsJob{
+			OperationType: params.Operation,
+			InputFiles:    []string{jobID},
+			InputType:     toolsInputType(jwm.Metadata),
+			Parameters:    params.ToolsParameters,
+		}
+		if err := s.whileQueueFull(tools.ErrQueueFull, func() error { return s.tools.Submit(job) }); err != nil {
+			return fail(err)
+		}
+		item.Detail = job.ID
+
+	default:
+		return fail(fmt.Errorf("unknown action: %q", operation.Action))
+	}
+	return item
+}
+
+// whileQueueFull calls submit until it succeeds or fails with anything but
+// full, waiting between attempts so large selections are not rejected past
+// the capacity of the download or tools queue.
+func (s *Service) whileQueueFull(full error, submit func() error) error {
+	for {
+		err := submit()
+		if err == nil || !errors.Is(err, full) {
+			return err
+		}
+		select {
+		case <-s.ctx.Done():
+			return err
+		case <-time.After(s.queueRetryDelay):
+		}
+	}
+}
+
+func titleOf(jwm *domain.JobWithMetadata) string {
+	switch m := jwm.Metadata.(type) {
+	case *domain.VideoMetadata:
+		return m.Title
+	case *domain.PlaylistMetadata:
+		return m.Title
+	case *domain.ChannelMetadata:
+		return m.Channel
+	}
+	return jwm.Job.URL
+}
+
+// toolsInputType tells the tools service how to expand an item: playlists
+// and channels stand for their videos.
+func toolsInputType(metadata domain.Metadata) domain.ToolsInputType {
+	switch metadata.(type) {
+	case *domain.PlaylistMetadata:
+		return domain.InputTypePlaylist
+	case *domain.ChannelMetadata:
+		return domain.InputTypeChannel
+	}
+	return domain.InputTypeVideos
+}
+
+func containsFold(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(strings.TrimSpace(n), name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,346 @@
+package bulk
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"video-archiver/internal/domain"
+	"video-archiver/internal/repositories/sqlite"
+	"video-archiver/internal/services/download"
+	"video-archiver/internal/services/tools"
+	"video-archiver/internal/testutil"
+)
+
+// fakeDownloads records the jobs each download service call was made for.
+// The first full resyncs are rejected as if the queue were full.
+type fakeDownloads struct {
+	mu        sync.Mutex
+	deleted   []string
+	kept      []string
+	resynced  []string
+	refreshed []string
+	full      int
+}
+
+func (f *fakeDownloads) DeleteJob(id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.deleted = append(f.deleted, id)
+	return nil
+}
+
+func (f *fakeDownloads) DeleteJobKeepFiles(id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.kept = append(f.kept, id)
+	return nil
+}
+
+func (f *fakeDownloads) Resync(id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.full > 0 {
+		f.full--
+		return download.ErrQueueFull
+	}
+	f.resynced = append(f.resynced, id)
+	return nil
+}
+
+func (f *fakeDownloads) RefreshJob(_ context.Context, id string) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.refreshed = append(f.refreshed, id)
+	return 1, nil
+}
+
+// fakeTools records submitted jobs, rejecting the first full submissions as
+// if the queue were full.
+type fakeTools struct {
+	mu   sync.Mutex
+	jobs []*domain.ToolsJob
+	full int
+}
+
+func (f *fakeTools) Submit(job *domain.ToolsJob) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.full > 0 {
+		f.full--
+		return tools.ErrQueueFull
+	}
+	job.ID = fmt.Sprintf("tools-%d", len(f.jobs)+1)
+	f.jobs = append(f.jobs, job)
+	return nil
+}
+
+type fakeBroadcaster struct {
+	mu      sync.Mutex
+	updates []domain.BulkUpdate
+}
+
+func (f *fakeBroadcaster) Broadcast(update interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.updates = append(f.updates, update.(domain.BulkUpdate))
+}
+
+type testEnv struct {
+	svc         *Service
+	repo        *sqlite.BulkRepository
+	jobs        *sqlite.JobRepository
+	collections *sqlite.CollectionRepository
+	downloads   *fakeDownloads
+	tools       *fakeTools
+	broadcaster *fakeBroadcaster
+}
+
+// newTestEnv returns a service over a library of two completed videos
+// (video-1, video-2) and a playlist (playlist-1).
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	db := testutil.CreateTestDB(t)
+	t.Cleanup(func() { db.Close() })
+
+	env := &testEnv{
+		repo:        sqlite.NewBulkRepository(db),
+		jobs:        sqlite.NewJobRepository(db),
+		collections: sqlite.NewCollectionRepository(db),
+		downloads:   &fakeDownloads{},
+		tools:       &fakeTools{},
+		broadcaster: &fakeBroadcaster{},
+	}
+	for i, id := range []string{"video-1", "video-2", "playlist-1"} {
+		job := testutil.CreateTestJob(id, "https://example.com/"+id)
+		job.Status = domain.JobStatusComplete
+		job.CreatedAt = time.Now().Add(time.Duration(i) * time.Minute)
+		if err := env.jobs.Create(job); err != nil {
+			t.Fatalf("Create(%s) error = %v", id, err)
+		}
+		var meta domain.Metadata
+		if id == "playlist-1" {
+			playlist := testutil.CreateTestPlaylistMetadata()
+			playlist.ID = id
+			meta = playlist
+		} else {
+			video := testutil.CreateTestVideoMetadata()
+			video.ID = id
+			video.Title = "Title " + id
+			meta = video
+		}
+		if err := env.jobs.StoreMetadata(id, meta); err != nil {
+			t.Fatalf("StoreMetadata(%s) error = %v", id, err)
+		}
+	}
+
+	env.svc = NewService(&Config{
+		Repository:           env.repo,
+		JobRepository:        env.jobs,
+		CollectionRepository: env.collections,
+		Downloads:            env.downloads,
+		Tools:                env.tools,
+		Broadcaster:          env.broadcaster,
+	})
+	t.Cleanup(env.svc.Stop)
+	return env
+}
+
+// run starts the request and waits for the operation to finish, returning it
+// with its items.
+func (env *testEnv) run(t *testing.T, req domain.BulkRequest) *domain.BulkOperation {
+	t.Helper()
+	started, err := env.svc.Start(req)
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	env.svc.wg.Wait()
+	operation, err := env.repo.GetByID(started.ID)
+	if err != nil || operation == nil {
+		t.Fatalf("GetByID(%d) = %v, %v", started.ID, operation, err)
+	}
+	return operation
+}
+
+func outcomes(operation *domain.BulkOperation) map[string]domain.BulkOutcome {
+	out := map[string]domain.BulkOutcome{}
+	for _, item := range operation.Items {
+		out[item.JobID] = item.Outcome
+	}
+	return out
+}
+
+func TestBulkTags(t *testing.T) {
+	env := newTestEnv(t)
+
+	op := env.run(t, domain.BulkRequest{
+		Action:     domain.BulkActionAddTags,
+		JobIDs:     []string{"video-1", "video-2", "video-1", "missing"},
+		Parameters: domain.BulkParameters{Tags: []string{"Favorites"}},
+	})
+	if op.Status != domain.BulkStatusComplete || op.FinishedAt == nil {
+		t.Errorf("operation = %+v, want complete", op)
+	}
+	if op.Total != 3 || op.Succeeded != 2 || op.Failed != 1 {
+		t.Errorf("totals = %d/%d/%d, want 3 total, 2 succeeded, 1 failed", op.Total, op.Succeeded, op.Failed)
+	}
+	if got := outcomes(op)["missing"]; got != domain.BulkOutcomeFailed {
+		t.Errorf("missing job outcome = %q, want failed", got)
+	}
+	if op.Items[0].Title != "Title video-1" {
+		t.Errorf("item title = %q", op.Items[0].Title)
+	}
+
+	tags, _ := env.jobs.GetTagsForJob("video-2")
+	found := false
+	for _, tag := range tags {
+		found = found || (tag.Name == "Favorites" && tag.Source == domain.TagSourceUser)
+	}
+	if !found {
+		t.Errorf("video-2 tags = %+v, want user tag Favorites", tags)
+	}
+
+	op = env.run(t, domain.BulkRequest{
+		Action:     domain.BulkActionRemoveTags,
+		JobIDs:     []string{"video-1", "playlist-1"},
+		Parameters: domain.BulkParameters{Tags: []string{"favorites"}},
+	})
+	got := outcomes(op)
+	if got["video-1"] != domain.BulkOutcomeSucceeded || got["playlist-1"] != domain.BulkOutcomeSkipped {
+		t.Errorf("remove outcomes = %v", got)
+	}
+	tags, _ = env.jobs.GetTagsForJob("video-1")
+	for _, tag := range tags {
+		if tag.Name == "Favorites" {
+			t.Errorf("video-1 still tagged Favorites")
+		}
+	}
+
+	// Progress is broadcast once per item and once on completion.
+	env.broadcaster.mu.Lock()
+	defer env.broadcaster.mu.Unlock()
+	last := env.broadcaster.updates[len(env.broadcaster.updates)-1]
+	if len(env.broadcaster.updates) != 7 || last.Type != "bulk" || last.Status != domain.BulkStatusComplete || last.Processed != 2 {
+		t.Errorf("updates = %+v", env.broadcaster.updates)
+	}
+}
+
+func TestBulkQuerySelection(t *testing.T) {
+	env := newTestEnv(t)
+
+	op := env.run(t, domain.BulkRequest{
+		Action: domain.BulkActionRedownload,
+		Query:  &domain.BulkQuery{Type: "videos"},
+	})
+	if op.Total != 2 || op.Succeeded != 2 {
+		t.Errorf("totals = %+v, want both videos", op)
+	}
+	if len(env.downloads.resynced) != 2 {
+		t.Errorf("resynced = %v", env.downloads.resynced)
+	}
+
+	op = env.run(t, domain.BulkRequest{
+		Action: domain.BulkActionRefreshMetadata,
+		Query:  &domain.BulkQuery{Type: "videos", Search: "video-2"},
+	})
+	if op.Total != 1 || env.downloads.refreshed[0] != "video-2" || op.Items[0].Detail != "1 video(s) refreshed" {
+		t.Errorf("refresh = %+v, refreshed %v", op, env.downloads.refreshed)
+	}
+}
+
+func TestBulkCollectionsDeleteAndTools(t *testing.T) {
+	env := newTestEnv(t)
+	collection := &domain.Collection{ID: "c1", Name: "Watch later", CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	if err := env.collections.Create(collection); err != nil {
+		t.Fatalf("Create collection error = %v", err)
+	}
+
+	op := env.run(t, domain.BulkRequest{
+		Action:     domain.BulkActionAddToCollection,
+		JobIDs:     []string{"video-1", "playlist-1"},
+		Parameters: domain.BulkParameters{CollectionID: "c1"},
+	})
+	if got := outcomes(op); got["video-1"] != domain.BulkOutcomeSucceeded || got["playlist-1"] != domain.BulkOutcomeSkipped {
+		t.Errorf("collection outcomes = %v", got)
+	}
+	if videos, _ := env.collections.GetVideos("c1"); len(videos) != 1 {
+		t.Errorf("collection has %d videos, want 1", len(videos))
+	}
+
+	env.run(t, domain.BulkRequest{Action: domain.BulkActionDelete, JobIDs: []string{"video-1"}})
+	env.run(t, domain.BulkRequest{
+		Action:     domain.BulkActionDelete,
+		JobIDs:     []string{"video-2"},
+		Parameters: domain.BulkParameters{DeleteFiles: true},
+	})
+	if len(env.downloads.kept) != 1 || env.downloads.kept[0] != "video-1" ||
+		len(env.downloads.deleted) != 1 || env.downloads.deleted[0] != "video-2" {
+		t.Errorf("kept files for %v, deleted files for %v", env.downloads.kept, env.downloads.deleted)
+	}
+
+	op = env.run(t, domain.BulkRequest{
+		Action:     domain.BulkActionTools,
+		JobIDs:     []string{"video-1", "playlist-1"},
+		Parameters: domain.BulkParameters{Operation: domain.OpTypeExtractAudio, ToolsParameters: map[string]any{"output_format": "mp3"}},
+	})
+	if len(env.tools.jobs) != 2 {
+		t.Fatalf("submitted %d tools jobs, want 2", len(env.tools.jobs))
+	}
+	if env.tools.jobs[0].InputType != domain.InputTypeVideos || env.tools.jobs[1].InputType != domain.InputTypePlaylist {
+		t.Errorf("input types = %q, %q", env.tools.jobs[0].InputType, env.tools.jobs[1].InputType)
+	}
+	if op.Items[0].Detail != env.tools.jobs[0].ID {
+		t.Errorf("item detail = %q, want the tools job ID", op.Items[0].Detail)
+	}
+}
+
+func TestBulkWaitsWhileQueueIsFull(t *testing.T) {
+	env := newTestEnv(t)
+	env.svc.queueRetryDelay = time.Millisecond
+	env.tools.full = 3
+	env.downloads.full = 2
+
+	op := env.run(t, domain.BulkRequest{
+		Action:     domain.BulkActionTools,
+		JobIDs:     []string{"video-1", "video-2"},
+		Parameters: domain.BulkParameters{Operation: domain.OpTypeExtractAudio, ToolsParameters: map[string]any{"output_format": "mp3"}},
+	})
+	if got := outcomes(op); got["video-1"] != domain.BulkOutcomeSucceeded || got["video-2"] != domain.BulkOutcomeSucceeded {
+		t.Errorf("tools outcomes = %v", got)
+	}
+	if len(env.tools.jobs) != 2 {
+		t.Errorf("submitted %d tools jobs, want 2", len(env.tools.jobs))
+	}
+
+	op = env.run(t, domain.BulkRequest{Action: domain.BulkActionRedownload, JobIDs: []string{"playlist-1"}})
+	if got := outcomes(op); got["playlist-1"] != domain.BulkOutcomeSucceeded {
+		t.Errorf("re-download outcomes = %v", got)
+	}
+	if len(env.downloads.resynced) != 1 {
+		t.Errorf("resynced %v, want playlist-1 once", env.downloads.resynced)
+	}
+}
+
+func TestBulkValidate(t *testing.T) {
+	env := newTestEnv(t)
+
+	tests := []struct {
+		name string
+		req  domain.BulkRequest
+	}{
+		{"missing collection", domain.BulkRequest{Action: domain.BulkActionAddToCollection, JobIDs: []string{"video-1"},
+			Parameters: domain.BulkParameters{CollectionID: "nope"}}},
+		{"unknown tools operation", domain.BulkRequest{Action: domain.BulkActionTools, JobIDs: []string{"video-1"},
+			Parameters: domain.BulkParameters{Operation: "transmogrify"}}},
+		{"no selection", domain.BulkRequest{Action: domain.BulkActionDelete}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := env.svc.Start(tt.req); err == nil {
+				t.Error("Start() error = nil, want a validation error")
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"video-archiver/internal/domain"
+)
+
+// defaultAvailabilityCheckHours applies when settings cannot be read.
+const defaultAvailabilityCheckHours = 24 * 7
+
+// availabilityTick is how often the checker wakes up; each wake-up checks at
+// most availabilityBatchSize videos, pausing availabilityProbeDelay between
+// probes, so a large library is spread over the interval instead of hitting
+// the source in one burst.
+const (
+	availabilityTick       = time.Hour
+	availabilityBatchSize  = 50
+	availabilityProbeDelay = 2 * time.Second
+)
+
+// availabilityPatterns maps fragments of yt-dlp error output to the status
+// they indicate. Order matters: YouTube prefixes most messages with "Video
+// unavailable", so the specific causes must be matched before the generic
+// removed patterns.
+var availabilityPatterns = []struct {
+	fragment string
+	status   domain.Availability
+}{
+	{"account associated with this video has been terminated", domain.AvailabilityAccountTerminated},
+	{"account has been terminated", domain.AvailabilityAccountTerminated},
+	{"channel has been terminated", domain.AvailabilityAccountTerminated},
+	{"private video", domain.AvailabilityPrivate},
+	{"video is private", domain.AvailabilityPrivate},
+	{"not made this video available in your country", domain.AvailabilityGeoBlocked},
+	{"not available in your country", domain.AvailabilityGeoBlocked},
+	{"blocked it in your country", domain.AvailabilityGeoBlocked},
+	{"geo restriction", domain.AvailabilityGeoBlocked},
+	{"geo-restricted", domain.AvailabilityGeoBlocked},
+	{"has been removed", domain.AvailabilityRemoved},
+	{"no longer available", domain.AvailabilityRemoved},
+	{"video unavailable", domain.AvailabilityRemoved},
+	{"video does not exist", domain.AvailabilityRemoved},
+	{"http error 404", domain.AvailabilityRemoved},
+	{"http error 410", domain.AvailabilityRemoved},
+}
+
+// classifyAvailability maps yt-dlp error output to an availability status.
+// ok is false for failures that say nothing about the video itself (network
+// errors, rate limiting, extractor breakage); those are not recorded.
+func classifyAvailability(output string) (status domain.Availability, ok bool) {
+	lower := strings.ToLower(output)
+	for _, p := range availabilityPatterns {
+		if strings.Contains(lower, p.fragment) {
+			return p.status, true
+		}
+	}
+	return "", false
+}
+
+// lastErrorLine returns yt-dlp's final "ERROR:" message, for display.
+func lastErrorLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if msg, found := strings.CutPrefix(strings.TrimSpace(lines[i]), "ERROR: "); found {
+			return msg
+		}
+	}
+	return ""
+}
+
+// probeAvailability asks yt-dlp to resolve the video without downloading it.
+func probeAvailability(ctx context.Context, url string) (domain.Availability, string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "yt-dlp",
+		"--skip-download",
+		"--no-playlist",
+		"--no-warnings",
+		"--print", "id",
+		url,
+	)
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return "", "", ctx.Err()
+		}
+		output := stderr.String()
+		status, ok := classifyAvailability(output)
+		if !ok {
+			return "", "", fmt.Errorf("inconclusive availability check: %w: %s", err, lastErrorLine(output))
+		}
+		return status, lastErrorLine(output), nil
+	}
+	return domain.AvailabilityAvailable, "", nil
+}
+
+// runAvailabilityChecker periodically checks archived videos against their
+// source until the service stops.
+func (s *Service) runAvailabilityChecker() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(availabilityTick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.checkAvailabilityBatch(now)
+		}
+	}
+}
+
+// availabilityCheckHours returns the configured check interval; 0 disables
+// the checker.
+func (s *Service) availabilityCheckHours() int {
+	if s.settings == nil {
+		return defaultAvailabilityCheckHours
+	}
+	settings, err := s.settings.Get()
+	if err != nil {
+		return defaultAvailabilityCheckHours
+	}
+	return settings.AvailabilityCheckHours
+}
+
+// checkAvailabilityBatch checks the videos whose last check is older than the
+// configured interval.
+func (s *Service) checkAvailabilityBatch(now time.Time) {
+	hours := s.availabilityCheckHours()
+	if hours <= 0 {
+		return
+	}
+
+	jobs, err := s.jobs.ListAvailabilityCandidates(now.Add(-time.Duration(hours)*time.Hour), availabilityBatchSize)
+	if err != nil {
+		log.WithError(err).Warn("Failed to list videos for availability check")
+		return
+	}
+
+	for i, job := range jobs {
+		if i > 0 {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-time.After(availabilityProbeDelay):
+			}
+		}
+		if _, err := s.CheckAvailability(s.ctx, job.ID); err != nil {
+			log.WithError(err).WithField("jobID", job.ID).Warn("Availability check failed")
+		}
+	}
+}
+
+// CheckAvailability probes a video's source, records the result and notifies
+// clients when the status changed. Inconclusive probes return an error and
+// leave the stored status untouched.
+func (s *Service) CheckAvailability(ctx context.Context, jobID string) (*domain.AvailabilityStatus, error) {
+	job, err := s.jobs.GetByID(jobID)
+	if err != nil {
+		return nil, fmt.Errorf("get job: %w", err)
+	}
+
+	status, reason, err := probeAvailability(ctx, job.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := s.jobs.RecordAvailability(jobID, status, reason, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	s.notifyAvailabilityChange(jobID, previous, status, reason)
+
+	return s.jobs.GetAvailability(jobID)
+}
+
+// notifyAvailabilityChange broadcasts a status change. A first check only
+// notifies when the video is already gone.
+func (s *Service) notifyAvailabilityChange(jobID string, previous *domain.AvailabilityStatus, status domain.Availability, reason string) {
+	update := domain.AvailabilityUpdate{
+		Type:   "availability",
+		JobID:  jobID,
+		Status: status,
+		Reason: reason,
+	}
+	if previous != nil {
+		if previous.Status == status {
+			return
+		}
+		update.Previous = previous.Status
+	} else if status == domain.AvailabilityAvailable {
+		return
+	}
+
+	if jobWithMetadata, err := s.jobs.GetJobWithMetadata(jobID); err == nil {
+		if video, ok := jobWithMetadata.Metadata.(*domain.VideoMetadata); ok {
+			update.Title = video.Title
+		}
+	}
+
+	log.WithField("jobID", jobID).Infof("Upstream availability changed: %q -> %q", update.Previous, status)
+	s.hub.Broadcast(update)
+	if status != domain.AvailabilityAvailable {
+		s.notify(domain.WebhookEventVideoRemoved, update)
+	}
+}
@@ -0,0 +1,70 @@
+package download
+
+import (
+	"testing"
+
+	"video-archiver/internal/domain"
+)
+
+func TestClassifyAvailability(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   domain.Availability
+		wantOK bool
+	}{
+		{
+			name:   "private",
+			output: "ERROR: [youtube] abc: Private video. Sign in if you've been granted access to this video",
+			want:   domain.AvailabilityPrivate,
+			wantOK: true,
+		},
+		{
+			name:   "removed by uploader",
+			output: "ERROR: [youtube] abc: Video unavailable. This video has been removed by the uploader",
+			want:   domain.AvailabilityRemoved,
+			wantOK: true,
+		},
+		{
+			name:   "terminated account wins over no longer available",
+			output: "ERROR: [youtube] abc: Video unavailable. This video is no longer available because the YouTube account associated with this video has been terminated.",
+			want:   domain.AvailabilityAccountTerminated,
+			wantOK: true,
+		},
+		{
+			name:   "geo blocked wins over video unavailable",
+			output: "ERROR: [youtube] abc: Video unavailable. The uploader has not made this video available in your country",
+			want:   domain.AvailabilityGeoBlocked,
+			wantOK: true,
+		},
+		{
+			name:   "network error is inconclusive",
+			output: "ERROR: [youtube] abc: Unable to download API page: <urlopen error [Errno -3] Temporary failure in name resolution>",
+			wantOK: false,
+		},
+		{
+			name:   "rate limit is inconclusive",
+			output: "ERROR: [youtube] abc: HTTP Error 429: Too Many Requests",
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := classifyAvailability(tt.output)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("classifyAvailability() = %q, %v; want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestLastErrorLine(t *testing.T) {
+	output := "WARNING: something\nERROR: first\nsome detail\nERROR: [youtube] abc: Private video\n"
+	if got := lastErrorLine(output); got != "[youtube] abc: Private video" {
+		t.Errorf("lastErrorLine() = %q", got)
+	}
+	if got := lastErrorLine("no errors here"); got != "" {
+		t.Errorf("lastErrorLine() = %q, want empty", got)
+	}
+}
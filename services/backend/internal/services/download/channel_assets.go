@@ -0,0 +1,194 @@
+package download
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"video-archiver/internal/domain"
+)
+
+// channelAssetsDirName is the directory under the download path holding one
+// asset directory per source channel (keyed by channel ID, so re-syncs of the
+// same channel share it). Hidden like the thumbnail cache.
+const channelAssetsDirName = ".channels"
+
+// maxChannelAssetBytes caps a single avatar/banner download; real channel art
+// is well under a few megabytes.
+const maxChannelAssetBytes = 20 << 20
+
+// channelAssetExtensions maps the image types channel art is served as to the
+// extension it is stored under.
+var channelAssetExtensions = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/webp": "webp",
+}
+
+// ChannelAssetDir returns the asset directory of a source channel, validated
+// to stay within the download directory.
+func ChannelAssetDir(downloadPath, channelID string) (string, error) {
+	base := filepath.Join(filepath.Clean(downloadPath), channelAssetsDirName)
+	dir := filepath.Clean(filepath.Join(base, channelID))
+	if channelID == "" || filepath.Dir(dir) != base {
+		return "", fmt.Errorf("invalid channel ID %q", channelID)
+	}
+	return dir, nil
+}
+
+// ChannelAssetPath returns the archived file for one kind of channel art, if
+// it has been downloaded.
+func ChannelAssetPath(downloadPath, channelID, kind string) (string, bool) {
+	dir, err := ChannelAssetDir(downloadPath, channelID)
+	if err != nil {
+		return "", false
+	}
+	for _, ext := range channelAssetExtensions {
+		p := filepath.Join(dir, kind+"."+ext)
+		if info, err := os.Stat(p); err == nil && info.Mode().IsRegular() {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// archiveChannelAssets downloads a channel's avatar and banner into its asset
+// directory, replacing earlier copies so a re-sync picks up new art. Failures
+// are logged per asset and never fail the channel download.
+func (s *Service) archiveChannelAssets(ctx context.Context, jobID string, metadata *domain.ChannelMetadata) {
+	urls := domain.ChannelArtURLs(metadata.Thumbnails)
+	if len(urls) == 0 {
+		return
+	}
+	dir, err := ChannelAssetDir(s.config.DownloadPath, metadata.ID)
+	if err != nil {
+		log.WithError(err).WithField("jobID", jobID).Warn("Cannot archive channel assets")
+		return
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		log.WithError(err).WithField("jobID", jobID).Warn("Failed to create channel asset directory")
+		return
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	for kind, url := range urls {
+		logger := log.WithField("jobID", jobID).WithField("kind", kind)
+		if err := fetchChannelAsset(ctx, client, url, dir, kind); err != nil {
+			logger.WithError(err).Warn("Failed to archive channel asset")
+			continue
+		}
+		logger.Debug("Archived channel asset")
+	}
+}
+
+// fetchChannelAsset downloads url to <dir>/<kind>.<ext>, writing to a temp
+// file first so a failed download never replaces a good copy.
+func fetchChannelAsset(ctx context.Context, client *http.Client, url, dir, kind string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("fetch %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetch %s: unexpected status %s", url, resp.Status)
+	}
+
+	ext := channelAssetExtension(resp.Header.Get("Content-Type"), url)
+	tmp, err := os.CreateTemp(dir, kind+"-*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	// One byte over the cap tells a too large asset from one exactly at it.
+	n, copyErr := io.Copy(tmp, io.LimitReader(resp.Body, maxChannelAssetBytes+1))
+	if copyErr == nil && n > maxChannelAssetBytes {
+		copyErr = fmt.Errorf("asset exceeds %d bytes", maxChannelAssetBytes)
+	}
+	if closeErr := tmp.Close(); copyErr == nil {
+		copyErr = closeErr
+	}
+	if copyErr != nil {
+		return fmt.Errorf("write asset: %w", copyErr)
+	}
+
+	// Drop copies stored under another extension so lookups stay unambiguous.
+	for _, other := range channelAssetExtensions {
+		if other != ext {
+			_ = os.Remove(filepath.Join(dir, kind+"."+other))
+		}
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, kind+"."+ext)); err != nil {
+		return fmt.Errorf("finalize asset: %w", err)
+	}
+	return nil
+}
+
+// channelAssetExtension picks the stored extension from the response content
+// type, falling back to the URL's extension and finally jpg.
+func channelAssetExtension(contentType, url string) string {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	if ext, ok := channelAssetExtensions[strings.TrimSpace(strings.ToLower(mediaType))]; ok {
+		return ext
+	}
+	urlPath, _, _ := strings.Cut(url, "?")
+	urlExt := strings.TrimPrefix(strings.ToLower(path.Ext(urlPath)), ".")
+	if urlExt == "jpeg" {
+		urlExt = "jpg"
+	}
+	for _, ext := range channelAssetExtensions {
+		if ext == urlExt {
+			return ext
+		}
+	}
+	return "jpg"
+}
+
+// removeChannelAssets deletes a channel's asset directory once no job of the
+// channel remains — neither the channel nor any of its videos or playlists.
+// Must run after the deleted job's records are gone.
+func (s *Service) removeChannelAssets(channelID string) {
+	if channelID == "" {
+		return
+	}
+	if remaining, err := s.jobs.HasChannelJobs(channelID); err != nil || remaining {
+		return
+	}
+	dir, err := ChannelAssetDir(s.config.DownloadPath, channelID)
+	if err != nil {
+		return
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		log.WithError(err).WithField("path", dir).Warn("Failed to delete channel assets")
+	}
+}
+
+// channelIDOf returns the source channel of an item's metadata.
+func channelIDOf(metadata domain.Metadata) string {
+	switch m := metadata.(type) {
+	case *domain.ChannelMetadata:
+		if m != nil {
+			return m.ID
+		}
+	case *domain.VideoMetadata:
+		if m != nil {
+			return m.ChannelID
+		}
+	case *domain.PlaylistMetadata:
+		if m != nil {
+			return m.ChannelID
+		}
+	}
+	return ""
+}
@@ -0,0 +1,153 @@
+package download
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"video-archiver/internal/domain"
+	"video-archiver/internal/repositories/sqlite"
+	"video-archiver/internal/testutil"
+)
+
+func TestChannelAssetDirRejectsTraversal(t *testing.T) {
+	base := t.TempDir()
+	for _, id := range []string{"", "..", "../x", "a/b"} {
+		if _, err := ChannelAssetDir(base, id); err == nil {
+			t.Errorf("expected channel ID %q to be rejected", id)
+		}
+	}
+	got, err := ChannelAssetDir(base, "UC123")
+	if err != nil {
+		t.Fatalf("ChannelAssetDir: %v", err)
+	}
+	if want := filepath.Join(base, channelAssetsDirName, "UC123"); got != want {
+		t.Errorf("ChannelAssetDir = %q, want %q", got, want)
+	}
+}
+
+func TestChannelAssetExtension(t *testing.T) {
+	tests := []struct {
+		contentType, url, want string
+	}{
+		{"image/png", "https://x/a.jpg", "png"},
+		{"image/webp; charset=binary", "https://x/a", "webp"},
+		{"application/octet-stream", "https://x/a.JPEG?size=900", "jpg"},
+		{"", "https://x/a.png", "png"},
+		{"", "https://x/a=s900-c-k", "jpg"},
+	}
+	for _, tt := range tests {
+		if got := channelAssetExtension(tt.contentType, tt.url); got != tt.want {
+			t.Errorf("channelAssetExtension(%q, %q) = %q, want %q", tt.contentType, tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestFetchChannelAssetReplacesOtherExtension(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("png-bytes"))
+	}))
+	defer srv.Close()
+
+	base := t.TempDir()
+	dir, err := ChannelAssetDir(base, "UC123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "avatar.jpg"), []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A failed fetch keeps the existing copy.
+	if err := fetchChannelAsset(context.Background(), srv.Client(), srv.URL+"/missing", dir, "avatar"); err == nil {
+		t.Fatal("expected error for 404")
+	}
+	if got, ok := ChannelAssetPath(base, "UC123", "avatar"); !ok || filepath.Base(got) != "avatar.jpg" {
+		t.Fatalf("existing asset lost after failed fetch: %q, %v", got, ok)
+	}
+
+	if err := fetchChannelAsset(context.Background(), srv.Client(), srv.URL+"/avatar", dir, "avatar"); err != nil {
+		t.Fatalf("fetchChannelAsset: %v", err)
+	}
+	got, ok := ChannelAssetPath(base, "UC123", "avatar")
+	if !ok || filepath.Base(got) != "avatar.png" {
+		t.Fatalf("ChannelAssetPath = %q, %v; want avatar.png", got, ok)
+	}
+	if data, _ := os.ReadFile(got); string(data) != "png-bytes" {
+		t.Errorf("asset content = %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "avatar.jpg")); !os.IsNotExist(err) {
+		t.Error("stale avatar.jpg should have been removed")
+	}
+}
+
+func TestFetchChannelAssetRejectsOversizedAsset(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/jpeg")
+		w.Write(make([]byte, maxChannelAssetBytes+1))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	if err := fetchChannelAsset(context.Background(), srv.Client(), srv.URL+"/banner", dir, "banner"); err == nil {
+		t.Fatal("expected error for an asset over the cap")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("oversized asset left %d files behind", len(entries))
+	}
+}
+
+func TestDeleteRemovesChannelAssetsWithLastChannelJob(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+	repo := sqlite.NewJobRepository(db)
+	s := NewService(&Config{JobRepository: repo, DownloadPath: t.TempDir()})
+
+	for id, metadata := range map[string]domain.Metadata{
+		"channel-1": testutil.CreateTestChannelMetadata(),
+		"video-1":   testutil.CreateTestVideoMetadata(),
+	} {
+		job := testutil.CreateTestJob(id, "https://youtube.com/"+id)
+		job.Status = domain.JobStatusComplete
+		repo.Create(job)
+		repo.StoreMetadata(id, metadata)
+	}
+
+	dir, err := ChannelAssetDir(s.config.DownloadPath, "test-channel-id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "avatar.jpg"), []byte("jpeg"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A video of the channel still shows its art.
+	if err := s.DeleteJob("channel-1"); err != nil {
+		t.Fatalf("DeleteJob(channel) error = %v", err)
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Fatalf("channel assets removed while a video of the channel remains: %v", err)
+	}
+
+	// Even kept files don't keep the art of the channel's last job.
+	if err := s.DeleteJobKeepFiles("video-1"); err != nil {
+		t.Fatalf("DeleteJobKeepFiles(video) error = %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("channel assets left after the last job of the channel: %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package download
+
+import (
+	"fmt"
+	"strings"
+
+	"video-archiver/internal/domain"
+)
+
+// channelTabSuffixes are the channel page sections yt-dlp understands as the
+// last URL segment; a submitted URL pointing at one is reduced to the channel
+// itself before the selected tabs are appended.
+var channelTabSuffixes = []string{
+	"videos", "shorts", "streams", "playlists", "live", "featured",
+	"community", "podcasts", "releases", "about",
+}
+
+// channelTabURLs returns the URLs to download for the selected tabs of a
+// channel, or the submitted URL when no tabs are selected.
+func channelTabURLs(channelURL string, tabs []domain.ChannelTab) []string {
+	if len(tabs) == 0 {
+		return []string{channelURL}
+	}
+
+	base := strings.TrimRight(channelURL, "/")
+	if i := strings.IndexAny(base, "?#"); i >= 0 {
+		base = strings.TrimRight(base[:i], "/")
+	}
+	for _, suffix := range channelTabSuffixes {
+		if trimmed, ok := strings.CutSuffix(base, "/"+suffix); ok {
+			base = trimmed
+			break
+		}
+	}
+
+	urls := make([]string, 0, len(tabs))
+	for _, tab := range tabs {
+		urls = append(urls, base+"/"+string(tab))
+	}
+	return urls
+}
+
+// channelFilterArgs translates channel options into yt-dlp selection flags.
+func channelFilterArgs(options *domain.ChannelDownloadOptions) []string {
+	if options == nil {
+		return nil
+	}
+
+	var args []string
+	if options.DateAfter != "" {
+		args = append(args, "--dateafter", options.DateAfter)
+	}
+	if options.DateBefore != "" {
+		args = append(args, "--datebefore", options.DateBefore)
+	}
+	if options.MaxItems > 0 {
+		args = append(args, "--max-downloads", fmt.Sprintf("%d", options.MaxItems))
+	}
+	if filter := channelMatchFilter(options); filter != "" {
+		args = append(args, "--match-filter", filter)
+	}
+	return args
+}
+
+// channelMatchFilter builds the --match-filter expression for the duration
+// and title rules. Conditions are joined with "&", so all must hold.
+func channelMatchFilter(options *domain.ChannelDownloadOptions) string {
+	var conditions []string
+	if options.MinDuration > 0 {
+		conditions = append(conditions, fmt.Sprintf("duration >= %d", options.MinDuration))
+	}
+	if options.MaxDuration > 0 {
+		conditions = append(conditions, fmt.Sprintf("duration <= %d", options.MaxDuration))
+	}
+	if options.TitleInclude != "" {
+		conditions = append(conditions, "title ~= "+quoteFilterValue(options.TitleInclude))
+	}
+	if options.TitleExclude != "" {
+		conditions = append(conditions, "title !~= "+quoteFilterValue(options.TitleExclude))
+	}
+	return strings.Join(conditions, " & ")
+}
+
+// quoteFilterValue quotes a string for a yt-dlp match filter. yt-dlp splits
+// filters on unescaped "&" before parsing the quoted value, so ampersands
+// need escaping as well as the quote character.
+func quoteFilterValue(value string) string {
+	value = strings.ReplaceAll(value, "&", `\&`)
+	value = strings.ReplaceAll(value, "'", `\'`)
+	return "'" + value + "'"
+}
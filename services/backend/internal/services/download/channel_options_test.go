@@ -0,0 +1,60 @@
+package download
+
+import (
+	"reflect"
+	"testing"
+
+	"video-archiver/internal/domain"
+)
+
+func TestChannelTabURLs(t *testing.T) {
+	tabs := []domain.ChannelTab{domain.ChannelTabVideos, domain.ChannelTabShorts}
+	tests := []struct {
+		url  string
+		tabs []domain.ChannelTab
+		want []string
+	}{
+		{"https://www.youtube.com/@example", nil, []string{"https://www.youtube.com/@example"}},
+		{"https://www.youtube.com/@example", tabs, []string{
+			"https://www.youtube.com/@example/videos",
+			"https://www.youtube.com/@example/shorts",
+		}},
+		{"https://www.youtube.com/@example/streams/", tabs, []string{
+			"https://www.youtube.com/@example/videos",
+			"https://www.youtube.com/@example/shorts",
+		}},
+		{"https://www.youtube.com/channel/UC123/featured?view=0", []domain.ChannelTab{domain.ChannelTabPlaylists}, []string{
+			"https://www.youtube.com/channel/UC123/playlists",
+		}},
+	}
+	for _, tt := range tests {
+		if got := channelTabURLs(tt.url, tt.tabs); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("channelTabURLs(%q, %v) = %v, want %v", tt.url, tt.tabs, got, tt.want)
+		}
+	}
+}
+
+func TestChannelFilterArgs(t *testing.T) {
+	if args := channelFilterArgs(nil); len(args) != 0 {
+		t.Errorf("channelFilterArgs(nil) = %v, want none", args)
+	}
+
+	got := channelFilterArgs(&domain.ChannelDownloadOptions{
+		DateAfter:    "20240101",
+		DateBefore:   "20241231",
+		MaxItems:     25,
+		MinDuration:  60,
+		MaxDuration:  600,
+		TitleInclude: "Q&A",
+		TitleExclude: "it's",
+	})
+	want := []string{
+		"--dateafter", "20240101",
+		"--datebefore", "20241231",
+		"--max-downloads", "25",
+		"--match-filter", `duration >= 60 & duration <= 600 & title ~= 'Q\&A' & title !~= 'it\'s'`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("channelFilterArgs() =\n%q\nwant\n%q", got, want)
+	}
+}
@@ -0,0 +1,54 @@
+package download
+
+import (
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"video-archiver/internal/domain"
+	"video-archiver/internal/services/metadata"
+)
+
+// archiveCommentsFor reports whether a job should archive comments: the job's
+// own choice when set, otherwise the archive_comments setting. Comment
+// extraction can add minutes per video, so it is off unless opted into.
+func (s *Service) archiveCommentsFor(job domain.Job) bool {
+	if job.ArchiveComments != nil {
+		return *job.ArchiveComments
+	}
+	if s.settings == nil {
+		return false
+	}
+	settings, err := s.settings.Get()
+	if err != nil {
+		log.WithError(err).Warn("Failed to get settings, not archiving comments")
+		return false
+	}
+	return settings.ArchiveComments
+}
+
+// commentArgs makes yt-dlp include the comment section in the .info.json it
+// writes next to each video.
+func commentArgs() []string {
+	return []string{"--write-comments"}
+}
+
+// infoJSONFor returns the .info.json path yt-dlp writes next to a media file.
+func infoJSONFor(mediaPath string) string {
+	return strings.TrimSuffix(mediaPath, filepath.Ext(mediaPath)) + ".info.json"
+}
+
+// storeComments parses the comments out of a video's .info.json and stores
+// them. Best-effort: failures are logged, never a job failure.
+func (s *Service) storeComments(jobID, infoJSONPath string) {
+	comments, err := metadata.ExtractComments(infoJSONPath)
+	if err != nil {
+		log.WithError(err).WithField("jobID", jobID).Warn("Failed to read archived comments")
+		return
+	}
+	if err := s.jobs.StoreComments(jobID, comments); err != nil {
+		log.WithError(err).WithField("jobID", jobID).Warn("Failed to store archived comments")
+		return
+	}
+	log.WithField("jobID", jobID).Debugf("Archived %d comments", len(comments))
+}
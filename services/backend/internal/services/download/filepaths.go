@@ -31,6 +31,20 @@ func printedFilepath(r io.Reader) string {
 	return last
 }
 
+// readPrintFile returns printedFilepath of the print file at path, or "" when
+// there is none or it cannot be read.
+func readPrintFile(path string) string {
+	if path == "" {
+		return ""
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	return printedFilepath(f)
+}
+
 // printedFilepathsByID parses lines of "<video id>\t<filepath>" written with
 // --print-to-file "after_move:%(id)s\t%(filepath)s" during playlist/channel
 // downloads. Videos that failed simply don't appear. Malformed lines are
@@ -0,0 +1,147 @@
+package download
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"video-archiver/internal/domain"
+	"video-archiver/internal/services/tools"
+)
+
+// liveProgressTemplate replaces the percentage template for live recordings:
+// yt-dlp knows neither a total size nor an ETA for a stream that is still
+// running, only how much it has written so far per format.
+const liveProgressTemplate = "live:[%(info.format_id)s][%(progress.downloaded_bytes)s]"
+
+const (
+	// liveWaitRetry is the --wait-for-video range in seconds: how often a
+	// scheduled stream is re-checked while waiting for it to start. yt-dlp
+	// sleeps until the announced start time when it is closer than that.
+	liveWaitRetry = "60-600"
+	// liveStopGrace is how long yt-dlp gets to finalize a recording after
+	// being interrupted before it is killed.
+	liveStopGrace = 30 * time.Second
+	// liveMergeTimeout bounds remuxing separately recorded streams.
+	liveMergeTimeout = 10 * time.Minute
+)
+
+// formatSuffixPattern matches the ".f<format id>" yt-dlp appends to the name
+// of each stream it records separately before merging.
+var formatSuffixPattern = regexp.MustCompile(`\.f[0-9A-Za-z_-]+$`)
+
+// liveArgs are the yt-dlp flags for recording a livestream or premiere.
+// --no-part keeps the recording at its final name while it grows, so a
+// stopped recording needs no rename; yt-dlp already writes live HLS as
+// MPEG-TS, which stays playable when cut off.
+func liveArgs() []string {
+	return []string{
+		"--wait-for-video", liveWaitRetry,
+		"--live-from-start",
+		"--no-part",
+	}
+}
+
+// markLive flags a running job as a live recording, which changes how
+// CancelJob stops it.
+func (s *Service) markLive(jobID string) {
+	if activeJobVal, ok := s.activeJobs.Load(jobID); ok {
+		if aj, ok := activeJobVal.(*activeJob); ok {
+			aj.live.Store(true)
+		}
+	}
+}
+
+// stopGracefully makes cancelling cmd's context interrupt yt-dlp like Ctrl+C
+// instead of killing it, so it (and the ffmpeg it drives) can close the file
+// being recorded.
+func stopGracefully(cmd *exec.Cmd) {
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(os.Interrupt)
+	}
+	cmd.WaitDelay = liveStopGrace
+}
+
+// finalizeLiveRecording turns whatever a stopped live recording left on disk
+// into one playable file and returns its path. If yt-dlp got to finish its
+// post-processing the printed path is used; otherwise the announced
+// destinations that exist are taken as they are, or remuxed into one file
+// when video and audio were recorded separately.
+func (s *Service) finalizeLiveRecording(ctx context.Context, jobID, printedPath string, destinations []string) (string, error) {
+	if printedPath != "" {
+		if info, err := os.Stat(printedPath); err == nil && info.Size() > 0 {
+			return printedPath, nil
+		}
+	}
+
+	recorded := existingRecordings(destinations)
+	switch len(recorded) {
+	case 0:
+		return "", fmt.Errorf("live recording stopped before anything was recorded")
+	case 1:
+		return recorded[0], nil
+	}
+
+	output := mergedRecordingPath(recorded[0])
+	opArgs := []string{}
+	for _, path := range recorded {
+		opArgs = append(opArgs, "-i", path)
+	}
+	for i := range recorded {
+		opArgs = append(opArgs, "-map", fmt.Sprintf("%d", i))
+	}
+	opArgs = append(opArgs, "-c", "copy")
+
+	mergeCtx, cancel := context.WithTimeout(ctx, liveMergeTimeout)
+	defer cancel()
+	if err := tools.NewFFmpeg().Run(mergeCtx, opArgs, output, 0, nil); err != nil {
+		// The separate streams are still playable on their own; keep the
+		// video one rather than failing the recording.
+		log.WithError(err).WithField("jobID", jobID).Warn("Failed to merge live recording streams, keeping the first")
+		return recorded[0], nil
+	}
+	for _, path := range recorded {
+		if err := os.Remove(path); err != nil {
+			log.WithError(err).WithField("path", path).Warn("Failed to remove recorded stream after merging")
+		}
+	}
+	return output, nil
+}
+
+// existingRecordings returns the non-empty files among paths, once each and
+// in order.
+func existingRecordings(paths []string) []string {
+	seen := make(map[string]bool, len(paths))
+	var recorded []string
+	for _, path := range paths {
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+		if info, err := os.Stat(path); err == nil && info.Size() > 0 {
+			recorded = append(recorded, path)
+		}
+	}
+	return recorded
+}
+
+// mergedRecordingPath is where separately recorded streams are remuxed to:
+// the stream's name without its format suffix, in Matroska, which accepts
+// any codec pairing live sources use.
+func mergedRecordingPath(streamPath string) string {
+	stem := strings.TrimSuffix(streamPath, filepath.Ext(streamPath))
+	return formatSuffixPattern.ReplaceAllString(stem, "") + ".mkv"
+}
+
+// isLiveSource reports whether extracted metadata describes a stream that is
+// live or scheduled, which switches the job to live recording.
+func isLiveSource(extracted domain.Metadata) bool {
+	video, ok := extracted.(*domain.VideoMetadata)
+	return ok && video != nil && video.IsLiveOrUpcoming()
+}
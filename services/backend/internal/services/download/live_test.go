@@ -0,0 +1,136 @@
+package download
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"video-archiver/internal/domain"
+	"video-archiver/internal/testutil"
+)
+
+func TestMergedRecordingPath(t *testing.T) {
+	tests := map[string]string{
+		"/d/Stream.f299.mp4":  "/d/Stream.mkv",
+		"/d/Stream.f96-1.ts":  "/d/Stream.mkv",
+		"/d/Stream.mp4":       "/d/Stream.mkv",
+		"/d/My.Show.f140.m4a": "/d/My.Show.mkv",
+	}
+	for in, want := range tests {
+		if got := mergedRecordingPath(in); got != want {
+			t.Errorf("mergedRecordingPath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestFinalizeLiveRecording(t *testing.T) {
+	dir := t.TempDir()
+	recorded := filepath.Join(dir, "Stream.ts")
+	empty := filepath.Join(dir, "Empty.ts")
+	if err := os.WriteFile(recorded, []byte("data"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(empty, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s := NewService(&Config{JobRepository: testutil.NewMockJobRepository(), DownloadPath: dir})
+
+	got, err := s.finalizeLiveRecording(context.Background(), "job", "", []string{empty, recorded, recorded})
+	if err != nil || got != recorded {
+		t.Errorf("finalizeLiveRecording() = %q, %v; want %q", got, err, recorded)
+	}
+
+	got, err = s.finalizeLiveRecording(context.Background(), "job", recorded, []string{filepath.Join(dir, "missing.ts")})
+	if err != nil || got != recorded {
+		t.Errorf("finalizeLiveRecording() with printed path = %q, %v; want %q", got, err, recorded)
+	}
+
+	if _, err := s.finalizeLiveRecording(context.Background(), "job", "", []string{empty}); err == nil {
+		t.Error("finalizeLiveRecording() with nothing recorded should fail")
+	}
+}
+
+func TestCancelJobStopsLiveRecording(t *testing.T) {
+	mockRepo := testutil.NewMockJobRepository()
+	s := NewService(&Config{JobRepository: mockRepo, DownloadPath: t.TempDir()})
+
+	job := testutil.CreateTestJob("live-job", "https://youtube.com/watch?v=live")
+	job.Status = domain.JobStatusInProgress
+	mockRepo.Create(job)
+
+	cancelled := false
+	aj := &activeJob{job: job, cancel: func() { cancelled = true }}
+	aj.live.Store(true)
+	s.activeJobs.Store(job.ID, aj)
+
+	if err := s.CancelJob(job.ID); err != nil {
+		t.Fatalf("CancelJob() error = %v", err)
+	}
+	if !cancelled {
+		t.Error("CancelJob() did not stop the recording")
+	}
+	// The job stays in progress until the recording is finalized.
+	if got, _ := mockRepo.GetByID(job.ID); got.Status != domain.JobStatusInProgress {
+		t.Errorf("status = %v, want %v", got.Status, domain.JobStatusInProgress)
+	}
+}
+
+func TestDeleteJobWaitsForLiveRecording(t *testing.T) {
+	mockRepo := testutil.NewMockJobRepository()
+	s := NewService(&Config{JobRepository: mockRepo, DownloadPath: t.TempDir()})
+
+	job := testutil.CreateTestJob("live-job", "https://youtube.com/watch?v=live")
+	job.Status = domain.JobStatusInProgress
+	mockRepo.Create(job)
+
+	aj := &activeJob{job: job, cancel: func() {}, done: make(chan struct{})}
+	aj.live.Store(true)
+	s.activeJobs.Store(job.ID, aj)
+
+	deleted := make(chan error, 1)
+	go func() { deleted <- s.DeleteJobKeepFiles(job.ID) }()
+
+	// The recording is still being finalized: the job must stay until the
+	// worker lets go of it, and the worker must know not to complete it.
+	select {
+	case err := <-deleted:
+		t.Fatalf("DeleteJob() returned before the recording stopped: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if !s.deletedWhileRunning(job.ID) {
+		t.Error("worker not told the job is deleted")
+	}
+	if got, _ := mockRepo.GetByID(job.ID); got == nil {
+		t.Error("job deleted while its worker still runs")
+	}
+
+	s.activeJobs.Delete(job.ID)
+	close(aj.done)
+	if err := <-deleted; err != nil {
+		t.Fatalf("DeleteJob() error = %v", err)
+	}
+	if got, _ := mockRepo.GetByID(job.ID); got != nil {
+		t.Errorf("job still present after delete: %+v", got)
+	}
+}
+
+func TestIsLiveSource(t *testing.T) {
+	tests := []struct {
+		meta domain.Metadata
+		want bool
+	}{
+		{&domain.VideoMetadata{LiveStatus: "is_live"}, true},
+		{&domain.VideoMetadata{LiveStatus: "is_upcoming"}, true},
+		{&domain.VideoMetadata{LiveStatus: "was_live"}, false},
+		{&domain.VideoMetadata{}, false},
+		{&domain.PlaylistMetadata{}, false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := isLiveSource(tt.meta); got != tt.want {
+			t.Errorf("isLiveSource(%+v) = %v, want %v", tt.meta, got, tt.want)
+		}
+	}
+}
@@ -47,6 +47,14 @@ var (
 	fragmentRetryPattern = regexp.MustCompile(`Retrying fragment (\d+) \((\d+)/(\d+)\)`)
 	errorPattern         = regexp.MustCompile(`^ERROR:`)
 	fileEmptyPattern     = regexp.MustCompile(`The downloaded file is empty`)
+
+	// Live recording patterns: --wait-for-video countdowns, the live progress
+	// template (format id and bytes so far), ffmpeg's own stats line when
+	// yt-dlp hands an HLS stream to ffmpeg, and the files being written.
+	waitPattern         = regexp.MustCompile(`^\[wait\] `)
+	liveProgressPattern = regexp.MustCompile(`^live:\[([^\]]*)\]\[(\d+)\]`)
+	ffmpegSizePattern   = regexp.MustCompile(`size=\s*(\d+)(?:kB|KiB)\s`)
+	destinationPattern  = regexp.MustCompile(`^\[download\] Destination: (.+)$`)
 )
 
 const (
@@ -76,6 +84,10 @@ type ProgressState struct {
 	IsStuck           bool               // Flag if download is stuck retrying
 	HasError          bool               // Flag if download encountered an error
 	Warnings          []string           // Collected warnings/errors during download
+	IsLive            bool               // Live recording: report elapsed time and bytes, not percent
+	RecordingStarted  time.Time          // When the first live bytes arrived
+	RecordedBytes     map[string]int64   // Live bytes recorded per format
+	Destinations      []string           // Files yt-dlp announced it is writing
 }
 
 // ProgressTracker handles robust progress tracking. One tracker exists per
@@ -124,6 +136,7 @@ func (pt *ProgressTracker) consume(pipe io.Reader) {
 	scanner := bufio.NewScanner(pipe)
 	buf := make([]byte, 0, 64*1024)
 	scanner.Buffer(buf, 1024*1024)
+	scanner.Split(scanLinesOrCR)
 
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
@@ -143,6 +156,21 @@ func (pt *ProgressTracker) consume(pipe io.Reader) {
 	}
 }
 
+// scanLinesOrCR splits on \n and on bare \r. --newline makes yt-dlp's own
+// progress line-based, but ffmpeg (live HLS recordings) and the
+// --wait-for-video countdown redraw a single line with carriage returns.
+func scanLinesOrCR(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	for i, b := range data {
+		if b == '\n' || b == '\r' {
+			return i + 1, data[:i], nil
+		}
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
 // track consumes both output pipes concurrently and returns a wait function
 // that blocks until they are drained. Callers must invoke wait() BEFORE
 // cmd.Wait() — os/exec closes the pipes on Wait, so reads have to finish
@@ -166,6 +194,23 @@ func (pt *ProgressTracker) finish() {
 	pt.sendFinalUpdate()
 }
 
+// finishStopped sends the final update for a live recording that was stopped
+// on request. yt-dlp reports the interruption as an error, but the recording
+// itself was kept.
+func (pt *ProgressTracker) finishStopped() {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	pt.state.HasError = false
+	pt.sendFinalUpdate()
+}
+
+// destinations returns the files yt-dlp announced while recording.
+func (pt *ProgressTracker) destinations() []string {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	return append([]string(nil), pt.state.Destinations...)
+}
+
 // processLine analyzes each line and updates state accordingly
 func (pt *ProgressTracker) processLine(line string) {
 	// Update last activity
@@ -196,7 +241,11 @@ func (pt *ProgressTracker) processLine(line string) {
 	pt.updatePlaylistProgress(line)
 
 	// 3. Phase detection and progress simulation
-	pt.detectPhase(line)
+	if pt.state.IsLive {
+		pt.trackLive(line)
+	} else {
+		pt.detectPhase(line)
+	}
 
 	// 4. No need to simulate progress - we get real progress from yt-dlp template
 
@@ -444,6 +493,54 @@ func (pt *ProgressTracker) detectPhase(line string) {
 	}
 }
 
+// trackLive follows a live recording: waiting for a scheduled start, then
+// bytes recorded per format. There is no total, so no percentage either.
+func (pt *ProgressTracker) trackLive(line string) {
+	if match := destinationPattern.FindStringSubmatch(line); match != nil {
+		pt.state.Destinations = append(pt.state.Destinations, strings.TrimSpace(match[1]))
+		return
+	}
+	if waitPattern.MatchString(line) {
+		pt.state.Phase = domain.DownloadPhaseWaiting
+		return
+	}
+
+	format, bytes := "", int64(-1)
+	if match := liveProgressPattern.FindStringSubmatch(line); match != nil {
+		format = match[1]
+		bytes, _ = strconv.ParseInt(match[2], 10, 64)
+	} else if match := ffmpegSizePattern.FindStringSubmatch(line); match != nil {
+		format = "ffmpeg"
+		if kib, err := strconv.ParseInt(match[1], 10, 64); err == nil {
+			bytes = kib * 1024
+		}
+	}
+	if bytes < 0 {
+		if mergePattern.MatchString(line) {
+			pt.state.Phase = domain.DownloadPhaseMerging
+		}
+		return
+	}
+
+	if pt.state.RecordedBytes == nil {
+		pt.state.RecordedBytes = make(map[string]int64)
+	}
+	pt.state.RecordedBytes[format] = bytes
+	if pt.state.RecordingStarted.IsZero() {
+		pt.state.RecordingStarted = time.Now()
+	}
+	pt.state.Phase = domain.DownloadPhaseRecording
+}
+
+// recordedBytes sums the live bytes recorded across formats.
+func (pt *ProgressTracker) recordedBytes() int64 {
+	var total int64
+	for _, b := range pt.state.RecordedBytes {
+		total += b
+	}
+	return total
+}
+
 // handleSpecialCases deals with edge cases like already downloaded files and retries
 func (pt *ProgressTracker) handleSpecialCases(line string) {
 	// Check for error messages
@@ -671,6 +768,13 @@ func (pt *ProgressTracker) broadcastUpdate() {
 		RetryError:           pt.state.RetryError,
 		Warnings:             pt.state.Warnings,
 	}
+	if pt.state.IsLive {
+		update.IsLive = true
+		update.RecordedBytes = pt.recordedBytes()
+		if !pt.state.RecordingStarted.IsZero() {
+			update.ElapsedSeconds = int(time.Since(pt.state.RecordingStarted).Seconds())
+		}
+	}
 
 	pt.service.hub.Broadcast(update)
 
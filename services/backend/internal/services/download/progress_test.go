@@ -1,6 +1,7 @@
 package download
 
 import (
+	"bufio"
 	"strings"
 	"testing"
 	"video-archiver/internal/domain"
@@ -554,3 +555,58 @@ func TestDownloadFormatArgsByMediaType(t *testing.T) {
 		t.Errorf("audio job format args should not merge containers: %s", args)
 	}
 }
+
+func TestTrackLive(t *testing.T) {
+	service := NewService(&Config{JobRepository: testutil.NewMockJobRepository(), DownloadPath: "/tmp/test"})
+	tracker := NewProgressTracker(service, "test-job", "video")
+	tracker.state.IsLive = true
+
+	tracker.trackLive("[wait] Remaining time until next attempt: 00:09:58")
+	if tracker.state.Phase != domain.DownloadPhaseWaiting {
+		t.Errorf("Phase = %v, want %v", tracker.state.Phase, domain.DownloadPhaseWaiting)
+	}
+
+	for _, line := range []string{
+		"[download] Destination: /downloads/Stream.f299.mp4",
+		"live:[299][1048576]",
+		"live:[140][65536]",
+		"live:[299][2097152]",
+		"live:[NA][NA]",
+	} {
+		tracker.trackLive(line)
+	}
+	if tracker.state.Phase != domain.DownloadPhaseRecording {
+		t.Errorf("Phase = %v, want %v", tracker.state.Phase, domain.DownloadPhaseRecording)
+	}
+	if got := tracker.recordedBytes(); got != 2097152+65536 {
+		t.Errorf("recordedBytes() = %d, want %d", got, 2097152+65536)
+	}
+	if tracker.state.OverallProgress != 0 {
+		t.Errorf("OverallProgress = %v, want 0 for a live recording", tracker.state.OverallProgress)
+	}
+	if dest := tracker.destinations(); len(dest) != 1 || dest[0] != "/downloads/Stream.f299.mp4" {
+		t.Errorf("destinations() = %v", dest)
+	}
+
+	// HLS streams handed to ffmpeg report ffmpeg's own stats line.
+	ffmpeg := NewProgressTracker(service, "test-job", "video")
+	ffmpeg.state.IsLive = true
+	ffmpeg.trackLive("frame= 1500 fps= 30 q=-1.0 size=   10240KiB time=00:00:50.00 bitrate=1677.7kbits/s speed=1x")
+	if got := ffmpeg.recordedBytes(); got != 10240*1024 {
+		t.Errorf("recordedBytes() from ffmpeg = %d, want %d", got, 10240*1024)
+	}
+}
+
+func TestScanLinesOrCR(t *testing.T) {
+	scanner := bufio.NewScanner(strings.NewReader("a\rb\r\nc\nd"))
+	scanner.Split(scanLinesOrCR)
+	var tokens []string
+	for scanner.Scan() {
+		if scanner.Text() != "" {
+			tokens = append(tokens, scanner.Text())
+		}
+	}
+	if strings.Join(tokens, ",") != "a,b,c,d" {
+		t.Errorf("tokens = %q, want a,b,c,d", tokens)
+	}
+}
@@ -0,0 +1,265 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"video-archiver/internal/domain"
+)
+
+// refreshCheckInterval is how often the scheduler looks for due refresh
+// schedules. Intervals are whole hours, so a minute of slack is invisible.
+const refreshCheckInterval = time.Minute
+
+// refreshTimeout bounds one refresh run; a large channel refreshes its
+// videos one yt-dlp call at a time.
+const refreshTimeout = 30 * time.Minute
+
+// runRefreshScheduler refreshes jobs whose schedule is due until the service
+// stops.
+func (s *Service) runRefreshScheduler() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(refreshCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.refreshDue(now)
+		}
+	}
+}
+
+// refreshRetryDelay is how long a failed scheduled refresh waits before it
+// is retried, so an unreachable source isn't hit every minute.
+const refreshRetryDelay = time.Hour
+
+// ErrRefreshRunning is returned when a job is already being refreshed.
+var ErrRefreshRunning = errors.New("refresh already running")
+
+// refreshDue runs every due schedule in turn. Only a complete run counts as a
+// refresh; a run that ran out of time or failed keeps its cursor and resumes
+// from it, right away after a timeout and after refreshRetryDelay otherwise.
+func (s *Service) refreshDue(now time.Time) {
+	schedules, err := s.jobs.ListRefreshSchedules()
+	if err != nil {
+		log.WithError(err).Warn("Failed to list refresh schedules")
+		return
+	}
+
+	for _, schedule := range schedules {
+		if schedule.NextRefreshAt.After(now) || s.ctx.Err() != nil {
+			continue
+		}
+		logger := log.WithField("jobID", schedule.JobID)
+		if _, busy := s.refreshing.LoadOrStore(schedule.JobID, struct{}{}); busy {
+			logger.Debug("Scheduled metadata refresh skipped, one is already running")
+			continue
+		}
+		ctx, cancel := context.WithTimeout(s.ctx, refreshTimeout)
+		refreshed, cursor, err := s.refresh(ctx, schedule.JobID, schedule.Cursor)
+		cancel()
+		s.refreshing.Delete(schedule.JobID)
+
+		if err == nil {
+			logger.Infof("Scheduled metadata refresh updated %d video(s)", refreshed)
+			if err := s.jobs.MarkRefreshed(schedule.JobID, time.Now()); err != nil {
+				logger.WithError(err).Warn("Failed to record metadata refresh")
+			}
+			continue
+		}
+
+		retryAt := time.Now()
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+			logger.Infof("Scheduled metadata refresh interrupted after %d video(s), resuming later", refreshed)
+		} else {
+			logger.WithError(err).Warn("Scheduled metadata refresh failed")
+			retryAt = retryAt.Add(refreshRetryDelay)
+		}
+		if err := s.jobs.SaveRefreshCursor(schedule.JobID, cursor, retryAt); err != nil {
+			logger.WithError(err).Warn("Failed to record metadata refresh progress")
+		}
+	}
+}
+
+// RefreshJobAsync starts a metadata refresh of a job in the background and
+// returns once the job is known to exist. A job already being refreshed is
+// not refreshed twice: ErrRefreshRunning is returned instead.
+func (s *Service) RefreshJobAsync(jobID string) error {
+	if _, err := s.jobs.GetByID(jobID); err != nil {
+		return fmt.Errorf("get job: %w", err)
+	}
+	if _, busy := s.refreshing.LoadOrStore(jobID, struct{}{}); busy {
+		return ErrRefreshRunning
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer s.refreshing.Delete(jobID)
+		ctx, cancel := context.WithTimeout(s.ctx, refreshTimeout)
+		defer cancel()
+
+		refreshed, _, err := s.refresh(ctx, jobID, "")
+		if err != nil {
+			log.WithError(err).WithField("jobID", jobID).Warn("Metadata refresh failed")
+			return
+		}
+		log.WithField("jobID", jobID).Infof("Metadata refresh updated %d video(s)", refreshed)
+	}()
+	return nil
+}
+
+// RefreshJob re-fetches the metadata of a video job, or of every member video
+// of a playlist or channel job, and appends the results to their engagement
+// history. Returns the number of videos refreshed; failures of individual
+// member videos are logged and skipped.
+func (s *Service) RefreshJob(ctx context.Context, jobID string) (int, error) {
+	if _, busy := s.refreshing.LoadOrStore(jobID, struct{}{}); busy {
+		return 0, fmt.Errorf("refresh of %s: %w", jobID, ErrRefreshRunning)
+	}
+	defer s.refreshing.Delete(jobID)
+
+	refreshed, _, err := s.refresh(ctx, jobID, "")
+	return refreshed, err
+}
+
+// refresh runs a refresh of jobID, starting after the member video cursor
+// when it is set. It returns the number of videos refreshed and, when it
+// stops early, the cursor to resume from. The caller holds the job's
+// refreshing entry.
+func (s *Service) refresh(ctx context.Context, jobID, cursor string) (int, string, error) {
+	jobWithMetadata, err := s.jobs.GetJobWithMetadata(jobID)
+	if err != nil {
+		return 0, cursor, fmt.Errorf("get job: %w", err)
+	}
+
+	switch m := jobWithMetadata.Metadata.(type) {
+	case *domain.VideoMetadata:
+		if err := s.refreshVideo(ctx, jobWithMetadata.Job, m); err != nil {
+			return 0, cursor, err
+		}
+		return 1, "", nil
+	case *domain.PlaylistMetadata, *domain.ChannelMetadata:
+		videos, err := s.jobs.GetVideosForParent(jobID)
+		if err != nil {
+			return 0, cursor, fmt.Errorf("get member videos: %w", err)
+		}
+		refreshed := 0
+		for _, video := range resumeAfter(videos, cursor) {
+			if ctx.Err() != nil {
+				return refreshed, cursor, ctx.Err()
+			}
+			videoMeta, ok := video.Metadata.(*domain.VideoMetadata)
+			if !ok || video.Job == nil {
+				continue
+			}
+			if err := s.refreshVideo(ctx, video.Job, videoMeta); err != nil {
+				if ctx.Err() != nil {
+					// Cut off mid-fetch; this video is the first to redo.
+					return refreshed, cursor, ctx.Err()
+				}
+				log.WithError(err).WithField("jobID", video.Job.ID).Warn("Failed to refresh video metadata")
+			} else {
+				refreshed++
+			}
+			cursor = video.Job.ID
+		}
+		return refreshed, "", nil
+	default:
+		return 0, cursor, fmt.Errorf("job %s has no metadata to refresh", jobID)
+	}
+}
+
+// resumeAfter returns the member videos following the one with job ID
+// cursor, or all of them when cursor is empty or no longer a member.
+func resumeAfter(videos []*domain.JobWithMetadata, cursor string) []*domain.JobWithMetadata {
+	if cursor == "" {
+		return videos
+	}
+	for i, video := range videos {
+		if video != nil && video.Job != nil && video.Job.ID == cursor {
+			return videos[i+1:]
+		}
+	}
+	return videos
+}
+
+// refreshVideo fetches a video's current metadata, records it as a history
+// snapshot and folds the new counters into the stored metadata.
+func (s *Service) refreshVideo(ctx context.Context, job *domain.Job, stored *domain.VideoMetadata) error {
+	fresh, err := fetchVideoMetadata(ctx, job.URL)
+	if err != nil {
+		return err
+	}
+
+	logger := log.WithField("jobID", job.ID)
+	if fresh.Title != stored.Title {
+		logger.Infof("Upstream title changed: %q -> %q", stored.Title, fresh.Title)
+	}
+	if fresh.Description != stored.Description {
+		logger.Info("Upstream description changed")
+	}
+
+	if err := s.jobs.RecordMetadataSnapshot(job.ID, fresh); err != nil {
+		return err
+	}
+
+	updated := mergeRefreshedMetadata(stored, fresh)
+	if err := s.jobs.StoreMetadata(job.ID, updated); err != nil {
+		return fmt.Errorf("store refreshed metadata: %w", err)
+	}
+	s.hub.Broadcast(domain.MetadataUpdate{JobID: job.ID, Metadata: updated})
+	return nil
+}
+
+// mergeRefreshedMetadata returns the stored metadata with the engagement
+// counters taken from a refresh. Title and description stay as archived —
+// upstream edits live in the history — and format fields keep describing the
+// downloaded file rather than what the source offers now.
+func mergeRefreshedMetadata(stored, fresh *domain.VideoMetadata) *domain.VideoMetadata {
+	updated := *stored
+	updated.ViewCount = fresh.ViewCount
+	updated.LikeCount = fresh.LikeCount
+	updated.CommentCount = fresh.CommentCount
+	if fresh.ChannelFollowers > 0 {
+		updated.ChannelFollowers = fresh.ChannelFollowers
+	}
+	return &updated
+}
+
+// fetchVideoMetadata runs yt-dlp in metadata-only mode for a single video.
+func fetchVideoMetadata(ctx context.Context, url string) (*domain.VideoMetadata, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "yt-dlp",
+		"--skip-download",
+		"--dump-json",
+		"--no-playlist",
+		"--no-warnings",
+		url,
+	)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("metadata fetch failed: %w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+
+	var fresh domain.VideoMetadata
+	if err := json.Unmarshal(stdout.Bytes(), &fresh); err != nil {
+		return nil, fmt.Errorf("parse refreshed metadata: %w", err)
+	}
+	if fresh.Type != "" && fresh.Type != "video" {
+		return nil, fmt.Errorf("expected a video, got %q", fresh.Type)
+	}
+	return &fresh, nil
+}
@@ -0,0 +1,99 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"video-archiver/internal/domain"
+	"video-archiver/internal/testutil"
+)
+
+func TestMergeRefreshedMetadata(t *testing.T) {
+	stored := &domain.VideoMetadata{
+		Title:       "Archived title",
+		Description: "Archived description",
+		ViewCount:   10,
+		LikeCount:   1,
+		Height:      720,
+		VideoCodec:  "avc1",
+	}
+	fresh := &domain.VideoMetadata{
+		Title:            "Edited title",
+		Description:      "Edited description",
+		ViewCount:        500,
+		LikeCount:        40,
+		CommentCount:     7,
+		ChannelFollowers: 1000,
+		Height:           2160,
+		VideoCodec:       "vp9",
+	}
+
+	got := mergeRefreshedMetadata(stored, fresh)
+
+	if got.ViewCount != 500 || got.LikeCount != 40 || got.CommentCount != 7 || got.ChannelFollowers != 1000 {
+		t.Errorf("counters not refreshed: %+v", got)
+	}
+	if got.Title != "Archived title" || got.Description != "Archived description" {
+		t.Errorf("archived text overwritten: %q / %q", got.Title, got.Description)
+	}
+	if got.Height != 720 || got.VideoCodec != "avc1" {
+		t.Errorf("downloaded format overwritten: %dp %s", got.Height, got.VideoCodec)
+	}
+	if stored.ViewCount != 10 {
+		t.Error("stored metadata was modified in place")
+	}
+}
+
+func TestResumeAfter(t *testing.T) {
+	var videos []*domain.JobWithMetadata
+	for _, id := range []string{"a", "b", "c"} {
+		videos = append(videos, &domain.JobWithMetadata{Job: &domain.Job{ID: id}})
+	}
+	ids := func(videos []*domain.JobWithMetadata) string {
+		var s string
+		for _, v := range videos {
+			s += v.Job.ID
+		}
+		return s
+	}
+
+	for cursor, want := range map[string]string{"": "abc", "a": "bc", "c": "", "gone": "abc"} {
+		if got := ids(resumeAfter(videos, cursor)); got != want {
+			t.Errorf("resumeAfter(%q) = %q, want %q", cursor, got, want)
+		}
+	}
+}
+
+func TestRefreshKeepsCursorWhenInterrupted(t *testing.T) {
+	repo := testutil.NewMockJobRepository()
+	repo.Create(testutil.CreateTestJob("pl", "https://youtube.com/playlist?list=x"))
+	repo.StoreMetadata("pl", testutil.CreateTestPlaylistMetadata())
+	for _, id := range []string{"v1", "v2"} {
+		repo.Create(testutil.CreateTestJob(id, "https://youtube.com/watch?v="+id))
+		repo.StoreMetadata(id, testutil.CreateTestVideoMetadata())
+		repo.AddVideoToParent(id, "pl", "playlist")
+	}
+	s := NewService(&Config{JobRepository: repo, DownloadPath: t.TempDir()})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	refreshed, cursor, err := s.refresh(ctx, "pl", "v1")
+	if !errors.Is(err, context.Canceled) || refreshed != 0 || cursor != "v1" {
+		t.Errorf("refresh() = %d, %q, %v; want the cursor kept", refreshed, cursor, err)
+	}
+}
+
+func TestRefreshJobAsyncRejectsRunningRefresh(t *testing.T) {
+	repo := testutil.NewMockJobRepository()
+	repo.Create(testutil.CreateTestJob("v1", "https://youtube.com/watch?v=v1"))
+	s := NewService(&Config{JobRepository: repo, DownloadPath: t.TempDir()})
+
+	s.refreshing.Store("v1", struct{}{})
+	if err := s.RefreshJobAsync("v1"); !errors.Is(err, ErrRefreshRunning) {
+		t.Errorf("RefreshJobAsync() error = %v, want ErrRefreshRunning", err)
+	}
+	if _, err := s.RefreshJob(context.Background(), "v1"); !errors.Is(err, ErrRefreshRunning) {
+		t.Errorf("RefreshJob() error = %v, want ErrRefreshRunning", err)
+	}
+}
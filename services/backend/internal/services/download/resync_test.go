@@ -0,0 +1,48 @@
+package download
+
+import (
+	"testing"
+
+	"video-archiver/internal/domain"
+	"video-archiver/internal/repositories/sqlite"
+	"video-archiver/internal/testutil"
+)
+
+// TestResyncKeepsJobOptions re-syncs a job stored in the database and checks
+// the options it was submitted with reach the queued download again.
+func TestResyncKeepsJobOptions(t *testing.T) {
+	db := testutil.CreateTestDB(t)
+	defer db.Close()
+	repo := sqlite.NewJobRepository(db)
+	s := NewService(&Config{JobRepository: repo, DownloadPath: t.TempDir()})
+
+	archiveComments, quality := true, 480
+	job := testutil.CreateTestJob("pl", "https://youtube.com/playlist?list=x")
+	job.ArchiveComments = &archiveComments
+	job.CustomQuality = &quality
+	job.Live = true
+	if err := repo.Create(job); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	job.Status = domain.JobStatusComplete
+	if err := repo.Update(job); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	if err := s.Resync(job.ID); err != nil {
+		t.Fatalf("Resync() error = %v", err)
+	}
+	queued := <-s.queue
+	if queued.ArchiveComments == nil || !*queued.ArchiveComments {
+		t.Errorf("re-synced ArchiveComments = %v, want true", queued.ArchiveComments)
+	}
+	if !s.archiveCommentsFor(queued) {
+		t.Error("re-synced job no longer archives comments")
+	}
+	if queued.CustomQuality == nil || *queued.CustomQuality != 480 {
+		t.Errorf("re-synced CustomQuality = %v, want 480", queued.CustomQuality)
+	}
+	if !queued.Live {
+		t.Error("re-synced live capture queued as a normal download")
+	}
+}
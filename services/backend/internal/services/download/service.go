@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	log "github.com/sirupsen/logrus"
 	"io"
@@ -13,6 +14,7 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 	"video-archiver/internal/domain"
 	"video-archiver/internal/services/metadata"
@@ -25,26 +27,63 @@ type Config struct {
 	DownloadPath       string
 	Concurrency        int
 	MaxQuality         int
+	// Notifier receives lifecycle events for webhooks; optional.
+	Notifier domain.Notifier
+}
+
+// CompletionListener is told about every download that completes, after its
+// job is marked complete. It runs on the download worker, so it must hand
+// any real work off instead of blocking.
+type CompletionListener interface {
+	DownloadCompleted(job domain.Job)
+}
+
+// CompletionListeners tells each of several listeners, in order.
+type CompletionListeners []CompletionListener
+
+func (l CompletionListeners) DownloadCompleted(job domain.Job) {
+	for _, listener := range l {
+		listener.DownloadCompleted(job)
+	}
 }
 
 // activeJob tracks a running job and its cancellation function
 type activeJob struct {
 	job    *domain.Job
 	cancel context.CancelFunc
+	// live is set once the job is known to be a live recording, which
+	// cancelling stops and keeps instead of discarding.
+	live atomic.Bool
+	// deleted is set when the job is deleted while running, so the worker
+	// writes no status and tells no listener about it afterwards.
+	deleted atomic.Bool
+	// done is closed once the worker has let go of the job.
+	done chan struct{}
 }
 
 type Service struct {
 	config     *Config
 	jobs       domain.JobRepository
 	settings   domain.SettingsRepository
+	notifier   domain.Notifier
+	completion CompletionListener
 	queue      chan domain.Job
 	wg         sync.WaitGroup
 	hub        *WebSocketHub
 	ctx        context.Context
 	cancel     context.CancelFunc
-	activeJobs sync.Map // map[string]*activeJob
+	activeJobs sync.Map    // map[string]*activeJob
+	refreshing sync.Map    // job IDs with a metadata refresh in flight
+	upgrading  atomic.Bool // a quality upgrade run is in progress
+	// listTab lists the videos of a channel tab; a field so tests can stub
+	// yt-dlp.
+	listTab func(ctx context.Context, url string) ([]domain.PlaylistEntry, error)
 }
 
+// ErrQueueFull is returned by Submit and Resync while the download queue has
+// no room; callers may try again later.
+var ErrQueueFull = errors.New("download queue is full, try again later")
+
 func NewService(config *Config) *Service {
 	ctx, cancel := context.WithCancel(context.Background())
 	hub := NewWebSocketHub()
@@ -53,10 +92,12 @@ func NewService(config *Config) *Service {
 		config:   config,
 		jobs:     config.JobRepository,
 		settings: config.SettingsRepository,
+		notifier: config.Notifier,
 		queue:    make(chan domain.Job, 100),
 		hub:      hub,
 		ctx:      ctx,
 		cancel:   cancel,
+		listTab:  listChannelTab,
 	}
 }
 
@@ -68,6 +109,10 @@ func (s *Service) Start() error {
 		go s.processJobs()
 	}
 
+	s.wg.Add(2)
+	go s.runRefreshScheduler()
+	go s.runAvailabilityChecker()
+
 	return nil
 }
 
@@ -81,6 +126,19 @@ func (s *Service) GetHub() *WebSocketHub {
 	return s.hub
 }
 
+// notify passes a lifecycle event on to the webhooks, if any are configured.
+func (s *Service) notify(event domain.WebhookEvent, data any) {
+	if s.notifier != nil {
+		s.notifier.Notify(event, data)
+	}
+}
+
+// SetCompletionListener registers the listener told about completed
+// downloads. It must be called before Start.
+func (s *Service) SetCompletionListener(listener CompletionListener) {
+	s.completion = listener
+}
+
 func (s *Service) Submit(job domain.Job) error {
 	job.Status = domain.JobStatusPending
 	job.Progress = 0
@@ -88,6 +146,15 @@ func (s *Service) Submit(job domain.Job) error {
 	if err := s.jobs.Create(&job); err != nil {
 		return fmt.Errorf("failed to create job: %w", err)
 	}
+	// Stored before queueing so the download already sees them.
+	if job.ChannelOptions != nil {
+		if err := s.jobs.SetChannelOptions(job.ID, job.ChannelOptions); err != nil {
+			if delErr := s.jobs.DeleteJob(job.ID); delErr != nil {
+				log.WithError(delErr).WithField("jobID", job.ID).Warn("Failed to remove job after storing channel options failed")
+			}
+			return fmt.Errorf("failed to store channel options: %w", err)
+		}
+	}
 
 	// Never block the HTTP handler on a full queue — reject and clean up the
 	// record instead.
@@ -98,7 +165,40 @@ func (s *Service) Submit(job domain.Job) error {
 		if err := s.jobs.DeleteJob(job.ID); err != nil {
 			log.WithError(err).WithField("jobID", job.ID).Warn("Failed to remove rejected job")
 		}
-		return fmt.Errorf("download queue is full, try again later")
+		return ErrQueueFull
+	}
+}
+
+// Resync runs a finished playlist or channel job again under the same ID,
+// picking up items added since the last run. Already-archived videos are
+// skipped and stored channel options apply as before.
+func (s *Service) Resync(id string) error {
+	stored, err := s.jobs.GetByID(id)
+	if err != nil {
+		return fmt.Errorf("get job: %w", err)
+	}
+	if stored.Status == domain.JobStatusPending || stored.Status == domain.JobStatusInProgress {
+		return fmt.Errorf("job is already %s", stored.Status)
+	}
+
+	job := *stored
+	job.Status = domain.JobStatusPending
+	job.Progress = 0
+	job.Warnings = nil
+	if err := s.jobs.Update(&job); err != nil {
+		return fmt.Errorf("update job: %w", err)
+	}
+
+	select {
+	case s.queue <- job:
+		return nil
+	default:
+		job.Status = stored.Status
+		job.Progress = stored.Progress
+		if err := s.jobs.Update(&job); err != nil {
+			log.WithError(err).WithField("jobID", job.ID).Warn("Failed to restore job status")
+		}
+		return ErrQueueFull
 	}
 }
 
@@ -120,10 +220,21 @@ func (s *Service) CancelJob(id string) error {
 	if activeJobVal, ok := s.activeJobs.Load(id); ok {
 		if aj, ok := activeJobVal.(*activeJob); ok && aj.cancel != nil {
 			aj.cancel() // This will stop the yt-dlp process via context
+			if aj.live.Load() {
+				// The recording is finalized and the job completed by
+				// processJob; it only ends up cancelled if nothing was recorded.
+				log.WithField("job_id", id).Info("Stopping live recording")
+				return nil
+			}
 			log.WithField("job_id", id).Info("Cancelled running download process")
 		}
 	}
 
+	return s.markCancelled(job)
+}
+
+// markCancelled records a job as cancelled and tells clients.
+func (s *Service) markCancelled(job *domain.Job) error {
 	job.Status = domain.JobStatusCancelled
 	job.UpdatedAt = time.Now()
 
@@ -139,8 +250,9 @@ func (s *Service) CancelJob(id string) error {
 		Progress: job.Progress,
 	}
 	s.hub.Broadcast(cancelUpdate)
+	s.notify(domain.WebhookEventDownloadCancelled, cancelUpdate)
 
-	log.WithField("job_id", id).Info("Download job cancelled")
+	log.WithField("job_id", job.ID).Info("Download job cancelled")
 	return nil
 }
 
@@ -149,6 +261,16 @@ func (s *Service) CancelJob(id string) error {
 // Deleting a playlist or channel removes only the parent record — the videos it
 // contains remain individually downloaded jobs.
 func (s *Service) DeleteJob(id string) error {
+	return s.deleteJob(id, true)
+}
+
+// DeleteJobKeepFiles removes a download from the library like DeleteJob but
+// leaves its media files on disk.
+func (s *Service) DeleteJobKeepFiles(id string) error {
+	return s.deleteJob(id, false)
+}
+
+func (s *Service) deleteJob(id string, removeFiles bool) error {
 	jwm, err := s.jobs.GetJobWithMetadata(id)
 	if err != nil {
 		return fmt.Errorf("get job: %w", err)
@@ -158,23 +280,65 @@ func (s *Service) DeleteJob(id string) error {
 	}
 
 	if jwm.Job.Status == domain.JobStatusPending || jwm.Job.Status == domain.JobStatusInProgress {
-		if err := s.CancelJob(id); err != nil {
-			log.WithError(err).WithField("job_id", id).Warn("Failed to cancel job before deletion")
-		}
+		s.stopForDeletion(id)
 	}
 
-	if meta, ok := jwm.Metadata.(*domain.VideoMetadata); ok && meta != nil {
+	if meta, ok := jwm.Metadata.(*domain.VideoMetadata); ok && meta != nil && removeFiles {
 		s.removeVideoFiles(jwm.Job.FilePath, meta)
 	}
-
 	if err := s.jobs.DeleteJob(id); err != nil {
 		return fmt.Errorf("delete job records: %w", err)
 	}
+	// After the records: a storyboard generation finishing now sees the job
+	// gone and removes its own output.
+	s.removeCachedThumbnails(id)
+	// Channel art is a cache like the thumbnails: it goes with the last job of
+	// its channel, whichever kind that is.
+	s.removeChannelAssets(channelIDOf(jwm.Metadata))
 
 	log.WithField("job_id", id).Info("Download job deleted")
 	return nil
 }
 
+// deleteStopTimeout bounds how long deleting a running job waits for its
+// worker to stop: a live recording gets liveStopGrace to finalize, plus time
+// to remux what it left.
+const deleteStopTimeout = liveStopGrace + 30*time.Second
+
+// stopForDeletion cancels a job about to be deleted and waits for its worker
+// to let go of it, so the worker doesn't write the job back afterwards. Were
+// the wait to time out, the deleted flag still keeps the worker quiet.
+func (s *Service) stopForDeletion(id string) {
+	var aj *activeJob
+	if v, ok := s.activeJobs.Load(id); ok {
+		aj, _ = v.(*activeJob)
+	}
+	if aj != nil {
+		aj.deleted.Store(true)
+	}
+	if err := s.CancelJob(id); err != nil {
+		log.WithError(err).WithField("job_id", id).Warn("Failed to cancel job before deletion")
+	}
+	if aj == nil || aj.done == nil {
+		return
+	}
+	select {
+	case <-aj.done:
+	case <-time.After(deleteStopTimeout):
+		log.WithField("job_id", id).Warn("Job still running after cancellation, deleting anyway")
+	}
+}
+
+// deletedWhileRunning reports whether a running job has been deleted.
+func (s *Service) deletedWhileRunning(id string) bool {
+	if v, ok := s.activeJobs.Load(id); ok {
+		if aj, ok := v.(*activeJob); ok {
+			return aj.deleted.Load()
+		}
+	}
+	return false
+}
+
 // removeVideoFiles deletes a video's media file and its yt-dlp sidecar files
 // (.info.json etc.) from disk. Missing files are not an error — the library
 // record should be removable even if the file is already gone.
@@ -192,7 +356,11 @@ func (s *Service) removeVideoFiles(storedPath string, meta *domain.VideoMetadata
 	}
 
 	stem := strings.TrimSuffix(path, filepath.Ext(path))
-	for _, sidecar := range []string{stem + ".info.json", stem + ".description", stem + ".webp", stem + ".jpg"} {
+	sidecars := []string{stem + ".info.json", stem + ".description"}
+	for _, ext := range thumbnailExtensions {
+		sidecars = append(sidecars, stem+"."+ext)
+	}
+	for _, sidecar := range sidecars {
 		if err := os.Remove(sidecar); err == nil {
 			log.WithField("path", sidecar).Debug("Deleted sidecar file")
 		}
@@ -215,16 +383,27 @@ func (s *Service) processJobs() {
 			jobCtx, cancelFunc := context.WithCancel(s.ctx)
 
 			// Store job with cancel function
-			s.activeJobs.Store(job.ID, &activeJob{
+			aj := &activeJob{
 				job:    &job,
 				cancel: cancelFunc,
-			})
+				done:   make(chan struct{}),
+			}
+			aj.live.Store(job.Live)
+			s.activeJobs.Store(job.ID, aj)
 
 			if err := s.processJob(jobCtx, job); err != nil {
 				// Check if the error was due to context cancellation
-				if jobCtx.Err() == context.Canceled {
+				if aj.deleted.Load() {
+					log.WithField("jobID", job.ID).Info("Job was deleted while running")
+				} else if jobCtx.Err() == context.Canceled {
 					log.WithField("jobID", job.ID).Info("Job was cancelled")
-					// Status already updated by CancelJob, no need to update here
+					// Status already updated by CancelJob, except for live
+					// recordings that were stopped before recording anything.
+					if aj.live.Load() {
+						if err := s.markCancelled(&job); err != nil {
+							log.WithError(err).Error("Failed to update job status")
+						}
+					}
 				} else {
 					log.WithError(err).
 						WithField("jobID", job.ID).
@@ -243,11 +422,13 @@ func (s *Service) processJobs() {
 						Progress: job.Progress,
 					}
 					s.hub.Broadcast(errorUpdate)
+					s.notify(domain.WebhookEventDownloadFailed, errorUpdate)
 				}
 			}
 
 			// Remove from active jobs after completion
 			s.activeJobs.Delete(job.ID)
+			close(aj.done)
 		}
 	}
 }
@@ -273,6 +454,12 @@ func (s *Service) processJob(ctx context.Context, job domain.Job) error {
 		log.WithError(err).Error("Failed to extract basic metadata")
 	}
 
+	if !job.Live && isLiveSource(extractedMetadata) {
+		log.WithField("jobID", job.ID).Info("Source is a live or scheduled stream, recording it live")
+		job.Live = true
+		s.markLive(job.ID)
+	}
+
 	isPlaylist := false
 	isChannel := false
 
@@ -307,6 +494,29 @@ func (s *Service) processJob(ctx context.Context, job domain.Job) error {
 		}()
 	}
 
+	// Archive channel art and playlist covers alongside the download; like
+	// enhancement it is bounded by the service context rather than the job's.
+	if channelMeta, ok := extractedMetadata.(*domain.ChannelMetadata); ok && channelMeta != nil {
+		assetsCtx, assetsCancel := context.WithTimeout(s.ctx, 2*time.Minute)
+		channelCopy := copyMetadata(channelMeta).(*domain.ChannelMetadata)
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer assetsCancel()
+			s.archiveChannelAssets(assetsCtx, job.ID, channelCopy)
+		}()
+	}
+	if playlistMeta, ok := extractedMetadata.(*domain.PlaylistMetadata); ok && playlistMeta != nil {
+		thumbCtx, thumbCancel := context.WithTimeout(s.ctx, 2*time.Minute)
+		playlistCopy := copyMetadata(playlistMeta).(*domain.PlaylistMetadata)
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer thumbCancel()
+			s.archivePlaylistThumbnail(thumbCtx, job.ID, playlistCopy)
+		}()
+	}
+
 	// Start download immediately (runs in parallel with metadata enhancement)
 	if isPlaylist || isChannel {
 		// For playlists and channels, we may want to modify the download command
@@ -321,10 +531,26 @@ func (s *Service) processJob(ctx context.Context, job domain.Job) error {
 		return fmt.Errorf("download failed: %w", err)
 	}
 
+	if s.deletedWhileRunning(job.ID) {
+		return nil
+	}
 	job.Status = domain.JobStatusComplete
 	job.Progress = 100.0
 
-	return s.jobs.Update(&job)
+	if err := s.jobs.Update(&job); err != nil {
+		return err
+	}
+	s.notify(domain.WebhookEventDownloadCompleted, domain.ProgressUpdate{
+		JobID:         job.ID,
+		JobType:       jobTypeFor(job),
+		Status:        domain.JobStatusComplete,
+		Progress:      job.Progress,
+		DownloadPhase: domain.DownloadPhaseComplete,
+	})
+	if s.completion != nil {
+		s.completion.DownloadCompleted(job)
+	}
+	return nil
 }
 
 func (s *Service) getSettings() (int, int) {
@@ -446,6 +672,11 @@ func (s *Service) downloadPlaylistOrChannel(ctx context.Context, job domain.Job,
 		"--yes-playlist", // Ensure playlist processing is enabled
 	}
 	cmdArgs = append(cmdArgs, downloadFormatArgs(job, maxQuality)...)
+	cmdArgs = append(cmdArgs, thumbnailArgs()...)
+	archiveComments := s.archiveCommentsFor(job)
+	if archiveComments {
+		cmdArgs = append(cmdArgs, commentArgs()...)
+	}
 
 	printFile, cleanupPrintFile := createPrintFile(job.ID)
 	if printFile != "" {
@@ -453,14 +684,22 @@ func (s *Service) downloadPlaylistOrChannel(ctx context.Context, job domain.Job,
 		cmdArgs = append(cmdArgs, "--print-to-file", "after_move:%(id)s\t%(filepath)s", printFile)
 	}
 
-	// Add channel-specific arguments if this is a channel
+	// Channels honour the tabs and filters stored on the job, so a re-sync
+	// selects the same videos as the first download.
+	downloadURLs := []string{downloadURL}
 	if _, isChannel := metadataModel.(*domain.ChannelMetadata); isChannel {
-		// For channels, we may want to limit the number of videos or specify sorting
-		// Add any channel-specific parameters here if needed
-		log.Info("Adding channel-specific download parameters")
+		options, err := s.jobs.GetChannelOptions(job.ID)
+		if err != nil {
+			log.WithError(err).WithField("jobID", job.ID).Warn("Failed to load channel options, downloading the whole channel")
+		}
+		if options != nil {
+			downloadURLs = channelTabURLs(downloadURL, options.Tabs)
+			cmdArgs = append(cmdArgs, channelFilterArgs(options)...)
+			log.WithFields(log.Fields{"jobID": job.ID, "urls": downloadURLs}).Info("Applying channel download options")
+		}
 	}
 
-	cmdArgs = append(cmdArgs, downloadURL)
+	cmdArgs = append(cmdArgs, downloadURLs...)
 	downloadCmd := exec.CommandContext(ctx, "yt-dlp", cmdArgs...)
 
 	stdout, err := downloadCmd.StdoutPipe()
@@ -612,6 +851,9 @@ func (s *Service) downloadPlaylistOrChannel(ctx context.Context, job domain.Job,
 					if existingJob.FilePath == "" {
 						s.recordFilePath(videoJobID, printedPaths[id])
 					}
+					if archiveComments {
+						s.storeComments(videoJobID, metadataFilePath)
+					}
 					// Job exists, create membership relationship
 					membershipType := "unknown"
 					switch metadataModel.(type) {
@@ -663,6 +905,9 @@ func (s *Service) downloadPlaylistOrChannel(ctx context.Context, job domain.Job,
 					continue
 				}
 				log.Debugf("Successfully stored metadata for video %s", videoJobID)
+				if archiveComments {
+					s.storeComments(videoJobID, metadataFilePath)
+				}
 
 				// Link the video to the playlist/channel
 				membershipType := "unknown"
@@ -912,11 +1157,16 @@ func (s *Service) downloadVideo(ctx context.Context, job domain.Job, outputPath
 		log.Infof("[Job %s] Starting video download with quality: %dp, concurrency: %d", job.ID, maxQuality, concurrency)
 	}
 
+	// Enhanced progress template with format info to distinguish video/audio streams
+	progressTemplate := "[NA][NA][%(info.id)s][%(info.title).50s][%(info.format_id)s][%(info.format_note)s][%(info.vcodec)s][%(info.acodec)s]prog:[%(progress.downloaded_bytes)s/%(progress.total_bytes)s][%(progress._percent_str)s][%(progress.speed)s][%(progress.eta)s]"
+	if job.Live {
+		progressTemplate = liveProgressTemplate
+	}
+
 	cmdArgs := []string{
 		"-N", fmt.Sprintf("%d", concurrency),
 		"--newline",
-		// Enhanced progress template with format info to distinguish video/audio streams
-		"--progress-template", "[NA][NA][%(info.id)s][%(info.title).50s][%(info.format_id)s][%(info.format_note)s][%(info.vcodec)s][%(info.acodec)s]prog:[%(progress.downloaded_bytes)s/%(progress.total_bytes)s][%(progress._percent_str)s][%(progress.speed)s][%(progress.eta)s]",
+		"--progress-template", progressTemplate,
 		"--retries", "3", // Retry up to 3 times per fragment
 		"--fragment-retries", "5", // Retry fragments up to 5 times
 		"--file-access-retries", "2", // Retry file access operations
@@ -927,6 +1177,14 @@ func (s *Service) downloadVideo(ctx context.Context, job domain.Job, outputPath
 		"--output", outputPath,
 	}
 	cmdArgs = append(cmdArgs, downloadFormatArgs(job, maxQuality)...)
+	cmdArgs = append(cmdArgs, thumbnailArgs()...)
+	if job.Live {
+		cmdArgs = append(cmdArgs, liveArgs()...)
+	}
+	archiveComments := s.archiveCommentsFor(job)
+	if archiveComments {
+		cmdArgs = append(cmdArgs, commentArgs()...)
+	}
 
 	printFile, cleanupPrintFile := createPrintFile(job.ID)
 	if printFile != "" {
@@ -936,6 +1194,9 @@ func (s *Service) downloadVideo(ctx context.Context, job domain.Job, outputPath
 
 	cmdArgs = append(cmdArgs, job.URL)
 	downloadCmd := exec.CommandContext(ctx, "yt-dlp", cmdArgs...)
+	if job.Live {
+		stopGracefully(downloadCmd)
+	}
 
 	stdout, err := downloadCmd.StdoutPipe()
 	if err != nil {
@@ -953,10 +1214,25 @@ func (s *Service) downloadVideo(ctx context.Context, job domain.Job, outputPath
 
 	// One tracker fed by both pipes; the pipes must be drained before Wait.
 	tracker := NewProgressTracker(s, job.ID, jobTypeFor(job))
+	tracker.state.IsLive = job.Live
 	waitForOutput := tracker.track(stdout, stderr)
 	waitForOutput()
 
 	err = downloadCmd.Wait()
+	if err != nil && job.Live && ctx.Err() != nil {
+		// A stopped live recording is kept: finalize what was recorded and
+		// complete the job with it. The per-job context is already
+		// cancelled, so the finalization runs detached from it.
+		mediaPath, finalizeErr := s.finalizeLiveRecording(context.WithoutCancel(ctx), job.ID, readPrintFile(printFile), tracker.destinations())
+		if finalizeErr != nil {
+			log.WithError(finalizeErr).WithField("jobID", job.ID).Warn("Nothing to keep from stopped live recording")
+			return err
+		}
+		tracker.finishStopped()
+		s.recordFilePath(job.ID, mediaPath)
+		log.WithFields(log.Fields{"jobID": job.ID, "path": mediaPath}).Info("Live recording stopped and kept")
+		return nil
+	}
 	if err != nil {
 		log.WithError(err).WithField("jobID", job.ID).Error("yt-dlp command failed")
 		return err
@@ -967,8 +1243,12 @@ func (s *Service) downloadVideo(ctx context.Context, job domain.Job, outputPath
 
 	if printFile != "" {
 		if f, err := os.Open(printFile); err == nil {
-			s.recordFilePath(job.ID, printedFilepath(f))
+			mediaPath := printedFilepath(f)
 			f.Close()
+			s.recordFilePath(job.ID, mediaPath)
+			if archiveComments && mediaPath != "" {
+				s.storeComments(job.ID, infoJSONFor(mediaPath))
+			}
 		}
 	}
 
@@ -991,6 +1271,9 @@ func (s *Service) extractBasicMetadata(ctx context.Context, job domain.Job, outp
 		"--write-info-json",
 		"--no-progress",
 		"--flat-playlist",
+		// Scheduled streams have no formats yet; without this yt-dlp fails
+		// instead of writing the info JSON that identifies them as upcoming.
+		"--ignore-no-formats-error",
 		"--output", outputPath,
 		job.URL,
 	)
@@ -1058,6 +1341,7 @@ func (s *Service) extractBasicMetadata(ctx context.Context, job domain.Job, outp
 		s.hub.Broadcast(basicMetadataUpdate)
 		log.Debug("Sent immediate basic metadata update to UI")
 	}
+	s.recordPlaylistSnapshot(ctx, job.ID, extractedMetadata, metadataPath)
 
 	update.Progress = 1
 	s.hub.Broadcast(update)
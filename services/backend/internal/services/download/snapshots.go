@@ -0,0 +1,165 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"path"
+
+	log "github.com/sirupsen/logrus"
+	"video-archiver/internal/domain"
+	"video-archiver/internal/services/metadata"
+)
+
+// snapshotTabs are the channel tabs whose videos make up the snapshot of a
+// channel listed by its root URL.
+var snapshotTabs = []domain.ChannelTab{domain.ChannelTabVideos, domain.ChannelTabShorts, domain.ChannelTabStreams}
+
+// recordPlaylistSnapshot stores the item list of a playlist or channel sync
+// from its flat .info.json. Best-effort: a failure is logged and the sync
+// continues.
+func (s *Service) recordPlaylistSnapshot(ctx context.Context, jobID string, extracted domain.Metadata, infoJSONPath string) {
+	var sourceID string
+	switch m := extracted.(type) {
+	case *domain.PlaylistMetadata:
+		sourceID = m.ID
+	case *domain.ChannelMetadata:
+		sourceID = m.ID
+	default:
+		return
+	}
+	logger := log.WithField("jobID", jobID)
+	if sourceID == "" {
+		logger.Debug("Playlist has no ID, skipping snapshot")
+		return
+	}
+
+	entries, err := s.playlistEntries(ctx, infoJSONPath)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to read playlist entries for snapshot")
+		return
+	}
+	if len(entries) == 0 {
+		logger.Debug("Playlist listing has no items, skipping snapshot")
+		return
+	}
+
+	snapshot, err := s.jobs.RecordPlaylistSnapshot(jobID, sourceID, entries)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to record playlist snapshot")
+		return
+	}
+	logger.Infof("Recorded playlist snapshot %d: %d items (+%d -%d, %d renamed, %d reordered)",
+		snapshot.ID, snapshot.ItemCount, snapshot.AddedCount, snapshot.RemovedCount,
+		snapshot.RenamedCount, snapshot.ReorderedCount)
+
+	if snapshot.AddedCount > 0 && s.notifier != nil {
+		s.notifyNewItems(jobID, sourceID, snapshot.ID, entries)
+	}
+}
+
+// playlistEntries reads the items of a playlist or channel listing. A
+// channel root lists its tabs instead of videos; those are listed in turn
+// and their videos numbered across tabs in listing order, each video once.
+// A tab that fails to list fails the whole listing, since a partial one
+// would show the missing videos as removed.
+func (s *Service) playlistEntries(ctx context.Context, infoJSONPath string) ([]domain.PlaylistEntry, error) {
+	entries, err := metadata.ExtractPlaylistEntries(infoJSONPath)
+	if err != nil || len(entries) > 0 {
+		return entries, err
+	}
+	tabs, err := metadata.ExtractChannelTabs(infoJSONPath)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	for _, tab := range tabs {
+		if !isSnapshotTab(tab) {
+			continue
+		}
+		tabEntries, err := s.listTab(ctx, tab)
+		if err != nil {
+			return nil, fmt.Errorf("list %s: %w", tab, err)
+		}
+		for _, entry := range tabEntries {
+			if seen[entry.VideoID] {
+				continue
+			}
+			seen[entry.VideoID] = true
+			entry.Position = len(entries) + 1
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// isSnapshotTab reports whether tabURL points at one of snapshotTabs.
+func isSnapshotTab(tabURL string) bool {
+	u, err := url.Parse(tabURL)
+	if err != nil {
+		return false
+	}
+	name := domain.ChannelTab(path.Base(u.Path))
+	for _, tab := range snapshotTabs {
+		if name == tab {
+			return true
+		}
+	}
+	return false
+}
+
+// listChannelTab runs yt-dlp in flat-playlist mode over a channel tab.
+func listChannelTab(ctx context.Context, tabURL string) ([]domain.PlaylistEntry, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "yt-dlp",
+		"--flat-playlist",
+		"--dump-single-json",
+		"--no-warnings",
+		tabURL,
+	)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("tab listing failed: %w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return metadata.ParsePlaylistEntries(stdout.Bytes())
+}
+
+// notifyNewItems sends a subscription.new_item event for every item the
+// snapshot added over the previous one of the source. A first snapshot has
+// nothing to compare with and is never reported.
+func (s *Service) notifyNewItems(jobID, sourceID string, snapshotID int64, entries []domain.PlaylistEntry) {
+	snapshots, err := s.jobs.GetPlaylistSnapshots(sourceID)
+	if err != nil {
+		log.WithError(err).WithField("jobID", jobID).Warn("Failed to list playlist snapshots for notifications")
+		return
+	}
+	var previousID int64
+	for _, snapshot := range snapshots {
+		if snapshot.ID < snapshotID && snapshot.ID > previousID {
+			previousID = snapshot.ID
+		}
+	}
+	if previousID == 0 {
+		return
+	}
+	previous, err := s.jobs.GetPlaylistSnapshot(previousID)
+	if err != nil {
+		log.WithError(err).WithField("jobID", jobID).Warn("Failed to load previous playlist snapshot for notifications")
+		return
+	}
+
+	for _, added := range domain.DiffPlaylistEntries(previous.Items, entries).Added {
+		s.notify(domain.WebhookEventSubscriptionItem, domain.SubscriptionItem{
+			JobID:    jobID,
+			SourceID: sourceID,
+			VideoID:  added.VideoID,
+			Title:    added.Title,
+			Position: added.Position,
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package download
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"video-archiver/internal/domain"
+	"video-archiver/internal/testutil"
+)
+
+type recordingNotifier struct {
+	events []domain.WebhookEvent
+	data   []any
+}
+
+func (n *recordingNotifier) Notify(event domain.WebhookEvent, data any) {
+	n.events = append(n.events, event)
+	n.data = append(n.data, data)
+}
+
+func TestNotifyNewItems(t *testing.T) {
+	mockRepo := testutil.NewMockJobRepository()
+	notifier := &recordingNotifier{}
+	s := NewService(&Config{JobRepository: mockRepo, Notifier: notifier})
+
+	first := []domain.PlaylistEntry{{VideoID: "a", Title: "A", Position: 1}}
+	snapshot, _ := mockRepo.RecordPlaylistSnapshot("playlist-job", "PL1", first)
+	s.notifyNewItems("playlist-job", "PL1", snapshot.ID, first)
+	if len(notifier.events) != 0 {
+		t.Fatalf("first snapshot notified %v", notifier.events)
+	}
+
+	second := []domain.PlaylistEntry{
+		{VideoID: "b", Title: "B", Position: 1},
+		{VideoID: "a", Title: "A", Position: 2},
+	}
+	snapshot, _ = mockRepo.RecordPlaylistSnapshot("playlist-job", "PL1", second)
+	s.notifyNewItems("playlist-job", "PL1", snapshot.ID, second)
+
+	if len(notifier.events) != 1 || notifier.events[0] != domain.WebhookEventSubscriptionItem {
+		t.Fatalf("events = %v, want one subscription item", notifier.events)
+	}
+	item, ok := notifier.data[0].(domain.SubscriptionItem)
+	if !ok || item.VideoID != "b" || item.JobID != "playlist-job" || item.SourceID != "PL1" || item.Position != 1 {
+		t.Errorf("data = %+v", notifier.data[0])
+	}
+}
+
+func TestRecordPlaylistSnapshotListsChannelTabs(t *testing.T) {
+	mockRepo := testutil.NewMockJobRepository()
+	s := NewService(&Config{JobRepository: mockRepo})
+	tabs := map[string][]domain.PlaylistEntry{
+		"https://www.youtube.com/@chan/videos": {{VideoID: "a", Title: "A", Position: 1}, {VideoID: "b", Title: "B", Position: 2}},
+		"https://www.youtube.com/@chan/shorts": {{VideoID: "s", Title: "S", Position: 1}, {VideoID: "a", Title: "A", Position: 2}},
+	}
+	var listed []string
+	s.listTab = func(_ context.Context, url string) ([]domain.PlaylistEntry, error) {
+		listed = append(listed, url)
+		return tabs[url], nil
+	}
+
+	// A channel root listed flat has only its tabs as entries.
+	path := filepath.Join(t.TempDir(), "channel.info.json")
+	data := `{
+		"id": "UC1",
+		"_type": "playlist",
+		"entries": [
+			{"id": "UC1", "title": "Chan - Videos", "_type": "url", "ie_key": "YoutubeTab", "url": "https://www.youtube.com/@chan/videos"},
+			{"id": "UC1", "title": "Chan - Shorts", "_type": "url", "ie_key": "YoutubeTab", "url": "https://www.youtube.com/@chan/shorts"},
+			{"id": "UC1", "title": "Chan - Playlists", "_type": "url", "ie_key": "YoutubeTab", "url": "https://www.youtube.com/@chan/playlists"}
+		]
+	}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s.recordPlaylistSnapshot(context.Background(), "channel-job", &domain.ChannelMetadata{ID: "UC1"}, path)
+
+	if want := []string{"https://www.youtube.com/@chan/videos", "https://www.youtube.com/@chan/shorts"}; !reflect.DeepEqual(listed, want) {
+		t.Errorf("listed tabs = %v, want %v", listed, want)
+	}
+	snapshots, _ := mockRepo.GetPlaylistSnapshots("UC1")
+	if len(snapshots) != 1 {
+		t.Fatalf("snapshots = %d, want 1", len(snapshots))
+	}
+	snapshot, _ := mockRepo.GetPlaylistSnapshot(snapshots[0].ID)
+	want := []domain.PlaylistEntry{
+		{VideoID: "a", Title: "A", Position: 1},
+		{VideoID: "b", Title: "B", Position: 2},
+		{VideoID: "s", Title: "S", Position: 3},
+	}
+	if !reflect.DeepEqual(snapshot.Items, want) {
+		t.Errorf("items = %+v, want %+v", snapshot.Items, want)
+	}
+}
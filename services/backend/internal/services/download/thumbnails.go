@@ -0,0 +1,166 @@
+package download
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"video-archiver/internal/domain"
+)
+
+// thumbsDirName is the directory under the download path holding generated
+// thumbnails (resized variants and posters for videos without a downloaded
+// thumbnail). Hidden so it never shows up next to the uploader directories.
+const thumbsDirName = ".thumbs"
+
+// storyboardsDirName is the directory under the download path, next to
+// thumbsDirName, holding the seek-preview storyboards of videos.
+const storyboardsDirName = ".storyboards"
+
+// thumbnailExtensions lists the image sidecars yt-dlp may leave next to a
+// media file, in order of preference. jpg is what --convert-thumbnails
+// produces; webp and png cover downloads made before conversion was enabled.
+var thumbnailExtensions = []string{"jpg", "webp", "png"}
+
+// thumbnailArgs makes yt-dlp store the thumbnail next to the media file at
+// archive time, so the library keeps its artwork offline and after the source
+// removes the video. Converting to jpg keeps every browser able to show it.
+func thumbnailArgs() []string {
+	return []string{
+		"--write-thumbnail",
+		"--convert-thumbnails", "jpg",
+	}
+}
+
+// LocalThumbnail returns the downloaded thumbnail sidecar of a media file
+// (<stem>.jpg etc.), if one exists.
+func LocalThumbnail(mediaPath string) (string, bool) {
+	stem := strings.TrimSuffix(mediaPath, filepath.Ext(mediaPath))
+	for _, ext := range thumbnailExtensions {
+		p := stem + "." + ext
+		if info, err := os.Stat(p); err == nil && info.Mode().IsRegular() {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// ThumbnailCachePath returns where a generated thumbnail for a job is stored.
+// width 0 is the full-size poster; other widths are resized variants. The
+// result is validated to stay within the download directory.
+func ThumbnailCachePath(downloadPath, jobID string, width int) (string, error) {
+	base := filepath.Clean(downloadPath)
+	name := jobID + ".jpg"
+	if width > 0 {
+		name = fmt.Sprintf("%s-w%d.jpg", jobID, width)
+	}
+	path := filepath.Clean(filepath.Join(base, thumbsDirName, name))
+	if filepath.Dir(path) != filepath.Join(base, thumbsDirName) {
+		return "", fmt.Errorf("invalid job ID %q", jobID)
+	}
+	return path, nil
+}
+
+// ArchivedThumbnail returns the thumbnail archived for a job without a media
+// file of its own (a playlist's cover), if one has been downloaded. It lives
+// at the poster path of the job, under whichever image extension it came as.
+func ArchivedThumbnail(downloadPath, jobID string) (string, bool) {
+	poster, err := ThumbnailCachePath(downloadPath, jobID, 0)
+	if err != nil {
+		return "", false
+	}
+	stem := strings.TrimSuffix(poster, ".jpg")
+	for _, ext := range thumbnailExtensions {
+		p := stem + "." + ext
+		if info, err := os.Stat(p); err == nil && info.Mode().IsRegular() {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// bestThumbnailURL picks the largest thumbnail of a list. yt-dlp orders
+// thumbnails from worst to best, so without dimensions the last one wins.
+func bestThumbnailURL(thumbnails []domain.Thumbnail) string {
+	var best *domain.Thumbnail
+	for i := range thumbnails {
+		t := &thumbnails[i]
+		if t.URL == "" {
+			continue
+		}
+		if best == nil || t.Width*t.Height >= best.Width*best.Height {
+			best = t
+		}
+	}
+	if best == nil {
+		return ""
+	}
+	return best.URL
+}
+
+// archivePlaylistThumbnail downloads a playlist's cover into the thumbnail
+// cache, replacing an earlier copy so a re-sync picks up new art. yt-dlp only
+// writes thumbnails of the videos, so without this the cover stays remote.
+// Failures are logged and never fail the playlist download.
+func (s *Service) archivePlaylistThumbnail(ctx context.Context, jobID string, metadata *domain.PlaylistMetadata) {
+	url := bestThumbnailURL(metadata.Thumbnails)
+	if url == "" {
+		return
+	}
+	poster, err := ThumbnailCachePath(s.config.DownloadPath, jobID, 0)
+	if err != nil {
+		log.WithError(err).WithField("jobID", jobID).Warn("Cannot archive playlist thumbnail")
+		return
+	}
+	dir := filepath.Dir(poster)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		log.WithError(err).WithField("jobID", jobID).Warn("Failed to create thumbnails directory")
+		return
+	}
+	client := &http.Client{Timeout: 30 * time.Second}
+	if err := fetchChannelAsset(ctx, client, url, dir, jobID); err != nil {
+		log.WithError(err).WithField("jobID", jobID).Warn("Failed to archive playlist thumbnail")
+		return
+	}
+	log.WithField("jobID", jobID).Debug("Archived playlist thumbnail")
+}
+
+// StoryboardCacheDir returns the directory holding a job's storyboard sprite
+// sheets and WebVTT index, validated to stay within the download directory.
+func StoryboardCacheDir(downloadPath, jobID string) (string, error) {
+	base := filepath.Clean(downloadPath)
+	dir := filepath.Clean(filepath.Join(base, storyboardsDirName, jobID))
+	if filepath.Dir(dir) != filepath.Join(base, storyboardsDirName) {
+		return "", fmt.Errorf("invalid job ID %q", jobID)
+	}
+	return dir, nil
+}
+
+// removeCachedThumbnails deletes every generated thumbnail and the storyboard
+// of a job. Used when the job itself is deleted.
+func (s *Service) removeCachedThumbnails(jobID string) {
+	poster, err := ThumbnailCachePath(s.config.DownloadPath, jobID, 0)
+	if err != nil {
+		return
+	}
+	stem := strings.TrimSuffix(poster, ".jpg")
+	variants, _ := filepath.Glob(stem + "-w*.jpg")
+	for _, ext := range thumbnailExtensions {
+		variants = append(variants, stem+"."+ext)
+	}
+	for _, path := range variants {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			log.WithError(err).WithField("path", path).Warn("Failed to delete cached thumbnail")
+		}
+	}
+	if dir, err := StoryboardCacheDir(s.config.DownloadPath, jobID); err == nil {
+		if err := os.RemoveAll(dir); err != nil {
+			log.WithError(err).WithField("path", dir).Warn("Failed to delete storyboard")
+		}
+	}
+}
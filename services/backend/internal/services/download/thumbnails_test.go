@@ -0,0 +1,154 @@
+package download
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"video-archiver/internal/domain"
+	"video-archiver/internal/testutil"
+)
+
+func TestLocalThumbnail(t *testing.T) {
+	dir := t.TempDir()
+	media := filepath.Join(dir, "Video.mp4")
+
+	if _, ok := LocalThumbnail(media); ok {
+		t.Error("expected no thumbnail before any sidecar exists")
+	}
+
+	webp := filepath.Join(dir, "Video.webp")
+	if err := os.WriteFile(webp, []byte("webp"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := LocalThumbnail(media); !ok || got != webp {
+		t.Errorf("LocalThumbnail = %q, %v; want %q", got, ok, webp)
+	}
+
+	// The converted jpg wins over a leftover webp.
+	jpg := filepath.Join(dir, "Video.jpg")
+	if err := os.WriteFile(jpg, []byte("jpg"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := LocalThumbnail(media); !ok || got != jpg {
+		t.Errorf("LocalThumbnail = %q, %v; want %q", got, ok, jpg)
+	}
+}
+
+func TestThumbnailCachePath(t *testing.T) {
+	base := t.TempDir()
+
+	got, err := ThumbnailCachePath(base, "job1", 0)
+	if err != nil {
+		t.Fatalf("ThumbnailCachePath: %v", err)
+	}
+	if want := filepath.Join(base, thumbsDirName, "job1.jpg"); got != want {
+		t.Errorf("poster path = %q, want %q", got, want)
+	}
+
+	got, err = ThumbnailCachePath(base, "job1", 320)
+	if err != nil {
+		t.Fatalf("ThumbnailCachePath: %v", err)
+	}
+	if want := filepath.Join(base, thumbsDirName, "job1-w320.jpg"); got != want {
+		t.Errorf("variant path = %q, want %q", got, want)
+	}
+
+	for _, id := range []string{"../../etc/passwd", "a/b"} {
+		if _, err := ThumbnailCachePath(base, id, 0); err == nil {
+			t.Errorf("expected job ID %q to be rejected", id)
+		}
+	}
+}
+
+func TestStoryboardCacheDir(t *testing.T) {
+	base := t.TempDir()
+
+	got, err := StoryboardCacheDir(base, "job1")
+	if err != nil {
+		t.Fatalf("StoryboardCacheDir: %v", err)
+	}
+	if want := filepath.Join(base, storyboardsDirName, "job1"); got != want {
+		t.Errorf("storyboard dir = %q, want %q", got, want)
+	}
+
+	for _, id := range []string{"../../etc", "a/b", "", ".."} {
+		if _, err := StoryboardCacheDir(base, id); err == nil {
+			t.Errorf("expected job ID %q to be rejected", id)
+		}
+	}
+}
+
+func TestRemoveCachedThumbnailsRemovesStoryboard(t *testing.T) {
+	s := NewService(&Config{JobRepository: testutil.NewMockJobRepository(), DownloadPath: t.TempDir()})
+
+	dir, err := StoryboardCacheDir(s.config.DownloadPath, "job1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "0.jpg"), []byte("jpeg"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s.removeCachedThumbnails("job1")
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("storyboard directory still exists: %v", err)
+	}
+}
+
+func TestBestThumbnailURL(t *testing.T) {
+	tests := []struct {
+		name       string
+		thumbnails []domain.Thumbnail
+		want       string
+	}{
+		{"none", nil, ""},
+		{"largest", []domain.Thumbnail{
+			{URL: "https://x/big", Width: 1280, Height: 720},
+			{URL: "https://x/small", Width: 320, Height: 180},
+		}, "https://x/big"},
+		{"last without dimensions", []domain.Thumbnail{{URL: "https://x/a"}, {URL: "https://x/b"}, {}}, "https://x/b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bestThumbnailURL(tt.thumbnails); got != tt.want {
+				t.Errorf("bestThumbnailURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestArchivePlaylistThumbnail(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/webp")
+		w.Write([]byte("cover"))
+	}))
+	defer srv.Close()
+
+	s := NewService(&Config{JobRepository: testutil.NewMockJobRepository(), DownloadPath: t.TempDir()})
+	if _, ok := ArchivedThumbnail(s.config.DownloadPath, "pl1"); ok {
+		t.Error("expected no archived thumbnail before the download")
+	}
+
+	s.archivePlaylistThumbnail(context.Background(), "pl1", &domain.PlaylistMetadata{
+		Thumbnails: []domain.Thumbnail{{URL: srv.URL + "/cover", Width: 480, Height: 360}},
+	})
+	got, ok := ArchivedThumbnail(s.config.DownloadPath, "pl1")
+	if !ok || filepath.Base(got) != "pl1.webp" {
+		t.Fatalf("ArchivedThumbnail = %q, %v; want pl1.webp", got, ok)
+	}
+	if data, _ := os.ReadFile(got); string(data) != "cover" {
+		t.Errorf("archived content = %q", data)
+	}
+
+	s.removeCachedThumbnails("pl1")
+	if _, ok := ArchivedThumbnail(s.config.DownloadPath, "pl1"); ok {
+		t.Error("archived thumbnail survived removeCachedThumbnails")
+	}
+}
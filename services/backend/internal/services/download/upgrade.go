@@ -0,0 +1,424 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"video-archiver/internal/domain"
+	"video-archiver/internal/services/metadata"
+	"video-archiver/internal/services/tools"
+)
+
+const (
+	// upgradeProbeDelay spaces out the videos of an upgrade run so a large
+	// library does not hit the source in one burst.
+	upgradeProbeDelay = 2 * time.Second
+	// upgradeProbeTimeout and upgradeDownloadTimeout bound the work on one
+	// video.
+	upgradeProbeTimeout    = 2 * time.Minute
+	upgradeDownloadTimeout = 2 * time.Hour
+)
+
+// upgradeProbeTemplate prints the format yt-dlp would pick today. For merged
+// video+audio downloads the top-level fields describe the merged result.
+const upgradeProbeTemplate = "%(height)s\t%(vcodec)s\t%(filesize,filesize_approx)s"
+
+// upgradeCandidate is an archived video that may improve on re-download.
+type upgradeCandidate struct {
+	job  *domain.Job
+	meta *domain.VideoMetadata
+}
+
+// IsUpgradeRunning reports whether a quality upgrade run is in progress.
+func (s *Service) IsUpgradeRunning() bool {
+	return s.upgrading.Load()
+}
+
+// StartUpgrade starts a quality upgrade run in the background over jobIDs, or
+// over the whole library when jobIDs is empty, and returns the new run. Only
+// one run at a time is allowed. A dry run probes and reports without
+// downloading.
+func (s *Service) StartUpgrade(jobIDs []string, dryRun bool) (*domain.UpgradeRun, error) {
+	if !s.upgrading.CompareAndSwap(false, true) {
+		return nil, fmt.Errorf("an upgrade run is already in progress")
+	}
+
+	_, targetHeight := s.getSettings()
+	candidates, err := s.upgradeCandidates(jobIDs, targetHeight)
+	if err != nil {
+		s.upgrading.Store(false)
+		return nil, err
+	}
+
+	run := &domain.UpgradeRun{
+		Status:       domain.UpgradeRunRunning,
+		DryRun:       dryRun,
+		TargetHeight: targetHeight,
+		Candidates:   len(candidates),
+		StartedAt:    time.Now(),
+	}
+	if err := s.jobs.CreateUpgradeRun(run); err != nil {
+		s.upgrading.Store(false)
+		return nil, fmt.Errorf("create upgrade run: %w", err)
+	}
+
+	started := *run
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer s.upgrading.Store(false)
+		s.runUpgrade(run, candidates)
+	}()
+	return &started, nil
+}
+
+// upgradeCandidates returns the completed videos among jobIDs (or the whole
+// library) whose stored resolution or codec falls short of targetHeight and
+// the preferred codec. Playlists and channels in jobIDs stand for their
+// videos.
+func (s *Service) upgradeCandidates(jobIDs []string, targetHeight int) ([]upgradeCandidate, error) {
+	var items []*domain.JobWithMetadata
+	if len(jobIDs) == 0 {
+		all, err := s.jobs.GetAllJobsWithMetadata()
+		if err != nil {
+			return nil, fmt.Errorf("list jobs: %w", err)
+		}
+		items = all
+	} else {
+		for _, id := range jobIDs {
+			item, err := s.jobs.GetJobWithMetadata(id)
+			if err != nil || item == nil || item.Job == nil {
+				log.WithField("jobID", id).Warn("Skipping unknown job in upgrade request")
+				continue
+			}
+			switch item.Metadata.(type) {
+			case *domain.PlaylistMetadata, *domain.ChannelMetadata:
+				videos, err := s.jobs.GetVideosForParent(id)
+				if err != nil {
+					return nil, fmt.Errorf("list videos of %s: %w", id, err)
+				}
+				items = append(items, videos...)
+			default:
+				items = append(items, item)
+			}
+		}
+	}
+
+	seen := make(map[string]bool, len(items))
+	var candidates []upgradeCandidate
+	for _, item := range items {
+		if item == nil || item.Job == nil || seen[item.Job.ID] {
+			continue
+		}
+		seen[item.Job.ID] = true
+		meta, ok := item.Metadata.(*domain.VideoMetadata)
+		if ok && needsUpgrade(item.Job, meta, targetHeight) {
+			candidates = append(candidates, upgradeCandidate{job: item.Job, meta: meta})
+		}
+	}
+	return candidates, nil
+}
+
+// needsUpgrade reports whether an archived video is worth probing: a
+// completed video download stored below the target resolution or in a codec
+// other than the preferred one. Audio-only jobs and live recordings are
+// never upgraded.
+func needsUpgrade(job *domain.Job, meta *domain.VideoMetadata, targetHeight int) bool {
+	if job.Status != domain.JobStatusComplete || job.IsAudio() || job.Live || meta == nil {
+		return false
+	}
+	if meta.WasLive || meta.IsLiveOrUpcoming() {
+		return false
+	}
+	return meta.Height < targetHeight || !domain.IsPreferredVideoCodec(meta.VideoCodec)
+}
+
+// runUpgrade works through the candidates one at a time, recording an item
+// per video and keeping the run's totals current.
+func (s *Service) runUpgrade(run *domain.UpgradeRun, candidates []upgradeCandidate) {
+	logger := log.WithField("runID", run.ID)
+	logger.Infof("Starting quality upgrade run over %d candidate(s), target %dp", len(candidates), run.TargetHeight)
+
+	for i, candidate := range candidates {
+		if i > 0 {
+			select {
+			case <-s.ctx.Done():
+			case <-time.After(upgradeProbeDelay):
+			}
+		}
+		if s.ctx.Err() != nil {
+			break
+		}
+
+		item := s.upgradeVideo(run, candidate)
+		item.RunID = run.ID
+		if err := s.jobs.AddUpgradeItem(&item); err != nil {
+			logger.WithError(err).WithField("jobID", item.JobID).Warn("Failed to record upgrade item")
+		}
+
+		run.Checked++
+		switch item.Outcome {
+		case domain.UpgradeOutcomeUpgraded, domain.UpgradeOutcomeAvailable:
+			run.Upgraded++
+			run.BytesBefore += item.OldSize
+			run.BytesAfter += item.NewSize
+		case domain.UpgradeOutcomeFailed:
+			run.Failed++
+		}
+		if err := s.jobs.UpdateUpgradeRun(run); err != nil {
+			logger.WithError(err).Warn("Failed to update upgrade run")
+		}
+		s.broadcastUpgrade(run)
+	}
+
+	run.Status = domain.UpgradeRunComplete
+	if s.ctx.Err() != nil {
+		run.Status = domain.UpgradeRunStopped
+	}
+	finished := time.Now()
+	run.FinishedAt = &finished
+	if err := s.jobs.UpdateUpgradeRun(run); err != nil {
+		logger.WithError(err).Warn("Failed to finish upgrade run")
+	}
+	s.broadcastUpgrade(run)
+
+	logger.Infof("Quality upgrade run %s: %d checked, %d upgraded, %d failed, %+d bytes",
+		run.Status, run.Checked, run.Upgraded, run.Failed, run.BytesAfter-run.BytesBefore)
+}
+
+func (s *Service) broadcastUpgrade(run *domain.UpgradeRun) {
+	s.hub.Broadcast(domain.UpgradeUpdate{
+		Type:       "upgrade",
+		RunID:      run.ID,
+		Status:     run.Status,
+		Candidates: run.Candidates,
+		Checked:    run.Checked,
+		Upgraded:   run.Upgraded,
+		Failed:     run.Failed,
+	})
+}
+
+// upgradeVideo probes one video and, unless the run is a dry run, re-downloads
+// it when the format available now is an improvement.
+func (s *Service) upgradeVideo(run *domain.UpgradeRun, candidate upgradeCandidate) domain.UpgradeItem {
+	job, meta := candidate.job, candidate.meta
+	item := domain.UpgradeItem{
+		JobID:     job.ID,
+		Title:     meta.Title,
+		OldHeight: meta.Height,
+		OldCodec:  meta.VideoCodec,
+	}
+	fail := func(err error) domain.UpgradeItem {
+		log.WithError(err).WithField("jobID", job.ID).Warn("Quality upgrade failed")
+		item.Outcome = domain.UpgradeOutcomeFailed
+		item.Error = err.Error()
+		return item
+	}
+
+	oldPath, err := tools.ResolveVideoFileWithHint(s.config.DownloadPath, job.FilePath, meta)
+	if err != nil {
+		return fail(fmt.Errorf("archived file not found"))
+	}
+	if info, err := os.Stat(oldPath); err == nil {
+		item.OldSize = info.Size()
+	}
+
+	formatArgs := downloadFormatArgs(*job, run.TargetHeight)
+	probeCtx, cancel := context.WithTimeout(s.ctx, upgradeProbeTimeout)
+	height, vcodec, size, err := probeFormat(probeCtx, job.URL, formatArgs)
+	cancel()
+	if err != nil {
+		return fail(err)
+	}
+	item.NewHeight, item.NewCodec, item.NewSize = height, vcodec, size
+
+	if !domain.IsQualityImprovement(meta.Height, meta.VideoCodec, height, vcodec) {
+		item.Outcome = domain.UpgradeOutcomeNoImprovement
+		item.NewSize = 0
+		return item
+	}
+	if run.DryRun {
+		item.Outcome = domain.UpgradeOutcomeAvailable
+		return item
+	}
+
+	downloadCtx, cancel := context.WithTimeout(s.ctx, upgradeDownloadTimeout)
+	newPath, err := s.redownloadForUpgrade(downloadCtx, job, formatArgs, oldPath)
+	cancel()
+	if err != nil {
+		return fail(err)
+	}
+	if info, err := os.Stat(newPath); err == nil {
+		item.NewSize = info.Size()
+	}
+
+	if err := s.jobs.SetFilePath(job.ID, newPath); err != nil {
+		log.WithError(err).WithField("jobID", job.ID).Warn("Failed to store upgraded file path")
+	}
+	if updated := s.storeUpgradedFormat(job.ID, meta, infoJSONFor(newPath), item.NewSize); updated != nil {
+		item.NewHeight, item.NewCodec = updated.Height, updated.VideoCodec
+	}
+
+	item.Outcome = domain.UpgradeOutcomeUpgraded
+	log.WithFields(log.Fields{
+		"jobID": job.ID,
+		"from":  fmt.Sprintf("%dp %s", item.OldHeight, item.OldCodec),
+		"to":    fmt.Sprintf("%dp %s", item.NewHeight, item.NewCodec),
+	}).Info("Upgraded archived video")
+	return item
+}
+
+// probeFormat asks yt-dlp which format it would download now, without
+// downloading it.
+func probeFormat(ctx context.Context, url string, formatArgs []string) (int, string, int64, error) {
+	var stdout, stderr bytes.Buffer
+	args := []string{"--skip-download", "--no-playlist", "--no-warnings"}
+	args = append(args, formatArgs...)
+	args = append(args, "--print", upgradeProbeTemplate, url)
+	cmd := exec.CommandContext(ctx, "yt-dlp", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := lastErrorLine(stderr.String()); msg != "" {
+			return 0, "", 0, fmt.Errorf("probe failed: %s", msg)
+		}
+		return 0, "", 0, fmt.Errorf("probe failed: %w", err)
+	}
+	return parseFormatProbe(stdout.String())
+}
+
+// parseFormatProbe parses the output of upgradeProbeTemplate. yt-dlp prints
+// "NA" for unknown fields; an unknown size is reported as 0, an unknown
+// height is an error since nothing can be compared without it.
+func parseFormatProbe(output string) (int, string, int64, error) {
+	line := strings.TrimSpace(output)
+	if i := strings.LastIndex(line, "\n"); i >= 0 {
+		line = strings.TrimSpace(line[i+1:])
+	}
+	fields := strings.Split(line, "\t")
+	if len(fields) != 3 {
+		return 0, "", 0, fmt.Errorf("unexpected probe output %q", line)
+	}
+
+	height, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return 0, "", 0, fmt.Errorf("probe reported no resolution")
+	}
+	vcodec := fields[1]
+	if vcodec == "NA" {
+		vcodec = ""
+	}
+	size, err := strconv.ParseFloat(fields[2], 64)
+	if err != nil {
+		size = 0
+	}
+	return height, vcodec, int64(size), nil
+}
+
+// redownloadForUpgrade downloads job again into a scratch directory inside
+// the download path and moves the result over oldPath, returning where the
+// upgraded file ended up.
+func (s *Service) redownloadForUpgrade(ctx context.Context, job *domain.Job, formatArgs []string, oldPath string) (string, error) {
+	// Inside the download path so the final rename stays on one filesystem.
+	scratch, err := os.MkdirTemp(s.config.DownloadPath, ".upgrade-")
+	if err != nil {
+		return "", fmt.Errorf("create scratch directory: %w", err)
+	}
+	defer os.RemoveAll(scratch)
+
+	concurrency, _ := s.getSettings()
+	printFile := filepath.Join(scratch, "filepath.txt")
+	args := []string{
+		"-N", fmt.Sprintf("%d", concurrency),
+		"--no-playlist",
+		"--no-progress",
+		"--retries", "3",
+		"--fragment-retries", "5",
+		"--add-metadata",
+		"--write-info-json",
+		"--output", filepath.Join(scratch, "%(id)s.%(ext)s"),
+	}
+	args = append(args, formatArgs...)
+	args = append(args, "--print-to-file", "after_move:%(filepath)s", printFile, job.URL)
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "yt-dlp", args...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := lastErrorLine(stderr.String()); msg != "" {
+			return "", fmt.Errorf("download failed: %s", msg)
+		}
+		return "", fmt.Errorf("download failed: %w", err)
+	}
+
+	newPath := readPrintFile(printFile)
+	if newPath == "" || !validMediaPath(scratch, newPath) {
+		return "", fmt.Errorf("download finished without a media file")
+	}
+	return replaceMediaFile(oldPath, newPath)
+}
+
+// replaceMediaFile moves newPath over oldPath, keeping the old name but the
+// new extension. The rename is atomic, so readers see either the old file or
+// the new one. The old file is removed when the extension changed, and the
+// new .info.json replaces the old one; other sidecars such as the thumbnail
+// are kept.
+func replaceMediaFile(oldPath, newPath string) (string, error) {
+	dest := strings.TrimSuffix(oldPath, filepath.Ext(oldPath)) + filepath.Ext(newPath)
+	if err := os.Rename(newPath, dest); err != nil {
+		return "", fmt.Errorf("replace archived file: %w", err)
+	}
+	if dest != oldPath {
+		if err := os.Remove(oldPath); err != nil && !os.IsNotExist(err) {
+			log.WithError(err).WithField("path", oldPath).Warn("Failed to remove replaced video file")
+		}
+	}
+	if err := os.Rename(infoJSONFor(newPath), infoJSONFor(dest)); err != nil && !os.IsNotExist(err) {
+		log.WithError(err).WithField("path", dest).Warn("Failed to replace info.json of upgraded video")
+	}
+	return dest, nil
+}
+
+// storeUpgradedFormat copies the format fields of the upgraded download's
+// info.json into the stored metadata, leaving everything else (title,
+// engagement counts, ...) as it was. It returns the stored metadata, or nil
+// when the info.json could not be read.
+func (s *Service) storeUpgradedFormat(jobID string, meta *domain.VideoMetadata, infoJSONPath string, size int64) *domain.VideoMetadata {
+	extracted, err := metadata.ExtractMetadata(infoJSONPath)
+	if err != nil {
+		log.WithError(err).WithField("jobID", jobID).Warn("Failed to read info.json of upgraded video")
+		return nil
+	}
+	downloaded, ok := extracted.(*domain.VideoMetadata)
+	if !ok {
+		return nil
+	}
+
+	updated := *meta
+	updated.Width = downloaded.Width
+	updated.Height = downloaded.Height
+	updated.Resolution = downloaded.Resolution
+	updated.FPS = downloaded.FPS
+	updated.DynamicRange = downloaded.DynamicRange
+	updated.VideoCodec = downloaded.VideoCodec
+	updated.AudioCodec = downloaded.AudioCodec
+	updated.AudioChannels = downloaded.AudioChannels
+	updated.Format = downloaded.Format
+	updated.Extension = downloaded.Extension
+	if size > 0 {
+		updated.FileSize = size
+	}
+	if err := s.jobs.StoreMetadata(jobID, &updated); err != nil {
+		log.WithError(err).WithField("jobID", jobID).Warn("Failed to store upgraded video metadata")
+	}
+	return &updated
+}
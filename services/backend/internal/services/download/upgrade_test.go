@@ -0,0 +1,93 @@
+package download
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"video-archiver/internal/domain"
+)
+
+func TestNeedsUpgrade(t *testing.T) {
+	complete := &domain.Job{Status: domain.JobStatusComplete, MediaType: domain.MediaTypeVideo}
+	tests := []struct {
+		name string
+		job  *domain.Job
+		meta *domain.VideoMetadata
+		want bool
+	}{
+		{"below target", complete, &domain.VideoMetadata{Height: 720, VideoCodec: "avc1.64001F"}, true},
+		{"at target, preferred codec", complete, &domain.VideoMetadata{Height: 1080, VideoCodec: "avc1.640028"}, false},
+		{"at target, other codec", complete, &domain.VideoMetadata{Height: 1080, VideoCodec: "vp09.00.40.08"}, true},
+		{"audio job", &domain.Job{Status: domain.JobStatusComplete, MediaType: domain.MediaTypeAudio}, &domain.VideoMetadata{Height: 360}, false},
+		{"not complete", &domain.Job{Status: domain.JobStatusError}, &domain.VideoMetadata{Height: 360}, false},
+		{"live recording", complete, &domain.VideoMetadata{Height: 360, WasLive: true}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := needsUpgrade(tt.job, tt.meta, 1080); got != tt.want {
+				t.Errorf("needsUpgrade() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFormatProbe(t *testing.T) {
+	height, vcodec, size, err := parseFormatProbe("1080\tavc1.640028\t123456789.5\n")
+	if err != nil || height != 1080 || vcodec != "avc1.640028" || size != 123456789 {
+		t.Errorf("parseFormatProbe() = %d, %q, %d, %v", height, vcodec, size, err)
+	}
+
+	height, vcodec, size, err = parseFormatProbe("720\tNA\tNA")
+	if err != nil || height != 720 || vcodec != "" || size != 0 {
+		t.Errorf("parseFormatProbe(unknowns) = %d, %q, %d, %v", height, vcodec, size, err)
+	}
+
+	for _, output := range []string{"", "NA\tavc1\t100", "garbage"} {
+		if _, _, _, err := parseFormatProbe(output); err == nil {
+			t.Errorf("parseFormatProbe(%q) succeeded, want error", output)
+		}
+	}
+}
+
+func TestReplaceMediaFile(t *testing.T) {
+	dir := t.TempDir()
+	scratch := filepath.Join(dir, ".upgrade-1")
+	if err := os.Mkdir(scratch, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	oldPath := filepath.Join(dir, "Video.webm")
+	write(oldPath, "old")
+	write(filepath.Join(dir, "Video.info.json"), "old info")
+	write(filepath.Join(dir, "Video.jpg"), "thumbnail")
+	newPath := filepath.Join(scratch, "abc.mp4")
+	write(newPath, "new")
+	write(filepath.Join(scratch, "abc.info.json"), "new info")
+
+	dest, err := replaceMediaFile(oldPath, newPath)
+	if err != nil {
+		t.Fatalf("replaceMediaFile() error = %v", err)
+	}
+	if want := filepath.Join(dir, "Video.mp4"); dest != want {
+		t.Errorf("dest = %q, want %q", dest, want)
+	}
+	if data, _ := os.ReadFile(dest); string(data) != "new" {
+		t.Errorf("upgraded file holds %q", data)
+	}
+	if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+		t.Errorf("old file with different extension still exists")
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "Video.info.json")); string(data) != "new info" {
+		t.Errorf("info.json holds %q, want the new one", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "Video.jpg")); err != nil {
+		t.Errorf("thumbnail was removed: %v", err)
+	}
+}
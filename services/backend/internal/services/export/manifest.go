@@ -0,0 +1,72 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"strings"
+
+	"video-archiver/internal/domain"
+)
+
+// indexTemplate is the archive's index.html: a page listing the videos that
+// links to the files next to it, for recipients without a player that reads
+// playlists.
+var indexTemplate = template.Must(template.New("index").Funcs(template.FuncMap{
+	"duration": formatDuration,
+	"stars":    formatStars,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; max-width: 60rem; margin: 2rem auto; padding: 0 1rem; }
+li { display: flex; gap: 1rem; align-items: center; margin-bottom: 1rem; }
+img { width: 160px; aspect-ratio: 16 / 9; object-fit: cover; }
+small { color: #666; }
+.notes { white-space: pre-wrap; margin: 0.25rem 0 0; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p><small>{{len .Items}} video(s), exported {{.ExportedAt.Format "2006-01-02 15:04"}}</small></p>
+<ol>
+{{- range .Items}}
+<li>
+{{- if .Thumbnail}}<img src="{{.Thumbnail}}" alt="">{{end}}
+<div><a href="{{.URL}}">{{.Title}}</a>{{if .Favorite}} ♥{{end}}{{if .Rating}} <span title="{{.Rating}} of 5">{{stars .Rating}}</span>{{end}}<br><small>{{.Channel}}{{if .Duration}} · {{duration .Duration}}{{end}} · <a href="{{.SourceURL}}">source</a></small>
+{{- if .Notes}}<p class="notes">{{.Notes}}</p>{{end}}</div>
+</li>
+{{- end}}
+</ol>
+</body>
+</html>
+`))
+
+// renderManifest returns the manifest.json and index.html of an archive, in
+// which URL and Thumbnail are paths inside the archive.
+func renderManifest(manifest domain.PlaylistExport) ([]byte, []byte, error) {
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshal manifest: %w", err)
+	}
+	var index bytes.Buffer
+	if err := indexTemplate.Execute(&index, manifest); err != nil {
+		return nil, nil, fmt.Errorf("render index: %w", err)
+	}
+	return manifestJSON, index.Bytes(), nil
+}
+
+func formatDuration(seconds int) string {
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	}
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+// formatStars renders a 1-5 rating as filled and empty stars.
+func formatStars(rating int) string {
+	return strings.Repeat("★", rating) + strings.Repeat("☆", 5-rating)
+}
@@ -0,0 +1,121 @@
+package export
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"video-archiver/internal/domain"
+)
+
+// DefaultNaming names archive entries after their position and title, which
+// keeps the archive in collection order when sorted by name.
+const DefaultNaming = "{index} - {title}"
+
+var (
+	namingPlaceholderPattern = regexp.MustCompile(`\{([a-z_]+)\}`)
+	namingFields             = map[string]func(n *namingValues) string{
+		"index":    func(n *namingValues) string { return n.index },
+		"title":    func(n *namingValues) string { return n.meta.Title },
+		"channel":  func(n *namingValues) string { return firstNonEmpty(n.meta.Channel, n.meta.Uploader) },
+		"id":       func(n *namingValues) string { return n.jobID },
+		"video_id": func(n *namingValues) string { return n.meta.ID },
+		"date":     func(n *namingValues) string { return formatUploadDate(n.meta.UploadDate) },
+	}
+	unsafeNameReplacer = strings.NewReplacer(
+		"/", "_", "\\", "_", ":", "_", "*", "_", "?", "_",
+		"\"", "_", "<", "_", ">", "_", "|", "_",
+	)
+)
+
+// Naming is a validated file name template for archive entries, for example
+// "{channel}/{date} {title}". Placeholders are {index} (1-based position,
+// zero-padded), {title}, {channel}, {id} (job ID), {video_id} (source ID)
+// and {date} (upload date, YYYY-MM-DD); a "/" places files in folders.
+type Naming struct {
+	template string
+}
+
+type namingValues struct {
+	index string
+	jobID string
+	meta  *domain.VideoMetadata
+}
+
+// ParseNaming validates a template; an empty one is DefaultNaming.
+func ParseNaming(template string) (Naming, error) {
+	template = strings.TrimSpace(template)
+	if template == "" {
+		template = DefaultNaming
+	}
+	for _, m := range namingPlaceholderPattern.FindAllStringSubmatch(template, -1) {
+		if _, ok := namingFields[m[1]]; !ok {
+			return Naming{}, fmt.Errorf("unknown placeholder {%s}", m[1])
+		}
+	}
+	if strings.ContainsAny(namingPlaceholderPattern.ReplaceAllString(template, ""), "{}") {
+		return Naming{}, fmt.Errorf("unbalanced braces in %q", template)
+	}
+	return Naming{template: template}, nil
+}
+
+func (n Naming) String() string {
+	return n.template
+}
+
+// render returns the entry name, without extension, of the video at the
+// 1-based index in an archive of total videos. Placeholder values can't
+// introduce folders or escape the archive.
+func (n Naming) render(index, total int, jobID string, meta *domain.VideoMetadata) string {
+	values := &namingValues{
+		index: fmt.Sprintf("%0*d", len(strconv.Itoa(total)), index),
+		jobID: jobID,
+		meta:  meta,
+	}
+	rendered := namingPlaceholderPattern.ReplaceAllStringFunc(n.template, func(placeholder string) string {
+		return safeName(namingFields[placeholder[1:len(placeholder)-1]](values))
+	})
+
+	var segments []string
+	for _, segment := range strings.Split(rendered, "/") {
+		segment = strings.Trim(strings.Join(strings.Fields(segment), " "), " .")
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	if len(segments) == 0 {
+		return safeName(jobID)
+	}
+	return path.Join(segments...)
+}
+
+// safeName makes a value usable as (part of) a file name on common file
+// systems.
+func safeName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return ' '
+		}
+		return r
+	}, s)
+	return unsafeNameReplacer.Replace(s)
+}
+
+// formatUploadDate turns yt-dlp's YYYYMMDD into YYYY-MM-DD.
+func formatUploadDate(date string) string {
+	if len(date) != 8 {
+		return date
+	}
+	return date[:4] + "-" + date[4:6] + "-" + date[6:]
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
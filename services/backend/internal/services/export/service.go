@@ -0,0 +1,431 @@
+package export
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"video-archiver/internal/domain"
+	"video-archiver/internal/services/download"
+	"video-archiver/internal/services/tools"
+)
+
+// defaultCacheTTL is how long a finished archive stays cached for resumed
+// downloads.
+const defaultCacheTTL = 24 * time.Hour
+
+// defaultMaxCacheSize caps the cached archives when Config.MaxCacheSize is
+// unset.
+const defaultMaxCacheSize = 10 << 30
+
+// subtitleExtensions lists the subtitle sidecars yt-dlp writes next to a
+// media file as <stem>.<lang>.<ext>.
+var subtitleExtensions = map[string]bool{"vtt": true, "srt": true, "ass": true, "ssa": true, "ttml": true, "lrc": true}
+
+type Config struct {
+	DownloadPath string
+	// CachePath holds finished archives so interrupted downloads can resume
+	// with range requests.
+	CachePath string
+	// CacheTTL defaults to a day.
+	CacheTTL time.Duration
+	// MaxCacheSize caps the cached archives in bytes; the least recently used
+	// ones are deleted beyond it. It defaults to 10 GiB.
+	MaxCacheSize int64
+}
+
+// Service builds zip archives of a set of downloaded videos with their
+// sidecar files and a manifest. Archives are streamed as they are built,
+// without a temporary file, while a copy is written to the cache; later
+// requests for the same content are served from that copy.
+type Service struct {
+	downloadPath string
+	cachePath    string
+	cacheTTL     time.Duration
+	maxCacheSize int64
+
+	mu sync.Mutex
+	// building holds the keys of archives being written to the cache.
+	building map[string]bool
+}
+
+func NewService(config *Config) *Service {
+	ttl := config.CacheTTL
+	if ttl <= 0 {
+		ttl = defaultCacheTTL
+	}
+	maxSize := config.MaxCacheSize
+	if maxSize <= 0 {
+		maxSize = defaultMaxCacheSize
+	}
+	return &Service{
+		downloadPath: config.DownloadPath,
+		cachePath:    config.CachePath,
+		cacheTTL:     ttl,
+		maxCacheSize: maxSize,
+		building:     make(map[string]bool),
+	}
+}
+
+// Source is the collection, playlist or channel an archive is built from.
+type Source struct {
+	// Kind is "collection", "playlist" or "channel".
+	Kind   string
+	ID     string
+	Title  string
+	Videos []*domain.JobWithMetadata
+}
+
+// Entry is one file in an archive: a file on disk, or generated Data.
+type Entry struct {
+	Name    string
+	Path    string
+	Data    []byte
+	Size    int64
+	ModTime time.Time
+}
+
+// Archive is the planned content of a zip.
+type Archive struct {
+	// Key identifies the content: the same files, at the same sizes and
+	// modification times, under the same names.
+	Key     string
+	Entries []Entry
+}
+
+// Plan lists the files of the archive of source: each downloaded video's
+// media file, .info.json, thumbnail and subtitles, named by naming, followed
+// by a manifest.json and an index.html. Videos whose media file can't be
+// found are left out.
+func (s *Service) Plan(source Source, naming Naming) (*Archive, error) {
+	var videos []*domain.JobWithMetadata
+	for _, video := range source.Videos {
+		if _, ok := video.Metadata.(*domain.VideoMetadata); ok && video.Job.Status == domain.JobStatusComplete {
+			videos = append(videos, video)
+		}
+	}
+
+	archive := &Archive{}
+	manifest := domain.PlaylistExport{
+		Kind:       source.Kind,
+		ID:         source.ID,
+		Title:      source.Title,
+		ExportedAt: time.Now(),
+		Items:      []domain.PlaylistExportItem{},
+	}
+	used := map[string]bool{"manifest.json": true, "index.html": true}
+	for i, video := range videos {
+		metadata := video.Metadata.(*domain.VideoMetadata)
+		mediaPath, err := tools.ResolveVideoFileWithHint(s.downloadPath, video.Job.FilePath, metadata)
+		if err != nil {
+			log.WithField("jobID", video.Job.ID).Debug("Leaving video without a media file out of the archive")
+			continue
+		}
+
+		base := uniqueName(used, naming.render(i+1, len(videos), video.Job.ID, metadata))
+		item := domain.PlaylistExportItem{
+			JobID:     video.Job.ID,
+			Title:     metadata.Title,
+			Channel:   firstNonEmpty(metadata.Channel, metadata.Uploader),
+			Duration:  metadata.Duration,
+			SourceURL: video.Job.URL,
+		}
+		item.Annotate(video.Annotation)
+		for _, sidecar := range sidecars(mediaPath) {
+			entry, err := fileEntry(base+sidecar.suffix, sidecar.path)
+			if err != nil {
+				if sidecar.media {
+					return nil, err
+				}
+				continue
+			}
+			archive.Entries = append(archive.Entries, entry)
+			switch {
+			case sidecar.media:
+				item.URL = entry.Name
+			case sidecar.thumbnail:
+				item.Thumbnail = entry.Name
+			}
+		}
+		manifest.Items = append(manifest.Items, item)
+	}
+
+	archive.Key = archiveKey(source, naming, archive.Entries)
+
+	manifestJSON, index, err := renderManifest(manifest)
+	if err != nil {
+		return nil, err
+	}
+	archive.Entries = append(archive.Entries,
+		Entry{Name: "manifest.json", Data: manifestJSON, Size: int64(len(manifestJSON)), ModTime: manifest.ExportedAt},
+		Entry{Name: "index.html", Data: index, Size: int64(len(index)), ModTime: manifest.ExportedAt},
+	)
+	return archive, nil
+}
+
+type sidecar struct {
+	path      string
+	suffix    string
+	media     bool
+	thumbnail bool
+}
+
+// sidecars returns the media file and the files yt-dlp wrote next to it,
+// with the suffix each keeps in the archive.
+func sidecars(mediaPath string) []sidecar {
+	ext := filepath.Ext(mediaPath)
+	stem := strings.TrimSuffix(filepath.Base(mediaPath), ext)
+	files := []sidecar{{path: mediaPath, suffix: ext, media: true}}
+
+	info := strings.TrimSuffix(mediaPath, ext) + ".info.json"
+	if stat, err := os.Stat(info); err == nil && stat.Mode().IsRegular() {
+		files = append(files, sidecar{path: info, suffix: ".info.json"})
+	}
+	if thumbnail, ok := download.LocalThumbnail(mediaPath); ok {
+		files = append(files, sidecar{path: thumbnail, suffix: filepath.Ext(thumbnail), thumbnail: true})
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(mediaPath))
+	if err != nil {
+		return files
+	}
+	for _, entry := range entries {
+		// Subtitles are <stem>.<lang>.<ext>; the language has no dots, which
+		// keeps another video named <stem>.<something> out.
+		suffix, ok := strings.CutPrefix(entry.Name(), stem+".")
+		if !ok || !entry.Type().IsRegular() {
+			continue
+		}
+		lang, subtitleExt, ok := strings.Cut(suffix, ".")
+		if ok && lang != "" && subtitleExtensions[strings.ToLower(subtitleExt)] {
+			files = append(files, sidecar{
+				path:   filepath.Join(filepath.Dir(mediaPath), entry.Name()),
+				suffix: "." + suffix,
+			})
+		}
+	}
+	return files
+}
+
+func fileEntry(name, path string) (Entry, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Entry{}, fmt.Errorf("stat %s: %w", path, err)
+	}
+	return Entry{Name: name, Path: path, Size: info.Size(), ModTime: info.ModTime()}, nil
+}
+
+// uniqueName claims name, numbering it when another entry already has it.
+// Names compare case-insensitively for case-insensitive file systems.
+func uniqueName(used map[string]bool, name string) string {
+	candidate := name
+	for n := 2; used[strings.ToLower(candidate)]; n++ {
+		candidate = fmt.Sprintf("%s (%d)", name, n)
+	}
+	used[strings.ToLower(candidate)] = true
+	return candidate
+}
+
+func archiveKey(source Source, naming Naming, entries []Entry) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%s\n", source.Kind, source.ID, source.Title, naming)
+	for _, e := range entries {
+		fmt.Fprintf(h, "%s\x00%s\x00%d\x00%d\n", e.Name, e.Path, e.Size, e.ModTime.UnixNano())
+	}
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
+
+func (s *Service) cacheFile(key string) string {
+	return filepath.Join(s.cachePath, key+".zip")
+}
+
+// Cached opens the cached copy of an archive, if there is a finished one,
+// marking it used.
+func (s *Service) Cached(archive *Archive) (*os.File, os.FileInfo, bool) {
+	path := s.cacheFile(archive.Key)
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, false
+	}
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, false
+	}
+	return f, info, true
+}
+
+// Write streams the archive to w. Unless another request is already caching
+// the same archive, a copy goes to the cache; if w fails, for example
+// because the client went away, the copy is still finished so the download
+// can resume from it.
+func (s *Service) Write(w io.Writer, archive *Archive) error {
+	out := &teeWriter{client: w}
+	if cache, finish := s.startCache(archive.Key); cache != nil {
+		out.cache = cache
+		defer func() { finish(out.cache != nil && out.err == nil) }()
+	}
+
+	zw := zip.NewWriter(out)
+	for _, entry := range archive.Entries {
+		if out.err = writeEntry(zw, entry); out.err != nil {
+			break
+		}
+	}
+	if out.err == nil {
+		out.err = zw.Close()
+	}
+	if out.clientErr != nil {
+		return out.clientErr
+	}
+	return out.err
+}
+
+func writeEntry(zw *zip.Writer, entry Entry) error {
+	// Media is already compressed; storing keeps streaming cheap.
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: entry.Name, Method: zip.Store, Modified: entry.ModTime})
+	if err != nil {
+		return err
+	}
+	if entry.Path == "" {
+		_, err = w.Write(entry.Data)
+		return err
+	}
+	f, err := os.Open(entry.Path)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", entry.Path, err)
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// startCache opens a partial cache file for the archive, returning nil when
+// the archive is already being cached or the cache is unavailable. finish
+// publishes the file when complete and removes it otherwise.
+func (s *Service) startCache(key string) (*os.File, func(complete bool)) {
+	if s.cachePath == "" {
+		return nil, nil
+	}
+	s.mu.Lock()
+	if s.building[key] {
+		s.mu.Unlock()
+		return nil, nil
+	}
+	s.building[key] = true
+	s.mu.Unlock()
+
+	done := func() {
+		s.mu.Lock()
+		delete(s.building, key)
+		s.mu.Unlock()
+	}
+
+	if err := os.MkdirAll(s.cachePath, 0755); err != nil {
+		log.WithError(err).Warn("Failed to create export cache directory")
+		done()
+		return nil, nil
+	}
+	s.pruneCache()
+
+	partial := s.cacheFile(key) + ".partial"
+	f, err := os.Create(partial)
+	if err != nil {
+		log.WithError(err).Warn("Failed to create cached archive")
+		done()
+		return nil, nil
+	}
+	return f, func(complete bool) {
+		defer done()
+		if err := f.Close(); err != nil {
+			complete = false
+		}
+		if complete {
+			if err := os.Rename(partial, s.cacheFile(key)); err == nil {
+				s.pruneCache()
+				return
+			}
+		}
+		os.Remove(partial)
+	}
+}
+
+// pruneCache removes cached archives older than the cache TTL, then the
+// least recently used ones until the rest fit in the size cap. The most
+// recent archive stays even when it alone exceeds the cap. Files being
+// served remain readable after removal.
+func (s *Service) pruneCache() {
+	entries, err := os.ReadDir(s.cachePath)
+	if err != nil {
+		return
+	}
+	cutoff := time.Now().Add(-s.cacheTTL)
+	var kept []os.FileInfo
+	var size int64
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".zip") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if info.ModTime().Before(cutoff) {
+			os.Remove(filepath.Join(s.cachePath, entry.Name()))
+			continue
+		}
+		kept = append(kept, info)
+		size += info.Size()
+	}
+
+	sort.Slice(kept, func(i, j int) bool { return kept[i].ModTime().Before(kept[j].ModTime()) })
+	for len(kept) > 1 && size > s.maxCacheSize {
+		if err := os.Remove(filepath.Join(s.cachePath, kept[0].Name())); err != nil && !os.IsNotExist(err) {
+			log.WithError(err).WithField("archive", kept[0].Name()).Warn("Failed to evict cached archive")
+		}
+		size -= kept[0].Size()
+		kept = kept[1:]
+	}
+}
+
+// teeWriter writes to the client and the cache. A failing client doesn't
+// stop the cache copy, and a failing cache copy doesn't stop the client;
+// writing fails once neither is left.
+type teeWriter struct {
+	client    io.Writer
+	clientErr error
+	cache     *os.File
+	// err is the error that ended writing the archive, if any.
+	err error
+}
+
+func (t *teeWriter) Write(p []byte) (int, error) {
+	if t.cache != nil {
+		if _, err := t.cache.Write(p); err != nil {
+			log.WithError(err).Warn("Failed to write cached archive")
+			t.cache.Close()
+			os.Remove(t.cache.Name())
+			t.cache = nil
+		}
+	}
+	if t.clientErr == nil {
+		if _, err := t.client.Write(p); err != nil {
+			t.clientErr = err
+		}
+	}
+	if t.clientErr != nil && t.cache == nil {
+		return 0, t.clientErr
+	}
+	return len(p), nil
+}
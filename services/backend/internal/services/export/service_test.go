@@ -0,0 +1,110 @@
+package export
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"video-archiver/internal/domain"
+	"video-archiver/internal/testutil"
+)
+
+func TestNaming(t *testing.T) {
+	meta := testutil.CreateTestVideoMetadata()
+	meta.Title = "What/is: this?"
+
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{"", "07 - What_is_ this_"},
+		{"{channel}/{date} {title}", "Test Channel/2024-01-01 What_is_ this_"},
+		{"../{id}", "job-1"},
+		{" {video_id} ", meta.ID},
+	}
+	for _, tt := range tests {
+		naming, err := ParseNaming(tt.template)
+		if err != nil {
+			t.Errorf("ParseNaming(%q) error = %v", tt.template, err)
+			continue
+		}
+		if got := naming.render(7, 12, "job-1", meta); got != tt.want {
+			t.Errorf("render(%q) = %q, want %q", tt.template, got, tt.want)
+		}
+	}
+
+	for _, template := range []string{"{nope}", "{title", "title}"} {
+		if _, err := ParseNaming(template); err == nil {
+			t.Errorf("ParseNaming(%q) error = nil, want an error", template)
+		}
+	}
+}
+
+func TestUniqueName(t *testing.T) {
+	used := map[string]bool{}
+	for _, tt := range []struct{ name, want string }{{"a", "a"}, {"A", "A (2)"}, {"a", "a (3)"}} {
+		if got := uniqueName(used, tt.name); got != tt.want {
+			t.Errorf("uniqueName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("client went away") }
+
+func TestWriteFinishesCacheAfterClientFails(t *testing.T) {
+	dir := t.TempDir()
+	media := filepath.Join(dir, "v.mp4")
+	os.WriteFile(media, make([]byte, 1<<16), 0644)
+
+	s := NewService(&Config{DownloadPath: dir, CachePath: filepath.Join(dir, "cache")})
+	job := testutil.CreateTestJob("v", "https://example.com/v")
+	job.Status, job.FilePath = domain.JobStatusComplete, media
+	archive, err := s.Plan(Source{Kind: "collection", ID: "c", Title: "C", Videos: []*domain.JobWithMetadata{
+		{Job: job, Metadata: testutil.CreateTestVideoMetadata()},
+	}}, Naming{template: DefaultNaming})
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+
+	if err := s.Write(failingWriter{}, archive); err == nil {
+		t.Error("Write() error = nil, want the client's error")
+	}
+	f, info, ok := s.Cached(archive)
+	if !ok {
+		t.Fatal("archive not cached after the client went away")
+	}
+	f.Close()
+	if info.Size() <= 1<<16 {
+		t.Errorf("cached archive is %d bytes, want the whole archive", info.Size())
+	}
+}
+
+func TestPruneCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	dir := t.TempDir()
+	s := NewService(&Config{DownloadPath: dir, CachePath: dir, MaxCacheSize: 250})
+
+	start := time.Now().Add(-time.Hour)
+	for i, key := range []string{"a", "b", "c"} {
+		path := s.cacheFile(key)
+		os.WriteFile(path, make([]byte, 100), 0644)
+		at := start.Add(time.Duration(i) * time.Minute)
+		os.Chtimes(path, at, at)
+	}
+	// Serving a marks it used, so b is now the least recently used.
+	f, _, ok := s.Cached(&Archive{Key: "a"})
+	if !ok {
+		t.Fatal("archive a not cached")
+	}
+	f.Close()
+
+	s.pruneCache()
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, err := os.Stat(s.cacheFile(key)); (err == nil) != want {
+			t.Errorf("archive %s cached = %v, want %v", key, err == nil, want)
+		}
+	}
+}
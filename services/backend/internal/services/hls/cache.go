@@ -0,0 +1,200 @@
+package hls
+
+import (
+	"container/list"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// segmentCache tracks the cached segment files by last use and deletes the
+// least recently used ones once together they exceed maxSize bytes.
+//
+// Emptied directories stay until the next start: a transcode creates its
+// directory before ffmpeg writes to it, so removing them here would race.
+type segmentCache struct {
+	root    string
+	maxSize int64
+	// onEvict is called, without the lock held, with the stream directory
+	// name whose last segment was evicted.
+	onEvict func(stream string)
+
+	mu   sync.Mutex
+	size int64
+	// order holds *cacheEntry values, most recently used first.
+	order   *list.List
+	entries map[string]*list.Element
+	// streams counts the cached segments per stream directory.
+	streams map[string]int
+}
+
+type cacheEntry struct {
+	path string
+	size int64
+}
+
+// newSegmentCache indexes the segments left in root by an earlier run,
+// treating their modification time as their last use, and removes the
+// directories it left empty.
+func newSegmentCache(root string, maxSize int64, onEvict func(stream string)) *segmentCache {
+	c := &segmentCache{
+		root:    root,
+		maxSize: maxSize,
+		onEvict: onEvict,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+		streams: make(map[string]int),
+	}
+
+	type found struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var files []found
+	var dirs []string
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != root {
+				dirs = append(dirs, path)
+			}
+			return nil
+		}
+		// Partial segments of an interrupted transcode are never finished.
+		if strings.HasSuffix(path, partSuffix) {
+			_ = os.Remove(path)
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			files = append(files, found{path, info.Size(), info.ModTime()})
+		}
+		return nil
+	})
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+
+	c.mu.Lock()
+	for _, f := range files {
+		c.insertLocked(f.path, f.size)
+	}
+	evicted := c.evictLocked()
+	c.mu.Unlock()
+	c.notify(evicted)
+
+	// Nothing is transcoding yet, so empty directories can go. Children come
+	// after their parents in walk order; Remove fails harmlessly on the rest.
+	for i := len(dirs) - 1; i >= 0; i-- {
+		_ = os.Remove(dirs[i])
+	}
+	return c
+}
+
+// open returns the cached segment at path, marking it used, or false when it
+// isn't cached. The file stays readable if it is evicted while being served.
+func (c *segmentCache) open(path string) (*os.File, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[path]
+	if !ok {
+		return nil, false
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		// Removed behind our back; forget it.
+		c.removeLocked(elem)
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+	return f, true
+}
+
+// add records a newly written segment and evicts old ones to make room.
+func (c *segmentCache) add(path string, size int64) {
+	c.mu.Lock()
+	if elem, ok := c.entries[path]; ok {
+		c.removeLocked(elem)
+	}
+	c.insertLocked(path, size)
+	evicted := c.evictLocked()
+	c.mu.Unlock()
+	c.notify(evicted)
+}
+
+// evictLocked deletes least recently used segments until the cache fits,
+// always keeping the most recent one. It returns the streams left without
+// segments.
+func (c *segmentCache) evictLocked() []string {
+	var emptied []string
+	for c.size > c.maxSize && c.order.Len() > 1 {
+		elem := c.order.Back()
+		entry := elem.Value.(*cacheEntry)
+		if err := os.Remove(entry.path); err != nil && !os.IsNotExist(err) {
+			log.WithError(err).WithField("path", entry.path).Warn("Failed to evict HLS segment")
+		}
+		if stream, empty := c.removeLocked(elem); empty {
+			emptied = append(emptied, stream)
+		}
+	}
+	return emptied
+}
+
+// notify passes evicted streams on to onEvict.
+func (c *segmentCache) notify(streams []string) {
+	if c.onEvict == nil {
+		return
+	}
+	for _, stream := range streams {
+		c.onEvict(stream)
+	}
+}
+
+func (c *segmentCache) insertLocked(path string, size int64) {
+	c.entries[path] = c.order.PushFront(&cacheEntry{path: path, size: size})
+	c.size += size
+	c.streams[c.streamOf(path)]++
+}
+
+// removeLocked forgets a segment, reporting its stream and whether that was
+// its last cached segment.
+func (c *segmentCache) removeLocked(elem *list.Element) (string, bool) {
+	entry := elem.Value.(*cacheEntry)
+	c.order.Remove(elem)
+	delete(c.entries, entry.path)
+	c.size -= entry.size
+
+	stream := c.streamOf(entry.path)
+	c.streams[stream]--
+	if c.streams[stream] > 0 {
+		return stream, false
+	}
+	delete(c.streams, stream)
+	return stream, true
+}
+
+// streamOf returns the stream directory name of a segment path: its first
+// element below root.
+func (c *segmentCache) streamOf(path string) string {
+	rel, err := filepath.Rel(c.root, path)
+	if err != nil {
+		return ""
+	}
+	stream, _, _ := strings.Cut(filepath.ToSlash(rel), "/")
+	return stream
+}
+
+// usage returns the cached bytes and segment count.
+func (c *segmentCache) usage() (int64, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size, c.order.Len()
+}
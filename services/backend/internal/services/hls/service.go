@@ -0,0 +1,411 @@
+package hls
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"video-archiver/internal/services/tools"
+)
+
+const (
+	// defaultMaxCacheSize caps the segment cache at 2 GiB.
+	defaultMaxCacheSize = 2 << 30
+	// defaultSegmentDuration is the length of a segment in seconds.
+	defaultSegmentDuration = 6
+	// partSuffix marks a segment still being written.
+	partSuffix = ".part"
+)
+
+var (
+	// ErrNoVideo is returned for files without a video stream.
+	ErrNoVideo = errors.New("file has no video stream")
+	// ErrNotFound is returned for unknown renditions and segments.
+	ErrNotFound = errors.New("no such rendition or segment")
+)
+
+// Rendition is a quality of the adaptive stream. Bitrates are in kbit/s.
+type Rendition struct {
+	Name         string
+	Height       int
+	VideoBitrate int
+	AudioBitrate int
+}
+
+// Renditions are the qualities offered, best first. A video gets those no
+// taller than itself, or only the smallest at its own size.
+var Renditions = []Rendition{
+	{Name: "1080p", Height: 1080, VideoBitrate: 5000, AudioBitrate: 128},
+	{Name: "720p", Height: 720, VideoBitrate: 2800, AudioBitrate: 128},
+	{Name: "480p", Height: 480, VideoBitrate: 1400, AudioBitrate: 96},
+	{Name: "360p", Height: 360, VideoBitrate: 800, AudioBitrate: 96},
+}
+
+// FFmpeg probes and transcodes media; tools.FFmpeg satisfies it.
+type FFmpeg interface {
+	Probe(path string) (*tools.MediaInfo, error)
+	Run(ctx context.Context, opArgs []string, output string, totalDuration float64, cb tools.ProgressFunc) error
+}
+
+type Config struct {
+	// CachePath holds transcoded segments, one directory per version of a
+	// video file.
+	CachePath string
+	// MaxCacheSize caps the segment cache in bytes; least recently used
+	// segments are deleted beyond it. Defaults to 2 GiB.
+	MaxCacheSize int64
+	// SegmentDuration defaults to 6 seconds.
+	SegmentDuration float64
+	// FFmpeg defaults to tools.NewFFmpeg().
+	FFmpeg FFmpeg
+}
+
+// Service streams videos as HLS, transcoding each segment to h264/aac when a
+// player first asks for it. Finished segments are cached on disk; a
+// transcode nobody waits for any more is cancelled, so seeking away or
+// closing the player doesn't leave ffmpeg running.
+type Service struct {
+	cachePath       string
+	segmentDuration float64
+	ffmpeg          FFmpeg
+	cache           *segmentCache
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu sync.Mutex
+	// probes caches the probe of each stream by key, until the stream's
+	// segments are evicted.
+	probes map[string]*tools.MediaInfo
+	// transcodes holds the running transcodes by segment path.
+	transcodes map[string]*transcode
+}
+
+// transcode is a segment being produced for one or more waiting requests.
+type transcode struct {
+	done    chan struct{}
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+func NewService(config *Config) *Service {
+	maxSize := config.MaxCacheSize
+	if maxSize <= 0 {
+		maxSize = defaultMaxCacheSize
+	}
+	segmentDuration := config.SegmentDuration
+	if segmentDuration <= 0 {
+		segmentDuration = defaultSegmentDuration
+	}
+	ffmpeg := config.FFmpeg
+	if ffmpeg == nil {
+		ffmpeg = tools.NewFFmpeg()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Service{
+		cachePath:       config.CachePath,
+		segmentDuration: segmentDuration,
+		ffmpeg:          ffmpeg,
+		ctx:             ctx,
+		cancel:          cancel,
+		probes:          make(map[string]*tools.MediaInfo),
+		transcodes:      make(map[string]*transcode),
+	}
+	s.cache = newSegmentCache(config.CachePath, maxSize, s.forgetProbe)
+	return s
+}
+
+// forgetProbe drops the probe of a stream whose segments have all been
+// evicted; a player coming back probes the file again.
+func (s *Service) forgetProbe(key string) {
+	s.mu.Lock()
+	delete(s.probes, key)
+	s.mu.Unlock()
+}
+
+// Stop cancels the running transcodes and waits for them to exit.
+func (s *Service) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// Source is the video file of a job.
+type Source struct {
+	JobID string
+	Path  string
+}
+
+// stream is a probed version of a source file.
+type stream struct {
+	source Source
+	// key names the stream's cache directory; it changes when the file is
+	// replaced, so segments of an old version are never served.
+	key        string
+	info       *tools.MediaInfo
+	renditions []Rendition
+}
+
+func (s *Service) open(source Source) (*stream, error) {
+	stat, err := os.Stat(source.Path)
+	if err != nil {
+		return nil, fmt.Errorf("stat %s: %w", source.Path, err)
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%d|%d", source.Path, stat.Size(), stat.ModTime().UnixNano())))
+	key := source.JobID + "-" + hex.EncodeToString(sum[:8])
+
+	s.mu.Lock()
+	info, ok := s.probes[key]
+	s.mu.Unlock()
+	if !ok {
+		if info, err = s.ffmpeg.Probe(source.Path); err != nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		s.probes[key] = info
+		s.mu.Unlock()
+	}
+	if !info.HasVideo || info.Height <= 0 {
+		return nil, ErrNoVideo
+	}
+	if info.Duration <= 0 {
+		return nil, fmt.Errorf("unknown duration of %s", source.Path)
+	}
+	return &stream{source: source, key: key, info: info, renditions: renditionsFor(info.Height)}, nil
+}
+
+// renditionsFor picks the renditions of a video height pixels tall.
+func renditionsFor(height int) []Rendition {
+	var picked []Rendition
+	for _, r := range Renditions {
+		if r.Height <= height {
+			picked = append(picked, r)
+		}
+	}
+	if len(picked) == 0 {
+		smallest := Renditions[len(Renditions)-1]
+		smallest.Height = height
+		picked = []Rendition{smallest}
+	}
+	return picked
+}
+
+func (st *stream) rendition(name string) (Rendition, bool) {
+	for _, r := range st.renditions {
+		if r.Name == name {
+			return r, true
+		}
+	}
+	return Rendition{}, false
+}
+
+// width is the width of a rendition, keeping the aspect ratio and even.
+func (st *stream) width(r Rendition) int {
+	if st.info.Width <= 0 {
+		return 0
+	}
+	return int(math.Round(float64(st.info.Width*r.Height)/float64(st.info.Height)/2)) * 2
+}
+
+// segmentCount is how many segments the stream is cut into.
+func (s *Service) segmentCount(st *stream) int {
+	return int(math.Ceil(st.info.Duration / s.segmentDuration))
+}
+
+// segmentSpan returns the start and length of segment index, in seconds.
+func (s *Service) segmentSpan(st *stream, index int) (float64, float64) {
+	start := float64(index) * s.segmentDuration
+	return start, math.Min(s.segmentDuration, st.info.Duration-start)
+}
+
+// MasterPlaylist lists the renditions of source. Their playlists are at
+// <rendition>/index.m3u8 relative to it.
+func (s *Service) MasterPlaylist(source Source) ([]byte, error) {
+	st, err := s.open(source)
+	if err != nil {
+		return nil, err
+	}
+
+	codecs := "avc1.640028"
+	if st.info.HasAudio {
+		codecs += ",mp4a.40.2"
+	}
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n#EXT-X-VERSION:3\n")
+	for _, r := range st.renditions {
+		bandwidth := r.VideoBitrate * 1000
+		if st.info.HasAudio {
+			bandwidth += r.AudioBitrate * 1000
+		}
+		fmt.Fprintf(&b, "#EXT-X-STREAM-INF:BANDWIDTH=%d", bandwidth)
+		if width := st.width(r); width > 0 {
+			fmt.Fprintf(&b, ",RESOLUTION=%dx%d", width, r.Height)
+		}
+		fmt.Fprintf(&b, ",CODECS=\"%s\"\n%s/index.m3u8\n", codecs, r.Name)
+	}
+	return []byte(b.String()), nil
+}
+
+// MediaPlaylist lists the segments of a rendition of source. They are at
+// <index>.ts relative to it.
+func (s *Service) MediaPlaylist(source Source, rendition string) ([]byte, error) {
+	st, err := s.open(source)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := st.rendition(rendition); !ok {
+		return nil, ErrNotFound
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-TARGETDURATION:%d\n", int(math.Ceil(s.segmentDuration)))
+	b.WriteString("#EXT-X-MEDIA-SEQUENCE:0\n#EXT-X-PLAYLIST-TYPE:VOD\n")
+	for i := range s.segmentCount(st) {
+		_, length := s.segmentSpan(st, i)
+		fmt.Fprintf(&b, "#EXTINF:%.3f,\n%d.ts\n", length, i)
+	}
+	b.WriteString("#EXT-X-ENDLIST\n")
+	return []byte(b.String()), nil
+}
+
+// Segment returns segment index of a rendition of source as MPEG-TS,
+// transcoding it unless it is cached. Requests for a segment being
+// transcoded wait for the same ffmpeg run; it is cancelled when all of
+// their contexts are. The caller closes the file.
+func (s *Service) Segment(ctx context.Context, source Source, rendition string, index int) (*os.File, error) {
+	st, err := s.open(source)
+	if err != nil {
+		return nil, err
+	}
+	r, ok := st.rendition(rendition)
+	if !ok || index < 0 || index >= s.segmentCount(st) {
+		return nil, ErrNotFound
+	}
+
+	path := filepath.Join(s.cachePath, st.key, r.Name, strconv.Itoa(index)+".ts")
+	if f, ok := s.cache.open(path); ok {
+		return f, nil
+	}
+
+	t := s.startTranscode(st, r, index, path)
+	select {
+	case <-t.done:
+		if t.err != nil {
+			return nil, t.err
+		}
+		if f, ok := s.cache.open(path); ok {
+			return f, nil
+		}
+		// Evicted right away by a cache smaller than a segment.
+		return os.Open(path)
+	case <-ctx.Done():
+		s.mu.Lock()
+		t.waiters--
+		if t.waiters == 0 {
+			t.cancel()
+		}
+		s.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// startTranscode joins the transcode of the segment at path, starting it if
+// none is running.
+func (s *Service) startTranscode(st *stream, r Rendition, index int, path string) *transcode {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// A transcode without waiters is being cancelled; start over.
+	if t, ok := s.transcodes[path]; ok && t.waiters > 0 {
+		t.waiters++
+		return t
+	}
+
+	ctx, cancel := context.WithCancel(s.ctx)
+	t := &transcode{done: make(chan struct{}), waiters: 1, cancel: cancel}
+	s.transcodes[path] = t
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer cancel()
+		err := s.transcodeSegment(ctx, st, r, index, path)
+		s.mu.Lock()
+		if s.transcodes[path] == t {
+			delete(s.transcodes, path)
+		}
+		t.err = err
+		close(t.done)
+		s.mu.Unlock()
+	}()
+	return t
+}
+
+func (s *Service) transcodeSegment(ctx context.Context, st *stream, r Rendition, index int, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("create segment directory: %w", err)
+	}
+	start, length := s.segmentSpan(st, index)
+	// Each run writes its own part file: a cancelled run may still be
+	// exiting when the segment is requested again.
+	part := fmt.Sprintf("%s.%d%s", path, time.Now().UnixNano(), partSuffix)
+	if err := s.ffmpeg.Run(ctx, segmentArgs(st, r, start, length), part, length, nil); err != nil {
+		_ = os.Remove(part)
+		if ctx.Err() == nil {
+			log.WithError(err).WithFields(log.Fields{
+				"jobID": st.source.JobID, "rendition": r.Name, "segment": index,
+			}).Error("Failed to transcode HLS segment")
+		}
+		return err
+	}
+	stat, err := os.Stat(part)
+	if err != nil {
+		return fmt.Errorf("stat segment: %w", err)
+	}
+	if err := os.Rename(part, path); err != nil {
+		_ = os.Remove(part)
+		return fmt.Errorf("store segment: %w", err)
+	}
+	s.cache.add(path, stat.Size())
+	return nil
+}
+
+// segmentArgs builds the ffmpeg arguments transcoding length seconds from
+// start into a segment of rendition r. Seeking before the input is fast and
+// restarts timestamps at zero; output_ts_offset moves them back so segments
+// line up on the player's timeline.
+func segmentArgs(st *stream, r Rendition, start, length float64) []string {
+	args := []string{
+		"-ss", fmt.Sprintf("%.3f", start),
+		"-i", st.source.Path,
+		"-t", fmt.Sprintf("%.3f", length),
+		"-map", "0:v:0",
+	}
+	if st.info.HasAudio {
+		args = append(args, "-map", "0:a:0")
+	}
+	args = append(args,
+		"-vf", fmt.Sprintf("scale=-2:%d", r.Height),
+		"-c:v", "libx264", "-preset", "veryfast", "-profile:v", "high", "-pix_fmt", "yuv420p",
+		"-b:v", fmt.Sprintf("%dk", r.VideoBitrate),
+		"-maxrate", fmt.Sprintf("%dk", r.VideoBitrate*107/100),
+		"-bufsize", fmt.Sprintf("%dk", r.VideoBitrate*2),
+	)
+	if st.info.HasAudio {
+		args = append(args, "-c:a", "aac", "-b:a", fmt.Sprintf("%dk", r.AudioBitrate), "-ac", "2")
+	}
+	return append(args,
+		"-output_ts_offset", fmt.Sprintf("%.3f", start),
+		"-muxdelay", "0",
+		"-f", "mpegts",
+	)
+}
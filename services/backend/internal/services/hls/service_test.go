@@ -0,0 +1,284 @@
+package hls
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"video-archiver/internal/services/tools"
+)
+
+// fakeFFmpeg probes every file as info and writes the segment's arguments as
+// its content. While block is open, runs wait for it or their cancellation.
+type fakeFFmpeg struct {
+	info  tools.MediaInfo
+	block chan struct{}
+
+	runs      atomic.Int32
+	cancelled atomic.Int32
+}
+
+func (f *fakeFFmpeg) Probe(string) (*tools.MediaInfo, error) {
+	info := f.info
+	return &info, nil
+}
+
+func (f *fakeFFmpeg) Run(ctx context.Context, opArgs []string, output string, _ float64, _ tools.ProgressFunc) error {
+	f.runs.Add(1)
+	if f.block != nil {
+		select {
+		case <-f.block:
+		case <-ctx.Done():
+			f.cancelled.Add(1)
+			return ctx.Err()
+		}
+	}
+	return os.WriteFile(output, []byte(strings.Join(opArgs, " ")), 0644)
+}
+
+func newTestService(t *testing.T, ffmpeg *fakeFFmpeg, maxCacheSize int64) (*Service, Source) {
+	t.Helper()
+	video := filepath.Join(t.TempDir(), "video.webm")
+	if err := os.WriteFile(video, []byte("webm"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	svc := NewService(&Config{
+		CachePath:    filepath.Join(t.TempDir(), "hls"),
+		MaxCacheSize: maxCacheSize,
+		FFmpeg:       ffmpeg,
+	})
+	t.Cleanup(svc.Stop)
+	return svc, Source{JobID: "job-1", Path: video}
+}
+
+var hdVideo = tools.MediaInfo{Duration: 20, Width: 1280, Height: 720, HasVideo: true, HasAudio: true,
+	VideoCodec: "vp9", AudioCodec: "opus"}
+
+func TestMasterPlaylist(t *testing.T) {
+	svc, source := newTestService(t, &fakeFFmpeg{info: hdVideo}, 0)
+
+	data, err := svc.MasterPlaylist(source)
+	if err != nil {
+		t.Fatalf("MasterPlaylist() error = %v", err)
+	}
+	want := "#EXTM3U\n#EXT-X-VERSION:3\n" +
+		"#EXT-X-STREAM-INF:BANDWIDTH=2928000,RESOLUTION=1280x720,CODECS=\"avc1.640028,mp4a.40.2\"\n720p/index.m3u8\n" +
+		"#EXT-X-STREAM-INF:BANDWIDTH=1496000,RESOLUTION=854x480,CODECS=\"avc1.640028,mp4a.40.2\"\n480p/index.m3u8\n" +
+		"#EXT-X-STREAM-INF:BANDWIDTH=896000,RESOLUTION=640x360,CODECS=\"avc1.640028,mp4a.40.2\"\n360p/index.m3u8\n"
+	if string(data) != want {
+		t.Errorf("MasterPlaylist() =\n%s\nwant\n%s", data, want)
+	}
+
+	small, _ := newTestService(t, &fakeFFmpeg{info: tools.MediaInfo{Duration: 5, Width: 320, Height: 240, HasVideo: true}}, 0)
+	data, _ = small.MasterPlaylist(source)
+	if !strings.Contains(string(data), "RESOLUTION=320x240,CODECS=\"avc1.640028\"\n360p/index.m3u8") {
+		t.Errorf("small video master playlist = %s", data)
+	}
+
+	audio, _ := newTestService(t, &fakeFFmpeg{info: tools.MediaInfo{Duration: 5, HasAudio: true}}, 0)
+	if _, err := audio.MasterPlaylist(source); !errors.Is(err, ErrNoVideo) {
+		t.Errorf("audio-only MasterPlaylist() error = %v, want ErrNoVideo", err)
+	}
+}
+
+func TestMediaPlaylist(t *testing.T) {
+	svc, source := newTestService(t, &fakeFFmpeg{info: hdVideo}, 0)
+
+	data, err := svc.MediaPlaylist(source, "480p")
+	if err != nil {
+		t.Fatalf("MediaPlaylist() error = %v", err)
+	}
+	want := "#EXTM3U\n#EXT-X-VERSION:3\n#EXT-X-TARGETDURATION:6\n#EXT-X-MEDIA-SEQUENCE:0\n#EXT-X-PLAYLIST-TYPE:VOD\n" +
+		"#EXTINF:6.000,\n0.ts\n#EXTINF:6.000,\n1.ts\n#EXTINF:6.000,\n2.ts\n#EXTINF:2.000,\n3.ts\n#EXT-X-ENDLIST\n"
+	if string(data) != want {
+		t.Errorf("MediaPlaylist() =\n%s\nwant\n%s", data, want)
+	}
+	if _, err := svc.MediaPlaylist(source, "1080p"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("MediaPlaylist(taller than source) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestSegmentTranscodesOnceAndCaches(t *testing.T) {
+	ffmpeg := &fakeFFmpeg{info: hdVideo}
+	svc, source := newTestService(t, ffmpeg, 0)
+
+	for range 2 {
+		f, err := svc.Segment(context.Background(), source, "480p", 3)
+		if err != nil {
+			t.Fatalf("Segment() error = %v", err)
+		}
+		data, _ := io.ReadAll(f)
+		f.Close()
+		for _, arg := range []string{"-ss 18.000", "-t 2.000", "scale=-2:480", "-b:v 1400k", "-output_ts_offset 18.000", "-f mpegts"} {
+			if !strings.Contains(string(data), arg) {
+				t.Errorf("segment args %q lack %q", data, arg)
+			}
+		}
+	}
+	if runs := ffmpeg.runs.Load(); runs != 1 {
+		t.Errorf("ffmpeg ran %d times, want 1", runs)
+	}
+
+	for _, tt := range []struct {
+		rendition string
+		index     int
+	}{{"480p", 4}, {"480p", -1}, {"4k", 0}} {
+		if _, err := svc.Segment(context.Background(), source, tt.rendition, tt.index); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Segment(%s, %d) error = %v, want ErrNotFound", tt.rendition, tt.index, err)
+		}
+	}
+}
+
+func TestSegmentCancelledWhenNoLongerRequested(t *testing.T) {
+	ffmpeg := &fakeFFmpeg{info: hdVideo, block: make(chan struct{})}
+	svc, source := newTestService(t, ffmpeg, 0)
+
+	first, cancelFirst := context.WithCancel(context.Background())
+	second, cancelSecond := context.WithCancel(context.Background())
+	errs := make(chan error, 2)
+	for _, ctx := range []context.Context{first, second} {
+		go func() {
+			_, err := svc.Segment(ctx, source, "720p", 0)
+			errs <- err
+		}()
+	}
+	waitFor(t, func() bool {
+		svc.mu.Lock()
+		defer svc.mu.Unlock()
+		for _, tr := range svc.transcodes {
+			return tr.waiters == 2
+		}
+		return false
+	})
+
+	// One player leaving keeps the transcode for the other.
+	cancelFirst()
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled request error = %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if ffmpeg.cancelled.Load() != 0 {
+		t.Fatal("transcode cancelled while still requested")
+	}
+
+	cancelSecond()
+	<-errs
+	waitFor(t, func() bool { return ffmpeg.cancelled.Load() == 1 })
+	if ffmpeg.runs.Load() != 1 {
+		t.Errorf("ffmpeg ran %d times, want 1", ffmpeg.runs.Load())
+	}
+
+	// Asking again starts over.
+	close(ffmpeg.block)
+	f, err := svc.Segment(context.Background(), source, "720p", 0)
+	if err != nil {
+		t.Fatalf("Segment() after cancellation error = %v", err)
+	}
+	f.Close()
+}
+
+func TestSegmentCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	ffmpeg := &fakeFFmpeg{info: hdVideo}
+	svc, source := newTestService(t, ffmpeg, 0)
+
+	segment := func(index int) {
+		t.Helper()
+		f, err := svc.Segment(context.Background(), source, "360p", index)
+		if err != nil {
+			t.Fatalf("Segment(%d) error = %v", index, err)
+		}
+		f.Close()
+	}
+	segment(0)
+	// Fake segments differ in size by a few bytes at most; make three fit.
+	segmentSize, _ := svc.cache.usage()
+	maxSize := segmentSize*3 + segmentSize/2
+	svc.cache.maxSize = maxSize
+
+	segment(1)
+	segment(2)
+	segment(0) // used again, so 1 is now the oldest
+	segment(3)
+
+	if size, count := svc.cache.usage(); size > maxSize || count != 3 {
+		t.Errorf("cache usage = %d bytes in %d segments, want at most %d in 3", size, count, maxSize)
+	}
+	runs := ffmpeg.runs.Load()
+	segment(0)
+	if ffmpeg.runs.Load() != runs {
+		t.Error("recently used segment was evicted")
+	}
+	segment(1)
+	if ffmpeg.runs.Load() != runs+1 {
+		t.Error("least recently used segment was not evicted")
+	}
+
+	// A restart picks the cached segments up again.
+	reopened := newSegmentCache(svc.cachePath, maxSize, nil)
+	if _, count := reopened.usage(); count != 3 {
+		t.Errorf("reopened cache has %d segments, want 3", count)
+	}
+}
+
+func TestSegmentCacheEvictionForgetsStream(t *testing.T) {
+	ffmpeg := &fakeFFmpeg{info: hdVideo}
+	svc, source := newTestService(t, ffmpeg, 0)
+	other := Source{JobID: "job-2", Path: source.Path}
+
+	segment := func(source Source, index int) {
+		t.Helper()
+		f, err := svc.Segment(context.Background(), source, "360p", index)
+		if err != nil {
+			t.Fatalf("Segment(%s, %d) error = %v", source.JobID, index, err)
+		}
+		f.Close()
+	}
+	segment(source, 0)
+	segmentSize, _ := svc.cache.usage()
+	svc.cache.maxSize = segmentSize*2 + segmentSize/2
+	segment(source, 1)
+
+	st, err := svc.open(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	streamDir := filepath.Join(svc.cachePath, st.key)
+
+	// Two segments of another stream push out both of the first.
+	segment(other, 0)
+	segment(other, 1)
+	svc.mu.Lock()
+	_, probed := svc.probes[st.key]
+	probes := len(svc.probes)
+	svc.mu.Unlock()
+	if probed || probes != 1 {
+		t.Errorf("probes = %d, evicted stream still probed: %v", probes, probed)
+	}
+
+	// The emptied directory stays while transcodes may run, and goes on the
+	// next start.
+	if _, err := os.Stat(streamDir); err != nil {
+		t.Errorf("stream directory removed during eviction: %v", err)
+	}
+	newSegmentCache(svc.cachePath, svc.cache.maxSize, nil)
+	if _, err := os.Stat(streamDir); !os.IsNotExist(err) {
+		t.Errorf("empty stream directory kept after restart: %v", err)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
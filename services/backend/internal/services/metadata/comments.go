@@ -0,0 +1,64 @@
+package metadata
+
+import (
+	"encoding/json"
+	"os"
+	"video-archiver/internal/domain"
+)
+
+// rawComment mirrors an entry of the "comments" list yt-dlp writes into
+// .info.json when run with --write-comments.
+type rawComment struct {
+	ID               string `json:"id"`
+	Parent           string `json:"parent"`
+	Text             string `json:"text"`
+	Author           string `json:"author"`
+	AuthorID         string `json:"author_id"`
+	AuthorIsUploader bool   `json:"author_is_uploader"`
+	LikeCount        int    `json:"like_count"`
+	Timestamp        int64  `json:"timestamp"`
+	IsFavorited      bool   `json:"is_favorited"`
+	IsPinned         bool   `json:"is_pinned"`
+}
+
+// ExtractComments reads the archived comments from a video's .info.json. A
+// file without a comments list (comments not requested, or disabled on the
+// video) yields an empty result, not an error. yt-dlp marks top-level
+// comments with parent "root"; they are returned with an empty ParentID.
+func ExtractComments(path string) ([]domain.Comment, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var info struct {
+		Comments []rawComment `json:"comments"`
+	}
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, err
+	}
+
+	comments := make([]domain.Comment, 0, len(info.Comments))
+	for _, c := range info.Comments {
+		if c.ID == "" {
+			continue
+		}
+		parent := c.Parent
+		if parent == "root" {
+			parent = ""
+		}
+		comments = append(comments, domain.Comment{
+			ID:               c.ID,
+			ParentID:         parent,
+			Author:           c.Author,
+			AuthorID:         c.AuthorID,
+			AuthorIsUploader: c.AuthorIsUploader,
+			Text:             c.Text,
+			LikeCount:        c.LikeCount,
+			Timestamp:        c.Timestamp,
+			IsPinned:         c.IsPinned,
+			IsHearted:        c.IsFavorited,
+		})
+	}
+	return comments, nil
+}
@@ -0,0 +1,56 @@
+package metadata
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExtractComments(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "video.info.json")
+	data := `{
+		"id": "abc",
+		"title": "Video",
+		"comments": [
+			{"id": "c1", "parent": "root", "text": "First!", "author": "@alice", "like_count": 12,
+			 "timestamp": 1700000000, "is_pinned": true, "is_favorited": true},
+			{"id": "c1.r1", "parent": "c1", "text": "Reply", "author": "@uploader", "author_is_uploader": true},
+			{"parent": "root", "text": "no id, skipped"}
+		]
+	}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	comments, err := ExtractComments(path)
+	if err != nil {
+		t.Fatalf("ExtractComments() error = %v", err)
+	}
+	if len(comments) != 2 {
+		t.Fatalf("got %d comments, want 2", len(comments))
+	}
+	root := comments[0]
+	if root.ParentID != "" || !root.IsPinned || !root.IsHearted || root.LikeCount != 12 || root.Timestamp != 1700000000 {
+		t.Errorf("unexpected root comment: %+v", root)
+	}
+	if reply := comments[1]; reply.ParentID != "c1" || !reply.AuthorIsUploader {
+		t.Errorf("unexpected reply: %+v", reply)
+	}
+}
+
+func TestExtractComments_NoComments(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "video.info.json")
+	if err := os.WriteFile(path, []byte(`{"id": "abc", "title": "Video"}`), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	comments, err := ExtractComments(path)
+	if err != nil {
+		t.Fatalf("ExtractComments() error = %v", err)
+	}
+	if len(comments) != 0 {
+		t.Errorf("got %d comments, want 0", len(comments))
+	}
+}
@@ -0,0 +1,88 @@
+package metadata
+
+import (
+	"encoding/json"
+	"os"
+	"video-archiver/internal/domain"
+)
+
+// rawEntry mirrors an item of the "entries" list yt-dlp writes into a
+// playlist's .info.json in --flat-playlist mode.
+type rawEntry struct {
+	ID            string `json:"id"`
+	Title         string `json:"title"`
+	Type          string `json:"_type"`
+	IEKey         string `json:"ie_key"`
+	URL           string `json:"url"`
+	PlaylistIndex int    `json:"playlist_index"`
+}
+
+// ExtractPlaylistEntries reads the ordered item list from a playlist or
+// channel .info.json. Nested playlists (channel tabs listed instead of
+// videos) are skipped. Entries without a playlist_index get their 1-based
+// position in the list.
+func ExtractPlaylistEntries(path string) ([]domain.PlaylistEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePlaylistEntries(data)
+}
+
+// ParsePlaylistEntries is ExtractPlaylistEntries for a listing already in
+// memory, such as the output of --dump-single-json.
+func ParsePlaylistEntries(data []byte) ([]domain.PlaylistEntry, error) {
+	raw, err := parseEntries(data)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]domain.PlaylistEntry, 0, len(raw))
+	seen := map[int]bool{}
+	for i, e := range raw {
+		if e.ID == "" || isNested(e) {
+			continue
+		}
+		position := e.PlaylistIndex
+		if position <= 0 || seen[position] {
+			position = i + 1
+		}
+		seen[position] = true
+		entries = append(entries, domain.PlaylistEntry{VideoID: e.ID, Title: e.Title, Position: position})
+	}
+	return entries, nil
+}
+
+// ExtractChannelTabs returns the URLs of the nested tabs a channel root
+// .info.json lists in place of its videos, in listing order.
+func ExtractChannelTabs(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := parseEntries(data)
+	if err != nil {
+		return nil, err
+	}
+	var tabs []string
+	for _, e := range raw {
+		if e.IEKey == "YoutubeTab" && e.URL != "" {
+			tabs = append(tabs, e.URL)
+		}
+	}
+	return tabs, nil
+}
+
+func parseEntries(data []byte) ([]rawEntry, error) {
+	var info struct {
+		Entries []rawEntry `json:"entries"`
+	}
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, err
+	}
+	return info.Entries, nil
+}
+
+func isNested(e rawEntry) bool {
+	return e.Type == "playlist" || e.IEKey == "YoutubeTab"
+}
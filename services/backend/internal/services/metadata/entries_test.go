@@ -0,0 +1,66 @@
+package metadata
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"video-archiver/internal/domain"
+)
+
+func TestExtractPlaylistEntries(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "playlist.info.json")
+	data := `{
+		"id": "PL1",
+		"_type": "playlist",
+		"entries": [
+			{"id": "a", "title": "First", "_type": "url", "playlist_index": 1},
+			{"id": "UCtab", "title": "Channel - Shorts", "_type": "url", "ie_key": "YoutubeTab"},
+			{"id": "b", "title": "Second", "_type": "url", "playlist_index": 2},
+			{"id": "c", "title": "No index", "_type": "url"},
+			{"title": "No id"}
+		]
+	}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	entries, err := ExtractPlaylistEntries(path)
+	if err != nil {
+		t.Fatalf("ExtractPlaylistEntries() error = %v", err)
+	}
+	want := []domain.PlaylistEntry{
+		{VideoID: "a", Title: "First", Position: 1},
+		{VideoID: "b", Title: "Second", Position: 2},
+		{VideoID: "c", Title: "No index", Position: 4},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("entries = %+v, want %+v", entries, want)
+	}
+}
+
+func TestExtractChannelTabs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "channel.info.json")
+	data := `{
+		"id": "UC1",
+		"_type": "playlist",
+		"entries": [
+			{"id": "UC1", "title": "Channel - Videos", "_type": "url", "ie_key": "YoutubeTab", "url": "https://www.youtube.com/@chan/videos"},
+			{"id": "UC1", "title": "Channel - Shorts", "_type": "url", "ie_key": "YoutubeTab", "url": "https://www.youtube.com/@chan/shorts"},
+			{"id": "a", "title": "Video", "_type": "url"}
+		]
+	}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	tabs, err := ExtractChannelTabs(path)
+	if err != nil {
+		t.Fatalf("ExtractChannelTabs() error = %v", err)
+	}
+	want := []string{"https://www.youtube.com/@chan/videos", "https://www.youtube.com/@chan/shorts"}
+	if !reflect.DeepEqual(tabs, want) {
+		t.Errorf("tabs = %v, want %v", tabs, want)
+	}
+}
@@ -0,0 +1,393 @@
+package storyboard
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"video-archiver/internal/domain"
+	"video-archiver/internal/services/download"
+	"video-archiver/internal/services/tools"
+)
+
+const (
+	// defaultInterval is the seconds between two previews.
+	defaultInterval = 10
+	// maxFrames caps the previews of a long video; the interval grows
+	// instead, keeping a storyboard to a few sprite sheets.
+	maxFrames = 1000
+	// thumbWidth is the width of a preview in pixels.
+	thumbWidth = 160
+	// columns and rows tile the previews into a sprite sheet.
+	columns = 10
+	rows    = 10
+
+	// IndexName is the WebVTT index inside a storyboard directory.
+	IndexName = "storyboard.vtt"
+	// queueSize bounds the completed downloads waiting for a storyboard.
+	queueSize = 64
+)
+
+var (
+	// ErrNoVideo is returned for audio downloads, playlists and channels.
+	ErrNoVideo = errors.New("job has no video")
+	// ErrNotFound is returned for unknown jobs, missing files and sheets.
+	ErrNotFound = errors.New("no such video or sprite sheet")
+)
+
+// FFmpeg probes and runs ffmpeg; tools.FFmpeg satisfies it.
+type FFmpeg interface {
+	Probe(path string) (*tools.MediaInfo, error)
+	Run(ctx context.Context, opArgs []string, output string, totalDuration float64, cb tools.ProgressFunc) error
+}
+
+type Config struct {
+	JobRepository domain.JobRepository
+	// DownloadPath holds the videos, and the storyboards in a hidden
+	// directory next to the thumbnail cache.
+	DownloadPath string
+	// Interval is the seconds between two previews; defaults to 10.
+	Interval float64
+	// FFmpeg defaults to tools.NewFFmpeg().
+	FFmpeg FFmpeg
+}
+
+// Service generates seek-preview storyboards: a preview every few seconds,
+// tiled into JPEG sprite sheets and indexed by a WebVTT file that players
+// read their seek bar thumbnails from. Storyboards are generated for
+// completed downloads in the background, one at a time, and on first request
+// for videos downloaded before. A storyboard older than its video (re-download,
+// remux in place) is generated again.
+type Service struct {
+	jobs         domain.JobRepository
+	downloadPath string
+	interval     float64
+	ffmpeg       FFmpeg
+
+	queue  chan string
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu sync.Mutex
+	// generating holds the running generations by job ID.
+	generating map[string]*generation
+}
+
+// generation is a storyboard being produced for any number of waiters.
+type generation struct {
+	done chan struct{}
+	err  error
+}
+
+func NewService(config *Config) *Service {
+	interval := config.Interval
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	ffmpeg := config.FFmpeg
+	if ffmpeg == nil {
+		ffmpeg = tools.NewFFmpeg()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Service{
+		jobs:         config.JobRepository,
+		downloadPath: config.DownloadPath,
+		interval:     interval,
+		ffmpeg:       ffmpeg,
+		queue:        make(chan string, queueSize),
+		ctx:          ctx,
+		cancel:       cancel,
+		generating:   make(map[string]*generation),
+	}
+	s.removeLeftovers()
+	s.wg.Add(1)
+	go s.worker()
+	return s
+}
+
+// removeLeftovers deletes the temporary directories of generations cut short
+// by a restart.
+func (s *Service) removeLeftovers() {
+	dir, err := download.StoryboardCacheDir(s.downloadPath, "any")
+	if err != nil {
+		return
+	}
+	leftovers, _ := filepath.Glob(filepath.Join(filepath.Dir(dir), "*.tmp-*"))
+	for _, path := range leftovers {
+		if err := os.RemoveAll(path); err != nil {
+			log.WithError(err).WithField("path", path).Warn("Failed to remove partial storyboard")
+		}
+	}
+}
+
+// Stop cancels the running generations and waits for them to exit.
+func (s *Service) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// DownloadCompleted queues storyboards for a completed download: the video,
+// or every video of a playlist or channel. A full queue drops the job; its
+// storyboard is then generated on first request.
+func (s *Service) DownloadCompleted(job domain.Job) {
+	select {
+	case s.queue <- job.ID:
+	default:
+		log.WithField("jobID", job.ID).Debug("Storyboard queue full, generating on request")
+	}
+}
+
+func (s *Service) worker() {
+	defer s.wg.Done()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case jobID := <-s.queue:
+			for _, id := range s.videosOf(jobID) {
+				if _, err := s.Index(s.ctx, id); err != nil && !errors.Is(err, ErrNoVideo) && s.ctx.Err() == nil {
+					log.WithError(err).WithField("jobID", id).Warn("Failed to generate storyboard")
+				}
+			}
+		}
+	}
+}
+
+// videosOf returns the job itself, or the videos of a playlist or channel.
+func (s *Service) videosOf(jobID string) []string {
+	item, err := s.jobs.GetJobWithMetadata(jobID)
+	if err != nil || item == nil || item.Job == nil {
+		return nil
+	}
+	if _, ok := item.Metadata.(*domain.VideoMetadata); ok {
+		return []string{jobID}
+	}
+	videos, err := s.jobs.GetVideosForParent(jobID)
+	if err != nil {
+		log.WithError(err).WithField("jobID", jobID).Warn("Failed to list videos for storyboards")
+		return nil
+	}
+	var ids []string
+	for _, video := range videos {
+		if video != nil && video.Job != nil {
+			ids = append(ids, video.Job.ID)
+		}
+	}
+	return ids
+}
+
+// Index returns the WebVTT index of a video's storyboard, generating it first
+// when missing or older than the video. A generation keeps running when ctx
+// is done, so the next request finds it finished.
+func (s *Service) Index(ctx context.Context, jobID string) (string, error) {
+	source, err := s.source(jobID)
+	if err != nil {
+		return "", err
+	}
+	dir, err := download.StoryboardCacheDir(s.downloadPath, jobID)
+	if err != nil {
+		return "", ErrNotFound
+	}
+	index := filepath.Join(dir, IndexName)
+	if fresh(index, source) {
+		return index, nil
+	}
+
+	s.mu.Lock()
+	g, running := s.generating[jobID]
+	if !running {
+		g = &generation{done: make(chan struct{})}
+		s.generating[jobID] = g
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			g.err = s.generate(jobID, source, dir)
+			s.mu.Lock()
+			delete(s.generating, jobID)
+			s.mu.Unlock()
+			close(g.done)
+		}()
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-g.done:
+		if g.err != nil {
+			return "", g.err
+		}
+		return index, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// Sheet returns a sprite sheet of a video's storyboard. It never generates:
+// sheets are only asked for through an index.
+func (s *Service) Sheet(jobID string, index int) (string, error) {
+	dir, err := download.StoryboardCacheDir(s.downloadPath, jobID)
+	if err != nil || index < 0 {
+		return "", ErrNotFound
+	}
+	path := filepath.Join(dir, sheetName(index))
+	if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() {
+		return "", ErrNotFound
+	}
+	return path, nil
+}
+
+// source locates the video file of a job.
+func (s *Service) source(jobID string) (string, error) {
+	item, err := s.jobs.GetJobWithMetadata(jobID)
+	if err != nil || item == nil || item.Job == nil {
+		return "", ErrNotFound
+	}
+	metadata, ok := item.Metadata.(*domain.VideoMetadata)
+	if !ok || item.Job.IsAudio() {
+		return "", ErrNoVideo
+	}
+	path, err := tools.ResolveVideoFileWithHint(s.downloadPath, item.Job.FilePath, metadata)
+	if err != nil {
+		return "", ErrNotFound
+	}
+	return path, nil
+}
+
+// fresh reports whether the index exists and is no older than the video.
+func fresh(index, source string) bool {
+	indexInfo, err := os.Stat(index)
+	if err != nil {
+		return false
+	}
+	sourceInfo, err := os.Stat(source)
+	return err == nil && !indexInfo.ModTime().Before(sourceInfo.ModTime())
+}
+
+// layout is the geometry of a storyboard.
+type layout struct {
+	duration float64
+	interval float64
+	frames   int
+	width    int
+	height   int
+}
+
+// layoutFor spaces the previews of a video and sizes them to its aspect
+// ratio, with an even height as the encoder requires.
+func layoutFor(info *tools.MediaInfo, interval float64) layout {
+	if info.Duration/interval > maxFrames {
+		interval = math.Ceil(info.Duration / maxFrames)
+	}
+	height := thumbWidth * 9 / 16
+	if info.Width > 0 && info.Height > 0 {
+		height = int(math.Round(float64(thumbWidth*info.Height)/float64(info.Width)/2)) * 2
+	}
+	return layout{
+		duration: info.Duration,
+		interval: interval,
+		frames:   max(1, int(math.Ceil(info.Duration/interval))),
+		width:    thumbWidth,
+		height:   max(2, height),
+	}
+}
+
+// generate writes the sprite sheets and index of source into a temporary
+// directory, then swaps it in for dir. A job deleted meanwhile gets its
+// storyboard removed again: the delete path removes the directory after the
+// job's records, so either it or this check catches the swapped-in copy.
+func (s *Service) generate(jobID, source, dir string) error {
+	info, err := s.ffmpeg.Probe(source)
+	if err != nil {
+		return fmt.Errorf("probe %s: %w", source, err)
+	}
+	if !info.HasVideo {
+		return ErrNoVideo
+	}
+	l := layoutFor(info, s.interval)
+
+	tmp := fmt.Sprintf("%s.tmp-%d", dir, time.Now().UnixNano())
+	if err := os.MkdirAll(tmp, 0o755); err != nil {
+		return fmt.Errorf("create storyboard directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	start := time.Now()
+	if err := s.ffmpeg.Run(s.ctx, sheetArgs(source, l), filepath.Join(tmp, "%d.jpg"), l.duration, nil); err != nil {
+		return fmt.Errorf("generate storyboard: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmp, IndexName), []byte(buildIndex(l)), 0o644); err != nil {
+		return fmt.Errorf("write storyboard index: %w", err)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("remove old storyboard: %w", err)
+	}
+	if err := os.Rename(tmp, dir); err != nil {
+		return fmt.Errorf("finalize storyboard: %w", err)
+	}
+	if _, err := s.jobs.GetByID(jobID); errors.Is(err, sql.ErrNoRows) {
+		if err := os.RemoveAll(dir); err != nil {
+			log.WithError(err).WithField("path", dir).Warn("Failed to remove storyboard of deleted job")
+		}
+		return ErrNotFound
+	}
+	log.WithFields(log.Fields{"path": source, "frames": l.frames, "took": time.Since(start)}).Info("Generated storyboard")
+	return nil
+}
+
+// sheetArgs picks a frame every interval and tiles them into sprite sheets.
+// Decoding only keyframes makes this fast on long videos; a preview off by a
+// few seconds is fine for scrubbing.
+func sheetArgs(source string, l layout) []string {
+	filter := fmt.Sprintf("fps=1/%s,scale=%d:%d,tile=%dx%d",
+		strconv.FormatFloat(l.interval, 'f', -1, 64), l.width, l.height, columns, rows)
+	return []string{
+		"-skip_frame", "nokey",
+		"-i", source,
+		"-an", "-sn",
+		"-vf", filter,
+		"-q:v", "5",
+		"-start_number", "0",
+		"-f", "image2",
+	}
+}
+
+// sheetName is the file name of a sprite sheet, relative to the index.
+func sheetName(index int) string {
+	return strconv.Itoa(index) + ".jpg"
+}
+
+// buildIndex writes a cue per preview pointing at its tile, by the
+// media-fragment syntax players understand. Sheet URLs are relative to the
+// index, which is served from /video/{jobID}/storyboard.vtt.
+func buildIndex(l layout) string {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n")
+	perSheet := columns * rows
+	for i := range l.frames {
+		start := float64(i) * l.interval
+		end := min(start+l.interval, l.duration)
+		if end <= start {
+			end = start + l.interval
+		}
+		tile := i % perSheet
+		fmt.Fprintf(&b, "\n%s --> %s\nstoryboard/%s#xywh=%d,%d,%d,%d\n",
+			timestamp(start), timestamp(end), sheetName(i/perSheet),
+			tile%columns*l.width, tile/columns*l.height, l.width, l.height)
+	}
+	return b.String()
+}
+
+// timestamp formats seconds as a WebVTT timestamp.
+func timestamp(seconds float64) string {
+	ms := int64(math.Round(seconds * 1000))
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
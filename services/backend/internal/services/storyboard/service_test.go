@@ -0,0 +1,220 @@
+package storyboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"video-archiver/internal/services/download"
+	"video-archiver/internal/services/tools"
+	"video-archiver/internal/testutil"
+)
+
+// fakeFFmpeg probes every file as info and writes one sprite sheet per 100
+// frames, holding the ffmpeg arguments. While block is open, runs wait for it.
+type fakeFFmpeg struct {
+	info  tools.MediaInfo
+	block chan struct{}
+	runs  atomic.Int32
+}
+
+func (f *fakeFFmpeg) Probe(string) (*tools.MediaInfo, error) {
+	info := f.info
+	return &info, nil
+}
+
+func (f *fakeFFmpeg) Run(_ context.Context, opArgs []string, output string, _ float64, _ tools.ProgressFunc) error {
+	f.runs.Add(1)
+	if f.block != nil {
+		<-f.block
+	}
+	sheets := int(f.info.Duration/defaultInterval)/(columns*rows) + 1
+	for i := range sheets {
+		if err := os.WriteFile(strings.Replace(output, "%d", fmt.Sprint(i), 1), []byte(strings.Join(opArgs, " ")), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newTestService(t *testing.T, ffmpeg *fakeFFmpeg) (*Service, *testutil.MockJobRepository, string) {
+	t.Helper()
+	downloadPath := t.TempDir()
+	video := filepath.Join(downloadPath, "video.webm")
+	if err := os.WriteFile(video, []byte("webm"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	jobs := testutil.NewMockJobRepository()
+	job := testutil.CreateTestJob("v1", "https://youtube.com/watch?v=v1")
+	job.FilePath = video
+	jobs.Create(job)
+	jobs.StoreMetadata("v1", testutil.CreateTestVideoMetadata())
+
+	svc := NewService(&Config{JobRepository: jobs, DownloadPath: downloadPath, FFmpeg: ffmpeg})
+	t.Cleanup(svc.Stop)
+	return svc, jobs, video
+}
+
+var hdVideo = tools.MediaInfo{Duration: 1005, Width: 1280, Height: 720, HasVideo: true, VideoCodec: "vp9"}
+
+func TestIndexGeneratesOnceAndCaches(t *testing.T) {
+	ffmpeg := &fakeFFmpeg{info: hdVideo}
+	svc, _, video := newTestService(t, ffmpeg)
+
+	for range 2 {
+		index, err := svc.Index(context.Background(), "v1")
+		if err != nil {
+			t.Fatalf("Index() error = %v", err)
+		}
+		if filepath.Base(index) != IndexName {
+			t.Errorf("Index() = %q", index)
+		}
+	}
+	if runs := ffmpeg.runs.Load(); runs != 1 {
+		t.Errorf("ffmpeg ran %d times, want 1", runs)
+	}
+
+	sheet, err := svc.Sheet("v1", 1)
+	if err != nil {
+		t.Fatalf("Sheet() error = %v", err)
+	}
+	data, _ := os.ReadFile(sheet)
+	for _, arg := range []string{"-skip_frame nokey", "fps=1/10,scale=160:90,tile=10x10", "-start_number 0"} {
+		if !strings.Contains(string(data), arg) {
+			t.Errorf("sheet args %q lack %q", data, arg)
+		}
+	}
+	for _, index := range []int{2, -1} {
+		if _, err := svc.Sheet("v1", index); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Sheet(%d) error = %v, want ErrNotFound", index, err)
+		}
+	}
+
+	// A video replaced after its storyboard gets a new one.
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(video, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Index(context.Background(), "v1"); err != nil {
+		t.Fatalf("Index() after replacement error = %v", err)
+	}
+	if runs := ffmpeg.runs.Load(); runs != 2 {
+		t.Errorf("ffmpeg ran %d times, want 2", runs)
+	}
+}
+
+func TestIndexErrors(t *testing.T) {
+	svc, jobs, _ := newTestService(t, &fakeFFmpeg{info: hdVideo})
+	jobs.Create(testutil.CreateTestJob("pl", "https://youtube.com/playlist?list=x"))
+	jobs.StoreMetadata("pl", testutil.CreateTestPlaylistMetadata())
+
+	if _, err := svc.Index(context.Background(), "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Index(missing) error = %v, want ErrNotFound", err)
+	}
+	if _, err := svc.Index(context.Background(), "pl"); !errors.Is(err, ErrNoVideo) {
+		t.Errorf("Index(playlist) error = %v, want ErrNoVideo", err)
+	}
+
+	audio, _, _ := newTestService(t, &fakeFFmpeg{info: tools.MediaInfo{Duration: 60, HasAudio: true}})
+	if _, err := audio.Index(context.Background(), "v1"); !errors.Is(err, ErrNoVideo) {
+		t.Errorf("Index(audio-only file) error = %v, want ErrNoVideo", err)
+	}
+}
+
+func TestDownloadCompletedGeneratesInBackground(t *testing.T) {
+	ffmpeg := &fakeFFmpeg{info: hdVideo}
+	svc, jobs, _ := newTestService(t, ffmpeg)
+
+	job, _ := jobs.GetByID("v1")
+	svc.DownloadCompleted(*job)
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, err := svc.Sheet("v1", 0); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("storyboard not generated in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestGenerationOfDeletedJobLeavesNoStoryboard(t *testing.T) {
+	ffmpeg := &fakeFFmpeg{info: hdVideo, block: make(chan struct{})}
+	svc, jobs, _ := newTestService(t, ffmpeg)
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := svc.Index(context.Background(), "v1")
+		result <- err
+	}()
+	deadline := time.Now().Add(2 * time.Second)
+	for ffmpeg.runs.Load() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("generation not started in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// The job is deleted while its storyboard is being generated.
+	jobs.DeleteJob("v1")
+	close(ffmpeg.block)
+	if err := <-result; !errors.Is(err, ErrNotFound) {
+		t.Errorf("Index() error = %v, want ErrNotFound", err)
+	}
+	dir, _ := download.StoryboardCacheDir(svc.downloadPath, "v1")
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("storyboard of deleted job left behind: %v", err)
+	}
+}
+
+func TestLayoutFor(t *testing.T) {
+	tests := []struct {
+		name string
+		info tools.MediaInfo
+		want layout
+	}{
+		{"16:9", tools.MediaInfo{Duration: 95, Width: 1920, Height: 1080},
+			layout{duration: 95, interval: 10, frames: 10, width: 160, height: 90}},
+		{"portrait", tools.MediaInfo{Duration: 30, Width: 1080, Height: 1920},
+			layout{duration: 30, interval: 10, frames: 3, width: 160, height: 284}},
+		{"long video spaces previews out", tools.MediaInfo{Duration: 4 * 3600, Width: 640, Height: 480},
+			layout{duration: 4 * 3600, interval: 15, frames: 960, width: 160, height: 120}},
+		{"shorter than an interval", tools.MediaInfo{Duration: 4},
+			layout{duration: 4, interval: 10, frames: 1, width: 160, height: 90}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := layoutFor(&tt.info, defaultInterval); got != tt.want {
+				t.Errorf("layoutFor() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildIndex(t *testing.T) {
+	got := buildIndex(layout{duration: 1005, interval: 10, frames: 101, width: 160, height: 90})
+	if !strings.HasPrefix(got, "WEBVTT\n\n00:00:00.000 --> 00:00:10.000\nstoryboard/0.jpg#xywh=0,0,160,90\n") {
+		t.Errorf("first cue wrong:\n%s", got)
+	}
+	for _, cue := range []string{
+		"\n00:00:10.000 --> 00:00:20.000\nstoryboard/0.jpg#xywh=160,0,160,90\n",
+		"\n00:02:10.000 --> 00:02:20.000\nstoryboard/0.jpg#xywh=480,90,160,90\n",
+		"\n00:16:30.000 --> 00:16:40.000\nstoryboard/0.jpg#xywh=1440,810,160,90\n",
+		// The last cue starts a new sheet and ends with the video.
+		"\n00:16:40.000 --> 00:16:45.000\nstoryboard/1.jpg#xywh=0,0,160,90\n",
+	} {
+		if !strings.Contains(got, cue) {
+			t.Errorf("index lacks cue %q", cue)
+		}
+	}
+	if n := strings.Count(got, "-->"); n != 101 {
+		t.Errorf("index has %d cues, want 101", n)
+	}
+}
@@ -136,9 +136,33 @@ func validateConcat(p *domain.ConcatParameters) error {
 	if p.OutputFormat != "" && !contains(concatFormats, p.OutputFormat) {
 		return fmt.Errorf("unsupported output_format %q for concat", p.OutputFormat)
 	}
+	for i, segment := range p.Segments {
+		if segment.JobID == "" {
+			return fmt.Errorf("segment %d: job_id is required", i+1)
+		}
+		if _, _, err := segmentBounds(segment); err != nil {
+			return fmt.Errorf("segment %d: %w", i+1, err)
+		}
+	}
 	return nil
 }
 
+// segmentBounds returns a concat segment's start and end in seconds.
+func segmentBounds(segment domain.ConcatSegment) (float64, float64, error) {
+	start, err := parseTimecode(segment.StartTime)
+	if err != nil {
+		return 0, 0, fmt.Errorf("start_time: %w", err)
+	}
+	end, err := parseTimecode(segment.EndTime)
+	if err != nil {
+		return 0, 0, fmt.Errorf("end_time: %w", err)
+	}
+	if end <= start {
+		return 0, 0, fmt.Errorf("end_time must be greater than start_time")
+	}
+	return start, end, nil
+}
+
 func validateConvert(p *domain.ConvertParameters) error {
 	if p.OutputFormat == "" {
 		return fmt.Errorf("output_format is required")
@@ -36,6 +36,10 @@ type MediaInfo struct {
 	AudioCodec string
 	HasVideo   bool
 	HasAudio   bool
+	// Subtitles and Attachments count the subtitle and attachment (e.g.
+	// font) streams.
+	Subtitles   int
+	Attachments int
 }
 
 func NewFFmpeg() *FFmpeg {
@@ -124,6 +128,10 @@ func parseProbeOutput(data []byte) (*MediaInfo, error) {
 				info.HasAudio = true
 				info.AudioCodec = s.CodecName
 			}
+		case "subtitle":
+			info.Subtitles++
+		case "attachment":
+			info.Attachments++
 		}
 	}
 	return info, nil
@@ -155,6 +163,26 @@ func (f *FFmpeg) ExtractPoster(ctx context.Context, input, output string, seek f
 	return nil
 }
 
+// ResizeImage writes a JPEG copy of the image at input scaled to width pixels
+// (height keeps the aspect ratio). Used for thumbnail variants; the input may
+// be any format ffmpeg decodes (jpg, webp, png).
+func (f *FFmpeg) ResizeImage(ctx context.Context, input, output string, width int) error {
+	args := []string{
+		"-hide_banner", "-nostdin",
+		"-i", input,
+		"-frames:v", "1",
+		"-vf", fmt.Sprintf("scale=%d:-2", width),
+		"-q:v", "4",
+		"-f", "image2",
+		"-y", output,
+	}
+	out, err := exec.CommandContext(ctx, f.ffmpegPath, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("resize image %s: %w: %s", input, err, tailOf(string(out)))
+	}
+	return nil
+}
+
 // tailOf returns the last few lines of ffmpeg output for error messages.
 func tailOf(output string) string {
 	lines := strings.Split(strings.TrimSpace(output), "\n")
@@ -48,7 +48,10 @@ func TestParseProbeOutput(t *testing.T) {
 		"format": {"duration": "123.45", "bit_rate": "5000000"},
 		"streams": [
 			{"codec_type": "video", "codec_name": "h264", "width": 1920, "height": 1080},
-			{"codec_type": "audio", "codec_name": "aac"}
+			{"codec_type": "audio", "codec_name": "aac"},
+			{"codec_type": "subtitle", "codec_name": "ass"},
+			{"codec_type": "subtitle", "codec_name": "subrip"},
+			{"codec_type": "attachment", "codec_name": "ttf"}
 		]
 	}`)
 
@@ -56,6 +59,9 @@ func TestParseProbeOutput(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
+	if info.Subtitles != 2 || info.Attachments != 1 {
+		t.Errorf("subtitles, attachments = %d, %d; want 2, 1", info.Subtitles, info.Attachments)
+	}
 	if info.Duration != 123.45 {
 		t.Errorf("duration = %v, want 123.45", info.Duration)
 	}
@@ -264,7 +264,7 @@ func outputExtension(op domain.ToolsOperationType, params map[string]any) string
 			return format
 		}
 		return "mp3"
-	case domain.OpTypeConvert, domain.OpTypeConcat:
+	case domain.OpTypeConvert, domain.OpTypeConcat, domain.OpTypeRemux:
 		if format, ok := params["output_format"].(string); ok && format != "" {
 			return format
 		}
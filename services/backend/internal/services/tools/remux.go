@@ -0,0 +1,187 @@
+package tools
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"video-archiver/internal/domain"
+)
+
+// remuxFormats are the containers a remux can write.
+var remuxFormats = []string{"mp4", "mov", "mkv"}
+
+func validateRemux(p *domain.RemuxParameters) error {
+	if p.OutputFormat != "" && !contains(remuxFormats, p.OutputFormat) {
+		return fmt.Errorf("unsupported output_format %q for remux", p.OutputFormat)
+	}
+	return nil
+}
+
+// validateRemuxInputs requires a single video when the original is replaced.
+func validateRemuxInputs(job *domain.ToolsJob) error {
+	p, err := parseParameters[domain.RemuxParameters](job.Parameters)
+	if err != nil {
+		return err
+	}
+	if p.ReplaceOriginal && ((job.InputType != domain.InputTypeVideos && job.InputType != "") || len(job.InputFiles) != 1) {
+		return fmt.Errorf("replace_original requires a single video")
+	}
+	return nil
+}
+
+// buildRemuxArgs copies the streams without re-encoding. mkv keeps the
+// subtitles and attachments too; mp4 and mov can't hold most of what mkv and
+// webm carry, so they get the video and audio only.
+func buildRemuxArgs(input string, p *domain.RemuxParameters) ([]string, error) {
+	if err := validateRemux(p); err != nil {
+		return nil, err
+	}
+	args := []string{"-i", input, "-map", "0:V?", "-map", "0:a?"}
+	if p.OutputFormat == "mkv" {
+		args = append(args, "-map", "0:s?", "-map", "0:t?", "-c", "copy")
+	} else {
+		args = append(args, "-c", "copy", "-movflags", "+faststart")
+	}
+	return args, nil
+}
+
+// checkReplaceable refuses a remux replacing the original when the target
+// container would drop streams of it, which would then be lost for good.
+func checkReplaceable(info *MediaInfo, format string) error {
+	if format == "mkv" {
+		return nil
+	}
+	if info.Subtitles > 0 || info.Attachments > 0 {
+		return fmt.Errorf("replace_original to %s would drop %d subtitle and %d attachment streams; remux to mkv or keep the original",
+			format, info.Subtitles, info.Attachments)
+	}
+	return nil
+}
+
+// replacesOriginal reports whether job is a remux swapping the downloaded
+// file for its output instead of writing to processedPath.
+func replacesOriginal(job *domain.ToolsJob) bool {
+	return job.OperationType == domain.OpTypeRemux && !job.ProducesPlaybackCopy()
+}
+
+// replaceTempPath is where a remux replacing original writes: hidden, next to
+// the original, so the swap is a rename on the same filesystem.
+func replaceTempPath(original, format, jobID string) string {
+	shortID := jobID
+	if len(shortID) > 8 {
+		shortID = shortID[:8]
+	}
+	stem := strings.TrimSuffix(filepath.Base(original), filepath.Ext(original))
+	return filepath.Join(filepath.Dir(original), fmt.Sprintf(".%s.remux-%s.%s", stem, shortID, format))
+}
+
+// remuxInPlace remuxes the job's single input next to it and swaps the
+// result in for the original, returning the new path of the download.
+func (s *Service) remuxInPlace(ctx context.Context, job *domain.ToolsJob, inputs []resolvedInput) (string, error) {
+	if len(inputs) != 1 {
+		return "", fmt.Errorf("replace_original requires a single video")
+	}
+	format := outputExtension(job.OperationType, job.Parameters)
+	info, err := s.ffmpeg.Probe(inputs[0].path)
+	if err != nil {
+		return "", err
+	}
+	if err := checkReplaceable(info, format); err != nil {
+		return "", err
+	}
+	temp := replaceTempPath(inputs[0].path, format, job.ID)
+	if err := s.runOperation(ctx, job, job.OperationType, job.Parameters, inputs, temp, s.progressCallback(job, stepLabel(job.OperationType))); err != nil {
+		_ = os.Remove(temp)
+		return "", err
+	}
+	return s.swapOriginal(inputs[0], temp, format)
+}
+
+// swapOriginal replaces the downloaded file of input with the remuxed temp
+// file and returns the new path. The rename is atomic, so players and other
+// jobs see either the old file or the new one. A different container gets a
+// new extension; the job's file path is updated and the old file removed, or
+// the new file removed again when the update fails.
+func (s *Service) swapOriginal(input resolvedInput, temp, format string) (string, error) {
+	target := strings.TrimSuffix(input.path, filepath.Ext(input.path)) + "." + format
+	if target != input.path {
+		if _, err := os.Stat(target); err == nil {
+			_ = os.Remove(temp)
+			return "", fmt.Errorf("replace original: %s already exists", filepath.Base(target))
+		}
+	}
+	if err := os.Rename(temp, target); err != nil {
+		_ = os.Remove(temp)
+		return "", fmt.Errorf("replace original: %w", err)
+	}
+	if target == input.path {
+		return target, nil
+	}
+
+	if err := s.jobRepo.SetFilePath(input.jobID, target); err != nil {
+		// The original is still in place; drop the copy so the job's path
+		// stays right.
+		if rmErr := os.Remove(target); rmErr != nil {
+			log.WithError(rmErr).WithField("path", target).Warn("Failed to roll back remux")
+		}
+		return "", fmt.Errorf("update file path of %s: %w", input.jobID, err)
+	}
+	if err := os.Remove(input.path); err != nil && !os.IsNotExist(err) {
+		log.WithError(err).WithField("path", input.path).Warn("Failed to remove original after remux")
+	}
+	return target, nil
+}
+
+// IsFaststart reports whether an mp4/mov file has its index (the moov box)
+// ahead of the media data (mdat), so playback can start before the whole file
+// has been fetched. It walks the top-level boxes without reading their data.
+func IsFaststart(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	header := make([]byte, 16)
+	for {
+		if _, err := io.ReadFull(f, header[:8]); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return false, nil
+			}
+			return false, err
+		}
+		switch string(header[4:8]) {
+		case "moov":
+			return true, nil
+		case "mdat":
+			return false, nil
+		}
+
+		size := int64(binary.BigEndian.Uint32(header[:4]))
+		headerSize := int64(8)
+		switch size {
+		case 0:
+			// The box runs to the end of the file.
+			return false, nil
+		case 1:
+			if _, err := io.ReadFull(f, header[8:16]); err != nil {
+				return false, nil
+			}
+			size = int64(binary.BigEndian.Uint64(header[8:16]))
+			headerSize = 16
+		}
+		if size < headerSize {
+			return false, fmt.Errorf("invalid box size %d in %s", size, path)
+		}
+		if _, err := f.Seek(size-headerSize, io.SeekCurrent); err != nil {
+			return false, err
+		}
+	}
+}
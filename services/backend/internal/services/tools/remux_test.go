@@ -0,0 +1,179 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"video-archiver/internal/domain"
+	"video-archiver/internal/testutil"
+)
+
+func TestBuildRemuxArgs(t *testing.T) {
+	args, err := buildRemuxArgs("in.mkv", &domain.RemuxParameters{OutputFormat: "mp4"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !containsArg(args, "copy") || !containsArg(args, "+faststart") {
+		t.Errorf("mp4 remux should stream copy with faststart: %v", args)
+	}
+
+	mkv, err := buildRemuxArgs("in.mp4", &domain.RemuxParameters{OutputFormat: "mkv"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if containsArg(mkv, "-movflags") {
+		t.Errorf("mkv remux should not set movflags: %v", mkv)
+	}
+	if !containsArg(mkv, "0:s?") || !containsArg(mkv, "0:t?") {
+		t.Errorf("mkv remux should keep subtitles and attachments: %v", mkv)
+	}
+	if containsArg(args, "0:s?") {
+		t.Errorf("mp4 remux should not map subtitles: %v", args)
+	}
+
+	if _, err := buildRemuxArgs("in.mkv", &domain.RemuxParameters{OutputFormat: "webm"}); err == nil {
+		t.Error("expected error for webm output")
+	}
+}
+
+func TestCheckReplaceable(t *testing.T) {
+	subtitled := &MediaInfo{HasVideo: true, Subtitles: 1}
+	if err := checkReplaceable(subtitled, "mp4"); err == nil {
+		t.Error("expected replacing a subtitled video with an mp4 to be refused")
+	}
+	if err := checkReplaceable(&MediaInfo{HasVideo: true, Attachments: 2}, "mov"); err == nil {
+		t.Error("expected replacing a video with attachments with a mov to be refused")
+	}
+	if err := checkReplaceable(subtitled, "mkv"); err != nil {
+		t.Errorf("mkv keeps subtitles, got %v", err)
+	}
+	if err := checkReplaceable(&MediaInfo{HasVideo: true, HasAudio: true}, "mp4"); err != nil {
+		t.Errorf("plain video to mp4: %v", err)
+	}
+}
+
+func TestIsFaststart(t *testing.T) {
+	box := func(kind string, size int) []byte {
+		b := make([]byte, size)
+		binary.BigEndian.PutUint32(b, uint32(size))
+		copy(b[4:], kind)
+		return b
+	}
+	largeBox := func(kind string, size int) []byte {
+		b := make([]byte, size)
+		binary.BigEndian.PutUint32(b, 1)
+		copy(b[4:], kind)
+		binary.BigEndian.PutUint64(b[8:], uint64(size))
+		return b
+	}
+	tests := []struct {
+		name  string
+		boxes [][]byte
+		want  bool
+	}{
+		{"moov first", [][]byte{box("ftyp", 24), box("moov", 64), box("mdat", 128)}, true},
+		{"moov last", [][]byte{box("ftyp", 24), box("mdat", 128), box("moov", 64)}, false},
+		{"64-bit box before moov", [][]byte{box("ftyp", 24), largeBox("free", 40), box("moov", 16)}, true},
+		{"no moov", [][]byte{box("ftyp", 24)}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "video.mp4")
+			if err := os.WriteFile(path, bytes.Join(tt.boxes, nil), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := IsFaststart(path)
+			if err != nil {
+				t.Fatalf("IsFaststart: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("IsFaststart = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSwapOriginal(t *testing.T) {
+	jobRepo := testutil.NewMockJobRepository()
+	svc, _, _ := newTestService(t, jobRepo)
+	job := testutil.CreateTestJob("v1", "url")
+	_ = jobRepo.Create(job)
+
+	original := filepath.Join(svc.downloadPath, "video.mkv")
+	temp := replaceTempPath(original, "mp4", "0123456789abcdef")
+	if temp != filepath.Join(svc.downloadPath, ".video.remux-01234567.mp4") {
+		t.Errorf("replaceTempPath = %q", temp)
+	}
+	for path, data := range map[string]string{original: "mkv", temp: "mp4"} {
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := svc.swapOriginal(resolvedInput{jobID: "v1", path: original}, temp, "mp4")
+	if err != nil {
+		t.Fatalf("swapOriginal: %v", err)
+	}
+	want := filepath.Join(svc.downloadPath, "video.mp4")
+	if got != want {
+		t.Errorf("swapOriginal = %q, want %q", got, want)
+	}
+	if data, _ := os.ReadFile(want); string(data) != "mp4" {
+		t.Errorf("swapped file holds %q", data)
+	}
+	for _, gone := range []string{original, temp} {
+		if _, err := os.Stat(gone); !os.IsNotExist(err) {
+			t.Errorf("%s still exists", gone)
+		}
+	}
+	if job.FilePath != want {
+		t.Errorf("job file path = %q, want %q", job.FilePath, want)
+	}
+
+	// Another file already holding the new name is left alone.
+	other := filepath.Join(svc.downloadPath, "other.webm")
+	taken := filepath.Join(svc.downloadPath, "other.mp4")
+	temp = replaceTempPath(other, "mp4", "job")
+	for _, path := range []string{other, taken, temp} {
+		if err := os.WriteFile(path, []byte(path), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := svc.swapOriginal(resolvedInput{jobID: "v1", path: other}, temp, "mp4"); err == nil {
+		t.Error("expected error when the new name is taken")
+	}
+	if data, _ := os.ReadFile(taken); string(data) != taken {
+		t.Error("existing file was overwritten")
+	}
+	if _, err := os.Stat(temp); !os.IsNotExist(err) {
+		t.Error("temp file left behind")
+	}
+}
+
+func TestSwapOriginalRollsBackWhenPathUpdateFails(t *testing.T) {
+	svc, _, _ := newTestService(t, testutil.NewMockJobRepository())
+
+	original := filepath.Join(svc.downloadPath, "video.mkv")
+	temp := replaceTempPath(original, "mp4", "job")
+	for path, data := range map[string]string{original: "mkv", temp: "mp4"} {
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The job is unknown, so updating its file path fails.
+	if _, err := svc.swapOriginal(resolvedInput{jobID: "missing", path: original}, temp, "mp4"); err == nil {
+		t.Fatal("expected error when the file path update fails")
+	}
+	if data, _ := os.ReadFile(original); string(data) != "mkv" {
+		t.Errorf("original holds %q, want it untouched", data)
+	}
+	for _, gone := range []string{temp, filepath.Join(svc.downloadPath, "video.mp4")} {
+		if _, err := os.Stat(gone); !os.IsNotExist(err) {
+			t.Errorf("%s left behind", gone)
+		}
+	}
+}
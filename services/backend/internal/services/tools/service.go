@@ -2,6 +2,7 @@ package tools
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -30,6 +31,8 @@ type Config struct {
 	DownloadPath         string
 	ProcessedPath        string
 	Concurrency          int
+	// Notifier receives lifecycle events for webhooks; optional.
+	Notifier domain.Notifier
 }
 
 type Service struct {
@@ -37,6 +40,7 @@ type Service struct {
 	jobRepo        domain.JobRepository
 	collectionRepo domain.CollectionRepository
 	broadcaster    Broadcaster
+	notifier       domain.Notifier
 	ffmpeg         *FFmpeg
 	downloadPath   string
 	processedPath  string
@@ -77,6 +81,7 @@ func NewService(config *Config) *Service {
 		jobRepo:        config.JobRepository,
 		collectionRepo: config.CollectionRepository,
 		broadcaster:    config.Broadcaster,
+		notifier:       config.Notifier,
 		ffmpeg:         config.FFmpeg,
 		downloadPath:   config.DownloadPath,
 		processedPath:  config.ProcessedPath,
@@ -128,6 +133,10 @@ func (s *Service) ResolveOutputFile(job *domain.ToolsJob) (string, error) {
 	return path, nil
 }
 
+// ErrQueueFull is returned by Submit while the queue has no room; callers
+// may try again later.
+var ErrQueueFull = errors.New("tools queue is full")
+
 // Submit validates and enqueues a job. Validation happens here so the API can
 // reject bad requests synchronously instead of failing asynchronously.
 func (s *Service) Submit(job *domain.ToolsJob) error {
@@ -154,7 +163,12 @@ func (s *Service) Submit(job *domain.ToolsJob) error {
 	select {
 	case s.queue <- job:
 	default:
-		return fmt.Errorf("tools queue is full")
+		// Nothing would ever process the record; drop it so a retry starts
+		// clean.
+		if err := s.toolsRepo.Delete(job.ID); err != nil {
+			log.WithError(err).WithField("job_id", job.ID).Warn("Failed to remove rejected tools job")
+		}
+		return ErrQueueFull
 	}
 
 	log.WithFields(log.Fields{"job_id": job.ID, "operation": job.OperationType}).Info("Tools job submitted")
@@ -257,6 +271,8 @@ func (s *Service) processJob(ctx context.Context, job *domain.ToolsJob) {
 	var outputPath string
 	if job.OperationType == domain.OpTypeWorkflow {
 		outputPath, err = s.executeWorkflow(ctx, job, inputs)
+	} else if replacesOriginal(job) {
+		outputPath, err = s.remuxInPlace(ctx, job, inputs)
 	} else {
 		outputPath = generateOutputPath(s.processedPath, job.OperationType, job.ID, job.Parameters)
 		err = s.runOperation(ctx, job, job.OperationType, job.Parameters, inputs, outputPath, s.progressCallback(job, stepLabel(job.OperationType)))
@@ -287,12 +303,19 @@ func (s *Service) processJob(ctx context.Context, job *domain.ToolsJob) {
 	}
 	// Warm the poster image so the frontend gets it on first paint. Best
 	// effort — the thumbnail endpoint regenerates lazily if this fails.
-	if job.MediaKind == domain.MediaKindVideo {
+	if job.MediaKind == domain.MediaKindVideo && !replacesOriginal(job) {
 		if _, err := s.ThumbnailPath(job); err != nil {
 			log.WithError(err).WithField("job_id", job.ID).Warn("Failed to pre-generate thumbnail")
 		}
 	}
 	s.broadcast(job, 100, "Complete", 0, 0)
+	s.notify(domain.WebhookEventToolsCompleted, domain.ToolsProgressUpdate{
+		Type:        "tools-progress",
+		JobID:       job.ID,
+		Status:      job.Status,
+		Progress:    100,
+		CurrentStep: "Complete",
+	})
 	log.WithFields(log.Fields{"job_id": job.ID, "output": outputPath}).Info("Tools job completed")
 }
 
@@ -447,14 +470,25 @@ func (s *Service) prepareOperation(op domain.ToolsOperationType, params map[stri
 		if perr != nil {
 			return nil, 0, nil, perr
 		}
-		ordered := orderConcatInputs(inputs, p.FileOrder)
+		var entries []concatEntry
+		if len(p.Segments) > 0 {
+			entries, totalDuration, err = segmentEntries(inputs, p.Segments)
+			if err != nil {
+				return nil, 0, nil, err
+			}
+		} else {
+			ordered := orderConcatInputs(inputs, p.FileOrder)
+			for _, in := range ordered {
+				entries = append(entries, concatEntry{path: in.path})
+			}
+			totalDuration = s.sumDurations(ordered)
+		}
 		var listFile string
-		listFile, cleanup, err = s.writeConcatList(ordered)
+		listFile, cleanup, err = s.writeConcatList(entries)
 		if err != nil {
 			return nil, 0, cleanup, err
 		}
 		args, err = buildConcatArgs(listFile, p)
-		totalDuration = s.sumDurations(ordered)
 
 	case domain.OpTypeConvert:
 		p, perr := parseParameters[domain.ConvertParameters](params)
@@ -488,6 +522,14 @@ func (s *Service) prepareOperation(op domain.ToolsOperationType, params map[stri
 		args, err = buildRotateArgs(primary, p)
 		totalDuration = s.probeDuration(primary)
 
+	case domain.OpTypeRemux:
+		p, perr := parseParameters[domain.RemuxParameters](params)
+		if perr != nil {
+			return nil, 0, nil, perr
+		}
+		args, err = buildRemuxArgs(primary, p)
+		totalDuration = s.probeDuration(primary)
+
 	default:
 		return nil, 0, nil, fmt.Errorf("unsupported operation type: %q", op)
 	}
@@ -538,20 +580,55 @@ func orderConcatInputs(inputs []resolvedInput, fileOrder []string) []resolvedInp
 	return ordered
 }
 
+// concatEntry is a file of a concat list, cut to [inpoint, outpoint] when
+// outpoint is set.
+type concatEntry struct {
+	path              string
+	inpoint, outpoint float64
+}
+
+// segmentEntries maps concat segments to entries of their input files and
+// returns their total duration.
+func segmentEntries(inputs []resolvedInput, segments []domain.ConcatSegment) ([]concatEntry, float64, error) {
+	paths := make(map[string]string, len(inputs))
+	for _, in := range inputs {
+		paths[in.jobID] = in.path
+	}
+	entries := make([]concatEntry, 0, len(segments))
+	var total float64
+	for i, segment := range segments {
+		path, ok := paths[segment.JobID]
+		if !ok {
+			return nil, 0, fmt.Errorf("segment %d: video %s is not an input", i+1, segment.JobID)
+		}
+		start, end, err := segmentBounds(segment)
+		if err != nil {
+			return nil, 0, fmt.Errorf("segment %d: %w", i+1, err)
+		}
+		entries = append(entries, concatEntry{path: path, inpoint: start, outpoint: end})
+		total += end - start
+	}
+	return entries, total, nil
+}
+
 // writeConcatList writes a temporary ffmpeg concat demuxer list file.
-func (s *Service) writeConcatList(inputs []resolvedInput) (string, func(), error) {
+func (s *Service) writeConcatList(entries []concatEntry) (string, func(), error) {
 	listFile := filepath.Join(os.TempDir(), fmt.Sprintf("concat_%s.txt", uuid.New().String()))
 	cleanup := func() { _ = os.Remove(listFile) }
 
 	var b strings.Builder
-	for _, in := range inputs {
-		abs, err := filepath.Abs(in.path)
+	for _, entry := range entries {
+		abs, err := filepath.Abs(entry.path)
 		if err != nil {
 			return "", cleanup, fmt.Errorf("absolute path: %w", err)
 		}
 		// Escape single quotes for the concat demuxer syntax.
 		escaped := strings.ReplaceAll(abs, "'", `'\''`)
 		fmt.Fprintf(&b, "file '%s'\n", escaped)
+		if entry.outpoint > 0 {
+			// Without re-encoding, cuts land on the nearest keyframes.
+			fmt.Fprintf(&b, "inpoint %.3f\noutpoint %.3f\n", entry.inpoint, entry.outpoint)
+		}
 	}
 	if err := os.WriteFile(listFile, []byte(b.String()), 0o644); err != nil {
 		return "", cleanup, fmt.Errorf("write concat list: %w", err)
@@ -569,6 +646,21 @@ func (s *Service) failJob(job *domain.ToolsJob, err error) {
 		log.WithError(updateErr).Error("Failed to persist failed job")
 	}
 	s.broadcastError(job, err.Error())
+	s.notify(domain.WebhookEventToolsFailed, domain.ToolsProgressUpdate{
+		Type:        "tools-progress",
+		JobID:       job.ID,
+		Status:      job.Status,
+		Progress:    job.Progress,
+		CurrentStep: "Failed",
+		Error:       err.Error(),
+	})
+}
+
+// notify passes a lifecycle event on to the webhooks, if any are configured.
+func (s *Service) notify(event domain.WebhookEvent, data any) {
+	if s.notifier != nil {
+		s.notifier.Notify(event, data)
+	}
 }
 
 // progressCallback returns a ProgressFunc that throttles DB writes to once per
@@ -636,6 +728,8 @@ func stepLabel(op domain.ToolsOperationType) string {
 		return "Adjusting quality"
 	case domain.OpTypeRotate:
 		return "Rotating"
+	case domain.OpTypeRemux:
+		return "Remuxing"
 	default:
 		return "Processing"
 	}
@@ -1,6 +1,7 @@
 package tools
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"sync"
@@ -56,7 +57,7 @@ func (r *memToolsRepo) FindLatestConvertForInput(jobID string) (*domain.ToolsJob
 	defer r.mu.Unlock()
 	var latest *domain.ToolsJob
 	for _, job := range r.jobs {
-		if job.OperationType != domain.OpTypeConvert {
+		if !job.ProducesPlaybackCopy() {
 			continue
 		}
 		if len(job.InputFiles) != 1 || job.InputFiles[0] != jobID {
@@ -69,7 +70,24 @@ func (r *memToolsRepo) FindLatestConvertForInput(jobID string) (*domain.ToolsJob
 	}
 	return latest, nil
 }
-func (r *memToolsRepo) Delete(string) error { return nil }
+func (r *memToolsRepo) ListBySourceJob(sourceJobID string) ([]*domain.ToolsJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []*domain.ToolsJob
+	for _, job := range r.jobs {
+		if job.SourceJobID == sourceJobID {
+			cp := *job
+			out = append(out, &cp)
+		}
+	}
+	return out, nil
+}
+func (r *memToolsRepo) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.jobs, id)
+	return nil
+}
 func (r *memToolsRepo) List(int, int, string, string) ([]*domain.ToolsJob, int, error) {
 	return nil, 0, nil
 }
@@ -135,6 +153,36 @@ func TestValidateJob(t *testing.T) {
 				Parameters: map[string]any{"output_format": "mp4"},
 			},
 		},
+		{
+			name: "concat segments of one video ok",
+			job: &domain.ToolsJob{
+				OperationType: domain.OpTypeConcat, InputFiles: []string{"v1"}, InputType: domain.InputTypeVideos,
+				Parameters: map[string]any{"segments": []any{
+					map[string]any{"job_id": "v1", "start_time": "10", "end_time": "20"},
+					map[string]any{"job_id": "v1", "start_time": "40", "end_time": "45"},
+				}},
+			},
+		},
+		{
+			name: "concat segment of video not in inputs",
+			job: &domain.ToolsJob{
+				OperationType: domain.OpTypeConcat, InputFiles: []string{"v1"}, InputType: domain.InputTypeVideos,
+				Parameters: map[string]any{"segments": []any{
+					map[string]any{"job_id": "v2", "start_time": "10", "end_time": "20"},
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "concat segment ending before it starts",
+			job: &domain.ToolsJob{
+				OperationType: domain.OpTypeConcat, InputFiles: []string{"v1"}, InputType: domain.InputTypeVideos,
+				Parameters: map[string]any{"segments": []any{
+					map[string]any{"job_id": "v1", "start_time": "20", "end_time": "10"},
+				}},
+			},
+			wantErr: true,
+		},
 		{
 			name: "playlist requires single parent",
 			job: &domain.ToolsJob{
@@ -178,6 +226,39 @@ func TestValidateJob(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "remux replacing original",
+			job: &domain.ToolsJob{
+				OperationType: domain.OpTypeRemux, InputFiles: []string{"v1"}, InputType: domain.InputTypeVideos,
+				Parameters: map[string]any{"replace_original": true},
+			},
+		},
+		{
+			name: "remux replacing originals of a playlist",
+			job: &domain.ToolsJob{
+				OperationType: domain.OpTypeRemux, InputFiles: []string{"p1"}, InputType: domain.InputTypePlaylist,
+				Parameters: map[string]any{"replace_original": true},
+			},
+			wantErr: true,
+		},
+		{
+			name: "remux to webm",
+			job: &domain.ToolsJob{
+				OperationType: domain.OpTypeRemux, InputFiles: []string{"v1"},
+				Parameters: map[string]any{"output_format": "webm"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "workflow step replacing original",
+			job: &domain.ToolsJob{
+				OperationType: domain.OpTypeWorkflow, InputFiles: []string{"v1"},
+				Parameters: map[string]any{"steps": []any{
+					map[string]any{"operation": "remux", "parameters": map[string]any{"replace_original": true}},
+				}},
+			},
+			wantErr: true,
+		},
 		{
 			name: "invalid input type",
 			job: &domain.ToolsJob{
@@ -231,6 +312,27 @@ func TestSubmitValidatesAndEnqueues(t *testing.T) {
 	}
 }
 
+func TestSubmitRejectsWhenQueueIsFull(t *testing.T) {
+	svc, repo, _ := newTestService(t, testutil.NewMockJobRepository())
+
+	// Nothing drains the queue before Start.
+	for i := range cap(svc.queue) {
+		job := &domain.ToolsJob{OperationType: domain.OpTypeTrim, InputFiles: []string{"v1"},
+			Parameters: map[string]any{"start_time": "0", "end_time": "10"}}
+		if err := svc.Submit(job); err != nil {
+			t.Fatalf("Submit(%d) error = %v", i, err)
+		}
+	}
+	job := &domain.ToolsJob{OperationType: domain.OpTypeTrim, InputFiles: []string{"v1"},
+		Parameters: map[string]any{"start_time": "0", "end_time": "10"}}
+	if err := svc.Submit(job); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("Submit() error = %v, want ErrQueueFull", err)
+	}
+	if stored, _ := repo.GetByID(job.ID); stored != nil {
+		t.Error("rejected job left pending in the repository")
+	}
+}
+
 func TestCancelJob(t *testing.T) {
 	svc, repo, bc := newTestService(t, testutil.NewMockJobRepository())
 
@@ -368,8 +470,8 @@ func TestOrderConcatInputs(t *testing.T) {
 func TestWriteConcatList(t *testing.T) {
 	svc, _, _ := newTestService(t, testutil.NewMockJobRepository())
 
-	inputs := []resolvedInput{{jobID: "a", path: "/videos/a's movie.mp4"}}
-	listFile, cleanup, err := svc.writeConcatList(inputs)
+	entries := []concatEntry{{path: "/videos/a's movie.mp4"}}
+	listFile, cleanup, err := svc.writeConcatList(entries)
 	if err != nil {
 		t.Fatalf("writeConcatList: %v", err)
 	}
@@ -389,3 +491,41 @@ func TestWriteConcatList(t *testing.T) {
 		t.Error("expected list file to be removed by cleanup")
 	}
 }
+
+func TestSegmentEntries(t *testing.T) {
+	svc, _, _ := newTestService(t, testutil.NewMockJobRepository())
+
+	inputs := []resolvedInput{{jobID: "a", path: "/videos/a.mp4"}, {jobID: "b", path: "/videos/b.mp4"}}
+	segments := []domain.ConcatSegment{
+		{JobID: "b", StartTime: "00:01:00", EndTime: "70"},
+		{JobID: "a", StartTime: "5", EndTime: "7.5"},
+		{JobID: "b", StartTime: "0", EndTime: "2"},
+	}
+	entries, total, err := segmentEntries(inputs, segments)
+	if err != nil {
+		t.Fatalf("segmentEntries: %v", err)
+	}
+	if total != 14.5 {
+		t.Errorf("total duration = %v, want 14.5", total)
+	}
+
+	listFile, cleanup, err := svc.writeConcatList(entries)
+	if err != nil {
+		t.Fatalf("writeConcatList: %v", err)
+	}
+	defer cleanup()
+	data, err := os.ReadFile(listFile)
+	if err != nil {
+		t.Fatalf("read list: %v", err)
+	}
+	want := "file '/videos/b.mp4'\ninpoint 60.000\noutpoint 70.000\n" +
+		"file '/videos/a.mp4'\ninpoint 5.000\noutpoint 7.500\n" +
+		"file '/videos/b.mp4'\ninpoint 0.000\noutpoint 2.000\n"
+	if string(data) != want {
+		t.Errorf("concat list = %q, want %q", string(data), want)
+	}
+
+	if _, _, err := segmentEntries(inputs, []domain.ConcatSegment{{JobID: "c", StartTime: "0", EndTime: "1"}}); err == nil {
+		t.Error("expected error for a segment of an unknown video")
+	}
+}
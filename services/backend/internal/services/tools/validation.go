@@ -32,10 +32,16 @@ func validateJob(job *domain.ToolsJob) error {
 		}
 	}
 
-	if job.OperationType == domain.OpTypeConcat &&
-		(job.InputType == domain.InputTypeVideos || job.InputType == "") &&
-		len(job.InputFiles) < 2 {
-		return fmt.Errorf("concat requires at least two videos")
+	if job.OperationType == domain.OpTypeConcat {
+		if err := validateConcatInputs(job); err != nil {
+			return err
+		}
+	}
+
+	if job.OperationType == domain.OpTypeRemux {
+		if err := validateRemuxInputs(job); err != nil {
+			return err
+		}
 	}
 
 	if job.OperationType == domain.OpTypeWorkflow {
@@ -44,6 +50,36 @@ func validateJob(job *domain.ToolsJob) error {
 	return validateOperationParams(job.OperationType, job.Parameters)
 }
 
+// validateConcatInputs checks that a concat joins at least two videos, or
+// segments of videos listed in input_files.
+func validateConcatInputs(job *domain.ToolsJob) error {
+	p, err := parseParameters[domain.ConcatParameters](job.Parameters)
+	if err != nil {
+		return err
+	}
+	if len(p.Segments) == 0 {
+		if (job.InputType == domain.InputTypeVideos || job.InputType == "") && len(job.InputFiles) < 2 {
+			return fmt.Errorf("concat requires at least two videos")
+		}
+		return nil
+	}
+	if job.InputType != domain.InputTypeVideos && job.InputType != "" {
+		return fmt.Errorf("concat segments require videos input")
+	}
+	for i, segment := range p.Segments {
+		if !contains(job.InputFiles, segment.JobID) {
+			return fmt.Errorf("segment %d: video %s is not in input_files", i+1, segment.JobID)
+		}
+	}
+	return nil
+}
+
+// ValidateOperation checks the parameters of a job that is submitted later,
+// once per item, with the same rules Submit applies.
+func ValidateOperation(op domain.ToolsOperationType, params map[string]any) error {
+	return validateOperationParams(op, params)
+}
+
 // validateOperationParams validates the parameters of a single operation.
 func validateOperationParams(op domain.ToolsOperationType, params map[string]any) error {
 	switch op {
@@ -83,6 +119,12 @@ func validateOperationParams(op domain.ToolsOperationType, params map[string]any
 			return err
 		}
 		return validateRotate(p)
+	case domain.OpTypeRemux:
+		p, err := parseParameters[domain.RemuxParameters](params)
+		if err != nil {
+			return err
+		}
+		return validateRemux(p)
 	case domain.OpTypeWorkflow:
 		return validateWorkflowParams(params)
 	default:
@@ -102,6 +144,9 @@ func validateWorkflowParams(params map[string]any) error {
 		if step.Operation == domain.OpTypeWorkflow {
 			return fmt.Errorf("workflow step %d cannot itself be a workflow", i+1)
 		}
+		if replace, _ := step.Parameters["replace_original"].(bool); step.Operation == domain.OpTypeRemux && replace {
+			return fmt.Errorf("workflow step %d cannot replace the original", i+1)
+		}
 		if err := validateOperationParams(step.Operation, step.Parameters); err != nil {
 			return fmt.Errorf("workflow step %d (%s): %w", i+1, step.Operation, err)
 		}
@@ -2,6 +2,7 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -105,3 +106,32 @@ func (s *Service) workflowStepProgress(job *domain.ToolsJob, stepNum, totalSteps
 		s.broadcast(job, overall, step, int(elapsed.Seconds()), 0)
 	}
 }
+
+// NewWorkflowJob builds an unsubmitted workflow job that runs a saved
+// workflow on the given videos.
+func NewWorkflowJob(workflow domain.WorkflowParameters, videoIDs ...string) (*domain.ToolsJob, error) {
+	data, err := json.Marshal(workflow)
+	if err != nil {
+		return nil, fmt.Errorf("marshal workflow: %w", err)
+	}
+	var params map[string]any
+	if err := json.Unmarshal(data, &params); err != nil {
+		return nil, fmt.Errorf("unmarshal workflow: %w", err)
+	}
+	return &domain.ToolsJob{
+		OperationType: domain.OpTypeWorkflow,
+		InputFiles:    videoIDs,
+		InputType:     domain.InputTypeVideos,
+		Parameters:    params,
+	}, nil
+}
+
+// ValidateWorkflow checks a workflow that is saved to run later with the same
+// rules Submit applies to workflow jobs.
+func ValidateWorkflow(workflow domain.WorkflowParameters) error {
+	job, err := NewWorkflowJob(workflow)
+	if err != nil {
+		return err
+	}
+	return validateWorkflowParams(job.Parameters)
+}
@@ -0,0 +1,257 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"video-archiver/internal/domain"
+)
+
+// Headers sent with every delivery. The signature is the hex HMAC-SHA256 of
+// the raw request body keyed with the webhook's secret, prefixed "sha256=".
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+// defaultRetryDelays is the backoff between attempts; a delivery is given up
+// after the first attempt plus one retry per entry.
+var defaultRetryDelays = []time.Duration{
+	10 * time.Second,
+	time.Minute,
+	5 * time.Minute,
+	30 * time.Minute,
+}
+
+// requestTimeout bounds one delivery attempt.
+const requestTimeout = 10 * time.Second
+
+// maxErrorBody is how much of a failed response is kept in the delivery log.
+const maxErrorBody = 512
+
+type Config struct {
+	Repository domain.WebhookRepository
+	// Client defaults to an http.Client with requestTimeout.
+	Client *http.Client
+	// RetryDelays defaults to defaultRetryDelays.
+	RetryDelays []time.Duration
+}
+
+// Service delivers lifecycle events to registered webhooks. Every delivery is
+// logged before the first attempt and runs in its own goroutine, so Notify
+// never waits on the network; deliveries still pending when the service stops
+// are resumed on the next start.
+type Service struct {
+	repo        domain.WebhookRepository
+	client      *http.Client
+	retryDelays []time.Duration
+
+	wg     sync.WaitGroup
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func NewService(config *Config) *Service {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	client := config.Client
+	if client == nil {
+		client = &http.Client{Timeout: requestTimeout}
+	}
+	retryDelays := config.RetryDelays
+	if retryDelays == nil {
+		retryDelays = defaultRetryDelays
+	}
+
+	return &Service{
+		repo:        config.Repository,
+		client:      client,
+		retryDelays: retryDelays,
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// Start resumes the deliveries left pending by the previous run.
+func (s *Service) Start() error {
+	pending, err := s.repo.ListPendingDeliveries()
+	if err != nil {
+		return fmt.Errorf("list pending webhook deliveries: %w", err)
+	}
+	for _, delivery := range pending {
+		s.startDelivery(delivery)
+	}
+	log.WithField("resumed", len(pending)).Info("Webhook service started")
+	return nil
+}
+
+func (s *Service) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// Notify queues event for every enabled webhook subscribed to it.
+func (s *Service) Notify(event domain.WebhookEvent, data any) {
+	webhooks, err := s.repo.List()
+	if err != nil {
+		log.WithError(err).WithField("event", event).Warn("Failed to list webhooks")
+		return
+	}
+	for _, webhook := range webhooks {
+		if !webhook.Subscribes(event) {
+			continue
+		}
+		if _, err := s.send(webhook, event, data); err != nil {
+			log.WithError(err).WithFields(log.Fields{"webhookID": webhook.ID, "event": event}).
+				Warn("Failed to queue webhook delivery")
+		}
+	}
+}
+
+// SendTest queues a ping to webhook regardless of its event filter or
+// whether it is enabled, and returns the delivery to follow in the log.
+func (s *Service) SendTest(webhook *domain.Webhook) (*domain.WebhookDelivery, error) {
+	return s.send(webhook, domain.WebhookEventPing, map[string]string{"message": "Webhook test from video-archiver"})
+}
+
+// send logs a delivery of event to webhook and starts delivering it.
+func (s *Service) send(webhook *domain.Webhook, event domain.WebhookEvent, data any) (*domain.WebhookDelivery, error) {
+	delivery := &domain.WebhookDelivery{
+		WebhookID: webhook.ID,
+		Event:     event,
+		Status:    domain.WebhookDeliveryPending,
+	}
+	if err := s.repo.CreateDelivery(delivery); err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(domain.WebhookPayload{
+		Event:      event,
+		DeliveryID: delivery.ID,
+		Timestamp:  delivery.CreatedAt,
+		Data:       data,
+	})
+	if err != nil {
+		delivery.Status = domain.WebhookDeliveryFailed
+		delivery.Error = fmt.Sprintf("marshal payload: %v", err)
+		s.saveDelivery(delivery)
+		return nil, err
+	}
+	delivery.Payload = string(payload)
+	s.saveDelivery(delivery)
+
+	s.startDelivery(delivery)
+	return delivery, nil
+}
+
+func (s *Service) startDelivery(delivery *domain.WebhookDelivery) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.deliver(delivery)
+	}()
+}
+
+// deliver attempts a delivery until it succeeds, fails permanently or runs
+// out of retries, recording every attempt. A stop in between leaves it
+// pending for the next start.
+func (s *Service) deliver(delivery *domain.WebhookDelivery) {
+	for {
+		webhook, err := s.repo.GetByID(delivery.WebhookID)
+		if err != nil {
+			log.WithError(err).WithField("deliveryID", delivery.ID).Warn("Failed to load webhook for delivery")
+			return
+		}
+		if webhook == nil {
+			// Deleting a webhook removes its log; nothing left to record.
+			return
+		}
+
+		statusCode, retryable, err := s.attempt(webhook, delivery)
+		delivery.Attempts++
+		delivery.ResponseStatus = statusCode
+		delivery.Error = ""
+		if err == nil {
+			now := time.Now()
+			delivery.Status = domain.WebhookDeliveryDelivered
+			delivery.DeliveredAt = &now
+			s.saveDelivery(delivery)
+			return
+		}
+
+		delivery.Error = err.Error()
+		if !retryable || delivery.Attempts > len(s.retryDelays) {
+			delivery.Status = domain.WebhookDeliveryFailed
+			s.saveDelivery(delivery)
+			log.WithError(err).WithFields(log.Fields{"webhookID": webhook.ID, "deliveryID": delivery.ID}).
+				Warn("Webhook delivery failed")
+			return
+		}
+		s.saveDelivery(delivery)
+
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-time.After(s.retryDelays[delivery.Attempts-1]):
+		}
+	}
+}
+
+// attempt POSTs the signed payload once. Network errors, 408, 429 and 5xx
+// responses are worth retrying; other non-2xx responses are not.
+func (s *Service) attempt(webhook *domain.Webhook, delivery *domain.WebhookDelivery) (int, bool, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, false, fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "video-archiver-webhooks")
+	req.Header.Set(EventHeader, string(delivery.Event))
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, resp.Body)
+		return resp.StatusCode, false, nil
+	}
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	err = fmt.Errorf("HTTP %d: %s", resp.StatusCode, bytes.TrimSpace(snippet))
+	retryable := resp.StatusCode == http.StatusRequestTimeout ||
+		resp.StatusCode == http.StatusTooManyRequests ||
+		resp.StatusCode >= 500
+	return resp.StatusCode, retryable, err
+}
+
+func (s *Service) saveDelivery(delivery *domain.WebhookDelivery) {
+	if err := s.repo.UpdateDelivery(delivery); err != nil {
+		log.WithError(err).WithField("deliveryID", delivery.ID).Warn("Failed to update webhook delivery")
+	}
+}
+
+// Sign returns the signature header value for body: "sha256=" followed by
+// the hex HMAC-SHA256 keyed with secret. Receivers recompute it over the raw
+// body and compare in constant time.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,203 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"video-archiver/internal/domain"
+	"video-archiver/internal/repositories/sqlite"
+	"video-archiver/internal/testutil"
+)
+
+func newTestService(t *testing.T) (*Service, *sqlite.WebhookRepository) {
+	t.Helper()
+	db := testutil.CreateTestDB(t)
+	t.Cleanup(func() { db.Close() })
+	repo := sqlite.NewWebhookRepository(db)
+	service := NewService(&Config{
+		Repository:  repo,
+		RetryDelays: []time.Duration{10 * time.Millisecond, 10 * time.Millisecond},
+	})
+	t.Cleanup(service.Stop)
+	return service, repo
+}
+
+func createWebhook(t *testing.T, repo *sqlite.WebhookRepository, id, url string, events ...domain.WebhookEvent) *domain.Webhook {
+	t.Helper()
+	now := time.Now()
+	webhook := &domain.Webhook{ID: id, URL: url, Secret: "s3cret", Events: events, Enabled: true, CreatedAt: now, UpdatedAt: now}
+	if err := repo.Create(webhook); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	return webhook
+}
+
+// waitForDeliveries polls the delivery log until n deliveries of webhookID
+// are no longer pending.
+func waitForDeliveries(t *testing.T, repo *sqlite.WebhookRepository, webhookID string, n int) []*domain.WebhookDelivery {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		deliveries, err := repo.ListDeliveries(webhookID, 100)
+		if err != nil {
+			t.Fatalf("ListDeliveries() error = %v", err)
+		}
+		done := 0
+		for _, d := range deliveries {
+			if d.Status != domain.WebhookDeliveryPending {
+				done++
+			}
+		}
+		if done >= n {
+			return deliveries
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("deliveries did not finish: %+v", deliveries)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestNotify_DeliversSignedPayload(t *testing.T) {
+	service, repo := newTestService(t)
+
+	received := make(chan *http.Request, 1)
+	var body []byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		received <- r
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	createWebhook(t, repo, "hook-1", receiver.URL, domain.WebhookEventDownloadCompleted)
+	createWebhook(t, repo, "hook-other", receiver.URL, domain.WebhookEventToolsFailed)
+
+	service.Notify(domain.WebhookEventDownloadCompleted, domain.ProgressUpdate{
+		JobID:    "job-1",
+		JobType:  "video",
+		Status:   domain.JobStatusComplete,
+		Progress: 100,
+	})
+
+	var req *http.Request
+	select {
+	case req = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not called")
+	}
+	if got := req.Header.Get(EventHeader); got != string(domain.WebhookEventDownloadCompleted) {
+		t.Errorf("event header = %q", got)
+	}
+	if got, want := req.Header.Get(SignatureHeader), Sign("s3cret", body); got != want {
+		t.Errorf("signature = %q, want %q", got, want)
+	}
+
+	var payload struct {
+		Event      domain.WebhookEvent   `json:"event"`
+		DeliveryID int64                 `json:"delivery_id"`
+		Data       domain.ProgressUpdate `json:"data"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("unmarshal payload: %v", err)
+	}
+	if payload.Event != domain.WebhookEventDownloadCompleted || payload.Data.JobID != "job-1" || payload.Data.Status != domain.JobStatusComplete {
+		t.Errorf("payload = %+v", payload)
+	}
+
+	deliveries := waitForDeliveries(t, repo, "hook-1", 1)
+	if deliveries[0].Status != domain.WebhookDeliveryDelivered || deliveries[0].ResponseStatus != http.StatusNoContent || deliveries[0].ID != payload.DeliveryID {
+		t.Errorf("delivery = %+v", deliveries[0])
+	}
+	if other, _ := repo.ListDeliveries("hook-other", 10); len(other) != 0 {
+		t.Errorf("unsubscribed webhook got deliveries: %+v", other)
+	}
+}
+
+func TestNotify_RetriesWithBackoff(t *testing.T) {
+	service, repo := newTestService(t)
+
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+
+	createWebhook(t, repo, "hook-1", receiver.URL)
+	service.Notify(domain.WebhookEventToolsFailed, domain.ToolsProgressUpdate{Type: "tools-progress", JobID: "tools-1"})
+
+	delivery := waitForDeliveries(t, repo, "hook-1", 1)[0]
+	if delivery.Status != domain.WebhookDeliveryDelivered || delivery.Attempts != 3 {
+		t.Errorf("delivery = %+v, want delivered on the third attempt", delivery)
+	}
+}
+
+func TestNotify_GivesUp(t *testing.T) {
+	service, repo := newTestService(t)
+
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.Header.Get(EventHeader) == string(domain.WebhookEventDownloadFailed) {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "down", http.StatusBadGateway)
+	}))
+	defer receiver.Close()
+
+	createWebhook(t, repo, "hook-1", receiver.URL, domain.WebhookEventDownloadFailed)
+	createWebhook(t, repo, "hook-2", receiver.URL, domain.WebhookEventDownloadCancelled)
+
+	service.Notify(domain.WebhookEventDownloadFailed, domain.ProgressUpdate{JobID: "job-1"})
+	service.Notify(domain.WebhookEventDownloadCancelled, domain.ProgressUpdate{JobID: "job-2"})
+
+	rejected := waitForDeliveries(t, repo, "hook-1", 1)[0]
+	if rejected.Status != domain.WebhookDeliveryFailed || rejected.Attempts != 1 || rejected.ResponseStatus != http.StatusBadRequest {
+		t.Errorf("rejected delivery = %+v, want one attempt", rejected)
+	}
+	exhausted := waitForDeliveries(t, repo, "hook-2", 1)[0]
+	if exhausted.Status != domain.WebhookDeliveryFailed || exhausted.Attempts != 3 || exhausted.Error == "" {
+		t.Errorf("exhausted delivery = %+v, want three attempts", exhausted)
+	}
+}
+
+func TestStart_ResumesPendingDeliveries(t *testing.T) {
+	service, repo := newTestService(t)
+
+	received := make(chan struct{}, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- struct{}{}
+	}))
+	defer receiver.Close()
+
+	createWebhook(t, repo, "hook-1", receiver.URL)
+	repo.CreateDelivery(&domain.WebhookDelivery{
+		WebhookID: "hook-1",
+		Event:     domain.WebhookEventVideoRemoved,
+		Payload:   `{"event":"video.removed"}`,
+		Status:    domain.WebhookDeliveryPending,
+		Attempts:  1,
+	})
+
+	if err := service.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	select {
+	case <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("pending delivery was not resumed")
+	}
+	if delivery := waitForDeliveries(t, repo, "hook-1", 1)[0]; delivery.Attempts != 2 {
+		t.Errorf("attempts = %d, want 2", delivery.Attempts)
+	}
+}
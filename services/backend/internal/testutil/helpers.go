@@ -2,6 +2,9 @@ package testutil
 
 import (
 	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 	"video-archiver/internal/domain"
@@ -28,6 +31,9 @@ func CreateTestDB(t *testing.T) *sql.DB {
 		media_type TEXT NOT NULL DEFAULT 'video',
 		warnings TEXT,
 		file_path TEXT,
+		archive_comments BOOLEAN,
+		custom_quality INTEGER,
+		live BOOLEAN NOT NULL DEFAULT 0,
 		created_at DATETIME NOT NULL,
 		updated_at DATETIME NOT NULL
 	);
@@ -70,6 +76,7 @@ func CreateTestDB(t *testing.T) *sql.DB {
 		id TEXT PRIMARY KEY,
 		name TEXT NOT NULL,
 		description TEXT NOT NULL DEFAULT '',
+		query TEXT NOT NULL DEFAULT '',
 		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	);
@@ -87,7 +94,9 @@ func CreateTestDB(t *testing.T) *sql.DB {
 	CREATE TABLE IF NOT EXISTS tags (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		name TEXT NOT NULL UNIQUE COLLATE NOCASE,
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		color TEXT NOT NULL DEFAULT '',
+		parent_id INTEGER REFERENCES tags (id)
 	);
 
 	CREATE TABLE IF NOT EXISTS job_tags (
@@ -99,6 +108,247 @@ func CreateTestDB(t *testing.T) *sql.DB {
 		FOREIGN KEY (job_id) REFERENCES jobs (job_id),
 		FOREIGN KEY (tag_id) REFERENCES tags (id)
 	);
+
+	CREATE TABLE IF NOT EXISTS channel_snapshots (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		channel_id TEXT NOT NULL,
+		job_id TEXT NOT NULL,
+		description TEXT NOT NULL DEFAULT '',
+		follower_count INTEGER NOT NULL DEFAULT 0,
+		captured_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (job_id) REFERENCES jobs (job_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS comments (
+		job_id TEXT NOT NULL,
+		comment_id TEXT NOT NULL,
+		parent_id TEXT NOT NULL DEFAULT '',
+		author TEXT NOT NULL DEFAULT '',
+		author_id TEXT NOT NULL DEFAULT '',
+		author_is_uploader BOOLEAN NOT NULL DEFAULT 0,
+		text TEXT NOT NULL DEFAULT '',
+		like_count INTEGER NOT NULL DEFAULT 0,
+		timestamp INTEGER NOT NULL DEFAULT 0,
+		is_pinned BOOLEAN NOT NULL DEFAULT 0,
+		is_hearted BOOLEAN NOT NULL DEFAULT 0,
+		PRIMARY KEY (job_id, comment_id),
+		FOREIGN KEY (job_id) REFERENCES jobs (job_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS metadata_snapshots (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		job_id TEXT NOT NULL,
+		title TEXT NOT NULL DEFAULT '',
+		description TEXT NOT NULL DEFAULT '',
+		view_count INTEGER NOT NULL DEFAULT 0,
+		like_count INTEGER NOT NULL DEFAULT 0,
+		comment_count INTEGER NOT NULL DEFAULT 0,
+		captured_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (job_id) REFERENCES jobs (job_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS refresh_schedules (
+		job_id TEXT PRIMARY KEY,
+		interval_hours INTEGER NOT NULL,
+		last_refreshed_at TIMESTAMP,
+		next_refresh_at TIMESTAMP NOT NULL,
+		refresh_cursor TEXT NOT NULL DEFAULT '',
+		FOREIGN KEY (job_id) REFERENCES jobs (job_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS video_availability (
+		job_id TEXT PRIMARY KEY,
+		status TEXT NOT NULL,
+		reason TEXT NOT NULL DEFAULT '',
+		first_seen_at TIMESTAMP NOT NULL,
+		checked_at TIMESTAMP NOT NULL,
+		FOREIGN KEY (job_id) REFERENCES jobs (job_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS playlist_snapshots (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		source_id TEXT NOT NULL,
+		job_id TEXT NOT NULL,
+		item_count INTEGER NOT NULL DEFAULT 0,
+		added_count INTEGER NOT NULL DEFAULT 0,
+		removed_count INTEGER NOT NULL DEFAULT 0,
+		renamed_count INTEGER NOT NULL DEFAULT 0,
+		reordered_count INTEGER NOT NULL DEFAULT 0,
+		captured_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (job_id) REFERENCES jobs (job_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS playlist_snapshot_items (
+		snapshot_id INTEGER NOT NULL,
+		position INTEGER NOT NULL,
+		video_id TEXT NOT NULL,
+		title TEXT NOT NULL DEFAULT '',
+		PRIMARY KEY (snapshot_id, position),
+		FOREIGN KEY (snapshot_id) REFERENCES playlist_snapshots (id)
+	);
+
+	CREATE TABLE IF NOT EXISTS channel_download_options (
+		job_id TEXT PRIMARY KEY,
+		options_json TEXT NOT NULL,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (job_id) REFERENCES jobs (job_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS upgrade_runs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		status TEXT NOT NULL,
+		dry_run BOOLEAN NOT NULL DEFAULT 0,
+		target_height INTEGER NOT NULL,
+		candidates INTEGER NOT NULL DEFAULT 0,
+		checked INTEGER NOT NULL DEFAULT 0,
+		upgraded INTEGER NOT NULL DEFAULT 0,
+		failed INTEGER NOT NULL DEFAULT 0,
+		bytes_before INTEGER NOT NULL DEFAULT 0,
+		bytes_after INTEGER NOT NULL DEFAULT 0,
+		started_at TIMESTAMP NOT NULL,
+		finished_at TIMESTAMP
+	);
+	CREATE TABLE IF NOT EXISTS upgrade_items (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		run_id INTEGER NOT NULL,
+		job_id TEXT NOT NULL,
+		title TEXT NOT NULL DEFAULT '',
+		outcome TEXT NOT NULL,
+		old_height INTEGER NOT NULL DEFAULT 0,
+		new_height INTEGER NOT NULL DEFAULT 0,
+		old_codec TEXT NOT NULL DEFAULT '',
+		new_codec TEXT NOT NULL DEFAULT '',
+		old_size INTEGER NOT NULL DEFAULT 0,
+		new_size INTEGER NOT NULL DEFAULT 0,
+		error TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP NOT NULL,
+		FOREIGN KEY (run_id) REFERENCES upgrade_runs (id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_upgrade_items_run ON upgrade_items(run_id);
+	CREATE INDEX IF NOT EXISTS idx_upgrade_items_job ON upgrade_items(job_id);
+
+	CREATE TABLE IF NOT EXISTS webhooks (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL DEFAULT '',
+		url TEXT NOT NULL,
+		secret TEXT NOT NULL,
+		events_json TEXT NOT NULL DEFAULT '[]',
+		enabled BOOLEAN NOT NULL DEFAULT 1,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS webhook_deliveries (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		webhook_id TEXT NOT NULL,
+		event TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		status TEXT NOT NULL,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		response_status INTEGER NOT NULL DEFAULT 0,
+		error TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		delivered_at TIMESTAMP,
+		FOREIGN KEY (webhook_id) REFERENCES webhooks (id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook ON webhook_deliveries(webhook_id, id);
+	CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_status ON webhook_deliveries(status);
+
+	CREATE TABLE IF NOT EXISTS automation_rules (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		enabled BOOLEAN NOT NULL DEFAULT 1,
+		match_json TEXT NOT NULL DEFAULT '{}',
+		workflow_json TEXT NOT NULL,
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS tag_rules (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		enabled BOOLEAN NOT NULL DEFAULT 1,
+		match_json TEXT NOT NULL DEFAULT '{}',
+		tags_json TEXT NOT NULL DEFAULT '[]',
+		collection_id TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS tag_synonyms (
+		alias TEXT PRIMARY KEY COLLATE NOCASE,
+		canonical TEXT NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS tag_blocklist (
+		name TEXT PRIMARY KEY COLLATE NOCASE
+	);
+
+	CREATE TABLE IF NOT EXISTS bulk_operations (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		action TEXT NOT NULL,
+		parameters_json TEXT NOT NULL DEFAULT '{}',
+		status TEXT NOT NULL,
+		total INTEGER NOT NULL DEFAULT 0,
+		processed INTEGER NOT NULL DEFAULT 0,
+		succeeded INTEGER NOT NULL DEFAULT 0,
+		skipped INTEGER NOT NULL DEFAULT 0,
+		failed INTEGER NOT NULL DEFAULT 0,
+		started_at TIMESTAMP NOT NULL,
+		finished_at TIMESTAMP
+	);
+	CREATE TABLE IF NOT EXISTS bulk_items (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		operation_id INTEGER NOT NULL,
+		job_id TEXT NOT NULL,
+		title TEXT NOT NULL DEFAULT '',
+		outcome TEXT NOT NULL,
+		detail TEXT NOT NULL DEFAULT '',
+		error TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP NOT NULL,
+		FOREIGN KEY (operation_id) REFERENCES bulk_operations (id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_bulk_items_operation ON bulk_items(operation_id);
+
+	CREATE VIRTUAL TABLE IF NOT EXISTS search_index USING fts5(
+		item_id UNINDEXED,
+		kind UNINDEXED,
+		title,
+		description,
+		channel,
+		tags,
+		notes,
+		tokenize = 'unicode61 remove_diacritics 2'
+	);
+
+	CREATE TABLE IF NOT EXISTS watch_progress (
+		job_id TEXT PRIMARY KEY,
+		position REAL NOT NULL DEFAULT 0,
+		duration REAL NOT NULL DEFAULT 0,
+		completed BOOLEAN NOT NULL DEFAULT 0,
+		last_watched_at TIMESTAMP NOT NULL,
+		FOREIGN KEY (job_id) REFERENCES jobs (job_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS annotations (
+		item_id TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		favorite BOOLEAN NOT NULL DEFAULT 0,
+		rating INTEGER NOT NULL DEFAULT 0,
+		notes TEXT NOT NULL DEFAULT '',
+		updated_at TIMESTAMP NOT NULL,
+		PRIMARY KEY (item_id, kind)
+	);
+
+	CREATE TABLE IF NOT EXISTS bookmarks (
+		id TEXT PRIMARY KEY,
+		job_id TEXT NOT NULL,
+		start_seconds REAL NOT NULL,
+		end_seconds REAL,
+		label TEXT NOT NULL DEFAULT '',
+		tags TEXT NOT NULL DEFAULT '[]',
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL,
+		FOREIGN KEY (job_id) REFERENCES jobs (job_id)
+	);
 	`
 
 	if _, err := db.Exec(schema); err != nil {
@@ -220,16 +470,43 @@ type MockJobRepository struct {
 	parents  map[string][]*domain.JobWithMetadata
 	videos   map[string][]*domain.JobWithMetadata
 	tags     map[string][]domain.Tag
+	comments map[string][]domain.Comment
+	// snapshots and schedules back the metadata history methods.
+	snapshots map[string][]domain.MetadataSnapshot
+	schedules map[string]domain.RefreshSchedule
+	// availability backs the availability-check methods.
+	availability map[string]domain.AvailabilityStatus
+	// playlistSnapshots backs the playlist snapshot methods, in insertion order.
+	playlistSnapshots []domain.PlaylistSnapshot
+	channelOptions    map[string]*domain.ChannelDownloadOptions
+	// upgradeRuns and upgradeItems back the quality upgrade methods, in
+	// insertion order.
+	upgradeRuns  []domain.UpgradeRun
+	upgradeItems []domain.UpgradeItem
+	// tagRules and vocabulary back the tag rule methods.
+	tagRules   []domain.TagRule
+	vocabulary domain.TagVocabulary
+	// watch backs the watch progress methods.
+	watch map[string]domain.WatchProgress
+	// annotations backs the annotation methods.
+	annotations map[string]domain.Annotation
 }
 
 // NewMockJobRepository creates a new mock repository
 func NewMockJobRepository() *MockJobRepository {
 	return &MockJobRepository{
-		jobs:     make(map[string]*domain.Job),
-		metadata: make(map[string]domain.Metadata),
-		parents:  make(map[string][]*domain.JobWithMetadata),
-		videos:   make(map[string][]*domain.JobWithMetadata),
-		tags:     make(map[string][]domain.Tag),
+		jobs:           make(map[string]*domain.Job),
+		metadata:       make(map[string]domain.Metadata),
+		parents:        make(map[string][]*domain.JobWithMetadata),
+		videos:         make(map[string][]*domain.JobWithMetadata),
+		tags:           make(map[string][]domain.Tag),
+		comments:       make(map[string][]domain.Comment),
+		snapshots:      make(map[string][]domain.MetadataSnapshot),
+		schedules:      make(map[string]domain.RefreshSchedule),
+		availability:   make(map[string]domain.AvailabilityStatus),
+		watch:          make(map[string]domain.WatchProgress),
+		annotations:    make(map[string]domain.Annotation),
+		channelOptions: make(map[string]*domain.ChannelDownloadOptions),
 	}
 }
 
@@ -284,9 +561,11 @@ func (m *MockJobRepository) GetJobWithMetadata(jobID string) (*domain.JobWithMet
 	if !exists {
 		return nil, sql.ErrNoRows
 	}
+	annotation, _ := m.GetAnnotation(jobID)
 	return &domain.JobWithMetadata{
-		Job:      job,
-		Metadata: m.metadata[jobID],
+		Job:        job,
+		Metadata:   m.metadata[jobID],
+		Annotation: annotation,
 	}, nil
 }
 
@@ -408,6 +687,25 @@ func (m *MockJobRepository) DeleteJob(jobID string) error {
 	delete(m.parents, jobID)
 	delete(m.videos, jobID)
 	delete(m.tags, jobID)
+	delete(m.comments, jobID)
+	delete(m.snapshots, jobID)
+	delete(m.schedules, jobID)
+	delete(m.availability, jobID)
+	kept := m.playlistSnapshots[:0]
+	for _, s := range m.playlistSnapshots {
+		if s.JobID != jobID {
+			kept = append(kept, s)
+		}
+	}
+	m.playlistSnapshots = kept
+	delete(m.channelOptions, jobID)
+	keptItems := m.upgradeItems[:0]
+	for _, item := range m.upgradeItems {
+		if item.JobID != jobID {
+			keptItems = append(keptItems, item)
+		}
+	}
+	m.upgradeItems = keptItems
 	return nil
 }
 
@@ -461,6 +759,491 @@ func (m *MockJobRepository) RemoveTagFromJob(jobID string, tagID int64) error {
 	return nil
 }
 
+func (m *MockJobRepository) GetTag(id int64) (*domain.Tag, error) {
+	return m.findTag(func(tag domain.Tag) bool { return tag.ID == id }), nil
+}
+
+func (m *MockJobRepository) GetTagByName(name string) (*domain.Tag, error) {
+	return m.findTag(func(tag domain.Tag) bool { return strings.EqualFold(tag.Name, name) }), nil
+}
+
+func (m *MockJobRepository) findTag(match func(domain.Tag) bool) *domain.Tag {
+	var found *domain.Tag
+	for _, tags := range m.tags {
+		for _, tag := range tags {
+			if !match(tag) {
+				continue
+			}
+			if found == nil {
+				found = &domain.Tag{ID: tag.ID, Name: tag.Name, Color: tag.Color, ParentID: tag.ParentID}
+			}
+			found.Count++
+		}
+	}
+	return found
+}
+
+func (m *MockJobRepository) UpdateTag(tag *domain.Tag) error {
+	for jobID, tags := range m.tags {
+		for i := range tags {
+			if tags[i].ID == tag.ID {
+				m.tags[jobID][i].Name, m.tags[jobID][i].Color, m.tags[jobID][i].ParentID = tag.Name, tag.Color, tag.ParentID
+			}
+		}
+	}
+	return nil
+}
+
+func (m *MockJobRepository) MergeTags(targetID int64, sourceIDs []int64) error {
+	target := m.findTag(func(tag domain.Tag) bool { return tag.ID == targetID })
+	if target == nil {
+		return fmt.Errorf("tag %d not found", targetID)
+	}
+	for jobID, tags := range m.tags {
+		var kept []domain.Tag
+		hasTarget := false
+		for _, tag := range tags {
+			if tag.ID == targetID {
+				hasTarget = true
+			}
+		}
+		for _, tag := range tags {
+			merged := false
+			for _, sourceID := range sourceIDs {
+				merged = merged || (tag.ID == sourceID && sourceID != targetID)
+			}
+			if merged {
+				if hasTarget {
+					continue
+				}
+				hasTarget = true
+				tag.ID, tag.Name, tag.Color, tag.ParentID = target.ID, target.Name, target.Color, target.ParentID
+			}
+			kept = append(kept, tag)
+		}
+		m.tags[jobID] = kept
+	}
+	return nil
+}
+
+func (m *MockJobRepository) DeleteTag(id int64) error {
+	for jobID, tags := range m.tags {
+		var kept []domain.Tag
+		for _, tag := range tags {
+			if tag.ID != id {
+				kept = append(kept, tag)
+			}
+		}
+		m.tags[jobID] = kept
+	}
+	return nil
+}
+
 func (m *MockJobRepository) BackfillAutoTags() error {
 	return nil
 }
+
+func (m *MockJobRepository) ListTagRules() ([]domain.TagRule, error) {
+	return append([]domain.TagRule{}, m.tagRules...), nil
+}
+
+func (m *MockJobRepository) GetTagRule(id int64) (*domain.TagRule, error) {
+	for _, rule := range m.tagRules {
+		if rule.ID == id {
+			return &rule, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *MockJobRepository) CreateTagRule(rule *domain.TagRule) error {
+	var maxID int64
+	for _, r := range m.tagRules {
+		if r.ID > maxID {
+			maxID = r.ID
+		}
+	}
+	rule.ID = maxID + 1
+	rule.CreatedAt = time.Now()
+	rule.UpdatedAt = rule.CreatedAt
+	m.tagRules = append(m.tagRules, *rule)
+	return nil
+}
+
+func (m *MockJobRepository) UpdateTagRule(rule *domain.TagRule) error {
+	for i, r := range m.tagRules {
+		if r.ID == rule.ID {
+			rule.UpdatedAt = time.Now()
+			m.tagRules[i] = *rule
+			return nil
+		}
+	}
+	return fmt.Errorf("tag rule not found")
+}
+
+func (m *MockJobRepository) DeleteTagRule(id int64) error {
+	for i, r := range m.tagRules {
+		if r.ID == id {
+			m.tagRules = append(m.tagRules[:i], m.tagRules[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (m *MockJobRepository) GetTagVocabulary() (*domain.TagVocabulary, error) {
+	return &domain.TagVocabulary{
+		Synonyms: append([]domain.TagSynonym{}, m.vocabulary.Synonyms...),
+		Blocked:  append([]string{}, m.vocabulary.Blocked...),
+	}, nil
+}
+
+func (m *MockJobRepository) SetTagSynonym(alias, canonical string) error {
+	m.DeleteTagSynonym(alias)
+	m.vocabulary.Synonyms = append(m.vocabulary.Synonyms, domain.TagSynonym{Alias: alias, Canonical: canonical})
+	return nil
+}
+
+func (m *MockJobRepository) DeleteTagSynonym(alias string) error {
+	kept := m.vocabulary.Synonyms[:0]
+	for _, s := range m.vocabulary.Synonyms {
+		if !strings.EqualFold(s.Alias, alias) {
+			kept = append(kept, s)
+		}
+	}
+	m.vocabulary.Synonyms = kept
+	return nil
+}
+
+func (m *MockJobRepository) BlockTag(name string) error {
+	m.UnblockTag(name)
+	m.vocabulary.Blocked = append(m.vocabulary.Blocked, name)
+	return nil
+}
+
+func (m *MockJobRepository) UnblockTag(name string) error {
+	kept := m.vocabulary.Blocked[:0]
+	for _, b := range m.vocabulary.Blocked {
+		if !strings.EqualFold(b, name) {
+			kept = append(kept, b)
+		}
+	}
+	m.vocabulary.Blocked = kept
+	return nil
+}
+
+// ReapplyTagRules only adds tags; the sqlite repository is the reference for
+// removing stale ones.
+func (m *MockJobRepository) ReapplyTagRules() (*domain.TagRuleRun, error) {
+	engine := domain.NewTagEngine(m.tagRules, &m.vocabulary)
+	run := &domain.TagRuleRun{}
+	for jobID, metadata := range m.metadata {
+		run.Items++
+		before := len(m.tags[jobID])
+		m.AddTagsToJob(jobID, engine.AutoTags(metadata), domain.TagSourceAuto)
+		ruleTags, _ := engine.Evaluate(metadata)
+		m.AddTagsToJob(jobID, ruleTags, domain.TagSourceRule)
+		run.TagsAdded += len(m.tags[jobID]) - before
+	}
+	return run, nil
+}
+
+func (m *MockJobRepository) GetChannelSnapshots(channelID string) ([]domain.ChannelSnapshot, error) {
+	return []domain.ChannelSnapshot{}, nil
+}
+
+func (m *MockJobRepository) HasChannelJobs(channelID string) (bool, error) {
+	for _, metadata := range m.metadata {
+		switch md := metadata.(type) {
+		case *domain.ChannelMetadata:
+			if md.ID == channelID {
+				return true, nil
+			}
+		case *domain.VideoMetadata:
+			if md.ChannelID == channelID {
+				return true, nil
+			}
+		case *domain.PlaylistMetadata:
+			if md.ChannelID == channelID {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func (m *MockJobRepository) StoreComments(jobID string, comments []domain.Comment) error {
+	m.comments[jobID] = comments
+	return nil
+}
+
+func (m *MockJobRepository) GetComments(jobID string, opts domain.CommentQuery) ([]domain.Comment, int, error) {
+	threads := []domain.Comment{}
+	for _, c := range m.comments[jobID] {
+		if c.ParentID == "" {
+			threads = append(threads, c)
+		}
+	}
+	return threads, len(threads), nil
+}
+
+func (m *MockJobRepository) RecordMetadataSnapshot(jobID string, metadata *domain.VideoMetadata) error {
+	m.snapshots[jobID] = append(m.snapshots[jobID], domain.MetadataSnapshot{
+		ID:           int64(len(m.snapshots[jobID]) + 1),
+		JobID:        jobID,
+		Title:        metadata.Title,
+		Description:  metadata.Description,
+		ViewCount:    metadata.ViewCount,
+		LikeCount:    metadata.LikeCount,
+		CommentCount: metadata.CommentCount,
+		CapturedAt:   time.Now(),
+	})
+	return nil
+}
+
+func (m *MockJobRepository) GetMetadataSnapshots(jobID string) ([]domain.MetadataSnapshot, error) {
+	return append([]domain.MetadataSnapshot{}, m.snapshots[jobID]...), nil
+}
+
+func (m *MockJobRepository) SetRefreshSchedule(jobID string, intervalHours int) error {
+	if intervalHours <= 0 {
+		delete(m.schedules, jobID)
+		return nil
+	}
+	m.schedules[jobID] = domain.RefreshSchedule{JobID: jobID, IntervalHours: intervalHours, NextRefreshAt: time.Now()}
+	return nil
+}
+
+func (m *MockJobRepository) GetRefreshSchedule(jobID string) (*domain.RefreshSchedule, error) {
+	s, ok := m.schedules[jobID]
+	if !ok {
+		return nil, nil
+	}
+	return &s, nil
+}
+
+func (m *MockJobRepository) ListRefreshSchedules() ([]domain.RefreshSchedule, error) {
+	schedules := make([]domain.RefreshSchedule, 0, len(m.schedules))
+	for _, s := range m.schedules {
+		schedules = append(schedules, s)
+	}
+	return schedules, nil
+}
+
+func (m *MockJobRepository) MarkRefreshed(jobID string, at time.Time) error {
+	s, ok := m.schedules[jobID]
+	if !ok {
+		return nil
+	}
+	s.LastRefreshedAt = &at
+	s.NextRefreshAt = at.Add(time.Duration(s.IntervalHours) * time.Hour)
+	s.Cursor = ""
+	m.schedules[jobID] = s
+	return nil
+}
+
+func (m *MockJobRepository) SaveRefreshCursor(jobID, cursor string, retryAt time.Time) error {
+	s, ok := m.schedules[jobID]
+	if !ok {
+		return nil
+	}
+	s.Cursor = cursor
+	s.NextRefreshAt = retryAt
+	m.schedules[jobID] = s
+	return nil
+}
+
+func (m *MockJobRepository) RecordAvailability(jobID string, status domain.Availability, reason string, at time.Time) (*domain.AvailabilityStatus, error) {
+	var previous *domain.AvailabilityStatus
+	firstSeen := at
+	if p, ok := m.availability[jobID]; ok {
+		previous = &p
+		if p.Status == status {
+			firstSeen = p.FirstSeenAt
+		}
+	}
+	m.availability[jobID] = domain.AvailabilityStatus{
+		JobID:       jobID,
+		Status:      status,
+		Reason:      reason,
+		FirstSeenAt: firstSeen,
+		CheckedAt:   at,
+	}
+	return previous, nil
+}
+
+func (m *MockJobRepository) GetAvailability(jobID string) (*domain.AvailabilityStatus, error) {
+	s, ok := m.availability[jobID]
+	if !ok {
+		return nil, nil
+	}
+	return &s, nil
+}
+
+func (m *MockJobRepository) ListAvailabilityCandidates(checkedBefore time.Time, limit int) ([]*domain.Job, error) {
+	jobs := []*domain.Job{}
+	for id, job := range m.jobs {
+		if _, isVideo := m.metadata[id].(*domain.VideoMetadata); !isVideo || job.Status != domain.JobStatusComplete {
+			continue
+		}
+		if s, ok := m.availability[id]; ok && !s.CheckedAt.Before(checkedBefore) {
+			continue
+		}
+		if len(jobs) == limit {
+			break
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+func (m *MockJobRepository) RecordPlaylistSnapshot(jobID, sourceID string, items []domain.PlaylistEntry) (*domain.PlaylistSnapshot, error) {
+	snapshot := domain.PlaylistSnapshot{
+		ID:         int64(len(m.playlistSnapshots) + 1),
+		SourceID:   sourceID,
+		JobID:      jobID,
+		ItemCount:  len(items),
+		CapturedAt: time.Now(),
+		Items:      items,
+	}
+	m.playlistSnapshots = append(m.playlistSnapshots, snapshot)
+	return &snapshot, nil
+}
+
+func (m *MockJobRepository) GetPlaylistSnapshots(sourceID string) ([]domain.PlaylistSnapshot, error) {
+	snapshots := []domain.PlaylistSnapshot{}
+	for _, s := range m.playlistSnapshots {
+		if s.SourceID == sourceID {
+			s.Items = nil
+			snapshots = append(snapshots, s)
+		}
+	}
+	return snapshots, nil
+}
+
+func (m *MockJobRepository) GetPlaylistSnapshot(id int64) (*domain.PlaylistSnapshot, error) {
+	for _, s := range m.playlistSnapshots {
+		if s.ID == id {
+			return &s, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *MockJobRepository) SetChannelOptions(jobID string, options *domain.ChannelDownloadOptions) error {
+	if options == nil {
+		delete(m.channelOptions, jobID)
+		return nil
+	}
+	stored := *options
+	m.channelOptions[jobID] = &stored
+	return nil
+}
+
+func (m *MockJobRepository) GetChannelOptions(jobID string) (*domain.ChannelDownloadOptions, error) {
+	if options, ok := m.channelOptions[jobID]; ok {
+		stored := *options
+		return &stored, nil
+	}
+	return nil, nil
+}
+
+func (m *MockJobRepository) CreateUpgradeRun(run *domain.UpgradeRun) error {
+	run.ID = int64(len(m.upgradeRuns) + 1)
+	m.upgradeRuns = append(m.upgradeRuns, *run)
+	return nil
+}
+
+func (m *MockJobRepository) UpdateUpgradeRun(run *domain.UpgradeRun) error {
+	for i := range m.upgradeRuns {
+		if m.upgradeRuns[i].ID == run.ID {
+			stored := *run
+			stored.Items = nil
+			m.upgradeRuns[i] = stored
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (m *MockJobRepository) AddUpgradeItem(item *domain.UpgradeItem) error {
+	item.ID = int64(len(m.upgradeItems) + 1)
+	m.upgradeItems = append(m.upgradeItems, *item)
+	return nil
+}
+
+func (m *MockJobRepository) GetUpgradeRun(id int64) (*domain.UpgradeRun, error) {
+	for _, run := range m.upgradeRuns {
+		if run.ID == id {
+			run.Items = []domain.UpgradeItem{}
+			for _, item := range m.upgradeItems {
+				if item.RunID == id {
+					run.Items = append(run.Items, item)
+				}
+			}
+			return &run, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (m *MockJobRepository) ListUpgradeRuns(limit int) ([]domain.UpgradeRun, error) {
+	runs := []domain.UpgradeRun{}
+	for i := len(m.upgradeRuns) - 1; i >= 0 && len(runs) < limit; i-- {
+		runs = append(runs, m.upgradeRuns[i])
+	}
+	return runs, nil
+}
+
+func (m *MockJobRepository) SetWatchProgress(progress *domain.WatchProgress) error {
+	m.watch[progress.JobID] = *progress
+	return nil
+}
+
+func (m *MockJobRepository) GetWatchProgress(jobID string) (*domain.WatchProgress, error) {
+	p, ok := m.watch[jobID]
+	if !ok {
+		return nil, nil
+	}
+	return &p, nil
+}
+
+func (m *MockJobRepository) DeleteWatchProgress(jobID string) error {
+	delete(m.watch, jobID)
+	return nil
+}
+
+func (m *MockJobRepository) GetContinueWatching(limit int) ([]*domain.JobWithMetadata, error) {
+	result := []*domain.JobWithMetadata{}
+	for id, p := range m.watch {
+		if p.Completed || p.Position <= 0 {
+			continue
+		}
+		watch := p
+		result = append(result, &domain.JobWithMetadata{Job: m.jobs[id], Metadata: m.metadata[id], Watch: &watch})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Watch.LastWatchedAt.After(result[j].Watch.LastWatchedAt)
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (m *MockJobRepository) SetAnnotation(jobID string, annotation *domain.Annotation) error {
+	if annotation.IsEmpty() {
+		delete(m.annotations, jobID)
+		return nil
+	}
+	m.annotations[jobID] = *annotation
+	return nil
+}
+
+func (m *MockJobRepository) GetAnnotation(jobID string) (*domain.Annotation, error) {
+	a, ok := m.annotations[jobID]
+	if !ok {
+		return nil, nil
+	}
+	return &a, nil
+}
@@ -0,0 +1,39 @@
+package signedurl
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSigner(t *testing.T) {
+	if New("") != nil {
+		t.Fatal("New(\"\") should disable signing")
+	}
+
+	s := New("secret")
+	now := time.Unix(1700000000, 0)
+	s.now = func() time.Time { return now }
+
+	query := s.Sign("/video/abc", time.Hour)
+	if err := s.Verify("/video/abc", query); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+	if err := s.Verify("/video/other", query); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Verify(other path) error = %v, want ErrInvalidSignature", err)
+	}
+	if err := New("other").Verify("/video/abc", query); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Verify(other key) error = %v, want ErrInvalidSignature", err)
+	}
+
+	tampered := s.Sign("/video/abc", time.Hour)
+	tampered.Set(ExpiresParam, "9999999999")
+	if err := s.Verify("/video/abc", tampered); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Verify(extended expiry) error = %v, want ErrInvalidSignature", err)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if err := s.Verify("/video/abc", query); !errors.Is(err, ErrExpired) {
+		t.Errorf("Verify(expired) error = %v, want ErrExpired", err)
+	}
+}
@@ -1,5 +1,396 @@
 // Code generated by tygo. DO NOT EDIT.
 
+//////////
+// source: annotations.go
+
+/**
+ * MaxAnnotationNotes caps the length of an item's notes, in bytes.
+ */
+export const MaxAnnotationNotes = 64 << 10;
+/**
+ * AnnotationKind is the kind of library item an annotation belongs to.
+ */
+export type AnnotationKind = string;
+/**
+ * AnnotationKindJob covers videos, playlists and channels.
+ */
+export const AnnotationKindJob: AnnotationKind = "job";
+export const AnnotationKindCollection: AnnotationKind = "collection";
+/**
+ * Annotation holds a user's own marks on a job or collection.
+ */
+export interface Annotation {
+  favorite: boolean;
+  /**
+   * Rating is 1 to 5 stars; 0 means unrated.
+   */
+  rating: number /* int */;
+  /**
+   * Notes are free-form Markdown.
+   */
+  notes: string;
+  updated_at: string /* RFC3339 */;
+}
+/**
+ * AnnotationUpdate changes the fields it sets and keeps the others.
+ */
+export interface AnnotationUpdate {
+  favorite?: boolean;
+  rating?: number /* int */;
+  notes?: string;
+}
+
+//////////
+// source: automation.go
+
+/**
+ * AutomationRule runs a saved tools workflow on every downloaded video that
+ * matches its conditions, as soon as the download completes. The tools jobs
+ * it submits carry the rule's ID and the video's download job ID.
+ */
+export interface AutomationRule {
+  id: string;
+  name: string;
+  enabled: boolean;
+  match: AutomationMatch;
+  workflow: WorkflowParameters;
+  created_at: string /* RFC3339 */;
+  updated_at: string /* RFC3339 */;
+}
+/**
+ * AutomationMatch holds the conditions a video must meet for a rule to fire.
+ * Unset conditions are ignored, so the zero value matches every video.
+ */
+export interface AutomationMatch {
+  media_type?: MediaType;
+  /**
+   * Tags matches videos carrying any of these tags (case-insensitive).
+   */
+  tags?: string[];
+  /**
+   * Channel matches the channel name, channel ID or uploader
+   * (case-insensitive).
+   */
+  channel?: string;
+  /**
+   * BrowserSafe matches on whether the file's codecs play in browsers
+   * without a transcode (see BrowserSafeCodecs); false targets the files
+   * that need one. Audio-only files never match this condition.
+   */
+  browser_safe?: boolean;
+  /**
+   * MinDuration and MaxDuration bound the video length in seconds; 0 is
+   * unbounded.
+   */
+  min_duration?: number /* int */;
+  max_duration?: number /* int */;
+}
+/**
+ * AutomationSubject describes a downloaded video for rule matching.
+ */
+export interface AutomationSubject {
+  MediaType: MediaType;
+  Tags: string[];
+  /**
+   * Channels holds the channel name, channel ID and uploader.
+   */
+  Channels: string[];
+  Duration: number /* int */; // seconds
+  /**
+   * BrowserSafe is nil when the file couldn't be probed or has no video
+   * stream.
+   */
+  BrowserSafe?: boolean;
+}
+
+export type AutomationRepository = any;
+
+//////////
+// source: availability.go
+
+/**
+ * Availability is the upstream state of an archived video's source, as
+ * determined by the availability checker.
+ */
+export type Availability = string;
+export const AvailabilityAvailable: Availability = "available";
+export const AvailabilityPrivate: Availability = "private";
+export const AvailabilityRemoved: Availability = "removed";
+export const AvailabilityGeoBlocked: Availability = "geo_blocked";
+export const AvailabilityAccountTerminated: Availability = "account_terminated";
+/**
+ * AvailabilityStatus is the latest check result for a video. FirstSeenAt is
+ * when the current status was first observed; it only moves when the status
+ * changes, so "removed since" survives repeated checks.
+ */
+export interface AvailabilityStatus {
+  job_id: string;
+  status: Availability;
+  reason?: string; // yt-dlp's error message, if any
+  first_seen_at: string /* RFC3339 */;
+  checked_at: string /* RFC3339 */;
+}
+/**
+ * AvailabilityUpdate is broadcast when an archived video's upstream status
+ * changes.
+ */
+export interface AvailabilityUpdate {
+  type: string; // always "availability"
+  jobID: string;
+  title: string;
+  status: Availability;
+  previous?: Availability;
+  reason?: string;
+}
+
+//////////
+// source: bookmarks.go
+
+/**
+ * DefaultClipLength is how long, in seconds, an exported clip of a bookmark
+ * without an end runs.
+ */
+export const DefaultClipLength = 30;
+/**
+ * MaxBookmarkLabel caps the length of a bookmark's label, in runes.
+ */
+export const MaxBookmarkLabel = 200;
+/**
+ * Bookmark marks a moment of a video or, with an end, a clip of it.
+ */
+export interface Bookmark {
+  id: string;
+  job_id: string;
+  /**
+   * Start and End are in seconds; End is nil for a single moment.
+   */
+  start: number /* float64 */;
+  end?: number /* float64 */;
+  label: string;
+  tags: string[];
+  created_at: string /* RFC3339 */;
+  updated_at: string /* RFC3339 */;
+}
+/**
+ * BookmarkExportMode chooses how bookmarks are exported.
+ */
+export type BookmarkExportMode = string;
+/**
+ * BookmarkExportClips trims every bookmark into its own file.
+ */
+export const BookmarkExportClips: BookmarkExportMode = "clips";
+/**
+ * BookmarkExportReel joins the bookmarks into one highlight reel.
+ */
+export const BookmarkExportReel: BookmarkExportMode = "reel";
+
+export type BookmarkRepository = any;
+
+//////////
+// source: bulk.go
+
+/**
+ * BulkAction is what a bulk operation does to each selected library item.
+ */
+export type BulkAction = string;
+export const BulkActionAddTags: BulkAction = "add_tags";
+export const BulkActionRemoveTags: BulkAction = "remove_tags";
+export const BulkActionAddToCollection: BulkAction = "add_to_collection";
+export const BulkActionDelete: BulkAction = "delete";
+export const BulkActionRedownload: BulkAction = "redownload";
+export const BulkActionRefreshMetadata: BulkAction = "refresh_metadata";
+/**
+ * BulkActionTools submits one tools job per item.
+ */
+export const BulkActionTools: BulkAction = "tools";
+export type BulkStatus = string;
+export const BulkStatusRunning: BulkStatus = "running";
+export const BulkStatusComplete: BulkStatus = "complete";
+/**
+ * BulkStatusStopped: the service shut down before the operation finished.
+ */
+export const BulkStatusStopped: BulkStatus = "stopped";
+/**
+ * BulkOutcome is what a bulk operation did with one item.
+ */
+export type BulkOutcome = string;
+export const BulkOutcomeSucceeded: BulkOutcome = "succeeded";
+/**
+ * BulkOutcomeSkipped: the action does not apply to the item, e.g. adding
+ * a playlist to a collection.
+ */
+export const BulkOutcomeSkipped: BulkOutcome = "skipped";
+export const BulkOutcomeFailed: BulkOutcome = "failed";
+/**
+ * BulkParameters configures a bulk action; which fields apply depends on the
+ * action.
+ */
+export interface BulkParameters {
+  /**
+   * Tags for add_tags and remove_tags.
+   */
+  tags?: string[];
+  /**
+   * CollectionID for add_to_collection.
+   */
+  collection_id?: string;
+  /**
+   * DeleteFiles makes delete remove media files too, not only the library
+   * records.
+   */
+  delete_files?: boolean;
+  /**
+   * Operation and ToolsParameters describe the tools job submitted per
+   * item for tools.
+   */
+  operation?: ToolsOperationType;
+  tools_parameters?: { [key: string]: any};
+}
+/**
+ * BulkQuery selects every item a library listing would show, across all
+ * pages.
+ */
+export interface BulkQuery {
+  /**
+   * Type is "videos", "playlists" or "channels".
+   */
+  type: string;
+  search?: string;
+  tag?: string;
+  availability?: Availability;
+  /**
+   * Q is a library query, see ParseLibraryQuery.
+   */
+  q?: string;
+}
+/**
+ * BulkRequest selects library items, either by job ID or with a query, and
+ * names the action to apply to each of them.
+ */
+export interface BulkRequest {
+  action: BulkAction;
+  job_ids?: string[];
+  query?: BulkQuery;
+  parameters: BulkParameters;
+}
+/**
+ * BulkOperation is one action applied to a selection of library items in the
+ * background. The counters form the report; Items is only populated when a
+ * single operation is requested.
+ */
+export interface BulkOperation {
+  id: number /* int64 */;
+  action: BulkAction;
+  parameters: BulkParameters;
+  status: BulkStatus;
+  total: number /* int */;
+  processed: number /* int */;
+  succeeded: number /* int */;
+  skipped: number /* int */;
+  failed: number /* int */;
+  started_at: string /* RFC3339 */;
+  finished_at?: string /* RFC3339 */;
+  items?: BulkItem[];
+}
+/**
+ * BulkItem records what a bulk operation did with one library item. Detail
+ * holds a result worth following up on, such as the submitted tools job's ID.
+ */
+export interface BulkItem {
+  id: number /* int64 */;
+  operation_id: number /* int64 */;
+  job_id: string;
+  title: string;
+  outcome: BulkOutcome;
+  detail?: string;
+  error?: string;
+  created_at: string /* RFC3339 */;
+}
+/**
+ * BulkUpdate is broadcast over the WebSocket as a bulk operation progresses.
+ */
+export interface BulkUpdate {
+  type: string; // always "bulk"
+  operationID: number /* int64 */;
+  action: BulkAction;
+  status: BulkStatus;
+  total: number /* int */;
+  processed: number /* int */;
+  succeeded: number /* int */;
+  skipped: number /* int */;
+  failed: number /* int */;
+}
+
+export type BulkRepository = any;
+
+//////////
+// source: channel_options.go
+
+/**
+ * ChannelTab is a section of a channel page that can be downloaded.
+ */
+export type ChannelTab = string;
+export const ChannelTabVideos: ChannelTab = "videos";
+export const ChannelTabShorts: ChannelTab = "shorts";
+export const ChannelTabStreams: ChannelTab = "streams";
+export const ChannelTabPlaylists: ChannelTab = "playlists";
+/**
+ * ChannelDownloadOptions narrows what a channel download fetches. They are
+ * stored on the channel's job so every re-sync applies the same rules. Zero
+ * values mean "no restriction"; no tabs means the channel URL as submitted.
+ */
+export interface ChannelDownloadOptions {
+  tabs?: ChannelTab[];
+  /**
+   * DateAfter and DateBefore bound the upload date (YYYYMMDD, inclusive).
+   */
+  date_after?: string;
+  date_before?: string;
+  /**
+   * MaxItems stops the download after this many videos.
+   */
+  max_items?: number /* int */;
+  /**
+   * MinDuration and MaxDuration bound the video length in seconds.
+   */
+  min_duration?: number /* int */;
+  max_duration?: number /* int */;
+  /**
+   * TitleInclude and TitleExclude are regular expressions the title must
+   * (not) match.
+   */
+  title_include?: string;
+  title_exclude?: string;
+}
+
+//////////
+// source: channels.go
+
+/**
+ * ChannelAssetKind names a piece of channel art archived alongside a channel
+ * download and served from /job/{id}/assets/{kind}.
+ */
+export const ChannelAssetAvatar = "avatar";
+/**
+ * ChannelAssetKind names a piece of channel art archived alongside a channel
+ * download and served from /job/{id}/assets/{kind}.
+ */
+export const ChannelAssetBanner = "banner";
+/**
+ * ChannelSnapshot records a channel's about-page state at one point in time.
+ * Snapshots are keyed by the source channel ID rather than the job, so the
+ * history spans every job that archived or re-synced the same channel. A new
+ * snapshot is only recorded when the description or follower count changed.
+ */
+export interface ChannelSnapshot {
+  id: number /* int64 */;
+  channel_id: string;
+  job_id: string;
+  description: string;
+  channel_follower_count: number /* int */;
+  captured_at: string /* RFC3339 */;
+}
+
 //////////
 // source: collections.go
 
@@ -9,11 +400,19 @@
  * collections are curated locally and can mix videos from any source. The
  * tools section treats a collection like a playlist: one collection ID
  * expands into its member videos.
+ *
+ * A smart collection has a Query instead of hand-picked members: its videos
+ * are whatever the library query matches when the collection is read.
  */
 export interface Collection {
   id: string;
   name: string;
   description?: string;
+  /**
+   * Query is the library query (see ParseLibraryQuery) of a smart
+   * collection; empty for manual collections.
+   */
+  query?: string;
   created_at: string /* RFC3339 */;
   updated_at: string /* RFC3339 */;
   /**
@@ -22,10 +421,153 @@ export interface Collection {
    */
   video_count: number /* int */;
   thumbnail?: string;
+  /**
+   * Completion is how many of the videos have been watched.
+   */
+  completion?: WatchCompletion;
+  /**
+   * Annotation holds the user's favorite flag, rating and notes.
+   */
+  annotation?: Annotation;
 }
 
 export type CollectionRepository = any;
 
+//////////
+// source: comments.go
+
+/**
+ * Comment is an archived comment on a downloaded video, parsed from the
+ * "comments" list yt-dlp writes into .info.json with --write-comments.
+ * Top-level comments have an empty ParentID; when returned from the comments
+ * API they carry their replies (oldest first).
+ */
+export interface Comment {
+  id: string;
+  parent_id?: string;
+  author: string;
+  author_id?: string;
+  author_is_uploader?: boolean;
+  text: string;
+  like_count: number /* int */;
+  timestamp: number /* int64 */; // Unix seconds; 0 when the source gave none
+  is_pinned?: boolean;
+  is_hearted?: boolean; // liked by the uploader
+  reply_count?: number /* int */;
+  replies?: Comment[];
+}
+/**
+ * Comment sort orders accepted by CommentQuery.
+ */
+export const CommentSortTop = "top";
+/**
+ * Comment sort orders accepted by CommentQuery.
+ */
+export const CommentSortNewest = "newest";
+/**
+ * Comment sort orders accepted by CommentQuery.
+ */
+export const CommentSortOldest = "oldest";
+/**
+ * CommentQuery holds the listing options for GetComments. Paging applies to
+ * top-level threads; a thread matches Search when its root or any reply does.
+ */
+export interface CommentQuery {
+  Page: number /* int */;
+  Limit: number /* int */;
+  Sort: string;
+  Search: string;
+}
+
+//////////
+// source: export.go
+
+/**
+ * ExportFormat is a playlist file format collections, playlists and
+ * channels can be exported as, for players such as VLC and mpv.
+ */
+export type ExportFormat = string;
+export const ExportFormatM3U8: ExportFormat = "m3u8";
+export const ExportFormatXSPF: ExportFormat = "xspf";
+/**
+ * ExportFormatJSON is a manifest of PlaylistExport.
+ */
+export const ExportFormatJSON: ExportFormat = "json";
+/**
+ * PlaylistExport lists the downloaded videos of a collection, playlist or
+ * channel in order, with links that play them from the archive.
+ */
+export interface PlaylistExport {
+  /**
+   * Kind is "collection", "playlist" or "channel".
+   */
+  kind: string;
+  id: string;
+  title: string;
+  exported_at: string /* RFC3339 */;
+  items: PlaylistExportItem[];
+}
+export interface PlaylistExportItem {
+  job_id: string;
+  title: string;
+  channel?: string;
+  /**
+   * Duration is in seconds; zero when unknown.
+   */
+  duration?: number /* int */;
+  /**
+   * URL streams the video from /video/{jobID}, signed when requested. In
+   * a zip archive's manifest, URL and Thumbnail are paths inside the
+   * archive.
+   */
+  url: string;
+  thumbnail?: string;
+  /**
+   * SourceURL is where the video was downloaded from.
+   */
+  source_url: string;
+  /**
+   * Favorite, Rating and Notes carry the video's annotation.
+   */
+  favorite?: boolean;
+  rating?: number /* int */;
+  notes?: string;
+}
+
+//////////
+// source: history.go
+
+/**
+ * MetadataSnapshot is one point in a video's engagement history: the
+ * counters and editable text as seen by a download or a metadata refresh.
+ * TitleChanged/DescriptionChanged are derived when history is read and mark
+ * snapshots whose text differs from the one before.
+ */
+export interface MetadataSnapshot {
+  id: number /* int64 */;
+  job_id: string;
+  title: string;
+  description: string;
+  view_count: number /* int */;
+  like_count: number /* int */;
+  comment_count: number /* int */;
+  title_changed?: boolean;
+  description_changed?: boolean;
+  captured_at: string /* RFC3339 */;
+}
+/**
+ * RefreshSchedule re-fetches the metadata of a job every IntervalHours. For
+ * playlist and channel jobs the refresh covers their member videos; Cursor is
+ * the last member an interrupted run got through, where the next one resumes.
+ */
+export interface RefreshSchedule {
+  job_id: string;
+  interval_hours: number /* int */;
+  last_refreshed_at?: string /* RFC3339 */;
+  next_refresh_at: string /* RFC3339 */;
+  cursor?: string;
+}
+
 //////////
 // source: job.go
 
@@ -49,6 +591,22 @@ export interface Job {
   progress: number /* float64 */;
   media_type?: MediaType;
   custom_quality?: number /* int */;
+  /**
+   * ArchiveComments overrides the archive_comments setting for this job;
+   * nil uses the setting. Stored with the job, so re-syncs keep it.
+   */
+  archive_comments?: boolean;
+  /**
+   * Live records the source as a livestream: wait for scheduled streams,
+   * capture from the start and keep the recording when stopped. Also
+   * enabled automatically when the source reports a live or upcoming stream.
+   */
+  live?: boolean;
+  /**
+   * ChannelOptions narrows a channel download. Only set on submission;
+   * the stored copy is read back with GetChannelOptions.
+   */
+  channel_options?: ChannelDownloadOptions;
   warnings?: string[];
   /**
    * FilePath is the absolute on-disk path of the downloaded media file,
@@ -71,6 +629,16 @@ export interface MetadataQuery {
   Order: string;
   Search: string; // case-insensitive match against title/channel
   Tag: string; // only items carrying this tag
+  /**
+   * Availability narrows videos to those whose last availability check
+   * reported this status.
+   */
+  Availability: Availability;
+  /**
+   * Filters are parsed from the library query language; see
+   * ParseLibraryQuery.
+   */
+  Filters: QueryFilter[];
 }
 export type JobType = string;
 export const JobTypeVideo: JobType = "video";
@@ -83,6 +651,22 @@ export interface JobWithMetadata {
   job?: Job;
   metadata?: Metadata;
   tags?: Tag[];
+  /**
+   * Availability is the latest upstream check of a video, when it has one.
+   */
+  availability?: AvailabilityStatus;
+  /**
+   * Watch is where playback of a video stopped, when it has been played.
+   */
+  watch?: WatchProgress;
+  /**
+   * Completion is how much of a playlist or channel has been watched.
+   */
+  completion?: WatchCompletion;
+  /**
+   * Annotation holds the user's favorite flag, rating and notes.
+   */
+  annotation?: Annotation;
 }
 export interface ProgressUpdate {
   jobID: string;
@@ -98,12 +682,27 @@ export interface ProgressUpdate {
   maxRetries?: number /* int */;
   retryError?: string;
   warnings?: string[];
+  /**
+   * Live recordings have no meaningful percentage; they report how long
+   * and how much has been recorded instead.
+   */
+  isLive?: boolean;
+  elapsedSeconds?: number /* int */;
+  recordedBytes?: number /* int64 */;
 }
 export const DownloadPhaseMetadata = "metadata";
 export const DownloadPhaseVideo = "video";
 export const DownloadPhaseAudio = "audio";
 export const DownloadPhaseMerging = "merging";
 export const DownloadPhaseComplete = "complete";
+/**
+ * DownloadPhaseWaiting: a scheduled stream has not started yet.
+ */
+export const DownloadPhaseWaiting = "waiting";
+/**
+ * DownloadPhaseRecording: a live stream is being captured.
+ */
+export const DownloadPhaseRecording = "recording";
 export interface VideoMetadata {
   id: string;
   title: string;
@@ -139,6 +738,7 @@ export interface VideoMetadata {
   acodec: string;
   audio_channels: number /* int */;
   was_live: boolean;
+  live_status?: string;
   webpage_url_domain: string;
   extractor: string;
   fulltitle: string;
@@ -208,6 +808,99 @@ export interface MetadataUpdate {
 }
 export type Metadata = any;
 
+//////////
+// source: library_query.go
+
+/**
+ * QueryField names what a library query filter matches against. Only these
+ * fields are accepted; the repository maps each to a fixed SQL expression.
+ */
+export type QueryField = string;
+/**
+ * QueryFieldText matches bare words and quoted phrases against the
+ * full-text search index: title, description, channel and tags.
+ */
+export const QueryFieldText: QueryField = "text";
+export const QueryFieldTitle: QueryField = "title";
+export const QueryFieldChannel: QueryField = "channel";
+export const QueryFieldTag: QueryField = "tag";
+export const QueryFieldStatus: QueryField = "status";
+export const QueryFieldDuration: QueryField = "duration";
+export const QueryFieldUploaded: QueryField = "uploaded";
+export const QueryFieldResolution: QueryField = "res";
+export const QueryFieldCodec: QueryField = "codec";
+export const QueryFieldSize: QueryField = "size";
+export const QueryFieldViews: QueryField = "views";
+export const QueryFieldWatch: QueryField = "watch";
+export const QueryFieldFavorite: QueryField = "favorite";
+export const QueryFieldRating: QueryField = "rating";
+export const QueryFieldNotes: QueryField = "notes";
+/**
+ * QueryOp compares a field with a filter's value. ":" means "matches": a
+ * substring for text fields, equality for numbers and the whole period for
+ * dates.
+ */
+export type QueryOp = string;
+export const QueryOpMatch: QueryOp = ":";
+export const QueryOpGreater: QueryOp = ">";
+export const QueryOpGreaterEqual: QueryOp = ">=";
+export const QueryOpLess: QueryOp = "<";
+export const QueryOpLessEqual: QueryOp = "<=";
+/**
+ * QueryFilter is one condition of a library query.
+ */
+export interface QueryFilter {
+  Field: QueryField;
+  Op: QueryOp;
+  Negate: boolean;
+  /**
+   * Text is the value of text, title, channel, tag, status, codec, watch
+   * and notes filters.
+   */
+  Text: string;
+  /**
+   * Number is the value of numeric filters: seconds for duration, pixels
+   * of height for res, bytes for size, stars for rating and 1 or 0 for
+   * favorite.
+   */
+  Number: number /* int64 */;
+  /**
+   * From and To bound an uploaded filter's period as inclusive YYYYMMDD
+   * dates.
+   */
+  From: string;
+  To: string;
+}
+
+//////////
+// source: search.go
+
+/**
+ * SearchKind is the type of item a search result points at.
+ */
+export type SearchKind = string;
+export const SearchKindVideo: SearchKind = "video";
+export const SearchKindPlaylist: SearchKind = "playlist";
+export const SearchKindChannel: SearchKind = "channel";
+export const SearchKindCollection: SearchKind = "collection";
+/**
+ * SearchResult is one ranked full-text search hit. ID is a job ID, or a
+ * collection ID for collections. Title and Snippet are HTML-escaped, with the
+ * matched terms wrapped in <mark> elements.
+ */
+export interface SearchResult {
+  kind: SearchKind;
+  id: string;
+  title: string;
+  snippet: string;
+  /**
+   * Score is the relevance of the hit; higher is better.
+   */
+  score: number /* float64 */;
+}
+
+export type SearchRepository = any;
+
 //////////
 // source: settings.go
 
@@ -220,12 +913,79 @@ export interface Settings {
   tools_default_quality: string;
   tools_preserve_original: boolean;
   tools_output_path: string;
+  archive_comments: boolean;
+  /**
+   * AvailabilityCheckHours is how often each archived video's source is
+   * re-checked upstream; 0 disables the availability checker.
+   */
+  availability_check_hours: number /* int */;
   created_at: string /* RFC3339 */;
   updated_at: string /* RFC3339 */;
 }
 
 export type SettingsRepository = any;
 
+//////////
+// source: snapshots.go
+
+/**
+ * PlaylistEntry is one item of a playlist or channel listing as it appeared
+ * upstream at sync time. Position is the 1-based playlist_index.
+ */
+export interface PlaylistEntry {
+  video_id: string;
+  title: string;
+  position: number /* int */;
+}
+/**
+ * PlaylistSnapshot is the ordered item list of a playlist or channel recorded
+ * by one sync. Snapshots are keyed by the source's ID, not the job, so every
+ * re-sync of the same playlist extends one history. The counts summarize the
+ * diff against the previous snapshot (all zero for the first one). Items is
+ * only populated when a single snapshot is requested.
+ */
+export interface PlaylistSnapshot {
+  id: number /* int64 */;
+  source_id: string;
+  job_id: string;
+  item_count: number /* int */;
+  added_count: number /* int */;
+  removed_count: number /* int */;
+  renamed_count: number /* int */;
+  reordered_count: number /* int */;
+  captured_at: string /* RFC3339 */;
+  items?: PlaylistEntry[];
+}
+/**
+ * PlaylistRename is an item whose title changed between two snapshots.
+ */
+export interface PlaylistRename {
+  video_id: string;
+  old_title: string;
+  new_title: string;
+}
+/**
+ * PlaylistMove is an item that changed its order relative to the other
+ * items between two snapshots.
+ */
+export interface PlaylistMove {
+  video_id: string;
+  title: string;
+  old_position: number /* int */;
+  new_position: number /* int */;
+}
+/**
+ * PlaylistDiff describes how a playlist changed from one snapshot to another.
+ */
+export interface PlaylistDiff {
+  from: number /* int64 */;
+  to: number /* int64 */;
+  added: PlaylistEntry[];
+  removed: PlaylistEntry[];
+  renamed: PlaylistRename[];
+  reordered: PlaylistMove[];
+}
+
 //////////
 // source: statistics.go
 
@@ -245,6 +1005,100 @@ export interface VideoStorageInfo {
   channel: string;
 }
 
+//////////
+// source: tag_rules.go
+
+/**
+ * TagRule tags items whose metadata matches its conditions and adds matching
+ * videos to a collection. Rules are evaluated whenever metadata is stored
+ * and when they are re-applied to the whole library.
+ */
+export interface TagRule {
+  id: number /* int64 */;
+  name: string;
+  enabled: boolean;
+  match: TagRuleMatch;
+  tags: string[];
+  /**
+   * CollectionID receives matching videos; playlists and channels are
+   * never added. A rule whose collection was deleted only tags.
+   */
+  collection_id?: string;
+  created_at: string /* RFC3339 */;
+  updated_at: string /* RFC3339 */;
+}
+/**
+ * Content types a tag rule can be limited to.
+ */
+export const TagRuleContentVideo = "video";
+/**
+ * Content types a tag rule can be limited to.
+ */
+export const TagRuleContentPlaylist = "playlist";
+/**
+ * Content types a tag rule can be limited to.
+ */
+export const TagRuleContentChannel = "channel";
+/**
+ * TagRuleMatch holds a rule's conditions, all of which must hold. Unset
+ * conditions are ignored.
+ */
+export interface TagRuleMatch {
+  /**
+   * ContentType limits the rule to videos, playlists or channels.
+   */
+  content_type?: string;
+  /**
+   * TitlePattern is a regular expression matched case-insensitively
+   * against the title (the channel name for channels).
+   */
+  title_pattern?: string;
+  /**
+   * Channel matches the channel name, channel ID or uploader ID
+   * (case-insensitive).
+   */
+  channel?: string;
+  /**
+   * MinDuration and MaxDuration bound a video's length in seconds; 0 is
+   * unbounded. Only videos have a duration, so either bound excludes
+   * playlists and channels.
+   */
+  min_duration?: number /* int */;
+  max_duration?: number /* int */;
+}
+/**
+ * TagSynonym rewrites the Alias tag to Canonical wherever tags are derived.
+ */
+export interface TagSynonym {
+  alias: string;
+  canonical: string;
+}
+/**
+ * TagVocabulary normalizes derived tags: synonyms are rewritten to their
+ * canonical name and blocked names are dropped. Tags users attach by hand are
+ * never rewritten.
+ */
+export interface TagVocabulary {
+  synonyms: TagSynonym[];
+  blocked: string[];
+}
+/**
+ * TagRuleRun summarizes re-applying auto-tagging and the rules to the whole
+ * library. Removed counts auto and rule tags that no longer apply.
+ */
+export interface TagRuleRun {
+  items: number /* int */;
+  tags_added: number /* int */;
+  tags_removed: number /* int */;
+  collections_added: number /* int */;
+}
+/**
+ * TagEngine evaluates auto-tagging, tag rules and the vocabulary against
+ * metadata. Build one per batch: patterns are compiled once.
+ */
+export interface TagEngine {
+}
+
 //////////
 // source: tags.go
 
@@ -256,6 +1110,10 @@ export const TagSourceUser = "user";
  * TagSource records how a tag got attached to a job.
  */
 export const TagSourceAuto = "auto";
+/**
+ * TagSourceRule marks tags attached by a user-defined TagRule.
+ */
+export const TagSourceRule = "rule";
 /**
  * Tag is a label attached to a downloaded video, playlist or channel. Count is
  * only populated when listing the tag catalog; Source is only populated when a
@@ -266,6 +1124,15 @@ export interface Tag {
   name: string;
   source?: string;
   count?: number /* int */;
+  /**
+   * Color is a "#rrggbb" hex color, empty for the default.
+   */
+  color?: string;
+  /**
+   * ParentID nests the tag under another one; filtering by a parent also
+   * matches items carrying its descendants.
+   */
+  parent_id?: number /* int64 */;
 }
 
 //////////
@@ -284,6 +1151,7 @@ export const OpTypeConvert: ToolsOperationType = "convert";
 export const OpTypeExtractAudio: ToolsOperationType = "extract_audio";
 export const OpTypeAdjustQuality: ToolsOperationType = "adjust_quality";
 export const OpTypeRotate: ToolsOperationType = "rotate";
+export const OpTypeRemux: ToolsOperationType = "remux";
 export const OpTypeWorkflow: ToolsOperationType = "workflow";
 /**
  * ToolsInputType describes how InputFiles should be interpreted.
@@ -323,6 +1191,12 @@ export interface ToolsJob {
   height?: number /* int */;
   video_codec?: string;
   audio_codec?: string;
+  /**
+   * Set on jobs submitted by an automation rule: the rule, and the
+   * download job of the video it ran on.
+   */
+  automation_rule_id?: string;
+  source_job_id?: string;
 }
 /**
  * Media kind of a produced output file, probed after the job completes.
@@ -341,6 +1215,20 @@ export interface ConcatParameters {
   output_format: string; // mp4, mkv, webm
   re_encode: boolean; // Re-encode if codecs differ
   file_order: string[]; // Explicit ordering by job ID
+  /**
+   * Segments, when set, joins these parts of the input videos, in order,
+   * instead of the whole videos; a video may appear several times.
+   * FileOrder is ignored.
+   */
+  segments?: ConcatSegment[];
+}
+/**
+ * ConcatSegment is a part of an input video, for highlight reels.
+ */
+export interface ConcatSegment {
+  job_id: string;
+  start_time: string; // HH:MM:SS(.ms) or seconds
+  end_time: string; // HH:MM:SS(.ms) or seconds
 }
 export interface ConvertParameters {
   output_format: string; // mp4, webm, mkv, avi, mov
@@ -348,6 +1236,18 @@ export interface ConvertParameters {
   audio_codec: string; // aac, libmp3lame, libopus, copy
   bitrate: string; // e.g. "2M", "5M"
 }
+/**
+ * RemuxParameters copies the streams into a new container without
+ * re-encoding, with the index at the front for progressive playback.
+ */
+export interface RemuxParameters {
+  output_format: string; // mp4 (default), mov, mkv
+  /**
+   * ReplaceOriginal swaps the downloaded file for the remuxed one instead
+   * of writing to the processed directory. Requires a single video.
+   */
+  replace_original: boolean;
+}
 export interface ExtractAudioParameters {
   output_format: string; // mp3, aac, flac, wav, ogg
   bitrate: string; // e.g. "128k", "320k"
@@ -399,14 +1299,268 @@ export interface PlaybackInfo {
   video_codec: string;
   audio_codec: string;
   browser_safe: boolean;
+  /**
+   * Faststart reports an mp4/mov index ahead of the media data.
+   */
+  faststart: boolean;
+  playback: PlaybackMode;
   transcode?: PlaybackTranscode;
+  /**
+   * HLSPath is the master playlist of an adaptive stream transcoded on
+   * the fly, playable right away whatever the codecs.
+   */
+  hls_path: string;
+  /**
+   * StoryboardPath is the WebVTT index of seek bar previews.
+   */
+  storyboard_path: string;
 }
 /**
- * PlaybackTranscode is the state of the convert job backing a browser-safe
- * version of a video.
+ * PlaybackMode is what a video needs before browsers can play it.
+ */
+export type PlaybackMode = string;
+export const PlaybackModeDirect: PlaybackMode = "direct";
+/**
+ * PlaybackModeRemux: the codecs play, but the container doesn't, or an mp4
+ * keeps its index at the end; copying the streams takes seconds.
+ */
+export const PlaybackModeRemux: PlaybackMode = "remux";
+/**
+ * PlaybackModeTranscode: the codecs need re-encoding.
+ */
+export const PlaybackModeTranscode: PlaybackMode = "transcode";
+/**
+ * PlaybackTranscode is the state of the convert or remux job backing a
+ * browser-safe version of a video.
  */
 export interface PlaybackTranscode {
   job_id: string;
+  operation: ToolsOperationType;
   status: ToolsJobStatus;
   progress: number /* float64 */;
 }
+
+//////////
+// source: upgrades.go
+
+export type UpgradeRunStatus = string;
+export const UpgradeRunRunning: UpgradeRunStatus = "running";
+export const UpgradeRunComplete: UpgradeRunStatus = "complete";
+/**
+ * UpgradeRunStopped: the service shut down before the run finished.
+ */
+export const UpgradeRunStopped: UpgradeRunStatus = "stopped";
+/**
+ * UpgradeOutcome is what an upgrade run did with one video.
+ */
+export type UpgradeOutcome = string;
+export const UpgradeOutcomeUpgraded: UpgradeOutcome = "upgraded";
+/**
+ * UpgradeOutcomeAvailable: a dry run found a better format.
+ */
+export const UpgradeOutcomeAvailable: UpgradeOutcome = "available";
+export const UpgradeOutcomeNoImprovement: UpgradeOutcome = "no_improvement";
+export const UpgradeOutcomeFailed: UpgradeOutcome = "failed";
+/**
+ * UpgradeRun is one pass of re-downloading archived videos whose stored
+ * resolution or codec falls short of the current quality settings. The
+ * counters and byte totals form the report; Items is only populated when a
+ * single run is requested.
+ */
+export interface UpgradeRun {
+  id: number /* int64 */;
+  status: UpgradeRunStatus;
+  dry_run: boolean;
+  target_height: number /* int */;
+  candidates: number /* int */;
+  checked: number /* int */;
+  upgraded: number /* int */;
+  failed: number /* int */;
+  /**
+   * BytesBefore and BytesAfter are the sizes of the upgraded files before
+   * and after, so BytesAfter-BytesBefore is the disk the run cost. In a dry
+   * run Upgraded counts the videos that would be upgraded and BytesAfter
+   * is yt-dlp's size estimate.
+   */
+  bytes_before: number /* int64 */;
+  bytes_after: number /* int64 */;
+  started_at: string /* RFC3339 */;
+  finished_at?: string /* RFC3339 */;
+  items?: UpgradeItem[];
+}
+/**
+ * UpgradeItem records what an upgrade run found and did for one video.
+ */
+export interface UpgradeItem {
+  id: number /* int64 */;
+  run_id: number /* int64 */;
+  job_id: string;
+  title: string;
+  outcome: UpgradeOutcome;
+  old_height: number /* int */;
+  new_height: number /* int */;
+  old_codec: string;
+  new_codec: string;
+  old_size: number /* int64 */;
+  new_size: number /* int64 */;
+  error?: string;
+  created_at: string /* RFC3339 */;
+}
+/**
+ * UpgradeUpdate is broadcast over the WebSocket as an upgrade run progresses.
+ */
+export interface UpgradeUpdate {
+  type: string; // always "upgrade"
+  runID: number /* int64 */;
+  status: UpgradeRunStatus;
+  candidates: number /* int */;
+  checked: number /* int */;
+  upgraded: number /* int */;
+  failed: number /* int */;
+}
+
+//////////
+// source: watch.go
+
+/**
+ * WatchedThreshold is the share of a video's duration after which playback
+ * counts as watched, leaving end credits and outros out.
+ */
+export const WatchedThreshold = 0.9;
+/**
+ * WatchProgress is where playback of a video last stopped.
+ */
+export interface WatchProgress {
+  job_id: string;
+  /**
+   * Position and Duration are in seconds.
+   */
+  position: number /* float64 */;
+  duration: number /* float64 */;
+  completed: boolean;
+  last_watched_at: string /* RFC3339 */;
+}
+/**
+ * WatchProgressUpdate is a progress report from a player. Duration defaults
+ * to the video's duration; Completed marks the video watched (true) or
+ * unwatched (false) regardless of position, which otherwise decides it.
+ */
+export interface WatchProgressUpdate {
+  position: number /* float64 */;
+  duration?: number /* float64 */;
+  completed?: boolean;
+}
+/**
+ * WatchState narrows a listing by watch progress.
+ */
+export type WatchState = string;
+export const WatchStateWatched: WatchState = "watched";
+export const WatchStateUnwatched: WatchState = "unwatched";
+export const WatchStateInProgress: WatchState = "in-progress";
+/**
+ * WatchCompletion summarizes how much of a playlist, channel or collection
+ * has been watched.
+ */
+export interface WatchCompletion {
+  watched: number /* int */;
+  total: number /* int */;
+  /**
+   * Percent is Watched out of Total, rounded down.
+   */
+  percent: number /* int */;
+}
+
+//////////
+// source: webhooks.go
+
+/**
+ * WebhookEvent names a lifecycle event webhooks can subscribe to.
+ */
+export type WebhookEvent = string;
+export const WebhookEventDownloadCompleted: WebhookEvent = "download.completed";
+export const WebhookEventDownloadFailed: WebhookEvent = "download.failed";
+export const WebhookEventDownloadCancelled: WebhookEvent = "download.cancelled";
+export const WebhookEventToolsCompleted: WebhookEvent = "tools.completed";
+export const WebhookEventToolsFailed: WebhookEvent = "tools.failed";
+/**
+ * WebhookEventSubscriptionItem: a re-synced playlist or channel gained a
+ * video since its previous sync.
+ */
+export const WebhookEventSubscriptionItem: WebhookEvent = "subscription.new_item";
+/**
+ * WebhookEventVideoRemoved: an archived video stopped being available at
+ * its source (removed, private, terminated or geo-blocked).
+ */
+export const WebhookEventVideoRemoved: WebhookEvent = "video.removed";
+/**
+ * WebhookEventPing is only sent by the test endpoint.
+ */
+export const WebhookEventPing: WebhookEvent = "ping";
+/**
+ * Webhook is a registered HTTP endpoint that receives signed JSON payloads
+ * for the events it subscribes to. No events means all of them.
+ */
+export interface Webhook {
+  id: string;
+  name: string;
+  url: string;
+  events: WebhookEvent[];
+  enabled: boolean;
+  created_at: string /* RFC3339 */;
+  updated_at: string /* RFC3339 */;
+}
+/**
+ * WebhookPayload is the JSON body POSTed to a webhook. Data mirrors what the
+ * WebSocket carries for the event: a ProgressUpdate for downloads, a
+ * ToolsProgressUpdate for tools jobs, a SubscriptionItem or an
+ * AvailabilityUpdate.
+ */
+export interface WebhookPayload {
+  event: WebhookEvent;
+  delivery_id: number /* int64 */;
+  timestamp: string /* RFC3339 */;
+  data: any;
+}
+/**
+ * SubscriptionItem is the data of a subscription.new_item event.
+ */
+export interface SubscriptionItem {
+  jobID: string; // the playlist or channel job
+  sourceID: string;
+  videoID: string;
+  title: string;
+  position: number /* int */;
+}
+export type WebhookDeliveryStatus = string;
+export const WebhookDeliveryPending: WebhookDeliveryStatus = "pending";
+export const WebhookDeliveryDelivered: WebhookDeliveryStatus = "delivered";
+export const WebhookDeliveryFailed: WebhookDeliveryStatus = "failed";
+/**
+ * WebhookDelivery is the log entry of one event sent to one webhook,
+ * updated after every attempt.
+ */
+export interface WebhookDelivery {
+  id: number /* int64 */;
+  webhook_id: string;
+  event: WebhookEvent;
+  payload: string;
+  status: WebhookDeliveryStatus;
+  attempts: number /* int */;
+  /**
+   * ResponseStatus is the HTTP status of the last attempt; 0 when the
+   * request never got a response.
+   */
+  response_status?: number /* int */;
+  error?: string;
+  created_at: string /* RFC3339 */;
+  updated_at: string /* RFC3339 */;
+  delivered_at?: string /* RFC3339 */;
+}
+/**
+ * Notifier receives lifecycle events for webhook delivery. Notify must not
+ * block the caller on network I/O.
+ *
+ */
+export type Notifier = any;
+
+export type WebhookRepository = any;